@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/solana-wallet-tracker/pkg/notify"
+	"github.com/yourusername/solana-wallet-tracker/pkg/sns"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// newBalancesCommand builds `tracker balances`, which prints the current
+// token balances of the configured wallets
+func newBalancesCommand() *cobra.Command {
+	var asJSON, splToken bool
+
+	cmd := &cobra.Command{
+		Use:   "balances",
+		Short: "Print the current token balances of the configured wallets",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+			if len(cfg.Wallets) == 0 {
+				return fmt.Errorf("no wallets configured")
+			}
+
+			ctx := cmd.Context()
+			client, err := newClient(ctx, cfg.RPCEndpoint, cfg.WSEndpoint, cfg.HTTP)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			client.Commitments = commitments(cfg.Commitment)
+			if _, err := resolveConfigDomains(ctx, sns.NewResolver(client), cfg); err != nil {
+				return err
+			}
+
+			var balances []solana.TokenAccountInfo
+			for _, wallet := range cfg.Wallets {
+				tokens := cfg.TokensFor(wallet)
+				tracked := make(map[string]bool, len(tokens))
+				for _, token := range tokens {
+					tracked[token] = true
+				}
+
+				accounts, err := client.GetTokenAccounts(ctx, wallet)
+				if err != nil {
+					return fmt.Errorf("failed to get token accounts of %s: %w", wallet, err)
+				}
+				for _, account := range accounts {
+					if len(tracked) == 0 || tracked[account.Mint] {
+						balances = append(balances, account)
+					}
+				}
+			}
+
+			if splToken {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(solana.ToCLI(balances))
+			}
+			if asJSON {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(balances)
+			}
+
+			writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(writer, "WALLET\tMINT\tBALANCE\n")
+			for _, account := range balances {
+				fmt.Fprintf(writer, "%s\t%s\t%s\n", account.Owner, account.Mint, notify.FormatAmount(account.Balance, account.Decimals))
+			}
+			return writer.Flush()
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print balances as JSON")
+	cmd.Flags().BoolVar(&splToken, "spl-token", false, "print balances in the JSON format of spl-token accounts --output json")
+	return cmd
+}
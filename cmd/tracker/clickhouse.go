@@ -0,0 +1,40 @@
+//go:build !noclickhouse
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/clickhouse"
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+)
+
+func init() {
+	newAnalytics = newClickHouseWriter
+	newRecordTarget = newClickHouseTarget
+}
+
+// newClickHouseWriter creates the ClickHouse writer of the settings and its
+// tables
+func newClickHouseWriter(ctx context.Context, cfg *config.ClickHouseConfig) (eventExporter, error) {
+	writer := clickhouse.NewWriter(cfg.URL, cfg.Database, cfg.User, cfg.Password, cfg.BatchSize, cfg.FlushInterval.Duration(), logger)
+	if err := writer.CreateSchema(ctx); err != nil {
+		return nil, fmt.Errorf("failed to set up ClickHouse: %w", err)
+	}
+	return writer, nil
+}
+
+// newClickHouseTarget opens the ClickHouse database of a clickhouse:// URI
+// as a migration target and creates its balances table
+func newClickHouseTarget(ctx context.Context, uri string) (store.RecordTarget, error) {
+	writer, err := clickhouse.ParseURI(uri, logger)
+	if err != nil {
+		return nil, err
+	}
+	if err := writer.CreateHistorySchema(ctx); err != nil {
+		return nil, fmt.Errorf("failed to set up ClickHouse: %w", err)
+	}
+	return writer, nil
+}
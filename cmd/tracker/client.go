@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// newClient connects to the endpoints with the configured http settings
+func newClient(ctx context.Context, rpcEndpoint, wsEndpoint string, cfg *config.HTTPConfig) (*solana.Client, error) {
+	t, err := transport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	client, err := solana.NewClientWithTransport(ctx, rpcEndpoint, wsEndpoint, t)
+	if err != nil {
+		return nil, err
+	}
+	client.Logger = logger
+	return client, nil
+}
+
+// transport converts the configured http settings, loading the files of
+// their TLS settings
+func transport(cfg *config.HTTPConfig) (solana.Transport, error) {
+	if cfg == nil {
+		return solana.Transport{}, nil
+	}
+
+	t := solana.Transport{
+		Headers:          cfg.Headers,
+		Timeout:          cfg.Timeout.Duration(),
+		HandshakeTimeout: cfg.HandshakeTimeout.Duration(),
+	}
+	if cfg.Proxy != "" {
+		proxy, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return solana.Transport{}, fmt.Errorf("failed to parse proxy URL: %w", err)
+		}
+		t.Proxy = proxy
+	}
+	if cfg.TLS != nil {
+		tlsConfig, err := tlsConfig(cfg.TLS)
+		if err != nil {
+			return solana.Transport{}, err
+		}
+		t.TLS = tlsConfig
+	}
+	return t, nil
+}
+
+// tlsConfig builds the configured TLS settings
+func tlsConfig(cfg *config.TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
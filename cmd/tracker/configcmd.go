@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/solana-wallet-tracker/pkg/preflight"
+	"github.com/yourusername/solana-wallet-tracker/pkg/sns"
+)
+
+// newConfigCommand builds `tracker config validate` and `tracker config check`
+func newConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "validate",
+		Short: "Check the configuration and list every problem",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration from %s: %w", configSource(), err)
+			}
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+
+			fmt.Printf("Configuration from %s is valid: %d wallets on %d networks, %d tokens, %d notifiers\n",
+				configSource(), len(cfg.AllWallets()), len(cfg.Networks)+1, len(cfg.Tokens), len(cfg.Notifiers))
+			return nil
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "check",
+		Short: "Validate the configuration and check it against the chain",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration from %s: %w", configSource(), err)
+			}
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			client, err := newClient(ctx, cfg.RPCEndpoint, cfg.WSEndpoint, cfg.HTTP)
+			if err != nil {
+				return fmt.Errorf("failed to initialize Solana client: %w", err)
+			}
+			defer client.Close()
+			client.Commitments = commitments(cfg.Commitment)
+			if _, err := resolveConfigDomains(ctx, sns.NewResolver(client), cfg); err != nil {
+				return err
+			}
+
+			if err := preflight.Run(ctx, client, cfg, cfg.Preflight.MaxLatency.Duration()); err != nil {
+				return err
+			}
+
+			fmt.Printf("Preflight checks passed: %d wallets, %d mints\n",
+				len(cfg.Wallets), len(cfg.Tokens)+len(cfg.MintWatches))
+			return nil
+		},
+	})
+	return cmd
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/sns"
+)
+
+// resolveConfigDomains replaces the .sol domains given for wallets in the
+// configuration by the wallets owning them and returns the domains by wallet
+func resolveConfigDomains(ctx context.Context, resolver *sns.Resolver, cfg *config.Config) (map[string]string, error) {
+	return cfg.ResolveDomains(func(domain string) (string, error) {
+		return resolver.Resolve(ctx, domain)
+	})
+}
+
+// resolveWallet returns the wallet a command line value names, looking .sol
+// domains up through the configured RPC endpoint
+func resolveWallet(ctx context.Context, wallet string) (string, error) {
+	if !sns.IsDomain(wallet) {
+		return wallet, nil
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load configuration: %w", err)
+	}
+	client, err := newClient(ctx, cfg.RPCEndpoint, cfg.WSEndpoint, cfg.HTTP)
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize Solana client: %w", err)
+	}
+	defer client.Close()
+	client.Commitments = commitments(cfg.Commitment)
+
+	address, err := sns.NewResolver(client).Resolve(ctx, wallet)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", wallet, err)
+	}
+	return address, nil
+}
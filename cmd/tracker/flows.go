@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/solana-wallet-tracker/pkg/flow"
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+)
+
+// newFlowsCommand builds `tracker flows`, which reports the net inflows and
+// outflows of the wallets from the event log of the store
+func newFlowsCommand() *cobra.Command {
+	var wallet, mint, by, format string
+	var since time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "flows",
+		Short: "Report the net token inflows and outflows of the wallets",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "table" && format != "json" && format != "csv" {
+				return fmt.Errorf("unknown format %q: use table, json or csv", format)
+			}
+
+			st, err := openConfiguredStore()
+			if err != nil {
+				return err
+			}
+			defer st.Close()
+
+			events, ok := st.(store.EventStore)
+			if !ok {
+				return fmt.Errorf("the configured store doesn't keep events")
+			}
+
+			var from time.Time
+			if since > 0 {
+				from = time.Now().Add(-since)
+			}
+			var wallets []string
+			if wallet != "" {
+				address, err := resolveWallet(cmd.Context(), wallet)
+				if err != nil {
+					return err
+				}
+				wallets = []string{address}
+			}
+			stored, err := flow.Load(cmd.Context(), events, from, time.Time{}, wallets)
+			if err != nil {
+				return err
+			}
+			report, err := flow.Summarize(stored, by)
+			if err != nil {
+				return err
+			}
+			if mint != "" {
+				report = report.ForMint(mint)
+			}
+
+			switch format {
+			case "json":
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(report)
+			case "csv":
+				return flow.WriteCSV(os.Stdout, report)
+			}
+
+			writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(writer, "WALLET\tTOKEN\tPERIOD\tIN\tOUT\tNET\tCHANGES\n")
+			for _, f := range append(append([]flow.Flow{}, report.Wallets...), report.Mints...) {
+				owner := f.Wallet
+				if owner == "" {
+					owner = "all"
+				}
+				token := f.Mint
+				if f.Symbol != "" {
+					token = f.Symbol
+				}
+				period := f.Period
+				if period == "" {
+					period = flow.ByTotal
+				}
+				fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+					owner, token, period,
+					flow.FormatAmount(f.Inflow, f.Decimals), flow.FormatAmount(f.Outflow, f.Decimals),
+					flow.FormatNet(f.Net, f.Decimals), f.Changes)
+			}
+			return writer.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&wallet, "wallet", "", "only report this wallet, by address or .sol domain")
+	cmd.Flags().StringVar(&mint, "mint", "", "only report this mint")
+	cmd.Flags().StringVar(&by, "by", flow.ByDay, "group by day, week or total")
+	cmd.Flags().DurationVar(&since, "since", 0, "how far back to look (default everything)")
+	cmd.Flags().StringVar(&format, "format", "table", "output format: table, json or csv")
+	return cmd
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/solana-wallet-tracker/pkg/notify"
+)
+
+// newHistoryCommand builds `tracker history`, which prints the stored
+// balance history of a wallet and mint
+func newHistoryCommand() *cobra.Command {
+	var wallet, mint string
+	var since time.Duration
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Print the stored balance history of a wallet and mint",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			wallet, err := resolveWallet(cmd.Context(), wallet)
+			if err != nil {
+				return err
+			}
+			st, err := openConfiguredStore()
+			if err != nil {
+				return err
+			}
+			defer st.Close()
+
+			records, err := st.History(context.Background(), wallet, mint, time.Now().Add(-since))
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(records)
+			}
+
+			writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(writer, "TIME\tACCOUNT\tBALANCE\n")
+			for _, record := range records {
+				fmt.Fprintf(writer, "%s\t%s\t%s\n",
+					record.LastUpdatedAt.Format(time.RFC3339), record.Address, notify.FormatAmount(record.Balance, record.Decimals))
+			}
+			return writer.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&wallet, "wallet", "", "wallet address or .sol domain")
+	cmd.Flags().StringVar(&mint, "mint", "", "token mint")
+	cmd.Flags().DurationVar(&since, "since", 24*time.Hour, "how far back to look")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print history as JSON")
+	cmd.MarkFlagRequired("wallet")
+	cmd.MarkFlagRequired("mint")
+	return cmd
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/notify"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+)
+
+// inspection gathers what is known about a token account. Each source is
+// optional; its error is kept when it couldn't be read.
+type inspection struct {
+	Address string `json:"address"`
+	// Chain is the account as the RPC endpoint returns it, nil when closed
+	Chain      *solana.TokenAccountInfo `json:"chain"`
+	ChainError string                   `json:"chain_error,omitempty"`
+	// Timeline is the stored history of the account, oldest first
+	Timeline      []solana.TokenAccountInfo `json:"timeline"`
+	TimelineError string                    `json:"timeline_error,omitempty"`
+	// Live is what the running tracker knows, read through its API
+	Live      *monitor.AccountInspection `json:"live,omitempty"`
+	LiveError string                     `json:"live_error,omitempty"`
+	// Findings are the likely reasons the account isn't updating
+	Findings []string `json:"findings"`
+}
+
+// newInspectCommand builds `tracker inspect`, which gathers what the chain,
+// the store and the running tracker know about one token account
+func newInspectCommand() *cobra.Command {
+	var apiURL, apiKey string
+	var since time.Duration
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "inspect <token-account>",
+		Short: "Debug a token account: stored timeline, chain state, subscription and parse errors",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			address := args[0]
+			if err := solana.ValidateAddress(address); err != nil {
+				return err
+			}
+			cfg, err := loadConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+			if apiURL == "" && cfg.API != nil {
+				apiURL = listenURL(cfg.API.Listen)
+			}
+			if apiKey == "" {
+				apiKey = configAPIKey()
+			}
+
+			ctx := cmd.Context()
+			result := inspection{Address: address, Timeline: []solana.TokenAccountInfo{}}
+			owner, mint := inspectChain(ctx, cfg, &result)
+			if apiURL != "" {
+				inspectLive(apiURL, apiKey, address, owner, &result)
+				if result.Live != nil && result.Live.Account != nil && owner == "" {
+					owner, mint = result.Live.Account.Owner, result.Live.Account.Mint
+				}
+			} else {
+				result.LiveError = "api is not configured; set api.listen or pass --api"
+			}
+			inspectTimeline(ctx, cfg, owner, mint, time.Now().Add(-since), &result)
+			result.Findings = diagnose(result)
+
+			if asJSON {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(result)
+			}
+			return printInspection(result)
+		},
+	}
+
+	cmd.Flags().StringVar(&apiURL, "api", "", "tracker API URL (default from api.listen in the config)")
+	cmd.Flags().StringVar(&apiKey, "api-key", "", "tracker API key (default the first of api.keys in the config)")
+	cmd.Flags().DurationVar(&since, "since", 7*24*time.Hour, "how far back to read the stored timeline")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the inspection as JSON")
+	return cmd
+}
+
+// inspectChain reads the account from the RPC endpoint and returns its owner
+// and mint
+func inspectChain(ctx context.Context, cfg *config.Config, result *inspection) (string, string) {
+	client, err := newClient(ctx, cfg.RPCEndpoint, cfg.WSEndpoint, cfg.HTTP)
+	if err != nil {
+		result.ChainError = err.Error()
+		return "", ""
+	}
+	defer client.Close()
+	client.Commitments = commitments(cfg.Commitment)
+
+	account, err := client.GetTokenAccount(ctx, result.Address)
+	if err != nil {
+		result.ChainError = err.Error()
+		return "", ""
+	}
+	result.Chain = account
+	if account == nil {
+		return "", ""
+	}
+	return account.Owner, account.Mint
+}
+
+// inspectLive asks the running tracker about the account, with an API key
+// if one is given
+func inspectLive(apiURL, apiKey, address, owner string, result *inspection) {
+	endpoint := strings.TrimSuffix(apiURL, "/") + "/accounts/" + url.PathEscape(address)
+	if owner != "" {
+		endpoint += "?wallet=" + url.QueryEscape(owner)
+	}
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		result.LiveError = err.Error()
+		return
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		result.LiveError = fmt.Sprintf("failed to reach the tracker API: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		result.LiveError = fmt.Sprintf("tracker API returned status %d: %s", resp.StatusCode, body.Error)
+		return
+	}
+	var live monitor.AccountInspection
+	if err := json.NewDecoder(resp.Body).Decode(&live); err != nil {
+		result.LiveError = fmt.Sprintf("failed to read API response: %v", err)
+		return
+	}
+	result.Live = &live
+}
+
+// inspectTimeline reads the stored history of the account
+func inspectTimeline(ctx context.Context, cfg *config.Config, owner, mint string, since time.Time, result *inspection) {
+	if cfg.Store == nil {
+		result.TimelineError = "no store configured"
+		return
+	}
+	if owner == "" {
+		result.TimelineError = "owner and mint unknown, the account is closed and not tracked"
+		return
+	}
+	history, err := store.New(*cfg.Store, logger)
+	if err != nil {
+		result.TimelineError = err.Error()
+		return
+	}
+	defer history.Close()
+
+	records, err := history.History(ctx, owner, mint, since)
+	if err != nil {
+		result.TimelineError = err.Error()
+		return
+	}
+	for _, record := range records {
+		if record.Address == result.Address {
+			result.Timeline = append(result.Timeline, record)
+		}
+	}
+}
+
+// diagnose compares the sources and lists the likely reasons an account
+// isn't updating
+func diagnose(result inspection) []string {
+	findings := []string{}
+	chain := result.Chain
+	if chain == nil && result.ChainError == "" {
+		findings = append(findings, "the account doesn't exist on chain, it may have been closed")
+	}
+
+	if live := result.Live; live != nil {
+		switch {
+		case live.Account == nil && live.Subscription == nil:
+			findings = append(findings, "the tracker doesn't monitor the owner of this account")
+		case live.Account == nil:
+			findings = append(findings, "the tracker monitors the owner but not this account; check the token filter, dust and zero_balance_ttl")
+		case chain != nil && live.Account.Balance != chain.Balance:
+			findings = append(findings, fmt.Sprintf("the tracker holds balance %s but the chain has %s",
+				notify.FormatAmount(live.Account.Balance, live.Account.Decimals), notify.FormatAmount(chain.Balance, chain.Decimals)))
+		}
+		if live.Paused {
+			findings = append(findings, "the owner is paused")
+		}
+		if status := live.Subscription; status != nil && status.State != monitor.SubscriptionActive {
+			finding := fmt.Sprintf("the owner's subscription is %s since %s", status.State, status.Since.Format(time.RFC3339))
+			if status.LastError != "" {
+				finding += ": " + status.LastError
+			}
+			findings = append(findings, finding)
+		}
+		if len(live.ParseErrors) > 0 {
+			findings = append(findings, fmt.Sprintf("%d updates of the account failed to parse", len(live.ParseErrors)))
+		}
+	}
+
+	if n := len(result.Timeline); n > 0 && chain != nil && result.Timeline[n-1].Balance != chain.Balance {
+		findings = append(findings, "the latest stored balance differs from the chain")
+	}
+	return findings
+}
+
+// printInspection prints an inspection section by section
+func printInspection(result inspection) error {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "Account %s\n\n", result.Address)
+
+	fmt.Fprintf(writer, "CHAIN\n")
+	switch {
+	case result.ChainError != "":
+		fmt.Fprintf(writer, "  unavailable: %s\n", result.ChainError)
+	case result.Chain == nil:
+		fmt.Fprintf(writer, "  not found\n")
+	default:
+		printAccount(writer, *result.Chain)
+	}
+
+	fmt.Fprintf(writer, "\nTRACKER\n")
+	if live := result.Live; live == nil {
+		fmt.Fprintf(writer, "  unavailable: %s\n", result.LiveError)
+	} else {
+		if live.Account != nil {
+			printAccount(writer, *live.Account)
+			fmt.Fprintf(writer, "  updated\t%s\n", live.Account.LastUpdatedAt.Format(time.RFC3339))
+		} else {
+			fmt.Fprintf(writer, "  not tracked\n")
+		}
+		fmt.Fprintf(writer, "  paused\t%t\n", live.Paused)
+		if status := live.Subscription; status != nil {
+			fmt.Fprintf(writer, "  subscription\t%s since %s, %d restarts, %d updates\n",
+				status.State, status.Since.Format(time.RFC3339), status.Restarts, status.Updates)
+			if status.LastUpdateAt != nil {
+				fmt.Fprintf(writer, "  last update\t%s\n", status.LastUpdateAt.Format(time.RFC3339))
+			}
+			if status.LastError != "" {
+				fmt.Fprintf(writer, "  last error\t%s\n", status.LastError)
+			}
+		}
+		for _, parseError := range live.ParseErrors {
+			fmt.Fprintf(writer, "  parse error\t%s %s\n", parseError.At.Format(time.RFC3339), parseError.Error)
+		}
+	}
+
+	fmt.Fprintf(writer, "\nSTORED TIMELINE\n")
+	if result.TimelineError != "" {
+		fmt.Fprintf(writer, "  unavailable: %s\n", result.TimelineError)
+	} else if len(result.Timeline) == 0 {
+		fmt.Fprintf(writer, "  no records\n")
+	}
+	for _, record := range result.Timeline {
+		fmt.Fprintf(writer, "  %s\t%s\tslot %d\t%s\n", record.LastUpdatedAt.Format(time.RFC3339),
+			notify.FormatAmount(record.Balance, record.Decimals), record.Slot, record.Signature)
+	}
+
+	fmt.Fprintf(writer, "\nFINDINGS\n")
+	if len(result.Findings) == 0 {
+		fmt.Fprintf(writer, "  none, the sources agree\n")
+	}
+	for _, finding := range result.Findings {
+		fmt.Fprintf(writer, "  - %s\n", finding)
+	}
+	return writer.Flush()
+}
+
+// printAccount prints the state of a token account
+func printAccount(writer *tabwriter.Writer, account solana.TokenAccountInfo) {
+	fmt.Fprintf(writer, "  owner\t%s\n", account.Owner)
+	fmt.Fprintf(writer, "  mint\t%s\n", account.Mint)
+	fmt.Fprintf(writer, "  balance\t%s\n", notify.FormatAmount(account.Balance, account.Decimals))
+	if account.Slot != 0 {
+		fmt.Fprintf(writer, "  slot\t%d\n", account.Slot)
+	}
+	if account.Frozen {
+		fmt.Fprintf(writer, "  frozen\ttrue\n")
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+)
+
+// exporter exports in the background until its context is done and flushes
+// what's left on shutdown, like the OTLP provider and the ClickHouse writer
+type exporter interface {
+	Run(ctx context.Context)
+	Flush(ctx context.Context)
+}
+
+// eventExporter is an exporter of the events of the monitors
+type eventExporter interface {
+	exporter
+	Handle(event monitor.Event)
+}
+
+// liveDashboard shows the balances and events of the monitors while the
+// tracker runs, until the user quits it
+type liveDashboard interface {
+	SetLabels(labels map[string]string)
+	AddSource(source func() map[string]solana.TokenAccountInfo)
+	Handle(event monitor.Event)
+	Run(ctx context.Context) error
+}
+
+// The optional integrations compiled into the binary. Each registers itself
+// from a file excluded by its build tag, like the store backends, so
+// minimal builds leave their clients out; they stay nil in builds without
+// them.
+var (
+	// newTelemetry creates the OTLP provider of the telemetry settings and
+	// makes it record every span and metric, see otlp.go
+	newTelemetry func(cfg *config.TelemetryConfig) (exporter, error)
+	// newAnalytics creates the ClickHouse writer of the settings, with its
+	// schema, see clickhouse.go
+	newAnalytics func(ctx context.Context, cfg *config.ClickHouseConfig) (eventExporter, error)
+	// newRecordTarget opens the ClickHouse target of `tracker store
+	// migrate`, with its table, see clickhouse.go
+	newRecordTarget func(ctx context.Context, uri string) (store.RecordTarget, error)
+	// optionalCommands build the subcommands of optional integrations, such
+	// as `tracker tui`
+	optionalCommands []func() *cobra.Command
+)
+
+// unavailable is the error of an integration left out by its build tag
+func unavailable(name, tag string) error {
+	return fmt.Errorf("%s is not available in this build (built with the %s tag)", name, tag)
+}
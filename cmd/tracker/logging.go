@@ -0,0 +1,24 @@
+package main
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+)
+
+// logger is the logger passed to the packages of the tracker, the standard
+// logrus logger set up by setupLogging
+var logger = logging.Logrus(logrus.StandardLogger())
+
+// setupLogging sets the level of the logger from the configuration, info
+// when it's invalid, and logs with full timestamps
+func setupLogging(cfg *config.Config) {
+	level, err := logrus.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		level = logrus.InfoLevel
+	}
+	logrus.SetLevel(level)
+	logrus.SetFormatter(&logrus.TextFormatter{
+		FullTimestamp: true,
+	})
+}
@@ -1,78 +1,160 @@
 package main
 
 import (
+	"fmt"
 	"os"
-	"os/signal"
-	"syscall"
+	"strconv"
 
-	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
 	"github.com/yourusername/solana-wallet-tracker/pkg/config"
-	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
-	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
 )
 
+// configFile is the configuration file selected with --config or found by
+// config.FindFile, empty when configuring from the environment only
+var configFile string
+
+// noConfigFile configures the tracker from the environment only, see
+// --no-config-file
+var noConfigFile bool
+
+// noConfigFileEnv mirrors --no-config-file
+const noConfigFileEnv = "TRACKER_NO_CONFIG_FILE"
+
+// envFlags maps global flags to the environment variables they mirror
+var envFlags = []struct {
+	name  string
+	env   string
+	usage string
+}{
+	{"rpc-endpoint", "SOLANA_RPC_ENDPOINT", "Solana RPC endpoint URL"},
+	{"ws-endpoint", "SOLANA_WS_ENDPOINT", "Solana WebSocket endpoint URL"},
+	{"wallets", "MONITOR_WALLETS", "comma separated wallet addresses to monitor"},
+	{"tokens", "MONITOR_TOKENS", "comma separated token mints to track"},
+	{"log-level", "LOG_LEVEL", "logging level (debug, info, warn, error)"},
+}
+
 func main() {
-	// Create default config file if not exists
-	if err := config.CreateDefaultConfigFile(); err != nil {
-		logrus.Fatalf("Failed to create default config file: %v", err)
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// newRootCommand builds the tracker command tree. Running tracker without a
+// subcommand is the same as tracker run.
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:               "tracker",
+		Short:             "Monitor SPL token balances of Solana wallets",
+		SilenceUsage:      true,
+		Args:              cobra.NoArgs,
+		RunE:              func(cmd *cobra.Command, args []string) error { runTracker(nil); return nil },
+		PersistentPreRunE: setup,
 	}
 
-	// Load configuration
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		logrus.Fatalf("Failed to load configuration: %v", err)
+	root.PersistentFlags().StringVar(&configFile, "config", "",
+		"configuration file (env "+config.FileEnv+", default ./config.json or the first found in the config directories)")
+	root.PersistentFlags().BoolVar(&noConfigFile, "no-config-file", false,
+		"configure from environment variables only, never reading or writing a config file (env "+noConfigFileEnv+")")
+	for _, flag := range envFlags {
+		root.PersistentFlags().String(flag.name, "", flag.usage+" (env "+flag.env+")")
 	}
 
-	// Initialize Solana client
-	client, err := solana.NewClient(cfg.RPCEndpoint, cfg.WSEndpoint)
-	if err != nil {
-		logrus.Fatalf("Failed to initialize Solana client: %v", err)
+	root.AddCommand(
+		newRunCommand(),
+		newBalancesCommand(),
+		newAddWalletCommand(),
+		newHistoryCommand(),
+		newConfigCommand(),
+		newPortfolioCommand(),
+		newStateCommand(),
+		newStoreCommand(),
+		newNotificationsCommand(),
+		newReplayCommand(),
+		newRentCommand(),
+		newFlowsCommand(),
+		newInspectCommand(),
+	)
+	root.AddCommand(newPauseCommands()...)
+	for _, command := range optionalCommands {
+		root.AddCommand(command())
+	}
+
+	return root
+}
+
+// newRunCommand builds `tracker run`
+func newRunCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "run",
+		Short: "Monitor the configured wallets until interrupted",
+		Args:  cobra.NoArgs,
+		RunE:  func(cmd *cobra.Command, args []string) error { runTracker(nil); return nil },
 	}
-	defer client.Close()
+}
 
-	// Check if we have wallets to monitor
-	if len(cfg.Wallets) == 0 {
-		logrus.Fatal("No wallets configured to monitor. Add wallets to config.json or set MONITOR_WALLETS environment variable.")
+// setup applies the global flags before any command runs
+func setup(cmd *cobra.Command, args []string) error {
+	if err := applyEnvFlags(cmd, args); err != nil {
+		return err
 	}
+	return selectConfigFile(cmd)
+}
 
-	// Initialize monitor
-	walletMonitor := monitor.NewMonitor(client, cfg.Wallets, cfg.Tokens)
-
-	// Register a handler for balance changes
-	walletMonitor.RegisterHandler(func(accountInfo solana.TokenAccountInfo) {
-		logrus.WithFields(logrus.Fields{
-			"address":  accountInfo.Address,
-			"owner":    accountInfo.Owner,
-			"mint":     accountInfo.Mint,
-			"balance":  accountInfo.Balance,
-			"decimals": accountInfo.Decimals,
-		}).Info("Token balance updated")
-
-		// Here you can add code to notify other systems:
-		// - Send message to message queue
-		// - Call webhook
-		// - Update database
-		// - etc.
-	})
-
-	// Start the monitor
-	if err := walletMonitor.Start(); err != nil {
-		logrus.Fatalf("Failed to start monitor: %v", err)
+// applyEnvFlags exports global flags given on the command line to the
+// environment variables they mirror, so they override the config file and
+// the environment
+func applyEnvFlags(cmd *cobra.Command, args []string) error {
+	for _, flag := range envFlags {
+		f := cmd.Flags().Lookup(flag.name)
+		if f == nil || !f.Changed {
+			continue
+		}
+		if err := os.Setenv(flag.env, f.Value.String()); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	logrus.WithFields(logrus.Fields{
-		"wallets": cfg.Wallets,
-		"tokens":  cfg.Tokens,
-	}).Info("Started monitoring token balances")
+// selectConfigFile picks the configuration file: none with
+// --no-config-file, the one given with --config, or the one found by
+// config.FindFile
+func selectConfigFile(cmd *cobra.Command) error {
+	if value := os.Getenv(noConfigFileEnv); value != "" && !cmd.Flags().Changed("no-config-file") {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %w", noConfigFileEnv, value, err)
+		}
+		noConfigFile = enabled
+	}
 
-	// Wait for interrupt signal
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	switch {
+	case noConfigFile:
+		if cmd.Flags().Changed("config") {
+			return fmt.Errorf("--config can't be combined with --no-config-file")
+		}
+		configFile = ""
+	case !cmd.Flags().Changed("config"):
+		configFile = config.FindFile()
+	}
+	return nil
+}
 
-	// Shutdown gracefully
-	logrus.Info("Shutting down...")
+// loadConfig loads the configuration selected on the command line and sets
+// up logging with it
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.LoadConfigFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+	setupLogging(cfg)
+	return cfg, nil
+}
 
-	walletMonitor.Stop()
-	logrus.Info("Solana wallet tracker stopped")
+// configSource names where the configuration comes from in messages
+func configSource() string {
+	if configFile == "" {
+		return "the environment"
+	}
+	return configFile
 }
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/yourusername/solana-wallet-tracker/pkg/notify"
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+)
+
+// newNotificationsCommand builds `tracker notifications list|replay`
+func newNotificationsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notifications",
+		Short: "Inspect and resend notifications that failed to deliver",
+	}
+	cmd.AddCommand(newNotificationsListCommand(), newNotificationsReplayCommand())
+	return cmd
+}
+
+// newNotificationsListCommand builds `tracker notifications list`, which
+// lists the dead letter queue
+func newNotificationsListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List notifications in the dead letter queue",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, deadLetters, err := openDeadLetters()
+			if err != nil {
+				return err
+			}
+			defer st.Close()
+
+			letters, err := deadLetters.DeadLetters(context.Background())
+			if err != nil {
+				return err
+			}
+			for _, letter := range letters {
+				notifier := fmt.Sprintf("notifiers[%d] (%s)", letter.NotifierIndex, letter.Notifier)
+				if letter.Tenant != "" {
+					notifier = fmt.Sprintf("tenant %s %s", letter.Tenant, notifier)
+				}
+				fmt.Printf("%s\t%s\t%s\t%d attempts\t%s\n",
+					letter.ID, letter.FailedAt.Format(time.RFC3339), notifier, letter.Attempts, letter.Error)
+			}
+			return nil
+		},
+	}
+}
+
+// newNotificationsReplayCommand builds `tracker notifications replay`, which
+// resends dead letters through the configured notifiers
+func newNotificationsReplayCommand() *cobra.Command {
+	var id string
+
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Resend notifications in the dead letter queue",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+			st, deadLetters, err := openDeadLetters()
+			if err != nil {
+				return err
+			}
+			defer st.Close()
+
+			// Letters are replayed through the notifiers of their tenant
+			notifiers := make(map[string][]notify.Notifier)
+
+			ctx := context.Background()
+			letters, err := deadLetters.DeadLetters(ctx)
+			if err != nil {
+				return err
+			}
+
+			var replayed, failed int
+			for _, letter := range letters {
+				if id != "" && letter.ID != id {
+					continue
+				}
+				tenantNotifiers, ok := notifiers[letter.Tenant]
+				if !ok {
+					if tenantNotifiers, err = newNotifiers(cfg, letter.Tenant, st); err != nil {
+						logrus.WithField("id", letter.ID).Errorf("Failed to replay notification: %v", err)
+						failed++
+						continue
+					}
+					notify.SetCriticalRules(tenantNotifiers, criticalRules(cfg))
+					notifiers[letter.Tenant] = tenantNotifiers
+				}
+				if err := notify.Replay(tenantNotifiers, letter); err != nil {
+					logrus.WithField("id", letter.ID).Errorf("Failed to replay notification: %v", err)
+					failed++
+					continue
+				}
+				if err := deadLetters.RemoveDeadLetter(ctx, letter.ID); err != nil {
+					return fmt.Errorf("notification %s was resent but not removed: %w", letter.ID, err)
+				}
+				replayed++
+			}
+
+			if id != "" && replayed+failed == 0 {
+				return fmt.Errorf("notification %s is not in the dead letter queue", id)
+			}
+			logrus.WithFields(logrus.Fields{
+				"replayed": replayed,
+				"failed":   failed,
+			}).Info("Replayed dead letter queue")
+			if failed > 0 {
+				return fmt.Errorf("%d notifications could not be resent", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&id, "id", "", "only resend the notification with this ID")
+	return cmd
+}
+
+// openDeadLetters opens the configured store and its dead letter queue
+func openDeadLetters() (store.Store, store.DeadLetterStore, error) {
+	st, err := openConfiguredStore()
+	if err != nil {
+		return nil, nil, err
+	}
+	deadLetters, ok := st.(store.DeadLetterStore)
+	if !ok {
+		st.Close()
+		return nil, nil, fmt.Errorf("the configured store has no dead letter queue")
+	}
+	return st, deadLetters, nil
+}
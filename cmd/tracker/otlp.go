@@ -0,0 +1,48 @@
+//go:build !nootlp
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/telemetry"
+)
+
+func init() {
+	newTelemetry = newOTLPProvider
+}
+
+// otlpExporter is the telemetry provider as an exporter
+type otlpExporter struct {
+	*telemetry.Provider
+}
+
+// Flush exports the spans and metrics left
+func (e otlpExporter) Flush(ctx context.Context) {
+	e.Shutdown(ctx)
+}
+
+// newOTLPProvider creates the provider of the telemetry settings, exporting
+// to OTEL_EXPORTER_OTLP_ENDPOINT when they name no endpoint, and makes it
+// the default
+func newOTLPProvider(cfg *config.TelemetryConfig) (exporter, error) {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+	if endpoint == "" {
+		return nil, fmt.Errorf("telemetry needs an endpoint or OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+	provider := telemetry.NewProvider(telemetry.Options{
+		Endpoint:       endpoint,
+		Headers:        cfg.Headers,
+		ServiceName:    cfg.ServiceName,
+		SampleRatio:    cfg.SampleRatio,
+		MetricInterval: cfg.MetricInterval.Duration(),
+	}, logger)
+	telemetry.SetDefault(provider)
+	return otlpExporter{provider}, nil
+}
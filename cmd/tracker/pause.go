@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// newPauseCommands builds `tracker pause` and `tracker resume`, which control
+// a running tracker through its API
+func newPauseCommands() []*cobra.Command {
+	return []*cobra.Command{
+		newWalletActionCommand("pause", "Pause monitoring of a wallet in the running tracker"),
+		newWalletActionCommand("resume", "Resume monitoring of a paused wallet in the running tracker"),
+	}
+}
+
+// newWalletActionCommand builds a command that posts a wallet action to the
+// API of the running tracker
+func newWalletActionCommand(action, short string) *cobra.Command {
+	var apiURL, apiKey string
+	cmd := &cobra.Command{
+		Use:   action + " <address>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if apiURL == "" {
+				cfg, err := loadConfig()
+				if err != nil {
+					return fmt.Errorf("failed to load configuration: %w", err)
+				}
+				if cfg.API == nil {
+					return fmt.Errorf("api is not configured; set api.listen or pass --api")
+				}
+				apiURL = listenURL(cfg.API.Listen)
+			}
+			if apiKey == "" {
+				apiKey = configAPIKey()
+			}
+
+			endpoint := strings.TrimSuffix(apiURL, "/") + "/wallets/" + url.PathEscape(args[0]) + "/" + action
+			req, err := http.NewRequest(http.MethodPost, endpoint, nil)
+			if err != nil {
+				return err
+			}
+			req.Header.Set("Content-Type", "application/json")
+			if apiKey != "" {
+				req.Header.Set("Authorization", "Bearer "+apiKey)
+			}
+			client := &http.Client{Timeout: 10 * time.Second}
+			resp, err := client.Do(req)
+			if err != nil {
+				return fmt.Errorf("failed to reach the tracker API: %w", err)
+			}
+			defer resp.Body.Close()
+
+			var body struct {
+				Error  string `json:"error"`
+				Paused bool   `json:"paused"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				return fmt.Errorf("failed to read API response: %w", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("%s failed: %s", action, body.Error)
+			}
+
+			logrus.WithFields(logrus.Fields{
+				"wallet": args[0],
+				"paused": body.Paused,
+			}).Info("Wallet updated")
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&apiURL, "api", "", "tracker API URL (default from api.listen in the config)")
+	cmd.Flags().StringVar(&apiKey, "api-key", "", "tracker API key (default the first of api.keys in the config)")
+	return cmd
+}
+
+// configAPIKey returns the first admin API key of the configuration, or an
+// empty key if there is none
+func configAPIKey() string {
+	cfg, err := loadConfig()
+	if err != nil || cfg.API == nil || len(cfg.API.Keys) == 0 {
+		return ""
+	}
+	return cfg.API.Keys[0]
+}
+
+// listenURL turns an API listen address into a URL on the local host
+func listenURL(listen string) string {
+	host, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return "http://" + listen
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
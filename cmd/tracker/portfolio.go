@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/portfolio"
+	"github.com/yourusername/solana-wallet-tracker/pkg/price"
+	"github.com/yourusername/solana-wallet-tracker/pkg/sns"
+)
+
+// newPortfolioCommand builds `tracker portfolio`, which prints the USD value
+// of the holdings of every configured wallet and of every wallet group
+func newPortfolioCommand() *cobra.Command {
+	var (
+		asJSON bool
+		group  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "portfolio",
+		Short: "Value the holdings of the configured wallets and groups in USD",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPortfolio(cmd.Context(), asJSON, group)
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the portfolio as JSON")
+	cmd.Flags().StringVar(&group, "group", "", "only value the wallets of this group")
+	return cmd
+}
+
+// runPortfolio values and prints the portfolio, limited to the wallets of a
+// group unless it is empty
+func runPortfolio(ctx context.Context, asJSON bool, group string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if len(cfg.Wallets) == 0 {
+		return fmt.Errorf("no wallets configured")
+	}
+
+	client, err := newClient(ctx, cfg.RPCEndpoint, cfg.WSEndpoint, cfg.HTTP)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	client.Commitments = commitments(cfg.Commitment)
+	if _, err := resolveConfigDomains(ctx, sns.NewResolver(client), cfg); err != nil {
+		return err
+	}
+
+	groups := walletGroups(cfg)
+	wallets := cfg.Wallets
+	if group != "" {
+		wallets = groups[group]
+		if len(wallets) == 0 {
+			return fmt.Errorf("no wallets in group %q", group)
+		}
+		groups = map[string][]string{group: wallets}
+	}
+
+	result, err := portfolio.Build(ctx, client, price.NewClient(cfg.PriceAPI), wallets)
+	if err != nil {
+		return err
+	}
+	result.Rollup(groups)
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	// Lines without tabs end a column block, so each wallet aligns on its own
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, wallet := range result.Wallets {
+		fmt.Fprintf(writer, "%s\n", wallet.Owner)
+		printHoldings(writer, wallet.Holdings, wallet.TotalUSD)
+	}
+	for _, group := range result.Groups {
+		fmt.Fprintf(writer, "Group %s (%d wallets)\n", group.Name, len(group.Wallets))
+		printHoldings(writer, group.Holdings, group.TotalUSD)
+	}
+	fmt.Fprintf(writer, "Portfolio total: %s\n", formatUSD(result.TotalUSD))
+
+	return writer.Flush()
+}
+
+// printHoldings prints a table of holdings followed by their total
+func printHoldings(writer io.Writer, holdings []portfolio.Holding, totalUSD float64) {
+	fmt.Fprintf(writer, "TOKEN\tAMOUNT\tPRICE\tVALUE\n")
+	for _, holding := range holdings {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n",
+			tokenLabel(holding), formatAmount(holding.Amount), formatPrice(holding.PriceUSD), formatUSD(holding.ValueUSD))
+	}
+	fmt.Fprintf(writer, "TOTAL\t\t\t%s\n\n", formatUSD(totalUSD))
+}
+
+// walletGroups returns the configured wallets of each group in
+// wallet_labels
+func walletGroups(cfg *config.Config) map[string][]string {
+	groups := make(map[string][]string)
+	for _, wallet := range cfg.Wallets {
+		for _, group := range cfg.WalletLabels[wallet].Groups {
+			groups[group] = append(groups[group], wallet)
+		}
+	}
+	return groups
+}
+
+// tokenLabel names a holding by symbol, falling back to a shortened mint
+func tokenLabel(holding portfolio.Holding) string {
+	if holding.Symbol != "" {
+		return holding.Symbol
+	}
+	if len(holding.Mint) > 8 {
+		return holding.Mint[:4] + "…" + holding.Mint[len(holding.Mint)-4:]
+	}
+	return holding.Mint
+}
+
+// formatAmount renders a UI amount without trailing zeros
+func formatAmount(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// formatPrice renders a USD price, keeping the precision of sub-cent prices,
+// or "-" when unknown
+func formatPrice(value *float64) string {
+	if value == nil {
+		return "-"
+	}
+	if *value >= 1 {
+		return formatUSD(*value)
+	}
+	return "$" + strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.10f", *value), "0"), ".")
+}
+
+// formatUSD renders a USD value with cents
+func formatUSD(value float64) string {
+	return fmt.Sprintf("$%.2f", value)
+}
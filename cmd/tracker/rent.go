@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/solana-wallet-tracker/pkg/notify"
+	"github.com/yourusername/solana-wallet-tracker/pkg/rent"
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+)
+
+// solDecimals is the number of decimals of native SOL
+const solDecimals = 9
+
+// newRentCommand builds `tracker rent`, which reports the rent the wallets
+// paid for new accounts and reclaimed from closed ones
+func newRentCommand() *cobra.Command {
+	var wallet, by string
+	var since time.Duration
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "rent",
+		Short: "Report the account rent paid and reclaimed by the wallets",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := openConfiguredStore()
+			if err != nil {
+				return err
+			}
+			defer st.Close()
+
+			rentStore, ok := st.(store.RentStore)
+			if !ok {
+				return fmt.Errorf("the configured store doesn't keep rent")
+			}
+
+			var from time.Time
+			if since > 0 {
+				from = time.Now().Add(-since)
+			}
+			entries, err := rentStore.RentEntries(context.Background(), from)
+			if err != nil {
+				return err
+			}
+			if wallet != "" {
+				address, err := resolveWallet(cmd.Context(), wallet)
+				if err != nil {
+					return err
+				}
+				entries = rent.ForWallet(entries, address)
+			}
+			summaries, err := rent.Summarize(entries, by)
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(summaries)
+			}
+
+			writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(writer, "WALLET\tPERIOD\tCREATED\tPAID (SOL)\tCLOSED\tRECLAIMED (SOL)\tNET (SOL)\n")
+			for _, summary := range summaries {
+				period := summary.Period
+				if period == "" {
+					period = "total"
+				}
+				net := notify.FormatAmount(uint64(summary.Net), solDecimals)
+				if summary.Net < 0 {
+					net = "-" + notify.FormatAmount(uint64(-summary.Net), solDecimals)
+				}
+				fmt.Fprintf(writer, "%s\t%s\t%d\t%s\t%d\t%s\t%s\n",
+					summary.Wallet, period,
+					summary.Created, notify.FormatAmount(summary.Paid, solDecimals),
+					summary.Closed, notify.FormatAmount(summary.Reclaimed, solDecimals),
+					net)
+			}
+			return writer.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&wallet, "wallet", "", "only report this wallet, by address or .sol domain")
+	cmd.Flags().StringVar(&by, "by", rent.ByMonth, "group by day, month or total")
+	cmd.Flags().DurationVar(&since, "since", 0, "how far back to look (default everything)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the report as JSON")
+	return cmd
+}
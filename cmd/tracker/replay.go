@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/sns"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// newReplayCommand builds `tracker replay`, which re-emits balance events
+// rebuilt from stored history through the event pipeline
+func newReplayCommand() *cobra.Command {
+	var from, to string
+
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Re-emit stored balance events through filters, enrichers and notifiers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			now := time.Now()
+			fromTime, err := parseTime(from, now)
+			if err != nil {
+				return fmt.Errorf("invalid --from: %w", err)
+			}
+			toTime := now
+			if to != "" {
+				if toTime, err = parseTime(to, now); err != nil {
+					return fmt.Errorf("invalid --to: %w", err)
+				}
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+			st, err := openConfiguredStore()
+			if err != nil {
+				return err
+			}
+			defer st.Close()
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			records, err := st.Records(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to read history: %w", err)
+			}
+			archived := make(map[string]bool)
+			wallets, err := st.Wallets(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to read wallets: %w", err)
+			}
+			for _, wallet := range wallets {
+				archived[wallet.Owner] = wallet.Archived()
+			}
+
+			// Archived wallets are no longer monitored, so their events aren't
+			// replayed
+			var events []monitor.Event
+			for _, event := range monitor.HistoryEvents(records, fromTime) {
+				if !archived[event.Account.Owner] && !event.Account.LastUpdatedAt.After(toTime) {
+					events = append(events, event)
+				}
+			}
+
+			// Enrichers describing the chain as it is now, such as transfers
+			// and risk scores, would mislabel past events and are left out
+			var client *solana.Client
+			if cfg.NewHoldings.Metadata || cfg.NewHoldings.RugCheck || cfg.HasDomains() {
+				if client, err = newClient(ctx, cfg.RPCEndpoint, cfg.WSEndpoint, cfg.HTTP); err != nil {
+					return fmt.Errorf("failed to initialize Solana client: %w", err)
+				}
+				defer client.Close()
+				client.Commitments = commitments(cfg.Commitment)
+			}
+			var domains map[string]string
+			if cfg.HasDomains() {
+				if domains, err = resolveConfigDomains(ctx, sns.NewResolver(client), cfg); err != nil {
+					return err
+				}
+			}
+
+			walletMonitor := monitor.New(client, monitor.WithWallets(cfg.Wallets...), monitor.WithTokens(cfg.Tokens...), monitor.WithLogger(logger))
+			labels := make(map[string]monitor.WalletLabel, len(cfg.WalletLabels))
+			for address, label := range cfg.WalletLabels {
+				labels[address] = monitor.WalletLabel{Label: label.Label, Groups: label.Groups}
+			}
+			walletMonitor.SetWalletLabels(labels)
+			walletMonitor.SetWalletDomains(domains)
+			if cfg.Dust.Enabled() {
+				walletMonitor.RegisterFilter(dustFilter(cfg))
+			}
+			if cfg.NewHoldings.Metadata {
+				walletMonitor.RegisterEnricher(monitor.MetadataEnricher(client))
+			}
+			if cfg.NewHoldings.RugCheck {
+				walletMonitor.RegisterEnricher(monitor.RugCheckEnricher(client, rugChecks(cfg)))
+			}
+
+			dispatcher, err := newDispatcher(cfg, "", st)
+			if err != nil {
+				return err
+			}
+			if dispatcher == nil {
+				return fmt.Errorf("no notifiers configured in %s", configSource())
+			}
+			if err := walletMonitor.RegisterNamedEventHandler("dispatcher", dispatcher.Handle); err != nil {
+				return err
+			}
+
+			logrus.WithFields(logrus.Fields{
+				"from":   fromTime.Format(time.RFC3339),
+				"to":     toTime.Format(time.RFC3339),
+				"events": len(events),
+			}).Info("Replaying stored events")
+
+			err = walletMonitor.Replay(ctx, events)
+			dispatcher.Flush()
+			if err != nil {
+				return fmt.Errorf("replay interrupted: %w", err)
+			}
+			logrus.WithField("events", len(events)).Info("Replay finished")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "replay events since this RFC 3339 time, date or duration ago such as 24h")
+	cmd.Flags().StringVar(&to, "to", "", "replay events until this RFC 3339 time, date or duration ago (default now)")
+	cmd.MarkFlagRequired("from")
+	return cmd
+}
+
+// parseTime parses an RFC 3339 time, a date (midnight UTC) or a duration
+// before now
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not an RFC 3339 time, date or duration", value)
+}
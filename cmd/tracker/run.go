@@ -0,0 +1,1227 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/solana-wallet-tracker/pkg/anomaly"
+	"github.com/yourusername/solana-wallet-tracker/pkg/api"
+	"github.com/yourusername/solana-wallet-tracker/pkg/backfill"
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/counterparty"
+	"github.com/yourusername/solana-wallet-tracker/pkg/das"
+	"github.com/yourusername/solana-wallet-tracker/pkg/entity"
+	"github.com/yourusername/solana-wallet-tracker/pkg/flow"
+	"github.com/yourusername/solana-wallet-tracker/pkg/geyser"
+	"github.com/yourusername/solana-wallet-tracker/pkg/handover"
+	"github.com/yourusername/solana-wallet-tracker/pkg/ingest"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/notify"
+	"github.com/yourusername/solana-wallet-tracker/pkg/preflight"
+	"github.com/yourusername/solana-wallet-tracker/pkg/price"
+	"github.com/yourusername/solana-wallet-tracker/pkg/risk"
+	"github.com/yourusername/solana-wallet-tracker/pkg/snapshot"
+	"github.com/yourusername/solana-wallet-tracker/pkg/sns"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+	"github.com/yourusername/solana-wallet-tracker/pkg/timeseries"
+	"github.com/yourusername/solana-wallet-tracker/pkg/watchdog"
+)
+
+// runTracker monitors the configured wallets until interrupted, or until the
+// user quits the dashboard of `tracker tui` when one is given
+func runTracker(dashboard liveDashboard) {
+	// Create default config file if not exists, unless the environment
+	// lists the wallets. A read-only file system, such as that of a
+	// container, leaves the configuration to the environment.
+	if configFile != "" && os.Getenv("MONITOR_WALLETS") == "" {
+		if err := config.CreateDefaultConfigFileAt(configFile); err != nil {
+			logrus.Warnf("Failed to create default config file, configuring from the environment: %v", err)
+		}
+	}
+
+	// Load configuration
+	cfg, err := loadConfig()
+	if err != nil {
+		logrus.Fatalf("Failed to load configuration: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		logrus.Fatal(err)
+	}
+
+	// Take over the API listener and state of a previous process, or the
+	// listener of systemd socket activation
+	successor := handover.Inherited()
+	listener, err := handover.Listener()
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	// Trace updates and export metrics, set up before the clients so their
+	// requests are traced
+	var tracing exporter
+	if cfg.Telemetry != nil {
+		if newTelemetry == nil {
+			logrus.Fatal(unavailable("telemetry", "nootlp"))
+		}
+		if tracing, err = newTelemetry(cfg.Telemetry); err != nil {
+			logrus.Fatal(err)
+		}
+		telemetryCtx, stopTelemetry := context.WithCancel(context.Background())
+		defer stopTelemetry()
+		go tracing.Run(telemetryCtx)
+	}
+
+	// Initialize Solana client
+	client, err := newClient(context.Background(), cfg.RPCEndpoint, cfg.WSEndpoint, cfg.HTTP)
+	if err != nil {
+		logrus.Fatalf("Failed to initialize Solana client: %v", err)
+	}
+	defer client.Close()
+	client.Commitments = commitments(cfg.Commitment)
+
+	// Resolve wallets given as .sol domains
+	resolver := sns.NewResolver(client)
+	domains, err := resolveConfigDomains(context.Background(), resolver, cfg)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	// Check the wallets, mints and endpoints against the chain
+	if !cfg.Preflight.Skip {
+		if err := preflight.Run(context.Background(), client, cfg, cfg.Preflight.MaxLatency.Duration()); err != nil {
+			logrus.Fatal(err)
+		}
+	}
+
+	// Initialize monitor
+	walletMonitor := monitor.New(client,
+		monitor.WithWallets(cfg.Wallets...),
+		monitor.WithTokens(cfg.Tokens...),
+		monitor.WithPollPool(pollPool(cfg.Polling)),
+		monitor.WithLogger(logger),
+	)
+	walletMonitor.SetWalletTokens(cfg.WalletTokens)
+	labels := make(map[string]monitor.WalletLabel, len(cfg.WalletLabels))
+	for address, label := range cfg.WalletLabels {
+		labels[address] = monitor.WalletLabel{Label: label.Label, Groups: label.Groups}
+	}
+	for _, tenant := range cfg.Tenants {
+		for _, wallet := range tenant.Wallets {
+			label := labels[wallet]
+			label.Tenants = append(label.Tenants, tenant.Name)
+			labels[wallet] = label
+		}
+	}
+	walletMonitor.SetWalletLabels(labels)
+	walletMonitor.SetWalletDomains(domains)
+	if cfg.TagsNetworks() {
+		walletMonitor.SetNetwork(cfg.NetworkName())
+	}
+	if len(cfg.HotWallets) > 0 {
+		hot := make(map[string]*solana.Client, len(cfg.HotWallets))
+		for _, wallet := range cfg.HotWallets {
+			hotClient, err := client.Dedicated(context.Background(), rpc.CommitmentProcessed)
+			if err != nil {
+				logrus.Fatalf("Failed to connect hot wallet %s: %v", wallet, err)
+			}
+			defer hotClient.Close()
+			hot[wallet] = hotClient
+		}
+		walletMonitor.SetHotWallets(hot)
+	}
+	if cfg.EventDelivery != "" {
+		walletMonitor.SetDeliveryMode(monitor.DeliveryMode(cfg.EventDelivery))
+	}
+	if timeout := cfg.HandlerTimeout.Duration(); timeout > 0 {
+		walletMonitor.SetHandlerTimeout(timeout)
+	}
+	if cfg.Backpressure != nil {
+		low := cfg.Backpressure.LowWater
+		if low == 0 {
+			low = cfg.Backpressure.HighWater / 2
+		}
+		walletMonitor.SetBackpressure(cfg.Backpressure.HighWater, low)
+	}
+	if ttl := cfg.ZeroBalanceTTL.Duration(); ttl > 0 {
+		walletMonitor.SetZeroBalanceTTL(ttl)
+	}
+	if cfg.MeasureLatency {
+		walletMonitor.MeasureLatency()
+	}
+	if window := cfg.StaleAfter.Duration(); window > 0 {
+		walletMonitor.SetStaleAfter(window)
+	}
+	if !cfg.KeepWrappedSOL {
+		walletMonitor.FoldWrappedSOL()
+	}
+	if cfg.TrackDelegates {
+		walletMonitor.TrackDelegates()
+	}
+	if cfg.WatchAuthorities {
+		walletMonitor.WatchAuthorities()
+	}
+	if cfg.TrackFreezes {
+		walletMonitor.TrackFreezes()
+	}
+	if cfg.WatchOnly {
+		walletMonitor.WatchOnly()
+	}
+	if cfg.Ingest != nil {
+		walletMonitor.IngestOnly()
+	}
+	if cfg.Geyser != nil {
+		geyserClient, err := geyser.NewClient(cfg.Geyser.Endpoint, cfg.Geyser.Token)
+		if err != nil {
+			logrus.Fatalf("Failed to initialize geyser client: %v", err)
+		}
+		walletMonitor.SetDataSource(geyser.NewSource(geyserClient, client))
+		walletMonitor.SkipTokenPolls()
+	}
+	if cfg.StakeAccounts {
+		walletMonitor.EnableStakeMonitoring()
+	}
+	if cfg.NFTs.Enabled {
+		endpoint := cfg.NFTs.DASEndpoint
+		if endpoint == "" {
+			endpoint = cfg.RPCEndpoint
+		}
+		walletMonitor.EnableNFTMonitoring(das.NewClient(endpoint))
+	}
+	if cfg.NFTs.CompressedLogs {
+		walletMonitor.EnableCompressedNFTLogs()
+	}
+	for _, watch := range cfg.MintWatches {
+		walletMonitor.AddMintWatch(monitor.MintWatch{Mint: watch.Mint, Threshold: watch.Threshold})
+	}
+	// prices value group totals, API balances and time series points
+	var prices *price.Cache
+	if len(cfg.GroupRules) > 0 || cfg.API != nil || cfg.TimeSeries != nil {
+		prices = price.NewCache(price.NewClient(cfg.PriceAPI), 0, logger)
+		walletMonitor.SetPrices(prices)
+	}
+	for _, rule := range cfg.GroupRules {
+		walletMonitor.AddGroupRule(monitor.GroupRule{
+			Group: rule.Group,
+			Mint:  rule.Mint,
+			Below: rule.Below,
+			Above: rule.Above,
+		})
+	}
+
+	// Register a handler for balance changes
+	mustRegister(walletMonitor.RegisterNamedHandler("log", func(accountInfo solana.TokenAccountInfo) {
+		logrus.WithFields(logrus.Fields{
+			"address":  accountInfo.Address,
+			"owner":    accountInfo.Owner,
+			"label":    walletMonitor.WalletLabel(accountInfo.Owner).Label,
+			"mint":     accountInfo.Mint,
+			"balance":  accountInfo.Balance,
+			"decimals": accountInfo.Decimals,
+		}).Info("Token balance updated")
+
+		// Here you can add code to notify other systems:
+		// - Send message to message queue
+		// - Call webhook
+		// - Update database
+		// - etc.
+	}))
+
+	// Persist balance history if a store is configured
+	var history store.Store
+	if cfg.Store != nil {
+		history, err = store.New(*cfg.Store, logger)
+		if err != nil {
+			logrus.Fatalf("Failed to open store: %v", err)
+		}
+		defer history.Close()
+
+		// Archive history of wallets removed from the config
+		archived, restored, err := store.SyncWallets(context.Background(), history, cfg.AllWallets())
+		if err != nil {
+			logrus.Errorf("Failed to sync archived wallets: %v", err)
+		}
+		for _, wallet := range archived {
+			logrus.WithField("wallet", wallet).Info("Wallet removed from config, history archived")
+		}
+		for _, wallet := range restored {
+			logrus.WithField("wallet", wallet).Info("Wallet added back to config, history restored")
+		}
+
+		// Give wallets without history their recent past
+		if cfg.Backfill > 0 {
+			backfillWallets(context.Background(), client, history, cfg)
+		}
+
+		mustRegister(walletMonitor.RegisterNamedHandler("history", func(accountInfo solana.TokenAccountInfo) {
+			if err := history.SaveBalance(context.Background(), accountInfo); err != nil {
+				logrus.Errorf("Failed to save balance history: %v", err)
+			}
+		}))
+		walletMonitor.SetHoldingLookup(func(owner, mint string) bool {
+			held, err := history.HasHeld(context.Background(), owner, mint)
+			if err != nil {
+				logrus.Errorf("Failed to look up holding history: %v", err)
+			}
+			return held
+		})
+	}
+
+	// Record the rent wallets pay for new accounts and reclaim from closed ones
+	var rentStore store.RentStore
+	if cfg.TrackRent {
+		var ok bool
+		if rentStore, ok = history.(store.RentStore); !ok {
+			logrus.Fatalf("Store type %q does not support rent tracking", cfg.Store.Type)
+		}
+		walletMonitor.TrackRent(func(wallet string, tx *solana.Transaction, change solana.RentChange) {
+			at := tx.BlockTime
+			if at.IsZero() {
+				at = time.Now()
+			}
+			err := rentStore.AddRentEntry(context.Background(), store.RentEntry{
+				Wallet:    wallet,
+				Account:   change.Account,
+				Signature: tx.Signature,
+				Lamports:  change.Lamports,
+				Reclaimed: change.Reclaimed,
+				At:        at.UTC(),
+			})
+			if err != nil {
+				logrus.Errorf("Failed to save rent entry: %v", err)
+			}
+		})
+	}
+
+	// Index labels, notes and memos for full-text search
+	var searcher store.Searcher
+	if cfg.Store != nil && cfg.Store.Search {
+		var ok bool
+		if searcher, ok = history.(store.Searcher); !ok {
+			logrus.Fatalf("Store type %q does not support search", cfg.Store.Type)
+		}
+		indexWalletLabels(context.Background(), searcher, cfg.WalletLabels)
+
+		mustRegister(walletMonitor.RegisterNamedEventHandler("search index", func(event monitor.Event) {
+			if event.Transfer == nil || event.Transfer.Memo == "" {
+				return
+			}
+			err := searcher.IndexDocument(context.Background(), store.Document{
+				Kind:   store.DocumentMemo,
+				Key:    event.Transfer.Signature,
+				Wallet: event.Account.Owner,
+				Text:   event.Transfer.Memo,
+				At:     event.Transfer.BlockTime,
+			})
+			if err != nil {
+				logrus.Errorf("Failed to index memo: %v", err)
+			}
+		}))
+	}
+
+	// Drop events of dust balances
+	if cfg.Dust.Enabled() {
+		walletMonitor.RegisterFilter(dustFilter(cfg))
+	}
+
+	// Enrich new holding events
+	if cfg.NewHoldings.Metadata {
+		walletMonitor.RegisterEnricher(monitor.MetadataEnricher(client))
+	}
+	if cfg.NewHoldings.RugCheck {
+		walletMonitor.RegisterEnricher(monitor.RugCheckEnricher(client, rugChecks(cfg)))
+	}
+
+	// Resolve the transactions behind balance changes
+	if cfg.ResolveTransfers || cfg.DetectBurns || cfg.Counterparties.Enabled ||
+		(cfg.Risk.Enabled && len(cfg.Risk.Exchanges) > 0) || searcher != nil || cfg.Entities != nil {
+		walletMonitor.RegisterEnricher(monitor.TransferEnricher(client))
+	}
+
+	// Name counterparties that are known entities
+	entityCtx, stopEntities := context.WithCancel(context.Background())
+	defer stopEntities()
+	if cfg.Entities != nil {
+		directory, err := newEntityDirectory(entityCtx, cfg.Entities)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		walletMonitor.RegisterEnricher(monitor.EntityEnricher(directory))
+	}
+	if cfg.ResolveSignatures {
+		walletMonitor.RegisterEnricher(monitor.SignatureEnricher(client))
+	}
+
+	// Score wallet risk
+	var scorer *risk.Scorer
+	if cfg.Risk.Enabled {
+		scorer = risk.NewScorer(client, risk.Options{
+			Exchanges:    cfg.Risk.Exchanges,
+			DormantAfter: cfg.Risk.DormantAfter.Duration(),
+			Window:       cfg.Risk.Window.Duration(),
+		}, walletMonitor.GetCurrentState, logger)
+		scorer.Seed(context.Background(), cfg.Wallets)
+		walletMonitor.RegisterEnricher(monitor.WalletRiskEnricher(scorer))
+	}
+
+	// Flag transfers far outside the usual sizes of the wallet and mint
+	if cfg.Anomalies != nil {
+		stats, ok := history.(store.StatsStore)
+		if !ok {
+			logrus.Fatalf("Store type %q does not support anomaly detection", cfg.Store.Type)
+		}
+		walletMonitor.RegisterEnricher(monitor.AnomalyEnricher(anomaly.NewDetector(stats, anomaly.Options{
+			Sigma:         cfg.Anomalies.Sigma,
+			HoldingsShare: cfg.Anomalies.HoldingsShare,
+			MinSamples:    cfg.Anomalies.MinSamples,
+			Window:        cfg.Anomalies.Window,
+		})))
+	}
+
+	// Tell burns apart from outgoing transfers once balance changes are read
+	if cfg.DetectBurns {
+		walletMonitor.RegisterEnricher(monitor.BurnEnricher())
+	}
+
+	// Assign severities once events are enriched
+	if len(cfg.SeverityRules) > 0 {
+		walletMonitor.Use(monitor.PhaseRoute, notify.SeverityStage(severityRules(cfg)))
+	}
+
+	// Track counterparties of transfers
+	var counterparties *counterparty.Tracker
+	if cfg.Counterparties.Enabled {
+		counterparties = counterparty.NewTracker(cfg.Counterparties.Retention.Duration())
+		mustRegister(walletMonitor.RegisterNamedEventHandler("counterparties", func(event monitor.Event) {
+			counterparties.Record(event.Account, event.Previous, event.Transfer)
+		}))
+	}
+
+	// Take scheduled balance snapshots
+	var snapshots *snapshot.Store
+	if cfg.Snapshots != nil {
+		dir := cfg.Snapshots.Dir
+		if dir == "" {
+			dir = "snapshots"
+		}
+		if snapshots, err = snapshot.NewStore(dir); err != nil {
+			logrus.Fatalf("Failed to open snapshot store: %v", err)
+		}
+	}
+
+	// Register configured notification channels
+	dispatcher, err := newDispatcher(cfg, "", history)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	if dispatcher != nil {
+		mustRegister(walletMonitor.RegisterNamedEventHandler("dispatcher", dispatcher.Handle))
+	}
+
+	// Deliver the events of tenant wallets to the notifiers of their tenants
+	tenantDispatchers := make(map[string]*notify.Dispatcher)
+	for _, tenant := range cfg.Tenants {
+		tenantDispatcher, err := newDispatcher(cfg, tenant.Name, history)
+		if err != nil {
+			logrus.Fatalf("Failed to set up the notifiers of tenant %s: %v", tenant.Name, err)
+		}
+		if tenantDispatcher != nil {
+			tenantDispatchers[tenant.Name] = tenantDispatcher
+		}
+	}
+	routeTenants := func(event monitor.Event) {
+		for _, tenant := range event.Tenants {
+			if tenantDispatcher, ok := tenantDispatchers[tenant]; ok {
+				tenantDispatcher.Handle(event)
+			}
+		}
+	}
+	if len(tenantDispatchers) > 0 {
+		mustRegister(walletMonitor.RegisterNamedEventHandler("tenants", routeTenants))
+	}
+
+	// Report the health of the tracker to its own channel
+	var dog *watchdog.Watchdog
+	if cfg.Watchdog != nil {
+		channel, err := notify.New(cfg.Watchdog.Channel, nil, logger)
+		if err != nil {
+			logrus.Fatalf("Failed to initialize watchdog channel: %v", err)
+		}
+		dog = watchdog.New(channel, client.Ping, walletMonitor.ActiveWallets, watchdog.Options{
+			Heartbeat:     cfg.Watchdog.Heartbeat.Duration(),
+			Silence:       cfg.Watchdog.Silence.Duration(),
+			RPCFailures:   cfg.Watchdog.RPCFailures,
+			CheckInterval: cfg.Watchdog.CheckInterval.Duration(),
+		}, logger)
+		mustRegister(walletMonitor.RegisterNamedEventHandler("watchdog", dog.Observe))
+	}
+
+	// Watch the wallets of the other networks, whose events are stored,
+	// notified and observed by the watchdog like those of the top-level one
+	var networkMonitors []*monitor.Monitor
+	for _, network := range cfg.Networks {
+		networkClient, err := newClient(context.Background(), network.RPCEndpoint, network.WSEndpoint, cfg.NetworkHTTP(network))
+		if err != nil {
+			logrus.Fatalf("Failed to initialize Solana client of network %s: %v", network.Name, err)
+		}
+		defer networkClient.Close()
+		networkClient.Commitments = client.Commitments
+
+		networkMonitor := newNetworkMonitor(cfg, network, networkClient, labels)
+		if history != nil {
+			mustRegister(networkMonitor.RegisterNamedHandler("history", func(accountInfo solana.TokenAccountInfo) {
+				if err := history.SaveBalance(context.Background(), accountInfo); err != nil {
+					logrus.Errorf("Failed to save balance history: %v", err)
+				}
+			}))
+		}
+		if dispatcher != nil {
+			mustRegister(networkMonitor.RegisterNamedEventHandler("dispatcher", dispatcher.Handle))
+		}
+		if len(tenantDispatchers) > 0 {
+			mustRegister(networkMonitor.RegisterNamedEventHandler("tenants", routeTenants))
+		}
+		if dog != nil {
+			mustRegister(networkMonitor.RegisterNamedEventHandler("watchdog", dog.Observe))
+		}
+		networkMonitors = append(networkMonitors, networkMonitor)
+	}
+
+	// Show the balances and events of every network on the dashboard
+	if dashboard != nil {
+		names := make(map[string]string, len(labels)+len(domains))
+		for wallet, domain := range domains {
+			names[wallet] = domain
+		}
+		for wallet, label := range labels {
+			if label.Label != "" {
+				names[wallet] = label.Label
+			}
+		}
+		dashboard.SetLabels(names)
+		for _, m := range append([]*monitor.Monitor{walletMonitor}, networkMonitors...) {
+			mustRegister(m.RegisterNamedEventHandler("dashboard", dashboard.Handle))
+			dashboard.AddSource(m.GetCurrentState)
+		}
+	}
+
+	// Write balance points of every network to the time-series database
+	var sink *timeseries.Sink
+	if cfg.TimeSeries != nil {
+		writer, err := timeseries.New(*cfg.TimeSeries)
+		if err != nil {
+			logrus.Fatalf("Failed to open time series: %v", err)
+		}
+		defer writer.Close()
+		sink = timeseries.NewSink(writer, prices, cfg.TimeSeries.Interval.Duration(), logger)
+		for _, m := range append([]*monitor.Monitor{walletMonitor}, networkMonitors...) {
+			m := m
+			mustRegister(m.RegisterNamedEventHandler("time series", sink.Handle))
+			sink.AddSource(timeseries.Source{
+				Network: m.Network(),
+				State:   m.GetCurrentState,
+				Label:   func(wallet string) string { return m.WalletLabel(wallet).Label },
+			})
+		}
+	}
+
+	// Insert events and transactions of every network into ClickHouse
+	var analytics eventExporter
+	if cfg.ClickHouse != nil {
+		if newAnalytics == nil {
+			logrus.Fatal(unavailable("ClickHouse", "noclickhouse"))
+		}
+		if analytics, err = newAnalytics(context.Background(), cfg.ClickHouse); err != nil {
+			logrus.Fatal(err)
+		}
+		for _, m := range append([]*monitor.Monitor{walletMonitor}, networkMonitors...) {
+			mustRegister(m.RegisterNamedEventHandler("clickhouse", analytics.Handle))
+		}
+	}
+
+	// Keep the latest events of every network for the web dashboard
+	var eventLog *api.EventLog
+	if cfg.API != nil {
+		eventLog = api.NewEventLog(api.DefaultEventLogSize)
+		for _, m := range append([]*monitor.Monitor{walletMonitor}, networkMonitors...) {
+			mustRegister(m.RegisterNamedEventHandler("event log", eventLog.Handle))
+		}
+	}
+
+	// Persist every event under an increasing ID for consumers catching up
+	// over the API
+	var eventStore store.EventStore
+	if cfg.Store != nil && cfg.Store.Events {
+		var ok bool
+		if eventStore, ok = history.(store.EventStore); !ok {
+			logrus.Fatalf("Store type %q does not support the event log", cfg.Store.Type)
+		}
+		for _, m := range append([]*monitor.Monitor{walletMonitor}, networkMonitors...) {
+			mustRegister(m.RegisterNamedEventHandler("event store", storeEvent(eventStore)))
+		}
+	}
+
+	// Turn off the handlers and notifiers disabled in the configuration,
+	// again on SIGHUP
+	toggles := &handlerToggles{
+		monitors:    append([]*monitor.Monitor{walletMonitor}, networkMonitors...),
+		dispatchers: make(map[string]*notify.Dispatcher),
+	}
+	if dispatcher != nil {
+		toggles.dispatchers[""] = dispatcher
+	}
+	for tenant, tenantDispatcher := range tenantDispatchers {
+		toggles.dispatchers[tenant] = tenantDispatcher
+	}
+	toggles.apply(cfg)
+	go reloadOnHangup(toggles)
+
+	// The previous process stops monitoring once this one is set up
+	tookOver := false
+	// tookOverNetworks names the further networks whose state was handed
+	// over
+	tookOverNetworks := make(map[string]bool)
+	if successor != nil {
+		state, err := successor.TakeOver(handover.DefaultTimeout)
+		if err != nil {
+			logrus.Errorf("Failed to take over from the previous process, starting afresh: %v", err)
+		} else {
+			walletMonitor.SeedState(state.Accounts)
+			for _, networkMonitor := range networkMonitors {
+				if accounts, ok := state.Networks[networkMonitor.Network()]; ok {
+					networkMonitor.SeedState(accounts)
+					tookOverNetworks[networkMonitor.Network()] = true
+				}
+			}
+			tookOver = true
+			logrus.WithFields(logrus.Fields{
+				"accounts": len(state.Accounts),
+				"since":    state.At,
+			}).Info("Took over from the previous process")
+		}
+	}
+
+	// Only the first start loads the initial state silently; later ones
+	// report the changes since the balances last stored
+	var seedMarker store.SeedMarker
+	if cfg.SeedSilently {
+		var seeding []*monitor.Monitor
+		if !tookOver {
+			seeding = append(seeding, walletMonitor)
+		}
+		for _, networkMonitor := range networkMonitors {
+			if !tookOverNetworks[networkMonitor.Network()] {
+				seeding = append(seeding, networkMonitor)
+			}
+		}
+		seedMarker = seedMonitors(history, seeding)
+	}
+
+	// Start the monitor
+	if err := walletMonitor.Start(context.Background()); err != nil {
+		logrus.Fatalf("Failed to start monitor: %v", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"network": cfg.NetworkName(),
+		"wallets": cfg.Wallets,
+		"tokens":  cfg.Tokens,
+	}).Info("Started monitoring token balances")
+	for i, networkMonitor := range networkMonitors {
+		network := cfg.Networks[i]
+		if err := networkMonitor.Start(context.Background()); err != nil {
+			logrus.Fatalf("Failed to start monitor of network %s: %v", network.Name, err)
+		}
+		logrus.WithFields(logrus.Fields{
+			"network": network.Name,
+			"wallets": network.Wallets,
+			"tokens":  network.Tokens,
+		}).Info("Started monitoring token balances")
+	}
+	if seedMarker != nil {
+		if err := seedMarker.MarkSeeded(context.Background(), time.Now()); err != nil {
+			logrus.Errorf("Failed to mark the initial state as seeded: %v", err)
+		}
+	}
+
+	snapshotCtx, stopSnapshots := context.WithCancel(context.Background())
+	defer stopSnapshots()
+	if snapshots != nil {
+		scheduler := snapshot.NewScheduler(snapshots, walletMonitor.GetCurrentState,
+			cfg.Snapshots.Interval.Duration(), cfg.Snapshots.Offset.Duration(), logger)
+		for _, networkMonitor := range networkMonitors {
+			scheduler.AddNetwork(networkMonitor.Network(), networkMonitor.GetCurrentState)
+		}
+		go scheduler.Run(snapshotCtx)
+	}
+
+	// Send the net flows of every day or week to the notifiers
+	reportCtx, stopReports := context.WithCancel(context.Background())
+	defer stopReports()
+	if cfg.FlowReport != nil && eventStore != nil && dispatcher != nil {
+		scheduler, err := flow.NewScheduler(eventStore, cfg.FlowReport.Period,
+			cfg.FlowReport.Offset.Duration(), dispatcher.SendFlowReport, logger)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		go scheduler.Run(reportCtx)
+	}
+
+	sinkCtx, stopSink := context.WithCancel(context.Background())
+	defer stopSink()
+	if sink != nil {
+		go sink.Run(sinkCtx)
+	}
+	if analytics != nil {
+		go analytics.Run(sinkCtx)
+	}
+
+	watchdogCtx, stopWatchdog := context.WithCancel(context.Background())
+	defer stopWatchdog()
+	if dog != nil {
+		go dog.Run(watchdogCtx)
+	}
+
+	// Follow the domains of monitored wallets to their new owners
+	domainCtx, stopDomains := context.WithCancel(context.Background())
+	defer stopDomains()
+	var names []string
+	for _, wallet := range cfg.Wallets {
+		if domain, ok := domains[wallet]; ok {
+			names = append(names, domain)
+		}
+	}
+	if len(names) > 0 {
+		go resolver.Watch(domainCtx, cfg.DomainRefresh.Duration(), names, func(domain, previous, current string) {
+			if err := walletMonitor.ReplaceWallet(domainCtx, previous, current); err != nil {
+				logrus.WithField("domain", domain).Errorf("Failed to follow domain to its new owner: %v", err)
+			}
+		})
+	}
+
+	// Serve the HTTP API
+	var apiServer *api.Server
+	if cfg.API != nil {
+		apiServer = api.NewServer(cfg.API.Listen, logger)
+		wallets := make([]api.Wallet, 0, len(cfg.Wallets))
+		for _, wallet := range cfg.Wallets {
+			label := labels[wallet]
+			wallets = append(wallets, api.Wallet{
+				Address: wallet,
+				Domain:  domains[wallet],
+				Label:   label.Label,
+				Groups:  label.Groups,
+				Note:    cfg.WalletLabels[wallet].Note,
+				Tenants: label.Tenants,
+			})
+		}
+		apiServer.SetWallets(wallets)
+		tenants := make([]api.Tenant, 0, len(cfg.Tenants))
+		for _, tenant := range cfg.Tenants {
+			tenants = append(tenants, api.Tenant{Name: tenant.Name, Keys: tenant.APIKeys})
+		}
+		apiServer.SetAPIKeys(cfg.API.Keys, tenants)
+		apiServer.SetWalletController(walletMonitor)
+		apiServer.SetGroupSource(walletMonitor)
+		apiServer.SetAccountInspector(walletMonitor)
+		apiServer.SetHealthSource(walletMonitor)
+		apiServer.SetHandlerController(toggles)
+		if cfg.Ingest != nil {
+			apiServer.SetIngestor(walletMonitor, ingest.Secrets{
+				HeliusAuth:   cfg.Ingest.HeliusAuth,
+				QuickNodeKey: cfg.Ingest.QuickNodeKey,
+			})
+		}
+		balances := []api.BalanceSource{walletMonitor}
+		for _, networkMonitor := range networkMonitors {
+			balances = append(balances, networkMonitor)
+		}
+		apiServer.SetBalanceSources(balances...)
+		apiServer.SetEventLog(eventLog)
+		if cfg.Backpressure != nil {
+			apiServer.SetBackpressureSource(walletMonitor)
+		}
+		if cfg.MeasureLatency {
+			latency := []api.LatencySource{walletMonitor}
+			for _, networkMonitor := range networkMonitors {
+				latency = append(latency, networkMonitor)
+			}
+			apiServer.SetLatencySources(latency...)
+		}
+		apiServer.SetDomainResolver(resolver)
+		if searcher != nil {
+			apiServer.SetSearcher(searcher)
+		}
+		if scorer != nil {
+			apiServer.SetRiskScorer(scorer)
+		}
+		if counterparties != nil {
+			apiServer.SetCounterpartyTracker(counterparties)
+		}
+		if snapshots != nil {
+			apiServer.SetSnapshotStore(snapshots)
+		}
+		if rentStore != nil {
+			apiServer.SetRentStore(rentStore)
+		}
+		if eventStore != nil {
+			apiServer.SetEventStore(eventStore)
+		}
+		if err := apiServer.Start(listener); err != nil {
+			logrus.Fatal(err)
+		}
+	} else if listener != nil {
+		listener.Close()
+	}
+
+	if err := handover.Notify(fmt.Sprintf("READY=1\nMAINPID=%d", os.Getpid())); err != nil {
+		logrus.Warn(err)
+	}
+
+	// Wait for the user to quit the dashboard, or for interrupt signal,
+	// handing over to a new process on request
+	if dashboard != nil {
+		if err := dashboard.Run(context.Background()); err != nil {
+			logrus.Errorf("Dashboard failed: %v", err)
+		}
+	} else {
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, append([]os.Signal{syscall.SIGINT, syscall.SIGTERM}, handover.Signals...)...)
+		for sig := range quit {
+			if sig == syscall.SIGINT || sig == syscall.SIGTERM {
+				break
+			}
+			if err := handOver(apiServer, walletMonitor, networkMonitors); err != nil {
+				logrus.Errorf("Handover failed, carrying on: %v", err)
+				continue
+			}
+			break
+		}
+	}
+
+	// Shutdown gracefully
+	logrus.Info("Shutting down...")
+
+	if apiServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		apiServer.Shutdown(ctx)
+		cancel()
+	}
+	walletMonitor.Stop()
+	for _, networkMonitor := range networkMonitors {
+		networkMonitor.Stop()
+	}
+	if dispatcher != nil {
+		dispatcher.Flush()
+	}
+	for _, tenantDispatcher := range tenantDispatchers {
+		tenantDispatcher.Flush()
+	}
+	if analytics != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		analytics.Flush(ctx)
+		cancel()
+	}
+	if tracing != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		tracing.Flush(ctx)
+		cancel()
+	}
+	logrus.Info("Solana wallet tracker stopped")
+}
+
+// handOver starts a new process of the tracker and passes it the API
+// listener and the token account state once it is set up. This process then
+// only has to shut down. The monitors of other networks don't hand over their
+// state; the new process loads it afresh.
+func handOver(apiServer *api.Server, walletMonitor *monitor.Monitor, networkMonitors []*monitor.Monitor) error {
+	logrus.Info("Handing over to a new process...")
+
+	var listener net.Listener
+	if apiServer != nil {
+		listener = apiServer.Listener()
+	}
+	return handover.Start(listener, func() handover.State {
+		walletMonitor.Stop()
+		for _, networkMonitor := range networkMonitors {
+			networkMonitor.Stop()
+		}
+		state := handover.State{At: time.Now()}
+		for _, account := range walletMonitor.GetCurrentState() {
+			state.Accounts = append(state.Accounts, account)
+		}
+		for _, networkMonitor := range networkMonitors {
+			if state.Networks == nil {
+				state.Networks = make(map[string][]solana.TokenAccountInfo, len(networkMonitors))
+			}
+			for _, account := range networkMonitor.GetCurrentState() {
+				state.Networks[networkMonitor.Network()] = append(state.Networks[networkMonitor.Network()], account)
+			}
+		}
+		return state
+	}, handover.DefaultTimeout)
+}
+
+// newNetworkMonitor creates the monitor of a network watched alongside the
+// top-level one. It shares the labels, event delivery, polling pool,
+// pruning, staleness and severity settings; the other monitoring features
+// only cover the top-level network.
+func newNetworkMonitor(cfg *config.Config, network config.NetworkConfig, client *solana.Client,
+	labels map[string]monitor.WalletLabel) *monitor.Monitor {
+	networkMonitor := monitor.New(client,
+		monitor.WithWallets(network.Wallets...),
+		monitor.WithTokens(network.Tokens...),
+		monitor.WithPollPool(pollPool(cfg.Polling)),
+		monitor.WithLogger(logger),
+	)
+	networkMonitor.SetNetwork(network.Name)
+	networkMonitor.SetWalletLabels(labels)
+	if cfg.EventDelivery != "" {
+		networkMonitor.SetDeliveryMode(monitor.DeliveryMode(cfg.EventDelivery))
+	}
+	if timeout := cfg.HandlerTimeout.Duration(); timeout > 0 {
+		networkMonitor.SetHandlerTimeout(timeout)
+	}
+	if ttl := cfg.ZeroBalanceTTL.Duration(); ttl > 0 {
+		networkMonitor.SetZeroBalanceTTL(ttl)
+	}
+	if cfg.MeasureLatency {
+		networkMonitor.MeasureLatency()
+	}
+	if window := cfg.StaleAfter.Duration(); window > 0 {
+		networkMonitor.SetStaleAfter(window)
+	}
+	if !cfg.KeepWrappedSOL {
+		networkMonitor.FoldWrappedSOL()
+	}
+	if cfg.TrackDelegates {
+		networkMonitor.TrackDelegates()
+	}
+	if cfg.WatchAuthorities {
+		networkMonitor.WatchAuthorities()
+	}
+	if cfg.TrackFreezes {
+		networkMonitor.TrackFreezes()
+	}
+	if cfg.WatchOnly {
+		networkMonitor.WatchOnly()
+	}
+	if len(cfg.SeverityRules) > 0 {
+		networkMonitor.Use(monitor.PhaseRoute, notify.SeverityStage(severityRules(cfg)))
+	}
+
+	mustRegister(networkMonitor.RegisterNamedHandler("log", func(accountInfo solana.TokenAccountInfo) {
+		logrus.WithFields(logrus.Fields{
+			"network":  network.Name,
+			"address":  accountInfo.Address,
+			"owner":    accountInfo.Owner,
+			"label":    networkMonitor.WalletLabel(accountInfo.Owner).Label,
+			"mint":     accountInfo.Mint,
+			"balance":  accountInfo.Balance,
+			"decimals": accountInfo.Decimals,
+		}).Info("Token balance updated")
+	}))
+	return networkMonitor
+}
+
+// seedMonitors prepares the silent seeding of the initial state. Before
+// the store is marked seeded, or without a store, the monitors load it
+// silently; afterwards they start from the latest stored balances, so the
+// changes since are reported. It returns the marker to set once the first
+// load is done, nil when there is none to set.
+func seedMonitors(history store.Store, monitors []*monitor.Monitor) store.SeedMarker {
+	silently := func() {
+		for _, m := range monitors {
+			m.SeedSilently()
+		}
+	}
+
+	marker, ok := history.(store.SeedMarker)
+	if !ok {
+		silently()
+		return nil
+	}
+	ctx := context.Background()
+	seededAt, err := marker.SeededAt(ctx)
+	if err != nil {
+		logrus.Errorf("Failed to read the seeded marker, loading the initial state silently: %v", err)
+		silently()
+		return nil
+	}
+	if seededAt.IsZero() {
+		silently()
+		return marker
+	}
+
+	records, err := history.Records(ctx)
+	if err != nil {
+		logrus.Errorf("Failed to read stored balances, loading the initial state silently: %v", err)
+		silently()
+		return nil
+	}
+	latest := store.LatestBalances(records)
+	for _, m := range monitors {
+		m.SeedState(latest)
+	}
+	logrus.WithFields(logrus.Fields{
+		"accounts":  len(latest),
+		"seeded_at": seededAt,
+	}).Info("Starting from the stored balances")
+	return nil
+}
+
+// backfillWallets stores the recent balance history of configured wallets
+// that have none yet
+func backfillWallets(ctx context.Context, client *solana.Client, history store.Store, cfg *config.Config) {
+	known, err := history.Wallets(ctx)
+	if err != nil {
+		logrus.Errorf("Failed to list wallets with history: %v", err)
+		return
+	}
+	hasHistory := make(map[string]bool, len(known))
+	for _, wallet := range known {
+		hasHistory[wallet.Owner] = true
+	}
+
+	for _, wallet := range cfg.Wallets {
+		if hasHistory[wallet] {
+			continue
+		}
+		records, err := backfill.Wallet(ctx, client, history, wallet, cfg.TokensFor(wallet), cfg.Backfill)
+		if err != nil {
+			logrus.WithField("wallet", wallet).Errorf("Failed to backfill history: %v", err)
+			continue
+		}
+		logrus.WithFields(logrus.Fields{
+			"wallet":  wallet,
+			"records": records,
+		}).Info("Backfilled balance history")
+	}
+}
+
+// newEntityDirectory loads the configured entity file and fetches the remote
+// list, refreshing it until ctx is cancelled. A remote list that can't be
+// fetched at startup is retried at the next refresh.
+func newEntityDirectory(ctx context.Context, cfg *config.EntitiesConfig) (*entity.Directory, error) {
+	var entities []entity.Entity
+	if cfg.File != "" {
+		var err error
+		if entities, err = entity.LoadFile(cfg.File); err != nil {
+			return nil, err
+		}
+	}
+
+	directory := entity.NewDirectory(entities, logger)
+	if cfg.URL != "" {
+		if err := directory.Fetch(ctx, cfg.URL); err != nil {
+			logrus.Warnf("Failed to fetch entity list, retrying at the next refresh: %v", err)
+		}
+		go directory.Watch(ctx, cfg.URL, cfg.Refresh.Duration())
+	}
+	return directory, nil
+}
+
+// commitments converts the configured commitment levels
+func commitments(cfg config.CommitmentConfig) solana.Commitments {
+	return solana.Commitments{
+		Subscriptions: rpc.CommitmentType(cfg.Subscriptions),
+		Polling:       rpc.CommitmentType(cfg.Polling),
+		Transactions:  rpc.CommitmentType(cfg.Transactions),
+	}
+}
+
+// pollPool converts the configured polling pool, polling one wallet at a
+// time when it isn't configured
+func pollPool(cfg *config.PollingConfig) monitor.PollPool {
+	if cfg == nil {
+		return monitor.PollPool{}
+	}
+	return monitor.PollPool{
+		Workers:           cfg.Concurrency,
+		Timeout:           cfg.Timeout.Duration(),
+		BatchSize:         cfg.BatchSize,
+		InitialWorkers:    cfg.InitialConcurrency,
+		DiscoveryInterval: cfg.DiscoveryInterval.Duration(),
+	}
+}
+
+// dustFilter builds the configured dust filter
+func dustFilter(cfg *config.Config) monitor.Filter {
+	thresholds := monitor.DustThresholds{
+		Default: dustThreshold(cfg.Dust.DustThresholdConfig),
+		Mints:   make(map[string]monitor.DustThreshold, len(cfg.Dust.Mints)),
+	}
+	for mint, threshold := range cfg.Dust.Mints {
+		thresholds.Mints[mint] = dustThreshold(threshold)
+	}
+	var prices *price.Cache
+	if cfg.Dust.UsesUSD() {
+		prices = price.NewCache(price.NewClient(cfg.PriceAPI), 0, logger)
+	}
+	return monitor.DustFilter(thresholds, prices)
+}
+
+// newDispatcher creates the notification channels of a tenant, or the
+// top-level ones for an empty tenant, and the dispatcher delivering to them.
+// It returns nil if there are none.
+func newDispatcher(cfg *config.Config, tenant string, history store.Store) (*notify.Dispatcher, error) {
+	notifiers, err := newNotifiers(cfg, tenant, history)
+	if err != nil {
+		return nil, err
+	}
+	if len(notifiers) == 0 {
+		return nil, nil
+	}
+
+	notifierCfgs, _ := cfg.TenantNotifiers(tenant)
+	dispatcher := notify.NewDispatcher(cfg.BatchWindow.Duration(), logger, notifiers...)
+	dispatcher.SetTenant(tenant)
+	dispatcher.SetPriority(criticalRules(cfg), cfg.RateLimit)
+	dispatcher.SetCooldown(cfg.Cooldown)
+	severities := make([]string, 0, len(notifierCfgs))
+	for _, notifierCfg := range notifierCfgs {
+		severities = append(severities, notifierCfg.MinSeverity)
+	}
+	dispatcher.SetMinSeverities(severities)
+	names := make([]string, 0, len(notifierCfgs))
+	for _, notifierCfg := range notifierCfgs {
+		names = append(names, notifierCfg.Name)
+	}
+	dispatcher.SetNames(names)
+	schedules := make([]*notify.Schedule, 0, len(notifierCfgs))
+	for i, notifierCfg := range notifierCfgs {
+		schedule, err := notify.NewSchedule(notifierCfg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quiet hours of notifier %d: %w", i, err)
+		}
+		schedules = append(schedules, schedule)
+	}
+	dispatcher.SetSchedules(schedules)
+	digestWindows := make([]time.Duration, 0, len(notifierCfgs))
+	for _, notifierCfg := range notifierCfgs {
+		digestWindows = append(digestWindows, notifierCfg.DigestWindow.Duration())
+	}
+	dispatcher.SetDigestWindows(digestWindows)
+	if cfg.Retry != nil {
+		deadLetters, _ := history.(store.DeadLetterStore)
+		if deadLetters == nil {
+			logrus.Warn("No store configured, notifications that fail every retry will be dropped")
+		}
+		dispatcher.SetRetry(notify.RetryPolicy{
+			Attempts:     cfg.Retry.Attempts,
+			InitialDelay: cfg.Retry.InitialDelay.Duration(),
+			MaxDelay:     cfg.Retry.MaxDelay.Duration(),
+		}, deadLetters)
+	}
+	return dispatcher, nil
+}
+
+// newNotifiers creates the notification channels of a tenant, or the
+// top-level ones for an empty tenant
+func newNotifiers(cfg *config.Config, tenant string, history store.Store) ([]notify.Notifier, error) {
+	notifierCfgs, ok := cfg.TenantNotifiers(tenant)
+	if !ok {
+		return nil, fmt.Errorf("tenant %s is not configured", tenant)
+	}
+	var notifiers []notify.Notifier
+	for _, notifierCfg := range notifierCfgs {
+		notifier, err := notify.New(notifierCfg, history, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize notifier: %w", err)
+		}
+		notifiers = append(notifiers, notifier)
+	}
+	return notifiers, nil
+}
+
+// criticalRules builds the configured critical rules
+func criticalRules(cfg *config.Config) []notify.Rule {
+	rules := make([]notify.Rule, 0, len(cfg.CriticalRules))
+	for _, rule := range cfg.CriticalRules {
+		rules = append(rules, notify.NewRule(rule))
+	}
+	return rules
+}
+
+// rugChecks returns the rug check options of new holdings
+func rugChecks(cfg *config.Config) monitor.RugChecks {
+	return monitor.RugChecks{
+		Quotes:             price.NewQuoteClient(cfg.NewHoldings.QuoteAPI),
+		ProbeUSD:           cfg.NewHoldings.LiquidityProbeUSD,
+		MaxPriceImpact:     cfg.NewHoldings.MaxPriceImpact,
+		MaxTopHoldersShare: cfg.NewHoldings.MaxTopHoldersShare,
+	}
+}
+
+// severityRules builds the configured severity rules
+func severityRules(cfg *config.Config) []notify.SeverityRule {
+	rules := make([]notify.SeverityRule, 0, len(cfg.SeverityRules))
+	for _, rule := range cfg.SeverityRules {
+		rules = append(rules, notify.NewSeverityRule(rule))
+	}
+	return rules
+}
+
+// dustThreshold converts a configured dust threshold
+func dustThreshold(cfg config.DustThresholdConfig) monitor.DustThreshold {
+	return monitor.DustThreshold{
+		MinBalance:    cfg.MinBalance,
+		MinChange:     cfg.MinChange,
+		MinBalanceUSD: cfg.MinBalanceUSD,
+		MinChangeUSD:  cfg.MinChangeUSD,
+	}
+}
+
+// indexWalletLabels adds the configured labels and notes to the search index
+func indexWalletLabels(ctx context.Context, searcher store.Searcher, labels map[string]config.WalletLabelConfig) {
+	now := time.Now()
+	for address, label := range labels {
+		documents := []store.Document{
+			{Kind: store.DocumentLabel, Text: strings.TrimSpace(label.Label + " " + strings.Join(label.Groups, " "))},
+			{Kind: store.DocumentNote, Text: label.Note},
+		}
+		for _, doc := range documents {
+			doc.Key, doc.Wallet, doc.At = address, address, now
+			if err := searcher.IndexDocument(ctx, doc); err != nil {
+				logrus.Errorf("Failed to index %s of %s: %v", doc.Kind, address, err)
+			}
+		}
+	}
+}
+
+// storeEvent returns an event handler appending events to the event log of
+// a store
+func storeEvent(events store.EventStore) monitor.EventHandler {
+	return func(event monitor.Event) {
+		data, err := json.Marshal(event)
+		if err != nil {
+			logrus.Errorf("Failed to encode event: %v", err)
+			return
+		}
+		_, err = events.AppendEvent(context.Background(), store.StoredEvent{
+			Wallet: event.Account.Owner,
+			At:     time.Now().UTC(),
+			Event:  data,
+		})
+		if err != nil {
+			logrus.Errorf("Failed to save event: %v", err)
+		}
+	}
+}
+
+// mustRegister stops the tracker when a handler can't be registered, which
+// only happens when the wiring above is wrong
+func mustRegister(err error) {
+	if err != nil {
+		logrus.Fatalf("Failed to register handler: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/yourusername/solana-wallet-tracker/pkg/archive"
+)
+
+// newStateCommand builds `tracker state export|import`
+func newStateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "Export or import balance history and the latest snapshot",
+	}
+	cmd.AddCommand(newStateExportCommand(), newStateImportCommand())
+	return cmd
+}
+
+// newStateExportCommand builds `tracker state export`
+func newStateExportCommand() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write the configured store to an archive",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := openConfiguredStore()
+			if err != nil {
+				return err
+			}
+			defer st.Close()
+
+			file, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			manifest, err := archive.Export(context.Background(), st, file)
+			if err != nil {
+				return err
+			}
+
+			logrus.WithFields(logrus.Fields{
+				"file":     output,
+				"records":  manifest.Records,
+				"accounts": manifest.Accounts,
+			}).Info("State exported")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "tracker-state.tar.gz", "archive file to write")
+	return cmd
+}
+
+// newStateImportCommand builds `tracker state import`
+func newStateImportCommand() *cobra.Command {
+	var input string
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Load an archive into the configured store",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := openConfiguredStore()
+			if err != nil {
+				return err
+			}
+			defer st.Close()
+
+			ctx := context.Background()
+			existing, err := st.Records(ctx)
+			if err != nil {
+				return err
+			}
+			if len(existing) > 0 && !force {
+				return fmt.Errorf("store already contains %d records, use --force to import anyway", len(existing))
+			}
+
+			file, err := os.Open(input)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			manifest, err := archive.Import(ctx, st, file)
+			if err != nil {
+				return err
+			}
+
+			logrus.WithFields(logrus.Fields{
+				"file":     input,
+				"records":  manifest.Records,
+				"accounts": manifest.Accounts,
+			}).Info("State imported")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&input, "input", "i", "tracker-state.tar.gz", "archive file to read")
+	cmd.Flags().BoolVar(&force, "force", false, "import into a store that already has history")
+	return cmd
+}
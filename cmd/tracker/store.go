@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+)
+
+// newStoreCommand builds `tracker store migrate|wallets|purge`
+func newStoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "store",
+		Short: "Manage balance history stores",
+	}
+	cmd.AddCommand(newStoreMigrateCommand(), newStoreWalletsCommand(), newStorePurgeCommand())
+	return cmd
+}
+
+// newStoreMigrateCommand builds `tracker store migrate`, which copies history
+// between store backends
+func newStoreMigrateCommand() *cobra.Command {
+	var from, to string
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Copy history between store backends and verify the copy",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			source, err := openStoreURI(from)
+			if err != nil {
+				return fmt.Errorf("failed to open source store: %w", err)
+			}
+			defer source.Close()
+
+			ctx := context.Background()
+			var report *store.MigrationReport
+			if strings.HasPrefix(to, "clickhouse") {
+				// ClickHouse keeps the history for analytics only
+				if newRecordTarget == nil {
+					return unavailable("ClickHouse", "noclickhouse")
+				}
+				target, err := newRecordTarget(ctx, to)
+				if err != nil {
+					return fmt.Errorf("failed to open target: %w", err)
+				}
+				report, err = store.MigrateRecords(ctx, source, target)
+				if err != nil {
+					return err
+				}
+			} else {
+				target, err := openStoreURI(to)
+				if err != nil {
+					return fmt.Errorf("failed to open target store: %w", err)
+				}
+				defer target.Close()
+
+				report, err = store.Migrate(ctx, source, target)
+				if err != nil {
+					return err
+				}
+			}
+
+			logrus.WithFields(logrus.Fields{
+				"from":     from,
+				"to":       to,
+				"records":  report.Records,
+				"checksum": report.Checksum,
+			}).Info("Store migrated and verified")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "source store URI (file:<path>, sqlite:<path> or postgres://...)")
+	cmd.Flags().StringVar(&to, "to", "", "target store URI (file:<path>, sqlite:<path>, postgres://... or clickhouse://...)")
+	cmd.MarkFlagRequired("from")
+	cmd.MarkFlagRequired("to")
+	return cmd
+}
+
+// newStoreWalletsCommand builds `tracker store wallets`, which lists wallets
+// with stored history and their archive status
+func newStoreWalletsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "wallets",
+		Short: "List wallets with stored history and their archive status",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := openConfiguredStore()
+			if err != nil {
+				return err
+			}
+			defer st.Close()
+
+			wallets, err := st.Wallets(context.Background())
+			if err != nil {
+				return err
+			}
+
+			for _, wallet := range wallets {
+				status := "active"
+				if wallet.Archived() {
+					status = "archived " + wallet.ArchivedAt.Format(time.RFC3339)
+				}
+				fmt.Printf("%s\t%s\n", wallet.Owner, status)
+			}
+			return nil
+		},
+	}
+}
+
+// newStorePurgeCommand builds `tracker store purge`, which permanently
+// deletes the history of an archived wallet
+func newStorePurgeCommand() *cobra.Command {
+	var wallet string
+
+	cmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Permanently delete the history of an archived wallet",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			wallet, err := resolveWallet(cmd.Context(), wallet)
+			if err != nil {
+				return err
+			}
+			st, err := openConfiguredStore()
+			if err != nil {
+				return err
+			}
+			defer st.Close()
+
+			ctx := context.Background()
+			wallets, err := st.Wallets(ctx)
+			if err != nil {
+				return err
+			}
+
+			for _, status := range wallets {
+				if status.Owner != wallet {
+					continue
+				}
+				if !status.Archived() {
+					return fmt.Errorf("wallet %s is still monitored, remove it from the config first", wallet)
+				}
+				if err := st.PurgeWallet(ctx, wallet); err != nil {
+					return err
+				}
+				logrus.WithField("wallet", wallet).Info("Wallet history purged")
+				return nil
+			}
+
+			return fmt.Errorf("wallet %s has no stored history", wallet)
+		},
+	}
+
+	cmd.Flags().StringVar(&wallet, "wallet", "", "archived wallet to purge, by address or .sol domain")
+	cmd.MarkFlagRequired("wallet")
+	return cmd
+}
+
+// openConfiguredStore opens the store configured in the config file
+func openConfiguredStore() (store.Store, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if cfg.Store == nil {
+		return nil, fmt.Errorf("no store configured in %s", configSource())
+	}
+	return store.New(*cfg.Store, logger)
+}
+
+// openStoreURI opens a store from a URI
+func openStoreURI(uri string) (store.Store, error) {
+	cfg, err := store.ParseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	return store.New(cfg, logger)
+}
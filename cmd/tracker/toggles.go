@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/notify"
+)
+
+// handlerToggles turns the handlers of the monitors of every network and
+// the notifiers of every tenant on and off by name
+type handlerToggles struct {
+	monitors []*monitor.Monitor
+	// dispatchers are keyed by tenant, the top-level one by ""
+	dispatchers map[string]*notify.Dispatcher
+}
+
+// Handlers returns the handlers of the monitors, once per name
+func (t *handlerToggles) Handlers() []monitor.HandlerState {
+	seen := make(map[string]bool)
+	states := []monitor.HandlerState{}
+	for _, m := range t.monitors {
+		for _, state := range m.Handlers() {
+			if !seen[state.Name] {
+				seen[state.Name] = true
+				states = append(states, state)
+			}
+		}
+	}
+	return states
+}
+
+// SetHandlerEnabled turns the handlers of a name on or off in every monitor
+// registering them
+func (t *handlerToggles) SetHandlerEnabled(name string, enabled bool) error {
+	known := false
+	for _, m := range t.monitors {
+		if err := m.SetHandlerEnabled(name, enabled); err == nil {
+			known = true
+		}
+	}
+	if !known {
+		return fmt.Errorf("%w: %s", monitor.ErrUnknownHandler, name)
+	}
+	return nil
+}
+
+// Notifiers returns the notifiers of every tenant, the top-level ones first
+func (t *handlerToggles) Notifiers() []notify.NotifierState {
+	tenants := make([]string, 0, len(t.dispatchers))
+	for tenant := range t.dispatchers {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	states := []notify.NotifierState{}
+	for _, tenant := range tenants {
+		states = append(states, t.dispatchers[tenant].Notifiers()...)
+	}
+	return states
+}
+
+// SetNotifierEnabled turns the notifiers of a name on or off for every
+// tenant
+func (t *handlerToggles) SetNotifierEnabled(name string, enabled bool) error {
+	known := false
+	for _, dispatcher := range t.dispatchers {
+		if dispatcher.SetNotifierEnabled(name, enabled) {
+			known = true
+		}
+	}
+	if !known {
+		return fmt.Errorf("%w: %s", notify.ErrUnknownNotifier, name)
+	}
+	return nil
+}
+
+// apply turns off the handlers and notifiers disabled in the configuration
+// and turns on all others. Notifiers whose configuration no longer matches
+// the running ones keep their state, as changing them takes a restart.
+func (t *handlerToggles) apply(cfg *config.Config) {
+	disabled := make(map[string]bool)
+	for _, name := range cfg.DisabledHandlers {
+		disabled[name] = true
+	}
+	for _, state := range t.Handlers() {
+		if err := t.SetHandlerEnabled(state.Name, !disabled[state.Name]); err != nil {
+			logrus.Errorf("Failed to apply disabled_handlers: %v", err)
+		}
+		delete(disabled, state.Name)
+	}
+	for name := range disabled {
+		logrus.WithField("handler", name).Warn("disabled_handlers names an unknown handler")
+	}
+
+	for tenant, dispatcher := range t.dispatchers {
+		notifierCfgs, _ := cfg.TenantNotifiers(tenant)
+		running := dispatcher.Notifiers()
+		if len(notifierCfgs) != len(running) {
+			logrus.WithField("tenant", tenant).Warn("Notifiers changed, restart the tracker to apply them")
+			continue
+		}
+		enabled := make([]bool, 0, len(notifierCfgs))
+		changed := false
+		for i, notifierCfg := range notifierCfgs {
+			name := notifierCfg.Name
+			if name == "" {
+				name = running[i].Type
+			}
+			changed = changed || notifierCfg.Type != running[i].Type || name != running[i].Name
+			enabled = append(enabled, !notifierCfg.Disabled)
+		}
+		if changed {
+			logrus.WithField("tenant", tenant).Warn("Notifiers changed, restart the tracker to apply them")
+			continue
+		}
+		dispatcher.SetNotifiersEnabled(enabled)
+	}
+}
+
+// reloadOnHangup applies the disabled handlers and notifiers of the
+// configuration again whenever the tracker receives SIGHUP. Other changes
+// of the configuration take a restart.
+func reloadOnHangup(toggles *handlerToggles) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	for range hangup {
+		cfg, err := loadConfig()
+		if err == nil {
+			err = cfg.Validate()
+		}
+		if err != nil {
+			logrus.Errorf("Failed to reload configuration, keeping the current one: %v", err)
+			continue
+		}
+		toggles.apply(cfg)
+		logrus.WithFields(logrus.Fields{
+			"source":            configSource(),
+			"disabled_handlers": cfg.DisabledHandlers,
+		}).Info("Reloaded disabled handlers and notifiers")
+	}
+}
@@ -0,0 +1,64 @@
+//go:build !notui
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/yourusername/solana-wallet-tracker/pkg/i18n"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+	"github.com/yourusername/solana-wallet-tracker/pkg/tui"
+)
+
+func init() {
+	optionalCommands = append(optionalCommands, newTUICommand)
+}
+
+// terminalDashboard is the terminal dashboard as a liveDashboard
+type terminalDashboard struct {
+	*tui.Dashboard
+}
+
+// AddSource shows the token accounts of a monitor
+func (d terminalDashboard) AddSource(source func() map[string]solana.TokenAccountInfo) {
+	d.Dashboard.AddSource(source)
+}
+
+// newTUICommand builds `tracker tui`, which runs the tracker behind a
+// terminal dashboard instead of logging
+func newTUICommand() *cobra.Command {
+	var locale, logFile string
+	cmd := &cobra.Command{
+		Use:   "tui",
+		Short: "Monitor the configured wallets on a live terminal dashboard",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !i18n.Supported(locale) {
+				return fmt.Errorf("unsupported --locale %q", locale)
+			}
+
+			dashboard := tui.New(locale)
+			var output io.Writer = dashboard
+			if logFile != "" {
+				file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+				if err != nil {
+					return fmt.Errorf("failed to open log file: %w", err)
+				}
+				defer file.Close()
+				output = io.MultiWriter(dashboard, file)
+			}
+			logrus.SetOutput(output)
+
+			runTracker(terminalDashboard{dashboard})
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&locale, "locale", "en", "language of the event feed (en, vi)")
+	cmd.Flags().StringVar(&logFile, "log-file", "", "append the logs to this file; the dashboard only shows the latest line")
+	return cmd
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/yourusername/solana-wallet-tracker/pkg/backfill"
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/sns"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// newAddWalletCommand builds `tracker add-wallet`, which adds a wallet to the
+// config file
+func newAddWalletCommand() *cobra.Command {
+	var backfillLimit int
+
+	cmd := &cobra.Command{
+		Use:   "add-wallet <address|domain.sol>",
+		Short: "Add a wallet to the config file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			wallet := args[0]
+			if !sns.IsDomain(wallet) {
+				if err := solana.ValidateAddress(wallet); err != nil {
+					return err
+				}
+			}
+			if backfillLimit < 0 || backfillLimit > config.MaxBackfill {
+				return fmt.Errorf("--backfill must be between 0 and %d", config.MaxBackfill)
+			}
+
+			if configFile == "" {
+				return fmt.Errorf("add-wallet edits the config file, which --no-config-file leaves out; add the wallet to MONITOR_WALLETS instead")
+			}
+
+			added, err := config.AddWallet(configFile, wallet)
+			if err != nil {
+				return err
+			}
+
+			if !added {
+				logrus.WithField("wallet", wallet).Info("Wallet is already configured")
+				return nil
+			}
+			logrus.WithFields(logrus.Fields{
+				"wallet": wallet,
+				"config": configFile,
+			}).Info("Wallet added, restart the tracker to monitor it")
+
+			if backfillLimit > 0 {
+				return backfillWallet(cmd.Context(), wallet, backfillLimit)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&backfillLimit, "backfill", 0, "store the balances left by this many past transactions per token account")
+	return cmd
+}
+
+// backfillWallet stores the recent balance history of a wallet, given as an
+// address or domain, in the configured store unless it has history already
+func backfillWallet(ctx context.Context, name string, limit int) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	st, err := openConfiguredStore()
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	client, err := newClient(ctx, cfg.RPCEndpoint, cfg.WSEndpoint, cfg.HTTP)
+	if err != nil {
+		return fmt.Errorf("failed to initialize Solana client: %w", err)
+	}
+	defer client.Close()
+	client.Commitments = commitments(cfg.Commitment)
+
+	wallet, err := sns.NewResolver(client).Wallet(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", name, err)
+	}
+
+	// Archived wallets that were added back keep their history
+	known, err := st.Wallets(ctx)
+	if err != nil {
+		return err
+	}
+	for _, status := range known {
+		if status.Owner == wallet {
+			logrus.WithField("wallet", wallet).Info("Wallet already has history, skipping backfill")
+			return nil
+		}
+	}
+
+	records, err := backfill.Wallet(ctx, client, st, wallet, cfg.TokensFor(name), limit)
+	if err != nil {
+		return err
+	}
+	logrus.WithFields(logrus.Fields{
+		"wallet":  wallet,
+		"records": records,
+	}).Info("Backfilled balance history")
+	return nil
+}
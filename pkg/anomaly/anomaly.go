@@ -0,0 +1,163 @@
+// Package anomaly learns the typical transfer sizes of each wallet and mint
+// and flags transfers far larger than usual or moving most of a holding.
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+)
+
+// Defaults of Options
+const (
+	DefaultSigma      = 3
+	DefaultMinSamples = 10
+	DefaultWindow     = 100
+)
+
+// minDeviation is the smallest standard deviation assumed, relative to the
+// mean, so wallets always moving the same amount still flag outliers
+const minDeviation = 0.01
+
+// Reasons a transfer is anomalous
+const (
+	// ReasonSize marks transfers more than Sigma standard deviations above
+	// the mean size
+	ReasonSize = "size"
+	// ReasonHoldings marks outgoing transfers moving more than HoldingsShare
+	// percent of the balance
+	ReasonHoldings = "holdings"
+)
+
+// Options tune the detector. Zero values take the defaults.
+type Options struct {
+	// Sigma is how many standard deviations above the mean a transfer must
+	// be to be anomalous
+	Sigma float64
+	// HoldingsShare is the percentage of a balance an outgoing transfer must
+	// move to be anomalous. Zero doesn't check.
+	HoldingsShare float64
+	// MinSamples is how many transfers are learned before sizes are checked
+	MinSamples int
+	// Window is the number of recent transfers the statistics follow
+	Window int
+}
+
+// Finding describes an anomalous transfer
+type Finding struct {
+	Reasons []string `json:"reasons"`
+	// Amount is the size of the transfer in UI units
+	Amount float64 `json:"amount"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"std_dev"`
+	// Sigma is how many standard deviations the transfer is above the mean
+	Sigma float64 `json:"sigma,omitempty"`
+	// HoldingsShare is the percentage of the balance an outgoing transfer
+	// moved
+	HoldingsShare float64 `json:"holdings_share,omitempty"`
+	// Samples is the number of transfers learned before this one
+	Samples int64 `json:"samples"`
+}
+
+// Detector checks transfers against the statistics of earlier ones and
+// keeps the statistics in a store
+type Detector struct {
+	stats   store.StatsStore
+	options Options
+	// mutex serializes updates, which read and write the statistics
+	mutex sync.Mutex
+}
+
+// NewDetector creates a detector keeping its statistics in stats
+func NewDetector(stats store.StatsStore, options Options) *Detector {
+	if options.Sigma <= 0 {
+		options.Sigma = DefaultSigma
+	}
+	if options.MinSamples <= 0 {
+		options.MinSamples = DefaultMinSamples
+	}
+	if options.Window <= 0 {
+		options.Window = DefaultWindow
+	}
+	return &Detector{stats: stats, options: options}
+}
+
+// Observe checks a transfer changing a balance from previous to current, in
+// UI units, and learns its size. It returns nil for ordinary transfers.
+func (d *Detector) Observe(ctx context.Context, owner, mint string, previous, current float64) (*Finding, error) {
+	amount := math.Abs(current - previous)
+	if amount == 0 {
+		return nil, nil
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	stats, err := d.stats.TransferStats(ctx, owner, mint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load transfer statistics: %w", err)
+	}
+
+	finding := d.check(stats, amount, previous, current)
+
+	learn(&stats, amount, d.options.Window)
+	stats.Owner = owner
+	stats.Mint = mint
+	stats.UpdatedAt = time.Now()
+	if err := d.stats.SaveTransferStats(ctx, stats); err != nil {
+		return finding, fmt.Errorf("failed to save transfer statistics: %w", err)
+	}
+	return finding, nil
+}
+
+// check compares a transfer with the statistics of earlier ones
+func (d *Detector) check(stats store.TransferStats, amount, previous, current float64) *Finding {
+	finding := &Finding{
+		Amount:  amount,
+		Mean:    stats.Mean,
+		StdDev:  math.Sqrt(stats.Variance),
+		Samples: stats.Count,
+	}
+
+	if stats.Count >= int64(d.options.MinSamples) && amount > stats.Mean {
+		deviation := math.Max(finding.StdDev, stats.Mean*minDeviation)
+		if deviation > 0 {
+			finding.Sigma = (amount - stats.Mean) / deviation
+			if finding.Sigma > d.options.Sigma {
+				finding.Reasons = append(finding.Reasons, ReasonSize)
+			}
+		}
+	}
+
+	if d.options.HoldingsShare > 0 && current < previous {
+		finding.HoldingsShare = amount / previous * 100
+		if finding.HoldingsShare > d.options.HoldingsShare {
+			finding.Reasons = append(finding.Reasons, ReasonHoldings)
+		}
+	}
+
+	if len(finding.Reasons) == 0 {
+		return nil
+	}
+	return finding
+}
+
+// learn adds a transfer to exponentially weighted statistics, which match
+// the plain mean and variance until window transfers were seen
+func learn(stats *store.TransferStats, amount float64, window int) {
+	stats.Count++
+	n := stats.Count
+	if n > int64(window) {
+		n = int64(window)
+	}
+	alpha := 1 / float64(n)
+
+	diff := amount - stats.Mean
+	increment := alpha * diff
+	stats.Mean += increment
+	stats.Variance = (1 - alpha) * (stats.Variance + diff*increment)
+}
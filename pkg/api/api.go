@@ -0,0 +1,859 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/counterparty"
+	"github.com/yourusername/solana-wallet-tracker/pkg/ingest"
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/rent"
+	"github.com/yourusername/solana-wallet-tracker/pkg/risk"
+	"github.com/yourusername/solana-wallet-tracker/pkg/snapshot"
+	"github.com/yourusername/solana-wallet-tracker/pkg/sns"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+)
+
+// Wallet is a tracked wallet with its label
+type Wallet struct {
+	Address string `json:"address"`
+	// Domain is the .sol domain the wallet was configured as. Address
+	// follows it to its current owner.
+	Domain string   `json:"domain,omitempty"`
+	Label  string   `json:"label,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+	Note   string   `json:"note,omitempty"`
+	// Tenants are the tenants whose API keys see the wallet
+	Tenants []string `json:"tenants,omitempty"`
+	Paused  bool     `json:"paused"`
+}
+
+// WalletController pauses and resumes monitoring of single wallets
+type WalletController interface {
+	PauseWallet(wallet string) error
+	ResumeWallet(ctx context.Context, wallet string) error
+	IsPaused(wallet string) bool
+}
+
+// GroupSource totals the balances of wallet groups
+type GroupSource interface {
+	GroupSummary(ctx context.Context, group string) monitor.GroupSummary
+}
+
+// AccountInspector reports what the monitor knows about a token account
+type AccountInspector interface {
+	InspectAccount(address, wallet string) monitor.AccountInspection
+}
+
+// BackpressureSource reports the event queue and the polling suspensions it
+// caused
+type BackpressureSource interface {
+	BackpressureStatus() monitor.BackpressureStatus
+}
+
+// LatencySource reports how far behind the chain a network is handled
+type LatencySource interface {
+	LatencyReport() []monitor.LatencyStats
+	Network() string
+}
+
+// HealthSource reports whether the tracker is alive and ready
+type HealthSource interface {
+	Health(ctx context.Context) monitor.Health
+}
+
+// Server serves the tracker HTTP API
+type Server struct {
+	server         *http.Server
+	listener       net.Listener
+	wallets        []Wallet
+	controller     WalletController
+	groups         GroupSource
+	inspector      AccountInspector
+	backpressure   BackpressureSource
+	latency        []LatencySource
+	health         HealthSource
+	search         store.Searcher
+	risk           *risk.Scorer
+	counterparties *counterparty.Tracker
+	snapshots      *snapshot.Store
+	rent           store.RentStore
+	domains        *sns.Resolver
+	balances       []BalanceSource
+	ingestor       Ingestor
+	ingestSecrets  ingest.Secrets
+	keys           []apiKey
+	events         *EventLog
+	eventStore     store.EventStore
+	toggles        HandlerController
+	log            logging.Logger
+	// closing is closed on Shutdown, which doesn't wait for the hijacked
+	// connections of event streams
+	closing chan struct{}
+}
+
+// NewServer creates an API server listening on addr, logging through log or
+// the default logger when nil
+func NewServer(addr string, log logging.Logger) *Server {
+	if log == nil {
+		log = logging.Default()
+	}
+	s := &Server{log: log, closing: make(chan struct{})}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wallets", s.handleWallets)
+	mux.HandleFunc("/wallets/", s.handleWalletAction)
+	mux.HandleFunc("/groups", s.adminOnly(s.handleGroups))
+	mux.HandleFunc("/groups/", s.adminOnly(s.handleGroup))
+	mux.HandleFunc("/totals", s.adminOnly(s.handleTotals))
+	mux.HandleFunc("/accounts/", s.handleAccount)
+	mux.HandleFunc("/risk", s.handleRiskScores)
+	mux.HandleFunc("/risk/", s.handleRiskScore)
+	mux.HandleFunc("/counterparties/", s.handleCounterparties)
+	mux.HandleFunc("/search", s.handleSearch)
+	mux.HandleFunc("/snapshots", s.adminOnly(s.handleSnapshots))
+	mux.HandleFunc("/snapshots/", s.adminOnly(s.handleSnapshot))
+	mux.HandleFunc("/rent", s.handleRent)
+	mux.HandleFunc("/events", s.handleEvents)
+	mux.HandleFunc("/events/stream", s.handleEventStream)
+	mux.HandleFunc("/flows", s.handleFlows)
+	mux.HandleFunc("/backpressure", s.adminOnly(s.handleBackpressure))
+	mux.HandleFunc("/latency", s.adminOnly(s.handleLatency))
+	mux.HandleFunc("/handlers", s.adminOnly(s.handleHandlers))
+	mux.HandleFunc("/handlers/", s.adminOnly(s.handleToggle("handler", HandlerController.SetHandlerEnabled)))
+	mux.HandleFunc("/notifiers/", s.adminOnly(s.handleToggle("notifier", HandlerController.SetNotifierEnabled)))
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/dashboard", s.handleDashboard)
+	mux.HandleFunc("/dashboard/", s.handleDashboardData)
+	mux.HandleFunc("/ingest/", s.handleIngest)
+	mux.HandleFunc("/healthz", s.handleHealth(func(health monitor.Health) bool { return health.Live }))
+	mux.HandleFunc("/readyz", s.handleHealth(func(health monitor.Health) bool { return health.Ready }))
+
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           s.authenticate(mux),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	s.server.RegisterOnShutdown(func() { close(s.closing) })
+	return s
+}
+
+// SetWallets sets the wallets served by the wallets endpoint
+func (s *Server) SetWallets(wallets []Wallet) {
+	s.wallets = wallets
+}
+
+// SetWalletController enables pausing and resuming wallets
+func (s *Server) SetWalletController(controller WalletController) {
+	s.controller = controller
+}
+
+// SetGroupSource enables the group totals endpoints
+func (s *Server) SetGroupSource(groups GroupSource) {
+	s.groups = groups
+}
+
+// SetAccountInspector enables the token account inspection endpoint
+func (s *Server) SetAccountInspector(inspector AccountInspector) {
+	s.inspector = inspector
+}
+
+// SetBackpressureSource enables the backpressure endpoint
+func (s *Server) SetBackpressureSource(source BackpressureSource) {
+	s.backpressure = source
+}
+
+// SetLatencySources enables the latency endpoint, with the update sources
+// of every network
+func (s *Server) SetLatencySources(sources ...LatencySource) {
+	s.latency = sources
+}
+
+// SetHealthSource enables the liveness and readiness endpoints
+func (s *Server) SetHealthSource(source HealthSource) {
+	s.health = source
+}
+
+// SetSearcher enables the search endpoint
+func (s *Server) SetSearcher(searcher store.Searcher) {
+	s.search = searcher
+}
+
+// SetRiskScorer enables the risk endpoints
+func (s *Server) SetRiskScorer(scorer *risk.Scorer) {
+	s.risk = scorer
+}
+
+// SetCounterpartyTracker enables the counterparty statistics endpoint
+func (s *Server) SetCounterpartyTracker(tracker *counterparty.Tracker) {
+	s.counterparties = tracker
+}
+
+// SetSnapshotStore enables the snapshot endpoints
+func (s *Server) SetSnapshotStore(snapshots *snapshot.Store) {
+	s.snapshots = snapshots
+}
+
+// SetRentStore enables the rent report endpoint
+func (s *Server) SetRentStore(rentStore store.RentStore) {
+	s.rent = rentStore
+}
+
+// SetDomainResolver lets wallets be given as .sol domains and keeps the
+// addresses of wallets configured as domains current
+func (s *Server) SetDomainResolver(resolver *sns.Resolver) {
+	s.domains = resolver
+}
+
+// Start serves the API in the background on listener, such as one passed by
+// systemd or a previous process, or listens on the configured address when
+// it is nil
+func (s *Server) Start(listener net.Listener) error {
+	if listener == nil {
+		var err error
+		listener, err = net.Listen("tcp", s.server.Addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", s.server.Addr, err)
+		}
+	}
+	s.listener = listener
+
+	go func() {
+		s.log.Info("API listening", "addr", listener.Addr().String())
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			s.log.Error("API server failed", "error", err)
+		}
+	}()
+	return nil
+}
+
+// Listener returns the listener the API is served on, or nil before Start
+func (s *Server) Listener() net.Listener {
+	return s.listener
+}
+
+// Shutdown stops the server, waiting for in-flight requests
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// handleWallets serves GET /wallets, optionally filtered to one group with
+// ?group=
+func (s *Server) handleWallets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	group := r.URL.Query().Get("group")
+	tenant := tenantOf(r)
+	wallets := make([]Wallet, 0, len(s.wallets))
+	for _, wallet := range s.wallets {
+		if tenant != "" && !inTenant(wallet, tenant) {
+			continue
+		}
+		if group == "" || inGroup(wallet, group) {
+			wallet.Address = s.address(r.Context(), wallet)
+			if s.controller != nil {
+				wallet.Paused = s.controller.IsPaused(wallet.Address)
+			}
+			wallets = append(wallets, wallet)
+		}
+	}
+	s.writeJSON(w, http.StatusOK, wallets)
+}
+
+// handleWalletAction serves POST /wallets/{wallet}/pause and
+// POST /wallets/{wallet}/resume
+func (s *Server) handleWalletAction(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/wallets/"), "/")
+	if len(parts) != 2 || parts[0] == "" {
+		s.writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	action := parts[1]
+	wallet, ok := s.walletParam(w, r, parts[0])
+	if !ok || !s.allowWallet(w, r, wallet) {
+		return
+	}
+
+	var apply func(string) error
+	switch {
+	case s.controller == nil:
+		s.writeError(w, http.StatusNotFound, "wallet control is disabled")
+		return
+	case action == "pause":
+		apply = s.controller.PauseWallet
+	case action == "resume":
+		apply = func(wallet string) error {
+			return s.controller.ResumeWallet(r.Context(), wallet)
+		}
+	default:
+		s.writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if err := apply(wallet); err != nil {
+		if errors.Is(err, monitor.ErrUnknownWallet) {
+			s.writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"wallet": wallet,
+		"paused": s.controller.IsPaused(wallet),
+	})
+}
+
+// handleGroups serves GET /groups, the balance totals of every group of the
+// tracked wallets
+func (s *Server) handleGroups(w http.ResponseWriter, r *http.Request) {
+	if !s.groupsEnabled(w, r) {
+		return
+	}
+
+	summaries := make([]monitor.GroupSummary, 0)
+	for _, group := range s.groupNames() {
+		summaries = append(summaries, s.groups.GroupSummary(r.Context(), group))
+	}
+	s.writeJSON(w, http.StatusOK, summaries)
+}
+
+// handleGroup serves GET /groups/{group}
+func (s *Server) handleGroup(w http.ResponseWriter, r *http.Request) {
+	if !s.groupsEnabled(w, r) {
+		return
+	}
+
+	group := strings.TrimPrefix(r.URL.Path, "/groups/")
+	for _, name := range s.groupNames() {
+		if name == group {
+			s.writeJSON(w, http.StatusOK, s.groups.GroupSummary(r.Context(), group))
+			return
+		}
+	}
+	s.writeError(w, http.StatusNotFound, "unknown group")
+}
+
+// handleTotals serves GET /totals, the balance totals over every tracked
+// wallet
+func (s *Server) handleTotals(w http.ResponseWriter, r *http.Request) {
+	if !s.groupsEnabled(w, r) {
+		return
+	}
+	s.writeJSON(w, http.StatusOK, s.groups.GroupSummary(r.Context(), ""))
+}
+
+// handleAccount serves GET /accounts/<address>, the live state, owner
+// subscription and parse errors of a token account. ?wallet= names the
+// owner of accounts the monitor doesn't track.
+func (s *Server) handleAccount(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.inspector == nil {
+		s.writeError(w, http.StatusNotFound, "account inspection is disabled")
+		return
+	}
+
+	address := strings.TrimPrefix(r.URL.Path, "/accounts/")
+	if err := solana.ValidateAddress(address); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	wallet := r.URL.Query().Get("wallet")
+	if wallet != "" {
+		if err := solana.ValidateAddress(wallet); err != nil {
+			s.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	inspection := s.inspector.InspectAccount(address, wallet)
+	// Tenants see the accounts of their wallets only
+	if inspection.Account != nil {
+		wallet = inspection.Account.Owner
+	}
+	if !s.allowWallet(w, r, wallet) {
+		return
+	}
+	s.writeJSON(w, http.StatusOK, inspection)
+}
+
+// groupsEnabled rejects requests to the group endpoints when they can't be
+// served
+func (s *Server) groupsEnabled(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return false
+	}
+	if s.groups == nil {
+		s.writeError(w, http.StatusNotFound, "group totals are disabled")
+		return false
+	}
+	return true
+}
+
+// groupNames returns the groups of the tracked wallets in order
+func (s *Server) groupNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, wallet := range s.wallets {
+		for _, group := range wallet.Groups {
+			if !seen[group] {
+				seen[group] = true
+				names = append(names, group)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// handleRiskScores serves GET /risk, the scores of all wallets, optionally
+// filtered to one group with ?group=
+func (s *Server) handleRiskScores(w http.ResponseWriter, r *http.Request) {
+	if !s.riskEnabled(w, r) {
+		return
+	}
+
+	scores := s.risk.Scores()
+	if group := r.URL.Query().Get("group"); group != "" {
+		members := make(map[string]bool)
+		for _, wallet := range s.wallets {
+			if inGroup(wallet, group) {
+				members[s.address(r.Context(), wallet)] = true
+			}
+		}
+		filtered := scores[:0]
+		for _, score := range scores {
+			if members[score.Wallet] {
+				filtered = append(filtered, score)
+			}
+		}
+		scores = filtered
+	}
+	if wallets := s.tenantWallets(r); wallets != nil {
+		filtered := scores[:0]
+		for _, score := range scores {
+			if wallets[score.Wallet] {
+				filtered = append(filtered, score)
+			}
+		}
+		scores = filtered
+	}
+	s.writeJSON(w, http.StatusOK, scores)
+}
+
+// handleRiskScore serves GET /risk/{wallet}
+func (s *Server) handleRiskScore(w http.ResponseWriter, r *http.Request) {
+	if !s.riskEnabled(w, r) {
+		return
+	}
+
+	wallet := strings.TrimPrefix(r.URL.Path, "/risk/")
+	if wallet == "" || strings.Contains(wallet, "/") {
+		s.writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	wallet, ok := s.walletParam(w, r, wallet)
+	if !ok || !s.allowWallet(w, r, wallet) {
+		return
+	}
+	s.writeJSON(w, http.StatusOK, s.risk.Score(wallet))
+}
+
+// riskEnabled rejects requests to the risk endpoints when they can't be served
+func (s *Server) riskEnabled(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return false
+	}
+	if s.risk == nil {
+		s.writeError(w, http.StatusNotFound, "risk scoring is disabled")
+		return false
+	}
+	return true
+}
+
+// handleCounterparties serves GET /counterparties/{wallet}. Query parameters:
+// window (duration, default the retention), by (frequency or volume), mint
+// and limit (default 10).
+func (s *Server) handleCounterparties(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.counterparties == nil {
+		s.writeError(w, http.StatusNotFound, "counterparty statistics are disabled")
+		return
+	}
+
+	wallet := strings.TrimPrefix(r.URL.Path, "/counterparties/")
+	if wallet == "" || strings.Contains(wallet, "/") {
+		s.writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	wallet, ok := s.walletParam(w, r, wallet)
+	if !ok || !s.allowWallet(w, r, wallet) {
+		return
+	}
+
+	query := r.URL.Query()
+	var window time.Duration
+	if value := query.Get("window"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "invalid window: "+err.Error())
+			return
+		}
+		window = parsed
+	}
+	by := query.Get("by")
+	if by == "" {
+		by = counterparty.ByFrequency
+	}
+	limit := 10
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			s.writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = parsed
+	}
+
+	stats, err := s.counterparties.Top(wallet, window, by, query.Get("mint"), limit)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.writeJSON(w, http.StatusOK, stats)
+}
+
+// walletParam returns the wallet a request names, resolving .sol domains.
+// It writes an error response and returns false if that fails.
+func (s *Server) walletParam(w http.ResponseWriter, r *http.Request, value string) (string, bool) {
+	if !sns.IsDomain(value) {
+		return value, true
+	}
+	if s.domains == nil {
+		s.writeError(w, http.StatusBadRequest, "domain names are not supported")
+		return "", false
+	}
+	wallet, err := s.domains.Wallet(r.Context(), value)
+	if errors.Is(err, solana.ErrDomainNotFound) {
+		s.writeError(w, http.StatusNotFound, err.Error())
+		return "", false
+	}
+	if err != nil {
+		s.writeError(w, http.StatusBadGateway, err.Error())
+		return "", false
+	}
+	return wallet, true
+}
+
+// address returns the current address of a listed wallet
+func (s *Server) address(ctx context.Context, wallet Wallet) string {
+	if wallet.Domain == "" || s.domains == nil {
+		return wallet.Address
+	}
+	if address, err := s.domains.Wallet(ctx, wallet.Domain); err == nil {
+		return address
+	}
+	return wallet.Address
+}
+
+// inGroup reports whether a wallet carries a group tag
+func inGroup(wallet Wallet, group string) bool {
+	for _, g := range wallet.Groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// handleRent serves GET /rent, the rent paid and reclaimed per wallet, by
+// day, month or in total with ?by=, optionally within a ?window= and for one
+// ?wallet=
+func (s *Server) handleRent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.rent == nil {
+		s.writeError(w, http.StatusNotFound, "rent tracking is disabled")
+		return
+	}
+
+	query := r.URL.Query()
+	var since time.Time
+	if value := query.Get("window"); value != "" {
+		window, err := time.ParseDuration(value)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "invalid window: "+err.Error())
+			return
+		}
+		since = time.Now().Add(-window)
+	}
+	by := query.Get("by")
+	if by == "" {
+		by = rent.ByMonth
+	}
+
+	entries, err := s.rent.RentEntries(r.Context(), since)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if wallet := query.Get("wallet"); wallet != "" {
+		wallet, ok := s.walletParam(w, r, wallet)
+		if !ok || !s.allowWallet(w, r, wallet) {
+			return
+		}
+		entries = rent.ForWallet(entries, wallet)
+	} else if wallets := s.tenantWallets(r); wallets != nil {
+		filtered := entries[:0]
+		for _, entry := range entries {
+			if wallets[entry.Wallet] {
+				filtered = append(filtered, entry)
+			}
+		}
+		entries = filtered
+	}
+	summaries, err := rent.Summarize(entries, by)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.writeJSON(w, http.StatusOK, summaries)
+}
+
+// handleSearch serves GET /search?q=...&limit=, a full-text search over
+// wallet labels, notes and transaction memos
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.search == nil {
+		s.writeError(w, http.StatusNotFound, "search is disabled")
+		return
+	}
+
+	query := r.URL.Query()
+	if strings.TrimSpace(query.Get("q")) == "" {
+		s.writeError(w, http.StatusBadRequest, "missing q")
+		return
+	}
+	limit := 20
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			s.writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = parsed
+	}
+
+	results, err := s.search.Search(r.Context(), query.Get("q"), limit)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if wallets := s.tenantWallets(r); wallets != nil {
+		filtered := results[:0]
+		for _, result := range results {
+			if wallets[result.Wallet] {
+				filtered = append(filtered, result)
+			}
+		}
+		results = filtered
+	}
+	if results == nil {
+		results = []store.SearchResult{}
+	}
+	s.writeJSON(w, http.StatusOK, results)
+}
+
+// handleSnapshots serves GET /snapshots, listing every snapshot, and
+// GET /snapshots?at=<RFC 3339 time or date>, returning the latest snapshot
+// taken at or before then
+func (s *Server) handleSnapshots(w http.ResponseWriter, r *http.Request) {
+	if !s.snapshotsEnabled(w, r) {
+		return
+	}
+
+	at := r.URL.Query().Get("at")
+	if at == "" {
+		summaries, err := s.snapshots.List()
+		if err != nil {
+			s.writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.writeJSON(w, http.StatusOK, summaries)
+		return
+	}
+
+	t, err := time.Parse(time.RFC3339, at)
+	if err != nil {
+		// A date covers the whole day
+		day, dayErr := time.Parse("2006-01-02", at)
+		if dayErr != nil {
+			s.writeError(w, http.StatusBadRequest, "invalid at: use an RFC 3339 time or a date")
+			return
+		}
+		t = day.Add(24*time.Hour - time.Nanosecond)
+	}
+	snap, err := s.snapshots.At(t)
+	s.writeSnapshot(w, snap, err)
+}
+
+// handleSnapshot serves GET /snapshots/{id} and GET /snapshots/{id}/verify,
+// which recomputes the hash of the stored snapshot
+func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	if !s.snapshotsEnabled(w, r) {
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/snapshots/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] != "":
+		snap, err := s.snapshots.Get(parts[0])
+		s.writeSnapshot(w, snap, err)
+	case len(parts) == 2 && parts[1] == "verify":
+		snap, err := s.snapshots.Get(parts[0])
+		if err != nil {
+			s.writeSnapshot(w, nil, err)
+			return
+		}
+		verification, err := snap.Verify()
+		if err != nil {
+			s.writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.writeJSON(w, http.StatusOK, verification)
+	default:
+		s.writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// snapshotsEnabled rejects requests to the snapshot endpoints when they
+// can't be served
+func (s *Server) snapshotsEnabled(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return false
+	}
+	if s.snapshots == nil {
+		s.writeError(w, http.StatusNotFound, "snapshots are disabled")
+		return false
+	}
+	return true
+}
+
+// writeSnapshot writes a snapshot, or the error looking it up
+func (s *Server) writeSnapshot(w http.ResponseWriter, snap *snapshot.Snapshot, err error) {
+	switch {
+	case errors.Is(err, snapshot.ErrNotFound):
+		s.writeError(w, http.StatusNotFound, err.Error())
+	case err != nil:
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+	default:
+		s.writeJSON(w, http.StatusOK, snap)
+	}
+}
+
+// handleBackpressure serves GET /backpressure, the pending events and the
+// polling suspensions they caused
+func (s *Server) handleBackpressure(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.backpressure == nil {
+		s.writeError(w, http.StatusNotFound, "backpressure is disabled")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, s.backpressure.BackpressureStatus())
+}
+
+// latencyEntry is the latency of an update source of a network
+type latencyEntry struct {
+	Network string `json:"network,omitempty"`
+	monitor.LatencyStats
+}
+
+// handleLatency serves GET /latency, how far behind the block time of their
+// updates events reach their handlers, per network and update source
+func (s *Server) handleLatency(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if len(s.latency) == 0 {
+		s.writeError(w, http.StatusNotFound, "latency measurement is disabled")
+		return
+	}
+	entries := []latencyEntry{}
+	for _, source := range s.latency {
+		for _, stats := range source.LatencyReport() {
+			entries = append(entries, latencyEntry{Network: source.Network(), LatencyStats: stats})
+		}
+	}
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{"sources": entries})
+}
+
+// handleHealth serves GET /healthz and /readyz, the health report with
+// status 200 when ok holds for it and 503 otherwise, for liveness and
+// readiness probes
+func (s *Server) handleHealth(ok func(monitor.Health) bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		if s.health == nil {
+			s.writeError(w, http.StatusNotFound, "health checks are disabled")
+			return
+		}
+
+		health := s.health.Health(r.Context())
+		status := http.StatusOK
+		if !ok(health) {
+			status = http.StatusServiceUnavailable
+		}
+		s.writeJSON(w, status, health)
+	}
+}
+
+// writeJSON writes a JSON response
+func (s *Server) writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		s.log.Debug("Failed to write API response", "error", err)
+	}
+}
+
+// writeError writes a JSON error response
+func (s *Server) writeError(w http.ResponseWriter, status int, message string) {
+	s.writeJSON(w, status, map[string]string{"error": message})
+}
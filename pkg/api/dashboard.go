@@ -0,0 +1,292 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/i18n"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/notify"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// dashboardPage is the single page of the web dashboard, which reads the
+// /dashboard/ endpoints. It's embedded from a file excluded by the
+// nodashboard tag, nil in builds without it; the endpoints stay.
+var dashboardPage []byte
+
+// DefaultEventLogSize is how many events the dashboard timeline keeps
+const DefaultEventLogSize = 500
+
+// defaultHistoryLimit is how many of the latest snapshots the charts cover
+// unless ?limit= says otherwise
+const defaultHistoryLimit = 90
+
+// LoggedEvent is an event of the dashboard timeline
+type LoggedEvent struct {
+	// ID increases with every event, so clients ask for the events after
+	// the last one they have
+	ID      int64         `json:"id"`
+	At      time.Time     `json:"at"`
+	Summary string        `json:"summary"`
+	Event   monitor.Event `json:"event"`
+}
+
+// EventLog keeps the latest events of the monitors for the dashboard. Its
+// Handle method is a monitor.EventHandler.
+type EventLog struct {
+	mu      sync.Mutex
+	size    int
+	lastID  int64
+	events  []LoggedEvent
+	symbols map[string]string
+}
+
+// NewEventLog creates an event log keeping the latest size events
+func NewEventLog(size int) *EventLog {
+	if size <= 0 {
+		size = DefaultEventLogSize
+	}
+	return &EventLog{
+		size:    size,
+		symbols: map[string]string{solana.NativeMint: "SOL"},
+	}
+}
+
+// Handle adds an event to the log and learns the symbol of its mint
+func (l *EventLog) Handle(event monitor.Event) {
+	summary := notify.FormatSummaryLine(i18n.DefaultLocale, event)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if event.Metadata != nil && event.Metadata.Symbol != "" {
+		l.symbols[event.Account.Mint] = event.Metadata.Symbol
+	}
+	l.lastID++
+	l.events = append(l.events, LoggedEvent{ID: l.lastID, At: time.Now().UTC(), Summary: summary, Event: event})
+	if len(l.events) > l.size {
+		l.events = l.events[len(l.events)-l.size:]
+	}
+}
+
+// after returns the events logged after the event with the given ID
+func (l *EventLog) after(id int64) []LoggedEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	index := sort.Search(len(l.events), func(i int) bool { return l.events[i].ID > id })
+	return append([]LoggedEvent(nil), l.events[index:]...)
+}
+
+// symbol returns the symbol seen for a mint, if any
+func (l *EventLog) symbol(mint string) string {
+	if l == nil {
+		return ""
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.symbols[mint]
+}
+
+// SetEventLog enables the event timeline of the dashboard. The log must be
+// registered with the monitors before they start.
+func (s *Server) SetEventLog(events *EventLog) {
+	s.events = events
+}
+
+// dashboardBalance is a token account of the dashboard balance table
+type dashboardBalance struct {
+	Wallet  string  `json:"wallet"`
+	Label   string  `json:"label,omitempty"`
+	Network string  `json:"network,omitempty"`
+	Account string  `json:"account"`
+	Mint    string  `json:"mint"`
+	Symbol  string  `json:"symbol,omitempty"`
+	Balance string  `json:"balance"`
+	Amount  float64 `json:"amount"`
+}
+
+// dashboardHistory is the balance of every mint, totalled over the wallets,
+// at the time of each snapshot
+type dashboardHistory struct {
+	Times  []time.Time       `json:"times"`
+	Series []dashboardSeries `json:"series"`
+}
+
+// dashboardSeries is the total balance of a mint in UI units, by snapshot.
+// Network is set for the mints of further networks.
+type dashboardSeries struct {
+	Network string    `json:"network,omitempty"`
+	Mint    string    `json:"mint"`
+	Symbol  string    `json:"symbol,omitempty"`
+	Values  []float64 `json:"values"`
+}
+
+// handleDashboard serves GET /dashboard, the web dashboard
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if dashboardPage == nil {
+		s.writeError(w, http.StatusNotFound, "the dashboard page is not available in this build (built with the nodashboard tag)")
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'unsafe-inline'; style-src 'unsafe-inline'")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Write(dashboardPage)
+}
+
+// handleDashboardData serves the data of the dashboard:
+// GET /dashboard/balances, the live token balances,
+// GET /dashboard/events?after=<id>, the latest events, and
+// GET /dashboard/history?limit=, the totals of the latest snapshots. Tenant
+// keys only get their wallets.
+func (s *Server) handleDashboardData(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	switch r.URL.Path {
+	case "/dashboard/":
+		http.Redirect(w, r, "/dashboard", http.StatusMovedPermanently)
+	case "/dashboard/balances":
+		s.writeJSON(w, http.StatusOK, s.dashboardBalances(r))
+	case "/dashboard/events":
+		var after int64
+		if value := r.URL.Query().Get("after"); value != "" {
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				s.writeError(w, http.StatusBadRequest, "invalid after")
+				return
+			}
+			after = parsed
+		}
+		s.writeJSON(w, http.StatusOK, s.dashboardEvents(r, after))
+	case "/dashboard/history":
+		limit := defaultHistoryLimit
+		if value := r.URL.Query().Get("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 {
+				s.writeError(w, http.StatusBadRequest, "invalid limit")
+				return
+			}
+			limit = parsed
+		}
+		history, err := s.dashboardHistory(r, limit)
+		if err != nil {
+			s.writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.writeJSON(w, http.StatusOK, history)
+	default:
+		s.writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// dashboardBalances returns the token accounts of every balance source,
+// ordered by wallet, network and mint
+func (s *Server) dashboardBalances(r *http.Request) []dashboardBalance {
+	wallets := s.tenantWallets(r)
+	balances := []dashboardBalance{}
+	for _, source := range s.balances {
+		network := source.Network()
+		for _, account := range source.GetCurrentState() {
+			if wallets != nil && !wallets[account.Owner] {
+				continue
+			}
+			balances = append(balances, dashboardBalance{
+				Wallet:  account.Owner,
+				Label:   source.WalletLabel(account.Owner).Label,
+				Network: network,
+				Account: account.Address,
+				Mint:    account.Mint,
+				Symbol:  s.events.symbol(account.Mint),
+				Balance: account.UIAmountString(),
+				Amount:  account.UIAmount(),
+			})
+		}
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		a, b := balances[i], balances[j]
+		if a.Wallet != b.Wallet {
+			return a.Wallet < b.Wallet
+		}
+		if a.Network != b.Network {
+			return a.Network < b.Network
+		}
+		if a.Mint != b.Mint {
+			return a.Mint < b.Mint
+		}
+		return a.Account < b.Account
+	})
+	return balances
+}
+
+// dashboardEvents returns the logged events after an ID
+func (s *Server) dashboardEvents(r *http.Request, after int64) []LoggedEvent {
+	events := []LoggedEvent{}
+	if s.events == nil {
+		return events
+	}
+	wallets := s.tenantWallets(r)
+	for _, event := range s.events.after(after) {
+		if wallets == nil || wallets[event.Event.Account.Owner] {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// dashboardHistory totals the balances of each mint in the latest limit
+// snapshots, which is empty without snapshots
+func (s *Server) dashboardHistory(r *http.Request, limit int) (*dashboardHistory, error) {
+	history := &dashboardHistory{Times: []time.Time{}, Series: []dashboardSeries{}}
+	if s.snapshots == nil {
+		return history, nil
+	}
+	snaps, err := s.snapshots.Latest(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	wallets := s.tenantWallets(r)
+	series := make(map[[2]string]*dashboardSeries)
+	add := func(i int, network string, accounts []solana.TokenAccountInfo) {
+		for _, account := range accounts {
+			if wallets != nil && !wallets[account.Owner] {
+				continue
+			}
+			key := [2]string{network, account.Mint}
+			mint := series[key]
+			if mint == nil {
+				mint = &dashboardSeries{Network: network, Mint: account.Mint, Symbol: s.events.symbol(account.Mint), Values: make([]float64, len(snaps))}
+				series[key] = mint
+			}
+			mint.Values[i] += account.UIAmount()
+		}
+	}
+	for i, snap := range snaps {
+		history.Times = append(history.Times, snap.TakenAt)
+		add(i, "", snap.Accounts)
+		for network, accounts := range snap.Networks {
+			add(i, network, accounts)
+		}
+	}
+
+	for _, mint := range series {
+		history.Series = append(history.Series, *mint)
+	}
+	sort.Slice(history.Series, func(i, j int) bool {
+		a, b := history.Series[i], history.Series[j]
+		if a.Network != b.Network {
+			return a.Network < b.Network
+		}
+		return a.Mint < b.Mint
+	})
+	return history, nil
+}
@@ -0,0 +1,14 @@
+//go:build !nodashboard
+
+package api
+
+import _ "embed"
+
+// page is the web dashboard, see dashboardPage
+//
+//go:embed dashboard.html
+var page []byte
+
+func init() {
+	dashboardPage = page
+}
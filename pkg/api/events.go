@@ -0,0 +1,156 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/flow"
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+)
+
+// Page sizes of GET /events
+const (
+	defaultEventsLimit = 100
+	maxEventsLimit     = 1000
+)
+
+// eventsPage is a response of GET /events
+type eventsPage struct {
+	Events []store.StoredEvent `json:"events"`
+	// Next is the cursor of the following page, the ID of the last event,
+	// or the requested cursor when there are no newer events
+	Next int64 `json:"next"`
+}
+
+// SetEventStore serves GET /events?after=<cursor>&limit=N, the stored
+// events after a cursor, oldest first, and GET /flows, the net flows they
+// add up to
+func (s *Server) SetEventStore(events store.EventStore) {
+	s.eventStore = events
+}
+
+// handleEvents serves GET /events. Tenants only get the events of their
+// wallets.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.eventStore == nil {
+		s.writeError(w, http.StatusNotFound, "the event log is disabled")
+		return
+	}
+
+	query := r.URL.Query()
+	var after int64
+	if value := query.Get("after"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < 0 {
+			s.writeError(w, http.StatusBadRequest, "invalid after")
+			return
+		}
+		after = parsed
+	}
+	limit := defaultEventsLimit
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxEventsLimit {
+			s.writeError(w, http.StatusBadRequest, "invalid limit: must be between 1 and "+strconv.Itoa(maxEventsLimit))
+			return
+		}
+		limit = parsed
+	}
+
+	events, err := s.eventStore.EventsAfter(r.Context(), after, limit, s.tenantWalletList(r))
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	page := eventsPage{Events: events, Next: after}
+	if len(events) > 0 {
+		page.Next = events[len(events)-1].ID
+	} else {
+		page.Events = []store.StoredEvent{}
+	}
+	s.writeJSON(w, http.StatusOK, page)
+}
+
+// handleFlows serves GET /flows, the net inflows and outflows per wallet and
+// mint and per mint by day, week or in total with ?by=, optionally within a
+// ?window= and for one ?wallet= or ?mint=, as CSV with ?format=csv. Tenants
+// only get the flows of their wallets.
+func (s *Server) handleFlows(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.eventStore == nil {
+		s.writeError(w, http.StatusNotFound, "the event log is disabled")
+		return
+	}
+
+	query := r.URL.Query()
+	var from time.Time
+	if value := query.Get("window"); value != "" {
+		window, err := time.ParseDuration(value)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "invalid window: "+err.Error())
+			return
+		}
+		from = time.Now().Add(-window)
+	}
+	by := query.Get("by")
+	if by == "" {
+		by = flow.ByDay
+	}
+	format := query.Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		s.writeError(w, http.StatusBadRequest, "invalid format: use json or csv")
+		return
+	}
+
+	wallets := s.tenantWalletList(r)
+	if wallet := query.Get("wallet"); wallet != "" {
+		wallet, ok := s.walletParam(w, r, wallet)
+		if !ok || !s.allowWallet(w, r, wallet) {
+			return
+		}
+		wallets = []string{wallet}
+	}
+	events, err := flow.Load(r.Context(), s.eventStore, from, time.Time{}, wallets)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	report, err := flow.Summarize(events, by)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if mint := query.Get("mint"); mint != "" {
+		report = report.ForMint(mint)
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		flow.WriteCSV(w, report)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, report)
+}
+
+// tenantWalletList returns the wallets the tenant of a request may see, or
+// nil when it may see every wallet
+func (s *Server) tenantWalletList(r *http.Request) []string {
+	allowed := s.tenantWallets(r)
+	if allowed == nil {
+		return nil
+	}
+	wallets := make([]string, 0, len(allowed))
+	for wallet := range allowed {
+		wallets = append(wallets, wallet)
+	}
+	return wallets
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/notify"
+)
+
+// HandlerController turns the event handlers of the monitors and the
+// notifiers of the dispatchers on and off by name
+type HandlerController interface {
+	Handlers() []monitor.HandlerState
+	SetHandlerEnabled(name string, enabled bool) error
+	Notifiers() []notify.NotifierState
+	SetNotifierEnabled(name string, enabled bool) error
+}
+
+// SetHandlerController serves GET /handlers, which lists the handlers and
+// notifiers, and POST /handlers/{name}/{enable|disable} and
+// /notifiers/{name}/{enable|disable}, which turn them on and off
+func (s *Server) SetHandlerController(controller HandlerController) {
+	s.toggles = controller
+}
+
+// handleHandlers serves GET /handlers
+func (s *Server) handleHandlers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.toggles == nil {
+		s.writeError(w, http.StatusNotFound, "handler control is disabled")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"handlers":  s.toggles.Handlers(),
+		"notifiers": s.toggles.Notifiers(),
+	})
+}
+
+// handleToggle serves POST /{kind}s/{name}/{enable|disable} with set, which
+// turns a handler or notifier on or off
+func (s *Server) handleToggle(kind string, set func(HandlerController, string, bool) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/"+kind+"s/")
+		slash := strings.LastIndex(path, "/")
+		if slash <= 0 {
+			s.writeError(w, http.StatusNotFound, "not found")
+			return
+		}
+		name, action := path[:slash], path[slash+1:]
+		if action != "enable" && action != "disable" {
+			s.writeError(w, http.StatusNotFound, "not found")
+			return
+		}
+		if s.toggles == nil {
+			s.writeError(w, http.StatusNotFound, "handler control is disabled")
+			return
+		}
+		if r.Method != http.MethodPost {
+			s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		enabled := action == "enable"
+		if err := set(s.toggles, name, enabled); err != nil {
+			if errors.Is(err, monitor.ErrUnknownHandler) || errors.Is(err, notify.ErrUnknownNotifier) {
+				s.writeError(w, http.StatusNotFound, err.Error())
+				return
+			}
+			s.writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.log.Info("Changed "+kind+" over the API", kind, name, "enabled", enabled)
+		s.writeJSON(w, http.StatusOK, map[string]interface{}{
+			"name":    name,
+			"enabled": enabled,
+		})
+	}
+}
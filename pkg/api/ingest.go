@@ -0,0 +1,59 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/ingest"
+)
+
+// maxIngestBody bounds the webhook deliveries read by the ingest endpoints
+const maxIngestBody = 10 << 20
+
+// Ingestor applies the transactions delivered by webhook providers
+type Ingestor interface {
+	Ingest(transactions []ingest.Transaction)
+}
+
+// SetIngestor serves POST /ingest/helius and POST /ingest/quicknode, which
+// receive webhook deliveries and pass their transactions to the ingestor.
+// They don't take API keys; deliveries are authenticated with the secrets of
+// their provider instead.
+func (s *Server) SetIngestor(ingestor Ingestor, secrets ingest.Secrets) {
+	s.ingestor = ingestor
+	s.ingestSecrets = secrets
+}
+
+// handleIngest serves POST /ingest/{provider}
+func (s *Server) handleIngest(w http.ResponseWriter, r *http.Request) {
+	provider := strings.TrimPrefix(r.URL.Path, "/ingest/")
+	if s.ingestor == nil {
+		s.writeError(w, http.StatusNotFound, "ingestion is disabled")
+		return
+	}
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxIngestBody))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "failed to read delivery")
+		return
+	}
+	transactions, err := ingest.Parse(provider, s.ingestSecrets, r.Header, body)
+	if errors.Is(err, ingest.ErrUnauthorized) {
+		s.log.Warn("Rejected webhook delivery with an invalid signature", "provider", provider)
+		s.writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.ingestor.Ingest(transactions)
+	s.writeJSON(w, http.StatusOK, map[string]int{"transactions": len(transactions)})
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// metricsContentType is the Prometheus text exposition format
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// BalanceSource holds the live token account state of a network
+type BalanceSource interface {
+	GetCurrentState() map[string]solana.TokenAccountInfo
+	WalletLabel(wallet string) monitor.WalletLabel
+	Network() string
+}
+
+// labelEscaper escapes label values as the text format expects
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// balanceLabels are the labels of the balance gauges, in order
+var balanceLabels = []string{"wallet", "label", "tenants", "mint", "account", "network"}
+
+// Labels of the gauges of balances summed over every wallet and over the
+// wallets of a group, in order
+var (
+	totalLabels = []string{"mint", "network"}
+	groupLabels = []string{"group", "mint", "network"}
+)
+
+// SetBalanceSources enables the Prometheus metrics endpoint, with the token
+// balances of every source
+func (s *Server) SetBalanceSources(sources ...BalanceSource) {
+	s.balances = sources
+}
+
+// handleMetrics serves GET /metrics, the token balances as Prometheus gauges
+// in UI units, also summed by mint over every wallet and over the wallets of
+// each group. Tenant keys only get the balances of their wallets, and sums
+// over those.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if len(s.balances) == 0 {
+		s.writeError(w, http.StatusNotFound, "metrics are disabled")
+		return
+	}
+
+	wallets := s.tenantWallets(r)
+	var amounts, raw []gaugeSample
+	// sums are in base units, keyed by group, empty for every wallet, mint
+	// and network, as big integers since they can overflow a uint64
+	sums := make(map[[3]string]*big.Int)
+	// decimals are keyed by network and mint, as a mint address on one
+	// network says nothing about the same address on another
+	decimals := make(map[[2]string]uint8)
+	sum := func(group, network string, account solana.TokenAccountInfo) {
+		key := [3]string{group, account.Mint, network}
+		if sums[key] == nil {
+			sums[key] = new(big.Int)
+		}
+		sums[key].Add(sums[key], new(big.Int).SetUint64(account.Balance))
+		decimals[[2]string{network, account.Mint}] = account.Decimals
+	}
+	for _, source := range s.balances {
+		network := source.Network()
+		for _, account := range source.GetCurrentState() {
+			if wallets != nil && !wallets[account.Owner] {
+				continue
+			}
+			label := source.WalletLabel(account.Owner)
+			values := []string{account.Owner, label.Label, strings.Join(label.Tenants, ","),
+				account.Mint, account.Address, network}
+			amounts = append(amounts, gaugeSample{labels: values, value: account.UIAmount()})
+			raw = append(raw, gaugeSample{labels: values, value: float64(account.Balance)})
+			sum("", network, account)
+			for _, group := range label.Groups {
+				sum(group, network, account)
+			}
+		}
+	}
+	var totals, groups []gaugeSample
+	for key, balance := range sums {
+		key := key
+		value := solana.BigUIAmount(balance, decimals[[2]string{key[2], key[1]}])
+		if key[0] == "" {
+			totals = append(totals, gaugeSample{labels: key[1:], value: value})
+		} else {
+			groups = append(groups, gaugeSample{labels: key[:], value: value})
+		}
+	}
+
+	w.Header().Set("Content-Type", metricsContentType)
+	out := bufio.NewWriter(w)
+	writeGauge(out, "solana_tracker_token_balance",
+		"Balance of a tracked token account in UI units, adjusted for the decimals of its mint", balanceLabels, amounts)
+	writeGauge(out, "solana_tracker_token_balance_raw",
+		"Balance of a tracked token account in base units", balanceLabels, raw)
+	writeGauge(out, "solana_tracker_total_balance",
+		"Balance of a mint summed over every tracked wallet, in UI units", totalLabels, totals)
+	writeGauge(out, "solana_tracker_group_balance",
+		"Balance of a mint summed over the wallets of a group, in UI units", groupLabels, groups)
+	out.Flush()
+}
+
+// gaugeSample is a value of a gauge with its label values
+type gaugeSample struct {
+	labels []string
+	value  float64
+}
+
+// writeGauge writes a gauge in the Prometheus text format, its samples
+// sorted by label values so scrapes are stable
+func writeGauge(w *bufio.Writer, name, help string, labels []string, samples []gaugeSample) {
+	sort.Slice(samples, func(i, j int) bool {
+		return strings.Join(samples[i].labels, "\x00") < strings.Join(samples[j].labels, "\x00")
+	})
+
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+	for _, sample := range samples {
+		pairs := make([]string, 0, len(labels))
+		for i, label := range labels {
+			if sample.labels[i] != "" {
+				pairs = append(pairs, label+`="`+labelEscaper.Replace(sample.labels[i])+`"`)
+			}
+		}
+		fmt.Fprintf(w, "%s{%s} %s\n", name, strings.Join(pairs, ","), strconv.FormatFloat(sample.value, 'g', -1, 64))
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// balanceSource is a BalanceSource of fixed accounts
+type balanceSource struct {
+	network  string
+	accounts []solana.TokenAccountInfo
+}
+
+func (b balanceSource) GetCurrentState() map[string]solana.TokenAccountInfo {
+	state := make(map[string]solana.TokenAccountInfo, len(b.accounts))
+	for _, account := range b.accounts {
+		state[account.Owner+":"+account.Mint] = account
+	}
+	return state
+}
+
+func (b balanceSource) WalletLabel(wallet string) monitor.WalletLabel {
+	return monitor.WalletLabel{}
+}
+
+func (b balanceSource) Network() string {
+	return b.network
+}
+
+func TestMetricsTotalsPerNetwork(t *testing.T) {
+	s := NewServer("", nil)
+	// The same mint address has other decimals on the other network
+	s.SetBalanceSources(
+		balanceSource{network: "mainnet-beta", accounts: []solana.TokenAccountInfo{
+			{Owner: "w1", Mint: "mint", Address: "a1", Balance: 1500000, Decimals: 6},
+			{Owner: "w2", Mint: "mint", Address: "a2", Balance: 500000, Decimals: 6},
+		}},
+		balanceSource{network: "devnet", accounts: []solana.TokenAccountInfo{
+			{Owner: "w3", Mint: "mint", Address: "a3", Balance: 300, Decimals: 2},
+		}},
+	)
+
+	recorder := httptest.NewRecorder()
+	s.handleMetrics(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body := recorder.Body.String()
+
+	for _, want := range []string{
+		`solana_tracker_total_balance{mint="mint",network="mainnet-beta"} 2`,
+		`solana_tracker_total_balance{mint="mint",network="devnet"} 3`,
+	} {
+		if !strings.Contains(body, want+"\n") {
+			t.Errorf("metrics lack %s:\n%s", want, body)
+		}
+	}
+}
+
+func TestMetricsTotalsBeyondUint64(t *testing.T) {
+	s := NewServer("", nil)
+	s.SetBalanceSources(balanceSource{network: "mainnet-beta", accounts: []solana.TokenAccountInfo{
+		{Owner: "w1", Mint: "mint", Address: "a1", Balance: math.MaxUint64, Decimals: 0},
+		{Owner: "w2", Mint: "mint", Address: "a2", Balance: math.MaxUint64, Decimals: 0},
+	}})
+
+	recorder := httptest.NewRecorder()
+	s.handleMetrics(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body := recorder.Body.String()
+
+	// 2 * (2^64 - 1) rather than a wrapped 2^64 - 2
+	want := `solana_tracker_total_balance{mint="mint",network="mainnet-beta"} 3.6893488147419103e+19`
+	if !strings.Contains(body, want+"\n") {
+		t.Errorf("metrics lack %s:\n%s", want, body)
+	}
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+)
+
+// Timing of GET /events/stream
+const (
+	// streamPollInterval is how often an idle stream looks for new events
+	streamPollInterval = time.Second
+	// streamPingInterval is how often an idle stream pings the client,
+	// which must answer within two intervals
+	streamPingInterval = 30 * time.Second
+	streamWriteTimeout = 10 * time.Second
+)
+
+// streamUpgrader upgrades GET /events/stream. Clients authenticate with
+// headers, which browsers can't set on WebSockets, so any origin may
+// connect.
+var streamUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// streamWindow is a message of GET /events/stream: the events after the
+// acknowledged cursor, up to the window size
+type streamWindow struct {
+	Events []store.StoredEvent `json:"events"`
+	// Next is the ID of the last event, to acknowledge once the window is
+	// handled
+	Next int64 `json:"next"`
+}
+
+// streamAck is the message a client acknowledges events with
+type streamAck struct {
+	// Ack is the ID of the last handled event of the outstanding window
+	Ack int64 `json:"ack"`
+}
+
+// handleEventStream serves GET /events/stream?after=<cursor>&window=N, a
+// WebSocket streaming the stored events after a cursor. The events are
+// sent in windows of up to window events; the next window follows once the
+// client acknowledged the last event of the outstanding one, so at most
+// one window is unacknowledged. A client that disconnects resumes with its
+// last acknowledged ID as after and gets every event it didn't acknowledge
+// again. Tenants only get the events of their wallets.
+func (s *Server) handleEventStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.eventStore == nil {
+		s.writeError(w, http.StatusNotFound, "the event log is disabled")
+		return
+	}
+
+	query := r.URL.Query()
+	var cursor int64
+	if value := query.Get("after"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < 0 {
+			s.writeError(w, http.StatusBadRequest, "invalid after")
+			return
+		}
+		cursor = parsed
+	}
+	window := defaultEventsLimit
+	if value := query.Get("window"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxEventsLimit {
+			s.writeError(w, http.StatusBadRequest, "invalid window: must be between 1 and "+strconv.Itoa(maxEventsLimit))
+			return
+		}
+		window = parsed
+	}
+	wallets := s.tenantWalletList(r)
+
+	conn, err := streamUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader answered the request
+		s.log.Debug("Failed to upgrade event stream", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	// Acknowledgments are read apart from the writes, which the loop below
+	// owns
+	acks := make(chan int64)
+	go func() {
+		defer cancel()
+		deadline := func() { conn.SetReadDeadline(time.Now().Add(2 * streamPingInterval)) }
+		deadline()
+		conn.SetPongHandler(func(string) error {
+			deadline()
+			return nil
+		})
+		for {
+			var ack streamAck
+			if err := conn.ReadJSON(&ack); err != nil {
+				if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+					s.log.Debug("Event stream closed", "error", err)
+				}
+				return
+			}
+			deadline()
+			select {
+			case acks <- ack.Ack:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	poll := time.NewTicker(streamPollInterval)
+	defer poll.Stop()
+	lastWrite := time.Now()
+	// outstanding is the ID of the last event sent and not yet acknowledged,
+	// zero when no window is outstanding
+	var outstanding int64
+
+	for {
+		if outstanding == 0 {
+			events, err := s.eventStore.EventsAfter(ctx, cursor, window, wallets)
+			if err != nil {
+				if ctx.Err() == nil {
+					s.log.Error("Failed to read events for stream", "error", err)
+					s.closeStream(conn, websocket.CloseInternalServerErr, "failed to read events")
+				}
+				return
+			}
+			if len(events) > 0 {
+				outstanding = events[len(events)-1].ID
+				conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+				if err := conn.WriteJSON(streamWindow{Events: events, Next: outstanding}); err != nil {
+					return
+				}
+				lastWrite = time.Now()
+			}
+		}
+
+		select {
+		case ack := <-acks:
+			if outstanding == 0 || ack <= cursor || ack > outstanding {
+				s.closeStream(conn, websocket.ClosePolicyViolation,
+					"ack must be an ID of the outstanding window, after "+strconv.FormatInt(cursor, 10))
+				return
+			}
+			cursor = ack
+			if ack == outstanding {
+				outstanding = 0
+			}
+		case <-poll.C:
+			if time.Since(lastWrite) < streamPingInterval {
+				continue
+			}
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout)); err != nil {
+				return
+			}
+			lastWrite = time.Now()
+		case <-s.closing:
+			s.closeStream(conn, websocket.CloseGoingAway, "server shutting down")
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// closeStream ends an event stream with a close frame
+func (s *Server) closeStream(conn *websocket.Conn, code int, reason string) {
+	message := websocket.FormatCloseMessage(code, reason)
+	conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(streamWriteTimeout))
+}
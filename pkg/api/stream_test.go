@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+)
+
+func TestEventStream(t *testing.T) {
+	ctx := context.Background()
+	events, err := store.NewFileStore(filepath.Join(t.TempDir(), "history.jsonl"), nil)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	defer events.Close()
+	for i := 0; i < 5; i++ {
+		if _, err := events.AppendEvent(ctx, store.StoredEvent{Wallet: "wallet", At: time.Now().UTC(), Event: json.RawMessage(`{}`)}); err != nil {
+			t.Fatalf("AppendEvent: %v", err)
+		}
+	}
+
+	s := NewServer("", nil)
+	s.SetEventStore(events)
+	server := httptest.NewServer(s.server.Handler)
+	defer server.Close()
+
+	dial := func(after int64) *websocket.Conn {
+		url := strings.Replace(server.URL, "http", "ws", 1) + "/events/stream?window=2&after=" + strconv.FormatInt(after, 10)
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Fatalf("Dial: %v", err)
+		}
+		return conn
+	}
+	receive := func(conn *websocket.Conn) streamWindow {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var window streamWindow
+		if err := conn.ReadJSON(&window); err != nil {
+			t.Fatalf("ReadJSON: %v", err)
+		}
+		return window
+	}
+	ids := func(window streamWindow) []int64 {
+		var result []int64
+		for _, event := range window.Events {
+			result = append(result, event.ID)
+		}
+		return result
+	}
+
+	conn := dial(0)
+	if got := ids(receive(conn)); !equalInt64s(got, []int64{1, 2}) {
+		t.Fatalf("first window %v, want [1 2]", got)
+	}
+	// A partial acknowledgment advances the cursor without a new window
+	conn.WriteJSON(streamAck{Ack: 1})
+	conn.WriteJSON(streamAck{Ack: 2})
+	if got := ids(receive(conn)); !equalInt64s(got, []int64{3, 4}) {
+		t.Fatalf("second window %v, want [3 4]", got)
+	}
+	// Disconnect with the window unacknowledged and resume from the last
+	// acknowledged ID
+	conn.Close()
+
+	conn = dial(2)
+	defer conn.Close()
+	if got := ids(receive(conn)); !equalInt64s(got, []int64{3, 4}) {
+		t.Fatalf("resumed window %v, want [3 4]", got)
+	}
+	conn.WriteJSON(streamAck{Ack: 4})
+	window := receive(conn)
+	if got := ids(window); !equalInt64s(got, []int64{5}) || window.Next != 5 {
+		t.Fatalf("last window %v next %d, want [5] next 5", got, window.Next)
+	}
+
+	// Events appended while the client waits are streamed once the window
+	// is acknowledged
+	conn.WriteJSON(streamAck{Ack: 5})
+	if _, err := events.AppendEvent(ctx, store.StoredEvent{Wallet: "wallet", At: time.Now().UTC(), Event: json.RawMessage(`{}`)}); err != nil {
+		t.Fatalf("AppendEvent: %v", err)
+	}
+	if got := ids(receive(conn)); !equalInt64s(got, []int64{6}) {
+		t.Fatalf("new window %v, want [6]", got)
+	}
+
+	// Acknowledging outside the window ends the stream
+	conn.WriteJSON(streamAck{Ack: 9})
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+		t.Fatalf("got %v after a bad ack, want a policy violation close", err)
+	}
+}
+
+// equalInt64s compares IDs
+func equalInt64s(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
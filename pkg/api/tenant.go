@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// Tenant is a team sharing the tracker. Its API keys only see the wallets
+// listed for it, see Wallet.Tenants.
+type Tenant struct {
+	Name string
+	Keys []string
+}
+
+// apiKey is an accepted API key. Admin keys have no tenant.
+type apiKey struct {
+	key    string
+	tenant string
+}
+
+// tenantContextKey keys the tenant of a request in its context
+type tenantContextKey struct{}
+
+// SetAPIKeys requires an API key on every request but the health probes and
+// webhook deliveries, given as "Authorization: Bearer <key>",
+// "X-API-Key: <key>" or, for browsers opening the dashboard, as the password
+// of HTTP basic authentication with any user name. Admin keys see every wallet; tenant keys only the
+// wallets of their tenant and none of the endpoints totalling every wallet,
+// such as groups and snapshots.
+func (s *Server) SetAPIKeys(adminKeys []string, tenants []Tenant) {
+	s.keys = nil
+	for _, key := range adminKeys {
+		s.keys = append(s.keys, apiKey{key: key})
+	}
+	for _, tenant := range tenants {
+		for _, key := range tenant.Keys {
+			s.keys = append(s.keys, apiKey{key: key, tenant: tenant.Name})
+		}
+	}
+}
+
+// authenticate rejects requests without a valid API key once keys are set
+// and passes the tenant of the key on in the request context
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Webhook deliveries are authenticated by their provider's secret
+		if len(s.keys) == 0 || r.URL.Path == "/healthz" || r.URL.Path == "/readyz" || strings.HasPrefix(r.URL.Path, "/ingest/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		given := r.Header.Get("X-API-Key")
+		if bearer := r.Header.Get("Authorization"); strings.HasPrefix(bearer, "Bearer ") {
+			given = strings.TrimPrefix(bearer, "Bearer ")
+		} else if _, password, ok := r.BasicAuth(); ok {
+			given = password
+		}
+		// Compare every key in constant time so timing reveals none
+		var matched *apiKey
+		for i := range s.keys {
+			if subtle.ConstantTimeCompare([]byte(given), []byte(s.keys[i].key)) == 1 {
+				matched = &s.keys[i]
+			}
+		}
+		if given == "" || matched == nil {
+			// Browsers ask for basic credentials, and send them along
+			// with the requests of the dashboard page
+			if r.URL.Path == "/dashboard" || strings.HasPrefix(r.URL.Path, "/dashboard/") {
+				w.Header().Set("WWW-Authenticate", `Basic realm="solana-wallet-tracker", charset="UTF-8"`)
+			} else {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+			}
+			s.writeError(w, http.StatusUnauthorized, "missing or invalid API key")
+			return
+		}
+
+		if matched.tenant != "" {
+			r = r.WithContext(context.WithValue(r.Context(), tenantContextKey{}, matched.tenant))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// adminOnly rejects requests of tenant keys to endpoints covering every
+// wallet
+func (s *Server) adminOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if tenant := tenantOf(r); tenant != "" {
+			s.writeError(w, http.StatusForbidden, "not available to the keys of tenant "+tenant)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// tenantOf returns the tenant of a request, which is empty for admin keys
+// and when no keys are set
+func tenantOf(r *http.Request) string {
+	tenant, _ := r.Context().Value(tenantContextKey{}).(string)
+	return tenant
+}
+
+// tenantWallets returns the current addresses of the wallets the tenant of
+// a request may see, or nil when it may see every wallet
+func (s *Server) tenantWallets(r *http.Request) map[string]bool {
+	tenant := tenantOf(r)
+	if tenant == "" {
+		return nil
+	}
+	wallets := make(map[string]bool)
+	for _, wallet := range s.wallets {
+		if inTenant(wallet, tenant) {
+			wallets[wallet.Address] = true
+			wallets[s.address(r.Context(), wallet)] = true
+		}
+	}
+	return wallets
+}
+
+// allowWallet reports whether the tenant of a request may see a wallet. It
+// writes an error response and returns false if not.
+func (s *Server) allowWallet(w http.ResponseWriter, r *http.Request, wallet string) bool {
+	if wallets := s.tenantWallets(r); wallets != nil && !wallets[wallet] {
+		s.writeError(w, http.StatusForbidden, "wallet is not on the watchlist of tenant "+tenantOf(r))
+		return false
+	}
+	return true
+}
+
+// inTenant reports whether a wallet is on the watchlist of a tenant
+func inTenant(wallet Wallet, tenant string) bool {
+	for _, t := range wallet.Tenants {
+		if t == tenant {
+			return true
+		}
+	}
+	return false
+}
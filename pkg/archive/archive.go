@@ -0,0 +1,190 @@
+package archive
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+)
+
+// FormatVersion is the current archive format version
+const FormatVersion = 1
+
+// Archive entry names
+const (
+	manifestEntry = "manifest.json"
+	historyEntry  = "history.jsonl"
+	snapshotEntry = "snapshot.json"
+	walletsEntry  = "wallets.json"
+)
+
+// Manifest describes the contents of a state archive
+type Manifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Records   int       `json:"records"`
+	Accounts  int       `json:"accounts"`
+}
+
+// Export writes all stored history and the latest balance snapshot as a
+// gzipped tar archive
+func Export(ctx context.Context, st store.Store, w io.Writer) (*Manifest, error) {
+	records, err := st.Records(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var history bytes.Buffer
+	for _, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return nil, err
+		}
+		history.Write(append(data, '\n'))
+	}
+
+	snapshot := store.LatestBalances(records)
+	snapshotData, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	wallets, err := st.Wallets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wallets: %w", err)
+	}
+	walletsData, err := json.MarshalIndent(wallets, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		Version:   FormatVersion,
+		CreatedAt: time.Now().UTC(),
+		Records:   len(records),
+		Accounts:  len(snapshot),
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	entries := []struct {
+		name string
+		data []byte
+	}{
+		{manifestEntry, manifestData},
+		{historyEntry, history.Bytes()},
+		{snapshotEntry, snapshotData},
+		{walletsEntry, walletsData},
+	}
+	for _, entry := range entries {
+		header := &tar.Header{
+			Name:    entry.name,
+			Mode:    0644,
+			Size:    int64(len(entry.data)),
+			ModTime: manifest.CreatedAt,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(entry.data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// Import reads an archive produced by Export and saves its history into st
+func Import(ctx context.Context, st store.Store, r io.Reader) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+	defer gz.Close()
+
+	var manifest *Manifest
+	imported := 0
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive: %w", err)
+		}
+
+		switch header.Name {
+		case manifestEntry:
+			manifest = &Manifest{}
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return nil, fmt.Errorf("invalid manifest: %w", err)
+			}
+			if manifest.Version > FormatVersion {
+				return nil, fmt.Errorf("unsupported archive version %d", manifest.Version)
+			}
+		case historyEntry:
+			if manifest == nil {
+				return nil, fmt.Errorf("invalid archive: %s must precede %s", manifestEntry, historyEntry)
+			}
+			scanner := bufio.NewScanner(tr)
+			scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+			for scanner.Scan() {
+				var record solana.TokenAccountInfo
+				if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+					return nil, fmt.Errorf("invalid history record %d: %w", imported+1, err)
+				}
+				if err := st.SaveBalance(ctx, record); err != nil {
+					return nil, err
+				}
+				imported++
+			}
+			if err := scanner.Err(); err != nil {
+				return nil, err
+			}
+		case walletsEntry:
+			var wallets []store.WalletStatus
+			if err := json.NewDecoder(tr).Decode(&wallets); err != nil {
+				return nil, fmt.Errorf("invalid wallets: %w", err)
+			}
+			for _, wallet := range wallets {
+				if !wallet.Archived() {
+					continue
+				}
+				if err := st.ArchiveWallet(ctx, wallet.Owner, *wallet.ArchivedAt); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if manifest == nil {
+		return nil, fmt.Errorf("invalid archive: missing %s", manifestEntry)
+	}
+	if imported != manifest.Records {
+		return nil, fmt.Errorf("archive is truncated: imported %d of %d records", imported, manifest.Records)
+	}
+
+	return manifest, nil
+}
@@ -0,0 +1,125 @@
+// Package aws signs requests to AWS APIs with Signature Version 4 and finds
+// the credentials to sign them with, without the AWS SDK.
+package aws
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Sign signs a request to service in region with AWS Signature Version 4.
+// The host and every header set on the request are signed; body must be
+// the request body.
+func Sign(req *http.Request, body []byte, creds Credentials, region, service string, now time.Time) {
+	now = now.UTC()
+	stamp := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", stamp)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	// The signed headers, sorted by name
+	headers := []string{"host"}
+	for name := range req.Header {
+		if name := strings.ToLower(name); name != "authorization" {
+			headers = append(headers, name)
+		}
+	}
+	sort.Strings(headers)
+	var canonicalHeaders strings.Builder
+	for _, name := range headers {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	signedHeaders := strings.Join(headers, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonical := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		hexSHA256(body),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	toSign := strings.Join([]string{"AWS4-HMAC-SHA256", stamp, scope, hexSHA256([]byte(canonical))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// Region returns the region of AWS_REGION or AWS_DEFAULT_REGION, looked up
+// with env, or os.LookupEnv when nil
+func Region(env func(string) (string, bool)) string {
+	if region := lookup(env, "AWS_REGION"); region != "" {
+		return region
+	}
+	return lookup(env, "AWS_DEFAULT_REGION")
+}
+
+// ARNRegion returns the region of an ARN, or "" for anything else
+func ARNRegion(arn string) string {
+	// arn:PARTITION:SERVICE:REGION:ACCOUNT:RESOURCE
+	if parts := strings.SplitN(arn, ":", 5); len(parts) == 5 && parts[0] == "arn" {
+		return parts[3]
+	}
+	return ""
+}
+
+// Endpoint returns the endpoint of a service in a region:
+// AWS_ENDPOINT_URL_<SERVICE>, e.g. AWS_ENDPOINT_URL_SECRETS_MANAGER, else
+// AWS_ENDPOINT_URL, else the public endpoint of the region. envName is the
+// service name of the variable, e.g. "SECRETS_MANAGER".
+func Endpoint(env func(string) (string, bool), service, envName, region string) string {
+	if endpoint := lookup(env, "AWS_ENDPOINT_URL_"+envName); endpoint != "" {
+		return endpoint
+	}
+	if endpoint := lookup(env, "AWS_ENDPOINT_URL"); endpoint != "" {
+		return endpoint
+	}
+	return fmt.Sprintf("https://%s.%s.amazonaws.com", service, region)
+}
+
+// lookup returns an environment variable, or "" when it isn't set
+func lookup(env func(string) (string, bool), name string) string {
+	if env == nil {
+		env = os.LookupEnv
+	}
+	value, _ := env(name)
+	return value
+}
+
+// hexSHA256 returns the hex SHA-256 of data
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,268 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Endpoints of the credential sources
+const (
+	ecsCredentialsHost = "http://169.254.170.2"
+	imdsEndpoint       = "http://169.254.169.254"
+	stsEndpoint        = "https://sts.amazonaws.com/"
+)
+
+// imdsTimeout bounds the lookups in the instance metadata service, which
+// doesn't answer outside EC2
+const imdsTimeout = 2 * time.Second
+
+// refreshBefore is how long before they expire temporary credentials are
+// fetched again
+const refreshBefore = 5 * time.Minute
+
+// Credentials sign requests. Temporary credentials carry a session token
+// and expire.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Expires is zero for long-term credentials
+	Expires time.Time
+}
+
+// CredentialChain finds credentials like the AWS SDKs do: the static ones,
+// else AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY with AWS_SESSION_TOKEN,
+// else the role of AWS_ROLE_ARN assumed with the web identity token of
+// AWS_WEB_IDENTITY_TOKEN_FILE (EKS service accounts), else the role of the
+// ECS task, else the role of the EC2 instance. Temporary credentials are
+// cached until shortly before they expire.
+type CredentialChain struct {
+	// Static credentials, such as those of the configuration, are used
+	// when set
+	Static Credentials
+	// Env looks up environment variables, os.LookupEnv when nil
+	Env func(string) (string, bool)
+	// ReadFile reads the web identity token, os.ReadFile when nil
+	ReadFile func(string) ([]byte, error)
+	// HTTPClient fetches temporary credentials, a client with a 10s
+	// timeout when nil
+	HTTPClient *http.Client
+
+	mu     sync.Mutex
+	cached Credentials
+}
+
+// Credentials returns the credentials to sign requests with
+func (c *CredentialChain) Credentials(ctx context.Context) (Credentials, error) {
+	if c.Static.AccessKeyID != "" && c.Static.SecretAccessKey != "" {
+		return c.Static, nil
+	}
+	if accessKey := lookup(c.Env, "AWS_ACCESS_KEY_ID"); accessKey != "" {
+		secretKey := lookup(c.Env, "AWS_SECRET_ACCESS_KEY")
+		if secretKey == "" {
+			return Credentials{}, errors.New("AWS_ACCESS_KEY_ID is set without AWS_SECRET_ACCESS_KEY")
+		}
+		return Credentials{AccessKeyID: accessKey, SecretAccessKey: secretKey, SessionToken: lookup(c.Env, "AWS_SESSION_TOKEN")}, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cached.AccessKeyID != "" && time.Until(c.cached.Expires) > refreshBefore {
+		return c.cached, nil
+	}
+
+	var creds Credentials
+	var err error
+	switch {
+	case lookup(c.Env, "AWS_WEB_IDENTITY_TOKEN_FILE") != "" && lookup(c.Env, "AWS_ROLE_ARN") != "":
+		creds, err = c.webIdentity(ctx)
+	case lookup(c.Env, "AWS_CONTAINER_CREDENTIALS_RELATIVE_URI") != "" || lookup(c.Env, "AWS_CONTAINER_CREDENTIALS_FULL_URI") != "":
+		creds, err = c.container(ctx)
+	default:
+		creds, err = c.instance(ctx)
+		if err != nil {
+			err = fmt.Errorf("no credentials in the configuration or the environment and the instance metadata service failed: %w", err)
+		}
+	}
+	if err != nil {
+		return Credentials{}, err
+	}
+	c.cached = creds
+	return creds, nil
+}
+
+// webIdentity assumes the role of AWS_ROLE_ARN with the token of
+// AWS_WEB_IDENTITY_TOKEN_FILE
+func (c *CredentialChain) webIdentity(ctx context.Context) (Credentials, error) {
+	readFile := c.ReadFile
+	if readFile == nil {
+		readFile = os.ReadFile
+	}
+	token, err := readFile(lookup(c.Env, "AWS_WEB_IDENTITY_TOKEN_FILE"))
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to read web identity token: %w", err)
+	}
+	session := lookup(c.Env, "AWS_ROLE_SESSION_NAME")
+	if session == "" {
+		session = "solana-wallet-tracker"
+	}
+
+	endpoint := stsEndpoint
+	if region := Region(c.Env); region != "" {
+		endpoint = fmt.Sprintf("https://sts.%s.amazonaws.com/", region)
+	}
+	form := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {lookup(c.Env, "AWS_ROLE_ARN")},
+		"RoleSessionName":  {session},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Credentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var res struct {
+		Credentials struct {
+			AccessKeyID     string    `xml:"AccessKeyId"`
+			SecretAccessKey string    `xml:"SecretAccessKey"`
+			SessionToken    string    `xml:"SessionToken"`
+			Expiration      time.Time `xml:"Expiration"`
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+	body, err := c.do(req)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to assume role with web identity: %w", err)
+	}
+	if err := xml.Unmarshal(body, &res); err != nil {
+		return Credentials{}, fmt.Errorf("failed to decode STS response: %w", err)
+	}
+	return Credentials{
+		AccessKeyID:     res.Credentials.AccessKeyID,
+		SecretAccessKey: res.Credentials.SecretAccessKey,
+		SessionToken:    res.Credentials.SessionToken,
+		Expires:         res.Credentials.Expiration,
+	}, nil
+}
+
+// container fetches the credentials of the ECS task role
+func (c *CredentialChain) container(ctx context.Context) (Credentials, error) {
+	endpoint := lookup(c.Env, "AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if relative := lookup(c.Env, "AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); relative != "" {
+		endpoint = ecsCredentialsHost + relative
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Credentials{}, err
+	}
+	if token := lookup(c.Env, "AWS_CONTAINER_AUTHORIZATION_TOKEN"); token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	creds, err := c.roleCredentials(req)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to get container credentials: %w", err)
+	}
+	return creds, nil
+}
+
+// instance fetches the credentials of the EC2 instance role through IMDSv2
+func (c *CredentialChain) instance(ctx context.Context) (Credentials, error) {
+	ctx, cancel := context.WithTimeout(ctx, imdsTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, imdsEndpoint+"/latest/api/token", nil)
+	if err != nil {
+		return Credentials{}, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "300")
+	token, err := c.do(req)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	const rolePath = "/latest/meta-data/iam/security-credentials/"
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, imdsEndpoint+rolePath, nil)
+	if err != nil {
+		return Credentials{}, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token", string(token))
+	roles, err := c.do(req)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to get instance role: %w", err)
+	}
+	role := strings.TrimSpace(strings.SplitN(string(roles), "\n", 2)[0])
+	if role == "" {
+		return Credentials{}, errors.New("the instance has no IAM role")
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, imdsEndpoint+rolePath+url.PathEscape(role), nil)
+	if err != nil {
+		return Credentials{}, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token", string(token))
+	return c.roleCredentials(req)
+}
+
+// roleCredentials fetches temporary credentials in the JSON format of the
+// ECS and EC2 metadata services
+func (c *CredentialChain) roleCredentials(req *http.Request) (Credentials, error) {
+	body, err := c.do(req)
+	if err != nil {
+		return Credentials{}, err
+	}
+	var res struct {
+		AccessKeyID     string    `json:"AccessKeyId"`
+		SecretAccessKey string    `json:"SecretAccessKey"`
+		Token           string    `json:"Token"`
+		Expiration      time.Time `json:"Expiration"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return Credentials{}, fmt.Errorf("failed to decode credentials: %w", err)
+	}
+	if res.AccessKeyID == "" || res.SecretAccessKey == "" {
+		return Credentials{}, errors.New("no credentials in response")
+	}
+	return Credentials{
+		AccessKeyID:     res.AccessKeyID,
+		SecretAccessKey: res.SecretAccessKey,
+		SessionToken:    res.Token,
+		Expires:         res.Expiration,
+	}, nil
+}
+
+// do sends a request and returns the body of a successful response
+func (c *CredentialChain) do(req *http.Request) ([]byte, error) {
+	client := c.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		if len(body) > 512 {
+			body = body[:512]
+		}
+		return nil, fmt.Errorf("unexpected status %s: %s", res.Status, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
@@ -0,0 +1,95 @@
+package backfill
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+)
+
+// Wallet stores the balances left by the last limit transactions of each
+// token account the wallet holds, oldest first, so its history doesn't
+// start empty. Only mints in tokens are covered unless tokens is empty.
+// Token accounts closed before the backfill aren't found. It returns the
+// number of records stored.
+func Wallet(ctx context.Context, client *solana.Client, st store.Store, wallet string, tokens []string, limit int) (int, error) {
+	accounts, err := client.GetTokenAccounts(ctx, wallet)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get token accounts: %w", err)
+	}
+
+	// A transaction touching several accounts of the wallet, such as a swap,
+	// is fetched once
+	transactions := make(map[string]*solana.Transaction)
+
+	var records []solana.TokenAccountInfo
+	for _, account := range accounts {
+		if !tracks(tokens, account.Mint) {
+			continue
+		}
+
+		signatures, err := client.GetRecentSignatures(ctx, account.Address, limit)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get signatures of %s: %w", account.Address, err)
+		}
+
+		for _, signature := range signatures {
+			if signature.Failed {
+				continue
+			}
+			tx, ok := transactions[signature.Signature]
+			if !ok {
+				if tx, err = client.GetTransaction(ctx, signature.Signature); err != nil {
+					if ctx.Err() != nil {
+						return 0, ctx.Err()
+					}
+					client.Log().Debug("Failed to fetch transaction for backfill", "signature", signature.Signature, "error", err)
+					continue
+				}
+				transactions[signature.Signature] = tx
+			}
+
+			for _, change := range tx.TokenBalances {
+				if change.Account != account.Address {
+					continue
+				}
+				record := account
+				record.Balance = change.Post
+				record.Delegate = ""
+				record.DelegatedAmount = 0
+				record.CloseAuthority = ""
+				record.Frozen = false
+				record.Slot = tx.Slot
+				record.Signature = tx.Signature
+				record.LastUpdatedAt = tx.BlockTime
+				records = append(records, record)
+			}
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Slot < records[j].Slot
+	})
+
+	for i, record := range records {
+		if err := st.SaveBalance(ctx, record); err != nil {
+			return i, fmt.Errorf("failed to save balance history: %w", err)
+		}
+	}
+	return len(records), nil
+}
+
+// tracks reports whether a mint is in the token filter
+func tracks(tokens []string, mint string) bool {
+	if len(tokens) == 0 {
+		return true
+	}
+	for _, token := range tokens {
+		if token == mint {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+var (
+	background = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	rising     = color.RGBA{R: 22, G: 163, B: 74, A: 255}
+	falling    = color.RGBA{R: 220, G: 38, B: 38, A: 255}
+)
+
+// Sparkline renders values as a small line chart and returns it PNG-encoded.
+// The line is green when the last value is not below the first, red otherwise.
+func Sparkline(values []float64, width, height int) ([]byte, error) {
+	if len(values) < 2 {
+		return nil, fmt.Errorf("sparkline needs at least 2 values, got %d", len(values))
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, background)
+		}
+	}
+
+	lineColor := rising
+	if values[len(values)-1] < values[0] {
+		lineColor = falling
+	}
+
+	// Scale values into the drawable area with a small padding
+	minValue, maxValue := values[0], values[0]
+	for _, v := range values {
+		if v < minValue {
+			minValue = v
+		}
+		if v > maxValue {
+			maxValue = v
+		}
+	}
+
+	const padding = 2
+	plotWidth := float64(width - 2*padding - 1)
+	plotHeight := float64(height - 2*padding - 1)
+
+	point := func(i int) (int, int) {
+		x := padding + int(float64(i)/float64(len(values)-1)*plotWidth)
+		y := padding + int(plotHeight/2)
+		if maxValue > minValue {
+			y = padding + int((maxValue-values[i])/(maxValue-minValue)*plotHeight)
+		}
+		return x, y
+	}
+
+	for i := 1; i < len(values); i++ {
+		x0, y0 := point(i - 1)
+		x1, y1 := point(i)
+		drawLine(img, x0, y0, x1, y1, lineColor)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawLine draws a line using Bresenham's algorithm
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.Color) {
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+
+	err := dx + dy
+	for {
+		img.Set(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,387 @@
+// Package clickhouse writes events and the transactions behind them to
+// ClickHouse for analytical queries, in batches over its HTTP interface
+// without a driver.
+package clickhouse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+)
+
+// Defaults of the writer
+const (
+	DefaultDatabase      = "default"
+	DefaultBatchSize     = 1000
+	DefaultFlushInterval = 5 * time.Second
+)
+
+// maxBuffered is how many rows of a table wait, e.g. while ClickHouse is
+// down, before the oldest are dropped
+const maxBuffered = 100000
+
+// requestTimeout bounds a request to ClickHouse
+const requestTimeout = 30 * time.Second
+
+// timeLayout formats DateTime64(3) values
+const timeLayout = "2006-01-02 15:04:05.000"
+
+// Tables of the writer
+const (
+	eventsTable       = "events"
+	transactionsTable = "transactions"
+)
+
+// schema creates the tables. Transactions are deduplicated on merges, as a
+// transaction is seen again when the same update arrives from a
+// subscription and a poll.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS %s.events (
+		time DateTime64(3, 'UTC'),
+		type LowCardinality(String),
+		severity LowCardinality(String),
+		network LowCardinality(String),
+		wallet String,
+		label String,
+		mint String,
+		symbol String,
+		account String,
+		balance UInt64,
+		previous_balance UInt64,
+		decimals UInt8,
+		ui_amount Float64,
+		ui_delta Float64,
+		slot UInt64,
+		signature String,
+		data String
+	) ENGINE = MergeTree
+	PARTITION BY toYYYYMM(time)
+	ORDER BY (wallet, mint, time)`,
+	`CREATE TABLE IF NOT EXISTS %s.transactions (
+		block_time DateTime64(3, 'UTC'),
+		signature String,
+		slot UInt64,
+		network LowCardinality(String),
+		wallet String,
+		mint String,
+		account String,
+		direction LowCardinality(String),
+		kind LowCardinality(String),
+		ui_delta Float64,
+		counterparties Array(String),
+		memo String
+	) ENGINE = ReplacingMergeTree
+	PARTITION BY toYYYYMM(block_time)
+	ORDER BY (wallet, mint, block_time, signature, account)`,
+}
+
+// eventRow is a row of the events table
+type eventRow struct {
+	Time            string  `json:"time"`
+	Type            string  `json:"type"`
+	Severity        string  `json:"severity"`
+	Network         string  `json:"network"`
+	Wallet          string  `json:"wallet"`
+	Label           string  `json:"label"`
+	Mint            string  `json:"mint"`
+	Symbol          string  `json:"symbol"`
+	Account         string  `json:"account"`
+	Balance         uint64  `json:"balance"`
+	PreviousBalance uint64  `json:"previous_balance"`
+	Decimals        uint8   `json:"decimals"`
+	UIAmount        float64 `json:"ui_amount"`
+	UIDelta         float64 `json:"ui_delta"`
+	Slot            uint64  `json:"slot"`
+	Signature       string  `json:"signature"`
+	// Data is the JSON of the whole event
+	Data string `json:"data"`
+}
+
+// transactionRow is a row of the transactions table
+type transactionRow struct {
+	BlockTime      string   `json:"block_time"`
+	Signature      string   `json:"signature"`
+	Slot           uint64   `json:"slot"`
+	Network        string   `json:"network"`
+	Wallet         string   `json:"wallet"`
+	Mint           string   `json:"mint"`
+	Account        string   `json:"account"`
+	Direction      string   `json:"direction"`
+	Kind           string   `json:"kind"`
+	UIDelta        float64  `json:"ui_delta"`
+	Counterparties []string `json:"counterparties"`
+	Memo           string   `json:"memo"`
+}
+
+// buffer holds the JSON rows of a table waiting to be inserted
+type buffer struct {
+	rows [][]byte
+	// dropped counts the oldest rows dropped to stay within maxBuffered,
+	// dropped since the last warning
+	dropped int
+	// trimmed counts every row dropped, so a flush knows how many of its
+	// rows are still at the front
+	trimmed int
+}
+
+// add adds a row, dropping the oldest rows beyond maxBuffered
+func (b *buffer) add(row []byte) {
+	b.rows = append(b.rows, row)
+	if extra := len(b.rows) - maxBuffered; extra > 0 {
+		b.rows = b.rows[extra:]
+		b.dropped += extra
+		b.trimmed += extra
+	}
+}
+
+// Writer buffers events and the transactions behind them and inserts them
+// in batches. Its Handle method is a monitor.EventHandler.
+type Writer struct {
+	endpoint      string
+	database      string
+	user          string
+	password      string
+	batchSize     int
+	flushInterval time.Duration
+	httpClient    *http.Client
+	log           logging.Logger
+
+	// flushMu keeps a batch from being inserted by two flushes at once
+	flushMu sync.Mutex
+
+	mu           sync.Mutex
+	events       buffer
+	transactions buffer
+	// full signals Run to flush before the interval when a batch is full
+	full chan struct{}
+}
+
+// NewWriter creates a writer to the HTTP interface of ClickHouse at
+// endpoint, e.g. http://localhost:8123. Zero values use the defaults, and a
+// nil log the default logger.
+func NewWriter(endpoint, database, user, password string, batchSize int, flushInterval time.Duration, log logging.Logger) *Writer {
+	if database == "" {
+		database = DefaultDatabase
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = DefaultFlushInterval
+	}
+	if log == nil {
+		log = logging.Default()
+	}
+	return &Writer{
+		endpoint:      strings.TrimSuffix(endpoint, "/") + "/",
+		database:      database,
+		user:          user,
+		password:      password,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		httpClient:    &http.Client{Timeout: requestTimeout},
+		log:           log,
+		full:          make(chan struct{}, 1),
+	}
+}
+
+// CreateSchema creates the events and transactions tables when missing.
+// The database must exist.
+func (w *Writer) CreateSchema(ctx context.Context) error {
+	for _, statement := range schema {
+		if err := w.exec(ctx, fmt.Sprintf(statement, quoteIdentifier(w.database)), nil); err != nil {
+			return fmt.Errorf("failed to create tables: %w", err)
+		}
+	}
+	return nil
+}
+
+// Handle buffers the row of an event and, for events with a resolved
+// transfer, the row of its transaction
+func (w *Writer) Handle(event monitor.Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		w.log.Warn("Failed to encode event for ClickHouse", "error", err)
+		return
+	}
+	at := event.Account.LastUpdatedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+	row := eventRow{
+		Time:      at.UTC().Format(timeLayout),
+		Type:      string(event.Type),
+		Severity:  event.Severity,
+		Network:   event.Network,
+		Wallet:    event.Account.Owner,
+		Label:     event.Label,
+		Mint:      event.Account.Mint,
+		Account:   event.Account.Address,
+		Balance:   event.Account.Balance,
+		Decimals:  event.Account.Decimals,
+		UIAmount:  event.Account.UIAmount(),
+		UIDelta:   event.Account.UIAmount(),
+		Slot:      event.Account.Slot,
+		Signature: event.Account.Signature,
+		Data:      string(data),
+	}
+	if row.Severity == "" {
+		row.Severity = monitor.SeverityInfo
+	}
+	if event.Metadata != nil {
+		row.Symbol = event.Metadata.Symbol
+	}
+	if event.Previous != nil {
+		row.PreviousBalance = event.Previous.Balance
+		row.UIDelta = event.Account.UIAmount() - event.Previous.UIAmount()
+	}
+	eventLine, _ := json.Marshal(row)
+
+	var transactionLine []byte
+	if transfer := event.Transfer; transfer != nil && transfer.Signature != "" {
+		counterparties := make([]string, 0, len(transfer.Counterparties))
+		for _, counterparty := range transfer.Counterparties {
+			counterparties = append(counterparties, counterparty.Owner)
+		}
+		blockTime := transfer.BlockTime
+		if blockTime.IsZero() {
+			blockTime = at
+		}
+		transactionLine, _ = json.Marshal(transactionRow{
+			BlockTime:      blockTime.UTC().Format(timeLayout),
+			Signature:      transfer.Signature,
+			Slot:           transfer.Slot,
+			Network:        event.Network,
+			Wallet:         event.Account.Owner,
+			Mint:           event.Account.Mint,
+			Account:        event.Account.Address,
+			Direction:      transfer.Direction,
+			Kind:           transfer.Kind,
+			UIDelta:        row.UIDelta,
+			Counterparties: counterparties,
+			Memo:           transfer.Memo,
+		})
+	}
+
+	w.mu.Lock()
+	w.events.add(eventLine)
+	if transactionLine != nil {
+		w.transactions.add(transactionLine)
+	}
+	full := len(w.events.rows) >= w.batchSize || len(w.transactions.rows) >= w.batchSize
+	w.mu.Unlock()
+	if full {
+		select {
+		case w.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run inserts the buffered rows each flush interval, or as soon as a batch
+// is full, until ctx is done
+func (w *Writer) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-w.full:
+		}
+		w.Flush(ctx)
+	}
+}
+
+// Flush inserts the buffered rows in batches. Rows of a failed insert stay
+// buffered for the next flush.
+func (w *Writer) Flush(ctx context.Context) {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+	w.flushTable(ctx, eventsTable, &w.events)
+	w.flushTable(ctx, transactionsTable, &w.transactions)
+}
+
+// flushTable inserts the buffered rows of a table, batchSize at a time
+func (w *Writer) flushTable(ctx context.Context, table string, buf *buffer) {
+	for {
+		w.mu.Lock()
+		if buf.dropped > 0 {
+			w.log.Warn("ClickHouse buffer is full, dropped the oldest rows", "table", table, "rows", buf.dropped)
+			buf.dropped = 0
+		}
+		n := len(buf.rows)
+		if n > w.batchSize {
+			n = w.batchSize
+		}
+		batch := buf.rows[:n:n]
+		trimmed := buf.trimmed
+		w.mu.Unlock()
+		if n == 0 {
+			return
+		}
+
+		query := fmt.Sprintf("INSERT INTO %s.%s FORMAT JSONEachRow", quoteIdentifier(w.database), table)
+		if err := w.exec(ctx, query, bytes.Join(batch, []byte("\n"))); err != nil {
+			w.log.Warn("Failed to insert into ClickHouse", "table", table, "rows", n, "error", err)
+			return
+		}
+
+		w.mu.Lock()
+		// Rows dropped during the insert were the first of the batch
+		if left := n - (buf.trimmed - trimmed); left > 0 {
+			buf.rows = buf.rows[left:]
+		}
+		w.mu.Unlock()
+	}
+}
+
+// exec runs a query, with body as its data
+func (w *Writer) exec(ctx context.Context, query string, body []byte) error {
+	_, err := w.query(ctx, query, body)
+	return err
+}
+
+// query runs a query, with body as its data, and returns its result
+func (w *Writer) query(ctx context.Context, query string, body []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	params := url.Values{"query": {query}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.endpoint+"?"+params.Encode(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if w.user != "" {
+		req.Header.Set("X-ClickHouse-User", w.user)
+	}
+	if w.password != "" {
+		req.Header.Set("X-ClickHouse-Key", w.password)
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("clickhouse returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// quoteIdentifier quotes a database name for queries
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "\\`") + "`"
+}
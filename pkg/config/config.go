@@ -8,7 +8,7 @@ import (
 	"strings"
 
 	"github.com/joho/godotenv"
-	"github.com/sirupsen/logrus"
+	"github.com/yourusername/solana-wallet-tracker/pkg/secrets"
 )
 
 // Config holds the application configuration
@@ -18,22 +18,650 @@ type Config struct {
 	Wallets     []string `json:"wallets"`
 	Tokens      []string `json:"tokens"`
 	LogLevel    string   `json:"log_level"`
+
+	// Network names the network of the endpoints and wallets above:
+	// mainnet-beta (default), devnet, testnet, localnet or a custom name
+	Network string `json:"network,omitempty"`
+	// Networks are watched alongside it, each with its own endpoints and
+	// wallets
+	Networks []NetworkConfig `json:"networks,omitempty"`
+
+	// HTTP configures the connections to the RPC and WebSocket endpoints
+	HTTP *HTTPConfig `json:"http,omitempty"`
+
+	// Geyser streams the updates of the wallets from a Yellowstone gRPC
+	// endpoint instead of WebSocket subscriptions and polls
+	Geyser *GeyserConfig `json:"geyser,omitempty"`
+
+	// Commitment sets the commitment level of each kind of request
+	Commitment CommitmentConfig `json:"commitment"`
+
+	// WalletTokens are the token filters of wallets listed as objects,
+	// replacing Tokens for those wallets
+	WalletTokens map[string][]string `json:"-"`
+	// HotWallets are followed on the low latency path: a dedicated
+	// connection, processed commitment, no enrichment and no batching
+	HotWallets []string `json:"hot_wallets,omitempty"`
+	// WalletLabels names wallets by address for events, logs, notifications
+	// and the API
+	WalletLabels map[string]WalletLabelConfig `json:"wallet_labels,omitempty"`
+	// DomainRefresh is how often .sol domains given for wallets are resolved
+	// again to follow changes of owner (default 1h)
+	DomainRefresh Duration `json:"domain_refresh,omitempty"`
+
+	// Startup checks
+	Preflight PreflightConfig `json:"preflight"`
+	// SeedSilently loads the initial state of the first start without
+	// events, and later starts from the balances in the store
+	SeedSilently bool `json:"seed_silently,omitempty"`
+
+	// Notifications
+	Notifiers   []NotifierConfig `json:"notifiers"`
+	BatchWindow Duration         `json:"notification_batch_window,omitempty"`
+	// EventDelivery is concurrent (default), wallet or sequential and
+	// controls whether handlers receive events in order
+	EventDelivery string `json:"event_delivery,omitempty"`
+	// HandlerTimeout bounds every handler call; zero waits for each
+	HandlerTimeout Duration `json:"handler_timeout,omitempty"`
+	// DisabledHandlers are the names of the event and balance handlers
+	// turned off. They and the disabled notifiers are applied again when
+	// the tracker receives SIGHUP, overriding the API.
+	DisabledHandlers []string `json:"disabled_handlers,omitempty"`
+	// Backpressure suspends periodic polling while the event queue is
+	// saturated
+	Backpressure *BackpressureConfig `json:"backpressure,omitempty"`
+	// Polling sizes the worker pool of periodic polling
+	Polling *PollingConfig `json:"polling,omitempty"`
+	// RateLimit caps routine notifications per minute; zero is unlimited
+	RateLimit int `json:"notification_rate_limit,omitempty"`
+	// Cooldown caps the routine notifications of each wallet and mint per
+	// minute, suppressing those beyond; zero is unlimited
+	Cooldown int `json:"notification_cooldown,omitempty"`
+	// Retry retries failed notifications, keeping those that still fail in
+	// the store's dead letter queue
+	Retry *RetryConfig `json:"notification_retry,omitempty"`
+	// CriticalRules select events delivered ahead of routine ones,
+	// bypassing batching and the rate limit
+	CriticalRules []PriorityRuleConfig `json:"critical_rules,omitempty"`
+	// SeverityRules raise the severity of the events they match
+	SeverityRules []SeverityRuleConfig `json:"severity_rules,omitempty"`
+	// GroupRules alert when totals over the wallets of a group, or over
+	// every tracked wallet, cross a threshold
+	GroupRules []GroupRuleConfig `json:"group_rules,omitempty"`
+	// Anomalies flags transfers far outside the usual sizes of the wallet
+	// and mint
+	Anomalies *AnomalyConfig `json:"anomalies,omitempty"`
+
+	// Persistence
+	Store *StoreConfig `json:"store,omitempty"`
+	// Backfill is the number of past transactions per token account whose
+	// balances are stored for wallets without history when the tracker
+	// starts. Zero disables backfilling.
+	Backfill int `json:"backfill,omitempty"`
+
+	// Compliance snapshots
+	Snapshots *SnapshotConfig `json:"snapshots,omitempty"`
+	// FlowReport sends the net flows of every day or week to the notifiers
+	FlowReport *FlowReportConfig `json:"flow_report,omitempty"`
+
+	// Analytics export
+	TimeSeries *TimeSeriesConfig `json:"timeseries,omitempty"`
+	ClickHouse *ClickHouseConfig `json:"clickhouse,omitempty"`
+
+	// Market data
+	PriceAPI string `json:"price_api,omitempty"`
+	// Entities name counterparties that are known exchanges, bridges or
+	// protocols
+	Entities *EntitiesConfig `json:"entities,omitempty"`
+
+	// HTTP API
+	API *APIConfig `json:"api,omitempty"`
+	// Ingest feeds the tracker with webhook deliveries of Helius or
+	// QuickNode, received by the API, instead of RPC subscriptions and polls
+	Ingest *IngestConfig `json:"ingest,omitempty"`
+
+	// Tenants split the tracked wallets into watchlists of teams with their
+	// own notifiers and API keys
+	Tenants []TenantConfig `json:"tenants,omitempty"`
+
+	// Self-monitoring
+	Watchdog  *WatchdogConfig  `json:"watchdog,omitempty"`
+	Telemetry *TelemetryConfig `json:"telemetry,omitempty"`
+	// MeasureLatency measures how far behind the block time of their
+	// updates events reach their handlers, per update source
+	MeasureLatency bool `json:"measure_latency,omitempty"`
+
+	// Optional monitoring features
+	NewHoldings    NewHoldingsConfig  `json:"new_holdings"`
+	MintWatches    []MintWatchConfig  `json:"mint_watches"`
+	StakeAccounts  bool               `json:"stake_accounts"`
+	NFTs           NFTConfig          `json:"nfts"`
+	Risk           RiskConfig         `json:"risk"`
+	Counterparties CounterpartyConfig `json:"counterparties"`
+	Dust           DustConfig         `json:"dust"`
+	// ResolveSignatures looks up the transaction signature behind each token
+	// balance change
+	ResolveSignatures bool `json:"resolve_signatures,omitempty"`
+	// ResolveTransfers looks up the counterparties, direction and memo of
+	// the transfer behind each token balance change
+	ResolveTransfers bool `json:"resolve_transfers,omitempty"`
+	// DetectBurns reports balance changes caused by burn instructions as
+	// token_burned events, resolving their transfers
+	DetectBurns bool `json:"detect_burns,omitempty"`
+	// TrackRent records the rent wallets pay for the accounts they create
+	// and reclaim from closed token accounts in the store
+	TrackRent bool `json:"track_rent,omitempty"`
+	// TrackDelegates emits delegate_changed events when a delegate is
+	// approved or revoked on a tracked token account or its allowance changes
+	TrackDelegates bool `json:"track_delegates,omitempty"`
+	// WatchAuthorities emits critical authority_changed events when a
+	// tracked token account changes owner or close authority, or a wallet
+	// is assigned to another program
+	WatchAuthorities bool `json:"watch_authorities,omitempty"`
+	// TrackFreezes emits account_frozen and account_thawed events when a
+	// tracked token account is frozen or thawed
+	TrackFreezes bool `json:"track_freezes,omitempty"`
+	// WatchOnly only emits the signatures of the transactions mentioning
+	// each wallet, without tracking balances
+	WatchOnly bool `json:"watch_only,omitempty"`
+	// KeepWrappedSOL reports wrapped SOL accounts as token holdings of their
+	// own instead of folding them into the SOL balance of their wallet
+	KeepWrappedSOL bool `json:"keep_wrapped_sol,omitempty"`
+	// ZeroBalanceTTL prunes token accounts from the live state after their
+	// balance has been zero this long. Zero keeps them forever.
+	ZeroBalanceTTL Duration `json:"zero_balance_ttl,omitempty"`
+	// StaleAfter emits a wallet_stale event for wallets without subscription
+	// notifications and successful polls for this long. Zero disables it.
+	StaleAfter Duration `json:"stale_after,omitempty"`
+}
+
+// RetryConfig controls retries of failed notifications. Zero values use the
+// defaults.
+type RetryConfig struct {
+	Attempts     int      `json:"attempts,omitempty"`
+	InitialDelay Duration `json:"initial_delay,omitempty"`
+	MaxDelay     Duration `json:"max_delay,omitempty"`
+}
+
+// PriorityRuleConfig selects critical events. An event matches when it meets
+// every criterion set.
+type PriorityRuleConfig struct {
+	Types        []string `json:"types,omitempty"`
+	Wallets      []string `json:"wallets,omitempty"`
+	Groups       []string `json:"groups,omitempty"`
+	Mints        []string `json:"mints,omitempty"`
+	MinRiskScore int      `json:"min_risk_score,omitempty"`
+	Severities   []string `json:"severities,omitempty"`
+	// TransferKinds matches events whose resolved transfer is of one of the
+	// kinds, e.g. mint for airdrops and claims
+	TransferKinds []string `json:"transfer_kinds,omitempty"`
+	// Below and Above match balance events leaving the account with a
+	// balance, in UI units, below or above them. With both, a balance
+	// outside the range matches.
+	Below float64 `json:"below,omitempty"`
+	Above float64 `json:"above,omitempty"`
+}
+
+// SeverityRuleConfig gives the events matching its criteria a severity of
+// info, warn or critical
+type SeverityRuleConfig struct {
+	Severity string `json:"severity"`
+	PriorityRuleConfig
+}
+
+// GroupRuleConfig alerts when a group total falls below or rises above a
+// threshold. With a mint the group's balance of it is totalled in UI units,
+// otherwise the USD value of its tracked tokens. An empty group totals every
+// tracked wallet.
+type GroupRuleConfig struct {
+	Group string  `json:"group,omitempty"`
+	Mint  string  `json:"mint,omitempty"`
+	Below float64 `json:"below,omitempty"`
+	Above float64 `json:"above,omitempty"`
+}
+
+// WalletLabelConfig is a human-friendly label and group tags for a wallet
+type WalletLabelConfig struct {
+	Label  string   `json:"label"`
+	Groups []string `json:"groups,omitempty"`
+	// Note is free text about the wallet, indexed for search
+	Note string `json:"note,omitempty"`
+}
+
+// CommitmentConfig holds the commitment levels, processed, confirmed or
+// finalized, of subscriptions, polling and transaction lookups. Empty levels
+// are confirmed.
+type CommitmentConfig struct {
+	Subscriptions string `json:"subscriptions,omitempty"`
+	Polling       string `json:"polling,omitempty"`
+	// Transactions can't be processed, which the RPC doesn't serve
+	Transactions string `json:"transactions,omitempty"`
+}
+
+// PreflightConfig configures the checks run against the chain at startup
+type PreflightConfig struct {
+	Skip bool `json:"skip,omitempty"`
+	// MaxLatency is how long each endpoint may take to respond
+	MaxLatency Duration `json:"max_latency,omitempty"`
+}
+
+// DustThresholdConfig sets the smallest balances and changes worth an event
+type DustThresholdConfig struct {
+	// MinBalance and MinChange are in UI units
+	MinBalance    float64 `json:"min_balance,omitempty"`
+	MinChange     float64 `json:"min_change,omitempty"`
+	MinBalanceUSD float64 `json:"min_balance_usd,omitempty"`
+	MinChangeUSD  float64 `json:"min_change_usd,omitempty"`
 }
 
+// DustConfig configures dust filtering. The embedded thresholds apply to
+// every mint without its own entry in Mints.
+type DustConfig struct {
+	DustThresholdConfig
+	Mints map[string]DustThresholdConfig `json:"mints,omitempty"`
+}
+
+// Enabled reports whether any dust threshold is set
+func (d DustConfig) Enabled() bool {
+	return d.DustThresholdConfig != (DustThresholdConfig{}) || len(d.Mints) > 0
+}
+
+// UsesUSD reports whether any dust threshold is in USD, which requires prices
+func (d DustConfig) UsesUSD() bool {
+	if d.MinBalanceUSD > 0 || d.MinChangeUSD > 0 {
+		return true
+	}
+	for _, threshold := range d.Mints {
+		if threshold.MinBalanceUSD > 0 || threshold.MinChangeUSD > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// AnomalyConfig flags transfers more than Sigma standard deviations above
+// the learned mean size, or outgoing transfers moving more than
+// HoldingsShare percent of the balance. The statistics are kept in the store.
+type AnomalyConfig struct {
+	Sigma         float64 `json:"sigma,omitempty"`
+	HoldingsShare float64 `json:"holdings_share,omitempty"`
+	// MinSamples is how many transfers are learned before sizes are checked
+	MinSamples int `json:"min_samples,omitempty"`
+	// Window is the number of recent transfers the statistics follow
+	Window int `json:"window,omitempty"`
+}
+
+// BackpressureConfig suspends periodic polling once HighWater events are
+// queued or being processed, until they drain to LowWater
+type BackpressureConfig struct {
+	HighWater int `json:"high_water"`
+	// LowWater defaults to half of HighWater
+	LowWater int `json:"low_water,omitempty"`
+}
+
+// PollingConfig polls wallets on Concurrency workers, giving up on the
+// lookups of a wallet or batch after Timeout and looking up the token
+// accounts of BatchSize wallets per request
+type PollingConfig struct {
+	Concurrency int      `json:"concurrency,omitempty"`
+	Timeout     Duration `json:"timeout,omitempty"`
+	BatchSize   int      `json:"batch_size,omitempty"`
+	// InitialConcurrency is how many wallets are loaded at once when the
+	// tracker starts (default 8)
+	InitialConcurrency int `json:"initial_concurrency,omitempty"`
+	// DiscoveryInterval is how often wallets are polled for new token
+	// accounts; known ones are polled by address in between (default 5m)
+	DiscoveryInterval Duration `json:"discovery_interval,omitempty"`
+}
+
+// EntitiesConfig lists known entities in a static JSON file, a remote list
+// or both. Entries of the file take precedence.
+type EntitiesConfig struct {
+	File string `json:"file,omitempty"`
+	URL  string `json:"url,omitempty"`
+	// Refresh is how often the remote list is fetched again (default 24h)
+	Refresh Duration `json:"refresh,omitempty"`
+}
+
+// CounterpartyConfig configures counterparty statistics
+type CounterpartyConfig struct {
+	Enabled bool `json:"enabled"`
+	// Retention is how long transfers are kept, bounding the query window
+	Retention Duration `json:"retention,omitempty"`
+}
+
+// SnapshotConfig schedules hashed balance snapshots
+type SnapshotConfig struct {
+	Dir string `json:"dir"`
+	// Interval between snapshots, aligned to UTC midnight (default 24h)
+	Interval Duration `json:"interval,omitempty"`
+	// Offset moves the snapshot times past the aligned ones, e.g. 6h
+	Offset Duration `json:"offset,omitempty"`
+}
+
+// FlowReportConfig schedules reports of the net flows of each UTC day, or
+// of each week from Monday, computed from the event log of the store
+type FlowReportConfig struct {
+	// Period is day or week
+	Period string `json:"period"`
+	// Offset sends the report later than the end of the period, e.g. 9h
+	Offset Duration `json:"offset,omitempty"`
+}
+
+// TimeSeriesConfig writes a point of a balance to InfluxDB or TimescaleDB
+// whenever it changes and of every balance each Interval
+type TimeSeriesConfig struct {
+	// Type is influxdb or timescaledb
+	Type string `json:"type"`
+	// URL, Token, Org and Bucket address InfluxDB
+	URL    string `json:"url,omitempty"`
+	Token  string `json:"token,omitempty"`
+	Org    string `json:"org,omitempty"`
+	Bucket string `json:"bucket,omitempty"`
+	// Measurement names the InfluxDB points (default balance)
+	Measurement string `json:"measurement,omitempty"`
+	// DSN connects to TimescaleDB
+	DSN string `json:"dsn,omitempty"`
+	// Table holds the TimescaleDB points, created as a hypertable when
+	// missing (default balance_points)
+	Table string `json:"table,omitempty"`
+	// Interval between the points of every balance (default 5m)
+	Interval Duration `json:"interval,omitempty"`
+}
+
+// ClickHouseConfig inserts events and the transactions behind them into
+// ClickHouse
+type ClickHouseConfig struct {
+	// URL of the HTTP interface, e.g. http://localhost:8123
+	URL string `json:"url"`
+	// Database holds the tables, which are created when missing (default
+	// default)
+	Database string `json:"database,omitempty"`
+	User     string `json:"user,omitempty"`
+	Password string `json:"password,omitempty"`
+	// BatchSize is the most rows per insert (default 1000)
+	BatchSize int `json:"batch_size,omitempty"`
+	// FlushInterval is how often buffered rows are inserted, unless a batch
+	// fills up first (default 5s)
+	FlushInterval Duration `json:"flush_interval,omitempty"`
+}
+
+// WatchdogConfig configures the reports of the tracker about its own health
+type WatchdogConfig struct {
+	// Channel receives the reports, separately from event notifications
+	Channel NotifierConfig `json:"channel"`
+	// Heartbeat is the interval between heartbeats. Zero sends none.
+	Heartbeat Duration `json:"heartbeat,omitempty"`
+	// Silence reports the tracker degraded after no event for this long.
+	// Zero disables the check.
+	Silence Duration `json:"silence,omitempty"`
+	// RPCFailures is the number of failed RPC health checks in a row that
+	// report the tracker degraded (default 3)
+	RPCFailures int `json:"rpc_failures,omitempty"`
+	// CheckInterval is how often the RPC endpoint is checked (default 1m)
+	CheckInterval Duration `json:"check_interval,omitempty"`
+}
+
+// TelemetryConfig exports traces of updates, from their notification to the
+// delivery of their events, and metrics of the tracker to an OpenTelemetry
+// collector with OTLP over HTTP
+type TelemetryConfig struct {
+	// Endpoint is the base URL of the OTLP HTTP receiver, e.g.
+	// http://localhost:4318 (default OTEL_EXPORTER_OTLP_ENDPOINT)
+	Endpoint string `json:"endpoint,omitempty"`
+	// Headers are sent with every export, e.g. the API key of a vendor
+	Headers     map[string]string `json:"headers,omitempty"`
+	ServiceName string            `json:"service_name,omitempty"`
+	// SampleRatio is the share of traces exported, above 0 and up to 1
+	// (default 1)
+	SampleRatio float64 `json:"sample_ratio,omitempty"`
+	// MetricInterval is how often metrics are exported (default 1m)
+	MetricInterval Duration `json:"metric_interval,omitempty"`
+}
+
+// APIConfig configures the HTTP API
+type APIConfig struct {
+	Listen string `json:"listen"`
+	// Keys grant access to every wallet. Once keys or tenant API keys are
+	// set, requests other than the health probes need one.
+	Keys []string `json:"keys,omitempty"`
+}
+
+// IngestConfig authenticates the webhook providers whose deliveries the API
+// accepts. Providers without a secret are rejected.
+type IngestConfig struct {
+	// HeliusAuth is the auth header set on the Helius enhanced webhook
+	HeliusAuth string `json:"helius_auth,omitempty"`
+	// QuickNodeKey is the security token of the QuickNode stream or webhook
+	QuickNodeKey string `json:"quicknode_key,omitempty"`
+}
+
+// HTTPConfig configures the connections to RPC and WebSocket endpoints.
+// WebSocket connections only use the headers and the handshake timeout;
+// they follow the HTTPS_PROXY environment variable and trust the system
+// certificates.
+type HTTPConfig struct {
+	// Proxy is the http, https or socks5 URL of the proxy of RPC requests
+	Proxy string `json:"proxy,omitempty"`
+	// Headers are sent with every RPC request and WebSocket handshake, e.g.
+	// the API key header of an RPC provider
+	Headers map[string]string `json:"headers,omitempty"`
+	TLS     *TLSConfig        `json:"tls,omitempty"`
+	// Timeout bounds each RPC request (default 5m)
+	Timeout Duration `json:"timeout,omitempty"`
+	// HandshakeTimeout bounds the opening of WebSocket connections
+	HandshakeTimeout Duration `json:"handshake_timeout,omitempty"`
+}
+
+// TLSConfig configures the TLS connections of RPC requests
+type TLSConfig struct {
+	// CAFile is a PEM file of certificates trusted besides the system ones
+	CAFile string `json:"ca_file,omitempty"`
+	// CertFile and KeyFile are the PEM client certificate and key of
+	// endpoints requiring mutual TLS
+	CertFile string `json:"cert_file,omitempty"`
+	KeyFile  string `json:"key_file,omitempty"`
+	// ServerName overrides the name the certificate of the endpoint is
+	// verified against
+	ServerName string `json:"server_name,omitempty"`
+	// InsecureSkipVerify accepts any certificate, for testing only
+	InsecureSkipVerify bool `json:"insecure_skip_verify,omitempty"`
+}
+
+// GeyserConfig is a Yellowstone gRPC endpoint of a dedicated node
+type GeyserConfig struct {
+	// Endpoint is the https URL of the endpoint
+	Endpoint string `json:"endpoint"`
+	// Token is sent as the x-token header, if the endpoint requires one
+	Token string `json:"token,omitempty"`
+}
+
+// TenantConfig is the watchlist of a team sharing the tracker. Its wallets
+// must be tracked in wallets or networks and may be on several watchlists.
+type TenantConfig struct {
+	Name    string   `json:"name"`
+	Wallets []string `json:"wallets"`
+	// Notifiers receive the events of the tenant's wallets, in addition to
+	// the top-level notifiers receiving every event
+	Notifiers []NotifierConfig `json:"notifiers,omitempty"`
+	// APIKeys grant access to the tenant's wallets only
+	APIKeys []string `json:"api_keys,omitempty"`
+}
+
+// TenantNotifiers returns the notifiers of a tenant, or the top-level
+// notifiers for an empty name. It reports false for unknown tenants.
+func (c *Config) TenantNotifiers(tenant string) ([]NotifierConfig, bool) {
+	if tenant == "" {
+		return c.Notifiers, true
+	}
+	for _, candidate := range c.Tenants {
+		if candidate.Name == tenant {
+			return candidate.Notifiers, true
+		}
+	}
+	return nil, false
+}
+
+// RiskConfig configures per-wallet risk scoring
+type RiskConfig struct {
+	Enabled bool `json:"enabled"`
+	// Exchanges are exchange deposit addresses used to detect outflows
+	Exchanges    []string `json:"exchanges,omitempty"`
+	DormantAfter Duration `json:"dormant_after,omitempty"`
+	Window       Duration `json:"window,omitempty"`
+}
+
+// NFTConfig configures NFT holdings monitoring through a DAS API provider
+type NFTConfig struct {
+	Enabled bool `json:"enabled"`
+	// DASEndpoint defaults to the RPC endpoint, which works for providers
+	// that serve DAS alongside regular RPC
+	DASEndpoint string `json:"das_endpoint,omitempty"`
+	// CompressedLogs detects compressed NFT mints, transfers and burns from
+	// Bubblegum transaction logs as they happen instead of by polling
+	CompressedLogs bool `json:"compressed_logs,omitempty"`
+}
+
+// MintWatchConfig configures large holder monitoring for a mint
+type MintWatchConfig struct {
+	Mint string `json:"mint"`
+	// Threshold is the minimum holder balance in UI units
+	Threshold float64 `json:"threshold"`
+}
+
+// NewHoldingsConfig controls enrichment of new holding events
+type NewHoldingsConfig struct {
+	Metadata bool `json:"metadata"`
+	RugCheck bool `json:"rug_check"`
+	// QuoteAPI is the Jupiter quote API the rug check probes liquidity
+	// with, the public one when empty
+	QuoteAPI string `json:"quote_api,omitempty"`
+	// LiquidityProbeUSD is the amount of USDC whose swap into the mint is
+	// quoted (default 1000)
+	LiquidityProbeUSD float64 `json:"liquidity_probe_usd,omitempty"`
+	// MaxPriceImpact is the price impact in percent of the probe above which
+	// a mint is flagged for low liquidity (default 5)
+	MaxPriceImpact float64 `json:"max_price_impact,omitempty"`
+	// MaxTopHoldersShare is the percent of the supply its ten largest token
+	// accounts may hold before a mint is flagged (default 50)
+	MaxTopHoldersShare float64 `json:"max_top_holders_share,omitempty"`
+}
+
+// StoreConfig configures balance history persistence
+type StoreConfig struct {
+	Type string `json:"type"`
+	Path string `json:"path,omitempty"`
+	DSN  string `json:"dsn,omitempty"`
+	// Search indexes wallet labels, notes and transaction memos for
+	// full-text search. SQLite only.
+	Search bool `json:"search,omitempty"`
+	// Events keeps every event under an increasing ID, for consumers to
+	// catch up on with GET /events
+	Events bool `json:"events,omitempty"`
+}
+
+// NotifierConfig configures a notification channel
+type NotifierConfig struct {
+	Type string `json:"type"`
+	// Name turns the notifier on and off over the API, defaulting to its
+	// type. Notifiers of the same name are turned on and off together.
+	Name string `json:"name,omitempty"`
+	// Disabled mutes the notifier, see DisabledHandlers
+	Disabled bool   `json:"disabled,omitempty"`
+	Locale   string `json:"locale"`
+	URL      string `json:"url,omitempty"`
+	// Secret signs webhook requests, see pkg/events
+	Secret   string `json:"secret,omitempty"`
+	BotToken string `json:"bot_token,omitempty"`
+	ChatID   string `json:"chat_id,omitempty"`
+	Chart    bool   `json:"chart,omitempty"`
+	// RoutingKey is the integration key of a PagerDuty service
+	RoutingKey string `json:"routing_key,omitempty"`
+	// APIKey is the key of an Opsgenie API integration
+	APIKey string `json:"api_key,omitempty"`
+	// MinSeverity skips events of a lower severity: info, warn, anomaly or
+	// critical. Empty receives every event.
+	MinSeverity string `json:"min_severity,omitempty"`
+	// QuietHours hold back the routine events of the channel, which are
+	// delivered as a digest when they end
+	QuietHours []QuietHoursConfig `json:"quiet_hours,omitempty"`
+	// Timezone is the IANA time zone of the quiet hours, the local time of
+	// the tracker if empty
+	Timezone string `json:"timezone,omitempty"`
+	// DigestWindow sums up the routine events of each wallet within the
+	// window in one message, for webhook and Telegram channels
+	DigestWindow Duration `json:"digest_window,omitempty"`
+	// Topic is the topic template of MQTT messages, see notify.MQTTTopic,
+	// or the ARN of an SNS topic
+	Topic string `json:"topic,omitempty"`
+	// QoS is the MQTT quality of service, 0 or 1
+	QoS      int    `json:"qos,omitempty"`
+	ClientID string `json:"client_id,omitempty"`
+	// Region of an SQS queue whose URL doesn't name it
+	Region string `json:"region,omitempty"`
+	// AccessKeyID and SecretAccessKey sign SNS and SQS requests in place
+	// of the credentials of the environment or IAM role
+	AccessKeyID     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+	// Attributes are the event fields set as Pub/Sub message attributes,
+	// see PubSubAttributes
+	Attributes []string `json:"attributes,omitempty"`
+	// CredentialsFile is the service account key file of Pub/Sub requests
+	// in place of the credentials of the environment
+	CredentialsFile string `json:"credentials_file,omitempty"`
+	// Format is the encoding of Pub/Sub messages: json (default), avro or
+	// protobuf, see pkg/events
+	Format string `json:"format,omitempty"`
+	// Schema is the Pub/Sub schema of avro and protobuf messages, named
+	// projects/PROJECT/schemas/SCHEMA, which the tracker creates or revises
+	// to match its records
+	Schema string `json:"schema,omitempty"`
+	// EventBus is the name or ARN of an EventBridge event bus, default
+	// "default". EventSource and DetailType set the source and the detail
+	// type template of its events, see EventBridgePlaceholders.
+	EventBus    string `json:"event_bus,omitempty"`
+	EventSource string `json:"event_source,omitempty"`
+	DetailType  string `json:"detail_type,omitempty"`
+	// CloudEvents wraps the payloads of webhook, SNS, SQS and Pub/Sub
+	// channels in CloudEvents 1.0 envelopes of the source CloudEventsSource,
+	// see notify.DefaultCloudEventSource
+	CloudEvents       bool   `json:"cloudevents,omitempty"`
+	CloudEventsSource string `json:"cloudevents_source,omitempty"`
+}
+
+// PubSubAttributes are the event fields Pub/Sub channels can set as message
+// attributes
+var PubSubAttributes = []string{"wallet", "label", "network", "domain", "tenants", "account", "mint", "symbol", "event_type", "severity", "signature"}
+
+// EventBridgePlaceholders are the placeholders of the detail type templates
+// of EventBridge channels
+var EventBridgePlaceholders = []string{"{event_type}", "{severity}", "{network}"}
+
+// DefaultFile is the configuration file used when none is given
+const DefaultFile = "config.json"
+
+// MaxBackfill is the most signatures getSignaturesForAddress returns, which
+// bounds the transactions backfilled per token account
+const MaxBackfill = 1000
+
 // LoadConfig loads configuration from config.json and environment variables
 func LoadConfig() (*Config, error) {
+	return LoadConfigFile(DefaultFile)
+}
+
+// LoadConfigFile loads configuration from a JSON file and environment
+// variables. A missing file, or an empty path, leaves the configuration to
+// the environment.
+func LoadConfigFile(configFile string) (*Config, error) {
 	// Load .env file if it exists
 	_ = godotenv.Load()
 
-	// Default config
+	// Default config, whose endpoints are those of the configured network
 	config := &Config{
-		RPCEndpoint: "https://api.mainnet-beta.solana.com",
-		WSEndpoint:  "wss://api.mainnet-beta.solana.com",
-		LogLevel:    "info",
+		LogLevel: "info",
 	}
 
 	// Check if config file exists
-	configFile := "config.json"
 	if _, err := os.Stat(configFile); err == nil {
 		// Read config file
 		data, err := ioutil.ReadFile(configFile)
@@ -68,22 +696,24 @@ func LoadConfig() (*Config, error) {
 		config.LogLevel = logLevel
 	}
 
-	// Setup logger
-	level, err := logrus.ParseLevel(config.LogLevel)
-	if err != nil {
-		level = logrus.InfoLevel
+	// Expand the ${...} references to secrets kept out of the file
+	if err := config.expandSecrets(&secrets.Resolver{}); err != nil {
+		return nil, err
 	}
-	logrus.SetLevel(level)
-	logrus.SetFormatter(&logrus.TextFormatter{
-		FullTimestamp: true,
-	})
+
+	config.fillEndpoints()
 
 	return config, nil
 }
 
 // CreateDefaultConfigFile creates a default config.json file if it doesn't exist
 func CreateDefaultConfigFile() error {
-	configFile := "config.json"
+	return CreateDefaultConfigFileAt(DefaultFile)
+}
+
+// CreateDefaultConfigFileAt creates a default configuration file at a path
+// if it doesn't exist
+func CreateDefaultConfigFileAt(configFile string) error {
 	if _, err := os.Stat(configFile); os.IsNotExist(err) {
 		config := &Config{
 			RPCEndpoint: "https://api.mainnet-beta.solana.com",
@@ -106,3 +736,51 @@ func CreateDefaultConfigFile() error {
 	}
 	return nil
 }
+
+// AddWallet adds a wallet to the configuration file, leaving other settings
+// as written. It reports false if the wallet is already listed.
+func AddWallet(configFile, wallet string) (bool, error) {
+	data, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return false, err
+	}
+
+	// Edit the raw document so unset options aren't written out as zero values
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(data, &document); err != nil {
+		return false, err
+	}
+
+	// Keep wallet objects as written, appending the new wallet as a string
+	var wallets []json.RawMessage
+	if raw, ok := document["wallets"]; ok {
+		if err := json.Unmarshal(raw, &wallets); err != nil {
+			return false, err
+		}
+	}
+	for _, raw := range wallets {
+		var existing WalletConfig
+		if err := json.Unmarshal(raw, &existing); err != nil {
+			return false, err
+		}
+		if existing.Address == wallet {
+			return false, nil
+		}
+	}
+
+	entry, err := json.Marshal(wallet)
+	if err != nil {
+		return false, err
+	}
+	raw, err := json.Marshal(append(wallets, entry))
+	if err != nil {
+		return false, err
+	}
+	document["wallets"] = raw
+
+	data, err = json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return false, err
+	}
+	return true, ioutil.WriteFile(configFile, data, 0644)
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/sns"
+)
+
+// HasDomains reports whether any wallet is given as a .sol domain
+func (c *Config) HasDomains() bool {
+	found := false
+	c.eachWallet(func(wallet string) string {
+		found = found || sns.IsDomain(wallet)
+		return wallet
+	})
+	return found
+}
+
+// ResolveDomains replaces the .sol domains given for wallets by the wallets
+// resolve returns for them and returns the domains by wallet. Wallets,
+// hot_wallets, wallet_labels, critical_rules, severity_rules, risk.exchanges
+// and the wallets of tenants are covered.
+func (c *Config) ResolveDomains(resolve func(domain string) (string, error)) (map[string]string, error) {
+	domains := make(map[string]string)
+	var failed error
+	c.eachWallet(func(wallet string) string {
+		if failed != nil || !sns.IsDomain(wallet) {
+			return wallet
+		}
+		domain := strings.ToLower(wallet)
+		address, err := resolve(domain)
+		if err != nil {
+			failed = fmt.Errorf("failed to resolve %s: %w", domain, err)
+			return wallet
+		}
+		domains[address] = domain
+		return address
+	})
+	if failed != nil {
+		return nil, failed
+	}
+
+	seen := make(map[string]bool, len(c.Wallets))
+	for _, wallet := range c.Wallets {
+		if seen[wallet] {
+			return nil, fmt.Errorf("wallet %s (%s) is listed more than once", wallet, domains[wallet])
+		}
+		seen[wallet] = true
+	}
+	return domains, nil
+}
+
+// eachWallet replaces every wallet of the configuration by what replace
+// returns for it
+func (c *Config) eachWallet(replace func(wallet string) string) {
+	for i, wallet := range c.Wallets {
+		c.Wallets[i] = replace(wallet)
+		if tokens, ok := c.WalletTokens[wallet]; ok && c.Wallets[i] != wallet {
+			delete(c.WalletTokens, wallet)
+			c.WalletTokens[c.Wallets[i]] = tokens
+		}
+	}
+	for i, wallet := range c.HotWallets {
+		c.HotWallets[i] = replace(wallet)
+	}
+	for wallet, label := range c.WalletLabels {
+		if replaced := replace(wallet); replaced != wallet {
+			delete(c.WalletLabels, wallet)
+			c.WalletLabels[replaced] = label
+		}
+	}
+	for _, rule := range c.CriticalRules {
+		for i, wallet := range rule.Wallets {
+			rule.Wallets[i] = replace(wallet)
+		}
+	}
+	for _, rule := range c.SeverityRules {
+		for i, wallet := range rule.Wallets {
+			rule.Wallets[i] = replace(wallet)
+		}
+	}
+	for i, exchange := range c.Risk.Exchanges {
+		c.Risk.Exchanges[i] = replace(exchange)
+	}
+	for _, tenant := range c.Tenants {
+		for i, wallet := range tenant.Wallets {
+			tenant.Wallets[i] = replace(wallet)
+		}
+	}
+}
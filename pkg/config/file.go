@@ -0,0 +1,37 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// FileEnv names the configuration file when --config isn't given
+const FileEnv = "TRACKER_CONFIG"
+
+// dirName is the directory of the configuration file under the user and
+// system configuration directories
+const dirName = "solana-wallet-tracker"
+
+// FindFile returns the configuration file used when none is given: the file
+// named by TRACKER_CONFIG, else the first existing of config.json in the
+// working directory, solana-wallet-tracker/config.json in the user
+// configuration directory ($XDG_CONFIG_HOME or ~/.config on Linux) and
+// /etc/solana-wallet-tracker/config.json, else config.json in the working
+// directory.
+func FindFile() string {
+	if file := os.Getenv(FileEnv); file != "" {
+		return file
+	}
+
+	candidates := []string{DefaultFile}
+	if dir, err := os.UserConfigDir(); err == nil {
+		candidates = append(candidates, filepath.Join(dir, dirName, DefaultFile))
+	}
+	candidates = append(candidates, filepath.Join("/etc", dirName, DefaultFile))
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return DefaultFile
+}
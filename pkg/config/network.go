@@ -0,0 +1,97 @@
+package config
+
+// Known networks, whose public endpoints are used when none are configured
+const (
+	NetworkMainnet  = "mainnet-beta"
+	NetworkDevnet   = "devnet"
+	NetworkTestnet  = "testnet"
+	NetworkLocalnet = "localnet"
+)
+
+// networkEndpoints are the RPC and WebSocket endpoints of a known network
+type networkEndpoints struct {
+	rpc string
+	ws  string
+}
+
+// publicEndpoints maps the known networks to their public endpoints.
+// Localnet is a solana-test-validator with its default ports.
+var publicEndpoints = map[string]networkEndpoints{
+	NetworkMainnet:  {"https://api.mainnet-beta.solana.com", "wss://api.mainnet-beta.solana.com"},
+	NetworkDevnet:   {"https://api.devnet.solana.com", "wss://api.devnet.solana.com"},
+	NetworkTestnet:  {"https://api.testnet.solana.com", "wss://api.testnet.solana.com"},
+	NetworkLocalnet: {"http://127.0.0.1:8899", "ws://127.0.0.1:8900"},
+}
+
+// NetworkConfig is a network watched alongside the top-level one, with its
+// own endpoints and wallets. Endpoints of known networks default to their
+// public ones; custom networks need both.
+type NetworkConfig struct {
+	Name        string   `json:"name"`
+	RPCEndpoint string   `json:"rpc_endpoint,omitempty"`
+	WSEndpoint  string   `json:"ws_endpoint,omitempty"`
+	Wallets     []string `json:"wallets"`
+	// Tokens filters the token accounts of the network's wallets. Empty
+	// tracks every token.
+	Tokens []string `json:"tokens,omitempty"`
+	// HTTP replaces the top-level http settings for the network's
+	// endpoints
+	HTTP *HTTPConfig `json:"http,omitempty"`
+}
+
+// NetworkName returns the name of the top-level network
+func (c *Config) NetworkName() string {
+	if c.Network == "" {
+		return NetworkMainnet
+	}
+	return c.Network
+}
+
+// TagsNetworks reports whether events carry the name of their network, which
+// they do once the top-level network is named or other networks are added
+func (c *Config) TagsNetworks() bool {
+	return c.Network != "" || len(c.Networks) > 0
+}
+
+// AllWallets returns the wallets of the top-level network followed by those
+// of the other networks
+func (c *Config) AllWallets() []string {
+	wallets := append([]string{}, c.Wallets...)
+	for _, network := range c.Networks {
+		wallets = append(wallets, network.Wallets...)
+	}
+	return wallets
+}
+
+// NetworkHTTP returns the http settings of the endpoints of a network,
+// its own or the top-level ones
+func (c *Config) NetworkHTTP(network NetworkConfig) *HTTPConfig {
+	if network.HTTP != nil {
+		return network.HTTP
+	}
+	return c.HTTP
+}
+
+// fillEndpoints sets unset endpoints of known networks to their public ones
+func (c *Config) fillEndpoints() {
+	fillNetworkEndpoints(c.NetworkName(), &c.RPCEndpoint, &c.WSEndpoint)
+	for i := range c.Networks {
+		network := &c.Networks[i]
+		fillNetworkEndpoints(network.Name, &network.RPCEndpoint, &network.WSEndpoint)
+	}
+}
+
+// fillNetworkEndpoints sets unset endpoints to the public ones of a network,
+// if it is known
+func fillNetworkEndpoints(name string, rpcEndpoint, wsEndpoint *string) {
+	endpoints, ok := publicEndpoints[name]
+	if !ok {
+		return
+	}
+	if *rpcEndpoint == "" {
+		*rpcEndpoint = endpoints.rpc
+	}
+	if *wsEndpoint == "" {
+		*wsEndpoint = endpoints.ws
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// QuietHoursConfig is a window in which a notification channel only gets
+// critical events, e.g. from 01:00 to 07:00. Windows ending before they
+// start run past midnight.
+type QuietHoursConfig struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Days are the weekdays the window starts on, mon to sun. Empty is
+	// every day.
+	Days []string `json:"days,omitempty"`
+}
+
+// weekdays maps the names of days to weekdays
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ParseTimeOfDay parses a time of day such as 07:00 as the time since
+// midnight
+func ParseTimeOfDay(value string) (time.Duration, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a time of day such as 07:00", value)
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// ParseWeekday parses the name of a day, mon to sun
+func ParseWeekday(value string) (time.Weekday, error) {
+	day, ok := weekdays[strings.ToLower(value)]
+	if !ok {
+		return 0, fmt.Errorf("%q is not one of mon, tue, wed, thu, fri, sat, sun", value)
+	}
+	return day, nil
+}
+
+// LoadTimezone returns the location of an IANA time zone such as
+// Europe/Berlin, the local time of the tracker if empty
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a known time zone", name)
+	}
+	return location, nil
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/secrets"
+)
+
+// expandSecrets replaces the references to secrets in the fields holding
+// endpoints, credentials and secrets, see pkg/secrets. Every reference that
+// can't be resolved is reported.
+func (c *Config) expandSecrets(resolver *secrets.Resolver) error {
+	v := &validator{}
+	expand := func(field string, value *string) {
+		expanded, err := resolver.Expand(*value)
+		if err != nil {
+			v.add(field, "%v", err)
+			return
+		}
+		*value = expanded
+	}
+	expandAll := func(field string, values []string) {
+		for i := range values {
+			expand(fmt.Sprintf("%s[%d]", field, i), &values[i])
+		}
+	}
+
+	expand("rpc_endpoint", &c.RPCEndpoint)
+	expand("ws_endpoint", &c.WSEndpoint)
+	expandHTTP(v, resolver, "http", c.HTTP)
+	for i := range c.Networks {
+		network := &c.Networks[i]
+		field := fmt.Sprintf("networks[%d]", i)
+		expand(field+".rpc_endpoint", &network.RPCEndpoint)
+		expand(field+".ws_endpoint", &network.WSEndpoint)
+		expandHTTP(v, resolver, field+".http", network.HTTP)
+	}
+	if c.Geyser != nil {
+		expand("geyser.endpoint", &c.Geyser.Endpoint)
+		expand("geyser.token", &c.Geyser.Token)
+	}
+
+	expandNotifier := func(field string, notifier *NotifierConfig) {
+		expand(field+".url", &notifier.URL)
+		expand(field+".secret", &notifier.Secret)
+		expand(field+".bot_token", &notifier.BotToken)
+		expand(field+".routing_key", &notifier.RoutingKey)
+		expand(field+".api_key", &notifier.APIKey)
+		expand(field+".access_key_id", &notifier.AccessKeyID)
+		expand(field+".secret_access_key", &notifier.SecretAccessKey)
+	}
+	for i := range c.Notifiers {
+		expandNotifier(fmt.Sprintf("notifiers[%d]", i), &c.Notifiers[i])
+	}
+	for i := range c.Tenants {
+		tenant := &c.Tenants[i]
+		field := fmt.Sprintf("tenants[%d]", i)
+		for j := range tenant.Notifiers {
+			expandNotifier(fmt.Sprintf("%s.notifiers[%d]", field, j), &tenant.Notifiers[j])
+		}
+		expandAll(field+".api_keys", tenant.APIKeys)
+	}
+	if c.Watchdog != nil {
+		expandNotifier("watchdog.channel", &c.Watchdog.Channel)
+	}
+
+	if c.API != nil {
+		expandAll("api.keys", c.API.Keys)
+	}
+	if c.Ingest != nil {
+		expand("ingest.helius_auth", &c.Ingest.HeliusAuth)
+		expand("ingest.quicknode_key", &c.Ingest.QuickNodeKey)
+	}
+	if c.Store != nil {
+		expand("store.dsn", &c.Store.DSN)
+	}
+	if c.TimeSeries != nil {
+		expand("timeseries.token", &c.TimeSeries.Token)
+		expand("timeseries.dsn", &c.TimeSeries.DSN)
+	}
+	if c.ClickHouse != nil {
+		expand("clickhouse.url", &c.ClickHouse.URL)
+		expand("clickhouse.password", &c.ClickHouse.Password)
+	}
+	if c.Telemetry != nil {
+		expand("telemetry.endpoint", &c.Telemetry.Endpoint)
+		expandHeaders(v, resolver, "telemetry.headers", c.Telemetry.Headers)
+	}
+	if c.Entities != nil {
+		expand("entities.url", &c.Entities.URL)
+	}
+	expand("price_api", &c.PriceAPI)
+	expand("nfts.das_endpoint", &c.NFTs.DASEndpoint)
+	expand("new_holdings.quote_api", &c.NewHoldings.QuoteAPI)
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+// expandHTTP replaces the references to secrets in the proxy and headers of
+// http settings
+func expandHTTP(v *validator, resolver *secrets.Resolver, field string, cfg *HTTPConfig) {
+	if cfg == nil {
+		return
+	}
+	if proxy, err := resolver.Expand(cfg.Proxy); err != nil {
+		v.add(field+".proxy", "%v", err)
+	} else {
+		cfg.Proxy = proxy
+	}
+
+	expandHeaders(v, resolver, field+".headers", cfg.Headers)
+}
+
+// expandHeaders replaces the references to secrets in header values
+func expandHeaders(v *validator, resolver *secrets.Resolver, field string, headers map[string]string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value, err := resolver.Expand(headers[name])
+		if err != nil {
+			v.add(fmt.Sprintf("%s.%s", field, name), "%v", err)
+			continue
+		}
+		headers[name] = value
+	}
+}
@@ -0,0 +1,867 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/yourusername/solana-wallet-tracker/pkg/mqtt"
+	"github.com/yourusername/solana-wallet-tracker/pkg/sns"
+)
+
+// maxBatchWindow bounds notification batching so alerts aren't held back
+// for longer than is useful
+const maxBatchWindow = time.Hour
+
+// minAPIKeyLength is the shortest API key accepted
+const minAPIKeyLength = 16
+
+// severities are the severities of events, lowest first
+var severities = []string{"info", "warn", "anomaly", "critical"}
+
+// transferKinds are the kinds of transfers rules can match
+var transferKinds = []string{"transfer", "mint", "burn"}
+
+// logLevels are the log levels the tracker's logger accepts, in any case
+var logLevels = map[string]bool{
+	"panic": true, "fatal": true, "error": true, "warn": true, "warning": true,
+	"info": true, "debug": true, "trace": true,
+}
+
+// sqlIdentifier matches the table names that need no quoting, optionally
+// qualified by a schema
+var sqlIdentifier = regexp.MustCompile(`^([a-z_][a-z0-9_]*\.)?[a-z_][a-z0-9_]*$`)
+
+// FieldError is a problem with one configuration field
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// ValidationError lists every problem found in a configuration
+type ValidationError struct {
+	Problems []FieldError
+}
+
+// Error lists the problems one per line
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Problems)+1)
+	lines = append(lines, fmt.Sprintf("invalid configuration (%d problems):", len(e.Problems)))
+	for _, problem := range e.Problems {
+		lines = append(lines, fmt.Sprintf("  %s: %s", problem.Field, problem.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// validator collects field errors
+type validator struct {
+	problems []FieldError
+}
+
+// add records a problem with a field
+func (v *validator) add(field, format string, args ...interface{}) {
+	v.problems = append(v.problems, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// address checks that a field holds a base58 public key
+func (v *validator) address(field, value string) {
+	if value == "" {
+		v.add(field, "is required")
+		return
+	}
+	if _, err := solana.PublicKeyFromBase58(value); err != nil {
+		v.add(field, "%q is not a valid base58 public key", value)
+	}
+}
+
+// wallet checks that a field holds a base58 public key or a .sol domain
+func (v *validator) wallet(field, value string) {
+	if sns.IsDomain(value) {
+		return
+	}
+	v.address(field, value)
+}
+
+// endpoint checks that a field holds a URL with one of the given schemes
+func (v *validator) endpoint(field, value string, schemes ...string) {
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Host == "" {
+		v.add(field, "%q is not a valid URL", value)
+		return
+	}
+	for _, scheme := range schemes {
+		if parsed.Scheme == scheme {
+			return
+		}
+	}
+	v.add(field, "%q must use %s", value, strings.Join(schemes, " or "))
+}
+
+// duration checks that a field holds a duration within bounds. A zero max
+// means no upper bound.
+func (v *validator) duration(field string, value Duration, max time.Duration) {
+	d := value.Duration()
+	if d < 0 {
+		v.add(field, "must not be negative")
+	}
+	if max > 0 && d > max {
+		v.add(field, "%s is longer than the maximum of %s", d, max)
+	}
+}
+
+// http checks the connection settings of endpoints
+func (v *validator) http(field string, cfg *HTTPConfig) {
+	if cfg == nil {
+		return
+	}
+	if cfg.Proxy != "" {
+		v.endpoint(field+".proxy", cfg.Proxy, "http", "https", "socks5")
+	}
+	for name := range cfg.Headers {
+		if name == "" || strings.ContainsAny(name, " \t\r\n:") {
+			v.add(field+".headers", "%q is not a valid header name", name)
+		}
+	}
+	v.duration(field+".timeout", cfg.Timeout, 0)
+	v.duration(field+".handshake_timeout", cfg.HandshakeTimeout, 0)
+	if cfg.TLS != nil && (cfg.TLS.CertFile == "") != (cfg.TLS.KeyFile == "") {
+		v.add(field+".tls", "cert_file and key_file must be set together")
+	}
+}
+
+// commitment checks that a field holds one of the allowed commitment levels
+// or is empty
+func (v *validator) commitment(field, value string, allowed ...string) {
+	if value != "" {
+		v.oneOf(field, value, allowed...)
+	}
+}
+
+// eventBridgeDetailType checks the detail type template of an EventBridge
+// channel
+func (v *validator) eventBridgeDetailType(field, template string) {
+	rest := template
+	for _, placeholder := range EventBridgePlaceholders {
+		rest = strings.ReplaceAll(rest, placeholder, "")
+	}
+	if start := strings.Index(rest, "{"); start >= 0 {
+		v.add(field, "%q has an unknown placeholder, use %s", template, strings.Join(EventBridgePlaceholders, ", "))
+	}
+	if len(template) > 128 {
+		v.add(field, "must be at most 128 characters")
+	}
+}
+
+// quietHours checks a quiet hours window
+func (v *validator) quietHours(field string, window QuietHoursConfig) {
+	from, err := ParseTimeOfDay(window.From)
+	if err != nil {
+		v.add(field+".from", "%v", err)
+	}
+	to, err := ParseTimeOfDay(window.To)
+	if err != nil {
+		v.add(field+".to", "%v", err)
+	}
+	if window.From != "" && from == to {
+		v.add(field, "from and to must differ")
+	}
+	for i, day := range window.Days {
+		if _, err := ParseWeekday(day); err != nil {
+			v.add(fmt.Sprintf("%s.days[%d]", field, i), "%v", err)
+		}
+	}
+}
+
+// notifier checks that a notification channel has what its type needs
+func (v *validator) notifier(field string, notifier NotifierConfig) {
+	if strings.Contains(notifier.Name, "/") {
+		v.add(field+".name", "must not contain /")
+	}
+	if _, err := LoadTimezone(notifier.Timezone); err != nil {
+		v.add(field+".timezone", "%v", err)
+	}
+	for i, window := range notifier.QuietHours {
+		v.quietHours(fmt.Sprintf("%s.quiet_hours[%d]", field, i), window)
+	}
+	v.duration(field+".digest_window", notifier.DigestWindow, maxBatchWindow)
+	if (notifier.Format != "" || notifier.Schema != "") && notifier.Type != "pubsub" {
+		v.add(field+".format", "is only supported for pubsub")
+	}
+	if notifier.CloudEvents {
+		switch notifier.Type {
+		case "webhook", "sns", "sqs", "pubsub":
+		default:
+			v.add(field+".cloudevents", "is not supported for %s", notifier.Type)
+		}
+		if notifier.Format == "avro" || notifier.Format == "protobuf" {
+			v.add(field+".cloudevents", "requires format json")
+		}
+	}
+	if notifier.CloudEventsSource != "" {
+		if !notifier.CloudEvents {
+			v.add(field+".cloudevents_source", "requires cloudevents")
+		}
+		if _, err := url.Parse(notifier.CloudEventsSource); err != nil {
+			v.add(field+".cloudevents_source", "%q is not a URI reference", notifier.CloudEventsSource)
+		}
+	}
+	if notifier.DigestWindow > 0 && notifier.Type != "webhook" && notifier.Type != "telegram" {
+		v.add(field+".digest_window", "is not supported for %s", notifier.Type)
+	}
+	switch notifier.Type {
+	case "webhook":
+		v.endpoint(field+".url", notifier.URL, "http", "https")
+	case "telegram":
+		if notifier.BotToken == "" {
+			v.add(field+".bot_token", "is required for telegram")
+		}
+		if notifier.ChatID == "" {
+			v.add(field+".chat_id", "is required for telegram")
+		}
+	case "pagerduty", "opsgenie":
+		if notifier.Type == "pagerduty" && notifier.RoutingKey == "" {
+			v.add(field+".routing_key", "is required for pagerduty")
+		}
+		if notifier.Type == "opsgenie" && notifier.APIKey == "" {
+			v.add(field+".api_key", "is required for opsgenie")
+		}
+		if notifier.URL != "" {
+			v.endpoint(field+".url", notifier.URL, "http", "https")
+		}
+		// Routine events resolve the incidents, so they must get through
+		if notifier.MinSeverity != "" {
+			v.add(field+".min_severity", "is not supported for %s, which only opens incidents for critical events", notifier.Type)
+			return
+		}
+		if len(notifier.QuietHours) > 0 {
+			v.add(field+".quiet_hours", "is not supported for %s, which only opens incidents for critical events", notifier.Type)
+			return
+		}
+	case "mqtt":
+		v.endpoint(field+".url", notifier.URL, "mqtt", "mqtts")
+		if notifier.Topic != "" {
+			if err := mqtt.ValidateTemplate(notifier.Topic); err != nil {
+				v.add(field+".topic", "%v", err)
+			}
+		}
+		if notifier.QoS != 0 && notifier.QoS != 1 {
+			v.add(field+".qos", "must be 0 or 1")
+		}
+	case "sns", "sqs":
+		if notifier.Type == "sns" {
+			if parts := strings.SplitN(notifier.Topic, ":", 6); len(parts) != 6 || parts[0] != "arn" || parts[2] != "sns" {
+				v.add(field+".topic", "%q is not the ARN of an SNS topic", notifier.Topic)
+			}
+			if notifier.URL != "" {
+				v.endpoint(field+".url", notifier.URL, "http", "https")
+			}
+		} else {
+			v.endpoint(field+".url", notifier.URL, "http", "https")
+		}
+		if (notifier.AccessKeyID == "") != (notifier.SecretAccessKey == "") {
+			v.add(field+".secret_access_key", "must be set together with access_key_id")
+		}
+	case "eventbridge":
+		if strings.HasPrefix(notifier.EventBus, "arn:") {
+			if parts := strings.SplitN(notifier.EventBus, ":", 6); len(parts) != 6 || parts[2] != "events" || !strings.HasPrefix(parts[5], "event-bus/") {
+				v.add(field+".event_bus", "%q is not the ARN of an event bus", notifier.EventBus)
+			}
+		}
+		if strings.HasPrefix(notifier.EventSource, "aws.") {
+			v.add(field+".event_source", "must not start with aws.")
+		}
+		v.eventBridgeDetailType(field+".detail_type", notifier.DetailType)
+		if notifier.URL != "" {
+			v.endpoint(field+".url", notifier.URL, "http", "https")
+		}
+		if (notifier.AccessKeyID == "") != (notifier.SecretAccessKey == "") {
+			v.add(field+".secret_access_key", "must be set together with access_key_id")
+		}
+	case "pubsub":
+		if parts := strings.Split(notifier.Topic, "/"); len(parts) != 4 || parts[0] != "projects" || parts[2] != "topics" || parts[1] == "" || parts[3] == "" {
+			v.add(field+".topic", "%q is not named projects/PROJECT/topics/TOPIC", notifier.Topic)
+		}
+		if notifier.URL != "" {
+			v.endpoint(field+".url", notifier.URL, "http", "https")
+		}
+		for i, attribute := range notifier.Attributes {
+			v.oneOf(fmt.Sprintf("%s.attributes[%d]", field, i), attribute, PubSubAttributes...)
+		}
+		if notifier.Format != "" {
+			v.oneOf(field+".format", notifier.Format, "json", "avro", "protobuf")
+		}
+		if notifier.Schema != "" {
+			if notifier.Format != "avro" && notifier.Format != "protobuf" {
+				v.add(field+".schema", "requires format avro or protobuf")
+			}
+			if parts := strings.Split(notifier.Schema, "/"); len(parts) != 4 || parts[0] != "projects" || parts[2] != "schemas" || parts[1] == "" || parts[3] == "" {
+				v.add(field+".schema", "%q is not named projects/PROJECT/schemas/SCHEMA", notifier.Schema)
+			}
+		}
+	default:
+		v.add(field+".type", "%q is not one of webhook, telegram, pagerduty, opsgenie, mqtt, sns, sqs, eventbridge, pubsub", notifier.Type)
+	}
+	if notifier.MinSeverity != "" {
+		v.oneOf(field+".min_severity", notifier.MinSeverity, severities...)
+	}
+}
+
+// rule checks the criteria of a rule selecting events. what describes the
+// selected events for rules without criteria, which select every event.
+func (v *validator) rule(field string, rule PriorityRuleConfig, what string) {
+	if len(rule.Types) == 0 && len(rule.Wallets) == 0 && len(rule.Groups) == 0 &&
+		len(rule.Mints) == 0 && rule.MinRiskScore == 0 && len(rule.Severities) == 0 &&
+		len(rule.TransferKinds) == 0 && rule.Below == 0 && rule.Above == 0 {
+		v.add(field, "needs at least one criterion, otherwise every event is %s", what)
+	}
+	for j, wallet := range rule.Wallets {
+		v.wallet(fmt.Sprintf("%s.wallets[%d]", field, j), wallet)
+	}
+	for j, mint := range rule.Mints {
+		v.address(fmt.Sprintf("%s.mints[%d]", field, j), mint)
+	}
+	if rule.MinRiskScore < 0 || rule.MinRiskScore > 100 {
+		v.add(field+".min_risk_score", "must be between 0 and 100")
+	}
+	for j, severity := range rule.Severities {
+		v.oneOf(fmt.Sprintf("%s.severities[%d]", field, j), severity, severities...)
+	}
+	for j, kind := range rule.TransferKinds {
+		v.oneOf(fmt.Sprintf("%s.transfer_kinds[%d]", field, j), kind, transferKinds...)
+	}
+	if rule.Below < 0 {
+		v.add(field+".below", "must not be negative")
+	}
+	if rule.Above < 0 {
+		v.add(field+".above", "must not be negative")
+	}
+	if rule.Below > 0 && rule.Above > 0 && rule.Below >= rule.Above {
+		v.add(field, "below must be lower than above")
+	}
+}
+
+// apiKey checks that an API key is long enough to resist guessing and not
+// shared with another tenant or the admin keys
+func (v *validator) apiKey(field, key string, keys map[string]bool) {
+	switch {
+	case len(key) < minAPIKeyLength:
+		v.add(field, "must be at least %d characters", minAPIKeyLength)
+	case keys[key]:
+		v.add(field, "is already used by another key")
+	}
+	keys[key] = true
+}
+
+// oneOf checks that a value is one of the allowed ones
+func (v *validator) oneOf(field, value string, allowed ...string) {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return
+		}
+	}
+	v.add(field, "%q is not one of %s", value, strings.Join(allowed, ", "))
+}
+
+// dustThreshold checks that dust thresholds aren't negative
+func (v *validator) dustThreshold(field string, threshold DustThresholdConfig) {
+	values := []struct {
+		name  string
+		value float64
+	}{
+		{"min_balance", threshold.MinBalance},
+		{"min_change", threshold.MinChange},
+		{"min_balance_usd", threshold.MinBalanceUSD},
+		{"min_change_usd", threshold.MinChangeUSD},
+	}
+	for _, value := range values {
+		if value.value < 0 {
+			v.add(field+"."+value.name, "must not be negative")
+		}
+	}
+}
+
+// Validate checks the configuration and reports all problems at once as a
+// *ValidationError
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	v.endpoint("rpc_endpoint", c.RPCEndpoint, "http", "https")
+	v.endpoint("ws_endpoint", c.WSEndpoint, "ws", "wss")
+
+	if len(c.Wallets) == 0 && len(c.MintWatches) == 0 && len(c.Networks) == 0 {
+		v.add("wallets", "at least one wallet or mint watch is required (set wallets or MONITOR_WALLETS)")
+	}
+	seen := make(map[string]bool)
+	for i, wallet := range c.Wallets {
+		field := fmt.Sprintf("wallets[%d]", i)
+		v.wallet(field, wallet)
+		if sns.IsDomain(wallet) && c.NetworkName() != NetworkMainnet {
+			v.add(field, "%s can't be resolved, .sol domains only exist on %s", wallet, NetworkMainnet)
+		}
+		if seen[wallet] {
+			v.add(field, "%q is listed more than once", wallet)
+		}
+		seen[wallet] = true
+
+		for j, token := range c.WalletTokens[wallet] {
+			v.address(fmt.Sprintf("%s.tokens[%d]", field, j), token)
+		}
+	}
+	tracked := make(map[string]bool, len(c.Wallets))
+	for _, wallet := range c.Wallets {
+		tracked[wallet] = true
+	}
+	for i, wallet := range c.HotWallets {
+		field := fmt.Sprintf("hot_wallets[%d]", i)
+		v.wallet(field, wallet)
+		if !tracked[wallet] {
+			v.add(field, "%s is not in wallets", wallet)
+		}
+	}
+	labelled := make([]string, 0, len(c.WalletLabels))
+	for address := range c.WalletLabels {
+		labelled = append(labelled, address)
+	}
+	sort.Strings(labelled)
+	for _, address := range labelled {
+		label := c.WalletLabels[address]
+		field := fmt.Sprintf("wallet_labels[%s]", address)
+		v.wallet(field, address)
+		if label.Label == "" && len(label.Groups) == 0 && label.Note == "" {
+			v.add(field, "needs a label, a group or a note")
+		}
+		for _, group := range label.Groups {
+			if strings.TrimSpace(group) == "" {
+				v.add(field+".groups", "must not contain empty group names")
+			}
+		}
+	}
+	for i, token := range c.Tokens {
+		v.address(fmt.Sprintf("tokens[%d]", i), token)
+	}
+
+	// Wallets are tracked on one network only, so their history is too
+	networks := map[string]bool{c.NetworkName(): true}
+	for i, network := range c.Networks {
+		field := fmt.Sprintf("networks[%d]", i)
+		if network.Name == "" {
+			v.add(field+".name", "is required")
+		} else if networks[network.Name] {
+			v.add(field+".name", "%q is configured more than once", network.Name)
+		}
+		networks[network.Name] = true
+		v.endpoint(field+".rpc_endpoint", network.RPCEndpoint, "http", "https")
+		v.endpoint(field+".ws_endpoint", network.WSEndpoint, "ws", "wss")
+		if len(network.Wallets) == 0 {
+			v.add(field+".wallets", "at least one wallet is required")
+		}
+		for j, wallet := range network.Wallets {
+			walletField := fmt.Sprintf("%s.wallets[%d]", field, j)
+			v.address(walletField, wallet)
+			if seen[wallet] {
+				v.add(walletField, "%q is already tracked", wallet)
+			}
+			seen[wallet] = true
+		}
+		for j, token := range network.Tokens {
+			v.address(fmt.Sprintf("%s.tokens[%d]", field, j), token)
+		}
+		v.http(field+".http", network.HTTP)
+	}
+	v.http("http", c.HTTP)
+
+	v.duration("domain_refresh", c.DomainRefresh, 0)
+
+	if !logLevels[strings.ToLower(c.LogLevel)] {
+		v.add("log_level", "%q is not one of debug, info, warn, error", c.LogLevel)
+	}
+
+	v.commitment("commitment.subscriptions", c.Commitment.Subscriptions, "processed", "confirmed", "finalized")
+	v.commitment("commitment.polling", c.Commitment.Polling, "processed", "confirmed", "finalized")
+	v.commitment("commitment.transactions", c.Commitment.Transactions, "confirmed", "finalized")
+
+	v.duration("preflight.max_latency", c.Preflight.MaxLatency, 0)
+
+	for i, notifier := range c.Notifiers {
+		field := fmt.Sprintf("notifiers[%d]", i)
+		v.notifier(field, notifier)
+		if notifier.Chart && c.Store == nil {
+			v.add(field+".chart", "requires store")
+		}
+	}
+	v.duration("notification_batch_window", c.BatchWindow, maxBatchWindow)
+	if c.RateLimit < 0 {
+		v.add("notification_rate_limit", "must not be negative")
+	}
+	if c.Cooldown < 0 {
+		v.add("notification_cooldown", "must not be negative")
+	}
+	for i, rule := range c.CriticalRules {
+		v.rule(fmt.Sprintf("critical_rules[%d]", i), rule, "critical")
+	}
+	for i, rule := range c.SeverityRules {
+		field := fmt.Sprintf("severity_rules[%d]", i)
+		v.oneOf(field+".severity", rule.Severity, "info", "warn", "critical")
+		v.rule(field, rule.PriorityRuleConfig, "of this severity")
+	}
+
+	groups := make(map[string]bool)
+	for _, label := range c.WalletLabels {
+		for _, group := range label.Groups {
+			groups[group] = true
+		}
+	}
+	for i, rule := range c.GroupRules {
+		field := fmt.Sprintf("group_rules[%d]", i)
+		if rule.Group != "" && !groups[rule.Group] {
+			v.add(field+".group", "%q is not a group of any wallet in wallet_labels", rule.Group)
+		}
+		if rule.Mint != "" {
+			v.address(field+".mint", rule.Mint)
+		}
+		switch {
+		case rule.Below < 0 || rule.Above < 0:
+			v.add(field, "thresholds must not be negative")
+		case rule.Below == 0 && rule.Above == 0:
+			v.add(field, "needs a below or an above threshold")
+		case rule.Above > 0 && rule.Below >= rule.Above:
+			v.add(field, "below must be less than above")
+		}
+	}
+
+	if c.Store != nil {
+		switch c.Store.Type {
+		case "", "file", "sqlite":
+		case "postgres":
+			if c.Store.DSN == "" {
+				v.add("store.dsn", "is required for postgres")
+			}
+		default:
+			v.add("store.type", "%q is not one of file, sqlite, postgres", c.Store.Type)
+		}
+		if c.Store.Search && c.Store.Type != "sqlite" {
+			v.add("store.search", "requires a sqlite store")
+		}
+	}
+
+	switch {
+	case c.Backfill < 0 || c.Backfill > MaxBackfill:
+		v.add("backfill", "must be between 0 and %d", MaxBackfill)
+	case c.Backfill > 0 && c.Store == nil:
+		v.add("backfill", "requires store")
+	}
+
+	if c.PriceAPI != "" {
+		v.endpoint("price_api", c.PriceAPI, "http", "https")
+	}
+	if c.NewHoldings.QuoteAPI != "" {
+		v.endpoint("new_holdings.quote_api", c.NewHoldings.QuoteAPI, "http", "https")
+	}
+	if c.NewHoldings.LiquidityProbeUSD < 0 {
+		v.add("new_holdings.liquidity_probe_usd", "must not be negative")
+	}
+	if c.NewHoldings.MaxPriceImpact < 0 {
+		v.add("new_holdings.max_price_impact", "must not be negative")
+	}
+	if c.NewHoldings.MaxTopHoldersShare < 0 || c.NewHoldings.MaxTopHoldersShare > 100 {
+		v.add("new_holdings.max_top_holders_share", "must be between 0 and 100")
+	}
+	if c.Entities != nil {
+		if c.Entities.File == "" && c.Entities.URL == "" {
+			v.add("entities", "needs a file or a url")
+		}
+		if c.Entities.URL != "" {
+			v.endpoint("entities.url", c.Entities.URL, "http", "https")
+		}
+		v.duration("entities.refresh", c.Entities.Refresh, 0)
+	}
+	keys := make(map[string]bool)
+	if c.API != nil {
+		if _, _, err := net.SplitHostPort(c.API.Listen); err != nil {
+			v.add("api.listen", "%q is not a host:port address", c.API.Listen)
+		}
+		for i, key := range c.API.Keys {
+			v.apiKey(fmt.Sprintf("api.keys[%d]", i), key, keys)
+		}
+	}
+	if c.Ingest != nil {
+		if c.API == nil {
+			v.add("ingest", "requires api")
+		}
+		if c.Ingest.HeliusAuth == "" && c.Ingest.QuickNodeKey == "" {
+			v.add("ingest", "needs helius_auth or quicknode_key")
+		}
+		// Deliveries only feed the wallets of the main network
+		if len(c.Networks) > 0 {
+			v.add("ingest", "can't be used with networks")
+		}
+	}
+
+	if c.Geyser != nil {
+		// Plaintext HTTP/2 isn't supported by the client
+		v.endpoint("geyser.endpoint", c.Geyser.Endpoint, "https")
+		// The stream only covers the wallets of the main network
+		if len(c.Networks) > 0 {
+			v.add("geyser", "can't be used with networks")
+		}
+		if c.Ingest != nil {
+			v.add("geyser", "can't be used with ingest")
+		}
+	}
+
+	// Tenant wallets are tracked like any other, see seen above
+	tenants := make(map[string]bool, len(c.Tenants))
+	for i, tenant := range c.Tenants {
+		field := fmt.Sprintf("tenants[%d]", i)
+		if tenant.Name == "" {
+			v.add(field+".name", "is required")
+		} else if tenants[tenant.Name] {
+			v.add(field+".name", "%q is configured more than once", tenant.Name)
+		}
+		tenants[tenant.Name] = true
+		if len(tenant.Wallets) == 0 {
+			v.add(field+".wallets", "at least one wallet is required")
+		}
+		listed := make(map[string]bool, len(tenant.Wallets))
+		for j, wallet := range tenant.Wallets {
+			walletField := fmt.Sprintf("%s.wallets[%d]", field, j)
+			v.wallet(walletField, wallet)
+			switch {
+			case listed[wallet]:
+				v.add(walletField, "%q is listed more than once", wallet)
+			case !seen[wallet]:
+				v.add(walletField, "%s is not in wallets or networks", wallet)
+			}
+			listed[wallet] = true
+		}
+		for j, notifier := range tenant.Notifiers {
+			notifierField := fmt.Sprintf("%s.notifiers[%d]", field, j)
+			v.notifier(notifierField, notifier)
+			if notifier.Chart && c.Store == nil {
+				v.add(notifierField+".chart", "requires store")
+			}
+		}
+		if len(tenant.APIKeys) > 0 && c.API == nil {
+			v.add(field+".api_keys", "requires api")
+		}
+		for j, key := range tenant.APIKeys {
+			v.apiKey(fmt.Sprintf("%s.api_keys[%d]", field, j), key, keys)
+		}
+	}
+
+	for i, watch := range c.MintWatches {
+		field := fmt.Sprintf("mint_watches[%d]", i)
+		v.address(field+".mint", watch.Mint)
+		if watch.Threshold <= 0 {
+			v.add(field+".threshold", "must be greater than zero")
+		}
+	}
+
+	if c.NFTs.DASEndpoint != "" {
+		v.endpoint("nfts.das_endpoint", c.NFTs.DASEndpoint, "http", "https")
+	}
+
+	for i, exchange := range c.Risk.Exchanges {
+		v.wallet(fmt.Sprintf("risk.exchanges[%d]", i), exchange)
+	}
+	v.duration("risk.dormant_after", c.Risk.DormantAfter, 0)
+	v.duration("risk.window", c.Risk.Window, 0)
+	v.duration("counterparties.retention", c.Counterparties.Retention, 0)
+	if c.Snapshots != nil {
+		v.duration("snapshots.interval", c.Snapshots.Interval, 0)
+		v.duration("snapshots.offset", c.Snapshots.Offset, 0)
+		interval := c.Snapshots.Interval.Duration()
+		if interval <= 0 {
+			interval = 24 * time.Hour
+		} else if interval < time.Minute {
+			v.add("snapshots.interval", "must be at least 1m")
+		}
+		if c.Snapshots.Offset.Duration() >= interval {
+			v.add("snapshots.offset", "must be shorter than the interval")
+		}
+	}
+	if c.FlowReport != nil {
+		v.oneOf("flow_report.period", c.FlowReport.Period, "day", "week")
+		v.duration("flow_report.offset", c.FlowReport.Offset, 0)
+		period := 24 * time.Hour
+		if c.FlowReport.Period == "week" {
+			period *= 7
+		}
+		if c.FlowReport.Offset.Duration() >= period {
+			v.add("flow_report.offset", "must be shorter than the period")
+		}
+		if c.Store == nil || !c.Store.Events {
+			v.add("flow_report", "requires store.events")
+		}
+		if len(c.Notifiers) == 0 {
+			v.add("flow_report", "requires notifiers")
+		}
+	}
+	if c.TimeSeries != nil {
+		switch c.TimeSeries.Type {
+		case "influxdb":
+			v.endpoint("timeseries.url", c.TimeSeries.URL, "http", "https")
+			if c.TimeSeries.Bucket == "" {
+				v.add("timeseries.bucket", "is required for influxdb")
+			}
+		case "timescaledb":
+			if c.TimeSeries.DSN == "" {
+				v.add("timeseries.dsn", "is required for timescaledb")
+			}
+			if c.TimeSeries.Table != "" && !sqlIdentifier.MatchString(c.TimeSeries.Table) {
+				v.add("timeseries.table", "%q is not a valid table name", c.TimeSeries.Table)
+			}
+		default:
+			v.add("timeseries.type", "%q is not one of influxdb, timescaledb", c.TimeSeries.Type)
+		}
+		v.duration("timeseries.interval", c.TimeSeries.Interval, 0)
+		if interval := c.TimeSeries.Interval.Duration(); interval > 0 && interval < 10*time.Second {
+			v.add("timeseries.interval", "must be at least 10s")
+		}
+	}
+	if c.ClickHouse != nil {
+		v.endpoint("clickhouse.url", c.ClickHouse.URL, "http", "https")
+		if c.ClickHouse.BatchSize < 0 || c.ClickHouse.BatchSize > 100000 {
+			v.add("clickhouse.batch_size", "must be between 0 and 100000")
+		}
+		v.duration("clickhouse.flush_interval", c.ClickHouse.FlushInterval, 0)
+	}
+	if c.Telemetry != nil {
+		if c.Telemetry.Endpoint != "" {
+			v.endpoint("telemetry.endpoint", c.Telemetry.Endpoint, "http", "https")
+		}
+		if c.Telemetry.SampleRatio < 0 || c.Telemetry.SampleRatio > 1 {
+			v.add("telemetry.sample_ratio", "must be between 0 and 1")
+		}
+		v.duration("telemetry.metric_interval", c.Telemetry.MetricInterval, 0)
+		if interval := c.Telemetry.MetricInterval.Duration(); interval > 0 && interval < time.Second {
+			v.add("telemetry.metric_interval", "must be at least 1s")
+		}
+	}
+	if c.Watchdog != nil {
+		v.notifier("watchdog.channel", c.Watchdog.Channel)
+		if c.Watchdog.Channel.Chart {
+			v.add("watchdog.channel.chart", "is not supported for health reports")
+		}
+		if len(c.Watchdog.Channel.QuietHours) > 0 {
+			v.add("watchdog.channel.quiet_hours", "is not supported for health reports")
+		}
+		if c.Watchdog.Channel.DigestWindow > 0 {
+			v.add("watchdog.channel.digest_window", "is not supported for health reports")
+		}
+		if f := c.Watchdog.Channel.Format; f == "avro" || f == "protobuf" {
+			v.add("watchdog.channel.format", "%s is not supported for health reports", f)
+		}
+		if t := c.Watchdog.Channel.Type; t == "pagerduty" || t == "opsgenie" || t == "mqtt" {
+			v.add("watchdog.channel.type", "%s can't deliver health reports", t)
+		}
+		if c.Watchdog.Channel.MinSeverity != "" {
+			v.add("watchdog.channel.min_severity", "is not supported for health reports")
+		}
+		v.duration("watchdog.heartbeat", c.Watchdog.Heartbeat, 0)
+		v.duration("watchdog.silence", c.Watchdog.Silence, 0)
+		v.duration("watchdog.check_interval", c.Watchdog.CheckInterval, 0)
+		if c.Watchdog.RPCFailures < 0 {
+			v.add("watchdog.rpc_failures", "must not be negative")
+		}
+	}
+	v.dustThreshold("dust", c.Dust.DustThresholdConfig)
+	dustMints := make([]string, 0, len(c.Dust.Mints))
+	for mint := range c.Dust.Mints {
+		dustMints = append(dustMints, mint)
+	}
+	sort.Strings(dustMints)
+	for _, mint := range dustMints {
+		field := fmt.Sprintf("dust.mints[%s]", mint)
+		v.address(field, mint)
+		v.dustThreshold(field, c.Dust.Mints[mint])
+	}
+	v.duration("zero_balance_ttl", c.ZeroBalanceTTL, 0)
+	v.duration("stale_after", c.StaleAfter, 0)
+	if d := c.StaleAfter.Duration(); d > 0 && d < time.Minute {
+		// Quiet wallets are only refreshed by the polls every 30s
+		v.add("stale_after", "must be at least 1m")
+	}
+	if c.TrackRent && c.Store == nil {
+		v.add("track_rent", "requires store")
+	}
+	if c.WatchOnly {
+		// The signature feed doesn't load balances, stakes or NFTs
+		conflicts := []struct {
+			field   string
+			enabled bool
+		}{
+			{"stake_accounts", c.StakeAccounts},
+			{"nfts", c.NFTs.Enabled},
+			{"mint_watches", len(c.MintWatches) > 0},
+			{"group_rules", len(c.GroupRules) > 0},
+			{"track_rent", c.TrackRent},
+		}
+		for _, conflict := range conflicts {
+			if conflict.enabled {
+				v.add(conflict.field, "can't be used with watch_only")
+			}
+		}
+	}
+	if c.Anomalies != nil {
+		if c.Store == nil {
+			v.add("anomalies", "requires store")
+		}
+		if c.Anomalies.Sigma < 0 {
+			v.add("anomalies.sigma", "must not be negative")
+		}
+		if c.Anomalies.HoldingsShare < 0 || c.Anomalies.HoldingsShare > 100 {
+			v.add("anomalies.holdings_share", "must be between 0 and 100")
+		}
+		if c.Anomalies.MinSamples < 0 {
+			v.add("anomalies.min_samples", "must not be negative")
+		}
+		if c.Anomalies.Window < 0 {
+			v.add("anomalies.window", "must not be negative")
+		}
+	}
+	if c.Retry != nil {
+		if c.Retry.Attempts < 0 {
+			v.add("notification_retry.attempts", "must not be negative")
+		}
+		v.duration("notification_retry.initial_delay", c.Retry.InitialDelay, 0)
+		v.duration("notification_retry.max_delay", c.Retry.MaxDelay, 0)
+	}
+	if c.Backpressure != nil {
+		high, low := c.Backpressure.HighWater, c.Backpressure.LowWater
+		if high <= 0 {
+			v.add("backpressure.high_water", "must be positive")
+		} else if low < 0 || low >= high {
+			v.add("backpressure.low_water", "must be at least 0 and below high_water")
+		}
+	}
+	if c.Polling != nil {
+		if c.Polling.Concurrency < 0 {
+			v.add("polling.concurrency", "must not be negative")
+		}
+		if c.Polling.BatchSize < 0 {
+			v.add("polling.batch_size", "must not be negative")
+		}
+		if c.Polling.InitialConcurrency < 0 {
+			v.add("polling.initial_concurrency", "must not be negative")
+		}
+		v.duration("polling.timeout", c.Polling.Timeout, 0)
+		v.duration("polling.discovery_interval", c.Polling.DiscoveryInterval, 0)
+	}
+	v.duration("handler_timeout", c.HandlerTimeout, 0)
+	switch c.EventDelivery {
+	case "", "concurrent", "wallet", "sequential":
+	default:
+		v.add("event_delivery", "%q is not one of concurrent, wallet, sequential", c.EventDelivery)
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
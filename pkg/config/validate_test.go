@@ -0,0 +1,133 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// Valid addresses, those of the native and USDC mints
+const (
+	testWallet = "So11111111111111111111111111111111111111112"
+	testMint   = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+)
+
+// validConfig returns a minimal configuration that passes validation
+func validConfig() *Config {
+	return &Config{
+		RPCEndpoint: "https://api.mainnet-beta.solana.com",
+		WSEndpoint:  "wss://api.mainnet-beta.solana.com",
+		Wallets:     []string{testWallet},
+		Tokens:      []string{testMint},
+		LogLevel:    "info",
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		// fields are the fields reported, nil for a valid configuration
+		fields []string
+	}{
+		{"minimal", func(c *Config) {}, nil},
+		{"endpoints", func(c *Config) {
+			c.RPCEndpoint = "wss://api.mainnet-beta.solana.com"
+			c.WSEndpoint = "not a url"
+		}, []string{"rpc_endpoint", "ws_endpoint"}},
+		{"no wallets", func(c *Config) { c.Wallets = nil }, []string{"wallets"}},
+		{"wallets", func(c *Config) {
+			c.Wallets = []string{testWallet, "not-base58!", testWallet}
+			c.Tokens = []string{"short"}
+		}, []string{"tokens[0]", "wallets[1]", "wallets[2]"}},
+		{"domains", func(c *Config) { c.Wallets = append(c.Wallets, "toly.sol") }, nil},
+		{"hot wallet not tracked", func(c *Config) { c.HotWallets = []string{testMint} }, []string{"hot_wallets[0]"}},
+		{"log level", func(c *Config) { c.LogLevel = "verbose" }, []string{"log_level"}},
+		{"every problem at once", func(c *Config) {
+			c.LogLevel = "verbose"
+			c.RateLimit = -1
+			c.Cooldown = -1
+			c.BatchWindow = Duration(2 * time.Hour)
+		}, []string{"log_level", "notification_batch_window", "notification_cooldown", "notification_rate_limit"}},
+		{"networks", func(c *Config) {
+			c.Networks = []NetworkConfig{
+				{Name: "devnet", RPCEndpoint: "https://api.devnet.solana.com", WSEndpoint: "wss://api.devnet.solana.com", Wallets: []string{testMint}},
+				{Name: "devnet", RPCEndpoint: "https://api.devnet.solana.com", WSEndpoint: "wss://api.devnet.solana.com", Wallets: []string{testWallet}},
+			}
+		}, []string{"networks[1].name", "networks[1].wallets[0]"}},
+		{"webhook", func(c *Config) {
+			c.Notifiers = []NotifierConfig{{Type: "webhook", URL: "ftp://example.com"}}
+		}, []string{"notifiers[0].url"}},
+		{"telegram", func(c *Config) {
+			c.Notifiers = []NotifierConfig{{Type: "telegram", Chart: true}}
+		}, []string{"notifiers[0].bot_token", "notifiers[0].chart", "notifiers[0].chat_id"}},
+		{"unknown notifier", func(c *Config) {
+			c.Notifiers = []NotifierConfig{{Type: "carrier-pigeon"}}
+		}, []string{"notifiers[0].type"}},
+		{"quiet hours", func(c *Config) {
+			c.Notifiers = []NotifierConfig{{
+				Type: "webhook", URL: "https://example.com/hook", Timezone: "Nowhere/Town",
+				QuietHours: []QuietHoursConfig{{From: "22:00", To: "22:00", Days: []string{"mon", "someday"}}, {From: "7", To: "08:00"}},
+			}}
+		}, []string{"notifiers[0].quiet_hours[0]", "notifiers[0].quiet_hours[0].days[1]", "notifiers[0].quiet_hours[1].from", "notifiers[0].timezone"}},
+		{"incidents of every severity", func(c *Config) {
+			c.Notifiers = []NotifierConfig{{Type: "pagerduty", RoutingKey: "key", MinSeverity: "warn"}}
+		}, []string{"notifiers[0].min_severity"}},
+		{"mqtt", func(c *Config) {
+			c.Notifiers = []NotifierConfig{{Type: "mqtt", URL: "mqtt://broker:1883", Topic: "solana/{wallet}/{nope}", QoS: 2}}
+		}, []string{"notifiers[0].qos", "notifiers[0].topic"}},
+		{"sns", func(c *Config) {
+			c.Notifiers = []NotifierConfig{{Type: "sns", Topic: "arn:aws:sqs:us-east-1:123456789012:queue", AccessKeyID: "AKID"}}
+		}, []string{"notifiers[0].secret_access_key", "notifiers[0].topic"}},
+		{"valid sns", func(c *Config) {
+			c.Notifiers = []NotifierConfig{{Type: "sns", Topic: "arn:aws:sns:us-east-1:123456789012:tracker", CloudEvents: true}}
+		}, nil},
+		{"pubsub", func(c *Config) {
+			c.Notifiers = []NotifierConfig{{Type: "pubsub", Topic: "topics/tracker", Format: "xml", Schema: "schemas/event"}}
+		}, []string{"notifiers[0].format", "notifiers[0].schema", "notifiers[0].schema", "notifiers[0].topic"}},
+		{"cloudevents", func(c *Config) {
+			c.Notifiers = []NotifierConfig{
+				{Type: "telegram", BotToken: "token", ChatID: "1", CloudEvents: true},
+				{Type: "webhook", URL: "https://example.com/hook", CloudEventsSource: "/tracker"},
+			}
+		}, []string{"notifiers[0].cloudevents", "notifiers[1].cloudevents_source"}},
+		{"store", func(c *Config) {
+			c.Store = &StoreConfig{Type: "postgres", Search: true}
+		}, []string{"store.dsn", "store.search"}},
+		{"backfill without store", func(c *Config) { c.Backfill = 10 }, []string{"backfill"}},
+		{"api keys", func(c *Config) {
+			c.API = &APIConfig{Listen: "8080", Keys: []string{"short", "0123456789abcdef", "0123456789abcdef"}}
+		}, []string{"api.keys[0]", "api.keys[2]", "api.listen"}},
+		{"tenants", func(c *Config) {
+			c.Tenants = []TenantConfig{
+				{Name: "acme", Wallets: []string{testWallet, testMint}, APIKeys: []string{"0123456789abcdef"}},
+				{Name: "acme"},
+			}
+		}, []string{"tenants[0].api_keys", "tenants[0].wallets[1]", "tenants[1].name", "tenants[1].wallets"}},
+		{"snapshots", func(c *Config) {
+			c.Snapshots = &SnapshotConfig{Interval: Duration(30 * time.Second), Offset: Duration(time.Minute)}
+		}, []string{"snapshots.interval", "snapshots.offset"}},
+	}
+	for _, tt := range tests {
+		c := validConfig()
+		tt.modify(c)
+		err := c.Validate()
+
+		var fields []string
+		if err != nil {
+			var validation *ValidationError
+			if !errors.As(err, &validation) {
+				t.Fatalf("%s: got %T, want a *ValidationError", tt.name, err)
+			}
+			for _, problem := range validation.Problems {
+				fields = append(fields, problem.Field)
+			}
+			sort.Strings(fields)
+		}
+		if !reflect.DeepEqual(fields, tt.fields) {
+			t.Errorf("%s: problems with %q, want %q\n%v", tt.name, fields, tt.fields, err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// WalletConfig is an entry of the wallets list. Entries are either an
+// address or an object with the address and its own token filter, e.g.
+// {"address": "<address>", "tokens": ["<usdc mint>"]}.
+type WalletConfig struct {
+	Address string `json:"address"`
+	// Tokens replaces the global tokens for this wallet. Nil inherits them
+	// and an empty list tracks every token.
+	Tokens []string `json:"tokens"`
+}
+
+// UnmarshalJSON reads a bare address string or a wallet object
+func (w *WalletConfig) UnmarshalJSON(data []byte) error {
+	var address string
+	if err := json.Unmarshal(data, &address); err == nil {
+		*w = WalletConfig{Address: address}
+		return nil
+	}
+
+	type plain WalletConfig
+	if err := json.Unmarshal(data, (*plain)(w)); err != nil {
+		return fmt.Errorf("wallet must be an address or an object with an address: %w", err)
+	}
+	return nil
+}
+
+// UnmarshalJSON reads the configuration, splitting wallet objects into
+// Wallets and WalletTokens
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type plain Config
+	document := struct {
+		*plain
+		Wallets []WalletConfig `json:"wallets"`
+	}{plain: (*plain)(c)}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return err
+	}
+
+	c.Wallets = nil
+	for _, wallet := range document.Wallets {
+		c.Wallets = append(c.Wallets, wallet.Address)
+		if wallet.Tokens != nil {
+			if c.WalletTokens == nil {
+				c.WalletTokens = make(map[string][]string)
+			}
+			c.WalletTokens[wallet.Address] = wallet.Tokens
+		}
+	}
+	return nil
+}
+
+// TokensFor returns the token filter of a wallet. An empty list tracks
+// every token.
+func (c *Config) TokensFor(wallet string) []string {
+	if tokens, ok := c.WalletTokens[wallet]; ok {
+		return tokens
+	}
+	return c.Tokens
+}
@@ -0,0 +1,170 @@
+package counterparty
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// DefaultRetention is how long transfers are kept when no retention is set
+const DefaultRetention = 30 * 24 * time.Hour
+
+// Rankings for Top
+const (
+	ByFrequency = "frequency"
+	ByVolume    = "volume"
+)
+
+// Stat aggregates the transfers between a wallet and one counterparty
+type Stat struct {
+	Counterparty string `json:"counterparty"`
+	// Name and Kind are set for known entities, such as exchanges
+	Name      string `json:"name,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+	Transfers int    `json:"transfers"`
+	Incoming  int    `json:"incoming"`
+	Outgoing  int    `json:"outgoing"`
+	// Volume is the amount moved per mint in base units
+	Volume   map[string]uint64 `json:"volume"`
+	LastSeen time.Time         `json:"last_seen"`
+}
+
+// transfer is one counterparty leg of a wallet transfer
+type transfer struct {
+	counterparty string
+	name         string
+	kind         string
+	mint         string
+	amount       uint64
+	incoming     bool
+	at           time.Time
+}
+
+// Tracker keeps recent transfers per wallet and ranks their counterparties
+type Tracker struct {
+	retention time.Duration
+	transfers map[string][]transfer
+	mutex     sync.Mutex
+}
+
+// NewTracker creates a tracker that keeps transfers for retention
+func NewTracker(retention time.Duration) *Tracker {
+	if retention == 0 {
+		retention = DefaultRetention
+	}
+	return &Tracker{
+		retention: retention,
+		transfers: make(map[string][]transfer),
+	}
+}
+
+// Record adds the counterparties of a token balance change
+func (t *Tracker) Record(account solana.TokenAccountInfo, previous *solana.TokenAccountInfo, resolved *solana.Transfer) {
+	if resolved == nil || len(resolved.Counterparties) == 0 {
+		return
+	}
+
+	incoming := previous == nil || account.Balance > previous.Balance
+	at := resolved.BlockTime
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, counterparty := range resolved.Counterparties {
+		t.transfers[account.Owner] = append(t.transfers[account.Owner], transfer{
+			counterparty: counterparty.Owner,
+			name:         counterparty.Name,
+			kind:         counterparty.Kind,
+			mint:         account.Mint,
+			amount:       counterparty.Amount,
+			incoming:     incoming,
+			at:           at,
+		})
+	}
+	t.prune(account.Owner)
+}
+
+// Top ranks the counterparties of a wallet over the last window. Ranking by
+// volume compares amounts of one mint, so it requires mint.
+func (t *Tracker) Top(wallet string, window time.Duration, by, mint string, limit int) ([]Stat, error) {
+	if by == ByVolume && mint == "" {
+		return nil, fmt.Errorf("ranking by volume requires a mint")
+	}
+	if by != ByFrequency && by != ByVolume {
+		return nil, fmt.Errorf("unknown ranking %q", by)
+	}
+	if window <= 0 || window > t.retention {
+		window = t.retention
+	}
+
+	since := time.Now().Add(-window)
+	stats := make(map[string]*Stat)
+
+	t.mutex.Lock()
+	for _, transfer := range t.transfers[wallet] {
+		if transfer.at.Before(since) || (mint != "" && transfer.mint != mint) {
+			continue
+		}
+
+		stat, ok := stats[transfer.counterparty]
+		if !ok {
+			stat = &Stat{Counterparty: transfer.counterparty, Volume: make(map[string]uint64)}
+			stats[transfer.counterparty] = stat
+		}
+		stat.Transfers++
+		if transfer.incoming {
+			stat.Incoming++
+		} else {
+			stat.Outgoing++
+		}
+		stat.Volume[transfer.mint] += transfer.amount
+		if transfer.at.After(stat.LastSeen) {
+			stat.LastSeen = transfer.at
+		}
+		if transfer.name != "" {
+			stat.Name = transfer.name
+			stat.Kind = transfer.kind
+		}
+	}
+	t.mutex.Unlock()
+
+	result := make([]Stat, 0, len(stats))
+	for _, stat := range stats {
+		result = append(result, *stat)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if by == ByVolume && result[i].Volume[mint] != result[j].Volume[mint] {
+			return result[i].Volume[mint] > result[j].Volume[mint]
+		}
+		if result[i].Transfers != result[j].Transfers {
+			return result[i].Transfers > result[j].Transfers
+		}
+		return result[i].Counterparty < result[j].Counterparty
+	})
+
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// prune drops transfers of a wallet older than the retention. Callers must
+// hold the lock.
+func (t *Tracker) prune(wallet string) {
+	since := time.Now().Add(-t.retention)
+	transfers := t.transfers[wallet]
+
+	i := 0
+	for i < len(transfers) && transfers[i].at.Before(since) {
+		i++
+	}
+	if i > 0 {
+		t.transfers[wallet] = append([]transfer(nil), transfers[i:]...)
+	}
+}
@@ -0,0 +1,175 @@
+package das
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// pageLimit is the maximum page size accepted by DAS providers
+const pageLimit = 1000
+
+// Client is a Metaplex Digital Asset Standard (DAS) API client
+type Client struct {
+	endpoint   string
+	httpClient *http.Client
+}
+
+// Asset is a digital asset returned by the DAS API
+type Asset struct {
+	ID         string `json:"id"`
+	Interface  string `json:"interface"`
+	Name       string `json:"name"`
+	Symbol     string `json:"symbol,omitempty"`
+	Collection string `json:"collection,omitempty"`
+	Owner      string `json:"owner"`
+	Compressed bool   `json:"compressed"`
+	Delegated  bool   `json:"delegated"`
+	Frozen     bool   `json:"frozen"`
+	Burnt      bool   `json:"burnt"`
+}
+
+// Listed reports whether the asset appears listed on a marketplace.
+// Marketplaces take a delegate or freeze the asset while it is listed.
+func (a Asset) Listed() bool {
+	return a.Delegated || a.Frozen
+}
+
+// rawAsset mirrors the DAS asset response
+type rawAsset struct {
+	ID        string `json:"id"`
+	Interface string `json:"interface"`
+	Burnt     bool   `json:"burnt"`
+	Content   struct {
+		Metadata struct {
+			Name   string `json:"name"`
+			Symbol string `json:"symbol"`
+		} `json:"metadata"`
+	} `json:"content"`
+	Grouping []struct {
+		GroupKey   string `json:"group_key"`
+		GroupValue string `json:"group_value"`
+	} `json:"grouping"`
+	Compression struct {
+		Compressed bool `json:"compressed"`
+	} `json:"compression"`
+	Ownership struct {
+		Owner     string `json:"owner"`
+		Delegated bool   `json:"delegated"`
+		Frozen    bool   `json:"frozen"`
+	} `json:"ownership"`
+}
+
+// NewClient creates a DAS client for a provider endpoint
+func NewClient(endpoint string) *Client {
+	return &Client{
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// GetAssetsByOwner returns every asset held by a wallet
+func (c *Client) GetAssetsByOwner(ctx context.Context, owner string) ([]Asset, error) {
+	var assets []Asset
+	for page := 1; ; page++ {
+		var result struct {
+			Total int        `json:"total"`
+			Items []rawAsset `json:"items"`
+		}
+		err := c.call(ctx, "getAssetsByOwner", map[string]interface{}{
+			"ownerAddress": owner,
+			"page":         page,
+			"limit":        pageLimit,
+		}, &result)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range result.Items {
+			assets = append(assets, item.asset())
+		}
+		if len(result.Items) < pageLimit {
+			return assets, nil
+		}
+	}
+}
+
+// GetAsset returns a single asset by ID
+func (c *Client) GetAsset(ctx context.Context, id string) (*Asset, error) {
+	var result rawAsset
+	if err := c.call(ctx, "getAsset", map[string]interface{}{"id": id}, &result); err != nil {
+		return nil, err
+	}
+
+	asset := result.asset()
+	return &asset, nil
+}
+
+// asset converts a raw DAS response item
+func (r rawAsset) asset() Asset {
+	asset := Asset{
+		ID:         r.ID,
+		Interface:  r.Interface,
+		Name:       r.Content.Metadata.Name,
+		Symbol:     r.Content.Metadata.Symbol,
+		Owner:      r.Ownership.Owner,
+		Compressed: r.Compression.Compressed,
+		Delegated:  r.Ownership.Delegated,
+		Frozen:     r.Ownership.Frozen,
+		Burnt:      r.Burnt,
+	}
+	for _, group := range r.Grouping {
+		if group.GroupKey == "collection" {
+			asset.Collection = group.GroupValue
+		}
+	}
+	return asset
+}
+
+// call performs a JSON-RPC request against the DAS endpoint
+func (c *Client) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      "solana-wallet-tracker",
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned status %d", method, resp.StatusCode)
+	}
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", method, err)
+	}
+	if response.Error != nil {
+		return fmt.Errorf("%s failed: %s (%d)", method, response.Error.Message, response.Error.Code)
+	}
+
+	return json.Unmarshal(response.Result, result)
+}
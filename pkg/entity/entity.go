@@ -0,0 +1,152 @@
+// Package entity recognizes addresses of known entities, such as exchange
+// hot wallets, bridges and protocols, so counterparties can be named in
+// events and alerts.
+package entity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+)
+
+// DefaultRefresh is how often a remote list is fetched again by default
+const DefaultRefresh = 24 * time.Hour
+
+// Common kinds of entities
+const (
+	KindExchange = "exchange"
+	KindBridge   = "bridge"
+	KindProtocol = "protocol"
+)
+
+// Entity names the owner of an address
+type Entity struct {
+	Address string `json:"address"`
+	Name    string `json:"name"`
+	Kind    string `json:"kind,omitempty"`
+}
+
+// Directory looks up entities by address. Static entries take precedence
+// over those of the remote list, so they can correct it.
+type Directory struct {
+	static     map[string]Entity
+	remote     map[string]Entity
+	mutex      sync.RWMutex
+	httpClient *http.Client
+	log        logging.Logger
+}
+
+// NewDirectory creates a directory of static entities, logging through log
+// or the default logger when nil
+func NewDirectory(entities []Entity, log logging.Logger) *Directory {
+	if log == nil {
+		log = logging.Default()
+	}
+	static := make(map[string]Entity, len(entities))
+	for _, entity := range entities {
+		static[entity.Address] = entity
+	}
+	return &Directory{
+		static:     static,
+		remote:     make(map[string]Entity),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		log:        log,
+	}
+}
+
+// LoadFile reads a JSON array of entities
+func LoadFile(path string) ([]Entity, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open entity list: %w", err)
+	}
+	defer file.Close()
+	return decode(file)
+}
+
+// decode reads a JSON array of entities and checks that each has an address
+// and a name
+func decode(r io.Reader) ([]Entity, error) {
+	var entities []Entity
+	if err := json.NewDecoder(r).Decode(&entities); err != nil {
+		return nil, fmt.Errorf("failed to decode entity list: %w", err)
+	}
+	for i, entity := range entities {
+		if entity.Address == "" || entity.Name == "" {
+			return nil, fmt.Errorf("entity %d needs an address and a name", i)
+		}
+	}
+	return entities, nil
+}
+
+// Lookup returns the entity owning an address
+func (d *Directory) Lookup(address string) (Entity, bool) {
+	if entity, ok := d.static[address]; ok {
+		return entity, true
+	}
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	entity, ok := d.remote[address]
+	return entity, ok
+}
+
+// Fetch replaces the remote entities by the list served at url
+func (d *Directory) Fetch(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch entity list: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("entity list returned status %d", resp.StatusCode)
+	}
+	entities, err := decode(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	remote := make(map[string]Entity, len(entities))
+	for _, entity := range entities {
+		remote[entity.Address] = entity
+	}
+	d.mutex.Lock()
+	d.remote = remote
+	d.mutex.Unlock()
+
+	d.log.Info("Entity list fetched", "url", url, "entities", len(remote))
+	return nil
+}
+
+// Watch fetches the remote list again every interval until ctx is
+// cancelled. Failed fetches keep the previous list.
+func (d *Directory) Watch(ctx context.Context, url string, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultRefresh
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := d.Fetch(ctx, url); err != nil && ctx.Err() == nil {
+				d.log.Warn("Failed to refresh entity list", "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
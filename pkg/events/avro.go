@@ -0,0 +1,177 @@
+package events
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// MarshalAvro encodes a record in the Avro binary encoding of AvroSchema,
+// without a container or schema ID. Balances above the largest Avro long
+// don't fit and wrap around.
+func (r *Record) MarshalAvro() []byte {
+	var e avroEncoder
+	e.string(string(r.Type))
+	e.string(r.Wallet)
+	e.string(r.Account)
+	e.string(r.Mint)
+	e.long(int64(r.Decimals))
+	e.long(int64(r.Balance))
+	if r.PreviousBalance == nil {
+		e.long(0)
+	} else {
+		e.long(1)
+		e.long(int64(*r.PreviousBalance))
+	}
+	e.string(r.Symbol)
+	e.string(r.Signature)
+	e.long(int64(r.Slot))
+	e.long(r.observedAtMillis())
+	e.string(r.Severity)
+	e.string(r.Label)
+	e.string(r.Network)
+	e.strings(r.Groups)
+	e.strings(r.Tenants)
+	e.bool(r.Hot)
+	e.bool(r.Replayed)
+	e.long(int64(r.Suppressed))
+	e.string(r.Text)
+	return e.buf
+}
+
+// UnmarshalAvro decodes a record in the Avro binary encoding of AvroSchema
+func UnmarshalAvro(data []byte) (*Record, error) {
+	d := avroDecoder{buf: data}
+	r := &Record{}
+	r.Type = Type(d.string())
+	r.Wallet = d.string()
+	r.Account = d.string()
+	r.Mint = d.string()
+	r.Decimals = uint8(d.long())
+	r.Balance = uint64(d.long())
+	switch branch := d.long(); branch {
+	case 0:
+	case 1:
+		previous := uint64(d.long())
+		r.PreviousBalance = &previous
+	default:
+		if d.err == nil {
+			d.err = fmt.Errorf("invalid branch %d of previous_balance", branch)
+		}
+	}
+	r.Symbol = d.string()
+	r.Signature = d.string()
+	r.Slot = uint64(d.long())
+	r.setObservedAtMillis(d.long())
+	r.Severity = d.string()
+	r.Label = d.string()
+	r.Network = d.string()
+	r.Groups = d.strings()
+	r.Tenants = d.strings()
+	r.Hot = d.bool()
+	r.Replayed = d.bool()
+	r.Suppressed = int(d.long())
+	r.Text = d.string()
+	if d.err != nil {
+		return nil, fmt.Errorf("failed to decode Avro record: %w", d.err)
+	}
+	return r, nil
+}
+
+// avroEncoder appends Avro binary values
+type avroEncoder struct {
+	buf []byte
+}
+
+// long appends a zigzag varint, as Avro encodes ints and longs
+func (e *avroEncoder) long(value int64) {
+	e.buf = binary.AppendVarint(e.buf, value)
+}
+
+func (e *avroEncoder) bool(value bool) {
+	if value {
+		e.buf = append(e.buf, 1)
+	} else {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+func (e *avroEncoder) string(value string) {
+	e.long(int64(len(value)))
+	e.buf = append(e.buf, value...)
+}
+
+// strings appends an array of strings as a single block
+func (e *avroEncoder) strings(values []string) {
+	if len(values) > 0 {
+		e.long(int64(len(values)))
+		for _, value := range values {
+			e.string(value)
+		}
+	}
+	e.long(0)
+}
+
+// avroDecoder reads Avro binary values, keeping the first error
+type avroDecoder struct {
+	buf []byte
+	err error
+}
+
+func (d *avroDecoder) long() int64 {
+	if d.err != nil {
+		return 0
+	}
+	value, n := binary.Varint(d.buf)
+	if n <= 0 {
+		d.err = errTruncated
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return value
+}
+
+func (d *avroDecoder) bool() bool {
+	if d.err != nil {
+		return false
+	}
+	if len(d.buf) == 0 {
+		d.err = errTruncated
+		return false
+	}
+	value := d.buf[0] != 0
+	d.buf = d.buf[1:]
+	return value
+}
+
+func (d *avroDecoder) string() string {
+	length := d.long()
+	if d.err != nil {
+		return ""
+	}
+	if length < 0 || length > int64(len(d.buf)) {
+		d.err = errTruncated
+		return ""
+	}
+	value := string(d.buf[:length])
+	d.buf = d.buf[length:]
+	return value
+}
+
+// strings reads an array of strings. Blocks of a negative count are
+// followed by their size in bytes.
+func (d *avroDecoder) strings() []string {
+	var values []string
+	for {
+		count := d.long()
+		if d.err != nil || count == 0 {
+			return values
+		}
+		if count < 0 {
+			count = -count
+			d.long()
+		}
+		for i := int64(0); i < count && d.err == nil; i++ {
+			values = append(values, d.string())
+		}
+	}
+}
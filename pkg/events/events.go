@@ -0,0 +1,446 @@
+// Package events lets webhook receivers consume tracker payloads. It mirrors
+// the JSON the webhook notifier posts, validates it and verifies its HMAC
+// signature, and depends on the standard library only. Record is the flat
+// Avro or protobuf form Pub/Sub channels can publish instead.
+//
+//	http.HandleFunc("/hook", func(w http.ResponseWriter, r *http.Request) {
+//		payload, err := events.ParseRequest(r, secret)
+//		if err != nil {
+//			http.Error(w, err.Error(), http.StatusBadRequest)
+//			return
+//		}
+//		for _, event := range payload.All() {
+//			log.Printf("%s %s", event.Type, event.Account.Owner)
+//		}
+//	})
+package events
+
+import (
+	"math/big"
+	"time"
+)
+
+// Type identifies the kind of change an event reports
+type Type string
+
+// Event types
+const (
+	BalanceChanged         Type = "balance_changed"
+	NewHolding             Type = "new_holding"
+	LargeHolderMove        Type = "large_holder_move"
+	StakeDelegationChanged Type = "stake_delegation_changed"
+	StakeActivated         Type = "stake_activated"
+	StakeDeactivated       Type = "stake_deactivated"
+	StakeReward            Type = "stake_reward"
+	NFTReceived            Type = "nft_received"
+	NFTSent                Type = "nft_sent"
+	NFTListed              Type = "nft_listed"
+	NFTBurned              Type = "nft_burned"
+	NFTMinted              Type = "nft_minted"
+	GroupThreshold         Type = "group_threshold"
+	WalletStale            Type = "wallet_stale"
+	TokenBurned            Type = "token_burned"
+	DelegateChanged        Type = "delegate_changed"
+	AuthorityChanged       Type = "authority_changed"
+	AccountFrozen          Type = "account_frozen"
+	AccountThawed          Type = "account_thawed"
+	AccountClosed          Type = "account_closed"
+	Signature              Type = "signature"
+)
+
+// IsStake reports whether events of the type carry Stake
+func (t Type) IsStake() bool {
+	switch t {
+	case StakeDelegationChanged, StakeActivated, StakeDeactivated, StakeReward:
+		return true
+	}
+	return false
+}
+
+// IsNFT reports whether events of the type carry NFT
+func (t Type) IsNFT() bool {
+	switch t {
+	case NFTReceived, NFTSent, NFTListed, NFTBurned, NFTMinted:
+		return true
+	}
+	return false
+}
+
+// known lists the event types a payload may carry
+var known = map[Type]bool{
+	BalanceChanged: true, NewHolding: true, LargeHolderMove: true,
+	StakeDelegationChanged: true, StakeActivated: true, StakeDeactivated: true, StakeReward: true,
+	NFTReceived: true, NFTSent: true, NFTListed: true, NFTBurned: true, NFTMinted: true,
+	GroupThreshold: true, WalletStale: true, TokenBurned: true, DelegateChanged: true,
+	AuthorityChanged: true, AccountFrozen: true, AccountThawed: true, AccountClosed: true,
+	Signature: true,
+}
+
+// Payload is the body of a webhook request. A single event is sent in
+// Event; a batch of events of one wallet in Events, which Digest sums up for
+// channels with a digest window. Reports of the tracker's watchdog carry
+// Health and no event.
+type Payload struct {
+	Text   string  `json:"text"`
+	Locale string  `json:"locale"`
+	Event  *Event  `json:"event,omitempty"`
+	Events []Event `json:"events,omitempty"`
+	Health *Health `json:"health,omitempty"`
+	Digest *Digest `json:"digest,omitempty"`
+	// FlowReport is set on scheduled net flow reports, which carry no events
+	FlowReport *FlowReport `json:"flow_report,omitempty"`
+}
+
+// Digest sums up the events of a wallet within the digest window of a
+// channel
+type Digest struct {
+	Window  string      `json:"window"`
+	Changes []NetChange `json:"changes"`
+}
+
+// NetChange is the balance change of a token over the events of a digest,
+// in base units, summed over the token accounts of the wallet
+type NetChange struct {
+	Mint     string `json:"mint"`
+	Symbol   string `json:"symbol,omitempty"`
+	Decimals uint8  `json:"decimals"`
+	Before   uint64 `json:"before"`
+	After    uint64 `json:"after"`
+}
+
+// FlowReport is the net flows of the tracked wallets over a day or week,
+// per wallet and mint and per mint over every wallet
+type FlowReport struct {
+	// By is day or week
+	By string `json:"by"`
+	// Period is the UTC day, or the ISO week such as 2024-W05
+	Period  string `json:"period,omitempty"`
+	Wallets []Flow `json:"wallets"`
+	Mints   []Flow `json:"mints"`
+}
+
+// Flow is what moved in and out of the token accounts of a mint in a
+// period, in base units. The sums may exceed 64 bits.
+type Flow struct {
+	// Wallet is empty for the flows of a mint over every wallet
+	Wallet   string   `json:"wallet,omitempty"`
+	Mint     string   `json:"mint"`
+	Symbol   string   `json:"symbol,omitempty"`
+	Period   string   `json:"period,omitempty"`
+	Decimals uint8    `json:"decimals"`
+	Inflow   *big.Int `json:"inflow"`
+	Outflow  *big.Int `json:"outflow"`
+	// Net is Inflow minus Outflow
+	Net *big.Int `json:"net"`
+	// Changes counts the balance changes behind the flow
+	Changes int `json:"changes"`
+}
+
+// All returns the events of the payload, whether it is a single event or a
+// batch. It is empty for health and flow reports.
+func (p *Payload) All() []Event {
+	if p.Event != nil {
+		return []Event{*p.Event}
+	}
+	return p.Events
+}
+
+// Health statuses
+const (
+	HealthOK        = "ok"
+	HealthDegraded  = "degraded"
+	HealthRecovered = "recovered"
+)
+
+// Health is a report of the tracker about itself. Heartbeats carry the
+// current status; degraded and recovered reports are sent when it changes.
+type Health struct {
+	Status    string `json:"status"`
+	Heartbeat bool   `json:"heartbeat,omitempty"`
+	// Problems are no_events or rpc_failures while degraded
+	Problems []string `json:"problems,omitempty"`
+	Uptime   string   `json:"uptime"`
+	Wallets  int      `json:"wallets"`
+	// Events counts the events seen since the previous report
+	Events      int        `json:"events"`
+	LastEventAt *time.Time `json:"last_event_at,omitempty"`
+	QuietFor    string     `json:"quiet_for,omitempty"`
+	RPCFailures int        `json:"rpc_failures,omitempty"`
+	RPCError    string     `json:"rpc_error,omitempty"`
+	At          time.Time  `json:"at"`
+}
+
+// Event is a change detected by the tracker. For stake and NFT events the
+// stake account or asset is mirrored into Account so every event can be
+// routed by Account.Owner.
+type Event struct {
+	Type     Type           `json:"type"`
+	Account  TokenAccount   `json:"account"`
+	Previous *TokenAccount  `json:"previous,omitempty"`
+	Metadata *TokenMetadata `json:"metadata,omitempty"`
+	Risk     *RiskReport    `json:"risk,omitempty"`
+	Stake    *Stake         `json:"stake,omitempty"`
+	NFT      *Asset         `json:"nft,omitempty"`
+	Transfer *Transfer      `json:"transfer,omitempty"`
+	// Group is the crossed total of group_threshold events, whose Account is
+	// the balance change that crossed it
+	Group *GroupTotal `json:"group,omitempty"`
+	// Stale describes the wallet of wallet_stale events, whose Account
+	// carries the wallet as Address and Owner, without a mint
+	Stale *StaleWallet `json:"stale,omitempty"`
+	// Burn is the amount burned of token_burned events
+	Burn *Burn `json:"burn,omitempty"`
+	// Authority is the change of authority_changed events
+	Authority *Authority `json:"authority,omitempty"`
+	// Freeze names the freeze authority of account_frozen and
+	// account_thawed events
+	Freeze *Freeze `json:"freeze,omitempty"`
+	// Signature is the transaction of signature events, sent by trackers in
+	// watch-only mode. Their Account carries the wallet as Address and
+	// Owner, without a mint.
+	Signature *SignatureEvent `json:"signature,omitempty"`
+	// WalletRisk is the risk score of the wallet after this event
+	WalletRisk *WalletRisk `json:"wallet_risk,omitempty"`
+	// Severity is info, warn, anomaly or critical, from the severity rules
+	// or the anomaly detector. Anomaly describes anomalous transfers.
+	Severity string   `json:"severity,omitempty"`
+	Anomaly  *Anomaly `json:"anomaly,omitempty"`
+	// Label and Groups come from the configured label of the account owner
+	Label  string   `json:"label,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+	// Tenants are the tenants whose watchlists hold the account owner
+	Tenants []string `json:"tenants,omitempty"`
+	// Domain is the .sol domain the account owner was configured as
+	Domain string `json:"domain,omitempty"`
+	// Network is the network of the wallet, e.g. mainnet-beta or devnet,
+	// when the tracker is configured with a network name or several networks
+	Network string `json:"network,omitempty"`
+	// Hot marks events of hot wallets, which skip enrichment
+	Hot bool `json:"hot,omitempty"`
+	// Replayed marks past events resent by `tracker replay`
+	Replayed bool `json:"replayed,omitempty"`
+	// Suppressed counts the earlier events of the wallet and mint the
+	// notification cooldown held back since the last one delivered
+	Suppressed int `json:"suppressed,omitempty"`
+}
+
+// TokenAccount is the state of a token account. Balance is in base units.
+type TokenAccount struct {
+	Address   string `json:"address"`
+	Owner     string `json:"owner"`
+	Mint      string `json:"mint"`
+	Balance   uint64 `json:"balance"`
+	Decimals  uint8  `json:"decimals"`
+	ProgramID string `json:"program_id"`
+	Delegate  string `json:"delegate,omitempty"`
+	// DelegatedAmount is how much the delegate may still transfer
+	DelegatedAmount uint64 `json:"delegated_amount,omitempty"`
+	CloseAuthority  string `json:"close_authority,omitempty"`
+	Frozen          bool   `json:"frozen,omitempty"`
+	// Slot and Signature locate the change on chain when known
+	Slot          uint64    `json:"slot,omitempty"`
+	Signature     string    `json:"signature,omitempty"`
+	LastUpdatedAt time.Time `json:"last_updated_at"`
+	// UIAmount and UIAmountString are Balance in UI units, the latter exact
+	UIAmount       float64 `json:"ui_amount"`
+	UIAmountString string  `json:"ui_amount_string"`
+}
+
+// TokenMetadata is the Metaplex name and symbol of a mint
+type TokenMetadata struct {
+	Mint   string `json:"mint"`
+	Name   string `json:"name"`
+	Symbol string `json:"symbol"`
+	URI    string `json:"uri,omitempty"`
+}
+
+// RiskReport summarizes rug-check findings for a mint
+type RiskReport struct {
+	MintAuthority   string   `json:"mint_authority,omitempty"`
+	FreezeAuthority string   `json:"freeze_authority,omitempty"`
+	PriceImpact     *float64 `json:"price_impact,omitempty"`
+	TopHoldersShare *float64 `json:"top_holders_share,omitempty"`
+	Flags           []string `json:"flags,omitempty"`
+	// Score is from 0 to 100, higher is riskier
+	Score int `json:"score"`
+}
+
+// Stake describes a stake account change
+type Stake struct {
+	Account  StakeAccount  `json:"account"`
+	Previous *StakeAccount `json:"previous,omitempty"`
+	Reward   *Reward       `json:"reward,omitempty"`
+}
+
+// StakeAccount is the state of a stake account. Amounts are in lamports.
+type StakeAccount struct {
+	Address           string    `json:"address"`
+	Staker            string    `json:"staker"`
+	Withdrawer        string    `json:"withdrawer"`
+	Lamports          uint64    `json:"lamports"`
+	Voter             string    `json:"voter,omitempty"`
+	DelegatedStake    uint64    `json:"delegated_stake"`
+	ActivationEpoch   uint64    `json:"activation_epoch"`
+	DeactivationEpoch uint64    `json:"deactivation_epoch"`
+	Status            string    `json:"status"`
+	LastUpdatedAt     time.Time `json:"last_updated_at"`
+}
+
+// Reward is an inflation reward credited to a stake account
+type Reward struct {
+	Address     string `json:"address"`
+	Epoch       uint64 `json:"epoch"`
+	Amount      uint64 `json:"amount"`
+	PostBalance uint64 `json:"post_balance"`
+}
+
+// Asset is an NFT or compressed NFT
+type Asset struct {
+	ID         string `json:"id"`
+	Interface  string `json:"interface"`
+	Name       string `json:"name"`
+	Symbol     string `json:"symbol,omitempty"`
+	Collection string `json:"collection,omitempty"`
+	Owner      string `json:"owner"`
+	Compressed bool   `json:"compressed"`
+	Delegated  bool   `json:"delegated"`
+	Frozen     bool   `json:"frozen"`
+	Burnt      bool   `json:"burnt"`
+}
+
+// Transfer is the transaction behind a balance change
+type Transfer struct {
+	Signature string    `json:"signature"`
+	Slot      uint64    `json:"slot"`
+	BlockTime time.Time `json:"block_time"`
+	Memo      string    `json:"memo,omitempty"`
+	// Direction is "in" or "out" for the tracked account
+	Direction string `json:"direction"`
+	// Kind is "mint" for tokens minted to the tracked account, such as
+	// airdrops and claims, "burn" for tokens it burned and "transfer"
+	// otherwise
+	Kind           string         `json:"kind,omitempty"`
+	MintAuthority  string         `json:"mint_authority,omitempty"`
+	Burned         uint64         `json:"burned,omitempty"`
+	Counterparties []Counterparty `json:"counterparties,omitempty"`
+}
+
+// Counterparty is the other side of a transfer
+type Counterparty struct {
+	Owner   string `json:"owner"`
+	Account string `json:"account"`
+	Amount  uint64 `json:"amount"`
+	// Name and Kind identify known entities such as exchanges
+	Name string `json:"name,omitempty"`
+	Kind string `json:"kind,omitempty"`
+}
+
+// Burn describes tokens a tracked account burned
+type Burn struct {
+	// Amount is in base units, in the decimals of the account
+	Amount uint64 `json:"amount"`
+}
+
+// Authority kinds
+const (
+	AuthorityOwner   = "owner"
+	AuthorityClose   = "close_authority"
+	AuthorityProgram = "program"
+)
+
+// Authority describes an authority of a tracked account that changed: a
+// token account transferred to another owner, a close authority set or
+// removed, or a wallet assigned from the system program to another program.
+// Events of program changes carry the wallet as Account.Address and Owner.
+type Authority struct {
+	Kind     string `json:"kind"`
+	Previous string `json:"previous,omitempty"`
+	Current  string `json:"current,omitempty"`
+}
+
+// Freeze describes the freeze authority behind a freeze or thaw
+type Freeze struct {
+	// FreezeAuthority is empty when it couldn't be looked up
+	FreezeAuthority string `json:"freeze_authority,omitempty"`
+}
+
+// SignatureEvent is a transaction mentioning a wallet
+type SignatureEvent struct {
+	Signature string `json:"signature"`
+	Slot      uint64 `json:"slot"`
+	Failed    bool   `json:"failed,omitempty"`
+}
+
+// StaleWallet describes a wallet that received no subscription notification
+// and no successful poll within the configured window
+type StaleWallet struct {
+	// LastActivityAt is the last notification or successful poll, or the
+	// start of monitoring
+	LastActivityAt time.Time `json:"last_activity_at"`
+	StaleFor       string    `json:"stale_for"`
+	// Subscription is active, retrying, failed or stopped
+	Subscription string `json:"subscription"`
+	LastError    string `json:"last_error,omitempty"`
+}
+
+// Directions a group total crosses a threshold in
+const (
+	CrossedBelow = "below"
+	CrossedAbove = "above"
+)
+
+// GroupTotal is a total over the wallets of a group, or over every tracked
+// wallet when Group is empty, that crossed a threshold. It is in UI units of
+// Mint, or in USD when Mint is empty.
+type GroupTotal struct {
+	Group     string  `json:"group"`
+	Mint      string  `json:"mint,omitempty"`
+	Total     float64 `json:"total"`
+	Previous  float64 `json:"previous"`
+	Crossed   string  `json:"crossed"`
+	Threshold float64 `json:"threshold"`
+	Wallets   int     `json:"wallets"`
+}
+
+// Severities of events, lowest first. Events without one are info.
+const (
+	SeverityInfo     = "info"
+	SeverityWarn     = "warn"
+	SeverityAnomaly  = "anomaly"
+	SeverityCritical = "critical"
+)
+
+// Reasons a transfer is anomalous
+const (
+	AnomalySize     = "size"
+	AnomalyHoldings = "holdings"
+)
+
+// Anomaly describes a transfer far outside the learned sizes of the wallet
+// and mint. Amounts are in UI units and HoldingsShare in percent.
+type Anomaly struct {
+	Reasons       []string `json:"reasons"`
+	Amount        float64  `json:"amount"`
+	Mean          float64  `json:"mean"`
+	StdDev        float64  `json:"std_dev"`
+	Sigma         float64  `json:"sigma,omitempty"`
+	HoldingsShare float64  `json:"holdings_share,omitempty"`
+	Samples       int64    `json:"samples"`
+}
+
+// WalletRisk is the risk score of a wallet from 0 to 100
+type WalletRisk struct {
+	Wallet   string    `json:"wallet"`
+	Score    int       `json:"score"`
+	Findings []Finding `json:"findings,omitempty"`
+}
+
+// Finding is one signal contributing to a risk score
+type Finding struct {
+	Signal     string    `json:"signal"`
+	Points     int       `json:"points"`
+	Account    string    `json:"account,omitempty"`
+	Mint       string    `json:"mint,omitempty"`
+	Detail     string    `json:"detail,omitempty"`
+	ObservedAt time.Time `json:"observed_at"`
+}
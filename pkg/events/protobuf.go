@@ -0,0 +1,170 @@
+package events
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// MarshalProto encodes a record as the Event message of ProtoSchema.
+// Fields of default value are left out, as in proto3.
+func (r *Record) MarshalProto() []byte {
+	var e protoEncoder
+	e.string(1, string(r.Type))
+	e.string(2, r.Wallet)
+	e.string(3, r.Account)
+	e.string(4, r.Mint)
+	e.varint(5, uint64(r.Decimals))
+	e.varint(6, r.Balance)
+	if r.PreviousBalance != nil {
+		e.tag(7, wireVarint)
+		e.buf = binary.AppendUvarint(e.buf, *r.PreviousBalance)
+	}
+	e.string(8, r.Symbol)
+	e.string(9, r.Signature)
+	e.varint(10, r.Slot)
+	e.varint(11, uint64(r.observedAtMillis()))
+	e.string(12, r.Severity)
+	e.string(13, r.Label)
+	e.string(14, r.Network)
+	for _, group := range r.Groups {
+		e.bytes(15, []byte(group))
+	}
+	for _, tenant := range r.Tenants {
+		e.bytes(16, []byte(tenant))
+	}
+	e.bool(17, r.Hot)
+	e.bool(18, r.Replayed)
+	e.varint(19, uint64(r.Suppressed))
+	e.string(20, r.Text)
+	return e.buf
+}
+
+// UnmarshalProto decodes the Event message of ProtoSchema. Unknown fields
+// are skipped.
+func UnmarshalProto(data []byte) (*Record, error) {
+	r := &Record{}
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, fmt.Errorf("failed to decode protobuf record: %w", errTruncated)
+		}
+		data = data[n:]
+		field, wire := int(key>>3), int(key&7)
+
+		var number uint64
+		var value []byte
+		switch wire {
+		case wireVarint:
+			number, n = binary.Uvarint(data)
+		case wireFixed64:
+			n = 8
+		case wireFixed32:
+			n = 4
+		case wireBytes:
+			var length uint64
+			length, n = binary.Uvarint(data)
+			if n > 0 && length <= uint64(len(data)-n) {
+				value = data[n : n+int(length)]
+				n += int(length)
+			} else {
+				n = 0
+			}
+		default:
+			return nil, fmt.Errorf("failed to decode protobuf record: wire type %d of field %d", wire, field)
+		}
+		if n <= 0 || n > len(data) {
+			return nil, fmt.Errorf("failed to decode protobuf record: %w", errTruncated)
+		}
+		data = data[n:]
+
+		switch field {
+		case 1:
+			r.Type = Type(value)
+		case 2:
+			r.Wallet = string(value)
+		case 3:
+			r.Account = string(value)
+		case 4:
+			r.Mint = string(value)
+		case 5:
+			r.Decimals = uint8(number)
+		case 6:
+			r.Balance = number
+		case 7:
+			previous := number
+			r.PreviousBalance = &previous
+		case 8:
+			r.Symbol = string(value)
+		case 9:
+			r.Signature = string(value)
+		case 10:
+			r.Slot = number
+		case 11:
+			r.setObservedAtMillis(int64(number))
+		case 12:
+			r.Severity = string(value)
+		case 13:
+			r.Label = string(value)
+		case 14:
+			r.Network = string(value)
+		case 15:
+			r.Groups = append(r.Groups, string(value))
+		case 16:
+			r.Tenants = append(r.Tenants, string(value))
+		case 17:
+			r.Hot = number != 0
+		case 18:
+			r.Replayed = number != 0
+		case 19:
+			r.Suppressed = int(number)
+		case 20:
+			r.Text = string(value)
+		}
+	}
+	return r, nil
+}
+
+// protoEncoder appends protobuf fields, leaving out those of default value
+type protoEncoder struct {
+	buf []byte
+}
+
+// tag appends the key of a field
+func (e *protoEncoder) tag(field, wire int) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(field<<3|wire))
+}
+
+func (e *protoEncoder) varint(field int, value uint64) {
+	if value != 0 {
+		e.tag(field, wireVarint)
+		e.buf = binary.AppendUvarint(e.buf, value)
+	}
+}
+
+func (e *protoEncoder) bool(field int, value bool) {
+	if value {
+		e.varint(field, 1)
+	}
+}
+
+// bytes appends a length-delimited field, even if empty, as elements of
+// repeated fields are
+func (e *protoEncoder) bytes(field int, value []byte) {
+	e.tag(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(value)))
+	e.buf = append(e.buf, value...)
+}
+
+func (e *protoEncoder) string(field int, value string) {
+	if value != "" {
+		e.bytes(field, []byte(value))
+	}
+}
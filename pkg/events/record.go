@@ -0,0 +1,71 @@
+package events
+
+import (
+	_ "embed"
+	"errors"
+	"time"
+)
+
+// AvroSchema is the Avro schema of records, for channels publishing Avro
+//
+//go:embed event.avsc
+var AvroSchema string
+
+// ProtoSchema is the protobuf definition of records, for channels
+// publishing protobuf
+//
+//go:embed event.proto
+var ProtoSchema string
+
+// errTruncated is returned for binary records that end inside a field
+var errTruncated = errors.New("truncated record")
+
+// Record is the flat form of an event that channels publish as Avro or
+// protobuf, for streaming platforms checking messages against a schema. It
+// holds the fields every event has; details such as stake accounts and
+// transfers are only in the JSON payload.
+type Record struct {
+	Type    Type
+	Wallet  string
+	Account string
+	Mint    string
+	// Decimals, Balance and PreviousBalance are those of Account.
+	// PreviousBalance is nil when the event doesn't know it.
+	Decimals        uint8
+	Balance         uint64
+	PreviousBalance *uint64
+	Symbol          string
+	Signature       string
+	Slot            uint64
+	// ObservedAt is when the tracker observed the change, to the
+	// millisecond
+	ObservedAt time.Time
+	Severity   string
+	Label      string
+	Network    string
+	Groups     []string
+	Tenants    []string
+	Hot        bool
+	Replayed   bool
+	Suppressed int
+	// Text is the built-in message of the event in the locale of the
+	// channel
+	Text string
+}
+
+// observedAtMillis returns ObservedAt in milliseconds since the Unix epoch,
+// zero if unset
+func (r *Record) observedAtMillis() int64 {
+	if r.ObservedAt.IsZero() {
+		return 0
+	}
+	return r.ObservedAt.UnixMilli()
+}
+
+// setObservedAtMillis sets ObservedAt from milliseconds since the Unix
+// epoch, leaving it unset for zero
+func (r *Record) setObservedAtMillis(millis int64) {
+	if millis != 0 {
+		r.ObservedAt = time.UnixMilli(millis).UTC()
+	}
+}
@@ -0,0 +1,170 @@
+package events
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// records are the records every codec must round-trip
+func records() map[string]*Record {
+	zero := uint64(0)
+	previous := uint64(1500)
+	largest := uint64(math.MaxUint64)
+	return map[string]*Record{
+		"empty": {},
+		"every field": {
+			Type:            "balance_changed",
+			Wallet:          "wallet",
+			Account:         "account",
+			Mint:            "mint",
+			Decimals:        6,
+			Balance:         2500,
+			PreviousBalance: &previous,
+			Symbol:          "USDC",
+			Signature:       "sig",
+			Slot:            312345678,
+			ObservedAt:      time.Date(2024, 12, 1, 8, 30, 0, 123000000, time.UTC),
+			Severity:        "critical",
+			Label:           "treasury",
+			Network:         "devnet",
+			Groups:          []string{"ops", "cold"},
+			Tenants:         []string{"acme"},
+			Hot:             true,
+			Replayed:        true,
+			Suppressed:      3,
+			Text:            "Balance changed: +1,000 USDC\nwith ünïcode",
+		},
+		"previous balance of zero": {Type: "new_holding", Balance: 10, PreviousBalance: &zero},
+		"largest balances":         {Balance: largest, PreviousBalance: &largest, Slot: largest},
+		"empty group":              {Groups: []string{""}, Tenants: []string{"", "t"}},
+	}
+}
+
+func TestProtoRoundTrip(t *testing.T) {
+	for name, record := range records() {
+		decoded, err := UnmarshalProto(record.MarshalProto())
+		if err != nil {
+			t.Fatalf("%s: UnmarshalProto: %v", name, err)
+		}
+		if !reflect.DeepEqual(decoded, record) {
+			t.Errorf("%s: got %+v, want %+v", name, decoded, record)
+		}
+	}
+}
+
+func TestAvroRoundTrip(t *testing.T) {
+	for name, record := range records() {
+		decoded, err := UnmarshalAvro(record.MarshalAvro())
+		if err != nil {
+			t.Fatalf("%s: UnmarshalAvro: %v", name, err)
+		}
+		if !reflect.DeepEqual(decoded, record) {
+			t.Errorf("%s: got %+v, want %+v", name, decoded, record)
+		}
+	}
+}
+
+func TestTruncatedRecords(t *testing.T) {
+	record := records()["every field"]
+	codecs := []struct {
+		name      string
+		marshal   func(*Record) []byte
+		unmarshal func([]byte) (*Record, error)
+		// cuts are where the encoding is cut; protobuf cut between fields
+		// is a valid shorter record, so it is only cut inside fields
+		cuts func(length int) []int
+	}{
+		{"protobuf", (*Record).MarshalProto, UnmarshalProto, func(length int) []int { return []int{1, length - 1} }},
+		{"avro", (*Record).MarshalAvro, UnmarshalAvro, func(length int) []int { return []int{1, length / 2, length - 1} }},
+	}
+	for _, codec := range codecs {
+		data := codec.marshal(record)
+		for _, cut := range codec.cuts(len(data)) {
+			if _, err := codec.unmarshal(data[:cut]); !errors.Is(err, errTruncated) {
+				t.Errorf("%s cut at %d of %d: got %v, want a truncated record error", codec.name, cut, len(data), err)
+			}
+		}
+	}
+}
+
+func TestProtoSkipsUnknownFields(t *testing.T) {
+	var e protoEncoder
+	e.string(1, "balance_changed")
+	e.varint(99, 7)
+	e.string(100, "from a newer schema")
+	e.tag(101, wireFixed64)
+	e.buf = append(e.buf, 1, 2, 3, 4, 5, 6, 7, 8)
+	e.tag(102, wireFixed32)
+	e.buf = append(e.buf, 1, 2, 3, 4)
+	e.string(2, "wallet")
+
+	record, err := UnmarshalProto(e.buf)
+	if err != nil {
+		t.Fatalf("UnmarshalProto: %v", err)
+	}
+	if record.Type != "balance_changed" || record.Wallet != "wallet" {
+		t.Errorf("got %+v, want the known fields", record)
+	}
+}
+
+func TestAvroEncoding(t *testing.T) {
+	// Longs are zigzag varints, see
+	// https://avro.apache.org/docs/1.11.1/specification/#primitive-types-1
+	longs := []struct {
+		value int64
+		want  []byte
+	}{
+		{0, []byte{0x00}},
+		{-1, []byte{0x01}},
+		{1, []byte{0x02}},
+		{-64, []byte{0x7f}},
+		{64, []byte{0x80, 0x01}},
+	}
+	for _, tt := range longs {
+		var e avroEncoder
+		e.long(tt.value)
+		if !reflect.DeepEqual(e.buf, tt.want) {
+			t.Errorf("long %d encoded as %x, want %x", tt.value, e.buf, tt.want)
+		}
+	}
+
+	// Arrays may come in blocks of a negative count followed by their size
+	// in bytes
+	var e avroEncoder
+	for i := 0; i < 4; i++ {
+		e.string("")
+	}
+	e.long(0)
+	e.long(0)
+	e.long(0)
+	e.string("")
+	e.string("")
+	e.long(0)
+	e.long(0)
+	for i := 0; i < 3; i++ {
+		e.string("")
+	}
+	e.long(-2)
+	e.long(4)
+	e.string("a")
+	e.string("b")
+	e.long(1)
+	e.string("c")
+	e.long(0)
+	e.strings(nil)
+	e.bool(false)
+	e.bool(false)
+	e.long(0)
+	e.string("")
+
+	record, err := UnmarshalAvro(e.buf)
+	if err != nil {
+		t.Fatalf("UnmarshalAvro: %v", err)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(record.Groups, want) {
+		t.Errorf("groups %q, want %q", record.Groups, want)
+	}
+}
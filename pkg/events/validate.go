@@ -0,0 +1,176 @@
+package events
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Schema is the JSON Schema (draft-07) of webhook payloads, for receivers
+// written in other languages. Validate applies the same rules.
+//
+//go:embed schema.json
+var Schema string
+
+// ValidationError lists every way a payload violates the schema
+type ValidationError struct {
+	Problems []string
+}
+
+// Error lists the problems on one line
+func (e *ValidationError) Error() string {
+	return "invalid payload: " + strings.Join(e.Problems, "; ")
+}
+
+// add records a problem
+func (e *ValidationError) add(field, format string, args ...interface{}) {
+	e.Problems = append(e.Problems, field+" "+fmt.Sprintf(format, args...))
+}
+
+// Decode parses and validates a payload. Unknown fields are ignored so
+// receivers keep working when the tracker adds information.
+func Decode(body []byte) (*Payload, error) {
+	var payload Payload
+	if err := json.NewDecoder(bytes.NewReader(body)).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode payload: %w", err)
+	}
+	if err := Validate(&payload); err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
+
+// Validate checks that a payload carries one event, a batch, a health report
+// or a flow report and that every event has the fields its type requires. It returns a
+// *ValidationError.
+func Validate(payload *Payload) error {
+	problems := &ValidationError{}
+
+	switch {
+	case payload.Health != nil:
+		if payload.Event != nil || len(payload.Events) > 0 {
+			problems.add("payload", "has both health and events")
+		}
+		validateHealth(payload.Health, problems)
+	case payload.FlowReport != nil:
+		if payload.Event != nil || len(payload.Events) > 0 {
+			problems.add("payload", "has both flow_report and events")
+		}
+		if payload.FlowReport.By != "day" && payload.FlowReport.By != "week" {
+			problems.add("flow_report.by", "%q is not one of day, week", payload.FlowReport.By)
+		}
+	case payload.Event != nil && len(payload.Events) > 0:
+		problems.add("payload", "has both event and events")
+	case payload.Event != nil:
+		validateEvent("event", payload.Event, problems)
+	case len(payload.Events) > 0:
+		for i := range payload.Events {
+			validateEvent(fmt.Sprintf("events[%d]", i), &payload.Events[i], problems)
+		}
+	default:
+		problems.add("payload", "has no event")
+	}
+
+	if len(problems.Problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+// validateEvent checks one event
+func validateEvent(field string, event *Event, problems *ValidationError) {
+	if !known[event.Type] {
+		problems.add(field+".type", "%q is not a known event type", event.Type)
+	}
+
+	account := event.Account
+	if account.Address == "" {
+		problems.add(field+".account.address", "is required")
+	}
+	if account.Owner == "" {
+		problems.add(field+".account.owner", "is required")
+	}
+	if account.Mint == "" && event.Type != WalletStale && event.Type != Signature {
+		problems.add(field+".account.mint", "is required")
+	}
+
+	if event.Type.IsStake() {
+		if event.Stake == nil {
+			problems.add(field+".stake", "is required for %s events", event.Type)
+		} else if event.Stake.Account.Address == "" {
+			problems.add(field+".stake.account.address", "is required")
+		}
+	}
+	if event.Type.IsNFT() {
+		if event.NFT == nil {
+			problems.add(field+".nft", "is required for %s events", event.Type)
+		} else if event.NFT.ID == "" {
+			problems.add(field+".nft.id", "is required")
+		}
+	}
+	if event.Type == GroupThreshold {
+		switch {
+		case event.Group == nil:
+			problems.add(field+".group", "is required for %s events", event.Type)
+		case event.Group.Crossed != CrossedBelow && event.Group.Crossed != CrossedAbove:
+			problems.add(field+".group.crossed", "%q is not one of below, above", event.Group.Crossed)
+		}
+	}
+	if event.Type == WalletStale && event.Stale == nil {
+		problems.add(field+".stale", "is required for %s events", event.Type)
+	}
+	if event.Type == Signature {
+		if event.Signature == nil {
+			problems.add(field+".signature", "is required for %s events", event.Type)
+		} else if event.Signature.Signature == "" {
+			problems.add(field+".signature.signature", "is required")
+		}
+	}
+	if event.Type == TokenBurned && event.Burn == nil {
+		problems.add(field+".burn", "is required for %s events", event.Type)
+	}
+	if event.Type == AuthorityChanged {
+		switch {
+		case event.Authority == nil:
+			problems.add(field+".authority", "is required for %s events", event.Type)
+		case event.Authority.Kind != AuthorityOwner && event.Authority.Kind != AuthorityClose && event.Authority.Kind != AuthorityProgram:
+			problems.add(field+".authority.kind", "%q is not one of owner, close_authority, program", event.Authority.Kind)
+		}
+	}
+	switch event.Severity {
+	case "", SeverityInfo, SeverityWarn, SeverityAnomaly, SeverityCritical:
+	default:
+		problems.add(field+".severity", "%q is not one of info, warn, anomaly, critical", event.Severity)
+	}
+	if event.Severity == SeverityAnomaly && (event.Anomaly == nil || len(event.Anomaly.Reasons) == 0) {
+		problems.add(field+".anomaly.reasons", "is required for anomaly events")
+	}
+	if event.Risk != nil {
+		if event.Risk.Score < 0 || event.Risk.Score > 100 {
+			problems.add(field+".risk.score", "%d is outside 0-100", event.Risk.Score)
+		}
+		if share := event.Risk.TopHoldersShare; share != nil && (*share < 0 || *share > 100) {
+			problems.add(field+".risk.top_holders_share", "%g is outside 0-100", *share)
+		}
+		if impact := event.Risk.PriceImpact; impact != nil && *impact < 0 {
+			problems.add(field+".risk.price_impact", "%g is negative", *impact)
+		}
+	}
+	if event.WalletRisk != nil && (event.WalletRisk.Score < 0 || event.WalletRisk.Score > 100) {
+		problems.add(field+".wallet_risk.score", "%d is outside 0-100", event.WalletRisk.Score)
+	}
+}
+
+// validateHealth checks a health report
+func validateHealth(health *Health, problems *ValidationError) {
+	switch health.Status {
+	case HealthOK, HealthDegraded, HealthRecovered:
+	default:
+		problems.add("health.status", "%q is not one of ok, degraded, recovered", health.Status)
+	}
+	if health.Status == HealthDegraded && len(health.Problems) == 0 {
+		problems.add("health.problems", "is required for degraded reports")
+	}
+}
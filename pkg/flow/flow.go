@@ -0,0 +1,274 @@
+package flow
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+)
+
+// Periods for Summarize
+const (
+	ByDay = "day"
+	// ByWeek groups by ISO week, starting on Monday
+	ByWeek = "week"
+	// ByTotal sums all changes
+	ByTotal = "total"
+)
+
+// pageSize is how many stored events Load reads at a time
+const pageSize = 1000
+
+// Flow is what moved in and out of the token accounts of a mint in a
+// period. Amounts are in base units of the mint; sums of u64 balances can
+// exceed a u64, so they are big integers, encoded as JSON numbers.
+type Flow struct {
+	// Wallet is empty for the flows of a mint over every wallet
+	Wallet string `json:"wallet,omitempty"`
+	Mint   string `json:"mint"`
+	Symbol string `json:"symbol,omitempty"`
+	// Period is the UTC day, the ISO week such as 2024-W05, or empty for
+	// totals
+	Period   string   `json:"period,omitempty"`
+	Decimals uint8    `json:"decimals"`
+	Inflow   *big.Int `json:"inflow"`
+	Outflow  *big.Int `json:"outflow"`
+	// Net is Inflow minus Outflow
+	Net *big.Int `json:"net"`
+	// Changes counts the balance changes behind the flow
+	Changes int `json:"changes"`
+}
+
+// Report holds the flows per wallet and mint and per mint over every wallet,
+// each ordered by period
+type Report struct {
+	By string `json:"by"`
+	// Period is set on reports of a single period, such as scheduled ones
+	Period  string `json:"period,omitempty"`
+	Wallets []Flow `json:"wallets"`
+	Mints   []Flow `json:"mints"`
+}
+
+// Load reads the stored events of the given wallets, or of every wallet if
+// wallets is nil, that happened from from until before to, as eventTime
+// dates them, in the order they were stored. A zero to reads up to the
+// latest event. Events are stored after they happen, so reading starts at
+// the first event stored at from, which the store looks up, rather than at
+// the start of the log.
+func Load(ctx context.Context, events store.EventStore, from, to time.Time, wallets []string) ([]store.StoredEvent, error) {
+	var result []store.StoredEvent
+	var after int64
+	if !from.IsZero() {
+		cursor, err := events.EventCursorAt(ctx, from)
+		if err != nil {
+			return nil, err
+		}
+		after = cursor
+	}
+	for {
+		page, err := events.EventsAfter(ctx, after, pageSize, wallets)
+		if err != nil {
+			return nil, err
+		}
+		for _, stored := range page {
+			var event monitor.Event
+			if err := json.Unmarshal(stored.Event, &event); err != nil {
+				return nil, fmt.Errorf("failed to decode event %d: %w", stored.ID, err)
+			}
+			at := eventTime(stored, event)
+			if !at.Before(from) && (to.IsZero() || at.Before(to)) {
+				result = append(result, stored)
+			}
+		}
+		if len(page) < pageSize {
+			return result, nil
+		}
+		after = page[len(page)-1].ID
+	}
+}
+
+// Summarize sums the balance changes of stored events by wallet, mint and
+// the period they happened in, as eventTime dates them. Balance changes,
+// burns and closed accounts count from their previous balance and new
+// holdings from zero; changes whose previous balance isn't known, such as
+// the balances loaded when the tracker starts, and other events are left
+// out.
+func Summarize(events []store.StoredEvent, by string) (Report, error) {
+	period, ok := periodFuncs[by]
+	if !ok {
+		return Report{}, fmt.Errorf("unknown period %q: use %s, %s or %s", by, ByDay, ByWeek, ByTotal)
+	}
+
+	wallets := make(map[[3]string]*Flow)
+	mints := make(map[[3]string]*Flow)
+	add := func(flows map[[3]string]*Flow, wallet string, event monitor.Event, at time.Time, amount uint64, inflow bool) {
+		key := [3]string{wallet, event.Account.Mint, period(at)}
+		flow, ok := flows[key]
+		if !ok {
+			flow = &Flow{
+				Wallet: wallet, Mint: key[1], Period: key[2], Decimals: event.Account.Decimals,
+				Inflow: new(big.Int), Outflow: new(big.Int), Net: new(big.Int),
+			}
+			flows[key] = flow
+		}
+		if event.Metadata != nil && event.Metadata.Symbol != "" {
+			flow.Symbol = event.Metadata.Symbol
+		}
+		delta := new(big.Int).SetUint64(amount)
+		if inflow {
+			flow.Inflow.Add(flow.Inflow, delta)
+			flow.Net.Add(flow.Net, delta)
+		} else {
+			flow.Outflow.Add(flow.Outflow, delta)
+			flow.Net.Sub(flow.Net, delta)
+		}
+		flow.Changes++
+	}
+
+	for _, stored := range events {
+		var event monitor.Event
+		if err := json.Unmarshal(stored.Event, &event); err != nil {
+			return Report{}, fmt.Errorf("failed to decode event %d: %w", stored.ID, err)
+		}
+		amount, inflow, ok := change(event)
+		if !ok {
+			continue
+		}
+		at := eventTime(stored, event)
+		add(wallets, event.Account.Owner, event, at, amount, inflow)
+		add(mints, "", event, at, amount, inflow)
+	}
+	return Report{By: by, Wallets: sorted(wallets), Mints: sorted(mints)}, nil
+}
+
+// eventTime dates an event by the block time of its transaction when it was
+// resolved, else by when its change was observed, which replays keep and
+// backfills set to the block time, and else by when it was stored
+func eventTime(stored store.StoredEvent, event monitor.Event) time.Time {
+	switch {
+	case event.Transfer != nil && !event.Transfer.BlockTime.IsZero():
+		return event.Transfer.BlockTime
+	case !event.Account.LastUpdatedAt.IsZero():
+		return event.Account.LastUpdatedAt
+	}
+	return stored.At
+}
+
+// change returns the size of the balance change of an event and whether
+// the balance grew, and false for events that don't change a known balance.
+// The size is taken on the unsigned balances, which may exceed an int64.
+func change(event monitor.Event) (amount uint64, inflow bool, ok bool) {
+	switch event.Type {
+	case monitor.EventBalanceChanged, monitor.EventNewHolding, monitor.EventTokenBurned, monitor.EventAccountClosed:
+	default:
+		return 0, false, false
+	}
+	var previous uint64
+	switch {
+	case event.Previous != nil:
+		previous = event.Previous.Balance
+	case event.Type != monitor.EventNewHolding:
+		return 0, false, false
+	}
+	balance := event.Account.Balance
+	switch {
+	case balance > previous:
+		return balance - previous, true, true
+	case balance < previous:
+		return previous - balance, false, true
+	}
+	return 0, false, false
+}
+
+// periodFuncs name the period of a time
+var periodFuncs = map[string]func(time.Time) string{
+	ByDay: func(t time.Time) string { return t.UTC().Format("2006-01-02") },
+	ByWeek: func(t time.Time) string {
+		year, week := t.UTC().ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", year, week)
+	},
+	ByTotal: func(time.Time) string { return "" },
+}
+
+// sorted returns flows ordered by wallet, mint and period
+func sorted(flows map[[3]string]*Flow) []Flow {
+	result := make([]Flow, 0, len(flows))
+	for _, flow := range flows {
+		result = append(result, *flow)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Wallet != b.Wallet {
+			return a.Wallet < b.Wallet
+		}
+		if a.Mint != b.Mint {
+			return a.Mint < b.Mint
+		}
+		return a.Period < b.Period
+	})
+	return result
+}
+
+// ForMint returns the report with the flows of one mint only
+func (r Report) ForMint(mint string) Report {
+	filter := func(flows []Flow) []Flow {
+		result := []Flow{}
+		for _, flow := range flows {
+			if flow.Mint == mint {
+				result = append(result, flow)
+			}
+		}
+		return result
+	}
+	r.Wallets = filter(r.Wallets)
+	r.Mints = filter(r.Mints)
+	return r
+}
+
+// WriteCSV writes the flows of a report as CSV, those of every wallet
+// after those of each wallet. Amounts are in UI units.
+func WriteCSV(w io.Writer, report Report) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"wallet", "mint", "symbol", "period", "inflow", "outflow", "net", "changes"})
+	for _, flow := range append(append([]Flow{}, report.Wallets...), report.Mints...) {
+		wallet := flow.Wallet
+		if wallet == "" {
+			wallet = "all"
+		}
+		period := flow.Period
+		if period == "" {
+			period = ByTotal
+		}
+		writer.Write([]string{
+			wallet, flow.Mint, flow.Symbol, period,
+			solana.BigUIAmountString(flow.Inflow, flow.Decimals),
+			solana.BigUIAmountString(flow.Outflow, flow.Decimals),
+			FormatNet(flow.Net, flow.Decimals),
+			strconv.Itoa(flow.Changes),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// FormatAmount renders an inflow or outflow in UI units
+func FormatAmount(amount *big.Int, decimals uint8) string {
+	return solana.BigUIAmountString(amount, decimals)
+}
+
+// FormatNet renders a net flow in UI units with its sign
+func FormatNet(net *big.Int, decimals uint8) string {
+	if net.Sign() < 0 {
+		return "-" + solana.BigUIAmountString(new(big.Int).Neg(net), decimals)
+	}
+	return "+" + solana.BigUIAmountString(net, decimals)
+}
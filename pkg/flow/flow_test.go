@@ -0,0 +1,172 @@
+package flow
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+)
+
+func TestSummarize(t *testing.T) {
+	// 2024-12-02 is the Monday of ISO week 49
+	day := func(date int) time.Time { return time.Date(2024, 12, date, 12, 0, 0, 0, time.UTC) }
+	account := func(wallet, mint string, balance uint64) solana.TokenAccountInfo {
+		return solana.TokenAccountInfo{Address: wallet + mint, Owner: wallet, Mint: mint, Balance: balance, Decimals: 6}
+	}
+	changed := func(wallet, mint string, before, after uint64) monitor.Event {
+		previous := account(wallet, mint, before)
+		return monitor.Event{Type: monitor.EventBalanceChanged, Account: account(wallet, mint, after), Previous: &previous}
+	}
+	type stored struct {
+		at    time.Time
+		event monitor.Event
+	}
+	at := func(date int, event monitor.Event) stored { return stored{day(date), event} }
+
+	blockTimed := changed("w1", "m1", 0, 5)
+	blockTimed.Transfer = &solana.Transfer{BlockTime: day(1)}
+	observed := changed("w1", "m1", 0, 7)
+	observed.Account.LastUpdatedAt = day(1)
+
+	tests := []struct {
+		name    string
+		by      string
+		events  []stored
+		wallets []string
+		mints   []string
+	}{
+		{"inflow and outflow", ByTotal, []stored{
+			at(2, changed("w1", "m1", 100, 150)),
+			at(2, changed("w1", "m1", 150, 120)),
+		}, []string{"w1 m1  in 50 out 30 net 20 changes 2"}, []string{" m1  in 50 out 30 net 20 changes 2"}},
+		{"new holdings from zero", ByTotal, []stored{
+			at(2, monitor.Event{Type: monitor.EventNewHolding, Account: account("w1", "m1", 40)}),
+		}, []string{"w1 m1  in 40 out 0 net 40 changes 1"}, []string{" m1  in 40 out 0 net 40 changes 1"}},
+		{"burns and closed accounts", ByTotal, []stored{
+			at(2, withType(changed("w1", "m1", 100, 60), monitor.EventTokenBurned)),
+			at(2, withType(changed("w1", "m1", 60, 0), monitor.EventAccountClosed)),
+		}, []string{"w1 m1  in 0 out 100 net -100 changes 2"}, []string{" m1  in 0 out 100 net -100 changes 2"}},
+		{"unknown previous balances and other events", ByTotal, []stored{
+			at(2, monitor.Event{Type: monitor.EventBalanceChanged, Account: account("w1", "m1", 40)}),
+			at(2, withType(changed("w1", "m1", 0, 40), monitor.EventStakeReward)),
+			at(2, changed("w1", "m1", 40, 40)),
+		}, nil, nil},
+		{"wallets summed by mint", ByTotal, []stored{
+			at(2, changed("w1", "m1", 0, 10)),
+			at(2, changed("w2", "m1", 30, 0)),
+			at(2, changed("w2", "m2", 0, 1)),
+		}, []string{
+			"w1 m1  in 10 out 0 net 10 changes 1",
+			"w2 m1  in 0 out 30 net -30 changes 1",
+			"w2 m2  in 1 out 0 net 1 changes 1",
+		}, []string{
+			" m1  in 10 out 30 net -20 changes 2",
+			" m2  in 1 out 0 net 1 changes 1",
+		}},
+		{"by day", ByDay, []stored{
+			at(2, changed("w1", "m1", 0, 10)),
+			at(3, changed("w1", "m1", 10, 4)),
+			at(3, changed("w1", "m1", 4, 5)),
+		}, []string{
+			"w1 m1 2024-12-02 in 10 out 0 net 10 changes 1",
+			"w1 m1 2024-12-03 in 1 out 6 net -5 changes 2",
+		}, []string{
+			" m1 2024-12-02 in 10 out 0 net 10 changes 1",
+			" m1 2024-12-03 in 1 out 6 net -5 changes 2",
+		}},
+		{"by week", ByWeek, []stored{
+			at(1, changed("w1", "m1", 0, 1)),
+			at(2, changed("w1", "m1", 1, 3)),
+			at(8, changed("w1", "m1", 3, 6)),
+		}, []string{
+			"w1 m1 2024-W48 in 1 out 0 net 1 changes 1",
+			"w1 m1 2024-W49 in 5 out 0 net 5 changes 2",
+		}, []string{
+			" m1 2024-W48 in 1 out 0 net 1 changes 1",
+			" m1 2024-W49 in 5 out 0 net 5 changes 2",
+		}},
+		{"beyond 64 bits", ByTotal, []stored{
+			at(2, changed("w1", "m1", 0, math.MaxUint64)),
+			at(2, changed("w1", "m1", 0, math.MaxUint64)),
+			at(2, changed("w2", "m1", math.MaxUint64, 0)),
+		}, []string{
+			"w1 m1  in 36893488147419103230 out 0 net 36893488147419103230 changes 2",
+			"w2 m1  in 0 out 18446744073709551615 net -18446744073709551615 changes 1",
+		}, []string{
+			" m1  in 36893488147419103230 out 18446744073709551615 net 18446744073709551615 changes 3",
+		}},
+		{"dated by block time, then by observation", ByDay, []stored{
+			at(3, blockTimed),
+			at(3, observed),
+		}, []string{"w1 m1 2024-12-01 in 12 out 0 net 12 changes 2"}, []string{" m1 2024-12-01 in 12 out 0 net 12 changes 2"}},
+	}
+	for _, tt := range tests {
+		var events []store.StoredEvent
+		for i, e := range tt.events {
+			data, err := json.Marshal(e.event)
+			if err != nil {
+				t.Fatalf("%s: Marshal: %v", tt.name, err)
+			}
+			events = append(events, store.StoredEvent{ID: int64(i + 1), Wallet: e.event.Account.Owner, At: e.at, Event: data})
+		}
+		report, err := Summarize(events, tt.by)
+		if err != nil {
+			t.Fatalf("%s: Summarize: %v", tt.name, err)
+		}
+		if got := describe(report.Wallets); !reflect.DeepEqual(got, tt.wallets) {
+			t.Errorf("%s: wallets\ngot  %q\nwant %q", tt.name, got, tt.wallets)
+		}
+		if got := describe(report.Mints); !reflect.DeepEqual(got, tt.mints) {
+			t.Errorf("%s: mints\ngot  %q\nwant %q", tt.name, got, tt.mints)
+		}
+	}
+}
+
+func TestSummarizeErrors(t *testing.T) {
+	if _, err := Summarize(nil, "month"); err == nil {
+		t.Errorf("unknown period succeeded")
+	}
+	events := []store.StoredEvent{{ID: 1, Event: json.RawMessage(`{"type":`)}}
+	if _, err := Summarize(events, ByTotal); err == nil {
+		t.Errorf("undecodable event succeeded")
+	}
+}
+
+func TestFormatNet(t *testing.T) {
+	tests := []struct {
+		net  int64
+		want string
+	}{
+		{1500000, "+1.5"},
+		{-1500000, "-1.5"},
+		{0, "+0"},
+	}
+	for _, tt := range tests {
+		if got := FormatNet(big.NewInt(tt.net), 6); got != tt.want {
+			t.Errorf("FormatNet(%d) = %q, want %q", tt.net, got, tt.want)
+		}
+	}
+}
+
+// withType changes the type of an event
+func withType(event monitor.Event, eventType monitor.EventType) monitor.Event {
+	event.Type = eventType
+	return event
+}
+
+// describe renders flows for comparison, nil without any
+func describe(flows []Flow) []string {
+	var result []string
+	for _, flow := range flows {
+		result = append(result, fmt.Sprintf("%s %s %s in %s out %s net %s changes %d",
+			flow.Wallet, flow.Mint, flow.Period, flow.Inflow, flow.Outflow, flow.Net, flow.Changes))
+	}
+	return result
+}
@@ -0,0 +1,98 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+)
+
+// Scheduler reports the flows of every day or week once it ends
+type Scheduler struct {
+	events   store.EventStore
+	by       string
+	interval time.Duration
+	offset   time.Duration
+	send     func(Report)
+	log      logging.Logger
+}
+
+// NewScheduler creates a scheduler that summarizes the stored events of
+// each UTC day, or of each week from Monday, by ByDay or ByWeek, and passes
+// the report to send offset after the period ends. Periods without flows
+// aren't reported. It logs through log, or the default logger when nil.
+func NewScheduler(events store.EventStore, by string, offset time.Duration, send func(Report), log logging.Logger) (*Scheduler, error) {
+	var interval time.Duration
+	switch by {
+	case ByDay:
+		interval = 24 * time.Hour
+	case ByWeek:
+		// The zero time is a Monday, so weeks truncate to Mondays
+		interval = 7 * 24 * time.Hour
+	default:
+		return nil, fmt.Errorf("unknown report period %q: use %s or %s", by, ByDay, ByWeek)
+	}
+	if log == nil {
+		log = logging.Default()
+	}
+	return &Scheduler{
+		events:   events,
+		by:       by,
+		interval: interval,
+		offset:   offset % interval,
+		send:     send,
+		log:      log,
+	}, nil
+}
+
+// Run sends reports until ctx is cancelled. Reports missed while the
+// tracker was down are not made up for.
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		next := s.next(time.Now())
+		s.log.Debug("Next flow report scheduled", "at", next)
+
+		select {
+		case <-time.After(time.Until(next)):
+		case <-ctx.Done():
+			return
+		}
+
+		end := next.Add(-s.offset)
+		report, err := s.report(ctx, end.Add(-s.interval), end)
+		if err != nil {
+			s.log.Error("Failed to build flow report", "error", err)
+			continue
+		}
+		if len(report.Mints) == 0 {
+			s.log.Debug("No flows to report", "period", report.Period)
+			continue
+		}
+		s.send(report)
+	}
+}
+
+// report summarizes the events of a period
+func (s *Scheduler) report(ctx context.Context, from, to time.Time) (Report, error) {
+	events, err := Load(ctx, s.events, from, to, nil)
+	if err != nil {
+		return Report{}, err
+	}
+	report, err := Summarize(events, s.by)
+	if err != nil {
+		return Report{}, err
+	}
+	report.Period = periodFuncs[s.by](from)
+	return report, nil
+}
+
+// next returns the first scheduled time after now
+func (s *Scheduler) next(now time.Time) time.Time {
+	next := now.UTC().Add(-s.offset).Truncate(s.interval).Add(s.offset)
+	for !next.After(now) {
+		next = next.Add(s.interval)
+	}
+	return next
+}
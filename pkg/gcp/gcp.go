@@ -0,0 +1,211 @@
+// Package gcp gets OAuth access tokens for Google Cloud APIs, without the
+// Google Cloud SDK.
+package gcp
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scope grants access to every Google Cloud API the IAM roles allow
+const Scope = "https://www.googleapis.com/auth/cloud-platform"
+
+// metadataToken is the token endpoint of the metadata server
+const metadataToken = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// refreshBefore is how long before they expire tokens are fetched again
+const refreshBefore = 5 * time.Minute
+
+// TokenSource gets access tokens from, in order, GOOGLE_OAUTH_ACCESS_TOKEN,
+// the service account key file of CredentialsFile or
+// GOOGLE_APPLICATION_CREDENTIALS, and the service account of the metadata
+// server of GCE, GKE and Cloud Run. Tokens are cached until shortly before
+// they expire.
+type TokenSource struct {
+	// CredentialsFile is a service account key file used in place of
+	// GOOGLE_APPLICATION_CREDENTIALS
+	CredentialsFile string
+	// Env looks up environment variables, os.LookupEnv when nil
+	Env func(string) (string, bool)
+	// ReadFile reads the key file, os.ReadFile when nil
+	ReadFile func(string) ([]byte, error)
+	// HTTPClient gets the tokens, a client with a 10s timeout when nil
+	HTTPClient *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// Token returns an access token
+func (t *TokenSource) Token(ctx context.Context) (string, error) {
+	if token := t.lookupEnv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.token != "" && time.Until(t.expires) > refreshBefore {
+		return t.token, nil
+	}
+
+	credentials := t.CredentialsFile
+	if credentials == "" {
+		credentials = t.lookupEnv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	var res tokenResponse
+	var err error
+	if credentials != "" {
+		res, err = t.serviceAccountToken(ctx, credentials)
+	} else {
+		res, err = t.metadataToken(ctx)
+	}
+	if err != nil {
+		return "", err
+	}
+	t.token = res.AccessToken
+	t.expires = time.Now().Add(time.Duration(res.ExpiresIn) * time.Second)
+	return t.token, nil
+}
+
+// tokenResponse is the token of the OAuth token endpoint and the metadata
+// server
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	// ExpiresIn is the lifetime of the token in seconds
+	ExpiresIn int `json:"expires_in"`
+}
+
+// metadataToken gets a token of the service account of the metadata server
+func (t *TokenSource) metadataToken(ctx context.Context) (tokenResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataToken, nil)
+	if err != nil {
+		return tokenResponse{}, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	var res tokenResponse
+	if err := t.do(req, &res); err != nil {
+		return tokenResponse{}, fmt.Errorf("no credentials in the environment and the metadata server failed: %w", err)
+	}
+	return res, nil
+}
+
+// serviceAccountToken exchanges a JWT signed with the key of a service
+// account key file for a token
+func (t *TokenSource) serviceAccountToken(ctx context.Context, path string) (tokenResponse, error) {
+	readFile := t.ReadFile
+	if readFile == nil {
+		readFile = os.ReadFile
+	}
+	data, err := readFile(path)
+	if err != nil {
+		return tokenResponse{}, fmt.Errorf("failed to read credentials: %w", err)
+	}
+	var account struct {
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(data, &account); err != nil {
+		return tokenResponse{}, fmt.Errorf("failed to parse credentials: %w", err)
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" {
+		return tokenResponse{}, errors.New("credentials aren't a service account key")
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	block, _ := pem.Decode([]byte(account.PrivateKey))
+	if block == nil {
+		return tokenResponse{}, errors.New("invalid private key in credentials")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return tokenResponse{}, fmt.Errorf("invalid private key in credentials: %w", err)
+	}
+	privateKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return tokenResponse{}, errors.New("private key in credentials isn't an RSA key")
+	}
+
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   account.ClientEmail,
+		"scope": Scope,
+		"aud":   account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return tokenResponse{}, fmt.Errorf("failed to sign token request: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return tokenResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var res tokenResponse
+	if err := t.do(req, &res); err != nil {
+		return tokenResponse{}, fmt.Errorf("failed to get access token: %w", err)
+	}
+	return res, nil
+}
+
+// lookupEnv looks up an environment variable, reporting unset and empty
+// ones alike
+func (t *TokenSource) lookupEnv(name string) string {
+	lookup := t.Env
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	value, _ := lookup(name)
+	return value
+}
+
+// do sends a request and decodes its JSON response
+func (t *TokenSource) do(req *http.Request, out interface{}) error {
+	client := t.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", res.Status, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,398 @@
+// Package geyser streams account and transaction updates from a Yellowstone
+// gRPC endpoint, the Geyser plugin served by dedicated Solana nodes. It
+// implements the single Subscribe call the tracker needs over HTTP/2, with
+// its protobuf messages encoded by hand, so only TLS endpoints are supported.
+package geyser
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// subscribePath is the gRPC method of the Subscribe call
+const subscribePath = "/geyser.Geyser/Subscribe"
+
+// maxMessageSize bounds the updates read from a stream
+const maxMessageSize = 64 << 20
+
+// Commitment levels of a subscription
+const (
+	CommitmentProcessed = "processed"
+	CommitmentConfirmed = "confirmed"
+	CommitmentFinalized = "finalized"
+)
+
+// gRPC status codes that retrying can't fix
+const (
+	statusPermissionDenied = "7"
+	statusUnauthenticated  = "16"
+)
+
+// ErrUnauthenticated is returned when the endpoint rejects the token
+var ErrUnauthenticated = errors.New("geyser endpoint rejected the token")
+
+// Client subscribes to a Yellowstone gRPC endpoint
+type Client struct {
+	endpoint   string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient creates a client of an https endpoint. The token is sent as the
+// x-token header, and may be empty for endpoints that don't require one.
+func NewClient(endpoint, token string) (*Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid geyser endpoint %q: %w", endpoint, err)
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("geyser endpoint %q must be an https URL", endpoint)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ForceAttemptHTTP2 = true
+	return &Client{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		token:      token,
+		httpClient: &http.Client{Transport: transport},
+	}, nil
+}
+
+// SubscribeRequest selects the updates of a subscription. Filters are named;
+// updates carry the names of the filters they matched.
+type SubscribeRequest struct {
+	Accounts     map[string]AccountFilter
+	Transactions map[string]TransactionFilter
+	// Commitment is one of the commitment levels, confirmed when empty
+	Commitment string
+}
+
+// AccountFilter matches accounts by address, or by owner program and the
+// layout of their data
+type AccountFilter struct {
+	Accounts []string
+	Owners   []string
+	// DataSize, when set, only matches accounts with that much data
+	DataSize uint64
+	Memcmps  []Memcmp
+}
+
+// Memcmp matches accounts whose data holds the base58 decoded Base58 at
+// Offset
+type Memcmp struct {
+	Offset uint64
+	Base58 string
+}
+
+// TransactionFilter matches the transactions mentioning any of AccountInclude.
+// Vote transactions are excluded.
+type TransactionFilter struct {
+	AccountInclude []string
+}
+
+// Update is an update of a subscription
+type Update struct {
+	// Filters are the names of the filters the update matched
+	Filters     []string
+	Account     *AccountUpdate
+	Transaction *TransactionUpdate
+}
+
+// AccountUpdate is a write of an account
+type AccountUpdate struct {
+	Pubkey   string
+	Owner    string
+	Lamports uint64
+	Data     []byte
+	Slot     uint64
+	// Signature is the transaction that wrote the account, if any
+	Signature string
+}
+
+// TransactionUpdate is a transaction matching a filter
+type TransactionUpdate struct {
+	Signature string
+	Slot      uint64
+	Failed    bool
+}
+
+// Subscribe streams the updates selected by request to callback until ctx
+// is cancelled, which returns nil, or the stream fails
+func (c *Client) Subscribe(ctx context.Context, request SubscribeRequest, callback func(Update)) error {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+subscribePath, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create geyser request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	if c.token != "" {
+		req.Header.Set("x-token", c.token)
+	}
+
+	// The request body stays open to answer the pings of the endpoint, which
+	// closes streams that don't
+	var writeMutex sync.Mutex
+	send := func(message []byte) error {
+		writeMutex.Lock()
+		defer writeMutex.Unlock()
+		return writeFrame(writer, message)
+	}
+	go func() {
+		if err := send(request.encode()); err != nil {
+			writer.CloseWithError(err)
+		}
+	}()
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("failed to subscribe to geyser: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("geyser subscription failed: %s", resp.Status)
+	}
+	// Errors before the first message come without a body
+	if err := statusError(resp.Header); err != nil {
+		return err
+	}
+
+	for {
+		message, err := readFrame(resp.Body)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err == io.EOF {
+			if err := statusError(resp.Trailer); err != nil {
+				return err
+			}
+			return errors.New("geyser stream ended")
+		}
+		if err != nil {
+			return fmt.Errorf("geyser stream lost: %w", err)
+		}
+
+		update, ping, err := decodeUpdate(message)
+		if err != nil {
+			return fmt.Errorf("failed to decode geyser update: %w", err)
+		}
+		if ping {
+			if err := send(encodePing()); err != nil {
+				return fmt.Errorf("failed to answer geyser ping: %w", err)
+			}
+			continue
+		}
+		if update.Account != nil || update.Transaction != nil {
+			callback(update)
+		}
+	}
+}
+
+// statusError returns the error of a gRPC status in headers or trailers
+func statusError(header http.Header) error {
+	status := header.Get("grpc-status")
+	if status == "" || status == "0" {
+		return nil
+	}
+	message, err := url.PathUnescape(header.Get("grpc-message"))
+	if err != nil {
+		message = header.Get("grpc-message")
+	}
+
+	if status == statusUnauthenticated || status == statusPermissionDenied {
+		return fmt.Errorf("%w: %s", ErrUnauthenticated, message)
+	}
+	return fmt.Errorf("geyser stream failed with status %s: %s", status, message)
+}
+
+// writeFrame writes a message as an uncompressed gRPC frame
+func writeFrame(w io.Writer, message []byte) error {
+	frame := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+	_, err := w.Write(append(frame, message...))
+	return err
+}
+
+// readFrame reads the message of a gRPC frame
+func readFrame(r io.Reader) ([]byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	if header[0] != 0 {
+		return nil, errors.New("compressed messages aren't supported")
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > maxMessageSize {
+		return nil, fmt.Errorf("message of %d bytes is too large", size)
+	}
+
+	message := make([]byte, size)
+	if _, err := io.ReadFull(r, message); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return message, nil
+}
+
+// encode encodes a SubscribeRequest
+func (r SubscribeRequest) encode() []byte {
+	var e encoder
+	for name, filter := range r.Accounts {
+		filter := filter
+		e.mapEntry(1, name, func(f *encoder) {
+			for _, account := range filter.Accounts {
+				f.string(2, account)
+			}
+			for _, owner := range filter.Owners {
+				f.string(3, owner)
+			}
+			for _, memcmp := range filter.Memcmps {
+				memcmp := memcmp
+				f.message(4, func(inner *encoder) {
+					inner.message(1, func(m *encoder) {
+						m.varint(1, memcmp.Offset)
+						m.string(3, memcmp.Base58)
+					})
+				})
+			}
+			if filter.DataSize > 0 {
+				f.message(4, func(inner *encoder) {
+					inner.varint(2, filter.DataSize)
+				})
+			}
+		})
+	}
+	for name, filter := range r.Transactions {
+		filter := filter
+		e.mapEntry(3, name, func(f *encoder) {
+			f.bool(1, false)
+			for _, account := range filter.AccountInclude {
+				f.string(3, account)
+			}
+		})
+	}
+
+	// The commitment is optional, so confirmed is sent explicitly
+	var commitment uint64 = 1
+	switch r.Commitment {
+	case CommitmentProcessed:
+		commitment = 0
+	case CommitmentFinalized:
+		commitment = 2
+	}
+	e.varint(6, commitment)
+	return e.buf
+}
+
+// encodePing encodes a SubscribeRequest answering a ping, which leaves the
+// filters of the stream as they are
+func encodePing() []byte {
+	var e encoder
+	e.message(9, func(ping *encoder) {
+		ping.varint(1, 1)
+	})
+	return e.buf
+}
+
+// decodeUpdate decodes a SubscribeUpdate. Pings are reported apart; other
+// kinds of updates leave both Account and Transaction nil.
+func decodeUpdate(message []byte) (update Update, ping bool, err error) {
+	err = decodeFields(message, func(field int, _ uint64, data []byte) error {
+		switch field {
+		case 1:
+			update.Filters = append(update.Filters, string(data))
+		case 2:
+			account, err := decodeAccount(data)
+			if err != nil {
+				return err
+			}
+			update.Account = account
+		case 4:
+			transaction, err := decodeTransaction(data)
+			if err != nil {
+				return err
+			}
+			update.Transaction = transaction
+		case 6:
+			ping = true
+		}
+		return nil
+	})
+	return update, ping, err
+}
+
+// decodeAccount decodes a SubscribeUpdateAccount
+func decodeAccount(message []byte) (*AccountUpdate, error) {
+	var account AccountUpdate
+	err := decodeFields(message, func(field int, number uint64, data []byte) error {
+		switch field {
+		case 1:
+			return decodeFields(data, func(field int, number uint64, data []byte) error {
+				switch field {
+				case 1:
+					account.Pubkey = solanago.PublicKeyFromBytes(data).String()
+				case 2:
+					account.Lamports = number
+				case 3:
+					account.Owner = solanago.PublicKeyFromBytes(data).String()
+				case 6:
+					account.Data = data
+				case 8:
+					account.Signature = solanago.SignatureFromBytes(data).String()
+				}
+				return nil
+			})
+		case 2:
+			account.Slot = number
+		}
+		return nil
+	})
+	return &account, err
+}
+
+// decodeTransaction decodes a SubscribeUpdateTransaction. Transactions
+// whose status meta has an error failed.
+func decodeTransaction(message []byte) (*TransactionUpdate, error) {
+	var transaction TransactionUpdate
+	err := decodeFields(message, func(field int, number uint64, data []byte) error {
+		switch field {
+		case 1:
+			return decodeFields(data, func(field int, _ uint64, data []byte) error {
+				switch field {
+				case 1:
+					transaction.Signature = solanago.SignatureFromBytes(data).String()
+				case 4:
+					return decodeFields(data, func(field int, _ uint64, _ []byte) error {
+						if field == 1 {
+							transaction.Failed = true
+						}
+						return nil
+					})
+				}
+				return nil
+			})
+		case 2:
+			transaction.Slot = number
+		}
+		return nil
+	})
+	return &transaction, err
+}
@@ -0,0 +1,119 @@
+package geyser
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// errTruncated is returned for messages that end inside a field
+var errTruncated = errors.New("truncated protobuf message")
+
+// encoder appends protobuf fields to a message
+type encoder struct {
+	buf []byte
+}
+
+// tag appends the key of a field
+func (e *encoder) tag(field, wire int) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(field<<3|wire))
+}
+
+// varint appends an integer field
+func (e *encoder) varint(field int, value uint64) {
+	e.tag(field, wireVarint)
+	e.buf = binary.AppendUvarint(e.buf, value)
+}
+
+// bool appends a boolean field
+func (e *encoder) bool(field int, value bool) {
+	var v uint64
+	if value {
+		v = 1
+	}
+	e.varint(field, v)
+}
+
+// bytes appends a bytes field
+func (e *encoder) bytes(field int, value []byte) {
+	e.tag(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(value)))
+	e.buf = append(e.buf, value...)
+}
+
+// string appends a string field
+func (e *encoder) string(field int, value string) {
+	e.bytes(field, []byte(value))
+}
+
+// message appends an embedded message field
+func (e *encoder) message(field int, encode func(*encoder)) {
+	var inner encoder
+	encode(&inner)
+	e.bytes(field, inner.buf)
+}
+
+// mapEntry appends an entry of a map<string, message> field
+func (e *encoder) mapEntry(field int, key string, encode func(*encoder)) {
+	e.message(field, func(entry *encoder) {
+		entry.string(1, key)
+		entry.message(2, encode)
+	})
+}
+
+// decodeFields calls visit with each field of a message, passing the value
+// of varint fields as number and of length-delimited fields as data. Fixed
+// width fields are skipped.
+func decodeFields(message []byte, visit func(field int, number uint64, data []byte) error) error {
+	for len(message) > 0 {
+		key, n := binary.Uvarint(message)
+		if n <= 0 {
+			return errTruncated
+		}
+		message = message[n:]
+		field := int(key >> 3)
+
+		switch key & 7 {
+		case wireVarint:
+			value, n := binary.Uvarint(message)
+			if n <= 0 {
+				return errTruncated
+			}
+			message = message[n:]
+			if err := visit(field, value, nil); err != nil {
+				return err
+			}
+		case wireBytes:
+			length, n := binary.Uvarint(message)
+			if n <= 0 || uint64(len(message)-n) < length {
+				return errTruncated
+			}
+			data := message[n : n+int(length)]
+			message = message[n+int(length):]
+			if err := visit(field, 0, data); err != nil {
+				return err
+			}
+		case wireFixed64:
+			if len(message) < 8 {
+				return errTruncated
+			}
+			message = message[8:]
+		case wireFixed32:
+			if len(message) < 4 {
+				return errTruncated
+			}
+			message = message[4:]
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", key&7)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package geyser
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// Names of the filters of a wallet stream
+const (
+	filterTokens = "tokens"
+	filterWallet = "wallet"
+)
+
+// Layout of SPL token accounts, which hold their owner after the mint
+const (
+	tokenAccountSize = 165
+	tokenOwnerOffset = 32
+)
+
+// lamportsDecimals is the number of decimals of SOL
+const lamportsDecimals = 9
+
+var _ solana.DataSource = (*Source)(nil)
+
+// Source is a solana.DataSource streaming the token accounts of wallets, and
+// their lamports, from a Yellowstone endpoint. Lookups go to the RPC client,
+// which also decodes the streamed accounts with its mint cache.
+type Source struct {
+	client *Client
+	rpc    *solana.Client
+}
+
+// NewSource creates a source streaming from client, with the commitments
+// of the RPC client
+func NewSource(client *Client, rpc *solana.Client) *Source {
+	return &Source{client: client, rpc: rpc}
+}
+
+// GetTokenAccounts looks up the token accounts of a wallet over RPC
+func (s *Source) GetTokenAccounts(ctx context.Context, wallet string) ([]solana.TokenAccountInfo, error) {
+	return s.rpc.GetTokenAccounts(ctx, wallet)
+}
+
+// GetTransactions looks up the latest signatures of an address over RPC
+func (s *Source) GetTransactions(ctx context.Context, address string, limit int) ([]solana.SignatureInfo, error) {
+	return s.rpc.GetRecentSignatures(ctx, address, limit)
+}
+
+// Subscribe streams the token accounts owned by a wallet, and the wallet
+// account itself, which is reported as its SOL holding
+func (s *Source) Subscribe(ctx context.Context, wallet string, callback func(solana.TokenAccountInfo)) error {
+	request := SubscribeRequest{
+		Accounts: map[string]AccountFilter{
+			filterTokens: {
+				Owners:   []string{solana.TokenProgramID},
+				DataSize: tokenAccountSize,
+				Memcmps:  []Memcmp{{Offset: tokenOwnerOffset, Base58: wallet}},
+			},
+			filterWallet: {Accounts: []string{wallet}},
+		},
+		Commitment: string(s.rpc.Commitments.Subscriptions),
+	}
+
+	return s.client.Subscribe(ctx, request, func(update Update) {
+		account := update.Account
+		if account == nil {
+			return
+		}
+		if account.Pubkey == wallet {
+			callback(solana.TokenAccountInfo{
+				Address:       wallet,
+				Owner:         wallet,
+				Mint:          solana.NativeMint,
+				Balance:       account.Lamports,
+				Decimals:      lamportsDecimals,
+				ProgramID:     solana.SystemProgram,
+				Slot:          account.Slot,
+				Signature:     account.Signature,
+				LastUpdatedAt: time.Now(),
+				Source:        solana.SourceGeyser,
+			})
+			return
+		}
+
+		info, err := s.rpc.DecodeTokenAccount(ctx, account.Pubkey, account.Data)
+		if err != nil {
+			s.rpc.Log().Debug("Ignoring geyser update", "account", account.Pubkey, "error", err)
+			return
+		}
+		info.Slot = account.Slot
+		info.Signature = account.Signature
+		info.Source = solana.SourceGeyser
+		callback(*info)
+	})
+}
+
+// SubscribeToWalletLogs streams the transactions mentioning a wallet, the
+// way the logs subscription of the RPC client does
+func (s *Source) SubscribeToWalletLogs(ctx context.Context, wallet string, callback func(solana.LogNotification)) error {
+	request := SubscribeRequest{
+		Transactions: map[string]TransactionFilter{
+			filterWallet: {AccountInclude: []string{wallet}},
+		},
+		Commitment: string(s.rpc.Commitments.Transactions),
+	}
+
+	return s.client.Subscribe(ctx, request, func(update Update) {
+		tx := update.Transaction
+		if tx == nil {
+			return
+		}
+		callback(solana.LogNotification{
+			Signature: tx.Signature,
+			Slot:      tx.Slot,
+			Failed:    tx.Failed,
+		})
+	})
+}
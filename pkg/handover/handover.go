@@ -0,0 +1,242 @@
+// Package handover lets a new tracker process take over from a running one,
+// e.g. after a binary or configuration upgrade, without dropping API clients
+// or losing track of balances. It also supports systemd socket activation
+// and readiness notification.
+//
+// The running process starts the new one with the API listener and two
+// pipes. Once the new process is set up it reports ready; the running one
+// then stops monitoring and sends its token account state, which the new
+// process starts from, so changes made during the handover produce events.
+package handover
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// DefaultTimeout bounds how long the running process waits for the new one
+// to be set up, and the new one for the state
+const DefaultTimeout = 2 * time.Minute
+
+// Environment variables naming the descriptors passed to the new process
+const (
+	envListenFD = "TRACKER_LISTEN_FD"
+	envReadyFD  = "TRACKER_READY_FD"
+	envStateFD  = "TRACKER_STATE_FD"
+)
+
+// systemdListenFD is the first descriptor passed by socket activation
+const systemdListenFD = 3
+
+// State is what the running process hands over to the new one
+type State struct {
+	At       time.Time                 `json:"at"`
+	Accounts []solana.TokenAccountInfo `json:"accounts"`
+	// Networks holds the accounts of the further networks, by name
+	Networks map[string][]solana.TokenAccountInfo `json:"networks,omitempty"`
+}
+
+// Listener returns the listener passed by systemd socket activation or by
+// the previous process, or nil when there is none
+func Listener() (net.Listener, error) {
+	fd := -1
+	if value := os.Getenv(envListenFD); value != "" {
+		fd, _ = strconv.Atoi(value)
+	} else if os.Getenv("LISTEN_PID") == strconv.Itoa(os.Getpid()) {
+		if count, _ := strconv.Atoi(os.Getenv("LISTEN_FDS")); count > 0 {
+			fd = systemdListenFD
+		}
+	}
+	// Processes started later must not take the descriptor for theirs
+	for _, name := range []string{envListenFD, "LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+		os.Unsetenv(name)
+	}
+	if fd < 0 {
+		return nil, nil
+	}
+
+	file := os.NewFile(uintptr(fd), "listener")
+	defer file.Close()
+	listener, err := net.FileListener(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to use inherited listener: %w", err)
+	}
+	return listener, nil
+}
+
+// Successor is the end of a handover in the new process
+type Successor struct {
+	ready *os.File
+	state *os.File
+}
+
+// Inherited returns the handover to this process, or nil when it wasn't
+// started by Start
+func Inherited() *Successor {
+	readyFD, err := strconv.Atoi(os.Getenv(envReadyFD))
+	if err != nil {
+		return nil
+	}
+	stateFD, err := strconv.Atoi(os.Getenv(envStateFD))
+	if err != nil {
+		return nil
+	}
+	os.Unsetenv(envReadyFD)
+	os.Unsetenv(envStateFD)
+	return &Successor{
+		ready: os.NewFile(uintptr(readyFD), "handover-ready"),
+		state: os.NewFile(uintptr(stateFD), "handover-state"),
+	}
+}
+
+// TakeOver tells the previous process that this one is set up and returns
+// the state it leaves once it stopped monitoring. Call it right before
+// monitoring starts: the previous process keeps running if this one fails
+// earlier. systemd is told this process is the main one from now on.
+func (s *Successor) TakeOver(timeout time.Duration) (*State, error) {
+	defer s.state.Close()
+
+	if err := Notify(fmt.Sprintf("MAINPID=%d", os.Getpid())); err != nil {
+		return nil, err
+	}
+	_, err := s.ready.Write([]byte{1})
+	s.ready.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to report ready to the previous process: %w", err)
+	}
+
+	received := make(chan error, 1)
+	var state State
+	go func() {
+		received <- json.NewDecoder(s.state).Decode(&state)
+	}()
+	select {
+	case err := <-received:
+		if err != nil {
+			return nil, fmt.Errorf("failed to receive state from the previous process: %w", err)
+		}
+		return &state, nil
+	case <-time.After(timeout):
+		return nil, errors.New("timed out waiting for the state of the previous process")
+	}
+}
+
+// Start launches a new process of the same binary with the same arguments
+// and passes it the listener, if any. Once the new process is set up, stop
+// is called to stop monitoring and the state it returns is sent over. Until
+// then failures leave this process running as it was.
+func Start(listener net.Listener, stop func() State, timeout time.Duration) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the executable: %w", err)
+	}
+
+	readyRead, readyWrite, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer readyRead.Close()
+	stateRead, stateWrite, err := os.Pipe()
+	if err != nil {
+		readyWrite.Close()
+		return err
+	}
+	defer stateWrite.Close()
+
+	files := []*os.File{readyWrite, stateRead}
+	env := append(os.Environ(), envReadyFD+"=3", envStateFD+"=4")
+	if listener != nil {
+		file, err := listenerFile(listener)
+		if err != nil {
+			readyWrite.Close()
+			stateRead.Close()
+			return err
+		}
+		defer file.Close()
+		files = append(files, file)
+		env = append(env, envListenFD+"=5")
+	}
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = env
+	cmd.ExtraFiles = files
+	err = cmd.Start()
+	// The new process holds its own copies
+	readyWrite.Close()
+	stateRead.Close()
+	if err != nil {
+		return fmt.Errorf("failed to start the new process: %w", err)
+	}
+
+	ready := make(chan error, 1)
+	go func() {
+		_, err := readyRead.Read(make([]byte, 1))
+		ready <- err
+	}()
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-ready:
+		if err != nil {
+			cmd.Process.Kill()
+			return fmt.Errorf("new process failed before taking over: %w", err)
+		}
+	case err := <-exited:
+		return fmt.Errorf("new process exited before taking over: %v", err)
+	case <-time.After(timeout):
+		cmd.Process.Kill()
+		return errors.New("timed out waiting for the new process to take over")
+	}
+
+	if err := json.NewEncoder(stateWrite).Encode(stop()); err != nil {
+		return fmt.Errorf("failed to send state to the new process: %w", err)
+	}
+	return nil
+}
+
+// listenerFile duplicates the descriptor of a listener
+func listenerFile(listener net.Listener) (*os.File, error) {
+	switch l := listener.(type) {
+	case *net.TCPListener:
+		return l.File()
+	case *net.UnixListener:
+		return l.File()
+	}
+	return nil, fmt.Errorf("can't hand over a %T", listener)
+}
+
+// Notify sends a state such as "READY=1" to systemd when it supervises the
+// process with Type=notify, and does nothing otherwise
+func Notify(state string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("failed to notify systemd: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return fmt.Errorf("failed to notify systemd: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package handover
+
+import (
+	"os"
+	"syscall"
+)
+
+// Signals request a handover to a new process
+var Signals = []os.Signal{syscall.SIGUSR2}
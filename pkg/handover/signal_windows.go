@@ -0,0 +1,7 @@
+package handover
+
+import "os"
+
+// Signals request a handover to a new process. Windows has none, so
+// processes there are only ever restarted.
+var Signals []os.Signal
@@ -0,0 +1,149 @@
+package i18n
+
+var english = Catalog{
+	KeyBalanceChanged: "Token balance changed",
+	KeyNewHolding:     "New token acquired",
+	KeyLargeHolder:    "Large holder moved tokens",
+	KeyStakeChanged:   "Stake delegation changed",
+	KeyStakeActivated: "Stake activated",
+	KeyStakeInactive:  "Stake deactivated",
+	KeyStakeReward:    "Staking reward received",
+	KeyStakeAccount:   "Stake account: %[1]s",
+	KeyValidator:      "Validator: %[1]s",
+	KeyStakeStatus:    "Status: %[1]s",
+	KeyDelegated:      "Delegated: %[1]s SOL",
+	KeyReward:         "Reward (epoch %[2]d): %[1]s SOL",
+	KeyNFTReceived:    "NFT received",
+	KeyNFTSent:        "NFT sent",
+	KeyNFTListed:      "NFT listed",
+	KeyNFTBurned:      "NFT burned",
+	KeyNFTMinted:      "Compressed NFT minted",
+	KeyAsset:          "Asset: %[1]s (%[2]s)",
+	KeyCollection:     "Collection: %[1]s",
+	KeyBatchTitle:     "%[1]d changes for wallet %[2]s",
+	KeyDigestTitle:    "Wallet %[1]s: %[2]d changes in the last %[3]s",
+	KeyNetChange:      "Net %[1]s %[2]s",
+	KeySuppressed:     "(%[1]d earlier notifications suppressed by the cooldown)",
+	KeyTokenName:      "Name: %[1]s (%[2]s)",
+	KeyRiskFlags:      "Risk: %[1]s",
+	KeyRiskScore:      "Risk score: %[1]d/100",
+	KeyWallet:         "Wallet: %[1]s",
+	KeyMint:           "Token: %[1]s",
+	KeyBalance:        "Balance: %[1]s",
+	KeyAccount:        "Account: %[1]s",
+	KeyTransaction:    "Transaction: %[1]s",
+	KeyHeartbeat:      "Tracker is running (%[1]s)",
+	KeyDegraded:       "Tracker degraded",
+	KeyRecovered:      "Tracker recovered",
+	KeyHealthEvents:   "%[1]d events since the last report, %[2]d wallets monitored",
+	KeyLastEvent:      "Last event: %[1]s",
+	KeyNoEvents:       "No events for %[1]s",
+	KeyRPCFailures:    "RPC endpoint failed %[1]d checks in a row: %[2]s",
+	KeyGroupBelow:     "Group %[1]s fell below %[2]s",
+	KeyGroupAbove:     "Group %[1]s rose above %[2]s",
+	KeyTotalBelow:     "Total of all wallets fell below %[1]s",
+	KeyTotalAbove:     "Total of all wallets rose above %[1]s",
+	KeyFlowReport:     "Net flows of %[1]s",
+	KeyFlow:           "%[1]s: in %[2]s, out %[3]s, net %[4]s",
+	KeyGroupTotal:     "Total: %[1]s across %[2]d wallets (was %[3]s)",
+	KeySentTo:         "Sent %[1]s %[2]s to %[3]s",
+	KeyReceivedFrom:   "Received %[1]s %[2]s from %[3]s",
+	KeyMemo:           "Memo: %[1]s",
+	KeyMintedBy:       "Minted by %[1]s (airdrop or claim)",
+	KeyTokenBurned:    "Tokens burned",
+	KeyBurned:         "Burned: %[1]s",
+	KeyDelegation:     "Token delegate changed",
+	KeyDelegate:       "Delegate %[1]s may transfer %[2]s",
+	KeyRevoked:        "Delegate %[1]s revoked",
+	KeyAuthority:      "Account authority changed",
+	KeyNewOwner:       "Token account transferred to owner %[1]s",
+	KeyCloser:         "Close authority set to %[1]s",
+	KeyCloseRemoved:   "Close authority %[1]s removed",
+	KeyAssigned:       "Wallet assigned from program %[1]s to %[2]s",
+	KeyFrozen:         "Token account frozen",
+	KeyThawed:         "Token account thawed",
+	KeyClosed:         "Token account closed",
+	KeyFrozenBy:       "Freeze authority: %[1]s",
+	KeySignature:      "Wallet transaction",
+	KeyTxFailed:       "Transaction failed",
+	KeyAnomaly:        "Anomalous transfer",
+	KeyAnomalySize:    "%[1]s is %[2]s standard deviations above the usual %[3]s",
+	KeyAnomalyShare:   "Moved %[1]s%% of the balance",
+	KeyWalletStale:    "Wallet stale: no update or successful poll for %[1]s",
+	KeyLastActivity:   "Last activity: %[1]s",
+	KeySubscription:   "Subscription: %[1]s",
+}
+
+var vietnamese = Catalog{
+	KeyBalanceChanged: "Số dư token đã thay đổi",
+	KeyNewHolding:     "Ví nhận token mới",
+	KeyLargeHolder:    "Ví lớn vừa chuyển token",
+	KeyStakeChanged:   "Ủy thác stake đã thay đổi",
+	KeyStakeActivated: "Stake đã kích hoạt",
+	KeyStakeInactive:  "Stake đã hủy kích hoạt",
+	KeyStakeReward:    "Đã nhận thưởng staking",
+	KeyStakeAccount:   "Tài khoản stake: %[1]s",
+	KeyValidator:      "Validator: %[1]s",
+	KeyStakeStatus:    "Trạng thái: %[1]s",
+	KeyDelegated:      "Đã ủy thác: %[1]s SOL",
+	KeyReward:         "Thưởng (epoch %[2]d): %[1]s SOL",
+	KeyNFTReceived:    "Đã nhận NFT",
+	KeyNFTSent:        "Đã gửi NFT",
+	KeyNFTListed:      "NFT đã được niêm yết",
+	KeyNFTBurned:      "NFT đã bị đốt",
+	KeyNFTMinted:      "Đã mint NFT nén",
+	KeyAsset:          "Tài sản: %[1]s (%[2]s)",
+	KeyCollection:     "Bộ sưu tập: %[1]s",
+	KeyBatchTitle:     "%[1]d thay đổi của ví %[2]s",
+	KeyDigestTitle:    "Ví %[1]s: %[2]d thay đổi trong %[3]s qua",
+	KeyNetChange:      "Thay đổi ròng: %[1]s %[2]s",
+	KeySuppressed:     "(%[1]d thông báo trước đó bị chặn do giới hạn tần suất)",
+	KeyTokenName:      "Tên: %[1]s (%[2]s)",
+	KeyRiskFlags:      "Rủi ro: %[1]s",
+	KeyRiskScore:      "Điểm rủi ro: %[1]d/100",
+	KeyWallet:         "Ví: %[1]s",
+	KeyMint:           "Token: %[1]s",
+	KeyBalance:        "Số dư: %[1]s",
+	KeyAccount:        "Tài khoản: %[1]s",
+	KeyTransaction:    "Giao dịch: %[1]s",
+	KeyHeartbeat:      "Tracker đang chạy (%[1]s)",
+	KeyDegraded:       "Tracker gặp sự cố",
+	KeyRecovered:      "Tracker đã hoạt động trở lại",
+	KeyHealthEvents:   "%[1]d sự kiện từ lần báo cáo trước, đang theo dõi %[2]d ví",
+	KeyLastEvent:      "Sự kiện gần nhất: %[1]s",
+	KeyNoEvents:       "Không có sự kiện trong %[1]s",
+	KeyRPCFailures:    "RPC endpoint lỗi %[1]d lần kiểm tra liên tiếp: %[2]s",
+	KeyGroupBelow:     "Nhóm %[1]s đã xuống dưới %[2]s",
+	KeyGroupAbove:     "Nhóm %[1]s đã vượt trên %[2]s",
+	KeyTotalBelow:     "Tổng của tất cả các ví đã xuống dưới %[1]s",
+	KeyTotalAbove:     "Tổng của tất cả các ví đã vượt trên %[1]s",
+	KeyFlowReport:     "Dòng tiền ròng của %[1]s",
+	KeyFlow:           "%[1]s: vào %[2]s, ra %[3]s, ròng %[4]s",
+	KeyGroupTotal:     "Tổng: %[1]s trên %[2]d ví (trước đó %[3]s)",
+	KeySentTo:         "Đã gửi %[1]s %[2]s đến %[3]s",
+	KeyReceivedFrom:   "Đã nhận %[1]s %[2]s từ %[3]s",
+	KeyMemo:           "Ghi chú: %[1]s",
+	KeyMintedBy:       "Được mint bởi %[1]s (airdrop hoặc claim)",
+	KeyTokenBurned:    "Token đã bị đốt",
+	KeyBurned:         "Đã đốt: %[1]s",
+	KeyDelegation:     "Người được ủy quyền token đã thay đổi",
+	KeyDelegate:       "Người được ủy quyền %[1]s có thể chuyển %[2]s",
+	KeyRevoked:        "Đã thu hồi ủy quyền của %[1]s",
+	KeyAuthority:      "Quyền kiểm soát tài khoản đã thay đổi",
+	KeyNewOwner:       "Tài khoản token đã được chuyển cho chủ sở hữu %[1]s",
+	KeyCloser:         "Quyền đóng tài khoản được giao cho %[1]s",
+	KeyCloseRemoved:   "Đã gỡ quyền đóng tài khoản của %[1]s",
+	KeyAssigned:       "Ví đã được chuyển từ chương trình %[1]s sang %[2]s",
+	KeyFrozen:         "Tài khoản token đã bị đóng băng",
+	KeyThawed:         "Tài khoản token đã được mở băng",
+	KeyClosed:         "Tài khoản token đã bị đóng",
+	KeyFrozenBy:       "Quyền đóng băng: %[1]s",
+	KeySignature:      "Giao dịch của ví",
+	KeyTxFailed:       "Giao dịch thất bại",
+	KeyAnomaly:        "Giao dịch bất thường",
+	KeyAnomalySize:    "%[1]s cao hơn mức thông thường %[3]s %[2]s độ lệch chuẩn",
+	KeyAnomalyShare:   "Đã chuyển %[1]s%% số dư",
+	KeyWalletStale:    "Ví không còn cập nhật: không có thông báo hay lần kiểm tra thành công nào trong %[1]s",
+	KeyLastActivity:   "Hoạt động gần nhất: %[1]s",
+	KeySubscription:   "Đăng ký: %[1]s",
+}
@@ -0,0 +1,148 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Supported locales
+const (
+	English    = "en"
+	Vietnamese = "vi"
+
+	// DefaultLocale is used when a channel has no locale or an unknown one
+	DefaultLocale = English
+)
+
+// Message keys for built-in notification texts
+const (
+	KeyBalanceChanged = "balance_changed"
+	KeyNewHolding     = "new_holding"
+	KeyLargeHolder    = "large_holder_move"
+	KeyStakeChanged   = "stake_delegation_changed"
+	KeyStakeActivated = "stake_activated"
+	KeyStakeInactive  = "stake_deactivated"
+	KeyStakeReward    = "stake_reward"
+	KeyStakeAccount   = "stake_account"
+	KeyValidator      = "validator"
+	KeyStakeStatus    = "stake_status"
+	KeyDelegated      = "delegated"
+	KeyReward         = "reward"
+	KeyNFTReceived    = "nft_received"
+	KeyNFTSent        = "nft_sent"
+	KeyNFTListed      = "nft_listed"
+	KeyNFTBurned      = "nft_burned"
+	KeyNFTMinted      = "nft_minted"
+	KeyAsset          = "asset"
+	KeyCollection     = "collection"
+	KeyBatchTitle     = "batch_title"
+	KeyDigestTitle    = "digest_title"
+	KeyNetChange      = "net_change"
+	KeySuppressed     = "suppressed"
+	KeyTokenName      = "token_name"
+	KeyRiskFlags      = "risk_flags"
+	KeyRiskScore      = "risk_score"
+	KeyWallet         = "wallet"
+	KeyMint           = "mint"
+	KeyBalance        = "balance"
+	KeyAccount        = "account"
+	KeyTransaction    = "transaction"
+	KeyHeartbeat      = "heartbeat"
+	KeyDegraded       = "degraded"
+	KeyRecovered      = "recovered"
+	KeyHealthEvents   = "health_events"
+	KeyLastEvent      = "last_event"
+	KeyNoEvents       = "no_events"
+	KeyRPCFailures    = "rpc_failures"
+	KeyGroupBelow     = "group_below"
+	KeyGroupAbove     = "group_above"
+	KeyTotalBelow     = "total_below"
+	KeyTotalAbove     = "total_above"
+	KeyFlowReport     = "flow_report"
+	KeyFlow           = "flow"
+	KeyGroupTotal     = "group_total"
+	KeySentTo         = "sent_to"
+	KeyReceivedFrom   = "received_from"
+	KeyMemo           = "memo"
+	KeyMintedBy       = "minted_by"
+	KeyTokenBurned    = "token_burned"
+	KeyBurned         = "burned"
+	KeyDelegation     = "delegation"
+	KeyDelegate       = "delegate"
+	KeyRevoked        = "revoked"
+	KeyAuthority      = "authority_changed"
+	KeyNewOwner       = "new_owner"
+	KeyCloser         = "close_authority"
+	KeyCloseRemoved   = "close_removed"
+	KeyAssigned       = "assigned"
+	KeyFrozen         = "account_frozen"
+	KeyThawed         = "account_thawed"
+	KeyClosed         = "account_closed"
+	KeyFrozenBy       = "freeze_authority"
+	KeySignature      = "signature"
+	KeyTxFailed       = "transaction_failed"
+	KeyAnomaly        = "anomaly"
+	KeyAnomalySize    = "anomaly_size"
+	KeyAnomalyShare   = "anomaly_holdings"
+	KeyWalletStale    = "wallet_stale"
+	KeyLastActivity   = "last_activity"
+	KeySubscription   = "subscription"
+)
+
+// Catalog maps message keys to fmt format strings. Formats may use indexed
+// verbs (%[1]s) so translations can reorder arguments.
+type Catalog map[string]string
+
+var (
+	catalogs = map[string]Catalog{
+		English:    english,
+		Vietnamese: vietnamese,
+	}
+	catalogsMutex sync.RWMutex
+)
+
+// Register adds or replaces the catalog for a locale
+func Register(locale string, catalog Catalog) {
+	catalogsMutex.Lock()
+	defer catalogsMutex.Unlock()
+
+	catalogs[normalize(locale)] = catalog
+}
+
+// Supported reports whether a catalog exists for the locale
+func Supported(locale string) bool {
+	catalogsMutex.RLock()
+	defer catalogsMutex.RUnlock()
+
+	_, ok := catalogs[normalize(locale)]
+	return ok
+}
+
+// T returns the localized message for key, falling back to the default
+// locale and finally to the key itself
+func T(locale, key string, args ...interface{}) string {
+	catalogsMutex.RLock()
+	format, ok := catalogs[normalize(locale)][key]
+	if !ok {
+		format, ok = catalogs[DefaultLocale][key]
+	}
+	catalogsMutex.RUnlock()
+
+	if !ok {
+		return key
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// normalize reduces a locale tag such as "vi-VN" or "en_US" to its language
+func normalize(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		locale = locale[:i]
+	}
+	if locale == "" {
+		return DefaultLocale
+	}
+	return locale
+}
@@ -0,0 +1,83 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// heliusTransaction mirrors the fields of a Helius enhanced transaction the
+// tracker reads
+type heliusTransaction struct {
+	Signature        string          `json:"signature"`
+	Slot             uint64          `json:"slot"`
+	Timestamp        int64           `json:"timestamp"`
+	FeePayer         string          `json:"feePayer"`
+	TransactionError json.RawMessage `json:"transactionError"`
+	AccountData      []struct {
+		Account             string `json:"account"`
+		NativeBalanceChange int64  `json:"nativeBalanceChange"`
+		TokenBalanceChanges []struct {
+			UserAccount    string `json:"userAccount"`
+			TokenAccount   string `json:"tokenAccount"`
+			Mint           string `json:"mint"`
+			RawTokenAmount struct {
+				TokenAmount string `json:"tokenAmount"`
+				Decimals    uint8  `json:"decimals"`
+			} `json:"rawTokenAmount"`
+		} `json:"tokenBalanceChanges"`
+	} `json:"accountData"`
+}
+
+// parseHelius normalizes a delivery of a Helius enhanced webhook, an array
+// of enhanced transactions. Helius reports balance changes without the
+// balances they led to.
+func parseHelius(body []byte) ([]Transaction, error) {
+	var delivered []heliusTransaction
+	if err := json.Unmarshal(body, &delivered); err != nil {
+		return nil, fmt.Errorf("failed to decode Helius delivery: %w", err)
+	}
+
+	transactions := make([]Transaction, 0, len(delivered))
+	for _, tx := range delivered {
+		if tx.Signature == "" {
+			return nil, fmt.Errorf("Helius transaction has no signature")
+		}
+		transaction := Transaction{
+			Signature: tx.Signature,
+			Slot:      tx.Slot,
+			BlockTime: time.Unix(tx.Timestamp, 0),
+			Failed:    len(tx.TransactionError) > 0 && string(tx.TransactionError) != "null",
+		}
+		if tx.FeePayer != "" {
+			transaction.Accounts = append(transaction.Accounts, tx.FeePayer)
+		}
+
+		for _, data := range tx.AccountData {
+			transaction.Accounts = append(transaction.Accounts, data.Account)
+			if data.NativeBalanceChange != 0 {
+				transaction.Native = append(transaction.Native, NativeChange{
+					Account: data.Account,
+					Delta:   data.NativeBalanceChange,
+				})
+			}
+			for _, change := range data.TokenBalanceChanges {
+				delta, err := strconv.ParseInt(change.RawTokenAmount.TokenAmount, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid token amount %q in %s", change.RawTokenAmount.TokenAmount, tx.Signature)
+				}
+				transaction.Accounts = append(transaction.Accounts, change.UserAccount)
+				transaction.Tokens = append(transaction.Tokens, TokenChange{
+					Wallet:   change.UserAccount,
+					Account:  change.TokenAccount,
+					Mint:     change.Mint,
+					Decimals: change.RawTokenAmount.Decimals,
+					Delta:    delta,
+				})
+			}
+		}
+		transactions = append(transactions, transaction)
+	}
+	return transactions, nil
+}
@@ -0,0 +1,124 @@
+// Package ingest receives the transactions of webhook providers such as
+// Helius and QuickNode and normalizes them into balance changes, so the
+// tracker can be fed by pushes instead of RPC subscriptions and polling
+package ingest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Providers whose webhooks can be ingested
+const (
+	ProviderHelius    = "helius"
+	ProviderQuickNode = "quicknode"
+)
+
+// Tolerance is how far the timestamp of a signed QuickNode delivery may
+// be from the local clock, which bounds replays of captured requests
+const Tolerance = 5 * time.Minute
+
+// ErrUnauthorized is returned for deliveries whose secret or signature
+// doesn't match
+var ErrUnauthorized = errors.New("invalid webhook signature")
+
+// Transaction is a transaction delivered by a provider
+type Transaction struct {
+	Signature string
+	Slot      uint64
+	BlockTime time.Time
+	Failed    bool
+	// Accounts are the addresses the transaction mentions, which include
+	// the wallets it concerns
+	Accounts []string
+	// Tokens are the changes of the token balances it touched
+	Tokens []TokenChange
+	// Native are the changes of the lamports of its accounts
+	Native []NativeChange
+}
+
+// TokenChange is the change of a token account balance in a transaction
+type TokenChange struct {
+	Wallet   string
+	Account  string
+	Mint     string
+	Decimals uint8
+	// Delta is the change of the balance in base units
+	Delta int64
+	// Post is the balance after the transaction, when the provider reports
+	// it. Changes without one are applied to the known balance.
+	Post *uint64
+}
+
+// NativeChange is the change of the lamports of an account in a transaction
+type NativeChange struct {
+	Account string
+	Delta   int64
+}
+
+// Secrets authenticate the deliveries of each provider. Deliveries of a
+// provider without a secret are rejected.
+type Secrets struct {
+	// HeliusAuth is the auth header configured on the Helius webhook, which
+	// Helius sends as the Authorization header
+	HeliusAuth string
+	// QuickNodeKey is the security token of the QuickNode stream or
+	// webhook, which signs each delivery
+	QuickNodeKey string
+}
+
+// Parse verifies a delivery of a provider and normalizes its transactions
+func Parse(provider string, secrets Secrets, header http.Header, body []byte) ([]Transaction, error) {
+	switch provider {
+	case ProviderHelius:
+		if !verifyHelius(secrets.HeliusAuth, header) {
+			return nil, ErrUnauthorized
+		}
+		return parseHelius(body)
+	case ProviderQuickNode:
+		if !verifyQuickNode(secrets.QuickNodeKey, header, body, time.Now()) {
+			return nil, ErrUnauthorized
+		}
+		return parseQuickNode(body)
+	}
+	return nil, fmt.Errorf("unknown provider %q", provider)
+}
+
+// verifyHelius checks the Authorization header Helius sends with each
+// delivery against the configured auth header
+func verifyHelius(auth string, header http.Header) bool {
+	given := header.Get("Authorization")
+	return auth != "" && subtle.ConstantTimeCompare([]byte(given), []byte(auth)) == 1
+}
+
+// verifyQuickNode checks the signature of a QuickNode delivery, an HMAC-SHA256
+// of its nonce, timestamp and body keyed with the security token, and that
+// its timestamp is recent
+func verifyQuickNode(key string, header http.Header, body []byte, now time.Time) bool {
+	nonce := header.Get("X-QN-Nonce")
+	timestamp := header.Get("X-QN-Timestamp")
+	signature, err := hex.DecodeString(header.Get("X-QN-Signature"))
+	if key == "" || nonce == "" || err != nil {
+		return false
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if skew := now.Sub(time.Unix(seconds, 0)); skew > Tolerance || skew < -Tolerance {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(nonce + timestamp))
+	mac.Write(body)
+	return hmac.Equal(signature, mac.Sum(nil))
+}
@@ -0,0 +1,100 @@
+package ingest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerifyHelius(t *testing.T) {
+	tests := []struct {
+		name   string
+		auth   string
+		header string
+		valid  bool
+	}{
+		{"matching", "secret", "secret", true},
+		{"different", "secret", "Secret", false},
+		{"missing header", "secret", "", false},
+		{"no secret configured", "", "", false},
+		{"prefix of the secret", "secret", "sec", false},
+	}
+	for _, tt := range tests {
+		header := http.Header{}
+		if tt.header != "" {
+			header.Set("Authorization", tt.header)
+		}
+		if valid := verifyHelius(tt.auth, header); valid != tt.valid {
+			t.Errorf("%s: valid = %v, want %v", tt.name, valid, tt.valid)
+		}
+	}
+}
+
+func TestVerifyQuickNode(t *testing.T) {
+	const key = "token"
+	now := time.Unix(1733011200, 0)
+	body := []byte(`{"slot":1}`)
+	sign := func(key, nonce, timestamp string, body []byte) string {
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write([]byte(nonce + timestamp))
+		mac.Write(body)
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+	at := func(offset time.Duration) string { return strconv.FormatInt(now.Add(offset).Unix(), 10) }
+
+	tests := []struct {
+		name      string
+		key       string
+		nonce     string
+		timestamp string
+		signature string
+		body      []byte
+		valid     bool
+	}{
+		{"signed", key, "n1", at(0), sign(key, "n1", at(0), body), body, true},
+		{"within the tolerance", key, "n1", at(-Tolerance), sign(key, "n1", at(-Tolerance), body), body, true},
+		{"ahead within the tolerance", key, "n1", at(Tolerance), sign(key, "n1", at(Tolerance), body), body, true},
+		{"too old", key, "n1", at(-Tolerance - time.Second), sign(key, "n1", at(-Tolerance-time.Second), body), body, false},
+		{"too far ahead", key, "n1", at(Tolerance + time.Second), sign(key, "n1", at(Tolerance+time.Second), body), body, false},
+		{"other key", key, "n1", at(0), sign("other", "n1", at(0), body), body, false},
+		{"changed body", key, "n1", at(0), sign(key, "n1", at(0), body), []byte(`{"slot":2}`), false},
+		{"changed nonce", key, "n2", at(0), sign(key, "n1", at(0), body), body, false},
+		{"missing nonce", key, "", at(0), sign(key, "", at(0), body), body, false},
+		{"invalid timestamp", key, "n1", "soon", sign(key, "n1", "soon", body), body, false},
+		{"signature not hex", key, "n1", at(0), "zz", body, false},
+		{"missing signature", key, "n1", at(0), "", body, false},
+		{"no key configured", "", "n1", at(0), sign("", "n1", at(0), body), body, false},
+	}
+	for _, tt := range tests {
+		header := http.Header{}
+		header.Set("X-QN-Nonce", tt.nonce)
+		header.Set("X-QN-Timestamp", tt.timestamp)
+		header.Set("X-QN-Signature", tt.signature)
+		if valid := verifyQuickNode(tt.key, header, tt.body, now); valid != tt.valid {
+			t.Errorf("%s: valid = %v, want %v", tt.name, valid, tt.valid)
+		}
+	}
+}
+
+func TestParseRejectsUnauthorized(t *testing.T) {
+	secrets := Secrets{HeliusAuth: "secret", QuickNodeKey: "token"}
+	for _, provider := range []string{ProviderHelius, ProviderQuickNode} {
+		if _, err := Parse(provider, secrets, http.Header{}, []byte(`[]`)); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("%s: got %v without credentials, want ErrUnauthorized", provider, err)
+		}
+	}
+
+	header := http.Header{}
+	header.Set("Authorization", "secret")
+	if _, err := Parse(ProviderHelius, secrets, header, []byte(`[]`)); err != nil {
+		t.Errorf("authorized Helius delivery: %v", err)
+	}
+	if _, err := Parse("alchemy", secrets, header, []byte(`[]`)); err == nil || errors.Is(err, ErrUnauthorized) {
+		t.Errorf("unknown provider: got %v, want an unknown provider error", err)
+	}
+}
@@ -0,0 +1,166 @@
+package ingest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// rpcTransaction mirrors the fields of a transaction in the JSON format of
+// getTransaction the tracker reads
+type rpcTransaction struct {
+	Slot      uint64 `json:"slot"`
+	BlockTime *int64 `json:"blockTime"`
+	Meta      *struct {
+		Err               json.RawMessage   `json:"err"`
+		PreBalances       []uint64          `json:"preBalances"`
+		PostBalances      []uint64          `json:"postBalances"`
+		PreTokenBalances  []rpcTokenBalance `json:"preTokenBalances"`
+		PostTokenBalances []rpcTokenBalance `json:"postTokenBalances"`
+		LoadedAddresses   struct {
+			Writable []string `json:"writable"`
+			Readonly []string `json:"readonly"`
+		} `json:"loadedAddresses"`
+	} `json:"meta"`
+	Transaction struct {
+		Signatures []string `json:"signatures"`
+		Message    struct {
+			AccountKeys []json.RawMessage `json:"accountKeys"`
+		} `json:"message"`
+	} `json:"transaction"`
+}
+
+// rpcTokenBalance is a token balance of a transaction
+type rpcTokenBalance struct {
+	AccountIndex  int    `json:"accountIndex"`
+	Mint          string `json:"mint"`
+	Owner         string `json:"owner"`
+	UITokenAmount struct {
+		Amount   string `json:"amount"`
+		Decimals uint8  `json:"decimals"`
+	} `json:"uiTokenAmount"`
+}
+
+// parseQuickNode normalizes a delivery of a QuickNode stream or webhook,
+// a transaction or an array of transactions in the JSON format of
+// getTransaction. Their token balances include the balances after the
+// transaction.
+func parseQuickNode(body []byte) ([]Transaction, error) {
+	var delivered []rpcTransaction
+	body = bytes.TrimSpace(body)
+	if bytes.HasPrefix(body, []byte("{")) {
+		delivered = make([]rpcTransaction, 1)
+		if err := json.Unmarshal(body, &delivered[0]); err != nil {
+			return nil, fmt.Errorf("failed to decode QuickNode delivery: %w", err)
+		}
+	} else if err := json.Unmarshal(body, &delivered); err != nil {
+		return nil, fmt.Errorf("failed to decode QuickNode delivery: %w", err)
+	}
+
+	transactions := make([]Transaction, 0, len(delivered))
+	for _, tx := range delivered {
+		transaction, err := normalizeRPCTransaction(tx)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+	return transactions, nil
+}
+
+// normalizeRPCTransaction turns the balances of a transaction before and
+// after it into changes
+func normalizeRPCTransaction(tx rpcTransaction) (Transaction, error) {
+	if len(tx.Transaction.Signatures) == 0 || tx.Meta == nil {
+		return Transaction{}, fmt.Errorf("QuickNode transaction has no signature or meta")
+	}
+	transaction := Transaction{
+		Signature: tx.Transaction.Signatures[0],
+		Slot:      tx.Slot,
+		Failed:    len(tx.Meta.Err) > 0 && string(tx.Meta.Err) != "null",
+	}
+	if tx.BlockTime != nil {
+		transaction.BlockTime = time.Unix(*tx.BlockTime, 0)
+	}
+
+	// Keys are strings, or objects with a pubkey in the jsonParsed format,
+	// followed by the keys loaded from lookup tables
+	for _, raw := range tx.Transaction.Message.AccountKeys {
+		var key string
+		if err := json.Unmarshal(raw, &key); err != nil {
+			var parsed struct {
+				Pubkey string `json:"pubkey"`
+			}
+			if err := json.Unmarshal(raw, &parsed); err != nil {
+				return Transaction{}, fmt.Errorf("invalid account key in %s: %w", transaction.Signature, err)
+			}
+			key = parsed.Pubkey
+		}
+		transaction.Accounts = append(transaction.Accounts, key)
+	}
+	transaction.Accounts = append(transaction.Accounts, tx.Meta.LoadedAddresses.Writable...)
+	transaction.Accounts = append(transaction.Accounts, tx.Meta.LoadedAddresses.Readonly...)
+
+	for i, account := range transaction.Accounts {
+		if i >= len(tx.Meta.PreBalances) || i >= len(tx.Meta.PostBalances) {
+			break
+		}
+		if delta := int64(tx.Meta.PostBalances[i]) - int64(tx.Meta.PreBalances[i]); delta != 0 {
+			transaction.Native = append(transaction.Native, NativeChange{Account: account, Delta: delta})
+		}
+	}
+
+	// Accounts opened by the transaction have no balance before it, closed
+	// ones none after it
+	pre := make(map[int]uint64, len(tx.Meta.PreTokenBalances))
+	changes := make(map[int]TokenChange)
+	var order []int
+	for _, balance := range tx.Meta.PreTokenBalances {
+		amount, err := strconv.ParseUint(balance.UITokenAmount.Amount, 10, 64)
+		if err != nil {
+			return Transaction{}, fmt.Errorf("invalid token amount %q in %s", balance.UITokenAmount.Amount, transaction.Signature)
+		}
+		pre[balance.AccountIndex] = amount
+		changes[balance.AccountIndex] = tokenChange(transaction.Accounts, balance)
+		order = append(order, balance.AccountIndex)
+	}
+	for _, balance := range tx.Meta.PostTokenBalances {
+		amount, err := strconv.ParseUint(balance.UITokenAmount.Amount, 10, 64)
+		if err != nil {
+			return Transaction{}, fmt.Errorf("invalid token amount %q in %s", balance.UITokenAmount.Amount, transaction.Signature)
+		}
+		if _, ok := changes[balance.AccountIndex]; !ok {
+			order = append(order, balance.AccountIndex)
+		}
+		change := tokenChange(transaction.Accounts, balance)
+		change.Post = &amount
+		changes[balance.AccountIndex] = change
+	}
+	for _, index := range order {
+		change := changes[index]
+		if change.Post == nil {
+			zero := uint64(0)
+			change.Post = &zero
+		}
+		change.Delta = int64(*change.Post) - int64(pre[index])
+		if change.Delta != 0 && change.Account != "" {
+			transaction.Tokens = append(transaction.Tokens, change)
+		}
+	}
+	return transaction, nil
+}
+
+// tokenChange describes the token account of a balance of a transaction
+func tokenChange(keys []string, balance rpcTokenBalance) TokenChange {
+	change := TokenChange{
+		Wallet:   balance.Owner,
+		Mint:     balance.Mint,
+		Decimals: balance.UITokenAmount.Decimals,
+	}
+	if balance.AccountIndex >= 0 && balance.AccountIndex < len(keys) {
+		change.Account = keys[balance.AccountIndex]
+	}
+	return change
+}
@@ -0,0 +1,94 @@
+// Package logging defines the structured logger that the monitor and the
+// Solana client log through. Its methods match those of log/slog's
+// *slog.Logger, so a slog logger can be injected as is; Logrus adapts a
+// logrus logger, which is used by default.
+package logging
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Logger logs messages with key-value pairs of attributes, e.g.
+// log.Warn("Failed to poll", "wallet", wallet, "error", err)
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// badKey names values without a key, as slog does
+const badKey = "!BADKEY"
+
+// Default returns the logger of components that weren't given one, the
+// standard logrus logger
+func Default() Logger {
+	return Logrus(logrus.StandardLogger())
+}
+
+// Logrus adapts a logrus logger or entry. Attributes become its fields.
+func Logrus(logger logrus.FieldLogger) Logger {
+	return logrusLogger{logger: logger}
+}
+
+// logrusLogger is a Logger writing to logrus
+type logrusLogger struct {
+	logger logrus.FieldLogger
+}
+
+// Debug logs at debug level
+func (l logrusLogger) Debug(msg string, args ...any) {
+	l.with(args).Debug(msg)
+}
+
+// Info logs at info level
+func (l logrusLogger) Info(msg string, args ...any) {
+	l.with(args).Info(msg)
+}
+
+// Warn logs at warning level
+func (l logrusLogger) Warn(msg string, args ...any) {
+	l.with(args).Warn(msg)
+}
+
+// Error logs at error level
+func (l logrusLogger) Error(msg string, args ...any) {
+	l.with(args).Error(msg)
+}
+
+// with adds the attributes to the logger
+func (l logrusLogger) with(args []any) logrus.FieldLogger {
+	if len(args) == 0 {
+		return l.logger
+	}
+	fields := make(logrus.Fields, (len(args)+1)/2)
+	for i := 0; i < len(args); i += 2 {
+		key, ok := args[i].(string)
+		if !ok || i+1 == len(args) {
+			fields[badKey] = args[i]
+			i--
+			continue
+		}
+		fields[key] = args[i+1]
+	}
+	return l.logger.WithFields(fields)
+}
+
+// contextKey is the context key of loggers
+type contextKey struct{}
+
+// NewContext returns a context carrying logger, for code that only gets a
+// context, such as enrichers
+func NewContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger of a context, or the default logger
+func FromContext(ctx context.Context) Logger {
+	if logger, ok := ctx.Value(contextKey{}).(Logger); ok {
+		return logger
+	}
+	return Default()
+}
@@ -0,0 +1,106 @@
+package monitor
+
+import (
+	"context"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// AuthorityChange describes an authority of a tracked account that changed
+type AuthorityChange struct {
+	// Kind is AuthorityOwner, AuthorityClose or AuthorityProgram
+	Kind     string `json:"kind"`
+	Previous string `json:"previous,omitempty"`
+	Current  string `json:"current,omitempty"`
+}
+
+// Authority change kinds
+const (
+	// AuthorityOwner is a token account transferred to another owner
+	AuthorityOwner = "owner"
+	// AuthorityClose is a close authority set on or removed from a token
+	// account
+	AuthorityClose = "close_authority"
+	// AuthorityProgram is a wallet assigned from the system program to
+	// another program
+	AuthorityProgram = "program"
+)
+
+// WatchAuthorities emits critical authority_changed events when a tracked
+// token account is transferred to another owner or its close authority
+// changes, and when a wallet is assigned to another program. These are what
+// wallet drainers do, so they are reported as soon as they are seen: close
+// authorities with the account update, owner and program changes with the
+// next poll, since the owner filtered subscription stops reporting accounts
+// that left the wallet. Call it before Start.
+func (m *Monitor) WatchAuthorities() {
+	m.authorities = true
+	m.programs = make(map[string]string)
+}
+
+// closeAuthorityChanged reports whether an update set, changed or removed
+// the close authority of an account
+func closeAuthorityChanged(previous, account solana.TokenAccountInfo) bool {
+	return previous.CloseAuthority != account.CloseAuthority
+}
+
+// authorityEvent describes the authority change of an account
+func (m *Monitor) authorityEvent(account solana.TokenAccountInfo, previous *solana.TokenAccountInfo, change AuthorityChange) Event {
+	m.log.Warn("Tracked account authority changed",
+		"wallet", account.Owner,
+		"account", account.Address,
+		"kind", change.Kind,
+		"previous", change.Previous,
+		"current", change.Current,
+	)
+
+	return Event{
+		Type:      EventAuthorityChanged,
+		Account:   account,
+		Previous:  previous,
+		Authority: &change,
+		Severity:  SeverityCritical,
+	}
+}
+
+// checkWalletPrograms looks up the program owning each active wallet and
+// reports wallets assigned away from the program they were first seen
+// with, normally the system program
+func (m *Monitor) checkWalletPrograms(ctx context.Context) {
+	wallets := m.activeWallets()
+	summaries, err := m.client.GetAccountSummaries(ctx, wallets)
+	if err != nil {
+		m.log.Warn("Failed to look up the programs of the wallets", "error", err)
+		return
+	}
+
+	for _, wallet := range wallets {
+		// Wallets without lamports don't exist on chain
+		summary, ok := summaries[wallet]
+		if !ok {
+			continue
+		}
+
+		m.stateMutex.Lock()
+		previous, seen := m.programs[wallet]
+		m.programs[wallet] = summary.Owner
+		m.stateMutex.Unlock()
+
+		if !seen || previous == summary.Owner {
+			continue
+		}
+		m.emit(m.authorityEvent(solana.TokenAccountInfo{
+			Address:       wallet,
+			Owner:         wallet,
+			Mint:          solana.NativeMint,
+			Balance:       summary.Lamports,
+			Decimals:      lamportsDecimals,
+			ProgramID:     summary.Owner,
+			LastUpdatedAt: m.now(),
+		}, nil, AuthorityChange{
+			Kind:     AuthorityProgram,
+			Previous: previous,
+			Current:  summary.Owner,
+		}))
+	}
+}
@@ -0,0 +1,142 @@
+package monitor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+)
+
+// BackpressureStatus reports the pending events and the suspensions of
+// periodic polling they caused
+type BackpressureStatus struct {
+	// Pending is the number of events queued or being processed
+	Pending   int  `json:"pending"`
+	HighWater int  `json:"high_water"`
+	LowWater  int  `json:"low_water"`
+	Suspended bool `json:"suspended"`
+	// SuspendedSince is the start of the current suspension
+	SuspendedSince *time.Time `json:"suspended_since,omitempty"`
+	// Suspensions counts the times polling was suspended
+	Suspensions int64 `json:"suspensions"`
+	// SuspendedSeconds is the total time polling was suspended, including
+	// the current suspension
+	SuspendedSeconds float64 `json:"suspended_seconds"`
+	// SkippedPolls counts the polls left out while suspended
+	SkippedPolls int64 `json:"skipped_polls"`
+}
+
+// backpressure counts pending events and suspends polling between the time
+// they reach the high water mark and drain to the low one
+type backpressure struct {
+	high        int
+	low         int
+	mutex       sync.Mutex
+	pending     int
+	suspended   bool
+	since       time.Time
+	suspensions int64
+	total       time.Duration
+	skipped     int64
+	log         logging.Logger
+}
+
+// SetBackpressure suspends periodic polling while high or more events are
+// queued or being processed, and resumes it once they drain to low.
+// Subscriptions keep delivering updates meanwhile, so nothing is missed;
+// polling only catches up on what they dropped. Call it before Start.
+func (m *Monitor) SetBackpressure(high, low int) {
+	m.pressure = &backpressure{high: high, low: low, log: m.log}
+}
+
+// BackpressureStatus returns the pending events and polling suspensions.
+// It is zero when backpressure is disabled.
+func (m *Monitor) BackpressureStatus() BackpressureStatus {
+	b := m.pressure
+	if b == nil {
+		return BackpressureStatus{}
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	status := BackpressureStatus{
+		Pending:      b.pending,
+		HighWater:    b.high,
+		LowWater:     b.low,
+		Suspended:    b.suspended,
+		Suspensions:  b.suspensions,
+		SkippedPolls: b.skipped,
+	}
+	total := b.total
+	if b.suspended {
+		since := b.since
+		status.SuspendedSince = &since
+		total += time.Since(b.since)
+	}
+	status.SuspendedSeconds = total.Seconds()
+	return status
+}
+
+// track counts a job as pending until it returns
+func (b *backpressure) track(job func()) func() {
+	if b == nil {
+		return job
+	}
+	b.add(1)
+	return func() {
+		defer b.add(-1)
+		job()
+	}
+}
+
+// add changes the number of pending events and suspends or resumes polling
+// when it crosses a water mark
+func (b *backpressure) add(delta int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.pending += delta
+	switch {
+	case !b.suspended && b.pending >= b.high:
+		b.suspended = true
+		b.since = time.Now()
+		b.suspensions++
+		b.log.Warn("Event queue saturated, suspending polling",
+			"pending", b.pending,
+			"high_water", b.high,
+		)
+	case b.suspended && b.pending <= b.low:
+		b.suspended = false
+		suspended := time.Since(b.since)
+		b.total += suspended
+		b.log.Info("Event queue drained, resuming polling",
+			"pending", b.pending,
+			"suspended", suspended.Round(time.Millisecond),
+			"skipped", b.skipped,
+		)
+	}
+}
+
+// skip reports whether polling is suspended and counts the poll as skipped
+func (b *backpressure) skip() bool {
+	if b == nil {
+		return false
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.suspended {
+		b.skipped++
+	}
+	return b.suspended
+}
+
+// saturated reports whether polling is suspended, so a poll in progress can
+// stop early
+func (b *backpressure) saturated() bool {
+	if b == nil {
+		return false
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.suspended
+}
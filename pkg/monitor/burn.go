@@ -0,0 +1,23 @@
+package monitor
+
+import "context"
+
+// BurnEvent describes tokens a tracked account burned
+type BurnEvent struct {
+	// Amount is the amount burned in base units, in the decimals of Account
+	Amount uint64 `json:"amount"`
+}
+
+// BurnEnricher turns balance changes whose transfer burned tokens into
+// token_burned events, so burns aren't mistaken for outgoing transfers.
+// Register it after TransferEnricher and the enrichers reading balance
+// changes, such as WalletRiskEnricher and AnomalyEnricher.
+func BurnEnricher() Enricher {
+	return func(ctx context.Context, event *Event) {
+		if event.Type != EventBalanceChanged || event.Transfer == nil || event.Transfer.Burned == 0 {
+			return
+		}
+		event.Type = EventTokenBurned
+		event.Burn = &BurnEvent{Amount: event.Transfer.Burned}
+	}
+}
@@ -0,0 +1,42 @@
+package monitor
+
+import (
+	"context"
+	"time"
+)
+
+// Clock tells the monitor the time. The zero balance, staleness and health
+// checks and the timestamps of events read it, so tests can control them
+// with a fake clock, see package monitortest.
+type Clock interface {
+	Now() time.Time
+}
+
+// SetClock replaces the system clock. A fake clock doesn't tick, so the
+// periodic poller doesn't run; drive it with PollOnce instead. Call it
+// before Start.
+func (m *Monitor) SetClock(clock Clock) {
+	m.clock = clock
+}
+
+// now returns the time of the monitor's clock
+func (m *Monitor) now() time.Time {
+	if m.clock != nil {
+		return m.clock.Now()
+	}
+	return time.Now()
+}
+
+// PollOnce runs one round of the periodic poller, a poll followed by the
+// zero balance and staleness checks, and waits for the events it emits to
+// be delivered. ctx bounds the lookups of the poll.
+func (m *Monitor) PollOnce(ctx context.Context) {
+	m.tick(ctx)
+	m.Flush()
+}
+
+// Flush waits until the events emitted so far have been delivered to the
+// handlers
+func (m *Monitor) Flush() {
+	m.pending.Wait()
+}
@@ -0,0 +1,62 @@
+package monitor
+
+import (
+	"github.com/yourusername/solana-wallet-tracker/pkg/das"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// EnableCompressedNFTLogs turns on detection of compressed NFT mints,
+// transfers and burns from Bubblegum transactions of the tracked wallets.
+// Compressed assets have no token accounts, so balance monitoring misses them.
+func (m *Monitor) EnableCompressedNFTLogs() {
+	m.cnftLogs = true
+}
+
+// processBubblegumTransaction emits events for the compressed NFT actions of
+// a transaction that involve the wallet
+func (m *Monitor) processBubblegumTransaction(wallet string, tx *solana.Transaction) {
+	for _, action := range solana.ParseCompressedNFTActions(tx) {
+		var eventType EventType
+		switch {
+		case action.Kind == solana.CompressedNFTMint && action.To == wallet:
+			eventType = EventNFTMinted
+		case action.Kind == solana.CompressedNFTTransfer && action.To == wallet && action.From != wallet:
+			eventType = EventNFTReceived
+		case action.Kind == solana.CompressedNFTTransfer && action.From == wallet && action.To != wallet:
+			eventType = EventNFTSent
+		case action.Kind == solana.CompressedNFTBurn && action.From == wallet:
+			eventType = EventNFTBurned
+		default:
+			continue
+		}
+
+		m.log.Debug("Detected compressed NFT action",
+			"wallet", wallet,
+			"signature", tx.Signature,
+			"tree", action.Tree,
+		)
+
+		m.emitNFTEvent(eventType, wallet, m.compressedAsset(action), tx)
+	}
+}
+
+// compressedAsset describes the asset of a compressed NFT action, filling in
+// name and collection from DAS when available
+func (m *Monitor) compressedAsset(action solana.CompressedNFTAction) das.Asset {
+	asset := das.Asset{
+		ID:         action.AssetID,
+		Owner:      action.To,
+		Compressed: true,
+		Burnt:      action.Kind == solana.CompressedNFTBurn,
+	}
+
+	if m.das != nil && action.AssetID != "" {
+		if details, err := m.das.GetAsset(m.ctx, action.AssetID); err == nil {
+			asset = *details
+		} else {
+			m.log.Debug("Failed to look up compressed NFT", "asset", action.AssetID, "error", err)
+		}
+	}
+
+	return asset
+}
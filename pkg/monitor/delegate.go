@@ -0,0 +1,36 @@
+package monitor
+
+import (
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// TrackDelegates emits a delegate_changed event when the delegate of a
+// tracked token account or the amount it may transfer changes. An unexpected
+// approval is a common sign of a compromised wallet, so events of accounts
+// left with a delegate are warnings. Call it before Start.
+func (m *Monitor) TrackDelegates() {
+	m.delegates = true
+}
+
+// delegateChanged reports whether an update approved, revoked or changed
+// the delegate of an account or its delegated amount
+func delegateChanged(previous, account solana.TokenAccountInfo) bool {
+	return previous.Delegate != account.Delegate || previous.DelegatedAmount != account.DelegatedAmount
+}
+
+// delegateEvent describes the delegate change of an account
+func (m *Monitor) delegateEvent(previous, account solana.TokenAccountInfo) Event {
+	m.log.Warn("Token account delegate changed",
+		"wallet", account.Owner,
+		"account", account.Address,
+		"delegate", account.Delegate,
+		"delegated", account.DelegatedAmount,
+		"previous", previous.Delegate,
+	)
+
+	event := Event{Type: EventDelegateChanged, Account: account, Previous: &previous}
+	if account.Delegate != "" {
+		event.Severity = SeverityWarn
+	}
+	return event
+}
@@ -0,0 +1,125 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SetWalletDomains sets the .sol domains the wallets were given as, by
+// wallet address. They are attached to events. Call it before Start.
+func (m *Monitor) SetWalletDomains(domains map[string]string) {
+	m.domains = domains
+}
+
+// WalletDomain returns the domain a wallet was given as, which is empty if
+// it was given as an address
+func (m *Monitor) WalletDomain(wallet string) string {
+	m.registryMutex.RLock()
+	defer m.registryMutex.RUnlock()
+	return m.domains[wallet]
+}
+
+// trackedWallets returns the monitored wallets, paused or not. The
+// registry mutex guards them and the settings keyed by wallet, which
+// ReplaceWallet changes while the monitor runs.
+func (m *Monitor) trackedWallets() []string {
+	m.registryMutex.RLock()
+	defer m.registryMutex.RUnlock()
+	return m.wallets
+}
+
+// ReplaceWallet monitors current in place of previous, such as when the
+// domain a wallet was given as changes owner. The label, token filter,
+// domain, hot path and pause of previous carry over. Its token accounts are
+// dropped without events and those of current are loaded like at startup,
+// within ctx.
+func (m *Monitor) ReplaceWallet(ctx context.Context, previous, current string) error {
+	m.registryMutex.Lock()
+	index := -1
+	for i, wallet := range m.wallets {
+		if wallet == current {
+			m.registryMutex.Unlock()
+			return fmt.Errorf("wallet %s is already tracked", current)
+		}
+		if wallet == previous {
+			index = i
+		}
+	}
+	if index < 0 {
+		m.registryMutex.Unlock()
+		return ErrUnknownWallet
+	}
+
+	// Callers iterate over the wallets without the lock, so swap in a copy
+	wallets := append([]string(nil), m.wallets...)
+	wallets[index] = current
+	m.wallets = wallets
+	if tokens, ok := m.walletTokens[previous]; ok {
+		delete(m.walletTokens, previous)
+		m.walletTokens[current] = tokens
+	}
+	if label, ok := m.labels[previous]; ok {
+		delete(m.labels, previous)
+		m.labels[current] = label
+	}
+	if domain, ok := m.domains[previous]; ok {
+		delete(m.domains, previous)
+		m.domains[current] = domain
+	}
+	if client, ok := m.hot[previous]; ok {
+		delete(m.hot, previous)
+		m.hot[current] = client
+	}
+	m.registryMutex.Unlock()
+
+	m.walletMutex.Lock()
+	paused := m.paused[previous]
+	delete(m.paused, previous)
+	if paused {
+		m.paused[current] = true
+	}
+	cancel := m.walletCancels[previous]
+	delete(m.walletCancels, previous)
+	m.walletMutex.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+
+	m.stateMutex.Lock()
+	for key := range m.state {
+		if strings.HasPrefix(key, previous+":") {
+			delete(m.state, key)
+			delete(m.held, key)
+			delete(m.zeroSince, key)
+			delete(m.pruned, key)
+		}
+	}
+	delete(m.nfts, previous)
+	m.stateMutex.Unlock()
+
+	m.log.Info("Wallet replaced",
+		"previous", previous,
+		"wallet", current,
+	)
+
+	if paused {
+		return nil
+	}
+	if !m.watchOnly {
+		m.loadWallet(ctx, current)
+	}
+	m.startWallet(current)
+	return nil
+}
+
+// loadWallet loads the token accounts of a wallet as its initial state
+func (m *Monitor) loadWallet(ctx context.Context, wallet string) {
+	accounts, err := m.source.GetTokenAccounts(ctx, wallet)
+	if err != nil {
+		m.log.Error("Failed to load token accounts", "wallet", wallet, "error", err)
+		return
+	}
+	m.recordPoll(wallet)
+	m.applySnapshot(ctx, wallet, accounts, true)
+}
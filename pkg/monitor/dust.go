@@ -0,0 +1,83 @@
+package monitor
+
+import (
+	"context"
+	"math"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/price"
+)
+
+// Filter decides whether an event is dispatched
+type Filter func(ctx context.Context, event Event) bool
+
+// DustThreshold sets the smallest balances and changes worth an event, in UI
+// units or USD. Zero values don't filter.
+type DustThreshold struct {
+	MinBalance    float64
+	MinChange     float64
+	MinBalanceUSD float64
+	MinChangeUSD  float64
+}
+
+// DustThresholds holds the global threshold and per-mint overrides
+type DustThresholds struct {
+	Default DustThreshold
+	Mints   map[string]DustThreshold
+}
+
+// RegisterFilter registers a filter that can drop events before they are
+// enriched and dispatched
+func (m *Monitor) RegisterFilter(filter Filter) {
+	m.Use(PhaseFilter, FilterStage(filter))
+}
+
+// DustFilter drops token balance events of accounts that hold dust before
+// and after the change, and events whose change is too small to matter. USD
+// thresholds are skipped for mints without a price; prices may be nil when
+// only UI unit thresholds are set.
+func DustFilter(thresholds DustThresholds, prices *price.Cache) Filter {
+	return func(ctx context.Context, event Event) bool {
+		if event.Type != EventBalanceChanged && event.Type != EventNewHolding {
+			return true
+		}
+
+		threshold, ok := thresholds.Mints[event.Account.Mint]
+		if !ok {
+			threshold = thresholds.Default
+		}
+
+		balance := uiAmount(event.Account.Balance, event.Account.Decimals)
+		previous := 0.0
+		if event.Previous != nil {
+			previous = uiAmount(event.Previous.Balance, event.Previous.Decimals)
+		}
+		change := math.Abs(balance - previous)
+
+		if isDust(balance, previous, change, threshold.MinBalance, threshold.MinChange) {
+			return false
+		}
+
+		if (threshold.MinBalanceUSD > 0 || threshold.MinChangeUSD > 0) && prices != nil {
+			if usd, known := prices.Price(ctx, event.Account.Mint); known {
+				if isDust(balance*usd, previous*usd, change*usd, threshold.MinBalanceUSD, threshold.MinChangeUSD) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+}
+
+// isDust reports whether both balances are below minBalance or the change is
+// below minChange
+func isDust(balance, previous, change, minBalance, minChange float64) bool {
+	if minBalance > 0 && balance < minBalance && previous < minBalance {
+		return true
+	}
+	return minChange > 0 && change < minChange
+}
+
+// uiAmount converts a raw token amount to UI units
+func uiAmount(amount uint64, decimals uint8) float64 {
+	return float64(amount) / math.Pow10(int(decimals))
+}
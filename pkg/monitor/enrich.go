@@ -0,0 +1,285 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/anomaly"
+	"github.com/yourusername/solana-wallet-tracker/pkg/entity"
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+	"github.com/yourusername/solana-wallet-tracker/pkg/price"
+	"github.com/yourusername/solana-wallet-tracker/pkg/risk"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// Enricher adds information to an event before it is dispatched
+type Enricher func(ctx context.Context, event *Event)
+
+// MetadataEnricher attaches Metaplex token metadata to new holding events
+func MetadataEnricher(client *solana.Client) Enricher {
+	return func(ctx context.Context, event *Event) {
+		if event.Type != EventNewHolding {
+			return
+		}
+
+		metadata, err := client.GetTokenMetadata(ctx, event.Account.Mint)
+		if err != nil {
+			logging.FromContext(ctx).Debug("No metadata for mint", "mint", event.Account.Mint, "error", err)
+			return
+		}
+		event.Metadata = metadata
+	}
+}
+
+// RugChecks configures the checks of RugCheckEnricher beyond the mint and
+// freeze authorities
+type RugChecks struct {
+	// Quotes probes the liquidity of mints; nil skips the probe
+	Quotes *price.QuoteClient
+	// ProbeUSD is the amount of USDC whose swap into the mint is quoted
+	ProbeUSD float64
+	// MaxPriceImpact is the price impact in percent of the probe above
+	// which liquidity is low
+	MaxPriceImpact float64
+	// MaxTopHoldersShare is the percent of the supply the ten largest token
+	// accounts may hold before the mint counts as concentrated
+	MaxTopHoldersShare float64
+}
+
+// Defaults for zero rug check options
+const (
+	DefaultProbeUSD           = 1000
+	DefaultMaxPriceImpact     = 5
+	DefaultMaxTopHoldersShare = 50
+)
+
+// topHolders is how many of the largest token accounts count towards the
+// concentration of a mint
+const topHolders = 10
+
+// usdcDecimals is the number of decimals of USDC
+const usdcDecimals = 6
+
+// riskWeights are the shares of the risk score of each flag
+var riskWeights = map[string]int{
+	RiskMintAuthority:   30,
+	RiskFreezeAuthority: 20,
+	RiskNoLiquidity:     30,
+	RiskLowLiquidity:    25,
+	RiskConcentrated:    20,
+}
+
+// RugCheckEnricher attaches mint authority, liquidity and holder
+// concentration findings with a risk score to new holding events. Checks
+// that fail are logged and left out of the score.
+func RugCheckEnricher(client *solana.Client, checks RugChecks) Enricher {
+	if checks.ProbeUSD == 0 {
+		checks.ProbeUSD = DefaultProbeUSD
+	}
+	if checks.MaxPriceImpact == 0 {
+		checks.MaxPriceImpact = DefaultMaxPriceImpact
+	}
+	if checks.MaxTopHoldersShare == 0 {
+		checks.MaxTopHoldersShare = DefaultMaxTopHoldersShare
+	}
+
+	return func(ctx context.Context, event *Event) {
+		if event.Type != EventNewHolding {
+			return
+		}
+
+		mintInfo, err := client.GetCachedMintInfo(ctx, event.Account.Mint)
+		if err != nil {
+			logging.FromContext(ctx).Warn("Failed to run rug check", "mint", event.Account.Mint, "error", err)
+			return
+		}
+
+		report := &RiskReport{
+			MintAuthority:   mintInfo.MintAuthority,
+			FreezeAuthority: mintInfo.FreezeAuthority,
+		}
+		if mintInfo.MintAuthority != "" {
+			report.Flags = append(report.Flags, RiskMintAuthority)
+		}
+		if mintInfo.FreezeAuthority != "" {
+			report.Flags = append(report.Flags, RiskFreezeAuthority)
+		}
+		if checks.Quotes != nil && event.Account.Mint != price.USDCMint {
+			checkLiquidity(ctx, checks, event.Account.Mint, report)
+		}
+		checkConcentration(ctx, client, checks, mintInfo, report)
+
+		for _, flag := range report.Flags {
+			report.Score += riskWeights[flag]
+		}
+		if report.Score > 100 {
+			report.Score = 100
+		}
+		event.Risk = report
+	}
+}
+
+// checkLiquidity quotes a swap of USDC into a mint and flags it when the
+// price moves too much or no route exists
+func checkLiquidity(ctx context.Context, checks RugChecks, mint string, report *RiskReport) {
+	amount := uint64(checks.ProbeUSD * math.Pow10(usdcDecimals))
+	impact, err := checks.Quotes.PriceImpact(ctx, price.USDCMint, mint, amount)
+	if errors.Is(err, price.ErrNoRoute) {
+		report.Flags = append(report.Flags, RiskNoLiquidity)
+		return
+	}
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to probe liquidity", "mint", mint, "error", err)
+		return
+	}
+
+	report.PriceImpact = &impact
+	if impact > checks.MaxPriceImpact {
+		report.Flags = append(report.Flags, RiskLowLiquidity)
+	}
+}
+
+// checkConcentration flags a mint whose largest token accounts hold too
+// much of its supply
+func checkConcentration(ctx context.Context, client *solana.Client, checks RugChecks,
+	mintInfo *solana.MintInfo, report *RiskReport) {
+	if mintInfo.Supply == 0 {
+		return
+	}
+	largest, err := client.GetLargestTokenAccounts(ctx, mintInfo.Address)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to get largest holders", "mint", mintInfo.Address, "error", err)
+		return
+	}
+
+	sort.Slice(largest, func(i, j int) bool { return largest[i].Balance > largest[j].Balance })
+	var held float64
+	for i := 0; i < len(largest) && i < topHolders; i++ {
+		held += float64(largest[i].Balance)
+	}
+	share := held / float64(mintInfo.Supply) * 100
+
+	report.TopHoldersShare = &share
+	if share > checks.MaxTopHoldersShare {
+		report.Flags = append(report.Flags, RiskConcentrated)
+	}
+}
+
+// TransferEnricher attaches the transaction and counterparties behind token
+// balance changes
+func TransferEnricher(client *solana.Client) Enricher {
+	return func(ctx context.Context, event *Event) {
+		if event.Type != EventBalanceChanged && event.Type != EventNewHolding {
+			return
+		}
+		if event.Previous == nil && event.Type != EventNewHolding {
+			return
+		}
+
+		transfer, err := client.ResolveTransfer(ctx, event.Account)
+		if err != nil {
+			logging.FromContext(ctx).Debug("Failed to resolve transfer", "account", event.Account.Address, "error", err)
+			return
+		}
+		event.Transfer = transfer
+		if event.Account.Signature == "" && (event.Account.Slot == 0 || transfer.Slot <= event.Account.Slot) {
+			event.Account.Signature = transfer.Signature
+		}
+	}
+}
+
+// EntityEnricher names the counterparties of transfers that are known
+// entities, looked up by owner and then by token account. Register it after
+// TransferEnricher.
+func EntityEnricher(directory *entity.Directory) Enricher {
+	return func(ctx context.Context, event *Event) {
+		if event.Transfer == nil {
+			return
+		}
+		for i := range event.Transfer.Counterparties {
+			counterparty := &event.Transfer.Counterparties[i]
+			known, ok := directory.Lookup(counterparty.Owner)
+			if !ok {
+				known, ok = directory.Lookup(counterparty.Account)
+			}
+			if ok {
+				counterparty.Name = known.Name
+				counterparty.Kind = known.Kind
+			}
+		}
+	}
+}
+
+// SignatureEnricher attaches the signature of the transaction behind token
+// balance changes. The latest transaction of the account is taken as the
+// cause unless it landed after the slot the change was observed at. Register
+// it after TransferEnricher, which resolves the same transaction.
+func SignatureEnricher(source solana.DataSource) Enricher {
+	return func(ctx context.Context, event *Event) {
+		if event.Type != EventBalanceChanged && event.Type != EventNewHolding {
+			return
+		}
+		if event.Account.Signature != "" {
+			return
+		}
+
+		signatures, err := source.GetTransactions(ctx, event.Account.Address, 1)
+		if err != nil {
+			logging.FromContext(ctx).Debug("Failed to resolve signature", "account", event.Account.Address, "error", err)
+			return
+		}
+		if len(signatures) == 0 {
+			return
+		}
+		if event.Account.Slot != 0 && signatures[0].Slot > event.Account.Slot {
+			return
+		}
+		event.Account.Signature = signatures[0].Signature
+	}
+}
+
+// WalletRiskEnricher feeds token balance changes to a risk scorer and
+// attaches the resulting wallet score to every event. Register it after
+// TransferEnricher so exchange outflows can be detected.
+func WalletRiskEnricher(scorer *risk.Scorer) Enricher {
+	return func(ctx context.Context, event *Event) {
+		if event.Type == EventBalanceChanged || event.Type == EventNewHolding {
+			scorer.Observe(event.Account, event.Previous, event.Transfer)
+		}
+
+		score := scorer.Score(event.Account.Owner)
+		event.WalletRisk = &score
+	}
+}
+
+// AnomalyEnricher checks token balance changes against the transfer sizes
+// the detector learned for the wallet and mint, and marks outliers with the
+// anomaly severity
+func AnomalyEnricher(detector *anomaly.Detector) Enricher {
+	return func(ctx context.Context, event *Event) {
+		if event.Type != EventBalanceChanged || event.Previous == nil {
+			return
+		}
+
+		finding, err := detector.Observe(ctx, event.Account.Owner, event.Account.Mint,
+			uiAmount(event.Previous.Balance, event.Previous.Decimals),
+			uiAmount(event.Account.Balance, event.Account.Decimals))
+		if err != nil {
+			logging.FromContext(ctx).Warn("Failed to check transfer for anomalies", "account", event.Account.Address, "error", err)
+		}
+		if finding == nil {
+			return
+		}
+
+		logging.FromContext(ctx).Warn("Anomalous transfer detected",
+			"wallet", event.Account.Owner,
+			"mint", event.Account.Mint,
+			"amount", finding.Amount,
+			"reasons", finding.Reasons,
+		)
+		event.Severity = SeverityAnomaly
+		event.Anomaly = finding
+	}
+}
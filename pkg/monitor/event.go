@@ -0,0 +1,178 @@
+package monitor
+
+import (
+	"github.com/yourusername/solana-wallet-tracker/pkg/anomaly"
+	"github.com/yourusername/solana-wallet-tracker/pkg/das"
+	"github.com/yourusername/solana-wallet-tracker/pkg/risk"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+	"github.com/yourusername/solana-wallet-tracker/pkg/telemetry"
+)
+
+// EventType identifies the kind of change detected by the monitor
+type EventType string
+
+// Event types
+const (
+	// EventBalanceChanged is emitted when a tracked token account balance changes
+	EventBalanceChanged EventType = "balance_changed"
+	// EventNewHolding is emitted when a wallet receives a mint it has never held before
+	EventNewHolding EventType = "new_holding"
+	// EventLargeHolderMove is emitted when a holder of a watched mint above
+	// the configured threshold moves tokens
+	EventLargeHolderMove EventType = "large_holder_move"
+	// EventStakeDelegationChanged is emitted when a stake account is created,
+	// redelegated or its delegated amount changes
+	EventStakeDelegationChanged EventType = "stake_delegation_changed"
+	// EventStakeActivated is emitted when a delegation becomes active
+	EventStakeActivated EventType = "stake_activated"
+	// EventStakeDeactivated is emitted when a delegation starts deactivating
+	EventStakeDeactivated EventType = "stake_deactivated"
+	// EventStakeReward is emitted when an epoch reward is credited
+	EventStakeReward EventType = "stake_reward"
+	// EventNFTReceived is emitted when an NFT or compressed NFT arrives in a wallet
+	EventNFTReceived EventType = "nft_received"
+	// EventNFTSent is emitted when an NFT leaves a wallet
+	EventNFTSent EventType = "nft_sent"
+	// EventNFTListed is emitted when an NFT is delegated or frozen for a listing
+	EventNFTListed EventType = "nft_listed"
+	// EventNFTBurned is emitted when a held NFT is burned
+	EventNFTBurned EventType = "nft_burned"
+	// EventNFTMinted is emitted when a compressed NFT is minted to a wallet
+	EventNFTMinted EventType = "nft_minted"
+	// EventGroupThreshold is emitted when a total over the wallets of a group
+	// crosses the threshold of a group rule
+	EventGroupThreshold EventType = "group_threshold"
+	// EventWalletStale is emitted when a wallet received no subscription
+	// notification and no successful poll within the staleness window. Its
+	// Account carries the wallet as Address and Owner, without a mint.
+	EventWalletStale EventType = "wallet_stale"
+	// EventTokenBurned is emitted instead of a balance change when a tracked
+	// account burned tokens
+	EventTokenBurned EventType = "token_burned"
+	// EventDelegateChanged is emitted when the delegate of a tracked token
+	// account or its delegated amount changes. Previous holds the former
+	// delegate.
+	EventDelegateChanged EventType = "delegate_changed"
+	// EventAuthorityChanged is emitted when a tracked token account changes
+	// owner or close authority, or a wallet is assigned to another program.
+	// Authority describes the change.
+	EventAuthorityChanged EventType = "authority_changed"
+	// EventAccountFrozen is emitted when the freeze authority of a mint
+	// freezes a tracked token account
+	EventAccountFrozen EventType = "account_frozen"
+	// EventAccountThawed is emitted when a frozen tracked token account is
+	// thawed
+	EventAccountThawed EventType = "account_thawed"
+	// EventAccountClosed is emitted when a tracked token account is closed.
+	// Previous holds the account as last seen.
+	EventAccountClosed EventType = "account_closed"
+	// EventSignature is emitted for every transaction mentioning a wallet
+	// by monitors in watch-only mode. Its Account carries the wallet as
+	// Address and Owner, without a mint.
+	EventSignature EventType = "signature"
+)
+
+// Severities of events, lowest first. Events without one are info.
+const (
+	SeverityInfo = "info"
+	SeverityWarn = "warn"
+	// SeverityAnomaly marks transfers far outside the wallet's usual sizes
+	SeverityAnomaly  = "anomaly"
+	SeverityCritical = "critical"
+)
+
+// severityRanks orders the severities
+var severityRanks = map[string]int{
+	SeverityInfo:     0,
+	SeverityWarn:     1,
+	SeverityAnomaly:  2,
+	SeverityCritical: 3,
+}
+
+// SeverityRank orders severities from info (0) to critical. Empty and
+// unknown severities rank as info.
+func SeverityRank(severity string) int {
+	return severityRanks[severity]
+}
+
+// Event describes a change detected by the monitor
+type Event struct {
+	Type     EventType                `json:"type"`
+	Account  solana.TokenAccountInfo  `json:"account"`
+	Previous *solana.TokenAccountInfo `json:"previous,omitempty"`
+	Metadata *solana.TokenMetadata    `json:"metadata,omitempty"`
+	Risk     *RiskReport              `json:"risk,omitempty"`
+	Stake    *StakeEvent              `json:"stake,omitempty"`
+	NFT      *das.Asset               `json:"nft,omitempty"`
+	Transfer *solana.Transfer         `json:"transfer,omitempty"`
+	// Group is the crossed total of group threshold events, whose Account is
+	// the balance change that crossed it
+	Group *GroupTotal `json:"group,omitempty"`
+	// Stale describes the wallet of wallet stale events
+	Stale *StaleWallet `json:"stale,omitempty"`
+	// Burn is the amount burned of token burned events
+	Burn *BurnEvent `json:"burn,omitempty"`
+	// Authority is the change of authority changed events
+	Authority *AuthorityChange `json:"authority,omitempty"`
+	// Freeze names the freeze authority of account frozen and thawed events
+	Freeze *FreezeEvent `json:"freeze,omitempty"`
+	// Signature is the transaction of signature events
+	Signature *SignatureEvent `json:"signature,omitempty"`
+	// WalletRisk is the risk score of the wallet after this event
+	WalletRisk *risk.Score `json:"wallet_risk,omitempty"`
+	// Severity is assigned by severity rules and the anomaly detector, whose
+	// finding is Anomaly
+	Severity string           `json:"severity,omitempty"`
+	Anomaly  *anomaly.Finding `json:"anomaly,omitempty"`
+	// Label and Groups come from the configured label of the account owner
+	Label  string   `json:"label,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+	// Tenants are the tenants whose watchlists hold the account owner
+	Tenants []string `json:"tenants,omitempty"`
+	// Domain is the .sol domain the account owner was configured as
+	Domain string `json:"domain,omitempty"`
+	// Network is the network of the wallet, when the tracker names them
+	Network string `json:"network,omitempty"`
+	// Hot marks events of hot wallets, which skip enrichment and should be
+	// delivered without delay
+	Hot bool `json:"hot,omitempty"`
+	// Replayed marks past events rebuilt from stored history
+	Replayed bool `json:"replayed,omitempty"`
+	// Suppressed counts the earlier events of the wallet and mint the
+	// notification cooldown held back since the last one delivered
+	Suppressed int `json:"suppressed,omitempty"`
+	// Trace is the span of the update behind the event, so its handling and
+	// delivery continue the trace. It isn't serialized.
+	Trace telemetry.SpanContext `json:"-"`
+}
+
+// RiskReport summarizes rug-check findings for a mint
+type RiskReport struct {
+	MintAuthority   string `json:"mint_authority,omitempty"`
+	FreezeAuthority string `json:"freeze_authority,omitempty"`
+	// PriceImpact is the price impact in percent of the liquidity probe,
+	// unset when it wasn't run or the mint can't be traded
+	PriceImpact *float64 `json:"price_impact,omitempty"`
+	// TopHoldersShare is the percent of the supply held by the ten largest
+	// token accounts
+	TopHoldersShare *float64 `json:"top_holders_share,omitempty"`
+	Flags           []string `json:"flags,omitempty"`
+	// Score weighs the flags from 0 to 100, higher is riskier
+	Score int `json:"score"`
+}
+
+// Risk flags
+const (
+	RiskMintAuthority   = "mint_authority_not_renounced"
+	RiskFreezeAuthority = "freeze_authority_present"
+	RiskLowLiquidity    = "low_liquidity"
+	RiskNoLiquidity     = "no_liquidity"
+	RiskConcentrated    = "top_holders_concentrated"
+)
+
+// EventHandler is a function that handles monitor events
+type EventHandler func(event Event)
+
+// HoldingLookup reports whether a wallet has held a mint before, typically
+// backed by persisted history so new holdings survive restarts
+type HoldingLookup func(owner, mint string) bool
@@ -0,0 +1,57 @@
+package monitor
+
+import (
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// FreezeEvent describes the freeze authority behind an account_frozen or
+// account_thawed event
+type FreezeEvent struct {
+	// FreezeAuthority is the freeze authority of the mint, when it could be
+	// looked up
+	FreezeAuthority string `json:"freeze_authority,omitempty"`
+}
+
+// TrackFreezes emits account_frozen and account_thawed events when the
+// freeze authority of a mint freezes or thaws a tracked token account.
+// Frozen funds can't be moved, so freezes are critical, except for accounts
+// with a delegate, which marketplaces freeze while an NFT is listed and are
+// warnings. Call it before Start.
+func (m *Monitor) TrackFreezes() {
+	m.freezes = true
+}
+
+// freezeEvent describes the freeze or thaw of an account
+func (m *Monitor) freezeEvent(previous, account solana.TokenAccountInfo) Event {
+	event := Event{Type: EventAccountThawed, Account: account, Previous: &previous}
+	if account.Frozen {
+		event.Type = EventAccountFrozen
+		event.Severity = SeverityCritical
+		if account.Delegate != "" {
+			event.Severity = SeverityWarn
+		}
+	}
+
+	m.log.Warn("Token account freeze state changed",
+		"wallet", account.Owner,
+		"account", account.Address,
+		"mint", account.Mint,
+		"frozen", account.Frozen,
+	)
+	return event
+}
+
+// emitFreeze delivers a freeze event with the freeze authority of its mint,
+// which is looked up outside of the state lock
+func (m *Monitor) emitFreeze(event Event) {
+	m.deliver(event.Account.Owner, func() {
+		event.Freeze = &FreezeEvent{}
+		mint, err := m.client.GetCachedMintInfo(m.ctx, event.Account.Mint)
+		if err != nil {
+			m.log.Warn("Failed to get freeze authority", "mint", event.Account.Mint, "error", err)
+		} else {
+			event.Freeze.FreezeAuthority = mint.FreezeAuthority
+		}
+		m.dispatchEvent(event)
+	})
+}
@@ -0,0 +1,257 @@
+package monitor
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/price"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// GroupRule reports when a total over the wallets of a group crosses a
+// threshold. With a mint the group's balance of that token is totalled in UI
+// units; without one the USD value of all its tracked tokens is.
+type GroupRule struct {
+	// Group is empty for a total over every tracked wallet
+	Group string
+	Mint  string
+	// Below and Above are the thresholds; zero doesn't check
+	Below float64
+	Above float64
+}
+
+// Directions a group total crosses a threshold in
+const (
+	CrossedBelow = "below"
+	CrossedAbove = "above"
+)
+
+// GroupTotal is a group total that crossed the threshold of a rule. Group is
+// empty for totals over every tracked wallet.
+type GroupTotal struct {
+	Group string `json:"group"`
+	// Mint is empty for totals in USD
+	Mint      string  `json:"mint,omitempty"`
+	Total     float64 `json:"total"`
+	Previous  float64 `json:"previous"`
+	Crossed   string  `json:"crossed"`
+	Threshold float64 `json:"threshold"`
+	Wallets   int     `json:"wallets"`
+}
+
+// GroupBalance is the balance of a mint summed over the wallets of a group.
+// Balance is a big integer, encoded as a JSON number, as the sum of u64
+// balances can overflow a uint64.
+type GroupBalance struct {
+	Mint     string   `json:"mint"`
+	Balance  *big.Int `json:"balance"`
+	Decimals uint8    `json:"decimals"`
+	Amount   float64  `json:"amount"`
+	// ValueUSD is nil when no price is known for the mint
+	ValueUSD *float64 `json:"value_usd,omitempty"`
+}
+
+// GroupSummary totals the tracked token balances of the wallets of a group,
+// or of every tracked wallet when Group is empty
+type GroupSummary struct {
+	Group    string         `json:"group,omitempty"`
+	Wallets  []string       `json:"wallets"`
+	Balances []GroupBalance `json:"balances"`
+	// TotalUSD covers the mints with a known price
+	TotalUSD float64 `json:"total_usd"`
+}
+
+// Total returns the group's balance of a mint in UI units, or its USD value
+// without a mint
+func (s GroupSummary) Total(mint string) float64 {
+	if mint == "" {
+		return s.TotalUSD
+	}
+	for _, balance := range s.Balances {
+		if balance.Mint == mint {
+			return balance.Amount
+		}
+	}
+	return 0
+}
+
+// groupWatch follows the total of one group rule
+type groupWatch struct {
+	rule  GroupRule
+	total float64
+	below bool
+	above bool
+}
+
+// SetPrices sets the prices group totals are valued with. Without them
+// totals in USD stay zero. Call it before Start.
+func (m *Monitor) SetPrices(prices *price.Cache) {
+	m.prices = prices
+}
+
+// AddGroupRule registers a rule on a group total. Totals present when the
+// monitor starts are the baseline; only later crossings emit events.
+func (m *Monitor) AddGroupRule(rule GroupRule) {
+	m.groupWatches = append(m.groupWatches, &groupWatch{rule: rule})
+}
+
+// GroupSummary totals the current balances of the wallets tagged with a
+// group, paused or not, or of every tracked wallet for an empty group
+func (m *Monitor) GroupSummary(ctx context.Context, group string) GroupSummary {
+	m.stateMutex.RLock()
+	summary := m.groupBalances(group)
+	m.stateMutex.RUnlock()
+	m.valueGroup(ctx, &summary)
+	return summary
+}
+
+// groupBalances sums the balances of the wallets of a group by mint. The
+// caller holds the state mutex.
+func (m *Monitor) groupBalances(group string) GroupSummary {
+	summary := GroupSummary{Group: group, Wallets: []string{}, Balances: []GroupBalance{}}
+	members := make(map[string]bool)
+	for _, wallet := range m.trackedWallets() {
+		if group == "" || m.WalletLabel(wallet).InGroup(group) {
+			summary.Wallets = append(summary.Wallets, wallet)
+			members[wallet] = true
+		}
+	}
+
+	balances := make(map[string]int)
+	for _, account := range m.state {
+		if !members[account.Owner] {
+			continue
+		}
+		i, ok := balances[account.Mint]
+		if !ok {
+			i = len(summary.Balances)
+			balances[account.Mint] = i
+			summary.Balances = append(summary.Balances, GroupBalance{Mint: account.Mint, Balance: new(big.Int), Decimals: account.Decimals})
+		}
+		balance := summary.Balances[i].Balance
+		balance.Add(balance, new(big.Int).SetUint64(account.Balance))
+	}
+	for i := range summary.Balances {
+		balance := &summary.Balances[i]
+		balance.Amount = solana.BigUIAmount(balance.Balance, balance.Decimals)
+	}
+	sort.Slice(summary.Balances, func(a, b int) bool {
+		return summary.Balances[a].Mint < summary.Balances[b].Mint
+	})
+	return summary
+}
+
+// valueGroup prices the balances of a group summary and totals them in USD
+func (m *Monitor) valueGroup(ctx context.Context, summary *GroupSummary) {
+	if m.prices == nil {
+		return
+	}
+	for i := range summary.Balances {
+		balance := &summary.Balances[i]
+		if balance.Balance.Sign() == 0 {
+			continue
+		}
+		if usd, known := m.prices.Price(ctx, balance.Mint); known {
+			value := balance.Amount * usd
+			balance.ValueUSD = &value
+			summary.TotalUSD += value
+		}
+	}
+}
+
+// baselineGroupRules records the totals the rules start from
+func (m *Monitor) baselineGroupRules() {
+	for _, watch := range m.groupWatches {
+		summary := m.GroupSummary(m.ctx, watch.rule.Group)
+		m.groupMutex.Lock()
+		watch.update(summary.Total(watch.rule.Mint), len(summary.Wallets), true)
+		m.groupMutex.Unlock()
+	}
+}
+
+// groupSnapshots sums the balances of the groups whose rules a change of
+// account concerns. It runs under the state mutex, so each change is checked
+// against the balances it left rather than those of later changes.
+func (m *Monitor) groupSnapshots(account solana.TokenAccountInfo) map[string]GroupSummary {
+	label := m.WalletLabel(account.Owner)
+	snapshots := make(map[string]GroupSummary)
+	for _, watch := range m.groupWatches {
+		group := watch.rule.Group
+		if _, done := snapshots[group]; done || (group != "" && !label.InGroup(group)) {
+			continue
+		}
+		if watch.rule.Mint != "" && watch.rule.Mint != account.Mint {
+			continue
+		}
+		snapshots[group] = m.groupBalances(group)
+	}
+	return snapshots
+}
+
+// checkGroupRules evaluates group rules against the snapshots taken after a
+// balance change of account and dispatches an event for every threshold
+// crossed
+func (m *Monitor) checkGroupRules(account solana.TokenAccountInfo, snapshots map[string]GroupSummary) {
+	valued := make(map[string]bool)
+	for _, watch := range m.groupWatches {
+		summary, ok := snapshots[watch.rule.Group]
+		if !ok || (watch.rule.Mint != "" && watch.rule.Mint != account.Mint) {
+			continue
+		}
+		if watch.rule.Mint == "" && !valued[watch.rule.Group] {
+			m.valueGroup(m.ctx, &summary)
+			snapshots[watch.rule.Group] = summary
+			valued[watch.rule.Group] = true
+		}
+
+		m.groupMutex.Lock()
+		crossed := watch.update(summary.Total(watch.rule.Mint), len(summary.Wallets), false)
+		m.groupMutex.Unlock()
+
+		for _, total := range crossed {
+			total := total
+			m.log.Info("Group total crossed threshold",
+				"group", total.Group,
+				"mint", total.Mint,
+				"total", total.Total,
+				"crossed", total.Crossed,
+				"threshold", total.Threshold,
+			)
+			m.dispatchEvent(Event{Type: EventGroupThreshold, Account: account, Group: &total})
+		}
+	}
+}
+
+// update records a new total and returns the thresholds it crossed. The
+// initial total only sets which side of the thresholds the group is on.
+func (w *groupWatch) update(total float64, wallets int, initial bool) []GroupTotal {
+	previous := w.total
+	w.total = total
+
+	var crossed []GroupTotal
+	report := func(direction string, threshold float64) {
+		crossed = append(crossed, GroupTotal{
+			Group:     w.rule.Group,
+			Mint:      w.rule.Mint,
+			Total:     total,
+			Previous:  previous,
+			Crossed:   direction,
+			Threshold: threshold,
+			Wallets:   wallets,
+		})
+	}
+
+	below := w.rule.Below > 0 && total < w.rule.Below
+	if below && !w.below && !initial {
+		report(CrossedBelow, w.rule.Below)
+	}
+	w.below = below
+
+	above := w.rule.Above > 0 && total > w.rule.Above
+	if above && !w.above && !initial {
+		report(CrossedAbove, w.rule.Above)
+	}
+	w.above = above
+	return crossed
+}
@@ -0,0 +1,206 @@
+package monitor
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/telemetry"
+)
+
+// maxOverdueCalls is how many calls of a handler may run past the handler
+// timeout before further calls are skipped, so a hung handler doesn't pile
+// up goroutines
+const maxOverdueCalls = 10
+
+// balanceHandler is a registered balance change handler
+type balanceHandler struct {
+	name string
+	fn   BalanceChangeHandler
+}
+
+// eventHandler is a registered event handler
+type eventHandler struct {
+	name string
+	fn   EventHandler
+}
+
+// Kinds of handlers
+const (
+	HandlerBalance = "balance"
+	HandlerEvent   = "event"
+)
+
+// HandlerState is whether a registered handler is called
+type HandlerState struct {
+	Name string `json:"name"`
+	// Kind is balance or event
+	Kind    string `json:"kind"`
+	Enabled bool   `json:"enabled"`
+}
+
+// handlerGuard tracks the calls of each handler still running past the
+// handler timeout, and the handlers turned off
+type handlerGuard struct {
+	mu       sync.Mutex
+	overdue  map[string]int
+	disabled map[string]bool
+}
+
+// RegisterNamedHandler registers a balance change handler under a name,
+// which the logs of its panics and timeouts refer to. Handlers must be
+// registered before Start.
+func (m *Monitor) RegisterNamedHandler(name string, handler BalanceChangeHandler) error {
+	if handler == nil {
+		return ErrNilHandler
+	}
+	if m.isRunning() {
+		return ErrStarted
+	}
+	m.handlers = append(m.handlers, balanceHandler{name: name, fn: handler})
+	return nil
+}
+
+// RegisterNamedEventHandler registers an event handler under a name, which
+// the logs of its panics and timeouts refer to. Handlers must be registered
+// before Start.
+func (m *Monitor) RegisterNamedEventHandler(name string, handler EventHandler) error {
+	if handler == nil {
+		return ErrNilHandler
+	}
+	if m.isRunning() {
+		return ErrStarted
+	}
+	m.eventHandlers = append(m.eventHandlers, eventHandler{name: name, fn: handler})
+	return nil
+}
+
+// SetHandlerTimeout bounds every handler call. A call running longer is
+// logged and left behind so the delivery moves on; once a handler has
+// maxOverdueCalls calls past the timeout, its further calls are skipped
+// until they return. Zero, the default, waits for every call. Call it
+// before Start.
+func (m *Monitor) SetHandlerTimeout(timeout time.Duration) {
+	m.handlerTimeout = timeout
+}
+
+// SetHandlerEnabled turns the handlers registered under a name on or off.
+// Events reaching a handler while it is off are skipped, not queued.
+func (m *Monitor) SetHandlerEnabled(name string, enabled bool) error {
+	known := false
+	for _, handler := range m.handlers {
+		known = known || handler.name == name
+	}
+	for _, handler := range m.eventHandlers {
+		known = known || handler.name == name
+	}
+	if !known {
+		return fmt.Errorf("%w: %s", ErrUnknownHandler, name)
+	}
+
+	m.guard.mu.Lock()
+	defer m.guard.mu.Unlock()
+	if enabled {
+		delete(m.guard.disabled, name)
+		return nil
+	}
+	if m.guard.disabled == nil {
+		m.guard.disabled = make(map[string]bool)
+	}
+	m.guard.disabled[name] = true
+	return nil
+}
+
+// Handlers returns the registered handlers in the order they were
+// registered, balance handlers first
+func (m *Monitor) Handlers() []HandlerState {
+	m.guard.mu.Lock()
+	defer m.guard.mu.Unlock()
+	states := make([]HandlerState, 0, len(m.handlers)+len(m.eventHandlers))
+	for _, handler := range m.handlers {
+		states = append(states, HandlerState{Name: handler.name, Kind: HandlerBalance, Enabled: !m.guard.disabled[handler.name]})
+	}
+	for _, handler := range m.eventHandlers {
+		states = append(states, HandlerState{Name: handler.name, Kind: HandlerEvent, Enabled: !m.guard.disabled[handler.name]})
+	}
+	return states
+}
+
+// handlerEnabled reports whether a handler is on
+func (m *Monitor) handlerEnabled(name string) bool {
+	m.guard.mu.Lock()
+	defer m.guard.mu.Unlock()
+	return !m.guard.disabled[name]
+}
+
+// callHandler calls a handler, recovering from its panics and giving up on
+// it after the handler timeout
+func (m *Monitor) callHandler(name string, call func()) {
+	if m.handlerTimeout <= 0 {
+		m.recoverHandler(name, call)
+		return
+	}
+
+	guard := &m.guard
+	guard.mu.Lock()
+	if guard.overdue[name] >= maxOverdueCalls {
+		guard.mu.Unlock()
+		m.log.Error("Skipped handler call, too many calls are past the timeout",
+			"handler", name,
+			"overdue", maxOverdueCalls,
+		)
+		handlerFailures.Add(1, telemetry.String("handler", name), telemetry.String("reason", "skipped"))
+		return
+	}
+	guard.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.recoverHandler(name, call)
+	}()
+
+	timer := time.NewTimer(m.handlerTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return
+	case <-timer.C:
+	}
+
+	guard.mu.Lock()
+	if guard.overdue == nil {
+		guard.overdue = make(map[string]int)
+	}
+	guard.overdue[name]++
+	guard.mu.Unlock()
+	m.log.Error("Handler timed out", "handler", name, "timeout", m.handlerTimeout)
+	handlerFailures.Add(1, telemetry.String("handler", name), telemetry.String("reason", "timeout"))
+
+	go func() {
+		<-done
+		guard.mu.Lock()
+		guard.overdue[name]--
+		if guard.overdue[name] == 0 {
+			delete(guard.overdue, name)
+		}
+		guard.mu.Unlock()
+		m.log.Warn("Handler returned after timing out", "handler", name)
+	}()
+}
+
+// recoverHandler calls a handler and logs its panic instead of crashing
+func (m *Monitor) recoverHandler(name string, call func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			m.log.Error("Handler panicked",
+				"handler", name,
+				"panic", fmt.Sprint(r),
+				"stack", string(debug.Stack()),
+			)
+			handlerFailures.Add(1, telemetry.String("handler", name), telemetry.String("reason", "panic"))
+		}
+	}()
+	call()
+}
@@ -0,0 +1,200 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// pollInterval is how often token accounts are polled by default
+const pollInterval = 30 * time.Second
+
+// stalledTicks is the number of poll intervals without a tick after which the
+// polling loop is considered stuck
+const stalledTicks = 5
+
+// pingTimeout bounds the RPC health check of a health report
+const pingTimeout = 5 * time.Second
+
+// States of the WebSocket connections, derived from the subscriptions of the
+// wallets that aren't paused
+const (
+	WebSocketConnected    = "connected"
+	WebSocketDegraded     = "degraded"
+	WebSocketDisconnected = "disconnected"
+)
+
+// Health reports whether the monitor is alive and ready to detect changes
+type Health struct {
+	// Live is false when the polling loop is stuck, which a restart fixes
+	Live bool `json:"live"`
+	// Ready is true once the initial state is loaded while the RPC endpoint
+	// is healthy and at least one subscription is active
+	Ready     bool       `json:"ready"`
+	Problems  []string   `json:"problems,omitempty"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	// WebSocket is connected when the subscriptions of all active wallets
+	// are active, degraded when some are and disconnected when none is
+	WebSocket string    `json:"websocket"`
+	RPC       RPCHealth `json:"rpc"`
+	// Subscriptions counts the subscriptions of the wallets by state
+	Subscriptions map[string]int `json:"subscriptions"`
+	// StaleWallets counts the wallets reported stale, see SetStaleAfter
+	StaleWallets int `json:"stale_wallets"`
+	// LastTickAt is the last run of the polling loop, even when polling was
+	// skipped
+	LastTickAt *time.Time `json:"last_tick_at,omitempty"`
+	// UnloadedWallets failed to load their initial state at startup and
+	// are retried by the polls until they load
+	UnloadedWallets []UnloadedWallet `json:"unloaded_wallets,omitempty"`
+	Wallets         []WalletHealth   `json:"wallets"`
+}
+
+// RPCHealth is the result of a health check of the RPC endpoint
+type RPCHealth struct {
+	Healthy bool `json:"healthy"`
+	// LatencyMs is the duration of the check in milliseconds
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// WalletHealth describes the monitoring of one wallet
+type WalletHealth struct {
+	Wallet       string     `json:"wallet"`
+	Label        string     `json:"label,omitempty"`
+	Paused       bool       `json:"paused"`
+	Subscription string     `json:"subscription"`
+	Stale        bool       `json:"stale"`
+	LastPollAt   *time.Time `json:"last_poll_at,omitempty"`
+	LastUpdateAt *time.Time `json:"last_update_at,omitempty"`
+}
+
+// Health checks the RPC endpoint and reports the state of the
+// subscriptions and polls of every wallet
+func (m *Monitor) Health(ctx context.Context) Health {
+	health := Health{Subscriptions: make(map[string]int), Wallets: []WalletHealth{}}
+
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	start := time.Now()
+	err := m.client.Ping(ctx)
+	health.RPC.LatencyMs = time.Since(start).Milliseconds()
+	health.RPC.Healthy = err == nil
+	if err != nil {
+		health.RPC.Error = err.Error()
+	}
+
+	for _, wallet := range m.trackedWallets() {
+		health.Wallets = append(health.Wallets, WalletHealth{
+			Wallet:       wallet,
+			Label:        m.WalletLabel(wallet).Label,
+			Paused:       m.IsPaused(wallet),
+			Subscription: SubscriptionStopped,
+		})
+	}
+
+	health.UnloadedWallets = m.UnloadedWallets()
+
+	active, connected := 0, 0
+	m.statusMutex.Lock()
+	for i := range health.Wallets {
+		walletHealth := &health.Wallets[i]
+		if status, ok := m.subscriptions[walletHealth.Wallet]; ok {
+			walletHealth.Subscription = status.State
+			walletHealth.LastUpdateAt = status.LastUpdateAt
+		}
+		if at, ok := m.polls[walletHealth.Wallet]; ok {
+			at := at
+			walletHealth.LastPollAt = &at
+		}
+		health.Subscriptions[walletHealth.Subscription]++
+		if m.stale[walletHealth.Wallet] && !walletHealth.Paused {
+			walletHealth.Stale = true
+			health.StaleWallets++
+		}
+		if !walletHealth.Paused {
+			active++
+			if walletHealth.Subscription == SubscriptionActive {
+				connected++
+			}
+		}
+	}
+	if !m.startedAt.IsZero() {
+		startedAt := m.startedAt
+		health.StartedAt = &startedAt
+	}
+	if !m.lastTick.IsZero() {
+		lastTick := m.lastTick
+		health.LastTickAt = &lastTick
+	}
+	m.statusMutex.Unlock()
+
+	switch {
+	case connected == active:
+		health.WebSocket = WebSocketConnected
+	case connected > 0:
+		health.WebSocket = WebSocketDegraded
+	default:
+		health.WebSocket = WebSocketDisconnected
+	}
+
+	// Until its first tick the loop is timed from the start
+	health.Live = true
+	if health.StartedAt != nil {
+		lastTick := *health.StartedAt
+		if health.LastTickAt != nil {
+			lastTick = *health.LastTickAt
+		}
+		if stalled := m.now().Sub(lastTick); stalled > stalledTicks*m.interval {
+			health.Live = false
+			health.Problems = append(health.Problems, fmt.Sprintf("polling loop stuck for %s", stalled.Round(time.Second)))
+		}
+	}
+
+	if health.StartedAt == nil {
+		health.Problems = append(health.Problems, "initial state not loaded")
+	}
+	if !health.RPC.Healthy {
+		health.Problems = append(health.Problems, "rpc endpoint unhealthy: "+health.RPC.Error)
+	}
+	if health.WebSocket == WebSocketDisconnected && active > 0 {
+		health.Problems = append(health.Problems, "no active subscription")
+	}
+	health.Ready = health.Live && len(health.Problems) == 0
+	return health
+}
+
+// recordPoll records a successful poll of a wallet's token accounts
+func (m *Monitor) recordPoll(wallet string) {
+	m.statusMutex.Lock()
+	defer m.statusMutex.Unlock()
+	m.polls[wallet] = m.now()
+}
+
+// recordDiscovery records a poll of the wallets by owner
+func (m *Monitor) recordDiscovery() {
+	m.statusMutex.Lock()
+	defer m.statusMutex.Unlock()
+	m.discovered = m.now()
+}
+
+// discoveryDue reports whether the wallets are due to be polled by owner
+func (m *Monitor) discoveryDue() bool {
+	m.statusMutex.Lock()
+	defer m.statusMutex.Unlock()
+	return m.now().Sub(m.discovered) >= m.pool.discoveryInterval()
+}
+
+// recordTick records a run of the polling loop
+func (m *Monitor) recordTick() {
+	m.statusMutex.Lock()
+	defer m.statusMutex.Unlock()
+	m.lastTick = m.now()
+}
+
+// markStarted records that the initial state is loaded and monitoring began
+func (m *Monitor) markStarted() {
+	m.statusMutex.Lock()
+	defer m.statusMutex.Unlock()
+	m.startedAt = m.now()
+}
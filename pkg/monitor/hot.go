@@ -0,0 +1,30 @@
+package monitor
+
+import "github.com/yourusername/solana-wallet-tracker/pkg/solana"
+
+// SetHotWallets marks wallets for the low latency path. Each hot wallet is
+// followed through its own client, typically a Client.Dedicated connection
+// with processed commitment; its events skip enrichers, are marked Hot and
+// get their own delivery queue even in sequential mode. Call it before
+// Start.
+func (m *Monitor) SetHotWallets(clients map[string]*solana.Client) {
+	m.hot = clients
+}
+
+// IsHot reports whether a wallet is on the low latency path
+func (m *Monitor) IsHot(wallet string) bool {
+	m.registryMutex.RLock()
+	defer m.registryMutex.RUnlock()
+	_, ok := m.hot[wallet]
+	return ok
+}
+
+// walletClient returns the client that follows a wallet
+func (m *Monitor) walletClient(wallet string) *solana.Client {
+	m.registryMutex.RLock()
+	defer m.registryMutex.RUnlock()
+	if client, ok := m.hot[wallet]; ok {
+		return client
+	}
+	return m.client
+}
@@ -0,0 +1,158 @@
+package monitor
+
+import (
+	"github.com/yourusername/solana-wallet-tracker/pkg/ingest"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// IngestOnly takes token account updates from Ingest instead of RPC. No
+// token account subscriptions are opened and polls skip token accounts;
+// the initial state is still loaded once, as the baseline of providers that
+// only report balance changes. Call it before Start.
+func (m *Monitor) IngestOnly() {
+	m.ingestOnly = true
+}
+
+// Ingest applies the balance changes of transactions delivered by a webhook
+// provider to the tracked wallets they concern, which produces the same
+// events as updates observed over RPC. In watch-only mode each transaction
+// is emitted as a signature event of every tracked wallet it mentions.
+func (m *Monitor) Ingest(transactions []ingest.Transaction) {
+	for _, tx := range transactions {
+		if m.watchOnly {
+			m.ingestSignature(tx)
+			continue
+		}
+		// Failed transactions only charge fees
+		if !tx.Failed {
+			for _, change := range tx.Tokens {
+				m.ingestTokenChange(tx, change)
+			}
+		}
+		m.ingestNativeChanges(tx)
+	}
+}
+
+// ingestSignature emits a transaction as a signature event of each tracked
+// wallet it mentions
+func (m *Monitor) ingestSignature(tx ingest.Transaction) {
+	seen := make(map[string]bool)
+	for _, account := range tx.Accounts {
+		if seen[account] || !m.tracks(account) || m.IsPaused(account) {
+			continue
+		}
+		seen[account] = true
+		m.countUpdate(account)
+		m.emit(m.signatureEvent(account, solana.LogNotification{
+			Signature: tx.Signature,
+			Slot:      tx.Slot,
+			Failed:    tx.Failed,
+		}))
+	}
+}
+
+// ingestTokenChange updates the token account a change concerns. Wrapped
+// SOL changes are folded into the SOL balance of their wallet, see
+// ingestNativeChanges.
+func (m *Monitor) ingestTokenChange(tx ingest.Transaction, change ingest.TokenChange) {
+	if !m.tracks(change.Wallet) || !m.shouldTrackToken(change.Wallet, change.Mint) {
+		return
+	}
+	account := solana.TokenAccountInfo{
+		Address:  change.Account,
+		Owner:    change.Wallet,
+		Mint:     change.Mint,
+		Decimals: change.Decimals,
+	}
+	if m.isWrappedSOL(account) {
+		return
+	}
+
+	// Delegates and freezes aren't reported by the providers, so they are
+	// kept from the known state
+	m.stateMutex.RLock()
+	known, exists := m.state[change.Wallet+":"+change.Mint]
+	m.stateMutex.RUnlock()
+	if exists && known.Address == change.Account {
+		account = known
+		account.Decimals = change.Decimals
+	} else {
+		account.ProgramID = solana.TokenProgramID
+	}
+
+	switch {
+	case change.Post != nil:
+		account.Balance = *change.Post
+	case exists && known.Address == change.Account:
+		account.Balance = applyDelta(known.Balance, change.Delta)
+	case change.Delta > 0:
+		// A receipt of an account the wallet didn't hold yet
+		account.Balance = uint64(change.Delta)
+	default:
+		m.log.Debug("Ignoring change of an unknown token account",
+			"account", change.Account,
+			"signature", tx.Signature,
+		)
+		return
+	}
+
+	account.Slot = tx.Slot
+	account.Signature = tx.Signature
+	account.LastUpdatedAt = m.now()
+	account.Source = solana.SourceIngest
+	m.countUpdate(change.Wallet)
+	m.processAccountUpdate(account, false)
+}
+
+// ingestNativeChanges applies the lamport and wrapped SOL changes of a
+// transaction to the SOL holdings of the tracked wallets, when wrapped SOL
+// is folded into them
+func (m *Monitor) ingestNativeChanges(tx ingest.Transaction) {
+	if !m.foldSOL {
+		return
+	}
+
+	deltas := make(map[string]int64)
+	for _, change := range tx.Native {
+		deltas[change.Account] += change.Delta
+	}
+	if !tx.Failed {
+		for _, change := range tx.Tokens {
+			if change.Mint == solana.NativeMint && change.Account != change.Wallet {
+				deltas[change.Wallet] += change.Delta
+			}
+		}
+	}
+
+	for wallet, delta := range deltas {
+		if delta == 0 || !m.tracks(wallet) || !m.shouldTrackToken(wallet, solana.NativeMint) {
+			continue
+		}
+		m.stateMutex.RLock()
+		known, exists := m.state[wallet+":"+solana.NativeMint]
+		m.stateMutex.RUnlock()
+		if !exists {
+			continue
+		}
+
+		holding := known
+		holding.Balance = applyDelta(known.Balance, delta)
+		holding.Slot = tx.Slot
+		holding.Signature = tx.Signature
+		holding.LastUpdatedAt = m.now()
+		holding.Source = solana.SourceIngest
+		m.countUpdate(wallet)
+		m.processAccountUpdate(holding, false)
+	}
+}
+
+// applyDelta changes a balance by delta, stopping at zero
+func applyDelta(balance uint64, delta int64) uint64 {
+	if delta < 0 {
+		if uint64(-delta) > balance {
+			return 0
+		}
+		return balance - uint64(-delta)
+	}
+	return balance + uint64(delta)
+}
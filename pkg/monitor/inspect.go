@@ -0,0 +1,106 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// States of the token account subscription of a wallet
+const (
+	SubscriptionActive   = "active"
+	SubscriptionRetrying = "retrying"
+	SubscriptionFailed   = "failed"
+	// SubscriptionStopped is the state of paused wallets and of all wallets
+	// once the monitor stops
+	SubscriptionStopped = "stopped"
+)
+
+// SubscriptionStatus describes the token account subscription of a wallet
+type SubscriptionStatus struct {
+	Wallet string    `json:"wallet"`
+	State  string    `json:"state"`
+	Since  time.Time `json:"since"`
+	// Restarts counts the subscriptions made after the first
+	Restarts  int    `json:"restarts"`
+	LastError string `json:"last_error,omitempty"`
+	// Updates counts the updates received since the tracker started
+	Updates      int        `json:"updates"`
+	LastUpdateAt *time.Time `json:"last_update_at,omitempty"`
+}
+
+// AccountInspection is what the monitor knows about one token account
+type AccountInspection struct {
+	Address string `json:"address"`
+	// Account is the live state, nil when the account isn't tracked
+	Account *solana.TokenAccountInfo `json:"account,omitempty"`
+	Paused  bool                     `json:"paused"`
+	// Subscription is the status of the owner's subscription, nil when the
+	// owner isn't tracked
+	Subscription *SubscriptionStatus `json:"subscription,omitempty"`
+	ParseErrors  []solana.ParseError `json:"parse_errors"`
+}
+
+// InspectAccount returns the live state of a token account, the status of
+// its owner's subscription and its recent parse errors, for debugging
+// accounts that don't update. The owner is taken from the live state, or
+// from wallet when the account isn't tracked.
+func (m *Monitor) InspectAccount(address, wallet string) AccountInspection {
+	inspection := AccountInspection{Address: address, ParseErrors: m.client.ParseErrors.Recent(address)}
+	if inspection.ParseErrors == nil {
+		inspection.ParseErrors = []solana.ParseError{}
+	}
+
+	m.stateMutex.RLock()
+	for _, account := range m.state {
+		if account.Address == address {
+			account := account
+			inspection.Account = &account
+			wallet = account.Owner
+			break
+		}
+	}
+	m.stateMutex.RUnlock()
+
+	if wallet != "" {
+		inspection.Paused = m.IsPaused(wallet)
+		m.statusMutex.Lock()
+		if status, ok := m.subscriptions[wallet]; ok {
+			status := *status
+			inspection.Subscription = &status
+		}
+		m.statusMutex.Unlock()
+	}
+	return inspection
+}
+
+// setSubscriptionState records a change of state of a wallet's subscription
+func (m *Monitor) setSubscriptionState(wallet, state string, err error) {
+	m.statusMutex.Lock()
+	defer m.statusMutex.Unlock()
+
+	status, ok := m.subscriptions[wallet]
+	if !ok {
+		status = &SubscriptionStatus{Wallet: wallet}
+		m.subscriptions[wallet] = status
+	} else if state == SubscriptionActive {
+		status.Restarts++
+	}
+	status.State = state
+	status.Since = m.now()
+	if err != nil {
+		status.LastError = err.Error()
+	}
+}
+
+// countUpdate records an update received by a wallet's subscription
+func (m *Monitor) countUpdate(wallet string) {
+	m.statusMutex.Lock()
+	defer m.statusMutex.Unlock()
+
+	if status, ok := m.subscriptions[wallet]; ok {
+		now := m.now()
+		status.Updates++
+		status.LastUpdateAt = &now
+	}
+}
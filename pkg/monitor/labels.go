@@ -0,0 +1,33 @@
+package monitor
+
+// WalletLabel is a human-friendly name and group tags for a wallet, and the
+// tenants whose watchlists hold it
+type WalletLabel struct {
+	Label   string   `json:"label,omitempty"`
+	Groups  []string `json:"groups,omitempty"`
+	Tenants []string `json:"tenants,omitempty"`
+}
+
+// SetWalletLabels sets the labels attached to events by wallet address.
+// Addresses other than the tracked wallets, such as exchange deposit
+// addresses seen by mint watches, may be labelled too.
+func (m *Monitor) SetWalletLabels(labels map[string]WalletLabel) {
+	m.labels = labels
+}
+
+// WalletLabel returns the label of a wallet, which is empty if it has none
+func (m *Monitor) WalletLabel(wallet string) WalletLabel {
+	m.registryMutex.RLock()
+	defer m.registryMutex.RUnlock()
+	return m.labels[wallet]
+}
+
+// InGroup reports whether the label carries a group tag
+func (l WalletLabel) InGroup(group string) bool {
+	for _, g := range l.Groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
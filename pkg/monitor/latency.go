@@ -0,0 +1,278 @@
+package monitor
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/telemetry"
+)
+
+const (
+	// latencyWindow is how many of the latest samples of a source the
+	// percentiles are taken from
+	latencyWindow = 1000
+	// maxPendingLatency is how many samples wait for the block time of
+	// their slot before new ones are dropped
+	maxPendingLatency = 10000
+	// blockTimeCacheSize is how many block times are kept, as the updates
+	// of a slot usually arrive together
+	blockTimeCacheSize = 1000
+	// blockTimeAttempts is how often the block time of a slot is looked
+	// up, as it is missing until the block is confirmed
+	blockTimeAttempts = 3
+	// blockTimeRetryDelay separates the lookups of a block time
+	blockTimeRetryDelay = time.Second
+	// blockTimeTimeout bounds a lookup of a block time
+	blockTimeTimeout = 5 * time.Second
+)
+
+// LatencyStats reports how far behind the chain the events of an update
+// source reach their handlers: the time from the block time of the slot of
+// an update to the invocation of the handlers of its event. Block times are
+// whole seconds, so single samples are up to a second too high.
+type LatencyStats struct {
+	// Source is where the updates came from, e.g. websocket, geyser, poll
+	// or ingest
+	Source string `json:"source"`
+	// Samples counts the events measured
+	Samples int64 `json:"samples"`
+	// Unresolved counts the events whose block time couldn't be looked up
+	Unresolved int64      `json:"unresolved"`
+	LastMs     float64    `json:"last_ms"`
+	LastAt     *time.Time `json:"last_at,omitempty"`
+	MinMs      float64    `json:"min_ms"`
+	MaxMs      float64    `json:"max_ms"`
+	MeanMs     float64    `json:"mean_ms"`
+	// The percentiles cover the latest samples only
+	P50Ms float64 `json:"p50_ms"`
+	P95Ms float64 `json:"p95_ms"`
+	P99Ms float64 `json:"p99_ms"`
+}
+
+// latencySample is the invocation of the handlers of an event, waiting for
+// the block time of its slot
+type latencySample struct {
+	source string
+	slot   uint64
+	at     time.Time
+}
+
+// latencyRecord accumulates the samples of a source
+type latencyRecord struct {
+	samples    int64
+	unresolved int64
+	sum        float64
+	min        float64
+	max        float64
+	last       float64
+	lastAt     time.Time
+	// window holds the latest samples, next being the oldest once full
+	window []float64
+	next   int
+}
+
+// latencyTracker measures the delay behind the chain per update source
+type latencyTracker struct {
+	pending chan latencySample
+
+	mu         sync.Mutex
+	blockTimes map[uint64]time.Time
+	// slots orders the cached block times, oldest first
+	slots   []uint64
+	sources map[string]*latencyRecord
+}
+
+// MeasureLatency measures how far behind the chain the tracker runs per
+// update source, see LatencyReport. The block time of each slot with events
+// is looked up over RPC, apart from the delivery of the events. Call it
+// before Start.
+func (m *Monitor) MeasureLatency() {
+	m.latency = &latencyTracker{
+		pending:    make(chan latencySample, maxPendingLatency),
+		blockTimes: make(map[uint64]time.Time),
+		sources:    make(map[string]*latencyRecord),
+	}
+}
+
+// LatencyReport returns the latency of every update source with events,
+// by source. It is nil unless MeasureLatency was called.
+func (m *Monitor) LatencyReport() []LatencyStats {
+	l := m.latency
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	report := make([]LatencyStats, 0, len(l.sources))
+	for source, record := range l.sources {
+		stats := LatencyStats{Source: source, Samples: record.samples, Unresolved: record.unresolved}
+		if record.samples > 0 {
+			lastAt := record.lastAt
+			stats.LastMs = record.last
+			stats.LastAt = &lastAt
+			stats.MinMs = record.min
+			stats.MaxMs = record.max
+			stats.MeanMs = record.sum / float64(record.samples)
+			window := append([]float64(nil), record.window...)
+			sort.Float64s(window)
+			stats.P50Ms = percentile(window, 0.50)
+			stats.P95Ms = percentile(window, 0.95)
+			stats.P99Ms = percentile(window, 0.99)
+		}
+		report = append(report, stats)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Source < report[j].Source })
+	return report
+}
+
+// observeLatency queues the measurement of an event whose handlers are
+// invoked at at. Events of updates without a slot or source, and replayed
+// ones, aren't measured.
+func (m *Monitor) observeLatency(event Event, at time.Time) {
+	l := m.latency
+	if l == nil || event.Replayed || event.Account.Slot == 0 || event.Account.Source == "" {
+		return
+	}
+	select {
+	case l.pending <- latencySample{source: event.Account.Source, slot: event.Account.Slot, at: at}:
+	default:
+		m.log.Debug("Latency queue is full, dropped a sample", "source", event.Account.Source)
+	}
+}
+
+// resolveLatency looks up the block times of the queued samples and records
+// them until ctx is done
+func (m *Monitor) resolveLatency(ctx context.Context) {
+	l := m.latency
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sample := <-l.pending:
+			blockTime, err := m.blockTime(ctx, sample.slot)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				m.log.Debug("Failed to get block time", "slot", sample.slot, "error", err)
+				l.unresolved(sample.source)
+				continue
+			}
+			// The block time is rounded down to the second, and the clocks
+			// of validators drift, so it can fall after the invocation
+			delay := float64(sample.at.Sub(blockTime)) / float64(time.Millisecond)
+			if delay < 0 {
+				delay = 0
+			}
+			l.record(sample.source, delay, sample.at)
+			chainLag.Record(delay,
+				telemetry.String("source", sample.source),
+				telemetry.String("network", m.network),
+			)
+		}
+	}
+}
+
+// blockTime returns the block time of a slot from the cache or the RPC
+// endpoint, retrying while the block isn't confirmed yet
+func (m *Monitor) blockTime(ctx context.Context, slot uint64) (time.Time, error) {
+	l := m.latency
+	l.mu.Lock()
+	blockTime, ok := l.blockTimes[slot]
+	l.mu.Unlock()
+	if ok {
+		return blockTime, nil
+	}
+
+	var err error
+	for attempt := 0; attempt < blockTimeAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(blockTimeRetryDelay):
+			case <-ctx.Done():
+				return time.Time{}, ctx.Err()
+			}
+		}
+		lookupCtx, cancel := context.WithTimeout(ctx, blockTimeTimeout)
+		blockTime, err = m.client.GetBlockTime(lookupCtx, slot)
+		cancel()
+		if err == nil {
+			l.cache(slot, blockTime)
+			return blockTime, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// cache keeps the block time of a slot, evicting the oldest beyond
+// blockTimeCacheSize
+func (l *latencyTracker) cache(slot uint64, blockTime time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.blockTimes[slot]; ok {
+		return
+	}
+	l.blockTimes[slot] = blockTime
+	l.slots = append(l.slots, slot)
+	if len(l.slots) > blockTimeCacheSize {
+		delete(l.blockTimes, l.slots[0])
+		l.slots = l.slots[1:]
+	}
+}
+
+// source returns the record of a source, creating it. Callers hold mu.
+func (l *latencyTracker) source(source string) *latencyRecord {
+	record, ok := l.sources[source]
+	if !ok {
+		record = &latencyRecord{}
+		l.sources[source] = record
+	}
+	return record
+}
+
+// record adds a delay in milliseconds to the record of a source
+func (l *latencyTracker) record(source string, delay float64, at time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	record := l.source(source)
+	if record.samples == 0 || delay < record.min {
+		record.min = delay
+	}
+	if delay > record.max {
+		record.max = delay
+	}
+	record.samples++
+	record.sum += delay
+	record.last = delay
+	record.lastAt = at
+	if len(record.window) < latencyWindow {
+		record.window = append(record.window, delay)
+	} else {
+		record.window[record.next] = delay
+		record.next = (record.next + 1) % latencyWindow
+	}
+}
+
+// unresolved counts a sample of a source whose block time is unknown
+func (l *latencyTracker) unresolved(source string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.source(source).unresolved++
+}
+
+// percentile returns the value at quantile q of sorted values, with the
+// nearest rank method
+func percentile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
@@ -0,0 +1,177 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// defaultInitialWorkers is how many wallets are loaded at once when the
+// monitor starts, unless the poll pool sets it
+const defaultInitialWorkers = 8
+
+// initialWorkers returns the number of workers of the initial load
+func (p PollPool) initialWorkers() int {
+	if p.InitialWorkers < 1 {
+		return defaultInitialWorkers
+	}
+	return p.InitialWorkers
+}
+
+// SeedSilently loads the initial state without events: the balance
+// handlers still see every account, but event handlers only get the
+// changes that follow. A state given to SeedState isn't initial, so the
+// changes since are reported either way. Call it before Start.
+func (m *Monitor) SeedSilently() {
+	m.seedSilently = true
+}
+
+// updateInitialState loads the initial token account state of all wallets
+// on the initial workers of the pool. Wallets whose lookup fails are left
+// out and loaded by the next polls, see UnloadedWallets; it only fails when
+// every wallet failed or ctx is cancelled.
+func (m *Monitor) updateInitialState(ctx context.Context) error {
+	wallets := m.trackedWallets()
+	jobs := make(chan string)
+	var mu sync.Mutex
+	failures := make(map[string]error)
+	var workers sync.WaitGroup
+	for i := 0; i < m.pool.initialWorkers() && i < len(wallets); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for wallet := range jobs {
+				if err := m.loadInitialState(ctx, wallet); err != nil {
+					mu.Lock()
+					failures[wallet] = err
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, wallet := range wallets {
+		select {
+		case jobs <- wallet:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	workers.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if len(failures) > 0 {
+		m.reportLoadFailures(len(wallets), failures)
+		if len(failures) == len(wallets) {
+			return fmt.Errorf("failed to load the initial state of every wallet: %w", failures[wallets[0]])
+		}
+	}
+	m.recordDiscovery()
+	if m.authorities {
+		m.checkWalletPrograms(ctx)
+	}
+
+	return nil
+}
+
+// loadInitialState looks up the token accounts of a wallet within the
+// timeout of the pool and applies them as its initial state
+func (m *Monitor) loadInitialState(ctx context.Context, wallet string) error {
+	lookupCtx := ctx
+	if m.pool.Timeout > 0 {
+		var cancel context.CancelFunc
+		lookupCtx, cancel = context.WithTimeout(ctx, m.pool.Timeout)
+		defer cancel()
+	}
+	accounts, err := m.source.GetTokenAccounts(lookupCtx, wallet)
+	if err != nil {
+		return err
+	}
+	m.recordPoll(wallet)
+	m.applySnapshot(ctx, wallet, accounts, !m.seeded)
+	return nil
+}
+
+// reportLoadFailures logs the wallets whose initial state couldn't be
+// loaded and keeps them for the next polls to load
+func (m *Monitor) reportLoadFailures(total int, failures map[string]error) {
+	wallets := make([]string, 0, len(failures))
+	for wallet, err := range failures {
+		wallets = append(wallets, wallet)
+		m.log.Error("Failed to load initial token accounts", "wallet", wallet, "error", err)
+	}
+	sort.Strings(wallets)
+
+	m.statusMutex.Lock()
+	if m.unloaded == nil {
+		m.unloaded = make(map[string]string)
+	}
+	for wallet, err := range failures {
+		m.unloaded[wallet] = err.Error()
+	}
+	m.statusMutex.Unlock()
+
+	m.log.Warn("Initial state incomplete, the next polls retry the failed wallets",
+		"failed", len(failures),
+		"wallets", total,
+		"unloaded", wallets,
+	)
+}
+
+// UnloadedWallets returns the wallets whose initial state couldn't be
+// loaded yet, with the error of the load at startup, sorted by wallet
+func (m *Monitor) UnloadedWallets() []UnloadedWallet {
+	m.statusMutex.Lock()
+	defer m.statusMutex.Unlock()
+	wallets := make([]UnloadedWallet, 0, len(m.unloaded))
+	for wallet, err := range m.unloaded {
+		wallets = append(wallets, UnloadedWallet{Wallet: wallet, Error: err})
+	}
+	sort.Slice(wallets, func(i, j int) bool { return wallets[i].Wallet < wallets[j].Wallet })
+	return wallets
+}
+
+// UnloadedWallet is a wallet whose initial state couldn't be loaded
+type UnloadedWallet struct {
+	Wallet string `json:"wallet"`
+	Error  string `json:"error"`
+}
+
+// unloadedWallets returns the wallets awaiting their initial state, for
+// polls that would otherwise skip them
+func (m *Monitor) unloadedWallets() []string {
+	m.statusMutex.Lock()
+	unloaded := make([]string, 0, len(m.unloaded))
+	for wallet := range m.unloaded {
+		unloaded = append(unloaded, wallet)
+	}
+	m.statusMutex.Unlock()
+
+	wallets := unloaded[:0]
+	for _, wallet := range unloaded {
+		if !m.IsPaused(wallet) {
+			wallets = append(wallets, wallet)
+		}
+	}
+	sort.Strings(wallets)
+	return wallets
+}
+
+// completeLoad records a successful lookup of a wallet and reports whether
+// it is the initial state of a wallet that failed to load at startup, so
+// its holdings aren't reported as new
+func (m *Monitor) completeLoad(wallet string) (initial bool) {
+	m.statusMutex.Lock()
+	defer m.statusMutex.Unlock()
+	if _, ok := m.unloaded[wallet]; !ok {
+		return false
+	}
+	delete(m.unloaded, wallet)
+	m.log.Info("Loaded initial token accounts", "wallet", wallet)
+	return !m.seeded
+}
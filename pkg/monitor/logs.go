@@ -0,0 +1,81 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// Transaction log watcher timings
+const (
+	logsRetryDelay    = 5 * time.Second
+	logsFetchDelay    = 2 * time.Second
+	logsFetchAttempts = 3
+)
+
+// watchesLogs reports whether a feature needs the transactions of wallets
+func (m *Monitor) watchesLogs() bool {
+	return m.cnftLogs || m.rentHandler != nil
+}
+
+// watchWalletLogs follows the transactions mentioning a wallet until ctx is
+// cancelled, when the wallet is paused or the monitor stops
+func (m *Monitor) watchWalletLogs(ctx context.Context, wallet string) {
+	for {
+		err := m.client.SubscribeToWalletLogs(ctx, wallet, func(notification solana.LogNotification) {
+			if notification.Failed {
+				return
+			}
+			bubblegum := m.cnftLogs && solana.MentionsBubblegum(notification.Logs)
+			rent := m.rentHandler != nil && solana.MentionsRent(notification.Logs)
+			if bubblegum || rent {
+				go m.processWalletTransaction(wallet, notification.Signature, bubblegum, rent)
+			}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		delay, ok := retryDelay(err, logsRetryDelay)
+		if !ok {
+			m.log.Error("Transaction log watch stopped", "wallet", wallet, "error", err)
+			return
+		}
+		m.log.Error("Transaction log watch stopped, resubscribing", "wallet", wallet, "error", err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// processWalletTransaction fetches a transaction of a wallet once and hands
+// it to the features its logs concern
+func (m *Monitor) processWalletTransaction(wallet, signature string, bubblegum, rent bool) {
+	// Logs arrive before the transaction is always queryable, so retry briefly
+	var tx *solana.Transaction
+	var err error
+	for attempt := 0; attempt < logsFetchAttempts; attempt++ {
+		if tx, err = m.client.GetTransaction(m.ctx, signature); err == nil {
+			break
+		}
+		select {
+		case <-time.After(logsFetchDelay):
+		case <-m.ctx.Done():
+			return
+		}
+	}
+	if err != nil {
+		m.log.Error("Failed to fetch transaction", "signature", signature, "error", err)
+		return
+	}
+
+	if bubblegum {
+		m.processBubblegumTransaction(wallet, tx)
+	}
+	if rent {
+		m.processRentChanges(wallet, tx)
+	}
+}
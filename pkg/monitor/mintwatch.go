@@ -0,0 +1,121 @@
+package monitor
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// mintWatchRetryDelay is the wait before resubscribing a failed mint watch
+const mintWatchRetryDelay = 5 * time.Second
+
+// MintWatch watches every holder of a mint and reports moves by holders at
+// or above a balance threshold
+type MintWatch struct {
+	Mint string
+	// Threshold is the minimum holder balance in UI units
+	Threshold float64
+}
+
+// mintWatcher tracks the balances of large holders of a single mint
+type mintWatcher struct {
+	watch     MintWatch
+	threshold uint64
+	holders   map[string]uint64
+	mutex     sync.Mutex
+}
+
+// AddMintWatch registers a mint to watch for large holder moves
+func (m *Monitor) AddMintWatch(watch MintWatch) {
+	m.mintWatches = append(m.mintWatches, watch)
+}
+
+// watchMint seeds the largest holders of a mint and follows all of its token
+// accounts until the monitor stops
+func (m *Monitor) watchMint(watch MintWatch) {
+	mintInfo, err := m.client.GetCachedMintInfo(m.ctx, watch.Mint)
+	if err != nil {
+		m.log.Error("Failed to start mint watch", "mint", watch.Mint, "error", err)
+		return
+	}
+
+	w := &mintWatcher{
+		watch:     watch,
+		threshold: uint64(watch.Threshold * math.Pow10(int(mintInfo.Decimals))),
+		holders:   make(map[string]uint64),
+	}
+
+	// Seed the known whales so their first move has a previous balance
+	largest, err := m.client.GetLargestTokenAccounts(m.ctx, watch.Mint)
+	if err != nil {
+		m.log.Warn("Failed to seed largest holders", "mint", watch.Mint, "error", err)
+	}
+	for _, account := range largest {
+		if account.Balance >= w.threshold {
+			w.holders[account.Address] = account.Balance
+		}
+	}
+
+	m.log.Info("Watching mint for large holder moves",
+		"mint", watch.Mint,
+		"threshold", watch.Threshold,
+		"holders", len(w.holders),
+	)
+
+	for {
+		err := m.client.SubscribeToMintAccounts(m.ctx, watch.Mint, mintInfo.Decimals, func(account solana.TokenAccountInfo) {
+			if event, ok := w.update(account); ok {
+				m.emit(event)
+			}
+		})
+		if m.ctx.Err() != nil {
+			return
+		}
+
+		delay, ok := retryDelay(err, mintWatchRetryDelay)
+		if !ok {
+			m.log.Error("Mint watch stopped", "mint", watch.Mint, "error", err)
+			return
+		}
+		m.log.Error("Mint watch stopped, resubscribing", "mint", watch.Mint, "error", err)
+		select {
+		case <-time.After(delay):
+		case <-m.ctx.Done():
+			return
+		}
+	}
+}
+
+// update records a token account update and returns an event when a holder
+// at or above the threshold moved tokens
+func (w *mintWatcher) update(account solana.TokenAccountInfo) (Event, bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	previous, known := w.holders[account.Address]
+	if account.Balance >= w.threshold {
+		w.holders[account.Address] = account.Balance
+	} else {
+		delete(w.holders, account.Address)
+	}
+
+	// Only large holders are tracked, so an unknown account that is now
+	// above the threshold just received a large amount
+	if known && previous == account.Balance {
+		return Event{}, false
+	}
+	if !known && account.Balance < w.threshold {
+		return Event{}, false
+	}
+
+	event := Event{Type: EventLargeHolderMove, Account: account}
+	if known {
+		previousAccount := account
+		previousAccount.Balance = previous
+		event.Previous = &previousAccount
+	}
+
+	return event, true
+}
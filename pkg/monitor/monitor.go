@@ -2,11 +2,31 @@ package monitor
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
-	"github.com/sirupsen/logrus"
+	"github.com/yourusername/solana-wallet-tracker/pkg/das"
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+	"github.com/yourusername/solana-wallet-tracker/pkg/price"
 	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+	"github.com/yourusername/solana-wallet-tracker/pkg/telemetry"
+)
+
+// walletRetryDelay is the wait before resubscribing a failed wallet subscription
+const walletRetryDelay = 5 * time.Second
+
+// Errors of handler registration and Start
+var (
+	// ErrStarted is returned when registering handlers on, or starting, a
+	// monitor that was already started
+	ErrStarted = errors.New("monitor already started")
+	// ErrNilHandler is returned when registering a nil handler
+	ErrNilHandler = errors.New("handler is nil")
+	// ErrUnknownHandler is returned when turning on or off a handler that
+	// isn't registered
+	ErrUnknownHandler = errors.New("unknown handler")
 )
 
 // BalanceChangeHandler is a function that handles token balance changes
@@ -14,54 +34,203 @@ type BalanceChangeHandler func(accountInfo solana.TokenAccountInfo)
 
 // Monitor handles monitoring of token balances for Solana wallets
 type Monitor struct {
-	client     *solana.Client
-	wallets    []string
-	tokens     []string
-	handlers   []BalanceChangeHandler
-	state      map[string]solana.TokenAccountInfo
-	stateMutex sync.RWMutex
-	ctx        context.Context
-	cancel     context.CancelFunc
+	client         *solana.Client
+	source         solana.DataSource
+	network        string
+	wallets        []string
+	tokens         []string
+	walletTokens   map[string][]string
+	labels         map[string]WalletLabel
+	domains        map[string]string
+	registryMutex  sync.RWMutex
+	paused         map[string]bool
+	walletCancels  map[string]context.CancelFunc
+	walletMutex    sync.Mutex
+	handlers       []balanceHandler
+	eventHandlers  []eventHandler
+	handlerTimeout time.Duration
+	guard          handlerGuard
+	stages         [phaseCount][]Middleware
+	hot            map[string]*solana.Client
+	delivery       DeliveryMode
+	queues         map[string]*deliveryQueue
+	queueMutex     sync.Mutex
+	pressure       *backpressure
+	latency        *latencyTracker
+	pending        sync.WaitGroup
+	clock          Clock
+	interval       time.Duration
+	pool           PollPool
+	log            logging.Logger
+	running        bool
+	holdingLookup  HoldingLookup
+	mintWatches    []MintWatch
+	trackStakes    bool
+	stakes         map[string]solana.StakeAccountInfo
+	stakeEpoch     uint64
+	das            *das.Client
+	nfts           map[string]map[string]das.Asset
+	cnftLogs       bool
+	rentHandler    RentHandler
+	groupWatches   []*groupWatch
+	groupMutex     sync.Mutex
+	prices         *price.Cache
+	watchOnly      bool
+	ingestOnly     bool
+	skipPolls      bool
+	foldSOL        bool
+	delegates      bool
+	freezes        bool
+	authorities    bool
+	programs       map[string]string
+	zeroTTL        time.Duration
+	zeroSince      map[string]time.Time
+	pruned         map[string]bool
+	state          map[string]solana.TokenAccountInfo
+	held           map[string]bool
+	seeded         bool
+	seedSilently   bool
+	stateMutex     sync.RWMutex
+	subscriptions  map[string]*SubscriptionStatus
+	polls          map[string]time.Time
+	unloaded       map[string]string
+	lastTick       time.Time
+	discovered     time.Time
+	startedAt      time.Time
+	staleAfter     time.Duration
+	stale          map[string]bool
+	statusMutex    sync.Mutex
+	ctx            context.Context
+	cancel         context.CancelFunc
 }
 
-// NewMonitor creates a new wallet monitor
-func NewMonitor(client *solana.Client, wallets, tokens []string) *Monitor {
+// New creates a wallet monitor reading from client, configured with options
+func New(client *solana.Client, options ...Option) *Monitor {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &Monitor{
-		client:  client,
-		wallets: wallets,
-		tokens:  tokens,
-		state:   make(map[string]solana.TokenAccountInfo),
-		ctx:     ctx,
-		cancel:  cancel,
+	m := &Monitor{
+		client:        client,
+		source:        client,
+		interval:      pollInterval,
+		log:           logging.Default(),
+		state:         make(map[string]solana.TokenAccountInfo),
+		held:          make(map[string]bool),
+		stakes:        make(map[string]solana.StakeAccountInfo),
+		nfts:          make(map[string]map[string]das.Asset),
+		zeroSince:     make(map[string]time.Time),
+		pruned:        make(map[string]bool),
+		paused:        make(map[string]bool),
+		walletCancels: make(map[string]context.CancelFunc),
+		queues:        make(map[string]*deliveryQueue),
+		subscriptions: make(map[string]*SubscriptionStatus),
+		polls:         make(map[string]time.Time),
+		stale:         make(map[string]bool),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+	for _, option := range options {
+		option(m)
 	}
+	// Enrichers and handlers get the logger through the contexts they're
+	// given
+	m.ctx = logging.NewContext(m.ctx, m.log)
+	return m
 }
 
-// RegisterHandler registers a handler for balance change events
-func (m *Monitor) RegisterHandler(handler BalanceChangeHandler) {
-	m.handlers = append(m.handlers, handler)
+// NewMonitor creates a wallet monitor of wallets, tracking tokens or every
+// token when empty.
+//
+// Deprecated: use New with WithWallets and WithTokens.
+func NewMonitor(client *solana.Client, wallets, tokens []string) *Monitor {
+	return New(client, WithWallets(wallets...), WithTokens(tokens...))
+}
+
+// RegisterHandler registers a handler for balance change events, named
+// after its position, see RegisterNamedHandler. Handlers must be registered
+// before Start.
+func (m *Monitor) RegisterHandler(handler BalanceChangeHandler) error {
+	return m.RegisterNamedHandler(fmt.Sprintf("balance handler #%d", len(m.handlers)+1), handler)
 }
 
-// Start begins monitoring the wallets
-func (m *Monitor) Start() error {
+// RegisterEventHandler registers a handler for typed monitor events, named
+// after its position, see RegisterNamedEventHandler. Handlers must be
+// registered before Start.
+func (m *Monitor) RegisterEventHandler(handler EventHandler) error {
+	return m.RegisterNamedEventHandler(fmt.Sprintf("event handler #%d", len(m.eventHandlers)+1), handler)
+}
+
+// isRunning reports whether Start was called
+func (m *Monitor) isRunning() bool {
+	m.registryMutex.RLock()
+	defer m.registryMutex.RUnlock()
+	return m.running
+}
+
+// SetHoldingLookup sets the lookup used to decide whether a mint was held
+// before the tracker started
+func (m *Monitor) SetHoldingLookup(lookup HoldingLookup) {
+	m.holdingLookup = lookup
+}
+
+// Start loads the initial state and begins monitoring the wallets.
+// Monitoring stops when ctx is cancelled or on Stop, and a cancelled ctx
+// also interrupts the initial load.
+func (m *Monitor) Start(ctx context.Context) error {
+	m.registryMutex.Lock()
+	if m.running {
+		m.registryMutex.Unlock()
+		return ErrStarted
+	}
+	m.running = true
+	m.registryMutex.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			m.Stop()
+		case <-m.ctx.Done():
+		}
+	}()
+	if m.latency != nil {
+		go m.resolveLatency(m.ctx)
+	}
+
+	if m.watchOnly {
+		return m.startSignatureFeed()
+	}
+
 	// First, load the initial state
-	if err := m.updateInitialState(); err != nil {
+	// Seeded totals are the baseline, so crossings since the seed are reported
+	if m.seeded && len(m.groupWatches) > 0 {
+		m.baselineGroupRules()
+	}
+	if err := m.updateInitialState(ctx); err != nil {
 		return err
 	}
+	if m.trackStakes {
+		m.pollStakeAccounts(ctx, true)
+	}
+	if m.das != nil {
+		m.pollNFTs(ctx, true)
+	}
+	if !m.seeded && len(m.groupWatches) > 0 {
+		m.baselineGroupRules()
+	}
 
 	// Subscribe to updates for each wallet
-	for _, wallet := range m.wallets {
-		go func(walletAddress string) {
-			if err := m.subscribeToWalletUpdates(walletAddress); err != nil {
-				logrus.Errorf("Failed to subscribe to wallet updates for %s: %v", walletAddress, err)
-			}
-		}(wallet)
+	for _, wallet := range m.trackedWallets() {
+		m.startWallet(wallet)
 	}
 
 	// Start periodic polling to ensure we don't miss any updates
 	go m.startPeriodicPolling()
 
+	// Watch configured mints for large holder moves
+	for _, watch := range m.mintWatches {
+		go m.watchMint(watch)
+	}
+
+	m.markStarted()
 	return nil
 }
 
@@ -84,72 +253,182 @@ func (m *Monitor) GetCurrentState() map[string]solana.TokenAccountInfo {
 	return stateCopy
 }
 
-// updateInitialState loads the initial token account state for all wallets
-func (m *Monitor) updateInitialState() error {
-	for _, wallet := range m.wallets {
-		accounts, err := m.client.GetTokenAccounts(m.ctx, wallet)
-		if err != nil {
-			return err
-		}
+// SeedState starts the monitor from token accounts observed earlier, such
+// as by the process it takes over from. Changes since then are reported as
+// events when the initial state is loaded. Accounts of wallets or tokens no
+// longer tracked are ignored. Call it before Start.
+func (m *Monitor) SeedState(accounts []solana.TokenAccountInfo) {
+	tracked := make(map[string]bool)
+	for _, wallet := range m.trackedWallets() {
+		tracked[wallet] = true
+	}
 
-		// Filter by tokens if specified
-		for _, account := range accounts {
-			if m.shouldTrackToken(account.Mint) {
-				m.processAccountUpdate(account)
-			}
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	for _, account := range accounts {
+		if !tracked[account.Owner] || !m.shouldTrackToken(account.Owner, account.Mint) {
+			continue
+		}
+		key := account.Owner + ":" + account.Mint
+		m.state[key] = account
+		if account.Balance > 0 {
+			m.held[key] = true
 		}
 	}
-
-	return nil
+	m.seeded = true
 }
 
-// subscribeToWalletUpdates subscribes to token account updates for a wallet
-func (m *Monitor) subscribeToWalletUpdates(walletAddress string) error {
-	return m.client.SubscribeToTokenAccountUpdates(
-		m.ctx,
-		walletAddress,
-		func(account solana.TokenAccountInfo) {
-			// Check if we should track this token
-			if !m.shouldTrackToken(account.Mint) {
-				return
-			}
+// watchWallet follows the token account updates of a wallet until ctx is
+// cancelled, when the wallet is paused or the monitor stops
+func (m *Monitor) watchWallet(ctx context.Context, walletAddress string) {
+	defer func() {
+		if ctx.Err() != nil {
+			m.setSubscriptionState(walletAddress, SubscriptionStopped, nil)
+		}
+	}()
 
-			// Update the state and notify handlers if balance changed
-			m.processAccountUpdate(account)
-		},
-	)
+	for {
+		m.setSubscriptionState(walletAddress, SubscriptionActive, nil)
+		err := m.walletSource(walletAddress).Subscribe(
+			ctx,
+			walletAddress,
+			func(account solana.TokenAccountInfo) {
+				m.countUpdate(walletAddress)
+				subscriptionUpdates.Add(1, telemetry.String("network", m.network))
+				_, span := telemetry.Start(ctx, "subscription.update", telemetry.KindConsumer,
+					telemetry.String("wallet", walletAddress),
+					telemetry.String("account", account.Address),
+					telemetry.String("mint", account.Mint),
+					telemetry.Int("slot", int64(account.Slot)),
+				)
+				defer span.End()
+
+				// Lamports of the wallet only matter folded with its wrapped SOL
+				if account.Address == walletAddress {
+					if m.foldSOL && m.shouldTrackToken(walletAddress, solana.NativeMint) {
+						m.updateWrappedSOL(account, false)
+					}
+					return
+				}
+
+				// Check if we should track this token
+				if !m.shouldTrackToken(account.Owner, account.Mint) {
+					return
+				}
+
+				// Update the state and notify handlers if balance changed
+				m.processUpdate(span.Context(), account, false)
+			},
+		)
+		if ctx.Err() != nil {
+			return
+		}
+
+		delay, ok := retryDelay(err, walletRetryDelay)
+		if !ok {
+			m.log.Error("Failed to subscribe to wallet updates", "wallet", walletAddress, "error", err)
+			m.setSubscriptionState(walletAddress, SubscriptionFailed, err)
+			return
+		}
+		m.log.Error("Wallet subscription stopped, resubscribing", "wallet", walletAddress, "error", err)
+		m.setSubscriptionState(walletAddress, SubscriptionRetrying, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+	}
 }
 
-// startPeriodicPolling starts a periodic polling to update token account states
+// startPeriodicPolling starts a periodic polling to update token account
+// states. Fake clocks don't tick, see SetClock.
 func (m *Monitor) startPeriodicPolling() {
-	ticker := time.NewTicker(30 * time.Second)
+	if m.clock != nil {
+		return
+	}
+	ticker := time.NewTicker(m.interval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			// Update token balances for all wallets
-			for _, wallet := range m.wallets {
-				accounts, err := m.client.GetTokenAccounts(m.ctx, wallet)
-				if err != nil {
-					logrus.Errorf("Failed to poll token accounts for %s: %v", wallet, err)
-					continue
-				}
-
-				for _, account := range accounts {
-					if m.shouldTrackToken(account.Mint) {
-						m.processAccountUpdate(account)
-					}
-				}
-			}
+			m.tick(m.ctx)
 		case <-m.ctx.Done():
 			return
 		}
 	}
 }
 
-// processAccountUpdate processes a token account update
-func (m *Monitor) processAccountUpdate(account solana.TokenAccountInfo) {
+// tick runs a round of the poller
+func (m *Monitor) tick(ctx context.Context) {
+	m.recordTick()
+	// Polling would add to the load of a saturated event queue
+	if !m.watchOnly && !m.pressure.skip() {
+		m.poll(ctx)
+	}
+	if m.zeroTTL > 0 {
+		m.pruneZeroBalances()
+	}
+	if m.staleAfter > 0 {
+		m.checkStaleWallets()
+	}
+}
+
+// poll updates the token balances, stake accounts and NFTs of all wallets.
+// It stops early when the event queue saturates meanwhile.
+func (m *Monitor) poll(ctx context.Context) {
+	wallets := m.activeWallets()
+	// Token accounts of ingested wallets are pushed to Ingest, and streaming
+	// sources need no backup, apart from wallets whose initial state failed
+	// to load
+	if m.ingestOnly || m.skipPolls {
+		wallets = m.unloadedWallets()
+	}
+	// Between discoveries, the token accounts already known are looked up
+	// by address, and only wallets without any are polled by owner
+	if lookup, ok := m.source.(solana.AccountSource); ok && len(wallets) > 0 && !m.discoveryDue() {
+		var known map[string]solana.TokenAccountInfo
+		known, wallets = m.knownAccounts(wallets)
+		if m.pollAccounts(ctx, lookup, known) {
+			return
+		}
+	} else if len(wallets) > 0 {
+		m.recordDiscovery()
+	}
+	if m.pollWallets(ctx, wallets) {
+		return
+	}
+	if m.authorities {
+		m.checkWalletPrograms(ctx)
+	}
+
+	if m.trackStakes {
+		m.pollStakeAccounts(ctx, false)
+	}
+	if m.das != nil {
+		m.pollNFTs(ctx, false)
+	}
+}
+
+// processAccountUpdate processes a token account update. Holdings seen while
+// loading the initial state never produce new holding events.
+func (m *Monitor) processAccountUpdate(account solana.TokenAccountInfo, initial bool) {
+	m.processUpdate(telemetry.SpanContext{}, account, initial)
+}
+
+// processUpdate processes an account update, tracing the events it causes
+// as part of the span of trace
+func (m *Monitor) processUpdate(trace telemetry.SpanContext, account solana.TokenAccountInfo, initial bool) {
+	// The state of paused wallets stays frozen until they are resumed
+	if m.IsPaused(account.Owner) {
+		return
+	}
+	if m.isWrappedSOL(account) {
+		m.updateWrappedSOL(account, initial)
+		return
+	}
+
 	// Lock for state update
 	m.stateMutex.Lock()
 
@@ -158,38 +437,162 @@ func (m *Monitor) processAccountUpdate(account solana.TokenAccountInfo) {
 
 	// Check if this is a new account or if the balance has changed
 	oldAccount, exists := m.state[key]
-	balanceChanged := !exists || oldAccount.Balance != account.Balance
+
+	// The subscription and the poller both report updates. Data observed at
+	// an older slot than what is already known is stale, e.g. a poll that
+	// was answered before a change the subscription already delivered.
+	if exists && account.Slot != 0 && account.Slot < oldAccount.Slot {
+		m.stateMutex.Unlock()
+		m.log.Debug("Ignoring stale token account update",
+			"account", account.Address,
+			"slot", account.Slot,
+			"known", oldAccount.Slot,
+		)
+		return
+	}
+
+	// Pruned empty accounts stay out of the state until they are funded again
+	if m.zeroTTL > 0 && !m.trackZeroBalance(key, account, exists) {
+		m.stateMutex.Unlock()
+		return
+	}
+	// A change of decimals changes the amount the balance stands for
+	balanceChanged := !exists || oldAccount.Balance != account.Balance || oldAccount.Decimals != account.Decimals
+	delegated := m.delegates && exists && delegateChanged(oldAccount, account)
+	closeAuthority := m.authorities && exists && closeAuthorityChanged(oldAccount, account)
+	frozen := m.freezes && exists && oldAccount.Frozen != account.Frozen
+
+	// A receipt of a mint the wallet never held is a new holding
+	newHolding := false
+	if account.Balance > 0 && !m.held[key] {
+		m.held[key] = true
+		newHolding = !initial && (m.holdingLookup == nil || !m.holdingLookup(account.Owner, account.Mint))
+	}
 
 	// Update the state
 	m.state[key] = account
 
-	// Unlock after state update
-	m.stateMutex.Unlock()
+	// Unlock after queueing the event, so events are delivered in the order
+	// the state changed
+	defer m.stateMutex.Unlock()
 
 	// Notify handlers if balance changed
 	if balanceChanged {
-		logrus.WithFields(logrus.Fields{
-			"wallet":  account.Owner,
-			"mint":    account.Mint,
-			"balance": account.Balance,
-		}).Info("Token balance changed")
+		var groups map[string]GroupSummary
+		if !initial && len(m.groupWatches) > 0 {
+			groups = m.groupSnapshots(account)
+		}
 
-		// Notify all registered handlers
-		for _, handler := range m.handlers {
-			go handler(account)
+		m.log.Info("Token balance changed",
+			"wallet", account.Owner,
+			"mint", account.Mint,
+			"balance", account.Balance,
+		)
+
+		event := Event{Type: EventBalanceChanged, Account: account, Trace: trace}
+		if newHolding {
+			event.Type = EventNewHolding
 		}
+		if exists {
+			event.Previous = &oldAccount
+		}
+
+		// Notify all registered handlers
+		m.deliver(account.Owner, func() {
+			for _, handler := range m.handlers {
+				if !m.handlerEnabled(handler.name) {
+					continue
+				}
+				handler := handler
+				m.invoke(func() {
+					m.callHandler(handler.name, func() { handler.fn(account) })
+				})
+			}
+			if !initial || !m.seedSilently {
+				m.dispatchEvent(event)
+			}
+			if len(groups) > 0 {
+				m.checkGroupRules(account, groups)
+			}
+		})
+	}
+	if delegated {
+		m.emit(m.delegateEvent(oldAccount, account))
+	}
+	if frozen {
+		m.emitFreeze(m.freezeEvent(oldAccount, account))
+	}
+	if closeAuthority {
+		m.emit(m.authorityEvent(account, &oldAccount, AuthorityChange{
+			Kind:     AuthorityClose,
+			Previous: oldAccount.CloseAuthority,
+			Current:  account.CloseAuthority,
+		}))
 	}
 }
 
-// shouldTrackToken determines if a token should be tracked
-func (m *Monitor) shouldTrackToken(mint string) bool {
+// dispatchEvent labels an event and runs it through the pipeline, which ends
+// by notifying all registered event handlers
+func (m *Monitor) dispatchEvent(event Event) {
+	if len(m.eventHandlers) == 0 {
+		return
+	}
+
+	label := m.WalletLabel(event.Account.Owner)
+	event.Label = label.Label
+	event.Groups = label.Groups
+	event.Tenants = label.Tenants
+	event.Domain = m.WalletDomain(event.Account.Owner)
+	event.Network = m.network
+	// Enrichers make RPC calls that hot wallets can't wait for
+	event.Hot = m.IsHot(event.Account.Owner)
+
+	ctx, span := telemetry.Start(telemetry.ContextWithSpanContext(m.ctx, event.Trace), "monitor.dispatch", telemetry.KindInternal,
+		telemetry.String("event.type", string(event.Type)),
+		telemetry.String("wallet", event.Account.Owner),
+		telemetry.String("mint", event.Account.Mint),
+	)
+	defer span.End()
+	if span != nil {
+		event.Trace = span.Context()
+	}
+	m.pipeline()(ctx, event)
+}
+
+// SetNetwork tags every event with the name of the network the monitor
+// watches, for trackers watching several. Call it before Start.
+func (m *Monitor) SetNetwork(name string) {
+	m.network = name
+}
+
+// Network returns the name of the network events are tagged with, which is
+// empty unless set
+func (m *Monitor) Network() string {
+	return m.network
+}
+
+// SetWalletTokens sets per-wallet token filters that replace the global
+// tokens for those wallets. An empty list tracks every token of the wallet.
+func (m *Monitor) SetWalletTokens(tokens map[string][]string) {
+	m.walletTokens = tokens
+}
+
+// shouldTrackToken determines if a token should be tracked for a wallet
+func (m *Monitor) shouldTrackToken(owner, mint string) bool {
+	tokens := m.tokens
+	m.registryMutex.RLock()
+	if walletTokens, ok := m.walletTokens[owner]; ok {
+		tokens = walletTokens
+	}
+	m.registryMutex.RUnlock()
+
 	// If no tokens are specified, track all tokens
-	if len(m.tokens) == 0 {
+	if len(tokens) == 0 {
 		return true
 	}
 
 	// Check if the token is in the list
-	for _, token := range m.tokens {
+	for _, token := range tokens {
 		if token == mint {
 			return true
 		}
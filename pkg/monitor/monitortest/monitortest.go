@@ -0,0 +1,133 @@
+// Package monitortest drives a Monitor from an in-memory data source and a
+// fake clock, so the handlers and rules of applications embedding the
+// tracker can be tested deterministically
+package monitortest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// Epoch is the time fake clocks start at
+var Epoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Clock is a monitor clock that only moves when advanced
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock creates a clock set to start
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Now returns the time of the clock
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Harness runs a Monitor on a solana.MockClient and a fake Clock. Events are
+// delivered in sequence and recorded, and every call returns once the events
+// it caused have reached the handlers.
+//
+// The monitor has no RPC client, so features that look up the chain, such
+// as wrapped SOL folding, freeze alerts and stake or NFT monitoring, can't
+// be enabled on it.
+type Harness struct {
+	Monitor *monitor.Monitor
+	Source  *solana.MockClient
+	Clock   *Clock
+
+	wallets []string
+	mu      sync.Mutex
+	events  []monitor.Event
+}
+
+// New creates a harness of a monitor of wallets, tracking tokens or every
+// token when empty. Configure the monitor and seed Source before Start.
+func New(wallets, tokens []string) *Harness {
+	h := &Harness{
+		Monitor: monitor.New(nil, monitor.WithWallets(wallets...), monitor.WithTokens(tokens...)),
+		Source:  solana.NewMockClient(),
+		Clock:   NewClock(Epoch),
+		wallets: wallets,
+	}
+	h.Monitor.SetDataSource(h.Source)
+	h.Monitor.SetClock(h.Clock)
+	h.Monitor.SetDeliveryMode(monitor.DeliverySequential)
+	// A fresh monitor accepts the handler
+	_ = h.Monitor.RegisterEventHandler(h.record)
+	return h
+}
+
+// Start loads the initial state from Source and waits until every wallet
+// is subscribed
+func (h *Harness) Start() error {
+	if err := h.Monitor.Start(context.Background()); err != nil {
+		return err
+	}
+	for _, wallet := range h.wallets {
+		h.Source.WaitForSubscription(wallet)
+	}
+	h.Monitor.Flush()
+	return nil
+}
+
+// Stop stops the monitor
+func (h *Harness) Stop() {
+	h.Monitor.Stop()
+}
+
+// Update delivers an update of a token account to the subscription of its
+// owner, as if the chain had changed
+func (h *Harness) Update(account solana.TokenAccountInfo) {
+	if account.LastUpdatedAt.IsZero() {
+		account.LastUpdatedAt = h.Clock.Now()
+	}
+	h.Source.Push(account)
+	h.Monitor.Flush()
+}
+
+// Transaction delivers a transaction mentioning a wallet to its log
+// subscription, which feeds watch-only mode
+func (h *Harness) Transaction(wallet string, signature solana.SignatureInfo) {
+	h.Source.AddTransaction(wallet, signature)
+	h.Monitor.Flush()
+}
+
+// Advance moves the clock forward by d and runs a round of the poller, which
+// applies the time based rules such as zero balance pruning and staleness
+func (h *Harness) Advance(d time.Duration) {
+	h.Clock.Advance(d)
+	h.Monitor.PollOnce(context.Background())
+}
+
+// Events returns the events delivered since the last call, in order
+func (h *Harness) Events() []monitor.Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	events := h.events
+	h.events = nil
+	return events
+}
+
+// record is the event handler recording deliveries
+func (h *Harness) record(event monitor.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, event)
+}
@@ -0,0 +1,126 @@
+package monitortest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+const (
+	wallet = "wallet"
+	usdc   = "usdc"
+	bonk   = "bonk"
+)
+
+// account returns a token account of wallet
+func account(address, mint string, balance uint64) solana.TokenAccountInfo {
+	return solana.TokenAccountInfo{Address: address, Owner: wallet, Mint: mint, Balance: balance, Decimals: 6}
+}
+
+// start creates a harness of wallet holding accounts and starts it
+func start(t *testing.T, accounts ...solana.TokenAccountInfo) *Harness {
+	t.Helper()
+	h := New([]string{wallet}, nil)
+	h.Source.SetTokenAccounts(wallet, accounts...)
+	if err := h.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(h.Stop)
+	// The balances loaded at startup are reported once
+	h.Events()
+	return h
+}
+
+func TestEvents(t *testing.T) {
+	type want struct {
+		typ      monitor.EventType
+		address  string
+		balance  uint64
+		previous uint64
+	}
+
+	tests := []struct {
+		name   string
+		change func(h *Harness)
+		want   []want
+	}{
+		{
+			name: "new holding",
+			change: func(h *Harness) {
+				h.Update(account("a2", bonk, 1000))
+			},
+			want: []want{{monitor.EventNewHolding, "a2", 1000, 0}},
+		},
+		{
+			name: "balance change",
+			change: func(h *Harness) {
+				h.Update(account("a1", usdc, 2500000))
+			},
+			want: []want{{monitor.EventBalanceChanged, "a1", 2500000, 1500000}},
+		},
+		{
+			name: "unchanged balance",
+			change: func(h *Harness) {
+				h.Update(account("a1", usdc, 1500000))
+			},
+		},
+		{
+			name: "closed account",
+			change: func(h *Harness) {
+				// The account is missing from the next poll
+				h.Source.SetTokenAccounts(wallet)
+				h.Advance(time.Minute)
+			},
+			want: []want{{monitor.EventAccountClosed, "a1", 0, 1500000}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := start(t, account("a1", usdc, 1500000))
+			tt.change(h)
+
+			events := h.Events()
+			if len(events) != len(tt.want) {
+				t.Fatalf("got %d events %v, want %d", len(events), events, len(tt.want))
+			}
+			for i, event := range events {
+				w := tt.want[i]
+				if event.Type != w.typ || event.Account.Address != w.address || event.Account.Balance != w.balance {
+					t.Errorf("event %d = %s of %s with balance %d, want %s of %s with balance %d",
+						i, event.Type, event.Account.Address, event.Account.Balance, w.typ, w.address, w.balance)
+				}
+				var previous uint64
+				if event.Previous != nil {
+					previous = event.Previous.Balance
+				}
+				if previous != w.previous {
+					t.Errorf("event %d previous balance = %d, want %d", i, previous, w.previous)
+				}
+			}
+		})
+	}
+}
+
+func TestClosedAccountLeavesState(t *testing.T) {
+	h := start(t, account("a1", usdc, 1500000), account("a2", bonk, 1000))
+	h.Source.SetTokenAccounts(wallet, account("a2", bonk, 1000))
+	h.Advance(time.Minute)
+
+	state := h.Monitor.GetCurrentState()
+	if _, ok := state[wallet+":"+usdc]; ok {
+		t.Errorf("closed account is still in the state")
+	}
+	if got := state[wallet+":"+bonk].Balance; got != 1000 {
+		t.Errorf("balance of the open account = %d, want 1000", got)
+	}
+
+	// A later deposit to the mint is no new holding, the wallet held it
+	h.Events()
+	h.Update(account("a3", usdc, 10))
+	events := h.Events()
+	if len(events) != 1 || events[0].Type != monitor.EventBalanceChanged {
+		t.Errorf("got events %v, want one balance change", events)
+	}
+}
@@ -0,0 +1,109 @@
+package monitor
+
+import (
+	"context"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/das"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// EnableNFTMonitoring turns on polling of NFT and compressed NFT holdings
+// through a DAS API provider
+func (m *Monitor) EnableNFTMonitoring(client *das.Client) {
+	m.das = client
+}
+
+// pollNFTs refreshes the NFT holdings of all wallets and emits events for
+// received, sent, listed and burned assets
+func (m *Monitor) pollNFTs(ctx context.Context, initial bool) {
+	for _, wallet := range m.activeWallets() {
+		assets, err := m.das.GetAssetsByOwner(ctx, wallet)
+		if err != nil {
+			m.log.Error("Failed to poll NFTs", "wallet", wallet, "error", err)
+			continue
+		}
+
+		current := make(map[string]das.Asset, len(assets))
+		for _, asset := range assets {
+			current[asset.ID] = asset
+		}
+
+		m.stateMutex.Lock()
+		previous := m.nfts[wallet]
+		m.nfts[wallet] = current
+		m.stateMutex.Unlock()
+
+		if initial || previous == nil {
+			continue
+		}
+
+		for id, asset := range current {
+			if m.skipPolledAsset(asset) {
+				continue
+			}
+			old, held := previous[id]
+			switch {
+			case !held:
+				m.emitNFTEvent(EventNFTReceived, wallet, asset, nil)
+			case !old.Listed() && asset.Listed():
+				m.emitNFTEvent(EventNFTListed, wallet, asset, nil)
+			}
+		}
+
+		for id, asset := range previous {
+			if _, held := current[id]; held || m.skipPolledAsset(asset) {
+				continue
+			}
+
+			// The asset left the wallet: either transferred away or burned
+			eventType := EventNFTSent
+			if latest, err := m.das.GetAsset(ctx, id); err == nil {
+				asset = *latest
+				if latest.Burnt {
+					eventType = EventNFTBurned
+				}
+			}
+			m.emitNFTEvent(eventType, wallet, asset, nil)
+		}
+	}
+}
+
+// skipPolledAsset reports whether holding changes of an asset are left to
+// the Bubblegum log watcher, which sees them as they happen
+func (m *Monitor) skipPolledAsset(asset das.Asset) bool {
+	return m.cnftLogs && asset.Compressed
+}
+
+// emitNFTEvent dispatches an NFT event. The asset is mirrored into Account
+// so wallet-keyed consumers can route it, along with the slot and signature
+// of the causing transaction when it is known.
+func (m *Monitor) emitNFTEvent(eventType EventType, wallet string, asset das.Asset, tx *solana.Transaction) {
+	m.log.Info("NFT holding changed",
+		"wallet", wallet,
+		"asset", asset.ID,
+		"name", asset.Name,
+		"event", eventType,
+	)
+
+	balance := uint64(1)
+	if eventType == EventNFTSent || eventType == EventNFTBurned {
+		balance = 0
+	}
+
+	account := solana.TokenAccountInfo{
+		Address: asset.ID,
+		Owner:   wallet,
+		Mint:    asset.ID,
+		Balance: balance,
+	}
+	if tx != nil {
+		account.Slot = tx.Slot
+		account.Signature = tx.Signature
+	}
+
+	m.emit(Event{
+		Type:    eventType,
+		Account: account,
+		NFT:     &asset,
+	})
+}
@@ -0,0 +1,55 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+)
+
+// Option configures a Monitor created with New
+type Option func(*Monitor)
+
+// WithWallets sets the wallets to monitor
+func WithWallets(wallets ...string) Option {
+	return func(m *Monitor) {
+		m.wallets = append(m.wallets, wallets...)
+	}
+}
+
+// WithTokens limits monitoring to the given mints. Without it every token
+// of the wallets is tracked.
+func WithTokens(mints ...string) Option {
+	return func(m *Monitor) {
+		m.tokens = append(m.tokens, mints...)
+	}
+}
+
+// WithPollInterval sets how often token accounts are polled to catch
+// missed updates (default 30s)
+func WithPollInterval(interval time.Duration) Option {
+	return func(m *Monitor) {
+		if interval > 0 {
+			m.interval = interval
+		}
+	}
+}
+
+// WithPollPool polls the wallets on a pool of workers, with per-wallet
+// timeouts and batched lookups, for monitors of many wallets
+func WithPollPool(pool PollPool) Option {
+	return func(m *Monitor) {
+		m.pool = pool
+	}
+}
+
+// WithLogger sets the logger of the monitor, the standard logrus logger by
+// default. A *slog.Logger can be passed as is, and a logrus logger through
+// logging.Logrus. The logger also reaches enrichers through their context,
+// see logging.FromContext.
+func WithLogger(logger logging.Logger) Option {
+	return func(m *Monitor) {
+		if logger != nil {
+			m.log = logger
+		}
+	}
+}
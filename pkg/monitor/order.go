@@ -0,0 +1,111 @@
+package monitor
+
+// DeliveryMode controls the order in which handlers receive events
+type DeliveryMode string
+
+// Delivery modes
+const (
+	// DeliveryConcurrent runs every handler call in its own goroutine. It is
+	// the fastest, but events may reach handlers out of order.
+	DeliveryConcurrent DeliveryMode = "concurrent"
+	// DeliveryPerWallet delivers the events of each wallet one at a time in
+	// the order they were detected, while wallets proceed independently
+	DeliveryPerWallet DeliveryMode = "wallet"
+	// DeliverySequential delivers all events one at a time in the order they
+	// were detected
+	DeliverySequential DeliveryMode = "sequential"
+)
+
+// deliveryQueue holds the pending deliveries of one wallet, or of all
+// wallets in sequential mode
+type deliveryQueue struct {
+	jobs    []func()
+	running bool
+}
+
+// SetDeliveryMode sets how events are delivered to handlers. In the ordered
+// modes enrichers and handlers run in turn, so a slow handler holds back the
+// events queued behind it. Call it before Start.
+func (m *Monitor) SetDeliveryMode(mode DeliveryMode) {
+	m.delivery = mode
+}
+
+// ordered reports whether deliveries are queued
+func (m *Monitor) ordered() bool {
+	return m.delivery == DeliveryPerWallet || m.delivery == DeliverySequential
+}
+
+// emit dispatches an event in the wallet's delivery order
+func (m *Monitor) emit(event Event) {
+	m.deliver(event.Account.Owner, func() { m.dispatchEvent(event) })
+}
+
+// deliver runs a job after the jobs already queued for the wallet, or at
+// once in its own goroutine when deliveries are concurrent. A worker drains
+// each queue and exits once it is empty, so wallets that only appear in
+// mint watch events don't keep goroutines around.
+func (m *Monitor) deliver(wallet string, job func()) {
+	tracked := m.pressure.track(job)
+	m.pending.Add(1)
+	job = func() {
+		defer m.pending.Done()
+		tracked()
+	}
+	if !m.ordered() {
+		go job()
+		return
+	}
+
+	key := wallet
+	if m.delivery == DeliverySequential && !m.IsHot(wallet) {
+		key = ""
+	}
+
+	m.queueMutex.Lock()
+	queue, ok := m.queues[key]
+	if !ok {
+		queue = &deliveryQueue{}
+		m.queues[key] = queue
+	}
+	queue.jobs = append(queue.jobs, job)
+	start := !queue.running
+	queue.running = true
+	m.queueMutex.Unlock()
+
+	if start {
+		go m.drainQueue(key, queue)
+	}
+}
+
+// drainQueue runs the jobs of a queue in order until it is empty
+func (m *Monitor) drainQueue(key string, queue *deliveryQueue) {
+	for {
+		m.queueMutex.Lock()
+		if len(queue.jobs) == 0 {
+			queue.running = false
+			delete(m.queues, key)
+			m.queueMutex.Unlock()
+			return
+		}
+		job := queue.jobs[0]
+		queue.jobs[0] = nil
+		queue.jobs = queue.jobs[1:]
+		m.queueMutex.Unlock()
+
+		job()
+	}
+}
+
+// invoke calls a handler, in its own goroutine unless deliveries are ordered
+func (m *Monitor) invoke(call func()) {
+	if m.ordered() {
+		call()
+		return
+	}
+	tracked := m.pressure.track(call)
+	m.pending.Add(1)
+	go func() {
+		defer m.pending.Done()
+		tracked()
+	}()
+}
@@ -0,0 +1,133 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUnknownWallet is returned when pausing or resuming a wallet that isn't
+// tracked
+var ErrUnknownWallet = errors.New("wallet is not tracked")
+
+// startWallet subscribes to the updates of a wallet until it is paused or
+// the monitor stops
+func (m *Monitor) startWallet(wallet string) {
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.walletMutex.Lock()
+	m.walletCancels[wallet] = cancel
+	m.walletMutex.Unlock()
+
+	// Updates of ingested wallets are pushed to Ingest
+	if m.ingestOnly {
+		return
+	}
+	if m.watchOnly {
+		go m.watchSignatures(ctx, wallet)
+		return
+	}
+
+	go m.watchWallet(ctx, wallet)
+
+	// Follow the transactions of the wallet for Bubblegum activity and rent
+	if m.watchesLogs() {
+		go m.watchWalletLogs(ctx, wallet)
+	}
+}
+
+// PauseWallet stops monitoring a wallet without removing it. Its
+// subscriptions are torn down, polling skips it and its state is kept as it
+// was until ResumeWallet.
+func (m *Monitor) PauseWallet(wallet string) error {
+	if !m.tracks(wallet) {
+		return ErrUnknownWallet
+	}
+
+	m.walletMutex.Lock()
+	defer m.walletMutex.Unlock()
+
+	if m.paused[wallet] {
+		return nil
+	}
+	m.paused[wallet] = true
+	if cancel, ok := m.walletCancels[wallet]; ok {
+		cancel()
+		delete(m.walletCancels, wallet)
+	}
+
+	m.log.Info("Wallet monitoring paused", "wallet", wallet)
+	return nil
+}
+
+// ResumeWallet resubscribes to a paused wallet and refreshes its state
+// within ctx, so changes made while it was paused produce events
+func (m *Monitor) ResumeWallet(ctx context.Context, wallet string) error {
+	if !m.tracks(wallet) {
+		return ErrUnknownWallet
+	}
+
+	m.walletMutex.Lock()
+	wasPaused := m.paused[wallet]
+	delete(m.paused, wallet)
+	m.walletMutex.Unlock()
+
+	if !wasPaused {
+		return nil
+	}
+
+	m.startWallet(wallet)
+	m.refreshWallet(ctx, wallet)
+
+	m.log.Info("Wallet monitoring resumed", "wallet", wallet)
+	return nil
+}
+
+// IsPaused reports whether monitoring of a wallet is paused
+func (m *Monitor) IsPaused(wallet string) bool {
+	m.walletMutex.Lock()
+	defer m.walletMutex.Unlock()
+	return m.paused[wallet]
+}
+
+// ActiveWallets returns the number of wallets that aren't paused
+func (m *Monitor) ActiveWallets() int {
+	return len(m.activeWallets())
+}
+
+// activeWallets returns the wallets that aren't paused
+func (m *Monitor) activeWallets() []string {
+	m.walletMutex.Lock()
+	defer m.walletMutex.Unlock()
+
+	tracked := m.trackedWallets()
+	wallets := make([]string, 0, len(tracked))
+	for _, wallet := range tracked {
+		if !m.paused[wallet] {
+			wallets = append(wallets, wallet)
+		}
+	}
+	return wallets
+}
+
+// tracks reports whether a wallet is one of the monitored wallets
+func (m *Monitor) tracks(wallet string) bool {
+	for _, tracked := range m.trackedWallets() {
+		if tracked == wallet {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshWallet reloads the token accounts of a wallet
+func (m *Monitor) refreshWallet(ctx context.Context, wallet string) {
+	if m.watchOnly {
+		return
+	}
+	accounts, err := m.source.GetTokenAccounts(ctx, wallet)
+	if err != nil {
+		m.log.Error("Failed to refresh token accounts", "wallet", wallet, "error", err)
+		return
+	}
+	m.recordPoll(wallet)
+	m.applySnapshot(ctx, wallet, accounts, m.completeLoad(wallet))
+}
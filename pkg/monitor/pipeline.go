@@ -0,0 +1,116 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/telemetry"
+)
+
+// Phase is a step of the event pipeline. Events pass through the phases in
+// order: filter, enrich, route and deliver.
+type Phase int
+
+// Pipeline phases
+const (
+	// PhaseFilter drops events that aren't worth handling, e.g. dust
+	PhaseFilter Phase = iota
+	// PhaseEnrich attaches information looked up for the event
+	PhaseEnrich
+	// PhaseRoute decides, from the enriched event, whether and how it is
+	// delivered
+	PhaseRoute
+	// PhaseDeliver wraps the delivery to the event handlers
+	PhaseDeliver
+
+	phaseCount
+)
+
+// Next passes an event on to the rest of the pipeline
+type Next func(ctx context.Context, event Event)
+
+// Middleware is a pipeline stage. It receives the rest of the pipeline and
+// returns a Next that handles the event, calling next to pass it on. A stage
+// drops the event by not calling next.
+type Middleware func(next Next) Next
+
+// Use adds a stage to a phase of the pipeline. Stages of a phase run in the
+// order they were added, after the stages of earlier phases. Call it before
+// Start.
+func (m *Monitor) Use(phase Phase, middleware Middleware) {
+	if phase < 0 || phase >= phaseCount {
+		phase = PhaseRoute
+	}
+	m.stages[phase] = append(m.stages[phase], middleware)
+}
+
+// RegisterEnricher registers an enricher that runs before events are dispatched
+func (m *Monitor) RegisterEnricher(enricher Enricher) {
+	m.Use(PhaseEnrich, EnrichStage(enricher))
+}
+
+// FilterStage turns a filter into a pipeline stage
+func FilterStage(filter Filter) Middleware {
+	return func(next Next) Next {
+		return func(ctx context.Context, event Event) {
+			if filter(ctx, event) {
+				next(ctx, event)
+			}
+		}
+	}
+}
+
+// EnrichStage turns an enricher into a pipeline stage. Events of hot wallets
+// pass through without the enricher's RPC calls.
+func EnrichStage(enricher Enricher) Middleware {
+	return func(next Next) Next {
+		return func(ctx context.Context, event Event) {
+			if !event.Hot {
+				enricher(ctx, &event)
+			}
+			next(ctx, event)
+		}
+	}
+}
+
+// pipeline chains the stages of every phase in front of the delivery to the
+// event handlers
+func (m *Monitor) pipeline() Next {
+	next := m.deliverEvent
+	for phase := phaseCount - 1; phase >= 0; phase-- {
+		stages := m.stages[phase]
+		for i := len(stages) - 1; i >= 0; i-- {
+			next = stages[i](next)
+		}
+	}
+	return next
+}
+
+// deliverEvent calls every event handler, ending the pipeline. Each call is
+// a span of its own, which the event passed to the handler carries.
+func (m *Monitor) deliverEvent(ctx context.Context, event Event) {
+	m.observeLatency(event, m.now())
+	for _, handler := range m.eventHandlers {
+		if !m.handlerEnabled(handler.name) {
+			continue
+		}
+		handler := handler
+		m.invoke(func() {
+			_, span := telemetry.Start(ctx, "monitor.handler", telemetry.KindInternal,
+				telemetry.String("handler", handler.name),
+				telemetry.String("event.type", string(event.Type)),
+			)
+			event := event
+			if span != nil {
+				event.Trace = span.Context()
+			}
+			start := time.Now()
+			m.callHandler(handler.name, func() { handler.fn(event) })
+			span.End()
+			handlerDuration.Record(float64(time.Since(start))/float64(time.Millisecond),
+				telemetry.String("handler", handler.name),
+				telemetry.String("event.type", string(event.Type)),
+			)
+		})
+	}
+}
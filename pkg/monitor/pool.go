@@ -0,0 +1,242 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// accountBatchSize is how many token accounts are looked up by address per
+// request, the most getMultipleAccounts accepts
+const accountBatchSize = 100
+
+// defaultDiscoveryInterval is how often wallets are polled by owner when
+// known token accounts are polled by address
+const defaultDiscoveryInterval = 5 * time.Minute
+
+// PollPool sizes the workers of the periodic poller. The zero value runs
+// one lookup at a time, without timeouts or batches.
+type PollPool struct {
+	// Workers is how many token account lookups run at once
+	Workers int
+	// Timeout bounds the lookups of a wallet, or of a batch of them, in a
+	// poll. Wallets timing out are polled again on the next round.
+	Timeout time.Duration
+	// BatchSize is how many wallets share a lookup when the data source
+	// supports batches, see solana.BatchSource
+	BatchSize int
+	// InitialWorkers is how many wallets are loaded at once when the
+	// monitor starts (default 8)
+	InitialWorkers int
+	// DiscoveryInterval is how often the wallets are polled by owner, which
+	// finds their new token accounts (default 5m). In between, the token
+	// accounts already known are looked up by address, 100 per request, when
+	// the data source supports it, see solana.AccountSource.
+	DiscoveryInterval time.Duration
+}
+
+// discoveryInterval returns the discovery interval, defaulted
+func (p PollPool) discoveryInterval() time.Duration {
+	if p.DiscoveryInterval <= 0 {
+		return defaultDiscoveryInterval
+	}
+	return p.DiscoveryInterval
+}
+
+// workers returns the number of workers, at least one
+func (p PollPool) workers() int {
+	if p.Workers < 1 {
+		return 1
+	}
+	return p.Workers
+}
+
+// batches splits wallets into the batches of the pool
+func (p PollPool) batches(wallets []string, batching bool) [][]string {
+	size := p.BatchSize
+	if size < 1 || !batching {
+		size = 1
+	}
+	batches := make([][]string, 0, (len(wallets)+size-1)/size)
+	for len(wallets) > size {
+		batches = append(batches, wallets[:size])
+		wallets = wallets[size:]
+	}
+	if len(wallets) > 0 {
+		batches = append(batches, wallets)
+	}
+	return batches
+}
+
+// pollWallets polls the token accounts of wallets by owner on the workers
+// of the pool, and reports whether it stopped early for the event queue
+func (m *Monitor) pollWallets(ctx context.Context, wallets []string) (saturated bool) {
+	batcher, batching := m.source.(solana.BatchSource)
+	return m.runPool(ctx, m.pool.batches(wallets, batching), func(ctx context.Context, batch []string) bool {
+		return m.pollBatch(ctx, batcher, batch)
+	})
+}
+
+// runPool hands out batches to the workers of the pool, each run within the
+// timeout of the pool. It stops handing them out when the event queue
+// saturates or a batch reports that the source rate limited it, and reports
+// whether it stopped for the event queue.
+func (m *Monitor) runPool(ctx context.Context, batches [][]string,
+	run func(ctx context.Context, batch []string) (limited bool)) (saturated bool) {
+	jobs := make(chan []string)
+	limited := make(chan struct{})
+	var once sync.Once
+	var workers sync.WaitGroup
+	for i := 0; i < m.pool.workers() && i < len(batches); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for batch := range jobs {
+				if m.runBatch(ctx, batch, run) {
+					once.Do(func() { close(limited) })
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, batch := range batches {
+		if m.pressure.saturated() {
+			saturated = true
+			break
+		}
+		select {
+		case jobs <- batch:
+		case <-limited:
+			break feed
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	workers.Wait()
+	return saturated
+}
+
+// runBatch runs a batch within the timeout of the pool
+func (m *Monitor) runBatch(ctx context.Context, batch []string,
+	run func(ctx context.Context, batch []string) bool) bool {
+	if m.pool.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.pool.Timeout)
+		defer cancel()
+	}
+	return run(ctx, batch)
+}
+
+// pollBatch looks up and processes the token accounts of a batch of
+// wallets, and reports whether the source rate limited it
+func (m *Monitor) pollBatch(ctx context.Context, batcher solana.BatchSource, wallets []string) (limited bool) {
+	var results []solana.TokenAccountsResult
+	if len(wallets) > 1 {
+		results = batcher.GetTokenAccountsBatch(ctx, wallets)
+	} else {
+		accounts, err := m.source.GetTokenAccounts(ctx, wallets[0])
+		results = []solana.TokenAccountsResult{{Wallet: wallets[0], Accounts: accounts, Err: err}}
+	}
+
+	for _, result := range results {
+		if errors.Is(result.Err, solana.ErrRateLimited) {
+			// The remaining wallets would be throttled too
+			if !limited {
+				m.log.Warn("Token account polling rate limited, skipping until the next poll", "error", result.Err)
+			}
+			limited = true
+			continue
+		}
+		if result.Err != nil {
+			m.log.Error("Failed to poll token accounts", "wallet", result.Wallet, "error", result.Err)
+			continue
+		}
+		m.recordPoll(result.Wallet)
+		m.applySnapshot(ctx, result.Wallet, result.Accounts, m.completeLoad(result.Wallet))
+	}
+	return limited
+}
+
+// knownAccounts splits wallets into those with token accounts in the state,
+// returned by address, and those without any, which are left to be polled by
+// owner
+func (m *Monitor) knownAccounts(wallets []string) (map[string]solana.TokenAccountInfo, []string) {
+	active := make(map[string]bool, len(wallets))
+	for _, wallet := range wallets {
+		active[wallet] = true
+	}
+
+	m.stateMutex.RLock()
+	known := make(map[string]solana.TokenAccountInfo)
+	holding := make(map[string]bool, len(wallets))
+	for _, account := range m.state {
+		// The SOL holding isn't a token account
+		if !active[account.Owner] || account.Address == account.Owner {
+			continue
+		}
+		known[account.Address] = account
+		holding[account.Owner] = true
+	}
+	m.stateMutex.RUnlock()
+
+	var unknown []string
+	for _, wallet := range wallets {
+		if !holding[wallet] {
+			unknown = append(unknown, wallet)
+		}
+	}
+	return known, unknown
+}
+
+// pollAccounts looks up known token accounts by address on the workers of
+// the pool, accountBatchSize per request, and reports whether it stopped
+// early for the event queue
+func (m *Monitor) pollAccounts(ctx context.Context, lookup solana.AccountSource,
+	known map[string]solana.TokenAccountInfo) (saturated bool) {
+	addresses := make([]string, 0, len(known))
+	for address := range known {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	var batches [][]string
+	for len(addresses) > accountBatchSize {
+		batches = append(batches, addresses[:accountBatchSize])
+		addresses = addresses[accountBatchSize:]
+	}
+	if len(addresses) > 0 {
+		batches = append(batches, addresses)
+	}
+
+	return m.runPool(ctx, batches, func(ctx context.Context, batch []string) bool {
+		accounts, err := lookup.GetTokenAccountsByAddress(ctx, batch)
+		if errors.Is(err, solana.ErrRateLimited) {
+			m.log.Warn("Token account polling rate limited, skipping until the next poll", "error", err)
+			return true
+		}
+		if err != nil {
+			m.log.Error("Failed to poll token accounts", "accounts", len(batch), "error", err)
+			return false
+		}
+
+		previous := make(map[string]solana.TokenAccountInfo, len(batch))
+		polled := make(map[string]bool)
+		for _, address := range batch {
+			previous[address] = known[address]
+			polled[known[address].Owner] = true
+		}
+		// Accounts missing from the lookup were closed, and those of
+		// another owner moved
+		m.applyChanges(diffSnapshot(previous, accounts), false)
+		for wallet := range polled {
+			m.recordPoll(wallet)
+		}
+		return false
+	})
+}
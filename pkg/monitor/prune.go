@@ -0,0 +1,59 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// SetZeroBalanceTTL removes token accounts from the live state once their
+// balance has been zero for ttl, so long running trackers don't accumulate
+// dead dust accounts. A pruned account returns when it is funded again.
+func (m *Monitor) SetZeroBalanceTTL(ttl time.Duration) {
+	m.zeroTTL = ttl
+}
+
+// trackZeroBalance records when an account became empty. It reports false
+// for updates of pruned accounts that are still empty, which should be
+// ignored. Callers must hold the state lock.
+func (m *Monitor) trackZeroBalance(key string, account solana.TokenAccountInfo, exists bool) bool {
+	if account.Balance > 0 {
+		delete(m.zeroSince, key)
+		delete(m.pruned, key)
+		return true
+	}
+
+	if !exists && m.pruned[key] {
+		return false
+	}
+	if _, ok := m.zeroSince[key]; !ok {
+		m.zeroSince[key] = m.now()
+	}
+	return true
+}
+
+// pruneZeroBalances drops accounts that have been empty for longer than the TTL
+func (m *Monitor) pruneZeroBalances() {
+	cutoff := m.now().Add(-m.zeroTTL)
+
+	m.stateMutex.Lock()
+	pruned := 0
+	for key, since := range m.zeroSince {
+		if since.After(cutoff) {
+			continue
+		}
+		delete(m.state, key)
+		delete(m.zeroSince, key)
+		m.pruned[key] = true
+		pruned++
+	}
+	remaining := len(m.state)
+	m.stateMutex.Unlock()
+
+	if pruned > 0 {
+		m.log.Debug("Pruned empty token accounts from live state",
+			"pruned", pruned,
+			"remaining", remaining,
+		)
+	}
+}
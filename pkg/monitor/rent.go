@@ -0,0 +1,36 @@
+package monitor
+
+import (
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// RentHandler is called with rent a tracked wallet paid to create an
+// account, or reclaimed by closing one, in a transaction
+type RentHandler func(wallet string, tx *solana.Transaction, change solana.RentChange)
+
+// TrackRent turns on detection of the rent the tracked wallets pay for the
+// accounts they create and reclaim from the token accounts they close. Only
+// transactions seen while the tracker runs are covered. Call it before Start.
+func (m *Monitor) TrackRent(handler RentHandler) {
+	m.rentHandler = handler
+}
+
+// processRentChanges reports the rent changes of a transaction that concern
+// the wallet
+func (m *Monitor) processRentChanges(wallet string, tx *solana.Transaction) {
+	for _, change := range solana.ParseRentChanges(tx) {
+		if change.Wallet != wallet {
+			continue
+		}
+
+		m.log.Debug("Detected account rent",
+			"wallet", wallet,
+			"account", change.Account,
+			"lamports", change.Lamports,
+			"reclaimed", change.Reclaimed,
+			"signature", tx.Signature,
+		)
+
+		m.callHandler("rent handler", func() { m.rentHandler(wallet, tx, change) })
+	}
+}
@@ -0,0 +1,61 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// HistoryEvents rebuilds the balance events behind stored observations,
+// oldest first. Observations before from only establish the previous
+// balances; each later one that changed a balance becomes a balance changed
+// event, or a new holding event the first time the wallet held the mint.
+func HistoryEvents(records []solana.TokenAccountInfo, from time.Time) []Event {
+	state := make(map[string]solana.TokenAccountInfo)
+	held := make(map[string]bool)
+
+	var events []Event
+	for _, account := range records {
+		key := account.Owner + ":" + account.Mint
+		oldAccount, exists := state[key]
+		state[key] = account
+
+		newHolding := account.Balance > 0 && !held[key]
+		if account.Balance > 0 {
+			held[key] = true
+		}
+
+		if account.LastUpdatedAt.Before(from) || (exists && oldAccount.Balance == account.Balance) {
+			continue
+		}
+
+		event := Event{Type: EventBalanceChanged, Account: account}
+		// The first observation of a wallet is its balance when tracking
+		// started, not a receipt
+		if newHolding && exists {
+			event.Type = EventNewHolding
+		}
+		if exists {
+			event.Previous = &oldAccount
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// Replay runs past events through the pipeline in order, marked as replayed.
+// Balance change handlers aren't called, so replayed changes aren't stored
+// again. Handlers are called in turn, so Replay returns once every event was
+// handled. Use it on a monitor that isn't started.
+func (m *Monitor) Replay(ctx context.Context, events []Event) error {
+	m.delivery = DeliverySequential
+	for _, event := range events {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		event.Replayed = true
+		m.dispatchEvent(event)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package monitor
+
+import (
+	"errors"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/geyser"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// rateLimitBackoff multiplies retry delays while the provider throttles us
+const rateLimitBackoff = 6
+
+// retryDelay returns how long to wait before retrying after err, and false
+// when retrying can't succeed
+func retryDelay(err error, delay time.Duration) (time.Duration, bool) {
+	switch {
+	case errors.Is(err, solana.ErrProviderUnsupported), errors.Is(err, solana.ErrInvalidAddress),
+		errors.Is(err, geyser.ErrUnauthenticated):
+		return 0, false
+	case errors.Is(err, solana.ErrRateLimited):
+		return delay * rateLimitBackoff, true
+	}
+	return delay, true
+}
@@ -0,0 +1,98 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// SignatureEvent is a transaction of a signature feed
+type SignatureEvent struct {
+	Signature string `json:"signature"`
+	Slot      uint64 `json:"slot"`
+	// Failed marks transactions that landed with an error
+	Failed bool `json:"failed,omitempty"`
+}
+
+// WatchOnly turns the monitor into a signature feed. Each wallet gets a
+// single logs subscription mentioning it, and every transaction it delivers
+// is emitted as a signature event, without loading token accounts, diffing
+// balances or polling. Features built on token account state are off. Call
+// it before Start.
+func (m *Monitor) WatchOnly() {
+	m.watchOnly = true
+}
+
+// startSignatureFeed subscribes to the transactions of each wallet. The
+// poller only runs for the staleness checks.
+func (m *Monitor) startSignatureFeed() error {
+	for _, wallet := range m.trackedWallets() {
+		m.startWallet(wallet)
+	}
+	go m.startPeriodicPolling()
+
+	m.markStarted()
+	return nil
+}
+
+// watchSignatures emits the transactions mentioning a wallet until ctx is
+// cancelled, when the wallet is paused or the monitor stops
+func (m *Monitor) watchSignatures(ctx context.Context, wallet string) {
+	defer func() {
+		if ctx.Err() != nil {
+			m.setSubscriptionState(wallet, SubscriptionStopped, nil)
+		}
+	}()
+
+	for {
+		m.setSubscriptionState(wallet, SubscriptionActive, nil)
+		err := m.logSource().SubscribeToWalletLogs(ctx, wallet, func(notification solana.LogNotification) {
+			m.countUpdate(wallet)
+			m.emit(m.signatureEvent(wallet, notification))
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		delay, ok := retryDelay(err, logsRetryDelay)
+		if !ok {
+			m.log.Error("Signature feed stopped", "wallet", wallet, "error", err)
+			m.setSubscriptionState(wallet, SubscriptionFailed, err)
+			return
+		}
+		m.log.Error("Signature feed stopped, resubscribing", "wallet", wallet, "error", err)
+		m.setSubscriptionState(wallet, SubscriptionRetrying, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// signatureEvent describes a transaction mentioning a wallet. Its Account
+// carries the wallet as Address and Owner, without a mint.
+func (m *Monitor) signatureEvent(wallet string, notification solana.LogNotification) Event {
+	m.log.Debug("Wallet transaction",
+		"wallet", wallet,
+		"signature", notification.Signature,
+		"slot", notification.Slot,
+	)
+
+	return Event{
+		Type: EventSignature,
+		Account: solana.TokenAccountInfo{
+			Address:       wallet,
+			Owner:         wallet,
+			Slot:          notification.Slot,
+			Signature:     notification.Signature,
+			LastUpdatedAt: time.Now(),
+		},
+		Signature: &SignatureEvent{
+			Signature: notification.Signature,
+			Slot:      notification.Slot,
+			Failed:    notification.Failed,
+		},
+	}
+}
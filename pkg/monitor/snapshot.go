@@ -0,0 +1,240 @@
+package monitor
+
+import (
+	"context"
+	"sort"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// snapshotChange is the difference of a token account between the state
+// and a snapshot
+type snapshotChange struct {
+	// Previous is the account in the state, nil for accounts opened since
+	Previous *solana.TokenAccountInfo
+	// Current is the account in the snapshot, nil for accounts missing
+	// from it
+	Current *solana.TokenAccountInfo
+}
+
+// missing reports whether the account is missing from the snapshot
+func (c snapshotChange) missing() bool {
+	return c.Current == nil
+}
+
+// moved reports whether the account changed owner
+func (c snapshotChange) moved() bool {
+	return c.Previous != nil && c.Current != nil && c.Previous.Owner != c.Current.Owner
+}
+
+// diffSnapshot compares the token accounts of the state with a snapshot of
+// them, both by address, and returns the accounts that were opened, changed
+// or are missing, ordered by address. Slots and timestamps alone aren't
+// changes.
+func diffSnapshot(previous, current map[string]solana.TokenAccountInfo) []snapshotChange {
+	var changes []snapshotChange
+	for address, account := range current {
+		account := account
+		old, exists := previous[address]
+		if !exists {
+			changes = append(changes, snapshotChange{Current: &account})
+			continue
+		}
+		if !sameAccount(old, account) {
+			changes = append(changes, snapshotChange{Previous: &old, Current: &account})
+		}
+	}
+	for address, account := range previous {
+		account := account
+		if _, exists := current[address]; !exists {
+			changes = append(changes, snapshotChange{Previous: &account})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].address() < changes[j].address()
+	})
+	return changes
+}
+
+// address returns the address of the changed account
+func (c snapshotChange) address() string {
+	if c.Current != nil {
+		return c.Current.Address
+	}
+	return c.Previous.Address
+}
+
+// sameAccount reports whether two observations of a token account hold the
+// same data
+func sameAccount(a, b solana.TokenAccountInfo) bool {
+	return a.Owner == b.Owner &&
+		a.Mint == b.Mint &&
+		a.Balance == b.Balance &&
+		a.Decimals == b.Decimals &&
+		a.ProgramID == b.ProgramID &&
+		a.Delegate == b.Delegate &&
+		a.DelegatedAmount == b.DelegatedAmount &&
+		a.CloseAuthority == b.CloseAuthority &&
+		a.Frozen == b.Frozen
+}
+
+// walletSnapshot returns the tracked token accounts of a wallet in the
+// state, by address. The SOL holding isn't a token account and is left out.
+func (m *Monitor) walletSnapshot(wallet string) map[string]solana.TokenAccountInfo {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+
+	accounts := make(map[string]solana.TokenAccountInfo)
+	for _, account := range m.state {
+		if account.Owner == wallet && account.Address != wallet {
+			accounts[account.Address] = account
+		}
+	}
+	return accounts
+}
+
+// applySnapshot diffs the token accounts a poll found for a wallet against
+// its state and applies the differences in one pass: accounts opened or
+// changed are processed as updates, and those missing are looked up to tell
+// the ones closed from those moved to another owner.
+func (m *Monitor) applySnapshot(ctx context.Context, wallet string, accounts []solana.TokenAccountInfo, initial bool) {
+	current := make(map[string]solana.TokenAccountInfo, len(accounts))
+	for _, account := range accounts {
+		if m.shouldTrackToken(account.Owner, account.Mint) && !m.isWrappedSOL(account) {
+			current[account.Address] = account
+		}
+	}
+
+	changes := diffSnapshot(m.walletSnapshot(wallet), current)
+	m.applyChanges(m.resolveMissing(ctx, wallet, changes), initial)
+	m.syncWrappedSOL(wallet, accounts, initial)
+}
+
+// resolveMissing looks up the accounts missing from a snapshot of a wallet,
+// which were closed or moved to another owner. Accounts that can't be
+// looked up are left for the next poll; without an RPC client, missing
+// accounts are taken as closed.
+func (m *Monitor) resolveMissing(ctx context.Context, wallet string, changes []snapshotChange) []snapshotChange {
+	var addresses []string
+	for _, change := range changes {
+		if change.missing() {
+			addresses = append(addresses, change.Previous.Address)
+		}
+	}
+	if len(addresses) == 0 {
+		return changes
+	}
+
+	var lookup func(address string) (*solana.TokenAccountInfo, bool)
+	if source, ok := m.source.(solana.AccountSource); ok {
+		found, err := source.GetTokenAccountsByAddress(ctx, addresses)
+		lookup = func(address string) (*solana.TokenAccountInfo, bool) {
+			if err != nil {
+				return nil, false
+			}
+			if account, ok := found[address]; ok {
+				return &account, true
+			}
+			return nil, true
+		}
+		if err != nil {
+			m.log.Warn("Failed to look up token accounts missing from poll", "wallet", wallet, "error", err)
+		}
+	} else if m.client != nil {
+		lookup = func(address string) (*solana.TokenAccountInfo, bool) {
+			account, err := m.client.GetTokenAccount(ctx, address)
+			if err != nil {
+				m.log.Warn("Failed to look up token account missing from poll", "account", address, "wallet", wallet, "error", err)
+				return nil, false
+			}
+			return account, true
+		}
+	} else {
+		return changes
+	}
+
+	resolved := changes[:0]
+	for _, change := range changes {
+		if change.missing() {
+			account, ok := lookup(change.Previous.Address)
+			if !ok {
+				continue
+			}
+			change.Current = account
+		}
+		resolved = append(resolved, change)
+	}
+	return resolved
+}
+
+// applyChanges applies the differences of a snapshot to the state, emitting
+// the events they cause
+func (m *Monitor) applyChanges(changes []snapshotChange, initial bool) {
+	for _, change := range changes {
+		switch {
+		case change.missing():
+			m.accountClosed(*change.Previous)
+		case change.moved():
+			m.accountMoved(*change.Previous, *change.Current)
+		default:
+			if change.Previous != nil && change.Previous.Decimals != change.Current.Decimals {
+				m.log.Warn("Token account decimals changed",
+					"wallet", change.Current.Owner,
+					"account", change.Current.Address,
+					"previous", change.Previous.Decimals,
+					"current", change.Current.Decimals,
+				)
+			}
+			account := *change.Current
+			account.Source = solana.SourcePoll
+			m.processAccountUpdate(account, initial)
+		}
+	}
+}
+
+// accountClosed drops a closed token account from the state and reports it
+func (m *Monitor) accountClosed(previous solana.TokenAccountInfo) {
+	if m.IsPaused(previous.Owner) || !m.dropAccount(previous) {
+		return
+	}
+
+	m.log.Info("Token account closed",
+		"wallet", previous.Owner,
+		"account", previous.Address,
+		"mint", previous.Mint,
+	)
+	account := previous
+	account.Balance = 0
+	account.LastUpdatedAt = m.now()
+	m.emit(Event{Type: EventAccountClosed, Account: account, Previous: &previous})
+}
+
+// accountMoved drops a token account now owned by another wallet from the
+// state, and reports the change of owner when authorities are watched
+func (m *Monitor) accountMoved(previous, current solana.TokenAccountInfo) {
+	if m.IsPaused(previous.Owner) || !m.dropAccount(previous) || !m.authorities {
+		return
+	}
+
+	m.emit(m.authorityEvent(previous, nil, AuthorityChange{
+		Kind:     AuthorityOwner,
+		Previous: previous.Owner,
+		Current:  current.Owner,
+	}))
+}
+
+// dropAccount removes a token account from the state, unless another
+// account of its owner and mint replaced it, and reports whether it did
+func (m *Monitor) dropAccount(account solana.TokenAccountInfo) bool {
+	key := account.Owner + ":" + account.Mint
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	if m.state[key].Address != account.Address {
+		return false
+	}
+	delete(m.state, key)
+	delete(m.zeroSince, key)
+	return true
+}
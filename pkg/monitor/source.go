@@ -0,0 +1,46 @@
+package monitor
+
+import (
+	"context"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// logSubscriber is implemented by data sources that stream the transactions
+// of a wallet, which feed watch-only mode
+type logSubscriber interface {
+	SubscribeToWalletLogs(ctx context.Context, wallet string, callback func(solana.LogNotification)) error
+}
+
+// SetDataSource takes token accounts and their updates from source instead
+// of the RPC client, such as a Geyser stream or sources combined with
+// solana.Combine. Hot wallets keep their dedicated clients, and lookups of
+// mints, stakes and transactions stay on the RPC client. Call it before
+// Start.
+func (m *Monitor) SetDataSource(source solana.DataSource) {
+	m.source = source
+}
+
+// SkipTokenPolls stops polls from looking up token accounts, for data
+// sources whose subscriptions deliver every change. The initial state is
+// still loaded. Call it before Start.
+func (m *Monitor) SkipTokenPolls() {
+	m.skipPolls = true
+}
+
+// walletSource returns the data source that follows a wallet
+func (m *Monitor) walletSource(wallet string) solana.DataSource {
+	if m.IsHot(wallet) {
+		return m.walletClient(wallet)
+	}
+	return m.source
+}
+
+// logSource returns the source of the signature feed, the data source when
+// it streams transactions and the RPC client otherwise
+func (m *Monitor) logSource() logSubscriber {
+	if logs, ok := m.source.(logSubscriber); ok {
+		return logs
+	}
+	return m.client
+}
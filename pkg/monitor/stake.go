@@ -0,0 +1,150 @@
+package monitor
+
+import (
+	"context"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// lamportsDecimals is the number of decimals of native SOL
+const lamportsDecimals = 9
+
+// StakeEvent describes a change of a stake account owned by a tracked wallet
+type StakeEvent struct {
+	Account  solana.StakeAccountInfo  `json:"account"`
+	Previous *solana.StakeAccountInfo `json:"previous,omitempty"`
+	Reward   *solana.StakeReward      `json:"reward,omitempty"`
+}
+
+// EnableStakeMonitoring turns on polling of stake accounts whose staker or
+// withdrawer authority is a tracked wallet
+func (m *Monitor) EnableStakeMonitoring() {
+	m.trackStakes = true
+}
+
+// pollStakeAccounts refreshes stake accounts of all wallets, emitting events
+// for delegation and activation changes and for rewards of the last epoch
+func (m *Monitor) pollStakeAccounts(ctx context.Context, initial bool) {
+	epoch, err := m.client.GetEpoch(ctx)
+	if err != nil {
+		m.log.Error("Failed to poll stake accounts", "error", err)
+		return
+	}
+
+	for _, wallet := range m.activeWallets() {
+		accounts, err := m.client.GetStakeAccounts(ctx, wallet, epoch)
+		if err != nil {
+			m.log.Error("Failed to poll stake accounts", "wallet", wallet, "error", err)
+			continue
+		}
+
+		for _, account := range accounts {
+			m.processStakeUpdate(wallet, account, initial)
+		}
+	}
+
+	m.stateMutex.Lock()
+	previousEpoch := m.stakeEpoch
+	m.stakeEpoch = epoch
+	m.stateMutex.Unlock()
+
+	if previousEpoch != 0 && epoch > previousEpoch {
+		m.collectStakeRewards(ctx, epoch-1)
+	}
+}
+
+// processStakeUpdate compares a stake account with its previous state
+func (m *Monitor) processStakeUpdate(wallet string, account solana.StakeAccountInfo, initial bool) {
+	m.stateMutex.Lock()
+	previous, exists := m.stakes[account.Address]
+	m.stakes[account.Address] = account
+	m.stateMutex.Unlock()
+
+	if initial {
+		return
+	}
+
+	var eventType EventType
+	switch {
+	case !exists || previous.Voter != account.Voter || previous.DelegatedStake != account.DelegatedStake:
+		eventType = EventStakeDelegationChanged
+	case previous.Status != account.Status && account.Status == solana.StakeActive:
+		eventType = EventStakeActivated
+	case previous.Status != account.Status && (account.Status == solana.StakeDeactivating || account.Status == solana.StakeInactive):
+		eventType = EventStakeDeactivated
+	default:
+		return
+	}
+
+	stakeEvent := &StakeEvent{Account: account}
+	if exists {
+		stakeEvent.Previous = &previous
+	}
+
+	m.log.Info("Stake account changed",
+		"wallet", wallet,
+		"stake", account.Address,
+		"voter", account.Voter,
+		"status", account.Status,
+	)
+
+	m.emit(m.stakeEvent(eventType, wallet, stakeEvent))
+}
+
+// collectStakeRewards emits reward events for all known stake accounts
+func (m *Monitor) collectStakeRewards(ctx context.Context, epoch uint64) {
+	m.stateMutex.RLock()
+	accounts := make(map[string]solana.StakeAccountInfo, len(m.stakes))
+	addresses := make([]string, 0, len(m.stakes))
+	for address, account := range m.stakes {
+		accounts[address] = account
+		addresses = append(addresses, address)
+	}
+	m.stateMutex.RUnlock()
+
+	if len(addresses) == 0 {
+		return
+	}
+
+	rewards, err := m.client.GetStakeRewards(ctx, addresses, epoch)
+	if err != nil {
+		m.log.Error("Failed to collect stake rewards", "epoch", epoch, "error", err)
+		return
+	}
+
+	for i := range rewards {
+		reward := rewards[i]
+		account := accounts[reward.Address]
+		m.emit(m.stakeEvent(EventStakeReward, m.stakeWallet(account), &StakeEvent{
+			Account: account,
+			Reward:  &reward,
+		}))
+	}
+}
+
+// stakeWallet returns the tracked wallet that controls a stake account
+func (m *Monitor) stakeWallet(account solana.StakeAccountInfo) string {
+	for _, wallet := range m.trackedWallets() {
+		if wallet == account.Withdrawer {
+			return wallet
+		}
+	}
+	return account.Staker
+}
+
+// stakeEvent builds a monitor event for a stake change. The stake account is
+// mirrored into Account so wallet-keyed consumers can route it.
+func (m *Monitor) stakeEvent(eventType EventType, wallet string, stakeEvent *StakeEvent) Event {
+	return Event{
+		Type: eventType,
+		Account: solana.TokenAccountInfo{
+			Address:       stakeEvent.Account.Address,
+			Owner:         wallet,
+			Balance:       stakeEvent.Account.Lamports,
+			Decimals:      lamportsDecimals,
+			ProgramID:     solana.StakeProgramID,
+			LastUpdatedAt: stakeEvent.Account.LastUpdatedAt,
+		},
+		Stake: stakeEvent,
+	}
+}
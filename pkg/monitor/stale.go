@@ -0,0 +1,86 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// StaleWallet describes a wallet that received no subscription notification
+// and no successful poll within the staleness window
+type StaleWallet struct {
+	// LastActivityAt is the last notification or successful poll, or the
+	// start of monitoring when there was none
+	LastActivityAt time.Time `json:"last_activity_at"`
+	// StaleFor is the time since LastActivityAt, e.g. "12m30s"
+	StaleFor string `json:"stale_for"`
+	// Subscription is the state of the wallet's subscription
+	Subscription string `json:"subscription"`
+	LastError    string `json:"last_error,omitempty"`
+}
+
+// SetStaleAfter emits a wallet_stale event for wallets that receive no
+// subscription notification and no successful poll for window, such as when
+// the subscription silently stopped delivering while polls fail. It is
+// emitted once until the wallet is active again. Call it before Start.
+func (m *Monitor) SetStaleAfter(window time.Duration) {
+	m.staleAfter = window
+}
+
+// checkStaleWallets emits wallet_stale events for active wallets that became
+// stale and forgets those that are active again
+func (m *Monitor) checkStaleWallets() {
+	now := m.now()
+	for _, wallet := range m.activeWallets() {
+		m.statusMutex.Lock()
+		lastActivity := m.lastActivity(wallet)
+		stale := now.Sub(lastActivity) > m.staleAfter
+		reported := m.stale[wallet]
+		subscription, lastError := SubscriptionStopped, ""
+		if status, ok := m.subscriptions[wallet]; ok {
+			subscription, lastError = status.State, status.LastError
+		}
+		if stale {
+			m.stale[wallet] = true
+		} else {
+			delete(m.stale, wallet)
+		}
+		m.statusMutex.Unlock()
+
+		switch {
+		case stale && !reported:
+			staleFor := now.Sub(lastActivity).Round(time.Second)
+			m.log.Warn("Wallet is stale, no notification or successful poll received",
+				"wallet", wallet,
+				"stale_for", staleFor,
+				"subscription", subscription,
+			)
+			m.emit(Event{
+				Type:     EventWalletStale,
+				Account:  solana.TokenAccountInfo{Address: wallet, Owner: wallet, LastUpdatedAt: now},
+				Severity: SeverityWarn,
+				Stale: &StaleWallet{
+					LastActivityAt: lastActivity,
+					StaleFor:       staleFor.String(),
+					Subscription:   subscription,
+					LastError:      lastError,
+				},
+			})
+		case !stale && reported:
+			m.log.Info("Wallet is active again", "wallet", wallet)
+		}
+	}
+}
+
+// lastActivity returns the last notification or successful poll of a
+// wallet, or the start of monitoring. Callers must hold the status lock.
+func (m *Monitor) lastActivity(wallet string) time.Time {
+	last := m.startedAt
+	if at, ok := m.polls[wallet]; ok && at.After(last) {
+		last = at
+	}
+	if status, ok := m.subscriptions[wallet]; ok && status.LastUpdateAt != nil && status.LastUpdateAt.After(last) {
+		last = *status.LastUpdateAt
+	}
+	return last
+}
@@ -0,0 +1,11 @@
+package monitor
+
+import "github.com/yourusername/solana-wallet-tracker/pkg/telemetry"
+
+// Metrics of the monitor, exported while telemetry is enabled
+var (
+	subscriptionUpdates = telemetry.NewCounter("tracker.subscription.updates", "Token account updates received from subscriptions", "{update}")
+	handlerDuration     = telemetry.NewHistogram("tracker.handler.duration", "Duration of event handler calls", "ms", telemetry.DurationBounds)
+	handlerFailures     = telemetry.NewCounter("tracker.handler.failures", "Handler calls that panicked, timed out or were skipped", "{call}")
+	chainLag            = telemetry.NewHistogram("tracker.chain.lag", "Delay from the block time of an update to the invocation of its event handlers", "ms", telemetry.DurationBounds)
+)
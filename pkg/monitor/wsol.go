@@ -0,0 +1,85 @@
+package monitor
+
+import "github.com/yourusername/solana-wallet-tracker/pkg/solana"
+
+// FoldWrappedSOL reports wrapped SOL as part of the SOL balance of a wallet
+// instead of as token accounts of its own. Swaps wrap SOL into temporary
+// token accounts and close them again, which would otherwise show up as new
+// holdings and emptied accounts of wSOL. The SOL holding of a wallet has the
+// wallet as its address and combines its lamports with its wrapped SOL. Call
+// it before Start.
+func (m *Monitor) FoldWrappedSOL() {
+	m.foldSOL = true
+}
+
+// isWrappedSOL reports whether an update is of a wrapped SOL token account
+// to fold into the SOL balance of its wallet
+func (m *Monitor) isWrappedSOL(account solana.TokenAccountInfo) bool {
+	return m.foldSOL && account.Mint == solana.NativeMint && account.Address != account.Owner
+}
+
+// updateWrappedSOL reports the SOL balance of a wallet after an update of
+// one of its wrapped SOL accounts. Its other wrapped SOL accounts are looked
+// up again, since the subscription doesn't report closed ones.
+func (m *Monitor) updateWrappedSOL(account solana.TokenAccountInfo, initial bool) {
+	wrapped, err := m.client.GetTokenAccountsByMint(m.ctx, account.Owner, solana.NativeMint)
+	if err != nil {
+		m.log.Warn("Failed to get wrapped SOL accounts", "wallet", account.Owner, "error", err)
+		return
+	}
+	m.reportSOL(account.Owner, wrapped, account.Slot, account.Source, initial)
+}
+
+// syncWrappedSOL reports the SOL balance of a wallet from the token accounts
+// of a poll. Wrapped SOL accounts closed since the last poll are missing.
+func (m *Monitor) syncWrappedSOL(wallet string, accounts []solana.TokenAccountInfo, initial bool) {
+	if !m.foldSOL || !m.shouldTrackToken(wallet, solana.NativeMint) {
+		return
+	}
+
+	var wrapped []solana.TokenAccountInfo
+	var slot uint64
+	for _, account := range accounts {
+		if account.Slot > slot {
+			slot = account.Slot
+		}
+		if m.isWrappedSOL(account) {
+			wrapped = append(wrapped, account)
+		}
+	}
+	m.reportSOL(wallet, wrapped, slot, solana.SourcePoll, initial)
+}
+
+// reportSOL processes the SOL holding of a wallet, its lamports and the
+// balances of its wrapped SOL accounts, after an update from source
+func (m *Monitor) reportSOL(wallet string, wrapped []solana.TokenAccountInfo, slot uint64, source string, initial bool) {
+	if m.IsPaused(wallet) {
+		return
+	}
+
+	lamports, err := m.client.GetSOLBalance(m.ctx, wallet)
+	if err != nil {
+		m.log.Warn("Failed to get SOL balance", "wallet", wallet, "error", err)
+		return
+	}
+
+	balance := lamports
+	for _, account := range wrapped {
+		balance += account.Balance
+		if account.Slot > slot {
+			slot = account.Slot
+		}
+	}
+
+	m.processAccountUpdate(solana.TokenAccountInfo{
+		Address:       wallet,
+		Owner:         wallet,
+		Mint:          solana.NativeMint,
+		Balance:       balance,
+		Decimals:      lamportsDecimals,
+		ProgramID:     solana.SystemProgram,
+		Slot:          slot,
+		LastUpdatedAt: m.now(),
+		Source:        source,
+	}, initial)
+}
@@ -0,0 +1,285 @@
+// Package mqtt is a minimal MQTT 3.1.1 client that publishes messages, enough
+// to feed home automation brokers such as Mosquitto without a dependency.
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"time"
+)
+
+// DefaultKeepAlive is the keep alive interval announced to the broker
+const DefaultKeepAlive = 60 * time.Second
+
+// Packet types, shifted into the first byte of the fixed header
+const (
+	packetConnect    = 1 << 4
+	packetConnAck    = 2 << 4
+	packetPublish    = 3 << 4
+	packetPubAck     = 4 << 4
+	packetDisconnect = 14 << 4
+)
+
+// connectReturnCodes explains the refusals of CONNACK
+var connectReturnCodes = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "client identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+// ErrClosed is returned when publishing on a closed client
+var ErrClosed = errors.New("mqtt client is closed")
+
+// Options of a connection
+type Options struct {
+	ClientID string
+	// KeepAlive is announced to the broker, which drops connections idle
+	// for one and a half times as long. Zero uses DefaultKeepAlive.
+	KeepAlive time.Duration
+	// TLS configures mqtts:// connections; nil trusts the system
+	// certificates
+	TLS *tls.Config
+}
+
+// Client is a connection to a broker. It is not safe for concurrent use.
+type Client struct {
+	conn      net.Conn
+	reader    *bufio.Reader
+	keepAlive time.Duration
+	packetID  uint16
+	lastUsed  time.Time
+}
+
+// Dial connects to the broker of a mqtt:// or mqtts:// URL, with the user
+// name and password of the URL if any, and starts a clean session
+func Dial(ctx context.Context, rawURL string, opts Options) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid broker URL: %w", err)
+	}
+	if opts.KeepAlive <= 0 {
+		opts.KeepAlive = DefaultKeepAlive
+	}
+
+	var conn net.Conn
+	dialer := &net.Dialer{}
+	switch u.Scheme {
+	case "mqtt":
+		conn, err = dialer.DialContext(ctx, "tcp", hostPort(u, "1883"))
+	case "mqtts":
+		config := opts.TLS
+		if config == nil {
+			config = &tls.Config{}
+		}
+		if config.ServerName == "" {
+			config = config.Clone()
+			config.ServerName = u.Hostname()
+		}
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: config}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", hostPort(u, "8883"))
+	default:
+		return nil, fmt.Errorf("unsupported broker URL scheme %q, use mqtt or mqtts", u.Scheme)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to broker: %w", err)
+	}
+
+	c := &Client{conn: conn, reader: bufio.NewReader(conn), keepAlive: opts.KeepAlive}
+	if err := c.connect(ctx, u.User, opts.ClientID); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// hostPort returns the address of a broker URL, with the default port when
+// it has none
+func hostPort(u *url.URL, defaultPort string) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	return net.JoinHostPort(u.Hostname(), defaultPort)
+}
+
+// connect sends CONNECT and waits for the broker to accept it
+func (c *Client) connect(ctx context.Context, user *url.Userinfo, clientID string) error {
+	var flags byte = 0x02 // clean session
+	var payload []byte
+	payload = appendString(payload, clientID)
+	if user != nil {
+		flags |= 0x80
+		payload = appendString(payload, user.Username())
+		if password, ok := user.Password(); ok {
+			flags |= 0x40
+			payload = appendString(payload, password)
+		}
+	}
+
+	var variable []byte
+	variable = appendString(variable, "MQTT")
+	variable = append(variable, 4, flags) // protocol level 3.1.1
+	variable = binary.BigEndian.AppendUint16(variable, uint16(c.keepAlive/time.Second))
+	if err := c.write(ctx, packetConnect, append(variable, payload...)); err != nil {
+		return fmt.Errorf("failed to send CONNECT: %w", err)
+	}
+
+	kind, body, err := c.read(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read CONNACK: %w", err)
+	}
+	if kind != packetConnAck || len(body) != 2 {
+		return fmt.Errorf("broker answered CONNECT with packet type %d", kind>>4)
+	}
+	if code := body[1]; code != 0 {
+		reason, ok := connectReturnCodes[code]
+		if !ok {
+			reason = fmt.Sprintf("return code %d", code)
+		}
+		return fmt.Errorf("broker refused connection: %s", reason)
+	}
+	return nil
+}
+
+// Publish sends a message. With QoS 1 it waits for the broker to
+// acknowledge it; QoS 0 messages are sent once without acknowledgement.
+// Retained messages are kept by the broker as the latest of their topic and
+// delivered to every new subscriber.
+func (c *Client) Publish(ctx context.Context, topic string, payload []byte, qos byte, retain bool) error {
+	if c.conn == nil {
+		return ErrClosed
+	}
+	if qos > 1 {
+		return fmt.Errorf("unsupported QoS %d", qos)
+	}
+
+	flags := qos << 1
+	if retain {
+		flags |= 0x01
+	}
+	var body []byte
+	body = appendString(body, topic)
+	var id uint16
+	if qos > 0 {
+		c.packetID++
+		if c.packetID == 0 {
+			c.packetID = 1
+		}
+		id = c.packetID
+		body = binary.BigEndian.AppendUint16(body, id)
+	}
+	if err := c.write(ctx, packetPublish|flags, append(body, payload...)); err != nil {
+		return fmt.Errorf("failed to publish: %w", err)
+	}
+	if qos == 0 {
+		return nil
+	}
+
+	for {
+		kind, ack, err := c.read(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read PUBACK: %w", err)
+		}
+		// Only the acknowledgements of our messages are expected
+		if kind == packetPubAck && len(ack) == 2 && binary.BigEndian.Uint16(ack) == id {
+			return nil
+		}
+	}
+}
+
+// Idle reports whether the connection has been unused for so long that the
+// broker may have dropped it
+func (c *Client) Idle() bool {
+	return time.Since(c.lastUsed) >= c.keepAlive
+}
+
+// Close disconnects from the broker
+func (c *Client) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	c.write(context.Background(), packetDisconnect, nil)
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+// write sends a packet
+func (c *Client) write(ctx context.Context, header byte, body []byte) error {
+	c.deadline(ctx)
+	packet := append([]byte{header}, remainingLength(len(body))...)
+	if _, err := c.conn.Write(append(packet, body...)); err != nil {
+		return err
+	}
+	c.lastUsed = time.Now()
+	return nil
+}
+
+// read receives a packet, returning its type and body
+func (c *Client) read(ctx context.Context) (byte, []byte, error) {
+	c.deadline(ctx)
+	header, err := c.reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		b, err := c.reader.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7f) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		if i == 3 {
+			return 0, nil, errors.New("malformed remaining length")
+		}
+		multiplier *= 128
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, body); err != nil {
+		return 0, nil, err
+	}
+	return header & 0xf0, body, nil
+}
+
+// deadline bounds the next write or read by the deadline of ctx, or by the
+// keep alive interval without one
+func (c *Client) deadline(ctx context.Context) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(c.keepAlive)
+	}
+	c.conn.SetDeadline(deadline)
+}
+
+// remainingLength encodes the length of a packet body
+func remainingLength(length int) []byte {
+	var encoded []byte
+	for {
+		b := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			b |= 0x80
+		}
+		encoded = append(encoded, b)
+		if length == 0 {
+			return encoded
+		}
+	}
+}
+
+// appendString appends a length-prefixed UTF-8 string
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
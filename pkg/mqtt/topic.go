@@ -0,0 +1,46 @@
+package mqtt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Placeholders are the placeholders of topic templates
+var Placeholders = []string{"{wallet}", "{mint}", "{account}", "{label}", "{symbol}", "{network}"}
+
+// topicEscaper keeps values from adding topic levels or wildcards
+var topicEscaper = strings.NewReplacer("/", "_", "+", "_", "#", "_")
+
+// Render fills the placeholders of a topic template with values, keyed by
+// placeholder. Slashes and wildcards in values are replaced with
+// underscores.
+func Render(template string, values map[string]string) string {
+	pairs := make([]string, 0, 2*len(Placeholders))
+	for _, placeholder := range Placeholders {
+		pairs = append(pairs, placeholder, topicEscaper.Replace(values[placeholder]))
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// ValidateTemplate checks a topic template: it must not be empty or hold
+// wildcards or unknown placeholders
+func ValidateTemplate(template string) error {
+	if template == "" {
+		return fmt.Errorf("must not be empty")
+	}
+	if strings.ContainsAny(template, "+#") {
+		return fmt.Errorf("must not contain the wildcards + or #")
+	}
+	rest := template
+	for _, placeholder := range Placeholders {
+		rest = strings.ReplaceAll(rest, placeholder, "")
+	}
+	if start := strings.Index(rest, "{"); start >= 0 {
+		end := strings.Index(rest[start:], "}")
+		if end < 0 {
+			return fmt.Errorf("has an unclosed placeholder")
+		}
+		return fmt.Errorf("has unknown placeholder %s, use %s", rest[start:start+end+1], strings.Join(Placeholders, ", "))
+	}
+	return nil
+}
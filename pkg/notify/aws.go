@@ -0,0 +1,392 @@
+//go:build !noaws
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/aws"
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+)
+
+func init() {
+	builders["sns"] = func(cfg config.NotifierConfig, locale string, _ logging.Logger) (Notifier, error) {
+		if cfg.Topic == "" {
+			return nil, fmt.Errorf("sns notifier requires topic")
+		}
+		notifier := NewSNSNotifier(cfg.Topic, cfg.URL, locale, awsCredentials(cfg))
+		notifier.SetCloudEvents(cloudEventSource(cfg))
+		return notifier, nil
+	}
+	builders["sqs"] = func(cfg config.NotifierConfig, locale string, _ logging.Logger) (Notifier, error) {
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("sqs notifier requires url")
+		}
+		notifier, err := NewSQSNotifier(cfg.URL, cfg.Region, locale, awsCredentials(cfg))
+		if err != nil {
+			return nil, err
+		}
+		notifier.SetCloudEvents(cloudEventSource(cfg))
+		return notifier, nil
+	}
+}
+
+// awsCredentials returns the static AWS credentials of a notifier, which are
+// empty to use those of the environment or IAM role
+func awsCredentials(cfg config.NotifierConfig) aws.Credentials {
+	return aws.Credentials{AccessKeyID: cfg.AccessKeyID, SecretAccessKey: cfg.SecretAccessKey}
+}
+
+// awsMessage is a message for SNS or SQS: the webhook payload as body, in a
+// CloudEvents envelope if the channel sets a source, with
+// attributes to filter and route on and, for FIFO topics and queues, the
+// group and deduplication IDs
+type awsMessage struct {
+	body       []byte
+	attributes map[string]string
+	groupID    string
+	dedupID    string
+}
+
+// eventMessage builds the message of an event. Its attributes are the
+// wallet, mint, severity (info when unset), event type, label and network.
+// Events of a wallet share a FIFO group; their deduplication ID is the slot,
+// token account and type of the event, so the same update observed twice,
+// e.g. by a subscription and a poll, is delivered once.
+func eventMessage(locale, cloudEventSource string, event monitor.Event) (awsMessage, error) {
+	body, _, err := marshalPayload(cloudEventSource, webhookPayload{
+		Text:   FormatMessage(locale, event),
+		Locale: locale,
+		Event:  &event,
+	})
+	if err != nil {
+		return awsMessage{}, err
+	}
+
+	severity := event.Severity
+	if severity == "" {
+		severity = monitor.SeverityInfo
+	}
+	message := awsMessage{
+		body: body,
+		attributes: map[string]string{
+			"wallet":     event.Account.Owner,
+			"mint":       event.Account.Mint,
+			"severity":   severity,
+			"event_type": string(event.Type),
+			"label":      event.Label,
+			"network":    event.Network,
+		},
+		groupID: event.Account.Owner,
+		dedupID: eventID(event, body),
+	}
+	return message, nil
+}
+
+// healthMessage builds the message of a health report
+func healthMessage(locale, cloudEventSource string, health Health) (awsMessage, error) {
+	body, _, err := marshalPayload(cloudEventSource, webhookPayload{
+		Text:   FormatHealthMessage(locale, health),
+		Locale: locale,
+		Health: &health,
+	})
+	if err != nil {
+		return awsMessage{}, err
+	}
+	return awsMessage{
+		body:       body,
+		attributes: map[string]string{"event_type": "health", "status": health.Status},
+		groupID:    "health",
+		dedupID:    hashID(body),
+	}, nil
+}
+
+// attributeNames returns the names of the attributes with a value, sorted,
+// as SNS and SQS reject empty attribute values
+func (m awsMessage) attributeNames() []string {
+	names := make([]string, 0, len(m.attributes))
+	for name, value := range m.attributes {
+		if value != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// awsClient sends signed requests to an AWS API
+type awsClient struct {
+	service     string
+	region      string
+	endpoint    string
+	credentials *aws.CredentialChain
+	httpClient  *http.Client
+}
+
+// post signs and sends a request, returning the body of a successful
+// response
+func (c *awsClient) post(ctx context.Context, body []byte, header http.Header) ([]byte, error) {
+	creds, err := c.credentials.Credentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AWS credentials: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	aws.Sign(req, body, creds, c.region, c.service, time.Now())
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", c.service, err)
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		if len(data) > 512 {
+			data = data[:512]
+		}
+		return nil, fmt.Errorf("%s returned status %d: %s", c.service, res.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+// SNSNotifier publishes events to an Amazon SNS topic
+type SNSNotifier struct {
+	topicARN string
+	locale   string
+	fifo     bool
+	client   *awsClient
+	// cloudEventSource is the source of CloudEvents, empty to send plain
+	// payloads
+	cloudEventSource string
+}
+
+// NewSNSNotifier creates a notifier publishing to the topic of an ARN, signed
+// with the static credentials when set or those of the environment or IAM
+// role otherwise, see aws.CredentialChain. An empty endpoint uses that of the
+// region of the ARN.
+func NewSNSNotifier(topicARN, endpoint, locale string, creds aws.Credentials) *SNSNotifier {
+	region := aws.ARNRegion(topicARN)
+	if endpoint == "" {
+		endpoint = aws.Endpoint(nil, "sns", "SNS", region)
+	}
+	return &SNSNotifier{
+		topicARN: topicARN,
+		locale:   locale,
+		fifo:     strings.HasSuffix(topicARN, ".fifo"),
+		client: &awsClient{
+			service:     "sns",
+			region:      region,
+			endpoint:    endpoint,
+			credentials: &aws.CredentialChain{Static: creds},
+			httpClient:  &http.Client{Timeout: deliveryTimeout},
+		},
+	}
+}
+
+// SetCloudEvents publishes payloads in CloudEvents envelopes of the
+// source, or plain ones when it is empty
+func (s *SNSNotifier) SetCloudEvents(source string) {
+	s.cloudEventSource = source
+}
+
+// Name returns the notifier name
+func (s *SNSNotifier) Name() string {
+	return "sns"
+}
+
+// Notify publishes the event to the topic
+func (s *SNSNotifier) Notify(ctx context.Context, event monitor.Event) error {
+	message, err := eventMessage(s.locale, s.cloudEventSource, event)
+	if err != nil {
+		return err
+	}
+	return s.publish(ctx, message)
+}
+
+// NotifyHealth publishes a health report to the topic
+func (s *SNSNotifier) NotifyHealth(ctx context.Context, health Health) error {
+	message, err := healthMessage(s.locale, s.cloudEventSource, health)
+	if err != nil {
+		return err
+	}
+	return s.publish(ctx, message)
+}
+
+// publish calls the Publish action of the SNS query API
+func (s *SNSNotifier) publish(ctx context.Context, message awsMessage) error {
+	form := url.Values{
+		"Action":   {"Publish"},
+		"Version":  {"2010-03-31"},
+		"TopicArn": {s.topicARN},
+		"Message":  {string(message.body)},
+	}
+	for i, name := range message.attributeNames() {
+		prefix := "MessageAttributes.entry." + strconv.Itoa(i+1)
+		form.Set(prefix+".Name", name)
+		form.Set(prefix+".Value.DataType", "String")
+		form.Set(prefix+".Value.StringValue", message.attributes[name])
+	}
+	if s.fifo {
+		form.Set("MessageGroupId", message.groupID)
+		form.Set("MessageDeduplicationId", message.dedupID)
+	}
+
+	header := http.Header{"Content-Type": {"application/x-www-form-urlencoded; charset=utf-8"}}
+	data, err := s.client.post(ctx, []byte(form.Encode()), header)
+	if err != nil {
+		return err
+	}
+	var res struct {
+		MessageID string `xml:"PublishResult>MessageId"`
+	}
+	if err := xml.Unmarshal(data, &res); err != nil || res.MessageID == "" {
+		return fmt.Errorf("unexpected SNS response: %s", strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// SQSNotifier sends events to an Amazon SQS queue
+type SQSNotifier struct {
+	queueURL string
+	locale   string
+	fifo     bool
+	client   *awsClient
+	// cloudEventSource is the source of CloudEvents, empty to send plain
+	// payloads
+	cloudEventSource string
+}
+
+// NewSQSNotifier creates a notifier sending to the queue of a URL, such as
+// https://sqs.us-east-1.amazonaws.com/123456789012/tracker, signed with the
+// static credentials when set or those of the environment or IAM role
+// otherwise, see aws.CredentialChain. Requests go to the host of the queue
+// URL. An empty region is taken from the host, else from the environment.
+func NewSQSNotifier(queueURL, region, locale string, creds aws.Credentials) (*SQSNotifier, error) {
+	parsed, err := url.Parse(queueURL)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid queue URL %q", queueURL)
+	}
+	if region == "" {
+		region = sqsRegion(parsed.Hostname())
+	}
+	if region == "" {
+		region = aws.Region(nil)
+	}
+	if region == "" {
+		return nil, fmt.Errorf("the region of queue %s is unknown, set region", queueURL)
+	}
+	return &SQSNotifier{
+		queueURL: queueURL,
+		locale:   locale,
+		fifo:     strings.HasSuffix(parsed.Path, ".fifo"),
+		client: &awsClient{
+			service:     "sqs",
+			region:      region,
+			endpoint:    parsed.Scheme + "://" + parsed.Host + "/",
+			credentials: &aws.CredentialChain{Static: creds},
+			httpClient:  &http.Client{Timeout: deliveryTimeout},
+		},
+	}, nil
+}
+
+// sqsRegion returns the region of an SQS host, sqs.REGION.amazonaws.com or
+// the legacy REGION.queue.amazonaws.com
+func sqsRegion(host string) string {
+	parts := strings.Split(host, ".")
+	switch {
+	case len(parts) >= 4 && parts[0] == "sqs":
+		return parts[1]
+	case len(parts) >= 4 && parts[1] == "queue":
+		return parts[0]
+	}
+	return ""
+}
+
+// SetCloudEvents sends payloads in CloudEvents envelopes of the source, or
+// plain ones when it is empty
+func (s *SQSNotifier) SetCloudEvents(source string) {
+	s.cloudEventSource = source
+}
+
+// Name returns the notifier name
+func (s *SQSNotifier) Name() string {
+	return "sqs"
+}
+
+// Notify sends the event to the queue
+func (s *SQSNotifier) Notify(ctx context.Context, event monitor.Event) error {
+	message, err := eventMessage(s.locale, s.cloudEventSource, event)
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, message)
+}
+
+// NotifyHealth sends a health report to the queue
+func (s *SQSNotifier) NotifyHealth(ctx context.Context, health Health) error {
+	message, err := healthMessage(s.locale, s.cloudEventSource, health)
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, message)
+}
+
+// sqsAttribute is a message attribute of the SQS JSON API
+type sqsAttribute struct {
+	DataType    string `json:"DataType"`
+	StringValue string `json:"StringValue"`
+}
+
+// send calls SendMessage of the SQS JSON API
+func (s *SQSNotifier) send(ctx context.Context, message awsMessage) error {
+	request := struct {
+		QueueURL               string                  `json:"QueueUrl"`
+		MessageBody            string                  `json:"MessageBody"`
+		MessageAttributes      map[string]sqsAttribute `json:"MessageAttributes,omitempty"`
+		MessageGroupID         string                  `json:"MessageGroupId,omitempty"`
+		MessageDeduplicationID string                  `json:"MessageDeduplicationId,omitempty"`
+	}{
+		QueueURL:          s.queueURL,
+		MessageBody:       string(message.body),
+		MessageAttributes: make(map[string]sqsAttribute),
+	}
+	for _, name := range message.attributeNames() {
+		request.MessageAttributes[name] = sqsAttribute{DataType: "String", StringValue: message.attributes[name]}
+	}
+	if s.fifo {
+		request.MessageGroupID = message.groupID
+		request.MessageDeduplicationID = message.dedupID
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{
+		"Content-Type": {"application/x-amz-json-1.0"},
+		"X-Amz-Target": {"AmazonSQS.SendMessage"},
+	}
+	_, err = s.client.post(ctx, body, header)
+	return err
+}
@@ -0,0 +1,36 @@
+package notify
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/chart"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+)
+
+// Balance chart dimensions and window
+const (
+	chartWidth  = 240
+	chartHeight = 60
+	chartWindow = 24 * time.Hour
+)
+
+// renderBalanceChart renders a 24h balance sparkline for the account's wallet and mint
+func renderBalanceChart(ctx context.Context, history store.Store, accountInfo solana.TokenAccountInfo) ([]byte, error) {
+	records, err := history.History(ctx, accountInfo.Owner, accountInfo.Mint, time.Now().Add(-chartWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	// The current update may not be persisted yet, so always end on it
+	scale := math.Pow10(int(accountInfo.Decimals))
+	values := make([]float64, 0, len(records)+1)
+	for _, record := range records {
+		values = append(values, float64(record.Balance)/scale)
+	}
+	values = append(values, float64(accountInfo.Balance)/scale)
+
+	return chart.Sparkline(values, chartWidth, chartHeight)
+}
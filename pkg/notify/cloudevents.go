@@ -0,0 +1,146 @@
+package notify
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+)
+
+// CloudEvents content types of the JSON structured and batched modes
+const (
+	cloudEventContentType      = "application/cloudevents+json; charset=utf-8"
+	cloudEventBatchContentType = "application/cloudevents-batch+json; charset=utf-8"
+)
+
+// CloudEventTypePrefix prefixes the event type, health, digest or
+// flow_report in the type of CloudEvents, e.g. solana-wallet-tracker.balance_changed
+const CloudEventTypePrefix = "solana-wallet-tracker."
+
+// DefaultCloudEventSource is the source of CloudEvents of channels that
+// don't set one
+const DefaultCloudEventSource = "/solana-wallet-tracker"
+
+// cloudEvent is a CloudEvents 1.0 envelope in the JSON structured mode. Its
+// data is the webhook payload of one event, a digest or a health report.
+type cloudEvent struct {
+	SpecVersion     string         `json:"specversion"`
+	ID              string         `json:"id"`
+	Source          string         `json:"source"`
+	Type            string         `json:"type"`
+	Subject         string         `json:"subject,omitempty"`
+	Time            time.Time      `json:"time"`
+	DataContentType string         `json:"datacontenttype"`
+	Data            webhookPayload `json:"data"`
+}
+
+// cloudEventSource returns the CloudEvents source of a channel, empty for
+// channels sending plain payloads
+func cloudEventSource(cfg config.NotifierConfig) string {
+	switch {
+	case !cfg.CloudEvents:
+		return ""
+	case cfg.CloudEventsSource != "":
+		return cfg.CloudEventsSource
+	}
+	return DefaultCloudEventSource
+}
+
+// marshalPayload encodes a payload as JSON, in a CloudEvents envelope when
+// source is set, and returns it with its content type. Batches become a
+// CloudEvents batch of an envelope per event.
+func marshalPayload(source string, payload webhookPayload) ([]byte, string, error) {
+	if source == "" {
+		body, err := json.Marshal(payload)
+		return body, "application/json", err
+	}
+	if payload.Digest == nil && len(payload.Events) > 0 {
+		batch := make([]cloudEvent, 0, len(payload.Events))
+		for i := range payload.Events {
+			envelope, err := newCloudEvent(source, webhookPayload{
+				Text:   FormatMessage(payload.Locale, payload.Events[i]),
+				Locale: payload.Locale,
+				Event:  &payload.Events[i],
+			})
+			if err != nil {
+				return nil, "", err
+			}
+			batch = append(batch, envelope)
+		}
+		body, err := json.Marshal(batch)
+		return body, cloudEventBatchContentType, err
+	}
+
+	envelope, err := newCloudEvent(source, payload)
+	if err != nil {
+		return nil, "", err
+	}
+	body, err := json.Marshal(envelope)
+	return body, cloudEventContentType, err
+}
+
+// newCloudEvent wraps a payload of one event, a digest, a health report or a
+// flow report.
+// The ID of an event locates its update like the deduplication ID of FIFO
+// queues, so consumers can drop an update observed twice; others hash the
+// payload.
+func newCloudEvent(source string, payload webhookPayload) (cloudEvent, error) {
+	envelope := cloudEvent{
+		SpecVersion:     "1.0",
+		Source:          source,
+		DataContentType: "application/json",
+		Data:            payload,
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return cloudEvent{}, err
+	}
+	envelope.ID = hashID(data)
+
+	var last *monitor.Event
+	switch {
+	case payload.Health != nil:
+		envelope.Type = CloudEventTypePrefix + "health"
+		envelope.Time = payload.Health.At
+	case payload.FlowReport != nil:
+		envelope.Type = CloudEventTypePrefix + "flow_report"
+		envelope.Subject = payload.FlowReport.Period
+	case payload.Digest != nil:
+		envelope.Type = CloudEventTypePrefix + "digest"
+		last = &payload.Events[len(payload.Events)-1]
+	case payload.Event != nil:
+		envelope.Type = CloudEventTypePrefix + string(payload.Event.Type)
+		envelope.ID = eventID(*payload.Event, data)
+		last = payload.Event
+	default:
+		return cloudEvent{}, fmt.Errorf("payload has no event")
+	}
+	if last != nil {
+		envelope.Subject = last.Account.Owner
+		envelope.Time = last.Account.LastUpdatedAt
+	}
+	if envelope.Time.IsZero() {
+		envelope.Time = time.Now()
+	}
+	envelope.Time = envelope.Time.UTC()
+	return envelope, nil
+}
+
+// eventID identifies an event by the slot, token account and type of its
+// update, or by the hash of its encoding when they are unknown
+func eventID(event monitor.Event, body []byte) string {
+	if event.Account.Slot > 0 && event.Account.Address != "" {
+		return fmt.Sprintf("%d-%s-%s", event.Account.Slot, event.Account.Address, event.Type)
+	}
+	return hashID(body)
+}
+
+// hashID derives a deduplication ID from a message body
+func hashID(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/telemetry"
+)
+
+// cooldownWindow is the window the cooldown limit of a wallet and mint
+// applies to
+const cooldownWindow = time.Minute
+
+// suppressedEvents counts the routine events the cooldown suppressed
+var suppressedEvents = telemetry.NewCounter("tracker.notify.suppressed", "Routine notifications suppressed by the cooldown", "{event}")
+
+// cooldownState is what the cooldown knows of a wallet and mint
+type cooldownState struct {
+	// sent are the times of the notifications within the window, oldest
+	// first
+	sent       []time.Time
+	suppressed int
+}
+
+// SetCooldown limits the routine notifications of each wallet and mint to
+// limit per minute when it is positive. Events beyond it are suppressed and
+// counted in the next event of the wallet and mint that is delivered. Call
+// it before handling events.
+func (d *Dispatcher) SetCooldown(limit int) {
+	d.cooldownLimit = limit
+	d.cooldowns = make(map[string]*cooldownState)
+}
+
+// cool reports whether an event gets through the cooldown of its wallet
+// and mint, setting the events suppressed since the last one that did.
+// Urgent events always get through and don't count against the limit, and
+// replayed events aren't limited, as they are past ones.
+func (d *Dispatcher) cool(event *monitor.Event, urgent bool) bool {
+	if d.cooldownLimit <= 0 || event.Replayed {
+		return true
+	}
+	key := cooldownKey(*event)
+	now := time.Now()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	state := d.cooldowns[key]
+	if state == nil {
+		state = &cooldownState{}
+		d.cooldowns[key] = state
+	}
+	expired := 0
+	for expired < len(state.sent) && now.Sub(state.sent[expired]) >= cooldownWindow {
+		expired++
+	}
+	state.sent = state.sent[expired:]
+
+	if !urgent {
+		if len(state.sent) >= d.cooldownLimit {
+			state.suppressed++
+			suppressedEvents.Add(1)
+			return false
+		}
+		state.sent = append(state.sent, now)
+	}
+	event.Suppressed = state.suppressed
+	state.suppressed = 0
+	if len(state.sent) == 0 {
+		delete(d.cooldowns, key)
+	}
+	return true
+}
+
+// cooldownKey is the wallet and mint whose notifications share a cooldown
+func cooldownKey(event monitor.Event) string {
+	return event.Account.Owner + ":" + event.Account.Mint
+}
@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+func TestCooldown(t *testing.T) {
+	type step struct {
+		mint     string
+		urgent   bool
+		replayed bool
+		// expire ages the notifications sent so far past the window
+		expire         bool
+		pass           bool
+		wantSuppressed int
+	}
+	tests := []struct {
+		name  string
+		limit int
+		steps []step
+	}{
+		{"disabled", 0, []step{
+			{mint: "m1", pass: true},
+			{mint: "m1", pass: true},
+			{mint: "m1", pass: true},
+		}},
+		{"limit reached", 2, []step{
+			{mint: "m1", pass: true},
+			{mint: "m1", pass: true},
+			{mint: "m1", pass: false},
+			{mint: "m1", pass: false},
+		}},
+		{"mints apart", 1, []step{
+			{mint: "m1", pass: true},
+			{mint: "m2", pass: true},
+			{mint: "m1", pass: false},
+		}},
+		{"urgent carries the suppressed count", 1, []step{
+			{mint: "m1", pass: true},
+			{mint: "m1", pass: false},
+			{mint: "m1", pass: false},
+			{mint: "m1", urgent: true, pass: true, wantSuppressed: 2},
+			// The count was reported and urgent events don't take a slot
+			{mint: "m1", pass: false},
+		}},
+		{"window expires", 1, []step{
+			{mint: "m1", pass: true},
+			{mint: "m1", pass: false},
+			{mint: "m1", expire: true, pass: true, wantSuppressed: 1},
+			{mint: "m1", pass: false},
+		}},
+		{"replayed events aren't limited", 1, []step{
+			{mint: "m1", pass: true},
+			{mint: "m1", replayed: true, pass: true},
+			{mint: "m1", pass: false},
+		}},
+	}
+	for _, tt := range tests {
+		d := NewDispatcher(0, nil)
+		d.SetCooldown(tt.limit)
+		for i, s := range tt.steps {
+			if s.expire {
+				for _, state := range d.cooldowns {
+					for j := range state.sent {
+						state.sent[j] = state.sent[j].Add(-cooldownWindow)
+					}
+				}
+			}
+			event := monitor.Event{
+				Account:  solana.TokenAccountInfo{Owner: "wallet", Mint: s.mint},
+				Replayed: s.replayed,
+			}
+			if pass := d.cool(&event, s.urgent); pass != s.pass {
+				t.Errorf("%s: step %d: got through = %v, want %v", tt.name, i, pass, s.pass)
+			}
+			if s.pass && event.Suppressed != s.wantSuppressed {
+				t.Errorf("%s: step %d: suppressed = %d, want %d", tt.name, i, event.Suppressed, s.wantSuppressed)
+			}
+		}
+	}
+}
+
+func TestCooldownForgetsIdleKeys(t *testing.T) {
+	d := NewDispatcher(0, nil)
+	d.SetCooldown(1)
+	event := monitor.Event{Account: solana.TokenAccountInfo{Owner: "wallet", Mint: "m1"}}
+	d.cool(&event, false)
+	d.cooldowns[cooldownKey(event)].sent[0] = time.Now().Add(-2 * cooldownWindow)
+
+	// An urgent event after the window takes no slot, leaving nothing to
+	// remember
+	d.cool(&event, true)
+	if len(d.cooldowns) != 0 {
+		t.Errorf("cooldowns hold %d keys, want none", len(d.cooldowns))
+	}
+}
@@ -0,0 +1,228 @@
+package notify
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/i18n"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+)
+
+// Digest sums up the events of a wallet within the digest window of a
+// channel
+type Digest struct {
+	Events []monitor.Event
+	Window time.Duration
+}
+
+// NetChange is the balance change of a token over the events of a digest,
+// summed over the token accounts of the wallet
+type NetChange struct {
+	Mint     string `json:"mint"`
+	Symbol   string `json:"symbol,omitempty"`
+	Decimals uint8  `json:"decimals"`
+	Before   uint64 `json:"before"`
+	After    uint64 `json:"after"`
+}
+
+// DigestNotifier is implemented by notifiers that deliver a digest as a
+// summary rather than event by event
+type DigestNotifier interface {
+	NotifyDigest(ctx context.Context, digest Digest) error
+}
+
+// NetChanges returns the tokens whose balance changed over the digest, in
+// the order they first changed. Events without a token balance, such as
+// stake and NFT events, don't count.
+func (d Digest) NetChanges() []NetChange {
+	type accountChange struct {
+		mint   string
+		before uint64
+		after  uint64
+	}
+	var accounts []string
+	byAccount := make(map[string]*accountChange)
+	tokens := make(map[string]*NetChange)
+	var order []string
+	for _, event := range d.Events {
+		if !balanceEvent(event) {
+			continue
+		}
+		account := event.Account
+		change, ok := byAccount[account.Address]
+		if !ok {
+			change = &accountChange{mint: account.Mint, before: account.Balance}
+			switch {
+			case event.Previous != nil:
+				change.before = event.Previous.Balance
+			case event.Type == monitor.EventNewHolding:
+				change.before = 0
+			}
+			byAccount[account.Address] = change
+			accounts = append(accounts, account.Address)
+		}
+		change.after = account.Balance
+
+		if _, ok := tokens[account.Mint]; !ok {
+			tokens[account.Mint] = &NetChange{Mint: account.Mint, Decimals: account.Decimals}
+			order = append(order, account.Mint)
+		}
+		if event.Metadata != nil && event.Metadata.Symbol != "" {
+			tokens[account.Mint].Symbol = event.Metadata.Symbol
+		}
+	}
+
+	for _, address := range accounts {
+		change := byAccount[address]
+		tokens[change.mint].Before += change.before
+		tokens[change.mint].After += change.after
+	}
+	var changes []NetChange
+	for _, mint := range order {
+		if token := tokens[mint]; token.Before != token.After {
+			changes = append(changes, *token)
+		}
+	}
+	return changes
+}
+
+// balanceEvent reports whether an event carries the balance of a token
+// account
+func balanceEvent(event monitor.Event) bool {
+	return event.Account.Mint != "" && event.Account.Address != "" &&
+		event.Stake == nil && event.NFT == nil && event.Group == nil && event.Stale == nil && event.Signature == nil
+}
+
+// FormatDigestMessage renders the built-in text for a digest: the number of
+// events and the net change of every token
+func FormatDigestMessage(locale string, digest Digest) string {
+	lines := []string{i18n.T(locale, i18n.KeyDigestTitle, walletName(digest.Events[0]), len(digest.Events), formatWindow(digest.Window))}
+	for _, change := range digest.NetChanges() {
+		token := change.Mint
+		if change.Symbol != "" {
+			token = change.Symbol
+		}
+		lines = append(lines, i18n.T(locale, i18n.KeyNetChange, FormatDelta(change.Before, change.After, change.Decimals), token))
+	}
+	suppressed := 0
+	for _, event := range digest.Events {
+		suppressed += event.Suppressed
+	}
+	if suppressed > 0 {
+		lines = append(lines, i18n.T(locale, i18n.KeySuppressed, suppressed))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatWindow renders a window without trailing zero units, e.g. 5m
+func formatWindow(window time.Duration) string {
+	text := window.String()
+	if window%time.Minute == 0 {
+		text = strings.TrimSuffix(text, "0s")
+	}
+	if window%time.Hour == 0 {
+		text = strings.TrimSuffix(text, "0m")
+	}
+	return text
+}
+
+// SendDigest delivers a digest to a notifier implementing DigestNotifier
+// once, traced and measured like Send
+func SendDigest(notifier Notifier, digest Digest) error {
+	return observe(notifier, digest.Events, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+		defer cancel()
+		return notifier.(DigestNotifier).NotifyDigest(ctx, digest)
+	})
+}
+
+// SetDigestWindows sets the digest window of each notifier, in the order
+// the notifiers were given. Zero windows deliver events as they come.
+func (d *Dispatcher) SetDigestWindows(windows []time.Duration) {
+	d.digestWindows = windows
+}
+
+// collect keeps the routine events of a notifier with a digest window for
+// the digest of their wallet and returns those to deliver now
+func (d *Dispatcher) collect(index int, events []monitor.Event) []monitor.Event {
+	if index >= len(d.digestWindows) || d.digestWindows[index] <= 0 {
+		return events
+	}
+	if _, ok := d.notifiers[index].(DigestNotifier); !ok {
+		return events
+	}
+
+	var deliverNow []monitor.Event
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.digests == nil {
+		d.digests = make(map[int]map[string][]monitor.Event)
+	}
+	if d.digests[index] == nil {
+		d.digests[index] = make(map[string][]monitor.Event)
+	}
+	for _, event := range events {
+		if event.Hot || d.isCritical(event) {
+			deliverNow = append(deliverNow, event)
+			continue
+		}
+		key := walletKey(event)
+		if len(d.digests[index][key]) == 0 {
+			time.AfterFunc(d.digestWindows[index], func() { d.deliverDigest(index, key) })
+		}
+		d.digests[index][key] = append(d.digests[index][key], event)
+	}
+	return deliverNow
+}
+
+// deliverDigest delivers the digest of a wallet to a notifier. A single
+// event is delivered as it is, and a digest that fails is retried as a
+// batch of its events.
+func (d *Dispatcher) deliverDigest(index int, key string) {
+	d.mutex.Lock()
+	events := d.digests[index][key]
+	delete(d.digests[index], key)
+	d.mutex.Unlock()
+	if len(events) == 0 || !d.enabled(index) {
+		return
+	}
+	if len(events) == 1 {
+		d.deliverTo(index, events)
+		return
+	}
+
+	notifier := d.notifiers[index]
+	digest := Digest{Events: events, Window: d.digestWindows[index]}
+	if err := SendDigest(notifier, digest); err != nil {
+		d.log.Error("Failed to deliver notification digest",
+			"notifier", notifier.Name(),
+			"events", len(events),
+			"wallet", events[0].Account.Owner,
+			"label", events[0].Label,
+			"error", err,
+		)
+		d.retryLater(index, events, err)
+	}
+}
+
+// deliverAllDigests delivers the pending digests of every notifier, e.g.
+// on shutdown
+func (d *Dispatcher) deliverAllDigests() {
+	type pending struct {
+		index int
+		key   string
+	}
+	var all []pending
+	d.mutex.Lock()
+	for index, digests := range d.digests {
+		for key := range digests {
+			all = append(all, pending{index, key})
+		}
+	}
+	d.mutex.Unlock()
+
+	for _, p := range all {
+		d.deliverDigest(p.index, p.key)
+	}
+}
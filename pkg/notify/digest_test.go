@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+func TestDigestNetChanges(t *testing.T) {
+	account := func(address, mint string, balance uint64) solana.TokenAccountInfo {
+		return solana.TokenAccountInfo{Address: address, Owner: "wallet", Mint: mint, Balance: balance, Decimals: 6}
+	}
+	changed := func(address, mint string, before, after uint64) monitor.Event {
+		previous := account(address, mint, before)
+		return monitor.Event{Type: monitor.EventBalanceChanged, Account: account(address, mint, after), Previous: &previous}
+	}
+
+	tests := []struct {
+		name   string
+		events []monitor.Event
+		want   []NetChange
+	}{
+		{"one change", []monitor.Event{
+			changed("a1", "m1", 100, 150),
+		}, []NetChange{{Mint: "m1", Decimals: 6, Before: 100, After: 150}}},
+		{"first before and last after of an account", []monitor.Event{
+			changed("a1", "m1", 100, 150),
+			changed("a1", "m1", 150, 120),
+		}, []NetChange{{Mint: "m1", Decimals: 6, Before: 100, After: 120}}},
+		{"summed over the accounts of a mint", []monitor.Event{
+			changed("a1", "m1", 100, 150),
+			{Type: monitor.EventNewHolding, Account: account("a2", "m1", 30)},
+		}, []NetChange{{Mint: "m1", Decimals: 6, Before: 100, After: 180}}},
+		{"back where it started", []monitor.Event{
+			changed("a1", "m1", 100, 50),
+			changed("a1", "m1", 50, 100),
+		}, nil},
+		{"moved between accounts of a mint", []monitor.Event{
+			changed("a1", "m1", 100, 60),
+			changed("a2", "m1", 0, 40),
+		}, nil},
+		{"in the order tokens first changed", []monitor.Event{
+			changed("a2", "m2", 5, 7),
+			changed("a1", "m1", 1, 2),
+			changed("a2", "m2", 7, 9),
+		}, []NetChange{
+			{Mint: "m2", Decimals: 6, Before: 5, After: 9},
+			{Mint: "m1", Decimals: 6, Before: 1, After: 2},
+		}},
+		{"symbol from metadata", []monitor.Event{
+			changed("a1", "m1", 1, 2),
+			{Type: monitor.EventBalanceChanged, Account: account("a1", "m1", 3), Metadata: &solana.TokenMetadata{Symbol: "USDC"}},
+		}, []NetChange{{Mint: "m1", Symbol: "USDC", Decimals: 6, Before: 1, After: 3}}},
+		{"events without a token balance", []monitor.Event{
+			{Type: monitor.EventStakeReward, Account: account("a1", "m1", 10), Stake: &monitor.StakeEvent{}},
+			{Type: monitor.EventSignature, Account: account("a1", "m1", 10), Signature: &monitor.SignatureEvent{}},
+			{Type: monitor.EventBalanceChanged, Account: solana.TokenAccountInfo{Owner: "wallet", Balance: 10}},
+		}, nil},
+	}
+	for _, tt := range tests {
+		got := Digest{Events: tt.events}.NetChanges()
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
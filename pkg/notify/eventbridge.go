@@ -0,0 +1,217 @@
+//go:build !noaws
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/aws"
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+)
+
+func init() {
+	builders["eventbridge"] = func(cfg config.NotifierConfig, locale string, _ logging.Logger) (Notifier, error) {
+		notifier, err := NewEventBridgeNotifier(cfg.EventBus, cfg.EventSource, cfg.DetailType, cfg.URL, cfg.Region, locale, awsCredentials(cfg))
+		if err != nil {
+			return nil, err
+		}
+		return notifier, nil
+	}
+}
+
+// Defaults of EventBridge channels
+const (
+	DefaultEventBridgeSource     = "solana-wallet-tracker"
+	DefaultEventBridgeDetailType = "{event_type}"
+)
+
+// maxPutEvents is the most entries a PutEvents request takes
+const maxPutEvents = 10
+
+// EventBridgeNotifier puts events onto an Amazon EventBridge event bus
+type EventBridgeNotifier struct {
+	bus        string
+	source     string
+	detailType string
+	locale     string
+	client     *awsClient
+}
+
+// eventBridgeEntry is an entry of a PutEvents request
+type eventBridgeEntry struct {
+	Source       string `json:"Source"`
+	DetailType   string `json:"DetailType"`
+	Detail       string `json:"Detail"`
+	EventBusName string `json:"EventBusName"`
+	// Time is in seconds since the Unix epoch, the time of the request
+	// when zero
+	Time int64 `json:"Time,omitempty"`
+}
+
+// NewEventBridgeNotifier creates a notifier putting events onto a bus, given
+// by name or ARN, with a source and a detail type template of
+// config.EventBridgePlaceholders, signed with the static credentials when
+// set or those of the environment or IAM role otherwise. The region is that
+// of the ARN, else region, else that of the environment. An empty endpoint
+// uses that of the region.
+func NewEventBridgeNotifier(bus, source, detailType, endpoint, region, locale string, creds aws.Credentials) (*EventBridgeNotifier, error) {
+	if bus == "" {
+		bus = "default"
+	}
+	if source == "" {
+		source = DefaultEventBridgeSource
+	}
+	if detailType == "" {
+		detailType = DefaultEventBridgeDetailType
+	}
+	if arnRegion := aws.ARNRegion(bus); arnRegion != "" {
+		region = arnRegion
+	}
+	if region == "" {
+		region = aws.Region(nil)
+	}
+	if region == "" {
+		return nil, fmt.Errorf("the region of event bus %s is unknown, set region", bus)
+	}
+	if endpoint == "" {
+		endpoint = aws.Endpoint(nil, "events", "EVENTBRIDGE", region)
+	}
+	return &EventBridgeNotifier{
+		bus:        bus,
+		source:     source,
+		detailType: detailType,
+		locale:     locale,
+		client: &awsClient{
+			service:     "events",
+			region:      region,
+			endpoint:    endpoint,
+			credentials: &aws.CredentialChain{Static: creds},
+			httpClient:  &http.Client{Timeout: deliveryTimeout},
+		},
+	}, nil
+}
+
+// Name returns the notifier name
+func (e *EventBridgeNotifier) Name() string {
+	return "eventbridge"
+}
+
+// Notify puts the event onto the bus
+func (e *EventBridgeNotifier) Notify(ctx context.Context, event monitor.Event) error {
+	return e.NotifyBatch(ctx, []monitor.Event{event})
+}
+
+// NotifyBatch puts several events of one wallet onto the bus, each as an
+// entry of its own, in as few requests as possible. A retry puts them all
+// again.
+func (e *EventBridgeNotifier) NotifyBatch(ctx context.Context, events []monitor.Event) error {
+	entries := make([]eventBridgeEntry, 0, len(events))
+	for _, event := range events {
+		detail, err := json.Marshal(webhookPayload{
+			Text:   FormatMessage(e.locale, event),
+			Locale: e.locale,
+			Event:  &event,
+		})
+		if err != nil {
+			return err
+		}
+		severity := event.Severity
+		if severity == "" {
+			severity = monitor.SeverityInfo
+		}
+		entry := e.entry(detail, string(event.Type), severity, event.Network)
+		if !event.Account.LastUpdatedAt.IsZero() {
+			entry.Time = event.Account.LastUpdatedAt.Unix()
+		}
+		entries = append(entries, entry)
+	}
+	for len(entries) > 0 {
+		n := len(entries)
+		if n > maxPutEvents {
+			n = maxPutEvents
+		}
+		if err := e.put(ctx, entries[:n]); err != nil {
+			return err
+		}
+		entries = entries[n:]
+	}
+	return nil
+}
+
+// NotifyHealth puts a health report onto the bus, of event type health
+func (e *EventBridgeNotifier) NotifyHealth(ctx context.Context, health Health) error {
+	detail, err := json.Marshal(webhookPayload{
+		Text:   FormatHealthMessage(e.locale, health),
+		Locale: e.locale,
+		Health: &health,
+	})
+	if err != nil {
+		return err
+	}
+	entry := e.entry(detail, "health", monitor.SeverityInfo, "")
+	if !health.At.IsZero() {
+		entry.Time = health.At.Unix()
+	}
+	return e.put(ctx, []eventBridgeEntry{entry})
+}
+
+// entry builds the entry of a detail, rendering the detail type template
+func (e *EventBridgeNotifier) entry(detail []byte, eventType, severity, network string) eventBridgeEntry {
+	if network == "" {
+		network = "default"
+	}
+	detailType := strings.NewReplacer(
+		"{event_type}", eventType,
+		"{severity}", severity,
+		"{network}", network,
+	).Replace(e.detailType)
+	return eventBridgeEntry{
+		Source:       e.source,
+		DetailType:   strings.TrimSpace(detailType),
+		Detail:       string(detail),
+		EventBusName: e.bus,
+	}
+}
+
+// put calls PutEvents of the EventBridge JSON API. Entries EventBridge
+// rejects fail the call.
+func (e *EventBridgeNotifier) put(ctx context.Context, entries []eventBridgeEntry) error {
+	body, err := json.Marshal(map[string]interface{}{"Entries": entries})
+	if err != nil {
+		return err
+	}
+	header := http.Header{
+		"Content-Type": {"application/x-amz-json-1.1"},
+		"X-Amz-Target": {"AWSEvents.PutEvents"},
+	}
+	data, err := e.client.post(ctx, body, header)
+	if err != nil {
+		return err
+	}
+
+	var res struct {
+		FailedEntryCount int `json:"FailedEntryCount"`
+		Entries          []struct {
+			ErrorCode    string `json:"ErrorCode"`
+			ErrorMessage string `json:"ErrorMessage"`
+		} `json:"Entries"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return fmt.Errorf("unexpected EventBridge response: %s", strings.TrimSpace(string(data)))
+	}
+	if res.FailedEntryCount > 0 {
+		for _, entry := range res.Entries {
+			if entry.ErrorCode != "" {
+				return fmt.Errorf("eventbridge rejected %d of %d events: %s: %s", res.FailedEntryCount, len(entries), entry.ErrorCode, entry.ErrorMessage)
+			}
+		}
+		return fmt.Errorf("eventbridge rejected %d of %d events", res.FailedEntryCount, len(entries))
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/flow"
+	"github.com/yourusername/solana-wallet-tracker/pkg/i18n"
+)
+
+// FlowReportNotifier is implemented by notifiers that can deliver the
+// scheduled net flow reports
+type FlowReportNotifier interface {
+	NotifyFlowReport(ctx context.Context, report flow.Report) error
+}
+
+// FormatFlowReportMessage renders the built-in text for a flow report: the
+// flows of every mint over all wallets, then those of each wallet
+func FormatFlowReportMessage(locale string, report flow.Report) string {
+	lines := []string{i18n.T(locale, i18n.KeyFlowReport, report.Period)}
+	for _, f := range report.Mints {
+		lines = append(lines, formatFlow(locale, f))
+	}
+	wallet := ""
+	for _, f := range report.Wallets {
+		if f.Wallet != wallet {
+			wallet = f.Wallet
+			lines = append(lines, i18n.T(locale, i18n.KeyWallet, wallet))
+		}
+		lines = append(lines, "  "+formatFlow(locale, f))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatFlow renders the flows of a mint on one line
+func formatFlow(locale string, f flow.Flow) string {
+	token := f.Mint
+	if f.Symbol != "" {
+		token = f.Symbol
+	}
+	return i18n.T(locale, i18n.KeyFlow, token,
+		flow.FormatAmount(f.Inflow, f.Decimals), flow.FormatAmount(f.Outflow, f.Decimals), flow.FormatNet(f.Net, f.Decimals))
+}
+
+// SendFlowReport delivers a flow report to every enabled notifier that
+// takes them. Failed deliveries are logged, not retried.
+func (d *Dispatcher) SendFlowReport(report flow.Report) {
+	for index, notifier := range d.notifiers {
+		reporter, ok := notifier.(FlowReportNotifier)
+		if !ok || !d.enabled(index) {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+		err := reporter.NotifyFlowReport(ctx, report)
+		cancel()
+		if err != nil {
+			d.log.Error("Failed to deliver flow report",
+				"notifier", notifier.Name(),
+				"period", report.Period,
+				"error", err,
+			)
+		}
+	}
+}
+
+// NotifyFlowReport posts a flow report to the webhook URL
+func (w *WebhookNotifier) NotifyFlowReport(ctx context.Context, report flow.Report) error {
+	return w.post(ctx, webhookPayload{
+		Text:       FormatFlowReportMessage(w.locale, report),
+		Locale:     w.locale,
+		FlowReport: &report,
+	})
+}
+
+// NotifyFlowReport sends a flow report as a Telegram message
+func (t *TelegramNotifier) NotifyFlowReport(ctx context.Context, report flow.Report) error {
+	form := url.Values{}
+	form.Set("chat_id", t.chatID)
+	form.Set("text", FormatFlowReportMessage(t.locale, report))
+
+	return t.send(ctx, "sendMessage", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+}
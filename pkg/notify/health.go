@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/i18n"
+)
+
+// Health statuses
+const (
+	HealthOK        = "ok"
+	HealthDegraded  = "degraded"
+	HealthRecovered = "recovered"
+)
+
+// Health problems
+const (
+	ProblemNoEvents    = "no_events"
+	ProblemRPCFailures = "rpc_failures"
+)
+
+// Health is a report of the tracker about itself, sent by the watchdog.
+// Heartbeats carry the current status; degraded and recovered reports are
+// sent when it changes.
+type Health struct {
+	Status    string `json:"status"`
+	Heartbeat bool   `json:"heartbeat,omitempty"`
+	// Problems lists what is wrong while degraded
+	Problems []string `json:"problems,omitempty"`
+	Uptime   string   `json:"uptime"`
+	Wallets  int      `json:"wallets"`
+	// Events counts the events seen since the previous report
+	Events      int        `json:"events"`
+	LastEventAt *time.Time `json:"last_event_at,omitempty"`
+	// QuietFor is how long no event was seen, set with no_events
+	QuietFor string `json:"quiet_for,omitempty"`
+	// RPCFailures counts failed health checks in a row, with the last error
+	RPCFailures int       `json:"rpc_failures,omitempty"`
+	RPCError    string    `json:"rpc_error,omitempty"`
+	At          time.Time `json:"at"`
+}
+
+// Has reports whether a problem is reported
+func (h Health) Has(problem string) bool {
+	for _, p := range h.Problems {
+		if p == problem {
+			return true
+		}
+	}
+	return false
+}
+
+// HealthNotifier is implemented by notifiers that can deliver health reports
+type HealthNotifier interface {
+	NotifyHealth(ctx context.Context, health Health) error
+}
+
+// SendHealth delivers a health report within the delivery timeout
+func SendHealth(notifier Notifier, health Health) error {
+	healthNotifier, ok := notifier.(HealthNotifier)
+	if !ok {
+		return fmt.Errorf("%s notifier can't deliver health reports", notifier.Name())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+	defer cancel()
+	return healthNotifier.NotifyHealth(ctx, health)
+}
+
+// FormatHealthMessage renders the built-in text for a health report
+func FormatHealthMessage(locale string, health Health) string {
+	var lines []string
+	switch health.Status {
+	case HealthDegraded:
+		lines = append(lines, i18n.T(locale, i18n.KeyDegraded))
+	case HealthRecovered:
+		lines = append(lines, i18n.T(locale, i18n.KeyRecovered))
+	default:
+		lines = append(lines, i18n.T(locale, i18n.KeyHeartbeat, health.Uptime))
+	}
+
+	if health.Has(ProblemNoEvents) {
+		lines = append(lines, i18n.T(locale, i18n.KeyNoEvents, health.QuietFor))
+	}
+	if health.Has(ProblemRPCFailures) {
+		lines = append(lines, i18n.T(locale, i18n.KeyRPCFailures, health.RPCFailures, health.RPCError))
+	}
+	lines = append(lines, i18n.T(locale, i18n.KeyHealthEvents, health.Events, health.Wallets))
+	if health.LastEventAt != nil {
+		lines = append(lines, i18n.T(locale, i18n.KeyLastEvent, health.LastEventAt.UTC().Format(time.RFC3339)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// NotifyHealth posts a health report to the webhook URL
+func (w *WebhookNotifier) NotifyHealth(ctx context.Context, health Health) error {
+	return w.post(ctx, webhookPayload{
+		Text:   FormatHealthMessage(w.locale, health),
+		Locale: w.locale,
+		Health: &health,
+	})
+}
+
+// NotifyHealth sends a health report as a Telegram message
+func (t *TelegramNotifier) NotifyHealth(ctx context.Context, health Health) error {
+	form := url.Values{}
+	form.Set("chat_id", t.chatID)
+	form.Set("text", FormatHealthMessage(t.locale, health))
+
+	return t.send(ctx, "sendMessage", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+}
@@ -0,0 +1,136 @@
+package notify
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+)
+
+// incidentSource names the tracker in incidents
+const incidentSource = "solana-wallet-tracker"
+
+// IncidentNotifier is implemented by notifiers that open an incident for
+// critical events and resolve it once an event of the same wallet and mint
+// is routine again, e.g. when the balance returns within the bounds of the
+// critical rules
+type IncidentNotifier interface {
+	// SetCritical sets how critical events are recognized. By default only
+	// events of critical severity are.
+	SetCritical(critical func(monitor.Event) bool)
+}
+
+// incidents tracks the incidents a notifier opened, by wallet and mint.
+// Incidents are only known to the process that opened them; those left open
+// by a restart are resolved in the incident tool.
+type incidents struct {
+	critical func(monitor.Event) bool
+	mutex    sync.Mutex
+	open     map[string]bool
+	// latest is the time of the newest event handled per key, so events
+	// overtaken by newer ones, such as routine events held back by the rate
+	// limit, don't resolve or reopen an incident
+	latest map[string]time.Time
+}
+
+// newIncidents creates an empty incident tracker
+func newIncidents() *incidents {
+	return &incidents{
+		critical: func(event monitor.Event) bool { return event.Severity == monitor.SeverityCritical },
+		open:     make(map[string]bool),
+		latest:   make(map[string]time.Time),
+	}
+}
+
+// SetCritical sets how critical events are recognized
+func (i *incidents) SetCritical(critical func(monitor.Event) bool) {
+	i.critical = critical
+}
+
+// handle opens an incident for a critical event with trigger, and resolves
+// the open incident of the wallet and mint of a routine event with resolve.
+// Other routine events are ignored.
+func (i *incidents) handle(ctx context.Context, event monitor.Event,
+	trigger, resolve func(ctx context.Context, key string, event monitor.Event) error) error {
+	key := incidentKey(event)
+	critical := i.critical(event)
+	at := event.Account.LastUpdatedAt
+
+	i.mutex.Lock()
+	if at.Before(i.latest[key]) || (!critical && !i.open[key]) {
+		i.mutex.Unlock()
+		return nil
+	}
+	i.mutex.Unlock()
+
+	action := resolve
+	if critical {
+		action = trigger
+	}
+	if err := action(ctx, key, event); err != nil {
+		return err
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if at.After(i.latest[key]) {
+		i.latest[key] = at
+	}
+	if critical {
+		i.open[key] = true
+	} else {
+		delete(i.open, key)
+	}
+	return nil
+}
+
+// incidentKey deduplicates incidents by wallet and mint
+func incidentKey(event monitor.Event) string {
+	return event.Account.Owner + ":" + event.Account.Mint
+}
+
+// incidentSummary renders an event on one line with its wallet, cut to max
+// characters
+func incidentSummary(locale string, event monitor.Event, max int) string {
+	summary := walletName(event) + ": " + FormatSummaryLine(locale, event)
+	if runes := []rune(summary); len(runes) > max {
+		summary = string(runes[:max-3]) + "..."
+	}
+	return summary
+}
+
+// incidentDetails describes an event in the custom fields of an incident
+func incidentDetails(event monitor.Event) map[string]string {
+	details := map[string]string{
+		"event":   string(event.Type),
+		"wallet":  event.Account.Owner,
+		"mint":    event.Account.Mint,
+		"account": event.Account.Address,
+		"balance": FormatAmount(event.Account.Balance, event.Account.Decimals),
+	}
+	if event.Label != "" {
+		details["label"] = event.Label
+	}
+	if event.Severity != "" {
+		details["severity"] = event.Severity
+	}
+	if event.Previous != nil {
+		details["previous_balance"] = FormatAmount(event.Previous.Balance, event.Previous.Decimals)
+	}
+	if event.Burn != nil {
+		details["burned"] = FormatAmount(event.Burn.Amount, event.Account.Decimals)
+	}
+	if event.Authority != nil {
+		details["authority"] = event.Authority.Kind
+		details["previous_authority"] = event.Authority.Previous
+		details["new_authority"] = event.Authority.Current
+	}
+	if event.Freeze != nil && event.Freeze.FreezeAuthority != "" {
+		details["freeze_authority"] = event.Freeze.FreezeAuthority
+	}
+	if event.Account.Signature != "" {
+		details["signature"] = event.Account.Signature
+	}
+	return details
+}
@@ -0,0 +1,410 @@
+package notify
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/anomaly"
+	"github.com/yourusername/solana-wallet-tracker/pkg/i18n"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// stakeTitles maps stake event types to their message keys
+var stakeTitles = map[monitor.EventType]string{
+	monitor.EventStakeDelegationChanged: i18n.KeyStakeChanged,
+	monitor.EventStakeActivated:         i18n.KeyStakeActivated,
+	monitor.EventStakeDeactivated:       i18n.KeyStakeInactive,
+	monitor.EventStakeReward:            i18n.KeyStakeReward,
+}
+
+// nftTitles maps NFT event types to their message keys
+var nftTitles = map[monitor.EventType]string{
+	monitor.EventNFTReceived: i18n.KeyNFTReceived,
+	monitor.EventNFTSent:     i18n.KeyNFTSent,
+	monitor.EventNFTListed:   i18n.KeyNFTListed,
+	monitor.EventNFTBurned:   i18n.KeyNFTBurned,
+	monitor.EventNFTMinted:   i18n.KeyNFTMinted,
+}
+
+// FormatMessage renders the built-in text for an event in the given locale
+func FormatMessage(locale string, event monitor.Event) string {
+	text := formatMessage(locale, event)
+	if event.Suppressed > 0 {
+		text += "\n" + i18n.T(locale, i18n.KeySuppressed, event.Suppressed)
+	}
+	return text
+}
+
+// formatMessage renders an event without the events suppressed before it
+func formatMessage(locale string, event monitor.Event) string {
+	if event.Stake != nil {
+		return formatStakeMessage(locale, event)
+	}
+	if event.NFT != nil {
+		return formatNFTMessage(locale, event)
+	}
+	if event.Group != nil {
+		return formatGroupMessage(locale, event)
+	}
+	if event.Stale != nil {
+		return formatStaleMessage(locale, event)
+	}
+	if event.Signature != nil {
+		return formatSignatureMessage(locale, event)
+	}
+
+	title := i18n.KeyBalanceChanged
+	switch event.Type {
+	case monitor.EventNewHolding:
+		title = i18n.KeyNewHolding
+	case monitor.EventLargeHolderMove:
+		title = i18n.KeyLargeHolder
+	case monitor.EventTokenBurned:
+		title = i18n.KeyTokenBurned
+	case monitor.EventDelegateChanged:
+		title = i18n.KeyDelegation
+	case monitor.EventAuthorityChanged:
+		title = i18n.KeyAuthority
+	case monitor.EventAccountFrozen:
+		title = i18n.KeyFrozen
+	case monitor.EventAccountThawed:
+		title = i18n.KeyThawed
+	case monitor.EventAccountClosed:
+		title = i18n.KeyClosed
+	}
+
+	accountInfo := event.Account
+	lines := []string{i18n.T(locale, title)}
+	lines = append(lines, anomalyLines(locale, event)...)
+	lines = append(lines,
+		i18n.T(locale, i18n.KeyWallet, walletName(event)),
+		i18n.T(locale, i18n.KeyMint, accountInfo.Mint),
+	)
+	if event.Metadata != nil {
+		lines = append(lines, i18n.T(locale, i18n.KeyTokenName, event.Metadata.Name, event.Metadata.Symbol))
+	}
+	if event.Burn != nil {
+		lines = append(lines, i18n.T(locale, i18n.KeyBurned, FormatAmount(event.Burn.Amount, accountInfo.Decimals)))
+	}
+	if event.Type == monitor.EventDelegateChanged {
+		lines = append(lines, delegateLine(locale, event))
+	}
+	if event.Authority != nil {
+		lines = append(lines, authorityLine(locale, *event.Authority))
+	}
+	if event.Freeze != nil && event.Freeze.FreezeAuthority != "" {
+		lines = append(lines, i18n.T(locale, i18n.KeyFrozenBy, event.Freeze.FreezeAuthority))
+	}
+	lines = append(lines,
+		i18n.T(locale, i18n.KeyBalance, FormatAmount(accountInfo.Balance, accountInfo.Decimals)),
+		i18n.T(locale, i18n.KeyAccount, accountInfo.Address),
+	)
+	lines = append(lines, transferLines(locale, event)...)
+	if accountInfo.Signature != "" {
+		lines = append(lines, i18n.T(locale, i18n.KeyTransaction, accountInfo.Signature))
+	}
+	if event.Risk != nil {
+		lines = append(lines, i18n.T(locale, i18n.KeyRiskScore, event.Risk.Score))
+		if len(event.Risk.Flags) > 0 {
+			lines = append(lines, i18n.T(locale, i18n.KeyRiskFlags, strings.Join(event.Risk.Flags, ", ")))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// delegateLine describes the delegate an account was left with, or the one
+// that was revoked
+func delegateLine(locale string, event monitor.Event) string {
+	if event.Account.Delegate == "" {
+		previous := ""
+		if event.Previous != nil {
+			previous = event.Previous.Delegate
+		}
+		return i18n.T(locale, i18n.KeyRevoked, previous)
+	}
+	return i18n.T(locale, i18n.KeyDelegate, event.Account.Delegate,
+		FormatAmount(event.Account.DelegatedAmount, event.Account.Decimals))
+}
+
+// authorityLine describes the authority change of an account
+func authorityLine(locale string, change monitor.AuthorityChange) string {
+	switch {
+	case change.Kind == monitor.AuthorityOwner:
+		return i18n.T(locale, i18n.KeyNewOwner, change.Current)
+	case change.Kind == monitor.AuthorityProgram:
+		return i18n.T(locale, i18n.KeyAssigned, change.Previous, change.Current)
+	case change.Current == "":
+		return i18n.T(locale, i18n.KeyCloseRemoved, change.Previous)
+	}
+	return i18n.T(locale, i18n.KeyCloser, change.Current)
+}
+
+// anomalyLines explain why an event was flagged as an anomalous transfer
+func anomalyLines(locale string, event monitor.Event) []string {
+	if event.Anomaly == nil {
+		return nil
+	}
+
+	lines := []string{i18n.T(locale, i18n.KeyAnomaly)}
+	for _, reason := range event.Anomaly.Reasons {
+		switch reason {
+		case anomaly.ReasonSize:
+			lines = append(lines, i18n.T(locale, i18n.KeyAnomalySize,
+				formatFloat(event.Anomaly.Amount), formatFloat(event.Anomaly.Sigma), formatFloat(event.Anomaly.Mean)))
+		case anomaly.ReasonHoldings:
+			lines = append(lines, i18n.T(locale, i18n.KeyAnomalyShare, formatFloat(event.Anomaly.HoldingsShare)))
+		}
+	}
+	return lines
+}
+
+// formatFloat renders a statistic with at most two decimals
+func formatFloat(value float64) string {
+	text := strconv.FormatFloat(value, 'f', 2, 64)
+	return strings.TrimRight(strings.TrimRight(text, "0"), ".")
+}
+
+// transferLines renders the counterparties and memo of the transfer behind
+// an event, such as "Sent 50000 USDC to Binance hot wallet (exchange)".
+// Counterparties that aren't known entities are given by address.
+func transferLines(locale string, event monitor.Event) []string {
+	if event.Transfer == nil {
+		return nil
+	}
+
+	key := i18n.KeySentTo
+	switch event.Transfer.Direction {
+	case solana.DirectionIn:
+		key = i18n.KeyReceivedFrom
+	case "":
+		if event.Previous == nil || event.Account.Balance > event.Previous.Balance {
+			key = i18n.KeyReceivedFrom
+		}
+	}
+	token := event.Account.Mint
+	if event.Metadata != nil && event.Metadata.Symbol != "" {
+		token = event.Metadata.Symbol
+	}
+
+	var lines []string
+	for _, counterparty := range event.Transfer.Counterparties {
+		name := counterparty.Owner
+		if counterparty.Name != "" {
+			name = counterparty.Name
+			if counterparty.Kind != "" {
+				name = fmt.Sprintf("%s (%s)", name, counterparty.Kind)
+			}
+		}
+		lines = append(lines, i18n.T(locale, key, FormatAmount(counterparty.Amount, event.Account.Decimals), token, name))
+	}
+	if event.Transfer.Kind == solana.TransferKindMint {
+		lines = append(lines, i18n.T(locale, i18n.KeyMintedBy, event.Transfer.MintAuthority))
+	}
+	if event.Transfer.Memo != "" {
+		lines = append(lines, i18n.T(locale, i18n.KeyMemo, event.Transfer.Memo))
+	}
+	return lines
+}
+
+// formatStakeMessage renders the built-in text for a stake event
+func formatStakeMessage(locale string, event monitor.Event) string {
+	stake := event.Stake.Account
+	lines := []string{
+		i18n.T(locale, stakeTitles[event.Type]),
+		i18n.T(locale, i18n.KeyWallet, walletName(event)),
+		i18n.T(locale, i18n.KeyStakeAccount, stake.Address),
+	}
+	if stake.Voter != "" {
+		lines = append(lines, i18n.T(locale, i18n.KeyValidator, stake.Voter))
+	}
+	lines = append(lines,
+		i18n.T(locale, i18n.KeyStakeStatus, stake.Status),
+		i18n.T(locale, i18n.KeyDelegated, FormatAmount(stake.DelegatedStake, event.Account.Decimals)),
+	)
+	if reward := event.Stake.Reward; reward != nil {
+		lines = append(lines, i18n.T(locale, i18n.KeyReward, FormatAmount(reward.Amount, event.Account.Decimals), reward.Epoch))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatNFTMessage renders the built-in text for an NFT event
+func formatNFTMessage(locale string, event monitor.Event) string {
+	lines := []string{
+		i18n.T(locale, nftTitles[event.Type]),
+		i18n.T(locale, i18n.KeyWallet, walletName(event)),
+		i18n.T(locale, i18n.KeyAsset, event.NFT.Name, event.NFT.ID),
+	}
+	if event.NFT.Collection != "" {
+		lines = append(lines, i18n.T(locale, i18n.KeyCollection, event.NFT.Collection))
+	}
+	if event.Account.Signature != "" {
+		lines = append(lines, i18n.T(locale, i18n.KeyTransaction, event.Account.Signature))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatGroupMessage renders the built-in text for a group threshold event
+func formatGroupMessage(locale string, event monitor.Event) string {
+	group := event.Group
+	lines := []string{
+		groupTitle(locale, group),
+		i18n.T(locale, i18n.KeyGroupTotal, formatGroupAmount(group, group.Total), group.Wallets, formatGroupAmount(group, group.Previous)),
+	}
+	if group.Mint != "" {
+		lines = append(lines, i18n.T(locale, i18n.KeyMint, group.Mint))
+	}
+	lines = append(lines, i18n.T(locale, i18n.KeyWallet, walletName(event)))
+	if event.Account.Signature != "" {
+		lines = append(lines, i18n.T(locale, i18n.KeyTransaction, event.Account.Signature))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatStaleMessage renders the built-in text for a wallet stale event
+func formatStaleMessage(locale string, event monitor.Event) string {
+	stale := event.Stale
+	subscription := stale.Subscription
+	if stale.LastError != "" {
+		subscription += " (" + stale.LastError + ")"
+	}
+	return strings.Join([]string{
+		i18n.T(locale, i18n.KeyWalletStale, stale.StaleFor),
+		i18n.T(locale, i18n.KeyWallet, walletName(event)),
+		i18n.T(locale, i18n.KeyLastActivity, stale.LastActivityAt.UTC().Format(time.RFC3339)),
+		i18n.T(locale, i18n.KeySubscription, subscription),
+	}, "\n")
+}
+
+// formatSignatureMessage renders a transaction of the signature feed
+func formatSignatureMessage(locale string, event monitor.Event) string {
+	lines := []string{
+		i18n.T(locale, i18n.KeySignature),
+		i18n.T(locale, i18n.KeyWallet, walletName(event)),
+		i18n.T(locale, i18n.KeyTransaction, event.Signature.Signature),
+	}
+	if event.Signature.Failed {
+		lines = append(lines, i18n.T(locale, i18n.KeyTxFailed))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// groupTitle renders which threshold a group total crossed
+func groupTitle(locale string, group *monitor.GroupTotal) string {
+	if group.Group == "" {
+		key := i18n.KeyTotalBelow
+		if group.Crossed == monitor.CrossedAbove {
+			key = i18n.KeyTotalAbove
+		}
+		return i18n.T(locale, key, formatGroupAmount(group, group.Threshold))
+	}
+	key := i18n.KeyGroupBelow
+	if group.Crossed == monitor.CrossedAbove {
+		key = i18n.KeyGroupAbove
+	}
+	return i18n.T(locale, key, group.Group, formatGroupAmount(group, group.Threshold))
+}
+
+// formatGroupAmount renders a group total in UI units of its mint, or in USD
+func formatGroupAmount(group *monitor.GroupTotal, value float64) string {
+	if group.Mint == "" {
+		return fmt.Sprintf("$%.2f", value)
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// FormatBatchMessage renders several events of one wallet as a single
+// multi-line message
+func FormatBatchMessage(locale string, events []monitor.Event) string {
+	lines := []string{i18n.T(locale, i18n.KeyBatchTitle, len(events), walletName(events[0]))}
+	for _, event := range events {
+		lines = append(lines, "• "+FormatSummaryLine(locale, event))
+		if event.Suppressed > 0 {
+			lines = append(lines, "  "+i18n.T(locale, i18n.KeySuppressed, event.Suppressed))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// walletName renders the owner of an event with its label and domain, if
+// any, prefixed by its network when events carry one
+func walletName(event monitor.Event) string {
+	var names []string
+	if event.Label != "" {
+		names = append(names, event.Label)
+	}
+	if event.Domain != "" {
+		names = append(names, event.Domain)
+	}
+	name := event.Account.Owner
+	if len(names) > 0 {
+		name = fmt.Sprintf("%s (%s)", strings.Join(names, ", "), event.Account.Owner)
+	}
+	if event.Network != "" {
+		name = "[" + event.Network + "] " + name
+	}
+	return name
+}
+
+// FormatSummaryLine renders an event as a one-line summary, as batched
+// messages list them
+func FormatSummaryLine(locale string, event monitor.Event) string {
+	title := i18n.KeyBalanceChanged
+	switch {
+	case event.Stake != nil:
+		title = stakeTitles[event.Type]
+	case event.NFT != nil:
+		return fmt.Sprintf("%s: %s", i18n.T(locale, nftTitles[event.Type]), event.NFT.Name)
+	case event.Group != nil:
+		return fmt.Sprintf("%s: %s", groupTitle(locale, event.Group), formatGroupAmount(event.Group, event.Group.Total))
+	case event.Stale != nil:
+		return i18n.T(locale, i18n.KeyWalletStale, event.Stale.StaleFor)
+	case event.Signature != nil:
+		return fmt.Sprintf("%s: %s", i18n.T(locale, i18n.KeySignature), event.Signature.Signature)
+	case event.Type == monitor.EventNewHolding:
+		title = i18n.KeyNewHolding
+	case event.Type == monitor.EventLargeHolderMove:
+		title = i18n.KeyLargeHolder
+	case event.Type == monitor.EventTokenBurned:
+		title = i18n.KeyTokenBurned
+	case event.Type == monitor.EventDelegateChanged:
+		return fmt.Sprintf("%s: %s", i18n.T(locale, i18n.KeyDelegation), delegateLine(locale, event))
+	case event.Authority != nil:
+		return fmt.Sprintf("%s: %s", i18n.T(locale, i18n.KeyAuthority), authorityLine(locale, *event.Authority))
+	case event.Type == monitor.EventAccountFrozen:
+		title = i18n.KeyFrozen
+	case event.Type == monitor.EventAccountThawed:
+		title = i18n.KeyThawed
+	case event.Type == monitor.EventAccountClosed:
+		title = i18n.KeyClosed
+	}
+
+	token := event.Account.Mint
+	if event.Metadata != nil && event.Metadata.Symbol != "" {
+		token = event.Metadata.Symbol
+	}
+
+	line := fmt.Sprintf("%s: %s %s", i18n.T(locale, title), FormatAmount(event.Account.Balance, event.Account.Decimals), token)
+	if event.Previous != nil {
+		line += " (" + FormatDelta(event.Previous.Balance, event.Account.Balance, event.Account.Decimals) + ")"
+	}
+	if event.Anomaly != nil {
+		line = i18n.T(locale, i18n.KeyAnomaly) + " - " + line
+	}
+	return strings.TrimSpace(line)
+}
+
+// FormatDelta renders the signed difference between two raw amounts
+func FormatDelta(previous, current uint64, decimals uint8) string {
+	if current >= previous {
+		return "+" + FormatAmount(current-previous, decimals)
+	}
+	return "-" + FormatAmount(previous-current, decimals)
+}
+
+// FormatAmount converts a raw token amount into a decimal string
+func FormatAmount(amount uint64, decimals uint8) string {
+	return solana.UIAmountString(amount, decimals)
+}
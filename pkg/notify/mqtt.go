@@ -0,0 +1,181 @@
+//go:build !nomqtt
+
+package notify
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/mqtt"
+)
+
+func init() {
+	builders["mqtt"] = func(cfg config.NotifierConfig, locale string, _ logging.Logger) (Notifier, error) {
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("mqtt notifier requires url")
+		}
+		return NewMQTTNotifier(cfg.URL, cfg.Topic, byte(cfg.QoS), cfg.ClientID), nil
+	}
+}
+
+// DefaultMQTTTopic is the topic template of MQTT notifiers without one
+const DefaultMQTTTopic = "solana/{wallet}/{mint}"
+
+// MQTTNotifier publishes the latest balance of token accounts to an MQTT
+// broker as retained messages, one topic per account, so subscribers such
+// as Home Assistant get the current balance as soon as they connect
+type MQTTNotifier struct {
+	url      string
+	topic    string
+	qos      byte
+	clientID string
+
+	mu     sync.Mutex
+	client *mqtt.Client
+	// published is the update time of the balance last published of each
+	// token account, so retried older events don't replace newer balances
+	published map[string]time.Time
+}
+
+// mqttPayload is the JSON of a balance message
+type mqttPayload struct {
+	Wallet  string `json:"wallet"`
+	Label   string `json:"label,omitempty"`
+	Network string `json:"network,omitempty"`
+	Account string `json:"account"`
+	Mint    string `json:"mint"`
+	Symbol  string `json:"symbol,omitempty"`
+	// Balance is exact, Amount the number for templates and charts
+	Balance  string            `json:"balance"`
+	Amount   float64           `json:"amount"`
+	Previous string            `json:"previous,omitempty"`
+	Event    monitor.EventType `json:"event"`
+	At       time.Time         `json:"at"`
+}
+
+// NewMQTTNotifier creates a notifier publishing to the broker of a mqtt:// or
+// mqtts:// URL on topics rendered from a template, see MQTTTopic. An empty
+// client ID picks a random one.
+func NewMQTTNotifier(url, topic string, qos byte, clientID string) *MQTTNotifier {
+	if topic == "" {
+		topic = DefaultMQTTTopic
+	}
+	if clientID == "" {
+		suffix := make([]byte, 4)
+		rand.Read(suffix)
+		clientID = "solana-wallet-tracker-" + hex.EncodeToString(suffix)
+	}
+	return &MQTTNotifier{
+		url:       url,
+		topic:     topic,
+		qos:       qos,
+		clientID:  clientID,
+		published: make(map[string]time.Time),
+	}
+}
+
+// Name returns the notifier name
+func (m *MQTTNotifier) Name() string {
+	return "mqtt"
+}
+
+// Notify publishes the balance of the token account of the event. Events
+// without a token account, such as stake events, are skipped.
+func (m *MQTTNotifier) Notify(ctx context.Context, event monitor.Event) error {
+	account := event.Account
+	if account.Address == "" || account.Mint == "" {
+		return nil
+	}
+
+	payload := mqttPayload{
+		Wallet:  account.Owner,
+		Label:   event.Label,
+		Network: event.Network,
+		Account: account.Address,
+		Mint:    account.Mint,
+		Balance: account.UIAmountString(),
+		Amount:  account.UIAmount(),
+		Event:   event.Type,
+		At:      account.LastUpdatedAt,
+	}
+	if payload.At.IsZero() {
+		payload.At = time.Now().UTC()
+	}
+	if event.Metadata != nil {
+		payload.Symbol = event.Metadata.Symbol
+	}
+	if event.Previous != nil {
+		payload.Previous = event.Previous.UIAmountString()
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return m.publish(ctx, MQTTTopic(m.topic, event), body, account.Address, payload.At)
+}
+
+// publish sends the retained balance of a token account updated at a time,
+// connecting first if needed. A failed publish drops the connection so the
+// next one, or a retry, reconnects.
+func (m *MQTTNotifier) publish(ctx context.Context, topic string, body []byte, account string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if at.Before(m.published[account]) {
+		return nil
+	}
+
+	// Brokers drop connections idle past their keep alive, which a QoS 0
+	// publish wouldn't notice
+	if m.client != nil && m.client.Idle() {
+		m.client.Close()
+		m.client = nil
+	}
+	if m.client == nil {
+		client, err := mqtt.Dial(ctx, m.url, mqtt.Options{ClientID: m.clientID})
+		if err != nil {
+			return err
+		}
+		m.client = client
+	}
+
+	if err := m.client.Publish(ctx, topic, body, m.qos, true); err != nil {
+		m.client.Close()
+		m.client = nil
+		return err
+	}
+	m.published[account] = at
+	return nil
+}
+
+// MQTTTopic renders a topic template for an event. {label} falls back to the
+// wallet, {symbol} to the mint and {network} to "default".
+func MQTTTopic(template string, event monitor.Event) string {
+	label := event.Label
+	if label == "" {
+		label = event.Account.Owner
+	}
+	symbol := event.Account.Mint
+	if event.Metadata != nil && event.Metadata.Symbol != "" {
+		symbol = event.Metadata.Symbol
+	}
+	network := event.Network
+	if network == "" {
+		network = "default"
+	}
+	return mqtt.Render(template, map[string]string{
+		"{wallet}":  event.Account.Owner,
+		"{mint}":    event.Account.Mint,
+		"{account}": event.Account.Address,
+		"{label}":   label,
+		"{symbol}":  symbol,
+		"{network}": network,
+	})
+}
@@ -0,0 +1,302 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/i18n"
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+)
+
+// deliveryTimeout bounds a single notification delivery
+const deliveryTimeout = 10 * time.Second
+
+// Notifier delivers monitor events to an external channel
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, event monitor.Event) error
+}
+
+// BatchNotifier is implemented by notifiers that can deliver several events
+// as a single message
+type BatchNotifier interface {
+	NotifyBatch(ctx context.Context, events []monitor.Event) error
+}
+
+// builder creates a notifier of one type from its configuration, with the
+// locale already resolved
+type builder func(cfg config.NotifierConfig, locale string, log logging.Logger) (Notifier, error)
+
+// builders are the optional notifier types compiled into the binary. Each
+// registers itself from a file excluded by its build tag, like the store
+// backends, so minimal builds leave the cloud clients out.
+var builders = map[string]builder{}
+
+// builderTags are the build tags that exclude optional notifier types
+var builderTags = map[string]string{
+	"eventbridge": "noaws",
+	"mqtt":        "nomqtt",
+	"pubsub":      "nogcp",
+	"sns":         "noaws",
+	"sqs":         "noaws",
+}
+
+// New creates a notifier from its configuration, logging through log or the
+// default logger when nil. history may be nil when no store is configured;
+// channels that render charts then send text only.
+func New(cfg config.NotifierConfig, history store.Store, log logging.Logger) (Notifier, error) {
+	if log == nil {
+		log = logging.Default()
+	}
+	locale := cfg.Locale
+	if locale == "" {
+		locale = i18n.DefaultLocale
+	}
+	if !i18n.Supported(locale) {
+		log.Warn("Unsupported locale, falling back to the default",
+			"locale", locale,
+			"notifier", cfg.Type,
+			"default", i18n.DefaultLocale,
+		)
+		locale = i18n.DefaultLocale
+	}
+
+	switch cfg.Type {
+	case "webhook":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("webhook notifier requires url")
+		}
+		notifier := NewWebhookNotifier(cfg.URL, locale, cfg.Secret)
+		notifier.SetCloudEvents(cloudEventSource(cfg))
+		return notifier, nil
+	case "telegram":
+		if cfg.BotToken == "" || cfg.ChatID == "" {
+			return nil, fmt.Errorf("telegram notifier requires bot_token and chat_id")
+		}
+		if !cfg.Chart {
+			history = nil
+		}
+		return NewTelegramNotifier(cfg.BotToken, cfg.ChatID, locale, history, log), nil
+	case "pagerduty":
+		if cfg.RoutingKey == "" {
+			return nil, fmt.Errorf("pagerduty notifier requires routing_key")
+		}
+		return NewPagerDutyNotifier(cfg.RoutingKey, cfg.URL, locale), nil
+	case "opsgenie":
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("opsgenie notifier requires api_key")
+		}
+		return NewOpsgenieNotifier(cfg.APIKey, cfg.URL, locale), nil
+	}
+
+	if build, ok := builders[cfg.Type]; ok {
+		return build(cfg, locale, log)
+	}
+	if tag, ok := builderTags[cfg.Type]; ok {
+		return nil, fmt.Errorf("%s notifier is not available in this build (built with the %s tag)", cfg.Type, tag)
+	}
+	return nil, fmt.Errorf("unknown notifier type: %q", cfg.Type)
+}
+
+// Dispatcher fans monitor events out to all configured notifiers. With a
+// batch window, events of the same wallet arriving within the window, such
+// as the token and SOL accounts touched by one swap, are delivered together.
+type Dispatcher struct {
+	notifiers   []Notifier
+	tenant      string
+	batchWindow time.Duration
+	pending     map[string][]monitor.Event
+	mutex       sync.Mutex
+	log         logging.Logger
+
+	// Lowest severity of each notifier, see SetMinSeverities
+	minSeverities []string
+
+	// Names of the notifiers and those turned off, see SetNames
+	names []string
+	muted map[int]bool
+
+	// Quiet hours of the notifiers and the events they hold, see
+	// SetSchedules
+	schedules []*Schedule
+	held      map[int][]monitor.Event
+
+	// Digest windows of the notifiers and the events collected for their
+	// digests by notifier and wallet, see SetDigestWindows
+	digestWindows []time.Duration
+	digests       map[int]map[string][]monitor.Event
+
+	// Notifications of each wallet and mint within the last minute, see
+	// SetCooldown
+	cooldownLimit int
+	cooldowns     map[string]*cooldownState
+
+	// Priority lanes, see SetPriority
+	critical []Rule
+	interval time.Duration
+	routine  chan []monitor.Event
+	flushing chan struct{}
+	stopped  chan struct{}
+	closed   bool
+
+	// Retries of failed deliveries, see SetRetry
+	retry          *RetryPolicy
+	deadLetters    store.DeadLetterStore
+	retries        sync.WaitGroup
+	stopRetries    chan struct{}
+	retriesStopped bool
+}
+
+// NewDispatcher creates a dispatcher for the given notifiers, logging
+// through log or the default logger when nil. A zero batch window delivers
+// every event on its own.
+func NewDispatcher(batchWindow time.Duration, log logging.Logger, notifiers ...Notifier) *Dispatcher {
+	if log == nil {
+		log = logging.Default()
+	}
+	return &Dispatcher{
+		notifiers:   notifiers,
+		batchWindow: batchWindow,
+		pending:     make(map[string][]monitor.Event),
+		log:         log,
+	}
+}
+
+// SetTenant names the tenant whose notifiers the dispatcher delivers to, so
+// its dead letters are replayed through them
+func (d *Dispatcher) SetTenant(tenant string) {
+	d.tenant = tenant
+}
+
+// Handle delivers the event to every notifier. It matches the
+// monitor.EventHandler signature so it can be registered directly. Events of
+// hot wallets are delivered at once like critical ones.
+func (d *Dispatcher) Handle(event monitor.Event) {
+	urgent := event.Hot || d.isCritical(event)
+	if !d.cool(&event, urgent) {
+		return
+	}
+	if urgent {
+		d.deliver([]monitor.Event{event})
+		return
+	}
+	if d.batchWindow <= 0 {
+		d.enqueue([]monitor.Event{event})
+		return
+	}
+
+	key := batchKey(event)
+
+	d.mutex.Lock()
+	d.pending[key] = append(d.pending[key], event)
+	first := len(d.pending[key]) == 1
+	d.mutex.Unlock()
+
+	if first {
+		time.AfterFunc(d.batchWindow, func() { d.flush(key) })
+	}
+}
+
+// Flush delivers all pending batches and queued events immediately, e.g. on
+// shutdown. Deliveries waiting for a retry are moved to the dead letter
+// queue. Pending digests and events held for quiet hours are delivered.
+func (d *Dispatcher) Flush() {
+	d.mutex.Lock()
+	keys := make([]string, 0, len(d.pending))
+	for key := range d.pending {
+		keys = append(keys, key)
+	}
+	d.mutex.Unlock()
+
+	for _, key := range keys {
+		d.flush(key)
+	}
+	d.closeRoutineLane()
+	d.deliverAllDigests()
+	d.deliverAllHeld()
+	d.stopRetrying()
+}
+
+// flush delivers the pending batch for a key
+func (d *Dispatcher) flush(key string) {
+	d.mutex.Lock()
+	events := d.pending[key]
+	delete(d.pending, key)
+	d.mutex.Unlock()
+
+	if len(events) > 0 {
+		d.enqueue(events)
+	}
+}
+
+// deliver sends events to every notifier that is on and out of its quiet
+// hours, or collects them for its digest
+func (d *Dispatcher) deliver(all []monitor.Event) {
+	for index := range d.notifiers {
+		if !d.enabled(index) {
+			continue
+		}
+		events := d.collect(index, d.hold(index, d.accepts(index, all)))
+		if len(events) == 0 {
+			continue
+		}
+		d.deliverTo(index, events)
+	}
+}
+
+// deliverTo sends events to a notifier, as one message where supported.
+// Failed deliveries are retried if a retry policy is set.
+func (d *Dispatcher) deliverTo(index int, events []monitor.Event) {
+	notifier := d.notifiers[index]
+	if _, canBatch := notifier.(BatchNotifier); len(events) > 1 && canBatch {
+		if err := Send(notifier, events); err != nil {
+			d.log.Error("Failed to deliver notification batch",
+				"notifier", notifier.Name(),
+				"events", len(events),
+				"wallet", events[0].Account.Owner,
+				"label", events[0].Label,
+				"error", err,
+			)
+			d.retryLater(index, events, err)
+		}
+		return
+	}
+
+	for _, event := range events {
+		single := []monitor.Event{event}
+		if err := Send(notifier, single); err != nil {
+			d.log.Error("Failed to deliver notification",
+				"notifier", notifier.Name(),
+				"event", event.Type,
+				"wallet", event.Account.Owner,
+				"label", event.Label,
+				"mint", event.Account.Mint,
+				"error", err,
+			)
+			d.retryLater(index, single, err)
+		}
+	}
+}
+
+// batchKey groups the events of one transaction, such as a swap changing
+// several token accounts of a wallet, into one notification. The events of
+// a transaction are batched per wallet, as batch messages name one wallet.
+// The monitor only knows the signature of updates delivered by Geyser or
+// webhooks; RPC subscription updates get it from the transfer or signature
+// enricher. Events whose transaction isn't known are batched by wallet.
+func batchKey(event monitor.Event) string {
+	if event.Account.Signature == "" {
+		return walletKey(event)
+	}
+	return walletKey(event) + ":" + event.Account.Signature
+}
+
+// walletKey groups the events of a wallet, as digests sum them up
+func walletKey(event monitor.Event) string {
+	return event.Account.Owner
+}
@@ -0,0 +1,85 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+func TestBatchKey(t *testing.T) {
+	event := func(owner, signature string) monitor.Event {
+		return monitor.Event{Account: solana.TokenAccountInfo{Owner: owner, Signature: signature}}
+	}
+
+	tests := []struct {
+		name string
+		a, b monitor.Event
+		same bool
+	}{
+		{"accounts of one transaction", event("w1", "sig1"), event("w1", "sig1"), true},
+		{"two transactions of a wallet", event("w1", "sig1"), event("w1", "sig2"), false},
+		{"one transaction of two wallets", event("w1", "sig1"), event("w2", "sig1"), false},
+		{"unresolved transactions of a wallet", event("w1", ""), event("w1", ""), true},
+		{"resolved and unresolved", event("w1", "sig1"), event("w1", ""), false},
+	}
+	for _, tt := range tests {
+		if same := batchKey(tt.a) == batchKey(tt.b); same != tt.same {
+			t.Errorf("%s: same batch = %v, want %v", tt.name, same, tt.same)
+		}
+	}
+
+	// Digests still sum up every transaction of a wallet
+	if walletKey(event("w1", "sig1")) != walletKey(event("w1", "sig2")) {
+		t.Errorf("transactions of a wallet have different wallet keys")
+	}
+}
+
+// batchRecorder records the batches delivered to it
+type batchRecorder struct {
+	batches [][]monitor.Event
+}
+
+func (r *batchRecorder) Name() string { return "recorder" }
+
+func (r *batchRecorder) Notify(ctx context.Context, event monitor.Event) error {
+	return r.NotifyBatch(ctx, []monitor.Event{event})
+}
+
+func (r *batchRecorder) NotifyBatch(ctx context.Context, events []monitor.Event) error {
+	r.batches = append(r.batches, events)
+	return nil
+}
+
+func TestDispatcherBatchesWithoutEnricher(t *testing.T) {
+	// RPC subscription updates carry no signature unless an enricher
+	// resolves it, so their events are batched per wallet
+	event := func(owner, account string) monitor.Event {
+		return monitor.Event{
+			Type:    monitor.EventBalanceChanged,
+			Account: solana.TokenAccountInfo{Owner: owner, Address: account},
+		}
+	}
+
+	recorder := &batchRecorder{}
+	dispatcher := NewDispatcher(time.Hour, nil, recorder)
+	dispatcher.Handle(event("w1", "a1"))
+	dispatcher.Handle(event("w1", "a2"))
+	dispatcher.Handle(event("w2", "a3"))
+	dispatcher.Flush()
+
+	sizes := map[string]int{}
+	for _, batch := range recorder.batches {
+		sizes[batch[0].Account.Owner] += len(batch)
+		for _, event := range batch {
+			if event.Account.Owner != batch[0].Account.Owner {
+				t.Errorf("batch mixes wallets %s and %s", batch[0].Account.Owner, event.Account.Owner)
+			}
+		}
+	}
+	if len(recorder.batches) != 2 || sizes["w1"] != 2 || sizes["w2"] != 1 {
+		t.Errorf("batches = %d with %v events per wallet, want 2 with w1:2 w2:1", len(recorder.batches), sizes)
+	}
+}
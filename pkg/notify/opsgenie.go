@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+)
+
+// opsgenieAPI is the base URL of the Opsgenie API. Accounts in the EU
+// region use https://api.eu.opsgenie.com.
+const opsgenieAPI = "https://api.opsgenie.com"
+
+// opsgenieMessageLength is the longest alert message Opsgenie accepts
+const opsgenieMessageLength = 130
+
+// OpsgenieNotifier creates Opsgenie alerts for critical events and closes
+// them once the wallet and mint are routine again
+type OpsgenieNotifier struct {
+	*incidents
+	apiKey     string
+	baseURL    string
+	locale     string
+	httpClient *http.Client
+}
+
+// opsgenieAlert is the body creating an alert
+type opsgenieAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description"`
+	Entity      string            `json:"entity,omitempty"`
+	Source      string            `json:"source"`
+	Priority    string            `json:"priority"`
+	Tags        []string          `json:"tags,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+// opsgenieClose is the body closing an alert
+type opsgenieClose struct {
+	Source string `json:"source"`
+	Note   string `json:"note,omitempty"`
+}
+
+// NewOpsgenieNotifier creates an Opsgenie notifier for an API integration.
+// An empty baseURL uses the Opsgenie API of the US region.
+func NewOpsgenieNotifier(apiKey, baseURL, locale string) *OpsgenieNotifier {
+	if baseURL == "" {
+		baseURL = opsgenieAPI
+	}
+	return &OpsgenieNotifier{
+		incidents:  newIncidents(),
+		apiKey:     apiKey,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		locale:     locale,
+		httpClient: &http.Client{},
+	}
+}
+
+// Name returns the notifier name
+func (o *OpsgenieNotifier) Name() string {
+	return "opsgenie"
+}
+
+// Notify creates an alert for a critical event or closes the open alert of
+// the wallet and mint of a routine one
+func (o *OpsgenieNotifier) Notify(ctx context.Context, event monitor.Event) error {
+	return o.handle(ctx, event, o.create, o.close)
+}
+
+// create opens an alert, which Opsgenie deduplicates by alias while it is
+// open
+func (o *OpsgenieNotifier) create(ctx context.Context, key string, event monitor.Event) error {
+	alert := opsgenieAlert{
+		Message:     incidentSummary(o.locale, event, opsgenieMessageLength),
+		Alias:       key,
+		Description: FormatMessage(o.locale, event),
+		Entity:      event.Account.Owner,
+		Source:      incidentSource,
+		Priority:    "P1",
+		Tags:        append([]string{string(event.Type)}, event.Groups...),
+		Details:     incidentDetails(event),
+	}
+	return o.post(ctx, "/v2/alerts", alert)
+}
+
+// close closes the open alert of a key
+func (o *OpsgenieNotifier) close(ctx context.Context, key string, event monitor.Event) error {
+	path := "/v2/alerts/" + url.PathEscape(key) + "/close?identifierType=alias"
+	return o.post(ctx, path, opsgenieClose{
+		Source: incidentSource,
+		Note:   FormatMessage(o.locale, event),
+	})
+}
+
+// post sends a request to the Opsgenie API
+func (o *OpsgenieNotifier) post(ctx context.Context, path string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+o.apiKey)
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post opsgenie alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("opsgenie returned status %d", resp.StatusCode)
+	}
+	return nil
+}
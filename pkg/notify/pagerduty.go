@@ -0,0 +1,129 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+)
+
+// pagerDutyEventsURL is the endpoint of the PagerDuty Events API v2
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// pagerDutySummaryLength is the longest summary PagerDuty accepts
+const pagerDutySummaryLength = 1024
+
+// PagerDutyNotifier triggers PagerDuty incidents for critical events through
+// the Events API v2 and resolves them once the wallet and mint are routine
+// again
+type PagerDutyNotifier struct {
+	*incidents
+	routingKey string
+	url        string
+	locale     string
+	httpClient *http.Client
+}
+
+// pagerDutyEvent is a request of the Events API v2
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+// pagerDutyPayload describes the incident of a trigger event
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp,omitempty"`
+	Component     string            `json:"component,omitempty"`
+	Class         string            `json:"class,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+// NewPagerDutyNotifier creates a PagerDuty notifier for the service of a
+// routing key. An empty url uses the PagerDuty endpoint.
+func NewPagerDutyNotifier(routingKey, url, locale string) *PagerDutyNotifier {
+	if url == "" {
+		url = pagerDutyEventsURL
+	}
+	return &PagerDutyNotifier{
+		incidents:  newIncidents(),
+		routingKey: routingKey,
+		url:        url,
+		locale:     locale,
+		httpClient: &http.Client{},
+	}
+}
+
+// Name returns the notifier name
+func (p *PagerDutyNotifier) Name() string {
+	return "pagerduty"
+}
+
+// Notify triggers an incident for a critical event or resolves the open
+// incident of the wallet and mint of a routine one
+func (p *PagerDutyNotifier) Notify(ctx context.Context, event monitor.Event) error {
+	return p.handle(ctx, event, p.trigger, p.resolve)
+}
+
+// trigger opens or updates the incident of a key
+func (p *PagerDutyNotifier) trigger(ctx context.Context, key string, event monitor.Event) error {
+	payload := &pagerDutyPayload{
+		Summary:       incidentSummary(p.locale, event, pagerDutySummaryLength),
+		Source:        incidentSource,
+		Severity:      "critical",
+		Component:     event.Account.Mint,
+		Class:         string(event.Type),
+		CustomDetails: incidentDetails(event),
+	}
+	if !event.Account.LastUpdatedAt.IsZero() {
+		payload.Timestamp = event.Account.LastUpdatedAt.Format(time.RFC3339)
+	}
+	return p.send(ctx, pagerDutyEvent{
+		RoutingKey:  p.routingKey,
+		EventAction: "trigger",
+		DedupKey:    key,
+		Payload:     payload,
+	})
+}
+
+// resolve closes the incident of a key
+func (p *PagerDutyNotifier) resolve(ctx context.Context, key string, event monitor.Event) error {
+	return p.send(ctx, pagerDutyEvent{
+		RoutingKey:  p.routingKey,
+		EventAction: "resolve",
+		DedupKey:    key,
+	})
+}
+
+// send posts an event to the Events API
+func (p *PagerDutyNotifier) send(ctx context.Context, event pagerDutyEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send pagerduty %s: %w", event.EventAction, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("pagerduty returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package notify
+
+import (
+	"math"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+)
+
+// routineQueueSize bounds the routine notifications waiting for the rate
+// limit. Routine events are dropped when it is full.
+const routineQueueSize = 1000
+
+// Rule selects critical events. Empty criteria match everything, so an
+// event is critical when it meets every criterion set.
+type Rule struct {
+	types        map[monitor.EventType]bool
+	wallets      map[string]bool
+	groups       map[string]bool
+	mints        map[string]bool
+	minRiskScore int
+	severities   map[string]bool
+	transfers    map[string]bool
+	below        float64
+	above        float64
+}
+
+// NewRule builds a rule from its configuration
+func NewRule(cfg config.PriorityRuleConfig) Rule {
+	rule := Rule{
+		types:        make(map[monitor.EventType]bool, len(cfg.Types)),
+		wallets:      toSet(cfg.Wallets),
+		groups:       toSet(cfg.Groups),
+		mints:        toSet(cfg.Mints),
+		minRiskScore: cfg.MinRiskScore,
+		severities:   toSet(cfg.Severities),
+		transfers:    toSet(cfg.TransferKinds),
+		below:        cfg.Below,
+		above:        cfg.Above,
+	}
+	for _, eventType := range cfg.Types {
+		rule.types[monitor.EventType(eventType)] = true
+	}
+	return rule
+}
+
+// Matches reports whether the event meets every criterion of the rule
+func (r Rule) Matches(event monitor.Event) bool {
+	if len(r.types) > 0 && !r.types[event.Type] {
+		return false
+	}
+	if len(r.wallets) > 0 && !r.wallets[event.Account.Owner] {
+		return false
+	}
+	if len(r.mints) > 0 && !r.mints[event.Account.Mint] {
+		return false
+	}
+	if len(r.groups) > 0 {
+		inGroup := false
+		for _, group := range event.Groups {
+			inGroup = inGroup || r.groups[group]
+		}
+		if !inGroup {
+			return false
+		}
+	}
+	if r.minRiskScore > 0 && (event.WalletRisk == nil || event.WalletRisk.Score < r.minRiskScore) {
+		return false
+	}
+	if len(r.severities) > 0 && !r.severities[event.Severity] {
+		return false
+	}
+	if len(r.transfers) > 0 && (event.Transfer == nil || !r.transfers[event.Transfer.Kind]) {
+		return false
+	}
+	if r.below > 0 || r.above > 0 {
+		return r.outOfBounds(event)
+	}
+	return true
+}
+
+// outOfBounds reports whether a balance event left the account below or
+// above the bounds of the rule
+func (r Rule) outOfBounds(event monitor.Event) bool {
+	if event.Type != monitor.EventBalanceChanged && event.Type != monitor.EventNewHolding && event.Type != monitor.EventTokenBurned {
+		return false
+	}
+	balance := float64(event.Account.Balance) / math.Pow10(int(event.Account.Decimals))
+	return (r.below > 0 && balance < r.below) || (r.above > 0 && balance > r.above)
+}
+
+// SetPriority configures the lanes. Events matching any critical rule are
+// delivered at once, bypassing batching and the rate limit. Routine events
+// are limited to rateLimit notifications per minute when it is positive,
+// queueing behind the limit during bursts. Call it before handling events.
+func (d *Dispatcher) SetPriority(critical []Rule, rateLimit int) {
+	d.critical = critical
+	SetCriticalRules(d.notifiers, critical)
+	if rateLimit > 0 {
+		d.interval = time.Minute / time.Duration(rateLimit)
+		d.routine = make(chan []monitor.Event, routineQueueSize)
+		d.flushing = make(chan struct{})
+		d.stopped = make(chan struct{})
+		go d.runRoutineLane()
+	}
+}
+
+// isCritical reports whether an event is of critical severity or matches a
+// critical rule
+func (d *Dispatcher) isCritical(event monitor.Event) bool {
+	return isCritical(d.critical, event)
+}
+
+// SetCriticalRules lets the incident notifiers among notifiers open
+// incidents for events matching the critical rules. SetPriority does it for
+// the notifiers of a dispatcher.
+func SetCriticalRules(notifiers []Notifier, critical []Rule) {
+	for _, notifier := range notifiers {
+		if incidents, ok := notifier.(IncidentNotifier); ok {
+			incidents.SetCritical(func(event monitor.Event) bool { return isCritical(critical, event) })
+		}
+	}
+}
+
+// isCritical reports whether an event is of critical severity or matches
+// any of the rules
+func isCritical(rules []Rule, event monitor.Event) bool {
+	if event.Severity == monitor.SeverityCritical {
+		return true
+	}
+	for _, rule := range rules {
+		if rule.Matches(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueue delivers routine events, through the rate limited queue if there
+// is one
+func (d *Dispatcher) enqueue(events []monitor.Event) {
+	d.mutex.Lock()
+	queued := d.routine != nil && !d.closed
+	if queued {
+		select {
+		case d.routine <- events:
+		default:
+			d.log.Warn("Notification queue full, dropping routine events",
+				"events", len(events),
+				"wallet", events[0].Account.Owner,
+			)
+		}
+	}
+	d.mutex.Unlock()
+
+	if !queued {
+		d.deliver(events)
+	}
+}
+
+// runRoutineLane delivers queued routine events no faster than the rate
+// limit until the dispatcher is flushed
+func (d *Dispatcher) runRoutineLane() {
+	defer close(d.stopped)
+	for events := range d.routine {
+		d.deliver(events)
+		select {
+		case <-time.After(d.interval):
+		case <-d.flushing:
+		}
+	}
+}
+
+// closeRoutineLane stops queueing and delivers what is left without waiting
+// for the rate limit
+func (d *Dispatcher) closeRoutineLane() {
+	d.mutex.Lock()
+	if d.routine == nil || d.closed {
+		d.mutex.Unlock()
+		return
+	}
+	d.closed = true
+	close(d.flushing)
+	close(d.routine)
+	d.mutex.Unlock()
+
+	<-d.stopped
+}
+
+// toSet converts a list to a lookup set
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
@@ -0,0 +1,371 @@
+//go:build !nogcp
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/events"
+	"github.com/yourusername/solana-wallet-tracker/pkg/gcp"
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+)
+
+func init() {
+	builders["pubsub"] = func(cfg config.NotifierConfig, locale string, log logging.Logger) (Notifier, error) {
+		if cfg.Topic == "" {
+			return nil, fmt.Errorf("pubsub notifier requires topic")
+		}
+		notifier := NewPubSubNotifier(cfg.Topic, cfg.URL, locale, cfg.Attributes, cfg.CredentialsFile, log)
+		notifier.SetEncoding(cfg.Format, cfg.Schema)
+		notifier.SetCloudEvents(cloudEventSource(cfg))
+		return notifier, nil
+	}
+}
+
+// pubSubEndpoint is the global endpoint of Pub/Sub
+const pubSubEndpoint = "https://pubsub.googleapis.com"
+
+// DefaultPubSubAttributes are the attributes of Pub/Sub channels that don't
+// choose them
+var DefaultPubSubAttributes = []string{"wallet", "mint", "event_type", "severity"}
+
+// PubSubNotifier publishes events to a Google Cloud Pub/Sub topic. Messages
+// are ordered per wallet by their ordering key.
+type PubSubNotifier struct {
+	topic      string
+	endpoint   string
+	locale     string
+	attributes []string
+	// tokens is nil for the emulator, which takes no credentials
+	tokens     *gcp.TokenSource
+	httpClient *http.Client
+	log        logging.Logger
+
+	// Encoding of messages and the schema checking them, see SetEncoding
+	format string
+	schema string
+	// cloudEventSource is the source of CloudEvents, empty to publish plain
+	// payloads
+	cloudEventSource string
+	// schemaMutex guards schemaReady, set once the schema matches the
+	// records
+	schemaMutex sync.Mutex
+	schemaReady bool
+}
+
+// pubSubSchema is a schema of the Pub/Sub schema API
+type pubSubSchema struct {
+	Name       string `json:"name,omitempty"`
+	Type       string `json:"type"`
+	Definition string `json:"definition"`
+}
+
+// pubSubMessage is a message of the Pub/Sub publish API
+type pubSubMessage struct {
+	Data        string            `json:"data"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	OrderingKey string            `json:"orderingKey,omitempty"`
+}
+
+// NewPubSubNotifier creates a notifier publishing to a topic named
+// projects/PROJECT/topics/TOPIC, with the given event fields of
+// config.PubSubAttributes as attributes, DefaultPubSubAttributes when empty.
+// An empty endpoint uses the global one, or the emulator of
+// PUBSUB_EMULATOR_HOST. An empty credentials file uses the credentials of
+// the environment, see gcp.TokenSource. It logs through log, or the default
+// logger when nil.
+func NewPubSubNotifier(topic, endpoint, locale string, attributes []string, credentialsFile string, log logging.Logger) *PubSubNotifier {
+	if log == nil {
+		log = logging.Default()
+	}
+	if len(attributes) == 0 {
+		attributes = DefaultPubSubAttributes
+	}
+	tokens := &gcp.TokenSource{CredentialsFile: credentialsFile}
+	if endpoint == "" {
+		endpoint = pubSubEndpoint
+		if emulator := os.Getenv("PUBSUB_EMULATOR_HOST"); emulator != "" {
+			endpoint = "http://" + emulator
+			tokens = nil
+		}
+	}
+	return &PubSubNotifier{
+		topic:      topic,
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		locale:     locale,
+		attributes: attributes,
+		tokens:     tokens,
+		httpClient: &http.Client{Timeout: deliveryTimeout},
+		log:        log,
+	}
+}
+
+// SetEncoding publishes events as the avro or protobuf records of
+// pkg/events instead of the JSON payload of webhooks. With a schema, named
+// projects/PROJECT/schemas/SCHEMA, the first publish creates it or commits
+// a revision when its definition differs from that of the records.
+func (p *PubSubNotifier) SetEncoding(format, schema string) {
+	p.format = format
+	p.schema = schema
+}
+
+// SetCloudEvents publishes JSON payloads in CloudEvents envelopes of the
+// source, in the structured mode of the Pub/Sub binding, or plain ones when
+// it is empty
+func (p *PubSubNotifier) SetCloudEvents(source string) {
+	p.cloudEventSource = source
+}
+
+// Name returns the notifier name
+func (p *PubSubNotifier) Name() string {
+	return "pubsub"
+}
+
+// Notify publishes the event to the topic
+func (p *PubSubNotifier) Notify(ctx context.Context, event monitor.Event) error {
+	return p.NotifyBatch(ctx, []monitor.Event{event})
+}
+
+// NotifyBatch publishes several events of one wallet in one request, each
+// as a message of its own
+func (p *PubSubNotifier) NotifyBatch(ctx context.Context, events []monitor.Event) error {
+	if err := p.ensureSchema(ctx); err != nil {
+		return err
+	}
+	messages := make([]pubSubMessage, 0, len(events))
+	for _, event := range events {
+		data, err := p.encode(event)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, pubSubMessage{
+			Data:        base64.StdEncoding.EncodeToString(data),
+			Attributes:  p.withContentType(p.eventAttributes(event)),
+			OrderingKey: event.Account.Owner,
+		})
+	}
+	return p.publish(ctx, messages)
+}
+
+// NotifyHealth publishes a health report to the topic, with the attributes
+// event_type health and its status
+func (p *PubSubNotifier) NotifyHealth(ctx context.Context, health Health) error {
+	data, _, err := marshalPayload(p.cloudEventSource, webhookPayload{
+		Text:   FormatHealthMessage(p.locale, health),
+		Locale: p.locale,
+		Health: &health,
+	})
+	if err != nil {
+		return err
+	}
+	return p.publish(ctx, []pubSubMessage{{
+		Data:       base64.StdEncoding.EncodeToString(data),
+		Attributes: p.withContentType(map[string]string{"event_type": "health", "status": health.Status}),
+	}})
+}
+
+// encode serializes an event in the format of the notifier
+func (p *PubSubNotifier) encode(event monitor.Event) ([]byte, error) {
+	text := FormatMessage(p.locale, event)
+	switch p.format {
+	case "avro":
+		record := eventRecord(event, text)
+		return record.MarshalAvro(), nil
+	case "protobuf":
+		record := eventRecord(event, text)
+		return record.MarshalProto(), nil
+	}
+	data, _, err := marshalPayload(p.cloudEventSource, webhookPayload{
+		Text:   text,
+		Locale: p.locale,
+		Event:  &event,
+	})
+	return data, err
+}
+
+// withContentType adds the content-type attribute the CloudEvents binding
+// of Pub/Sub marks structured messages with, if the notifier sends
+// CloudEvents
+func (p *PubSubNotifier) withContentType(attributes map[string]string) map[string]string {
+	if p.cloudEventSource != "" {
+		attributes["content-type"] = cloudEventContentType
+	}
+	return attributes
+}
+
+// eventRecord flattens an event into the record of pkg/events
+func eventRecord(event monitor.Event, text string) events.Record {
+	record := events.Record{
+		Type:       events.Type(event.Type),
+		Wallet:     event.Account.Owner,
+		Account:    event.Account.Address,
+		Mint:       event.Account.Mint,
+		Decimals:   event.Account.Decimals,
+		Balance:    event.Account.Balance,
+		Signature:  event.Account.Signature,
+		Slot:       event.Account.Slot,
+		ObservedAt: event.Account.LastUpdatedAt,
+		Severity:   event.Severity,
+		Label:      event.Label,
+		Network:    event.Network,
+		Groups:     event.Groups,
+		Tenants:    event.Tenants,
+		Hot:        event.Hot,
+		Replayed:   event.Replayed,
+		Suppressed: event.Suppressed,
+		Text:       text,
+	}
+	if event.Previous != nil {
+		previous := event.Previous.Balance
+		record.PreviousBalance = &previous
+	}
+	if event.Metadata != nil {
+		record.Symbol = event.Metadata.Symbol
+	}
+	return record
+}
+
+// ensureSchema creates the schema of the notifier, or commits a revision
+// of it matching the records, until it succeeds once
+func (p *PubSubNotifier) ensureSchema(ctx context.Context) error {
+	if p.schema == "" {
+		return nil
+	}
+	p.schemaMutex.Lock()
+	defer p.schemaMutex.Unlock()
+	if p.schemaReady {
+		return nil
+	}
+
+	want := pubSubSchema{Type: "AVRO", Definition: events.AvroSchema}
+	if p.format == "protobuf" {
+		want = pubSubSchema{Type: "PROTOCOL_BUFFER", Definition: events.ProtoSchema}
+	}
+	var current pubSubSchema
+	status, err := p.call(ctx, http.MethodGet, p.schema+"?view=FULL", nil, &current)
+	switch {
+	case status == http.StatusNotFound:
+		slash := strings.LastIndex(p.schema, "/schemas/")
+		path := p.schema[:slash] + "/schemas?schemaId=" + url.QueryEscape(p.schema[slash+len("/schemas/"):])
+		if _, err := p.call(ctx, http.MethodPost, path, want, nil); err != nil {
+			return fmt.Errorf("failed to create schema %s: %w", p.schema, err)
+		}
+		p.log.Info("Created Pub/Sub schema", "schema", p.schema)
+	case err != nil:
+		return fmt.Errorf("failed to get schema %s: %w", p.schema, err)
+	case current.Type != want.Type:
+		return fmt.Errorf("schema %s is of type %s, not %s", p.schema, current.Type, want.Type)
+	case strings.TrimSpace(current.Definition) != strings.TrimSpace(want.Definition):
+		if _, err := p.call(ctx, http.MethodPost, p.schema+":commit", map[string]interface{}{"schema": want}, nil); err != nil {
+			return fmt.Errorf("failed to commit a revision of schema %s: %w", p.schema, err)
+		}
+		p.log.Info("Committed a revision of the Pub/Sub schema", "schema", p.schema)
+	}
+	p.schemaReady = true
+	return nil
+}
+
+// eventAttributes returns the chosen attributes of an event that are set
+func (p *PubSubNotifier) eventAttributes(event monitor.Event) map[string]string {
+	attributes := make(map[string]string, len(p.attributes))
+	for _, name := range p.attributes {
+		var value string
+		switch name {
+		case "wallet":
+			value = event.Account.Owner
+		case "label":
+			value = event.Label
+		case "network":
+			value = event.Network
+		case "domain":
+			value = event.Domain
+		case "tenants":
+			value = strings.Join(event.Tenants, ",")
+		case "account":
+			value = event.Account.Address
+		case "mint":
+			value = event.Account.Mint
+		case "symbol":
+			if event.Metadata != nil {
+				value = event.Metadata.Symbol
+			}
+		case "event_type":
+			value = string(event.Type)
+		case "severity":
+			value = event.Severity
+			if value == "" {
+				value = monitor.SeverityInfo
+			}
+		case "signature":
+			value = event.Account.Signature
+		}
+		if value != "" {
+			attributes[name] = value
+		}
+	}
+	return attributes
+}
+
+// publish calls the publish method of the topic
+func (p *PubSubNotifier) publish(ctx context.Context, messages []pubSubMessage) error {
+	if _, err := p.call(ctx, http.MethodPost, p.topic+":publish", map[string]interface{}{"messages": messages}, nil); err != nil {
+		return fmt.Errorf("failed to publish to Pub/Sub: %w", err)
+	}
+	return nil
+}
+
+// call sends a request with a JSON body, if any, to a path of the v1 API
+// and decodes the response into out, if given. It returns the status of the
+// response.
+func (p *PubSubNotifier) call(ctx context.Context, method, path string, in, out interface{}) (int, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.endpoint+"/v1/"+path, body)
+	if err != nil {
+		return 0, err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if p.tokens != nil {
+		token, err := p.tokens.Token(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get access token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, fmt.Errorf("pub/sub returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,228 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+	"github.com/yourusername/solana-wallet-tracker/pkg/telemetry"
+)
+
+// Retry defaults
+const (
+	DefaultRetryAttempts     = 5
+	DefaultRetryInitialDelay = time.Second
+	DefaultRetryMaxDelay     = time.Minute
+)
+
+// RetryPolicy controls how failed deliveries are retried. The delay doubles
+// after each attempt up to MaxDelay.
+type RetryPolicy struct {
+	// Attempts is the total number of deliveries tried, including the first
+	Attempts     int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+}
+
+// SetRetry retries failed deliveries in the background. Notifications that
+// still fail after every attempt, or are pending a retry when the
+// dispatcher is flushed, are kept in deadLetters for replay; with nil
+// deadLetters they are logged and dropped. Call it before handling events.
+func (d *Dispatcher) SetRetry(policy RetryPolicy, deadLetters store.DeadLetterStore) {
+	if policy.Attempts <= 0 {
+		policy.Attempts = DefaultRetryAttempts
+	}
+	if policy.InitialDelay <= 0 {
+		policy.InitialDelay = DefaultRetryInitialDelay
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = DefaultRetryMaxDelay
+	}
+	d.retry = &policy
+	d.deadLetters = deadLetters
+	d.stopRetries = make(chan struct{})
+}
+
+// retryLater retries a failed delivery in the background
+func (d *Dispatcher) retryLater(index int, events []monitor.Event, err error) {
+	if d.retry == nil {
+		return
+	}
+
+	d.retries.Add(1)
+	go func() {
+		defer d.retries.Done()
+
+		notifier := d.notifiers[index]
+		delay := d.retry.InitialDelay
+		attempts := 1
+		for ; attempts < d.retry.Attempts; attempts++ {
+			select {
+			case <-time.After(delay):
+			case <-d.stopRetries:
+				d.deadLetter(index, events, attempts, err)
+				return
+			}
+
+			if err = Send(notifier, events); err == nil {
+				d.log.Info("Delivered notification after retrying",
+					"notifier", notifier.Name(),
+					"events", len(events),
+					"wallet", events[0].Account.Owner,
+					"attempts", attempts+1,
+				)
+				return
+			}
+
+			delay *= 2
+			if delay > d.retry.MaxDelay {
+				delay = d.retry.MaxDelay
+			}
+		}
+		d.deadLetter(index, events, attempts, err)
+	}()
+}
+
+// stopRetrying dead-letters pending retries and waits for them
+func (d *Dispatcher) stopRetrying() {
+	if d.retry == nil {
+		return
+	}
+
+	d.mutex.Lock()
+	if !d.retriesStopped {
+		d.retriesStopped = true
+		close(d.stopRetries)
+	}
+	d.mutex.Unlock()
+
+	d.retries.Wait()
+}
+
+// deadLetter keeps a notification that couldn't be delivered
+func (d *Dispatcher) deadLetter(index int, events []monitor.Event, attempts int, err error) {
+	attrs := []any{
+		"notifier", d.notifiers[index].Name(),
+		"events", len(events),
+		"wallet", events[0].Account.Owner,
+		"attempts", attempts,
+	}
+	if d.tenant != "" {
+		attrs = append(attrs, "tenant", d.tenant)
+	}
+	if d.deadLetters == nil {
+		d.log.Error("Dropping undeliverable notification", append(attrs, "error", err)...)
+		return
+	}
+
+	data, marshalErr := json.Marshal(events)
+	if marshalErr != nil {
+		d.log.Error("Failed to encode undeliverable notification", append(attrs, "error", marshalErr)...)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+	defer cancel()
+	letter := store.DeadLetter{
+		Notifier:      d.notifiers[index].Name(),
+		NotifierIndex: index,
+		Tenant:        d.tenant,
+		Events:        data,
+		Error:         err.Error(),
+		Attempts:      attempts,
+		FailedAt:      time.Now().UTC(),
+	}
+	if storeErr := d.deadLetters.AddDeadLetter(ctx, letter); storeErr != nil {
+		d.log.Error("Failed to store undeliverable notification", append(attrs, "error", storeErr)...)
+		return
+	}
+	d.log.Warn("Moved undeliverable notification to the dead letter queue", append(attrs, "error", err)...)
+}
+
+// Send delivers events to a notifier once, as one message if there are
+// several and the notifier supports it, otherwise one at a time. The
+// delivery is a span of the trace of the first event; successful ones
+// record the latency from the observation of each event.
+func Send(notifier Notifier, events []monitor.Event) error {
+	return observe(notifier, events, func(ctx context.Context) error {
+		return send(ctx, notifier, events)
+	})
+}
+
+// observe traces a delivery of events to a notifier and records its outcome
+// and latency
+func observe(notifier Notifier, events []monitor.Event, deliver func(ctx context.Context) error) error {
+	ctx, span := telemetry.Start(telemetry.ContextWithSpanContext(context.Background(), events[0].Trace), "notify.deliver", telemetry.KindProducer,
+		telemetry.String("notifier", notifier.Name()),
+		telemetry.Int("events", int64(len(events))),
+	)
+	err := deliver(ctx)
+	span.SetError(err)
+	span.End()
+
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	deliveries.Add(1, telemetry.String("notifier", notifier.Name()), telemetry.String("outcome", outcome))
+	if err == nil {
+		for _, event := range events {
+			if !event.Replayed && !event.Account.LastUpdatedAt.IsZero() {
+				deliveryLatency.Record(float64(time.Since(event.Account.LastUpdatedAt))/float64(time.Millisecond),
+					telemetry.String("notifier", notifier.Name()),
+					telemetry.String("event.type", string(event.Type)),
+				)
+			}
+		}
+	}
+	return err
+}
+
+// Metrics of deliveries, exported while telemetry is enabled
+var (
+	deliveries      = telemetry.NewCounter("tracker.notify.deliveries", "Notification deliveries", "{delivery}")
+	deliveryLatency = telemetry.NewHistogram("tracker.notify.latency", "Time from observing an update to delivering its notification", "ms", telemetry.DurationBounds)
+)
+
+// send delivers events to a notifier
+func send(ctx context.Context, notifier Notifier, events []monitor.Event) error {
+	if batcher, ok := notifier.(BatchNotifier); ok && len(events) > 1 {
+		ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+		defer cancel()
+		return batcher.NotifyBatch(ctx, events)
+	}
+
+	for _, event := range events {
+		ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+		err := notifier.Notify(ctx, event)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Replay resends a dead letter through the notifier at its index. It fails
+// if the notifiers changed so that the index holds another type.
+func Replay(notifiers []Notifier, letter store.DeadLetter) error {
+	if letter.NotifierIndex < 0 || letter.NotifierIndex >= len(notifiers) {
+		return fmt.Errorf("notifier %d (%s) is no longer configured", letter.NotifierIndex, letter.Notifier)
+	}
+	notifier := notifiers[letter.NotifierIndex]
+	if notifier.Name() != letter.Notifier {
+		return fmt.Errorf("notifier %d is now a %s notifier, not %s", letter.NotifierIndex, notifier.Name(), letter.Notifier)
+	}
+
+	var events []monitor.Event
+	if err := json.Unmarshal(letter.Events, &events); err != nil {
+		return fmt.Errorf("failed to decode events: %w", err)
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("dead letter has no events")
+	}
+	return Send(notifier, events)
+}
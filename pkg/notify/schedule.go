@@ -0,0 +1,198 @@
+package notify
+
+import (
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+)
+
+// maxHeldEvents bounds the events a notifier holds during its quiet hours.
+// The oldest are dropped beyond it.
+const maxHeldEvents = 1000
+
+// Schedule is when a notifier only gets critical events
+type Schedule struct {
+	location *time.Location
+	windows  []quietWindow
+}
+
+// quietWindow is a window of quiet hours, as times since midnight
+type quietWindow struct {
+	from time.Duration
+	to   time.Duration
+	// days are the weekdays the window starts on, nil for every day
+	days map[time.Weekday]bool
+}
+
+// NewSchedule builds the schedule of a notifier from its quiet hours and
+// time zone. It is nil without quiet hours.
+func NewSchedule(cfg config.NotifierConfig) (*Schedule, error) {
+	if len(cfg.QuietHours) == 0 {
+		return nil, nil
+	}
+	location, err := config.LoadTimezone(cfg.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Schedule{location: location}
+	for _, windowCfg := range cfg.QuietHours {
+		var window quietWindow
+		if window.from, err = config.ParseTimeOfDay(windowCfg.From); err != nil {
+			return nil, err
+		}
+		if window.to, err = config.ParseTimeOfDay(windowCfg.To); err != nil {
+			return nil, err
+		}
+		for _, name := range windowCfg.Days {
+			day, err := config.ParseWeekday(name)
+			if err != nil {
+				return nil, err
+			}
+			if window.days == nil {
+				window.days = make(map[time.Weekday]bool)
+			}
+			window.days[day] = true
+		}
+		s.windows = append(s.windows, window)
+	}
+	return s, nil
+}
+
+// QuietUntil returns the end of the quiet hours at t, zero outside them
+func (s *Schedule) QuietUntil(t time.Time) time.Time {
+	local := t.In(s.location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.location)
+	yesterday := midnight.AddDate(0, 0, -1)
+	since := local.Sub(midnight)
+
+	var until time.Time
+	for _, window := range s.windows {
+		var end time.Time
+		switch {
+		case window.from < window.to:
+			if window.startsOn(midnight) && since >= window.from && since < window.to {
+				end = at(midnight, window.to)
+			}
+		// Windows past midnight started today or yesterday
+		case since >= window.from && window.startsOn(midnight):
+			end = at(midnight.AddDate(0, 0, 1), window.to)
+		case since < window.to && window.startsOn(yesterday):
+			end = at(midnight, window.to)
+		}
+		if end.After(until) {
+			until = end
+		}
+	}
+	return until
+}
+
+// startsOn reports whether the window starts on the day of midnight
+func (w quietWindow) startsOn(midnight time.Time) bool {
+	return w.days == nil || w.days[midnight.Weekday()]
+}
+
+// at returns the time of day of a day, in its time zone
+func at(midnight time.Time, since time.Duration) time.Time {
+	return time.Date(midnight.Year(), midnight.Month(), midnight.Day(),
+		int(since/time.Hour), int(since%time.Hour/time.Minute), 0, 0, midnight.Location())
+}
+
+// SetSchedules sets the schedule of each notifier, in the order the
+// notifiers were given. Nil schedules deliver at any time.
+func (d *Dispatcher) SetSchedules(schedules []*Schedule) {
+	d.schedules = schedules
+}
+
+// hold keeps the routine events of a notifier in its quiet hours for the
+// digest at their end and returns those to deliver now
+func (d *Dispatcher) hold(index int, events []monitor.Event) []monitor.Event {
+	if index >= len(d.schedules) || d.schedules[index] == nil {
+		return events
+	}
+	now := time.Now()
+	until := d.schedules[index].QuietUntil(now)
+	if until.IsZero() {
+		return events
+	}
+
+	var deliverNow []monitor.Event
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.held == nil {
+		d.held = make(map[int][]monitor.Event)
+	}
+	first := len(d.held[index]) == 0
+	for _, event := range events {
+		if event.Hot || d.isCritical(event) {
+			deliverNow = append(deliverNow, event)
+			continue
+		}
+		d.held[index] = append(d.held[index], event)
+	}
+	if dropped := len(d.held[index]) - maxHeldEvents; dropped > 0 {
+		d.log.Warn("Too many events held for quiet hours, dropping the oldest",
+			"notifier", d.name(index),
+			"dropped", dropped,
+		)
+		d.held[index] = append([]monitor.Event(nil), d.held[index][dropped:]...)
+	}
+	if first && len(d.held[index]) > 0 {
+		time.AfterFunc(until.Sub(now), func() { d.releaseHeld(index) })
+	}
+	return deliverNow
+}
+
+// releaseHeld delivers the digest of a notifier once its quiet hours end,
+// or waits for the end of the quiet hours that follow at once
+func (d *Dispatcher) releaseHeld(index int) {
+	now := time.Now()
+	if until := d.schedules[index].QuietUntil(now); !until.IsZero() {
+		time.AfterFunc(until.Sub(now), func() { d.releaseHeld(index) })
+		return
+	}
+	d.deliverHeld(index)
+}
+
+// deliverHeld delivers the events a notifier held, a message per wallet
+func (d *Dispatcher) deliverHeld(index int) {
+	d.mutex.Lock()
+	held := d.held[index]
+	delete(d.held, index)
+	d.mutex.Unlock()
+	if len(held) == 0 || !d.enabled(index) {
+		return
+	}
+
+	d.log.Info("Delivering the notifications held for quiet hours",
+		"notifier", d.name(index),
+		"events", len(held),
+	)
+	var keys []string
+	byKey := make(map[string][]monitor.Event)
+	for _, event := range held {
+		key := walletKey(event)
+		if _, ok := byKey[key]; !ok {
+			keys = append(keys, key)
+		}
+		byKey[key] = append(byKey[key], event)
+	}
+	for _, key := range keys {
+		d.deliverTo(index, byKey[key])
+	}
+}
+
+// deliverAllHeld delivers the events every notifier held, e.g. on shutdown
+func (d *Dispatcher) deliverAllHeld() {
+	d.mutex.Lock()
+	indexes := make([]int, 0, len(d.held))
+	for index := range d.held {
+		indexes = append(indexes, index)
+	}
+	d.mutex.Unlock()
+
+	for _, index := range indexes {
+		d.deliverHeld(index)
+	}
+}
@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+)
+
+func TestQuietUntil(t *testing.T) {
+	// 2024-12-02 is a Monday
+	day := func(date, hour, minute int) time.Time {
+		return time.Date(2024, 12, date, hour, minute, 0, 0, time.UTC)
+	}
+	nightly := []config.QuietHoursConfig{{From: "22:00", To: "07:00"}}
+
+	tests := []struct {
+		name     string
+		hours    []config.QuietHoursConfig
+		timezone string
+		at       time.Time
+		want     time.Time
+	}{
+		{"before the window", nightly, "UTC", day(2, 21, 59), time.Time{}},
+		{"at the start", nightly, "UTC", day(2, 22, 0), day(3, 7, 0)},
+		{"past midnight", nightly, "UTC", day(3, 3, 0), day(3, 7, 0)},
+		{"at the end", nightly, "UTC", day(3, 7, 0), time.Time{}},
+		{"same day window", []config.QuietHoursConfig{{From: "12:00", To: "13:30"}}, "UTC", day(2, 12, 15), day(2, 13, 30)},
+		{"on its day", []config.QuietHoursConfig{{From: "12:00", To: "13:00", Days: []string{"sat"}}}, "UTC", day(7, 12, 30), day(7, 13, 0)},
+		{"on another day", []config.QuietHoursConfig{{From: "12:00", To: "13:00", Days: []string{"sat"}}}, "UTC", day(2, 12, 30), time.Time{}},
+		{"the morning after its day", []config.QuietHoursConfig{{From: "23:00", To: "02:00", Days: []string{"fri"}}}, "UTC", day(7, 1, 0), day(7, 2, 0)},
+		{"the morning after another day", []config.QuietHoursConfig{{From: "23:00", To: "02:00", Days: []string{"fri"}}}, "UTC", day(8, 1, 0), time.Time{}},
+		{"overlapping windows", append([]config.QuietHoursConfig{{From: "06:00", To: "08:00"}}, nightly...), "UTC", day(3, 6, 30), day(3, 8, 0)},
+		{"in its time zone", nightly, "Asia/Ho_Chi_Minh", day(2, 16, 0), day(3, 0, 0)},
+		{"outside in its time zone", nightly, "Asia/Ho_Chi_Minh", day(2, 14, 0), time.Time{}},
+	}
+	for _, tt := range tests {
+		schedule, err := NewSchedule(config.NotifierConfig{QuietHours: tt.hours, Timezone: tt.timezone})
+		if err != nil {
+			t.Fatalf("%s: NewSchedule: %v", tt.name, err)
+		}
+		if got := schedule.QuietUntil(tt.at); !got.Equal(tt.want) {
+			t.Errorf("%s: QuietUntil(%s) = %s, want %s", tt.name, tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestNewSchedule(t *testing.T) {
+	schedule, err := NewSchedule(config.NotifierConfig{})
+	if schedule != nil || err != nil {
+		t.Errorf("without quiet hours got %v, %v, want no schedule", schedule, err)
+	}
+
+	invalid := []config.NotifierConfig{
+		{QuietHours: []config.QuietHoursConfig{{From: "25:00", To: "07:00"}}},
+		{QuietHours: []config.QuietHoursConfig{{From: "22:00", To: "7"}}},
+		{QuietHours: []config.QuietHoursConfig{{From: "22:00", To: "07:00", Days: []string{"someday"}}}},
+		{QuietHours: []config.QuietHoursConfig{{From: "22:00", To: "07:00"}}, Timezone: "Nowhere/Town"},
+	}
+	for _, cfg := range invalid {
+		if _, err := NewSchedule(cfg); err == nil {
+			t.Errorf("NewSchedule(%+v) succeeded, want an error", cfg)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+)
+
+// SeverityRule gives the events matching its rule a severity
+type SeverityRule struct {
+	Rule
+	Severity string
+}
+
+// NewSeverityRule builds a severity rule from its configuration
+func NewSeverityRule(cfg config.SeverityRuleConfig) SeverityRule {
+	return SeverityRule{Rule: NewRule(cfg.PriorityRuleConfig), Severity: cfg.Severity}
+}
+
+// SeverityStage assigns severities in the route phase of the monitor
+// pipeline, so every handler sees them. An event takes the highest severity
+// of the rules it matches; rules never lower a severity set earlier, such as
+// by the anomaly detector.
+func SeverityStage(rules []SeverityRule) monitor.Middleware {
+	return func(next monitor.Next) monitor.Next {
+		return func(ctx context.Context, event monitor.Event) {
+			for _, rule := range rules {
+				if monitor.SeverityRank(rule.Severity) > monitor.SeverityRank(event.Severity) && rule.Matches(event) {
+					event.Severity = rule.Severity
+				}
+			}
+			next(ctx, event)
+		}
+	}
+}
+
+// SetMinSeverities sets the lowest severity each notifier receives, in the
+// order the notifiers were given. Empty severities receive every event.
+func (d *Dispatcher) SetMinSeverities(severities []string) {
+	d.minSeverities = severities
+}
+
+// accepts returns the events a notifier receives
+func (d *Dispatcher) accepts(index int, events []monitor.Event) []monitor.Event {
+	if index >= len(d.minSeverities) || d.minSeverities[index] == "" {
+		return events
+	}
+	min := monitor.SeverityRank(d.minSeverities[index])
+	var accepted []monitor.Event
+	for _, event := range events {
+		if monitor.SeverityRank(event.Severity) >= min {
+			accepted = append(accepted, event)
+		}
+	}
+	return accepted
+}
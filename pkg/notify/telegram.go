@@ -0,0 +1,134 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+)
+
+// telegramAPI is the base URL of the Telegram Bot API
+const telegramAPI = "https://api.telegram.org"
+
+// TelegramNotifier sends events to a Telegram chat
+type TelegramNotifier struct {
+	botToken   string
+	chatID     string
+	locale     string
+	history    store.Store
+	httpClient *http.Client
+	log        logging.Logger
+}
+
+// NewTelegramNotifier creates a new Telegram notifier, logging through log or
+// the default logger when nil. When history is not nil, messages are sent as
+// a 24h balance chart with the text as caption.
+func NewTelegramNotifier(botToken, chatID, locale string, history store.Store, log logging.Logger) *TelegramNotifier {
+	if log == nil {
+		log = logging.Default()
+	}
+	return &TelegramNotifier{
+		botToken:   botToken,
+		chatID:     chatID,
+		locale:     locale,
+		history:    history,
+		httpClient: &http.Client{},
+		log:        log,
+	}
+}
+
+// Name returns the notifier name
+func (t *TelegramNotifier) Name() string {
+	return "telegram"
+}
+
+// Notify sends the event as a Telegram message
+func (t *TelegramNotifier) Notify(ctx context.Context, event monitor.Event) error {
+	text := FormatMessage(t.locale, event)
+
+	if t.history != nil {
+		image, err := renderBalanceChart(ctx, t.history, event.Account)
+		if err == nil {
+			return t.sendPhoto(ctx, text, image)
+		}
+		t.log.Debug("Sending telegram message without chart", "error", err)
+	}
+
+	form := url.Values{}
+	form.Set("chat_id", t.chatID)
+	form.Set("text", text)
+
+	return t.send(ctx, "sendMessage", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+}
+
+// NotifyBatch sends several events of one wallet as a single message
+func (t *TelegramNotifier) NotifyBatch(ctx context.Context, events []monitor.Event) error {
+	form := url.Values{}
+	form.Set("chat_id", t.chatID)
+	form.Set("text", FormatBatchMessage(t.locale, events))
+
+	return t.send(ctx, "sendMessage", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+}
+
+// NotifyDigest sends the events of a digest window as a single summary
+func (t *TelegramNotifier) NotifyDigest(ctx context.Context, digest Digest) error {
+	form := url.Values{}
+	form.Set("chat_id", t.chatID)
+	form.Set("text", FormatDigestMessage(t.locale, digest))
+
+	return t.send(ctx, "sendMessage", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+}
+
+// sendPhoto sends a PNG image with the text as caption
+func (t *TelegramNotifier) sendPhoto(ctx context.Context, caption string, image []byte) error {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	if err := writer.WriteField("chat_id", t.chatID); err != nil {
+		return err
+	}
+	if err := writer.WriteField("caption", caption); err != nil {
+		return err
+	}
+	part, err := writer.CreateFormFile("photo", "balance.png")
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(image); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return t.send(ctx, "sendPhoto", writer.FormDataContentType(), &body)
+}
+
+// send calls a Telegram Bot API method
+func (t *TelegramNotifier) send(ctx context.Context, method, contentType string, body io.Reader) error {
+	endpoint := fmt.Sprintf("%s/bot%s/%s", telegramAPI, t.botToken, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send telegram message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("telegram returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package notify
+
+import "errors"
+
+// ErrUnknownNotifier is returned when turning on or off a notifier that no
+// dispatcher has
+var ErrUnknownNotifier = errors.New("unknown notifier")
+
+// NotifierState is whether a notifier of a dispatcher receives events
+type NotifierState struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Tenant  string `json:"tenant,omitempty"`
+	Enabled bool   `json:"enabled"`
+	// Held counts the events held for the end of the quiet hours
+	Held int `json:"held,omitempty"`
+}
+
+// SetNames names the notifiers, in the order they were given, so they can
+// be turned on and off by name. Notifiers without a name go by their type.
+func (d *Dispatcher) SetNames(names []string) {
+	d.names = names
+}
+
+// SetNotifiersEnabled turns each notifier on or off, in the order the
+// notifiers were given
+func (d *Dispatcher) SetNotifiersEnabled(enabled []bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.muted = make(map[int]bool)
+	for index, on := range enabled {
+		if !on {
+			d.muted[index] = true
+		}
+	}
+}
+
+// SetNotifierEnabled turns the notifiers of a name on or off and reports
+// whether the dispatcher has any. Events reaching a notifier while it is
+// off are dropped; deliveries already waiting for a retry go ahead.
+func (d *Dispatcher) SetNotifierEnabled(name string, enabled bool) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	found := false
+	for index := range d.notifiers {
+		if d.name(index) != name {
+			continue
+		}
+		found = true
+		if enabled {
+			delete(d.muted, index)
+			continue
+		}
+		if d.muted == nil {
+			d.muted = make(map[int]bool)
+		}
+		d.muted[index] = true
+	}
+	return found
+}
+
+// Notifiers returns the notifiers of the dispatcher in order
+func (d *Dispatcher) Notifiers() []NotifierState {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	states := make([]NotifierState, 0, len(d.notifiers))
+	for index, notifier := range d.notifiers {
+		states = append(states, NotifierState{
+			Name:    d.name(index),
+			Type:    notifier.Name(),
+			Tenant:  d.tenant,
+			Enabled: !d.muted[index],
+			Held:    len(d.held[index]),
+		})
+	}
+	return states
+}
+
+// name returns the name of a notifier
+func (d *Dispatcher) name(index int) string {
+	if index < len(d.names) && d.names[index] != "" {
+		return d.names[index]
+	}
+	return d.notifiers[index].Name()
+}
+
+// enabled reports whether a notifier is on
+func (d *Dispatcher) enabled(index int) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return !d.muted[index]
+}
@@ -0,0 +1,132 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/events"
+	"github.com/yourusername/solana-wallet-tracker/pkg/flow"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/telemetry"
+)
+
+// WebhookNotifier posts events as JSON to an HTTP endpoint. Requests are
+// signed when a secret is set; receivers verify them with pkg/events.
+type WebhookNotifier struct {
+	url        string
+	locale     string
+	secret     string
+	httpClient *http.Client
+	// cloudEventSource is the source of CloudEvents, empty to post plain
+	// payloads
+	cloudEventSource string
+}
+
+// webhookPayload is the JSON body sent to webhook endpoints. Batches carry
+// all events in Events and leave Event empty; watchdog reports carry Health
+// only. It must decode as an events.Payload.
+type webhookPayload struct {
+	Text   string          `json:"text"`
+	Locale string          `json:"locale"`
+	Event  *monitor.Event  `json:"event,omitempty"`
+	Events []monitor.Event `json:"events,omitempty"`
+	Health *Health         `json:"health,omitempty"`
+	Digest *webhookDigest  `json:"digest,omitempty"`
+	// FlowReport is set on scheduled net flow reports
+	FlowReport *flow.Report `json:"flow_report,omitempty"`
+}
+
+// webhookDigest sums up the events of a digest payload
+type webhookDigest struct {
+	Window  string      `json:"window"`
+	Changes []NetChange `json:"changes"`
+}
+
+// NewWebhookNotifier creates a new webhook notifier. An empty secret sends
+// unsigned requests.
+func NewWebhookNotifier(url, locale, secret string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:        url,
+		locale:     locale,
+		secret:     secret,
+		httpClient: &http.Client{},
+	}
+}
+
+// SetCloudEvents posts payloads in CloudEvents envelopes of the source, or
+// plain ones when it is empty
+func (w *WebhookNotifier) SetCloudEvents(source string) {
+	w.cloudEventSource = source
+}
+
+// Name returns the notifier name
+func (w *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Notify posts the event to the webhook URL
+func (w *WebhookNotifier) Notify(ctx context.Context, event monitor.Event) error {
+	return w.post(ctx, webhookPayload{
+		Text:   FormatMessage(w.locale, event),
+		Locale: w.locale,
+		Event:  &event,
+	})
+}
+
+// NotifyBatch posts several events of one wallet as a single payload
+func (w *WebhookNotifier) NotifyBatch(ctx context.Context, events []monitor.Event) error {
+	return w.post(ctx, webhookPayload{
+		Text:   FormatBatchMessage(w.locale, events),
+		Locale: w.locale,
+		Events: events,
+	})
+}
+
+// NotifyDigest posts the events of a digest window as a single payload with
+// their net changes
+func (w *WebhookNotifier) NotifyDigest(ctx context.Context, digest Digest) error {
+	changes := digest.NetChanges()
+	if changes == nil {
+		changes = []NetChange{}
+	}
+	return w.post(ctx, webhookPayload{
+		Text:   FormatDigestMessage(w.locale, digest),
+		Locale: w.locale,
+		Events: digest.Events,
+		Digest: &webhookDigest{Window: digest.Window.String(), Changes: changes},
+	})
+}
+
+// post sends a payload to the webhook URL
+func (w *WebhookNotifier) post(ctx context.Context, payload webhookPayload) error {
+	body, contentType, err := marshalPayload(w.cloudEventSource, payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if w.secret != "" {
+		events.SetHeaders(req.Header, w.secret, body)
+	}
+	// Receivers that trace continue the trace of the delivery
+	if span := telemetry.SpanContextFromContext(ctx); span.IsValid() {
+		req.Header.Set("traceparent", telemetry.Traceparent(span))
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
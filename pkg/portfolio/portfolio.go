@@ -0,0 +1,191 @@
+package portfolio
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/price"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// solDecimals is the number of decimals of native SOL
+const solDecimals = 9
+
+// Holding is a valued token position of a wallet
+type Holding struct {
+	Mint     string `json:"mint"`
+	Symbol   string `json:"symbol,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Balance  uint64 `json:"balance"`
+	Decimals uint8  `json:"decimals"`
+	// Amount is the balance in UI units
+	Amount float64 `json:"amount"`
+	// PriceUSD is nil when no price is known for the mint
+	PriceUSD *float64 `json:"price_usd,omitempty"`
+	ValueUSD float64  `json:"value_usd"`
+}
+
+// Wallet is the valued holdings of one wallet
+type Wallet struct {
+	Owner    string    `json:"owner"`
+	Holdings []Holding `json:"holdings"`
+	TotalUSD float64   `json:"total_usd"`
+}
+
+// Group is the combined holdings of the wallets of a group
+type Group struct {
+	Name     string    `json:"name"`
+	Wallets  []string  `json:"wallets"`
+	Holdings []Holding `json:"holdings"`
+	TotalUSD float64   `json:"total_usd"`
+}
+
+// Portfolio is the valuation of a set of wallets
+type Portfolio struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Wallets     []Wallet  `json:"wallets"`
+	// Groups are filled by Rollup
+	Groups   []Group `json:"groups,omitempty"`
+	TotalUSD float64 `json:"total_usd"`
+}
+
+// Build fetches the SOL and token holdings of each wallet and values them
+// with current USD prices. Token names come from on-chain metadata when it
+// exists.
+func Build(ctx context.Context, client *solana.Client, prices *price.Client, wallets []string) (*Portfolio, error) {
+	portfolio := &Portfolio{GeneratedAt: time.Now()}
+	mints := map[string]bool{solana.NativeMint: true}
+
+	for _, owner := range wallets {
+		lamports, err := client.GetSOLBalance(ctx, owner)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get SOL balance of %s: %w", owner, err)
+		}
+
+		wallet := Wallet{Owner: owner}
+		if lamports > 0 {
+			wallet.Holdings = append(wallet.Holdings, Holding{
+				Mint:     solana.NativeMint,
+				Symbol:   "SOL",
+				Name:     "Solana",
+				Balance:  lamports,
+				Decimals: solDecimals,
+			})
+		}
+
+		accounts, err := client.GetTokenAccounts(ctx, owner)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get token accounts of %s: %w", owner, err)
+		}
+		for _, account := range accounts {
+			if account.Balance == 0 {
+				continue
+			}
+			wallet.Holdings = append(wallet.Holdings, Holding{
+				Mint:     account.Mint,
+				Balance:  account.Balance,
+				Decimals: account.Decimals,
+			})
+			mints[account.Mint] = true
+		}
+
+		portfolio.Wallets = append(portfolio.Wallets, wallet)
+	}
+
+	mintList := make([]string, 0, len(mints))
+	for mint := range mints {
+		mintList = append(mintList, mint)
+	}
+	quotes, err := prices.GetPrices(ctx, mintList)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := make(map[string]*solana.TokenMetadata)
+	for i := range portfolio.Wallets {
+		wallet := &portfolio.Wallets[i]
+		for j := range wallet.Holdings {
+			holding := &wallet.Holdings[j]
+			holding.Amount = float64(holding.Balance) / math.Pow10(int(holding.Decimals))
+
+			if quote, ok := quotes[holding.Mint]; ok {
+				holding.PriceUSD = &quote
+				holding.ValueUSD = holding.Amount * quote
+			}
+
+			if holding.Symbol == "" {
+				meta, seen := metadata[holding.Mint]
+				if !seen {
+					meta, err = client.GetTokenMetadata(ctx, holding.Mint)
+					if err != nil {
+						client.Log().Debug("No token metadata", "mint", holding.Mint, "error", err)
+					}
+					metadata[holding.Mint] = meta
+				}
+				if meta != nil {
+					holding.Symbol = meta.Symbol
+					holding.Name = meta.Name
+				}
+			}
+
+			wallet.TotalUSD += holding.ValueUSD
+		}
+
+		sort.SliceStable(wallet.Holdings, func(a, b int) bool {
+			return wallet.Holdings[a].ValueUSD > wallet.Holdings[b].ValueUSD
+		})
+		portfolio.TotalUSD += wallet.TotalUSD
+	}
+
+	return portfolio, nil
+}
+
+// Rollup combines the holdings of the wallets of each group, given as the
+// wallets by group name, into Groups. Wallets missing from the portfolio
+// are left out of the totals.
+func (p *Portfolio) Rollup(groups map[string][]string) {
+	wallets := make(map[string]*Wallet, len(p.Wallets))
+	for i := range p.Wallets {
+		wallets[p.Wallets[i].Owner] = &p.Wallets[i]
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	p.Groups = nil
+	for _, name := range names {
+		group := Group{Name: name, Wallets: []string{}, Holdings: []Holding{}}
+		byMint := make(map[string]int)
+		for _, owner := range groups[name] {
+			wallet, ok := wallets[owner]
+			if !ok {
+				continue
+			}
+			group.Wallets = append(group.Wallets, owner)
+			for _, holding := range wallet.Holdings {
+				i, seen := byMint[holding.Mint]
+				if !seen {
+					byMint[holding.Mint] = len(group.Holdings)
+					group.Holdings = append(group.Holdings, holding)
+					continue
+				}
+				total := &group.Holdings[i]
+				total.Balance += holding.Balance
+				total.Amount = float64(total.Balance) / math.Pow10(int(total.Decimals))
+				total.ValueUSD += holding.ValueUSD
+			}
+			group.TotalUSD += wallet.TotalUSD
+		}
+
+		sort.SliceStable(group.Holdings, func(a, b int) bool {
+			return group.Holdings[a].ValueUSD > group.Holdings[b].ValueUSD
+		})
+		p.Groups = append(p.Groups, group)
+	}
+}
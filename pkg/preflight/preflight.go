@@ -0,0 +1,144 @@
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// DefaultMaxLatency is how long an endpoint may take to respond when no
+// threshold is configured
+const DefaultMaxLatency = 5 * time.Second
+
+// Failure is a check that didn't pass, with what to do about it
+type Failure struct {
+	Check  string
+	Target string
+	Reason string
+}
+
+// Report lists every failed preflight check
+type Report struct {
+	Failures []Failure
+}
+
+// Error lists the failures one per line
+func (r *Report) Error() string {
+	lines := make([]string, 0, len(r.Failures)+1)
+	lines = append(lines, fmt.Sprintf("preflight checks failed (%d problems):", len(r.Failures)))
+	for _, failure := range r.Failures {
+		lines = append(lines, fmt.Sprintf("  %s %s: %s", failure.Check, failure.Target, failure.Reason))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fail records a failed check
+func (r *Report) fail(check, target, format string, args ...interface{}) {
+	r.Failures = append(r.Failures, Failure{Check: check, Target: target, Reason: fmt.Sprintf(format, args...)})
+}
+
+// Run checks the configuration against the chain before monitoring starts:
+// both endpoints respond within maxLatency, wallets are system accounts and
+// tokens and watched mints are SPL mints. It returns a *Report listing every
+// failure, or nil.
+func Run(ctx context.Context, client *solana.Client, cfg *config.Config, maxLatency time.Duration) error {
+	if maxLatency <= 0 {
+		maxLatency = DefaultMaxLatency
+	}
+	report := &Report{}
+
+	if !checkRPC(ctx, client, cfg.RPCEndpoint, maxLatency, report) {
+		// Account checks would only repeat the endpoint failure
+		return report
+	}
+	checkWebSocket(ctx, client, cfg.WSEndpoint, maxLatency, report)
+
+	mints := append([]string(nil), cfg.Tokens...)
+	for _, watch := range cfg.MintWatches {
+		mints = append(mints, watch.Mint)
+	}
+	checkAccounts(ctx, client, cfg.Wallets, mints, report)
+
+	if len(report.Failures) > 0 {
+		return report
+	}
+	return nil
+}
+
+// checkRPC reports whether the RPC endpoint is healthy and fast enough
+func checkRPC(ctx context.Context, client *solana.Client, endpoint string, maxLatency time.Duration, report *Report) bool {
+	ctx, cancel := context.WithTimeout(ctx, maxLatency)
+	defer cancel()
+
+	start := time.Now()
+	if err := client.Ping(ctx); err != nil {
+		if ctx.Err() != nil {
+			report.fail("rpc_endpoint", endpoint, "no response within %s; check the URL or raise preflight.max_latency", maxLatency)
+		} else {
+			report.fail("rpc_endpoint", endpoint, "unhealthy: %v", err)
+		}
+		return false
+	}
+	if elapsed := time.Since(start); elapsed > maxLatency {
+		report.fail("rpc_endpoint", endpoint, "responded in %s, slower than %s", elapsed.Round(time.Millisecond), maxLatency)
+	}
+	return true
+}
+
+// checkWebSocket checks that the WebSocket endpoint delivers notifications
+func checkWebSocket(ctx context.Context, client *solana.Client, endpoint string, maxLatency time.Duration, report *Report) {
+	// Slots are produced every ~400ms, so allow for one on top of the latency
+	ctx, cancel := context.WithTimeout(ctx, maxLatency+time.Second)
+	defer cancel()
+
+	if _, err := client.WaitForSlot(ctx); err != nil {
+		if ctx.Err() != nil {
+			report.fail("ws_endpoint", endpoint, "no slot notification within %s; the provider may not support subscriptions", maxLatency+time.Second)
+		} else {
+			report.fail("ws_endpoint", endpoint, "subscriptions fail: %v", err)
+		}
+	}
+}
+
+// checkAccounts checks that wallets and mints exist and are owned by the
+// expected programs
+func checkAccounts(ctx context.Context, client *solana.Client, wallets, mints []string, report *Report) {
+	addresses := append(append([]string(nil), wallets...), mints...)
+	if len(addresses) == 0 {
+		return
+	}
+
+	accounts, err := client.GetAccountSummaries(ctx, addresses)
+	if err != nil {
+		report.fail("accounts", "", "lookup failed: %v", err)
+		return
+	}
+
+	for _, wallet := range wallets {
+		account, ok := accounts[wallet]
+		switch {
+		case !ok:
+			report.fail("wallet", wallet, "does not exist on chain; check the address and cluster")
+		case account.IsMint():
+			report.fail("wallet", wallet, "is a token mint; list it under tokens instead")
+		case account.Owner == solana.TokenProgramID || account.Owner == solana.Token2022ProgramID:
+			report.fail("wallet", wallet, "is a token account; configure the wallet that owns it")
+		case account.Owner != solana.SystemProgramID:
+			report.fail("wallet", wallet, "is owned by program %s, not a wallet", account.Owner)
+		}
+	}
+
+	for _, mint := range mints {
+		account, ok := accounts[mint]
+		switch {
+		case !ok:
+			report.fail("mint", mint, "does not exist on chain; check the address and cluster")
+		case !account.IsMint():
+			report.fail("mint", mint, "is not an SPL token mint (owned by %s)", account.Owner)
+		}
+	}
+}
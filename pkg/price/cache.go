@@ -0,0 +1,69 @@
+package price
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+)
+
+// DefaultCacheTTL is how long cached prices are used when no TTL is given
+const DefaultCacheTTL = 5 * time.Minute
+
+// cachedPrice is a price and when it was fetched. Mints without a price are
+// cached too so they aren't requested on every lookup.
+type cachedPrice struct {
+	price     float64
+	known     bool
+	fetchedAt time.Time
+}
+
+// Cache serves prices of single mints, refetching them after the TTL
+type Cache struct {
+	client *Client
+	ttl    time.Duration
+	prices map[string]cachedPrice
+	mutex  sync.Mutex
+	log    logging.Logger
+}
+
+// NewCache creates a price cache logging through log, or the default logger
+// when nil. A zero ttl uses DefaultCacheTTL.
+func NewCache(client *Client, ttl time.Duration, log logging.Logger) *Cache {
+	if ttl == 0 {
+		ttl = DefaultCacheTTL
+	}
+	if log == nil {
+		log = logging.Default()
+	}
+	return &Cache{
+		client: client,
+		ttl:    ttl,
+		prices: make(map[string]cachedPrice),
+		log:    log,
+	}
+}
+
+// Price returns the USD price of a mint and whether it is known
+func (c *Cache) Price(ctx context.Context, mint string) (float64, bool) {
+	c.mutex.Lock()
+	cached, ok := c.prices[mint]
+	c.mutex.Unlock()
+	if ok && time.Since(cached.fetchedAt) < c.ttl {
+		return cached.price, cached.known
+	}
+
+	prices, err := c.client.GetPrices(ctx, []string{mint})
+	if err != nil {
+		c.log.Debug("Failed to fetch price", "mint", mint, "error", err)
+		// Keep using a stale price rather than none
+		return cached.price, cached.known
+	}
+
+	price, known := prices[mint]
+	c.mutex.Lock()
+	c.prices[mint] = cachedPrice{price: price, known: known, fetchedAt: time.Now()}
+	c.mutex.Unlock()
+	return price, known
+}
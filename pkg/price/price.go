@@ -0,0 +1,92 @@
+package price
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultEndpoint is the Jupiter price API
+const DefaultEndpoint = "https://api.jup.ag/price/v2"
+
+// batchSize is the maximum number of mints per price request
+const batchSize = 100
+
+// Client fetches USD token prices from a Jupiter compatible price API
+type Client struct {
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewClient creates a price client. An empty endpoint uses DefaultEndpoint.
+func NewClient(endpoint string) *Client {
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	return &Client{
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// GetPrices returns the USD price of each mint. Mints without a price are
+// left out of the result.
+func (c *Client) GetPrices(ctx context.Context, mints []string) (map[string]float64, error) {
+	prices := make(map[string]float64, len(mints))
+	for start := 0; start < len(mints); start += batchSize {
+		end := start + batchSize
+		if end > len(mints) {
+			end = len(mints)
+		}
+		if err := c.fetch(ctx, mints[start:end], prices); err != nil {
+			return nil, err
+		}
+	}
+	return prices, nil
+}
+
+// fetch requests the prices of a batch of mints into prices
+func (c *Client) fetch(ctx context.Context, mints []string, prices map[string]float64) error {
+	query := url.Values{"ids": {strings.Join(mints, ",")}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch prices: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("price API returned status %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Data map[string]*struct {
+			Price string `json:"price"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("failed to decode price response: %w", err)
+	}
+
+	for mint, entry := range response.Data {
+		if entry == nil {
+			continue
+		}
+		value, err := strconv.ParseFloat(entry.Price, 64)
+		if err != nil {
+			continue
+		}
+		prices[mint] = value
+	}
+
+	return nil
+}
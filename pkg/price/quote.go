@@ -0,0 +1,83 @@
+package price
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DefaultQuoteEndpoint is the Jupiter swap quote API
+const DefaultQuoteEndpoint = "https://quote-api.jup.ag/v6/quote"
+
+// USDCMint is the USDC mint, which liquidity is probed against
+const USDCMint = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+
+// ErrNoRoute is returned when no swap route exists between two mints
+var ErrNoRoute = errors.New("no swap route")
+
+// QuoteClient fetches swap quotes from a Jupiter compatible quote API
+type QuoteClient struct {
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewQuoteClient creates a quote client. An empty endpoint uses
+// DefaultQuoteEndpoint.
+func NewQuoteClient(endpoint string) *QuoteClient {
+	if endpoint == "" {
+		endpoint = DefaultQuoteEndpoint
+	}
+	return &QuoteClient{
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// PriceImpact returns the price impact, in percent, of swapping amount base
+// units of inputMint into outputMint. Thin markets have a high impact; it
+// returns ErrNoRoute for mints that can't be traded at all.
+func (c *QuoteClient) PriceImpact(ctx context.Context, inputMint, outputMint string, amount uint64) (float64, error) {
+	query := url.Values{
+		"inputMint":   {inputMint},
+		"outputMint":  {outputMint},
+		"amount":      {strconv.FormatUint(amount, 10)},
+		"slippageBps": {"50"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch quote: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		PriceImpactPct string `json:"priceImpactPct"`
+		ErrorCode      string `json:"errorCode"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil && resp.StatusCode < 300 {
+		return 0, fmt.Errorf("failed to decode quote response: %w", err)
+	}
+
+	switch {
+	case response.ErrorCode == "COULD_NOT_FIND_ANY_ROUTE" || response.ErrorCode == "TOKEN_NOT_TRADABLE":
+		return 0, ErrNoRoute
+	case resp.StatusCode >= 300:
+		return 0, fmt.Errorf("quote API returned status %d", resp.StatusCode)
+	}
+
+	// The API reports the impact as a fraction
+	impact, err := strconv.ParseFloat(response.PriceImpactPct, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid price impact: %q", response.PriceImpactPct)
+	}
+	return impact * 100, nil
+}
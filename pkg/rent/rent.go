@@ -0,0 +1,94 @@
+package rent
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/store"
+)
+
+// Periods for Summarize
+const (
+	ByDay   = "day"
+	ByMonth = "month"
+	// ByTotal sums all entries of a wallet
+	ByTotal = "total"
+)
+
+// periodLayouts format the period of an entry
+var periodLayouts = map[string]string{
+	ByDay:   "2006-01-02",
+	ByMonth: "2006-01",
+	ByTotal: "",
+}
+
+// Summary is the rent a wallet paid and reclaimed in a period. Amounts are
+// in lamports.
+type Summary struct {
+	Wallet string `json:"wallet"`
+	// Period is the UTC day or month, empty for totals
+	Period    string `json:"period,omitempty"`
+	Paid      uint64 `json:"paid"`
+	Reclaimed uint64 `json:"reclaimed"`
+	// Net is the rent still locked in accounts, paid minus reclaimed
+	Net int64 `json:"net"`
+	// Created and Closed count the accounts behind Paid and Reclaimed
+	Created int `json:"created"`
+	Closed  int `json:"closed"`
+}
+
+// Summarize groups entries by wallet and period, ordered by wallet and then
+// period
+func Summarize(entries []store.RentEntry, by string) ([]Summary, error) {
+	layout, ok := periodLayouts[by]
+	if !ok {
+		return nil, fmt.Errorf("unknown period %q: use %s, %s or %s", by, ByDay, ByMonth, ByTotal)
+	}
+
+	summaries := make(map[[2]string]*Summary)
+	for _, entry := range entries {
+		period := ""
+		if layout != "" {
+			period = entry.At.UTC().Format(layout)
+		}
+		key := [2]string{entry.Wallet, period}
+		summary, ok := summaries[key]
+		if !ok {
+			summary = &Summary{Wallet: entry.Wallet, Period: period}
+			summaries[key] = summary
+		}
+
+		if entry.Reclaimed {
+			summary.Reclaimed += entry.Lamports
+			summary.Net -= int64(entry.Lamports)
+			summary.Closed++
+		} else {
+			summary.Paid += entry.Lamports
+			summary.Net += int64(entry.Lamports)
+			summary.Created++
+		}
+	}
+
+	result := make([]Summary, 0, len(summaries))
+	for _, summary := range summaries {
+		result = append(result, *summary)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Wallet != result[j].Wallet {
+			return result[i].Wallet < result[j].Wallet
+		}
+		return result[i].Period < result[j].Period
+	})
+	return result, nil
+}
+
+// ForWallet returns the entries of one wallet
+func ForWallet(entries []store.RentEntry, wallet string) []store.RentEntry {
+	var result []store.RentEntry
+	for _, entry := range entries {
+		if entry.Wallet == wallet {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
@@ -0,0 +1,289 @@
+package risk
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// Signal identifies a kind of risky wallet behaviour
+type Signal string
+
+// Risk signals
+const (
+	// SignalDelegate is a token account with a delegate that can move its funds
+	SignalDelegate Signal = "delegate"
+	// SignalFrozenAccount is a token account frozen by its mint
+	SignalFrozenAccount Signal = "frozen_account"
+	// SignalExchangeOutflow is a transfer to a known exchange address
+	SignalExchangeOutflow Signal = "exchange_outflow"
+	// SignalDormantActivity is an outgoing transfer from a long dormant wallet
+	SignalDormantActivity Signal = "dormant_activity"
+)
+
+// weights are the points each finding adds to a wallet score
+var weights = map[Signal]int{
+	SignalDelegate:        20,
+	SignalFrozenAccount:   15,
+	SignalExchangeOutflow: 25,
+	SignalDormantActivity: 30,
+}
+
+// MaxScore is the highest possible risk score
+const MaxScore = 100
+
+// Defaults for Options
+const (
+	DefaultDormantAfter = 90 * 24 * time.Hour
+	DefaultWindow       = 7 * 24 * time.Hour
+)
+
+// Options configures a Scorer
+type Options struct {
+	// Exchanges are deposit addresses of exchanges; transfers to them count
+	// as exchange outflows
+	Exchanges []string
+	// DormantAfter is the inactivity after which a wallet counts as dormant
+	DormantAfter time.Duration
+	// Window is how long exchange outflows and dormant activity affect the score
+	Window time.Duration
+}
+
+// Finding is a risk signal observed for a wallet
+type Finding struct {
+	Signal     Signal    `json:"signal"`
+	Points     int       `json:"points"`
+	Account    string    `json:"account,omitempty"`
+	Mint       string    `json:"mint,omitempty"`
+	Detail     string    `json:"detail,omitempty"`
+	ObservedAt time.Time `json:"observed_at"`
+}
+
+// Score is the aggregated risk of a wallet, from 0 to MaxScore
+type Score struct {
+	Wallet   string    `json:"wallet"`
+	Score    int       `json:"score"`
+	Findings []Finding `json:"findings,omitempty"`
+}
+
+// Scorer aggregates risk signals per wallet. Delegates and frozen accounts
+// are read from the current account state; outflows and dormant activity
+// are observed from balance changes and expire after the window.
+type Scorer struct {
+	client     *solana.Client
+	options    Options
+	exchanges  map[string]bool
+	state      func() map[string]solana.TokenAccountInfo
+	lastActive map[string]time.Time
+	findings   map[string][]Finding
+	mutex      sync.Mutex
+	log        logging.Logger
+}
+
+// NewScorer creates a scorer logging through log, or the default logger when
+// nil. state returns the current token accounts of the tracked wallets,
+// typically Monitor.GetCurrentState.
+func NewScorer(client *solana.Client, options Options, state func() map[string]solana.TokenAccountInfo, log logging.Logger) *Scorer {
+	if log == nil {
+		log = logging.Default()
+	}
+	if options.DormantAfter == 0 {
+		options.DormantAfter = DefaultDormantAfter
+	}
+	if options.Window == 0 {
+		options.Window = DefaultWindow
+	}
+
+	exchanges := make(map[string]bool, len(options.Exchanges))
+	for _, address := range options.Exchanges {
+		exchanges[address] = true
+	}
+
+	return &Scorer{
+		client:     client,
+		options:    options,
+		exchanges:  exchanges,
+		state:      state,
+		lastActive: make(map[string]time.Time),
+		findings:   make(map[string][]Finding),
+		log:        log,
+	}
+}
+
+// Seed records the last on-chain activity of each wallet so the first
+// outgoing transfer can be checked for dormancy
+func (s *Scorer) Seed(ctx context.Context, wallets []string) {
+	for _, wallet := range wallets {
+		signatures, err := s.client.GetRecentSignatures(ctx, wallet, 1)
+		if err != nil {
+			s.log.Warn("Failed to load last activity", "wallet", wallet, "error", err)
+			continue
+		}
+		if len(signatures) == 0 || signatures[0].BlockTime.IsZero() {
+			continue
+		}
+
+		s.mutex.Lock()
+		s.lastActive[wallet] = signatures[0].BlockTime
+		s.mutex.Unlock()
+	}
+}
+
+// Observe checks a token balance change for exchange outflows and dormant
+// wallet activity. transfer may be nil when the causing transaction is unknown.
+func (s *Scorer) Observe(account solana.TokenAccountInfo, previous *solana.TokenAccountInfo, transfer *solana.Transfer) {
+	if previous == nil || account.Balance >= previous.Balance {
+		return
+	}
+
+	now := time.Now()
+
+	s.mutex.Lock()
+	last, known := s.lastActive[account.Owner]
+	s.lastActive[account.Owner] = now
+	if known && now.Sub(last) >= s.options.DormantAfter {
+		s.addFinding(account.Owner, Finding{
+			Signal:     SignalDormantActivity,
+			Account:    account.Address,
+			Mint:       account.Mint,
+			Detail:     "inactive since " + last.Format(time.RFC3339),
+			ObservedAt: now,
+		})
+	}
+	s.mutex.Unlock()
+
+	if transfer == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, counterparty := range transfer.Counterparties {
+		if s.exchanges[counterparty.Owner] {
+			s.addFinding(account.Owner, Finding{
+				Signal:     SignalExchangeOutflow,
+				Account:    account.Address,
+				Mint:       account.Mint,
+				Detail:     counterparty.Owner,
+				ObservedAt: now,
+			})
+			return
+		}
+	}
+}
+
+// addFinding records a finding. Callers must hold the lock.
+func (s *Scorer) addFinding(wallet string, finding Finding) {
+	finding.Points = weights[finding.Signal]
+	s.findings[wallet] = append(s.findings[wallet], finding)
+
+	s.log.Warn("Wallet risk signal",
+		"wallet", wallet,
+		"signal", finding.Signal,
+		"detail", finding.Detail,
+	)
+}
+
+// Score returns the current risk score of a wallet
+func (s *Scorer) Score(wallet string) Score {
+	return s.scores([]string{wallet})[0]
+}
+
+// Scores returns the current risk score of every wallet with token accounts
+// or findings, highest first
+func (s *Scorer) Scores() []Score {
+	wallets := make(map[string]bool)
+	for _, account := range s.state() {
+		wallets[account.Owner] = true
+	}
+	s.mutex.Lock()
+	for wallet := range s.findings {
+		wallets[wallet] = true
+	}
+	s.mutex.Unlock()
+
+	list := make([]string, 0, len(wallets))
+	for wallet := range wallets {
+		list = append(list, wallet)
+	}
+	sort.Strings(list)
+
+	scores := s.scores(list)
+	sort.SliceStable(scores, func(i, j int) bool { return scores[i].Score > scores[j].Score })
+	return scores
+}
+
+// scores computes the scores of the given wallets
+func (s *Scorer) scores(wallets []string) []Score {
+	now := time.Now()
+
+	result := make([]Score, len(wallets))
+	index := make(map[string]int, len(wallets))
+	for i, wallet := range wallets {
+		result[i].Wallet = wallet
+		index[wallet] = i
+	}
+
+	// Current account state
+	for _, account := range s.state() {
+		i, ok := index[account.Owner]
+		if !ok {
+			continue
+		}
+		if account.Delegate != "" {
+			result[i].Findings = append(result[i].Findings, Finding{
+				Signal:     SignalDelegate,
+				Points:     weights[SignalDelegate],
+				Account:    account.Address,
+				Mint:       account.Mint,
+				Detail:     account.Delegate,
+				ObservedAt: account.LastUpdatedAt,
+			})
+		}
+		if account.Frozen {
+			result[i].Findings = append(result[i].Findings, Finding{
+				Signal:     SignalFrozenAccount,
+				Points:     weights[SignalFrozenAccount],
+				Account:    account.Address,
+				Mint:       account.Mint,
+				ObservedAt: account.LastUpdatedAt,
+			})
+		}
+	}
+
+	// Observed findings within the window
+	s.mutex.Lock()
+	for wallet, findings := range s.findings {
+		kept := findings[:0]
+		for _, finding := range findings {
+			if now.Sub(finding.ObservedAt) < s.options.Window {
+				kept = append(kept, finding)
+			}
+		}
+		if len(kept) == 0 {
+			delete(s.findings, wallet)
+			continue
+		}
+		s.findings[wallet] = kept
+
+		if i, ok := index[wallet]; ok {
+			result[i].Findings = append(result[i].Findings, kept...)
+		}
+	}
+	s.mutex.Unlock()
+
+	for i := range result {
+		for _, finding := range result[i].Findings {
+			result[i].Score += finding.Points
+		}
+		if result[i].Score > MaxScore {
+			result[i].Score = MaxScore
+		}
+	}
+
+	return result
+}
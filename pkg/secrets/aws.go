@@ -0,0 +1,80 @@
+//go:build !noaws
+
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/aws"
+)
+
+// ${aws-sm://NAME#KEY} reads the secret NAME, a name or an ARN, of AWS
+// Secrets Manager, or its field KEY when the secret holds a JSON object, e.g.
+// ${aws-sm://prod/tracker#helius_api_key}. Requests are signed with the
+// credentials of the environment or of the IAM role of the EKS service
+// account, ECS task or EC2 instance, see aws.CredentialChain, in the region
+// of the ARN, AWS_REGION or AWS_DEFAULT_REGION.
+// AWS_ENDPOINT_URL_SECRETS_MANAGER or AWS_ENDPOINT_URL replace the endpoint
+// of the region.
+func init() {
+	backends["aws-sm"] = awsSecret
+}
+
+// awsService is the name of Secrets Manager in endpoints and signatures
+const awsService = "secretsmanager"
+
+// awsSecret reads the current version of a Secrets Manager secret
+func awsSecret(ctx context.Context, r *Resolver, name, key string) (string, error) {
+	region := aws.ARNRegion(name)
+	if region == "" {
+		region = aws.Region(r.lookupEnv)
+	}
+	if region == "" {
+		return "", fmt.Errorf("AWS_REGION is not set")
+	}
+	chain := &aws.CredentialChain{Env: r.lookupEnv, ReadFile: r.ReadFile, HTTPClient: r.client()}
+	creds, err := chain.Credentials(ctx)
+	if err != nil {
+		return "", err
+	}
+	target, err := url.Parse(aws.Endpoint(r.lookupEnv, awsService, "SECRETS_MANAGER", region))
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	body, err := json.Marshal(map[string]string{"SecretId": name})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	aws.Sign(req, body, creds, region, awsService, time.Now())
+
+	var res struct {
+		SecretString string `json:"SecretString"`
+		SecretBinary string `json:"SecretBinary"`
+	}
+	if err := r.do(req, &res); err != nil {
+		return "", err
+	}
+	secret := res.SecretString
+	if secret == "" && res.SecretBinary != "" {
+		decoded, err := base64.StdEncoding.DecodeString(res.SecretBinary)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode binary secret: %w", err)
+		}
+		secret = string(decoded)
+	}
+	return field(secret, key)
+}
@@ -0,0 +1,61 @@
+//go:build !nogcp
+
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/gcp"
+)
+
+// ${gcp-sm://projects/P/secrets/S#KEY} reads the latest version of the
+// secret S of the project P of GCP Secret Manager, or its field KEY when the
+// secret holds a JSON object; .../versions/V reads version V. Requests are
+// authorized with the access token of GOOGLE_OAUTH_ACCESS_TOKEN, the service
+// account key file of GOOGLE_APPLICATION_CREDENTIALS or the service account
+// of the metadata server, in that order.
+func init() {
+	backends["gcp-sm"] = gcpSecret
+}
+
+// gcpSecretManager is the endpoint of Secret Manager
+const gcpSecretManager = "https://secretmanager.googleapis.com/v1/"
+
+// gcpSecret accesses a version of a Secret Manager secret
+func gcpSecret(ctx context.Context, r *Resolver, name, key string) (string, error) {
+	if !strings.HasPrefix(name, "projects/") || !strings.Contains(name, "/secrets/") {
+		return "", fmt.Errorf("secret must be named projects/PROJECT/secrets/SECRET")
+	}
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+	tokens := &gcp.TokenSource{Env: r.lookupEnv, ReadFile: r.ReadFile, HTTPClient: r.client()}
+	token, err := tokens.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcpSecretManager+name+":access", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var res struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := r.do(req, &res); err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(res.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode payload: %w", err)
+	}
+	return field(string(data), key)
+}
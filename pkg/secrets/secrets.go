@@ -0,0 +1,236 @@
+// Package secrets expands references to secrets in configuration values, so
+// RPC API keys, bot tokens and webhook secrets can be kept out of the
+// configuration file. A value such as
+//
+//	https://mainnet.helius-rpc.com/?api-key=${HELIUS_API_KEY}
+//
+// takes the key from the environment. References are written ${REF}:
+//
+//   - ${NAME} is the environment variable NAME
+//   - ${file:PATH} is the content of the file at PATH without its trailing
+//     newline, such as a Docker or Kubernetes secret
+//   - ${vault://PATH#KEY}, ${aws-sm://NAME#KEY} and
+//     ${gcp-sm://projects/P/secrets/S#KEY} are secrets of HashiCorp Vault,
+//     AWS Secrets Manager and GCP Secret Manager, see the backend files.
+//     #KEY picks a field of a secret holding a JSON object.
+//
+// $${ is a literal ${.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultTimeout bounds each lookup in a secret manager
+const defaultTimeout = 10 * time.Second
+
+// backend looks up the secret at a path of a secret manager, or the field key
+// of it when key isn't empty
+type backend func(ctx context.Context, r *Resolver, path, key string) (string, error)
+
+// backends are the secret managers compiled into the binary. Each registers
+// itself from a file excluded by its build tag.
+var backends = map[string]backend{}
+
+// backendTags are the build tags that exclude secret managers
+var backendTags = map[string]string{
+	"vault":  "novault",
+	"aws-sm": "noaws",
+	"gcp-sm": "nogcp",
+}
+
+// Resolver looks up the secrets referenced by values. Secrets are looked up
+// once per resolver.
+type Resolver struct {
+	// Env looks up environment variables, os.LookupEnv when nil
+	Env func(name string) (string, bool)
+	// ReadFile reads secret files, os.ReadFile when nil
+	ReadFile func(path string) ([]byte, error)
+	// HTTPClient makes the requests to secret managers, a client with a
+	// 10 second timeout when nil
+	HTTPClient *http.Client
+
+	cache map[string]string
+}
+
+// HasReferences reports whether a value references secrets
+func HasReferences(value string) bool {
+	return strings.Contains(value, "${")
+}
+
+// Expand replaces the references of a value by the secrets they name
+func (r *Resolver) Expand(value string) (string, error) {
+	if !HasReferences(value) {
+		return value, nil
+	}
+
+	var expanded strings.Builder
+	for {
+		start := strings.Index(value, "${")
+		if start < 0 {
+			expanded.WriteString(value)
+			return expanded.String(), nil
+		}
+		// $${ escapes a literal ${
+		if start > 0 && value[start-1] == '$' {
+			expanded.WriteString(value[:start-1])
+			expanded.WriteString("${")
+			value = value[start+2:]
+			continue
+		}
+		end := strings.Index(value[start:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated reference %q", value[start:])
+		}
+
+		secret, err := r.Resolve(value[start+2 : start+end])
+		if err != nil {
+			return "", err
+		}
+		expanded.WriteString(value[:start])
+		expanded.WriteString(secret)
+		value = value[start+end+1:]
+	}
+}
+
+// Resolve returns the secret a reference, without its ${ and }, names
+func (r *Resolver) Resolve(ref string) (string, error) {
+	scheme, location, ok := strings.Cut(ref, ":")
+	if !ok {
+		return r.env(ref)
+	}
+	if scheme == "file" {
+		return r.file(location)
+	}
+
+	if secret, ok := r.cache[ref]; ok {
+		return secret, nil
+	}
+	lookup, ok := backends[scheme]
+	if !ok {
+		if tag, excluded := backendTags[scheme]; excluded {
+			return "", fmt.Errorf("%s secrets are not available in this build (built with the %s tag)", scheme, tag)
+		}
+		return "", fmt.Errorf("unknown secret reference %q", ref)
+	}
+	path, key, _ := strings.Cut(strings.TrimPrefix(location, "//"), "#")
+	if path == "" {
+		return "", fmt.Errorf("empty %s secret path", scheme)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+	secret, err := lookup(ctx, r, path, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up %s secret %s: %w", scheme, path, err)
+	}
+	if r.cache == nil {
+		r.cache = make(map[string]string)
+	}
+	r.cache[ref] = secret
+	return secret, nil
+}
+
+// lookupEnv looks up an environment variable through Env, or os.LookupEnv
+// when it's nil
+func (r *Resolver) lookupEnv(name string) (string, bool) {
+	if r.Env == nil {
+		return os.LookupEnv(name)
+	}
+	return r.Env(name)
+}
+
+// client returns the HTTP client of requests to secret managers
+func (r *Resolver) client() *http.Client {
+	if r.HTTPClient == nil {
+		return &http.Client{Timeout: defaultTimeout}
+	}
+	return r.HTTPClient
+}
+
+// field returns the field key of a secret holding a JSON object, or the
+// secret itself when key is empty
+func field(secret, key string) (string, error) {
+	if key == "" {
+		return secret, nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("secret isn't a JSON object, can't pick %q", key)
+	}
+	return fieldOf(fields, key)
+}
+
+// fieldOf returns a field of a secret, strings as is and other values as
+// JSON
+func fieldOf(fields map[string]interface{}, key string) (string, error) {
+	value, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("secret has no field %q", key)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// do sends a request to a secret manager and decodes its JSON response
+func (r *Resolver) do(req *http.Request, out interface{}) error {
+	res, err := r.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", res.Status, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// env resolves a ${NAME} reference to the environment variable NAME
+func (r *Resolver) env(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("empty reference")
+	}
+	value, ok := r.lookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+// file reads a secret file
+func (r *Resolver) file(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("empty secret file path")
+	}
+	data, err := r.readFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// readFile reads a file
+func (r *Resolver) readFile(path string) ([]byte, error) {
+	if r.ReadFile == nil {
+		return os.ReadFile(path)
+	}
+	return r.ReadFile(path)
+}
@@ -0,0 +1,102 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	env := map[string]string{"API_KEY": "k3y", "EMPTY": ""}
+	files := map[string]string{"/run/secrets/token": "t0ken\n", "/run/secrets/crlf": "line\r\n"}
+
+	// A fake secret manager counting its lookups
+	lookups := 0
+	backends["test"] = func(ctx context.Context, r *Resolver, path, key string) (string, error) {
+		lookups++
+		if path == "missing" {
+			return "", errors.New("not found")
+		}
+		return field(`{"user":"admin","port":5432}`, key)
+	}
+	defer delete(backends, "test")
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"no references", "https://rpc.example.com", "https://rpc.example.com", false},
+		{"dollar without brace", "$API_KEY costs $5", "$API_KEY costs $5", false},
+		{"environment variable", "https://rpc.example.com/?api-key=${API_KEY}", "https://rpc.example.com/?api-key=k3y", false},
+		{"empty variable", "[${EMPTY}]", "[]", false},
+		{"several references", "${API_KEY}:${file:/run/secrets/token}", "k3y:t0ken", false},
+		{"file without its newline", "${file:/run/secrets/crlf}", "line", false},
+		{"escaped", "$${API_KEY} is ${API_KEY}", "${API_KEY} is k3y", false},
+		{"secret manager", "${test://db#user}", "admin", false},
+		{"field as JSON", "${test://db#port}", "5432", false},
+		{"whole secret", "${test://db}", `{"user":"admin","port":5432}`, false},
+		{"unset variable", "${MISSING}", "", true},
+		{"empty reference", "${}", "", true},
+		{"unterminated", "${API_KEY", "", true},
+		{"missing file", "${file:/run/secrets/none}", "", true},
+		{"empty file path", "${file:}", "", true},
+		{"unknown scheme", "${keychain://tracker}", "", true},
+		{"empty path", "${test://#user}", "", true},
+		{"missing field", "${test://db#password}", "", true},
+		{"failed lookup", "${test://missing}", "", true},
+	}
+	for _, tt := range tests {
+		r := &Resolver{
+			Env: func(name string) (string, bool) {
+				value, ok := env[name]
+				return value, ok
+			},
+			ReadFile: func(path string) ([]byte, error) {
+				data, ok := files[path]
+				if !ok {
+					return nil, os.ErrNotExist
+				}
+				return []byte(data), nil
+			},
+		}
+		got, err := r.Expand(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Expand(%q) error = %v, want error %v", tt.name, tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: Expand(%q) = %q, want %q", tt.name, tt.value, got, tt.want)
+		}
+	}
+
+	// Secrets are looked up once per resolver
+	lookups = 0
+	r := &Resolver{}
+	for i := 0; i < 3; i++ {
+		if _, err := r.Expand("${test://db#user}"); err != nil {
+			t.Fatalf("Expand: %v", err)
+		}
+	}
+	if lookups != 1 {
+		t.Errorf("looked up the secret %d times, want once", lookups)
+	}
+}
+
+func TestExpandExcludedBackend(t *testing.T) {
+	// Pretend the build left out a secret manager
+	lookup := backends["vault"]
+	delete(backends, "vault")
+	defer func() {
+		if lookup != nil {
+			backends["vault"] = lookup
+		}
+	}()
+
+	_, err := (&Resolver{}).Expand("${vault://secret/data/tracker#key}")
+	if err == nil || err.Error() != "vault secrets are not available in this build (built with the novault tag)" {
+		t.Errorf("got %v, want an error naming the novault tag", err)
+	}
+}
@@ -0,0 +1,85 @@
+//go:build !novault
+
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ${vault://PATH#KEY} reads the field KEY of the secret at the API path PATH
+// of HashiCorp Vault, e.g. ${vault://secret/data/tracker#helius_api_key} for
+// the KV version 2 engine mounted at secret/. The server is that of
+// VAULT_ADDR, the token that of VAULT_TOKEN or ~/.vault-token and the
+// namespace that of VAULT_NAMESPACE, as for the vault CLI.
+func init() {
+	backends["vault"] = vaultSecret
+}
+
+// vaultSecret reads a field of a Vault secret. KEY may be left out for
+// secrets with a single field.
+func vaultSecret(ctx context.Context, r *Resolver, path, key string) (string, error) {
+	vaultAddr, _ := r.lookupEnv("VAULT_ADDR")
+	address := strings.TrimSuffix(vaultAddr, "/")
+	if address == "" {
+		return "", fmt.Errorf("VAULT_ADDR is not set")
+	}
+	token, _ := r.lookupEnv("VAULT_TOKEN")
+	if token == "" {
+		token = vaultTokenFile()
+	}
+	if token == "" {
+		return "", fmt.Errorf("VAULT_TOKEN is not set")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, address+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace, _ := r.lookupEnv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	var res struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := r.do(req, &res); err != nil {
+		return "", err
+	}
+
+	// The KV version 2 engine nests the fields under data, next to the
+	// metadata of the version
+	fields := res.Data
+	if nested, ok := fields["data"].(map[string]interface{}); ok {
+		if _, versioned := fields["metadata"]; versioned {
+			fields = nested
+		}
+	}
+	if key == "" {
+		if len(fields) != 1 {
+			return "", fmt.Errorf("secret has %d fields, pick one with #key", len(fields))
+		}
+		for name := range fields {
+			key = name
+		}
+	}
+	return fieldOf(fields, key)
+}
+
+// vaultTokenFile returns the token the vault CLI keeps after a login
+func vaultTokenFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	token, err := os.ReadFile(filepath.Join(home, ".vault-token"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(token))
+}
@@ -0,0 +1,381 @@
+package snapshot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// DefaultInterval takes one snapshot a day, at midnight UTC unless offset
+const DefaultInterval = 24 * time.Hour
+
+// idLayout formats snapshot IDs from the time they were taken at
+const idLayout = "20060102T150405Z"
+
+// ErrNotFound is returned for snapshots that don't exist
+var ErrNotFound = errors.New("snapshot not found")
+
+// Snapshot records every tracked token balance at a point in time
+type Snapshot struct {
+	ID       string                    `json:"id"`
+	TakenAt  time.Time                 `json:"taken_at"`
+	Accounts []solana.TokenAccountInfo `json:"accounts"`
+	// Networks holds the accounts of the further networks the tracker
+	// watches, by network name
+	Networks map[string][]solana.TokenAccountInfo `json:"networks,omitempty"`
+	// Hash is the hex SHA-256 of the JSON of TakenAt, Accounts and Networks
+	Hash string `json:"hash"`
+}
+
+// Summary describes a snapshot without its accounts
+type Summary struct {
+	ID       string    `json:"id"`
+	TakenAt  time.Time `json:"taken_at"`
+	Accounts int       `json:"accounts"`
+	Hash     string    `json:"hash"`
+}
+
+// Verification is the result of checking a snapshot against its hash
+type Verification struct {
+	ID       string `json:"id"`
+	Valid    bool   `json:"valid"`
+	Hash     string `json:"hash"`
+	Computed string `json:"computed"`
+}
+
+// New takes a snapshot of the token accounts in state and of those of
+// further networks by name, which may be nil. Accounts are sorted by owner,
+// mint and address so equal balances always hash the same.
+func New(at time.Time, state map[string]solana.TokenAccountInfo, networks map[string]map[string]solana.TokenAccountInfo) (*Snapshot, error) {
+	at = at.UTC()
+	snap := &Snapshot{
+		ID:       at.Format(idLayout),
+		TakenAt:  at,
+		Accounts: sortedAccounts(state),
+	}
+	for name, networkState := range networks {
+		if snap.Networks == nil {
+			snap.Networks = make(map[string][]solana.TokenAccountInfo, len(networks))
+		}
+		snap.Networks[name] = sortedAccounts(networkState)
+	}
+	hash, err := snap.ComputeHash()
+	if err != nil {
+		return nil, err
+	}
+	snap.Hash = hash
+	return snap, nil
+}
+
+// sortedAccounts returns the token accounts of a state by owner, mint and
+// address
+func sortedAccounts(state map[string]solana.TokenAccountInfo) []solana.TokenAccountInfo {
+	accounts := make([]solana.TokenAccountInfo, 0, len(state))
+	for _, account := range state {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		a, b := accounts[i], accounts[j]
+		if a.Owner != b.Owner {
+			return a.Owner < b.Owner
+		}
+		if a.Mint != b.Mint {
+			return a.Mint < b.Mint
+		}
+		return a.Address < b.Address
+	})
+	return accounts
+}
+
+// hashedAccount is a token account as hashed, without the UI amounts
+// derived from its balance, so the hashes of older snapshots still verify
+type hashedAccount solana.TokenAccountInfo
+
+// ComputeHash hashes the time and accounts of the snapshot. Snapshots
+// without further networks hash as they did before networks were added.
+func (s *Snapshot) ComputeHash() (string, error) {
+	hashed := func(accounts []solana.TokenAccountInfo) []hashedAccount {
+		result := make([]hashedAccount, 0, len(accounts))
+		for _, account := range accounts {
+			result = append(result, hashedAccount(account))
+		}
+		return result
+	}
+	var networks map[string][]hashedAccount
+	for name, accounts := range s.Networks {
+		if networks == nil {
+			networks = make(map[string][]hashedAccount, len(s.Networks))
+		}
+		networks[name] = hashed(accounts)
+	}
+	data, err := json.Marshal(struct {
+		TakenAt  time.Time                  `json:"taken_at"`
+		Accounts []hashedAccount            `json:"accounts"`
+		Networks map[string][]hashedAccount `json:"networks,omitempty"`
+	}{s.TakenAt, hashed(s.Accounts), networks})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Verify recomputes the hash of the snapshot and compares it with the
+// recorded one
+func (s *Snapshot) Verify() (*Verification, error) {
+	computed, err := s.ComputeHash()
+	if err != nil {
+		return nil, err
+	}
+	return &Verification{
+		ID:       s.ID,
+		Valid:    computed == s.Hash,
+		Hash:     s.Hash,
+		Computed: computed,
+	}, nil
+}
+
+// Summary describes the snapshot without its accounts
+func (s *Snapshot) Summary() Summary {
+	return Summary{ID: s.ID, TakenAt: s.TakenAt, Accounts: s.accountCount(), Hash: s.Hash}
+}
+
+// accountCount counts the accounts of every network
+func (s *Snapshot) accountCount() int {
+	count := len(s.Accounts)
+	for _, accounts := range s.Networks {
+		count += len(accounts)
+	}
+	return count
+}
+
+// Store keeps snapshots as read-only JSON files in a directory. Files are
+// created exclusively, so a snapshot is never overwritten.
+type Store struct {
+	dir string
+}
+
+// NewStore opens or creates a snapshot directory
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Save writes a new snapshot. It fails if one with the same ID exists.
+func (s *Store) Save(snap *Snapshot) error {
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	file, err := os.OpenFile(s.path(snap.ID), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0444)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot %s: %w", snap.ID, err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write snapshot %s: %w", snap.ID, err)
+	}
+	return file.Close()
+}
+
+// Get reads a snapshot by ID
+func (s *Store) Get(id string) (*Snapshot, error) {
+	// IDs are timestamps; anything else could escape the directory
+	if _, err := time.Parse(idLayout, id); err != nil {
+		return nil, ErrNotFound
+	}
+
+	data, err := ioutil.ReadFile(s.path(id))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %w", id, err)
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot %s: %w", id, err)
+	}
+	return &snap, nil
+}
+
+// List describes every snapshot, oldest first
+func (s *Store) List() ([]Summary, error) {
+	ids, err := s.ids()
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]Summary, 0, len(ids))
+	for _, id := range ids {
+		snap, err := s.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, snap.Summary())
+	}
+	return summaries, nil
+}
+
+// Latest returns the latest n snapshots, oldest first
+func (s *Store) Latest(n int) ([]*Snapshot, error) {
+	ids, err := s.ids()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > n {
+		ids = ids[len(ids)-n:]
+	}
+
+	snaps := make([]*Snapshot, 0, len(ids))
+	for _, id := range ids {
+		snap, err := s.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		snaps = append(snaps, snap)
+	}
+	return snaps, nil
+}
+
+// At returns the latest snapshot taken at or before t
+func (s *Store) At(t time.Time) (*Snapshot, error) {
+	ids, err := s.ids()
+	if err != nil {
+		return nil, err
+	}
+
+	// IDs sort chronologically
+	cutoff := t.UTC().Format(idLayout)
+	for i := len(ids) - 1; i >= 0; i-- {
+		if ids[i] <= cutoff {
+			return s.Get(ids[i])
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// ids returns the IDs of the stored snapshots in chronological order
+func (s *Store) ids() ([]string, error) {
+	entries, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		id := strings.TrimSuffix(entry.Name(), ".json")
+		if _, err := time.Parse(idLayout, id); err == nil && !entry.IsDir() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// path returns the file of a snapshot
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// Scheduler takes snapshots at fixed times
+type Scheduler struct {
+	store    *Store
+	state    func() map[string]solana.TokenAccountInfo
+	networks map[string]func() map[string]solana.TokenAccountInfo
+	interval time.Duration
+	offset   time.Duration
+	log      logging.Logger
+}
+
+// NewScheduler creates a scheduler that snapshots state every interval,
+// aligned to UTC midnight plus offset. With the default interval of a day
+// and an offset of 6h, snapshots are taken daily at 06:00 UTC. state returns
+// the current token accounts, typically Monitor.GetCurrentState. It logs
+// through log, or the default logger when nil.
+func NewScheduler(store *Store, state func() map[string]solana.TokenAccountInfo, interval, offset time.Duration, log logging.Logger) *Scheduler {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	if log == nil {
+		log = logging.Default()
+	}
+	return &Scheduler{
+		store:    store,
+		state:    state,
+		interval: interval,
+		offset:   offset % interval,
+		log:      log,
+	}
+}
+
+// AddNetwork includes the token accounts of a further network in the
+// snapshots, under its name. state is typically the Monitor.GetCurrentState
+// of the network. Add networks before Run.
+func (s *Scheduler) AddNetwork(name string, state func() map[string]solana.TokenAccountInfo) {
+	if s.networks == nil {
+		s.networks = make(map[string]func() map[string]solana.TokenAccountInfo)
+	}
+	s.networks[name] = state
+}
+
+// Run takes snapshots until ctx is cancelled. Runs missed while the tracker
+// was down are not made up for, since past balances aren't known.
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		next := s.next(time.Now())
+		s.log.Debug("Next balance snapshot scheduled", "at", next)
+
+		select {
+		case <-time.After(time.Until(next)):
+		case <-ctx.Done():
+			return
+		}
+
+		var networks map[string]map[string]solana.TokenAccountInfo
+		for name, state := range s.networks {
+			if networks == nil {
+				networks = make(map[string]map[string]solana.TokenAccountInfo, len(s.networks))
+			}
+			networks[name] = state()
+		}
+		snap, err := New(next, s.state(), networks)
+		if err == nil {
+			err = s.store.Save(snap)
+		}
+		if err != nil {
+			s.log.Error("Failed to take balance snapshot", "error", err)
+			continue
+		}
+		s.log.Info("Balance snapshot taken",
+			"id", snap.ID,
+			"accounts", snap.accountCount(),
+			"hash", snap.Hash,
+		)
+	}
+}
+
+// next returns the first scheduled time after now
+func (s *Scheduler) next(now time.Time) time.Time {
+	next := now.UTC().Add(-s.offset).Truncate(s.interval).Add(s.offset)
+	for !next.After(now) {
+		next = next.Add(s.interval)
+	}
+	return next
+}
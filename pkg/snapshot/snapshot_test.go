@@ -0,0 +1,148 @@
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+func TestComputeHash(t *testing.T) {
+	at := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	account := solana.TokenAccountInfo{Address: "a1", Owner: "w1", Mint: "m1", Balance: 5, Decimals: 6}
+
+	// The hash covers the JSON of the time and the accounts without their
+	// UI amounts, so snapshots taken before networks existed still verify
+	legacy := `{"taken_at":"2024-12-01T00:00:00Z","accounts":[` +
+		`{"address":"a1","owner":"w1","mint":"m1","balance":5,"decimals":6,"program_id":"","last_updated_at":"0001-01-01T00:00:00Z"}]}`
+	sum := sha256.Sum256([]byte(legacy))
+	want := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name     string
+		networks map[string]map[string]solana.TokenAccountInfo
+		same     bool
+	}{
+		{"without networks", nil, true},
+		{"with empty networks", map[string]map[string]solana.TokenAccountInfo{}, true},
+		{"with a network", map[string]map[string]solana.TokenAccountInfo{"devnet": {"a1": account}}, false},
+	}
+	for _, tt := range tests {
+		snap, err := New(at, map[string]solana.TokenAccountInfo{"a1": account}, tt.networks)
+		if err != nil {
+			t.Fatalf("%s: New: %v", tt.name, err)
+		}
+		if same := snap.Hash == want; same != tt.same {
+			t.Errorf("%s: hash %s, matches the legacy hash = %v, want %v", tt.name, snap.Hash, same, tt.same)
+		}
+	}
+}
+
+func TestNewSortsAccounts(t *testing.T) {
+	at := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	state := map[string]solana.TokenAccountInfo{
+		"a3": {Address: "a3", Owner: "w2", Mint: "m1"},
+		"a2": {Address: "a2", Owner: "w1", Mint: "m2"},
+		"a1": {Address: "a1", Owner: "w1", Mint: "m1"},
+		"a0": {Address: "a0", Owner: "w1", Mint: "m1"},
+	}
+	snap, err := New(at, state, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	var got []string
+	for _, account := range snap.Accounts {
+		got = append(got, account.Address)
+	}
+	if want := []string{"a0", "a1", "a2", "a3"}; !equalStrings(got, want) {
+		t.Errorf("accounts in order %v, want %v", got, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	at := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	take := func() *Snapshot {
+		snap, err := New(at,
+			map[string]solana.TokenAccountInfo{"a1": {Address: "a1", Owner: "w1", Mint: "m1", Balance: 5}},
+			map[string]map[string]solana.TokenAccountInfo{"devnet": {"a2": {Address: "a2", Owner: "w1", Mint: "m1", Balance: 7}}})
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		return snap
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(snap *Snapshot)
+		valid  bool
+	}{
+		{"untouched", func(*Snapshot) {}, true},
+		{"balance", func(snap *Snapshot) { snap.Accounts[0].Balance++ }, false},
+		{"network balance", func(snap *Snapshot) { snap.Networks["devnet"][0].Balance++ }, false},
+		{"network name", func(snap *Snapshot) {
+			snap.Networks["testnet"] = snap.Networks["devnet"]
+			delete(snap.Networks, "devnet")
+		}, false},
+		{"removed account", func(snap *Snapshot) { snap.Accounts = nil }, false},
+		{"time", func(snap *Snapshot) { snap.TakenAt = snap.TakenAt.Add(time.Second) }, false},
+		{"hash", func(snap *Snapshot) { snap.Hash = "00" + snap.Hash[2:] }, false},
+		{"unserialized source", func(snap *Snapshot) { snap.Accounts[0].Source = solana.SourceWebSocket }, true},
+	}
+	for _, tt := range tests {
+		snap := take()
+		tt.tamper(snap)
+		verification, err := snap.Verify()
+		if err != nil {
+			t.Fatalf("%s: Verify: %v", tt.name, err)
+		}
+		if verification.Valid != tt.valid {
+			t.Errorf("%s: valid = %v, want %v", tt.name, verification.Valid, tt.valid)
+		}
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	snap, err := New(time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC),
+		map[string]solana.TokenAccountInfo{"a1": {Address: "a1", Owner: "w1", Mint: "m1", Balance: 5, Decimals: 6}},
+		map[string]map[string]solana.TokenAccountInfo{"devnet": {"a2": {Address: "a2", Owner: "w1", Mint: "m1", Balance: 7}}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := store.Save(snap); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := store.Save(snap); err == nil {
+		t.Errorf("saving a snapshot twice succeeded")
+	}
+
+	saved, err := store.Get(snap.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	verification, err := saved.Verify()
+	if err != nil || !verification.Valid {
+		t.Errorf("saved snapshot doesn't verify: %+v, %v", verification, err)
+	}
+	if summary := saved.Summary(); summary.Accounts != 2 {
+		t.Errorf("summary counts %d accounts, want 2", summary.Accounts)
+	}
+}
+
+// equalStrings compares string slices
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+// Package sns resolves Solana Name Service (.sol) domains accepted in place
+// of wallet addresses and follows them as they change owners.
+package sns
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// DefaultRefresh is how often domains are resolved again by default
+const DefaultRefresh = time.Hour
+
+// IsDomain reports whether a value is a .sol domain rather than an address,
+// such as "bonfida.sol" or "dex.bonfida.sol"
+func IsDomain(value string) bool {
+	name := strings.ToLower(value)
+	if !strings.HasSuffix(name, ".sol") || strings.ContainsAny(name, " /") {
+		return false
+	}
+	for _, label := range strings.Split(strings.TrimSuffix(name, ".sol"), ".") {
+		if label == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Resolver resolves domains and remembers the wallets they resolved to
+type Resolver struct {
+	client  *solana.Client
+	mutex   sync.RWMutex
+	wallets map[string]string
+}
+
+// NewResolver creates a resolver looking domains up through client
+func NewResolver(client *solana.Client) *Resolver {
+	return &Resolver{
+		client:  client,
+		wallets: make(map[string]string),
+	}
+}
+
+// Resolve looks up the wallet owning a domain
+func (r *Resolver) Resolve(ctx context.Context, domain string) (string, error) {
+	domain = strings.ToLower(domain)
+	wallet, err := r.client.ResolveDomain(ctx, domain)
+	if err != nil {
+		return "", err
+	}
+
+	r.mutex.Lock()
+	r.wallets[domain] = wallet
+	r.mutex.Unlock()
+	return wallet, nil
+}
+
+// Wallet returns the wallet a value refers to: addresses as they are and
+// domains as last resolved, looking up domains not seen before
+func (r *Resolver) Wallet(ctx context.Context, value string) (string, error) {
+	if !IsDomain(value) {
+		return value, nil
+	}
+
+	r.mutex.RLock()
+	wallet, ok := r.wallets[strings.ToLower(value)]
+	r.mutex.RUnlock()
+	if ok {
+		return wallet, nil
+	}
+	return r.Resolve(ctx, value)
+}
+
+// Watch resolves domains again every interval until ctx is cancelled and
+// calls changed with the previous and current wallet of each domain that
+// changed owner. Lookups that fail keep the previous wallet.
+func (r *Resolver) Watch(ctx context.Context, interval time.Duration, domains []string, changed func(domain, previous, current string)) {
+	if interval <= 0 {
+		interval = DefaultRefresh
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		for _, domain := range domains {
+			previous, err := r.Wallet(ctx, domain)
+			if err != nil {
+				continue
+			}
+			current, err := r.Resolve(ctx, domain)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				r.client.Log().Warn("Failed to resolve domain again", "domain", domain, "error", err)
+				continue
+			}
+			if current != previous {
+				r.client.Log().Warn("Domain changed owner", "domain", domain, "previous", previous, "wallet", current)
+				changed(domain, previous, current)
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package solana
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+const (
+	// maxMultipleAccounts is the most accounts getMultipleAccounts accepts
+	maxMultipleAccounts = 100
+	// mintAccountSize is the size of a mint without extensions
+	mintAccountSize = 82
+)
+
+// Program IDs that own the accounts the tracker reads
+var (
+	SystemProgramID    = solana.SystemProgramID.String()
+	TokenProgramID     = solana.TokenProgramID.String()
+	Token2022ProgramID = "TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb"
+)
+
+// AccountSummary is the owner and size of an on-chain account
+type AccountSummary struct {
+	Address  string `json:"address"`
+	Owner    string `json:"owner"`
+	Lamports uint64 `json:"lamports"`
+	DataSize int    `json:"data_size"`
+}
+
+// IsMint reports whether the account is an SPL token mint
+func (a AccountSummary) IsMint() bool {
+	switch a.Owner {
+	case TokenProgramID:
+		return a.DataSize == mintAccountSize
+	case Token2022ProgramID:
+		// Token-2022 mints with extensions are padded past the account size
+		return a.DataSize == mintAccountSize || a.DataSize > tokenAccountSize
+	}
+	return false
+}
+
+// Ping checks that the RPC endpoint is up and caught up with the cluster
+func (c *Client) Ping(ctx context.Context) error {
+	if _, err := c.RPCClient.GetHealth(ctx); err != nil {
+		return rpcFailed("get health", err)
+	}
+	return nil
+}
+
+// GetBlockTime returns the estimated production time of the block of a
+// slot, in whole seconds. It fails until the block is confirmed.
+func (c *Client) GetBlockTime(ctx context.Context, slot uint64) (time.Time, error) {
+	res, err := c.RPCClient.GetBlockTime(ctx, slot)
+	if err != nil {
+		return time.Time{}, rpcFailed("get block time", err)
+	}
+	if res == nil {
+		return time.Time{}, fmt.Errorf("no block time for slot %d", slot)
+	}
+	return res.Time(), nil
+}
+
+// WaitForSlot waits for the next slot notification, which shows that the
+// WebSocket endpoint delivers subscriptions
+func (c *Client) WaitForSlot(ctx context.Context) (uint64, error) {
+	sub, err := c.WSClient.SlotSubscribe()
+	if err != nil {
+		return 0, rpcFailed("subscribe to slots", err)
+	}
+	defer sub.Unsubscribe()
+
+	type result struct {
+		slot uint64
+		err  error
+	}
+	received := make(chan result, 1)
+	go func() {
+		res, err := sub.Recv()
+		if err != nil {
+			received <- result{err: err}
+			return
+		}
+		received <- result{slot: res.Slot}
+	}()
+
+	select {
+	case res := <-received:
+		if res.err != nil {
+			return 0, subscriptionLost("slot", res.err)
+		}
+		return res.slot, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// GetAccountSummaries looks up several accounts. Accounts that don't exist
+// are missing from the result.
+func (c *Client) GetAccountSummaries(ctx context.Context, addresses []string) (map[string]AccountSummary, error) {
+	pubkeys := make([]solana.PublicKey, 0, len(addresses))
+	for _, address := range addresses {
+		pubkey, err := solana.PublicKeyFromBase58(address)
+		if err != nil {
+			return nil, invalidAddress("address", address, err)
+		}
+		pubkeys = append(pubkeys, pubkey)
+	}
+
+	summaries := make(map[string]AccountSummary, len(pubkeys))
+	for start := 0; start < len(pubkeys); start += maxMultipleAccounts {
+		end := start + maxMultipleAccounts
+		if end > len(pubkeys) {
+			end = len(pubkeys)
+		}
+
+		res, err := c.RPCClient.GetMultipleAccountsWithOpts(ctx, pubkeys[start:end], &rpc.GetMultipleAccountsOpts{
+			Encoding:   solana.EncodingBase64,
+			Commitment: c.polling(),
+		})
+		if err != nil {
+			return nil, rpcFailed("get accounts", err)
+		}
+
+		for i, account := range res.Value {
+			if account == nil {
+				continue
+			}
+			address := pubkeys[start+i].String()
+			summaries[address] = AccountSummary{
+				Address:  address,
+				Owner:    account.Owner.String(),
+				Lamports: account.Lamports,
+				DataSize: len(account.Data.GetBinary()),
+			}
+		}
+	}
+
+	return summaries, nil
+}
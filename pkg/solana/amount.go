@@ -0,0 +1,67 @@
+package solana
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// UIAmount converts a raw amount in base units to UI units, e.g. 1500000
+// with 6 decimals to 1.5. Large amounts lose precision; use UIAmountString
+// where it matters.
+func UIAmount(amount uint64, decimals uint8) float64 {
+	return float64(amount) / math.Pow10(int(decimals))
+}
+
+// BigUIAmount converts a raw amount of any size to UI units, e.g. sums of
+// balances that overflow a uint64. Like UIAmount it loses precision.
+func BigUIAmount(amount *big.Int, decimals uint8) float64 {
+	value, _ := new(big.Rat).SetFrac(
+		amount,
+		new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil),
+	).Float64()
+	return value
+}
+
+// UIAmountString converts a raw amount to UI units exactly, without
+// trailing zeros, like the uiAmountString of the RPC
+func UIAmountString(amount uint64, decimals uint8) string {
+	return BigUIAmountString(new(big.Int).SetUint64(amount), decimals)
+}
+
+// BigUIAmountString converts a raw amount of any size and sign to UI units
+// exactly, e.g. sums of balances that overflow a uint64
+func BigUIAmountString(amount *big.Int, decimals uint8) string {
+	value := new(big.Rat).SetFrac(
+		amount,
+		new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil),
+	)
+	text := value.FloatString(int(decimals))
+	if strings.Contains(text, ".") {
+		text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	}
+	return text
+}
+
+// UIAmount returns the balance in UI units
+func (t TokenAccountInfo) UIAmount() float64 {
+	return UIAmount(t.Balance, t.Decimals)
+}
+
+// UIAmountString returns the balance in UI units exactly
+func (t TokenAccountInfo) UIAmountString() string {
+	return UIAmountString(t.Balance, t.Decimals)
+}
+
+// MarshalJSON adds the balance in UI units as ui_amount and
+// ui_amount_string, so consumers don't need the decimals of every mint.
+// They are derived from balance and decimals and ignored when decoding.
+func (t TokenAccountInfo) MarshalJSON() ([]byte, error) {
+	type plain TokenAccountInfo
+	return json.Marshal(struct {
+		plain
+		UIAmount       float64 `json:"ui_amount"`
+		UIAmountString string  `json:"ui_amount_string"`
+	}{plain(t), t.UIAmount(), t.UIAmountString()})
+}
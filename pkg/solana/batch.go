@@ -0,0 +1,140 @@
+package solana
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+)
+
+// BatchSource is implemented by data sources that look up the token
+// accounts of several wallets in one request
+type BatchSource interface {
+	GetTokenAccountsBatch(ctx context.Context, wallets []string) []TokenAccountsResult
+}
+
+var _ BatchSource = (*Client)(nil)
+
+// TokenAccountsResult is the outcome of the token account lookup of a
+// wallet in a batch
+type TokenAccountsResult struct {
+	Wallet   string
+	Accounts []TokenAccountInfo
+	Err      error
+}
+
+// GetTokenAccountsBatch looks up the token accounts of wallets in a single
+// JSON-RPC batch request. Results are in the order of wallets; when the
+// whole request fails, every wallet carries its error. Providers that don't
+// accept batches fail it with ErrProviderUnsupported or ErrRateLimited.
+func (c *Client) GetTokenAccountsBatch(ctx context.Context, wallets []string) []TokenAccountsResult {
+	results := make([]TokenAccountsResult, len(wallets))
+	requests := make(jsonrpc.RPCRequests, 0, len(wallets))
+	// index maps the IDs of the requests to the wallets they look up
+	index := make([]int, 0, len(wallets))
+	for i, wallet := range wallets {
+		results[i].Wallet = wallet
+		owner, err := solana.PublicKeyFromBase58(wallet)
+		if err != nil {
+			results[i].Err = invalidAddress("wallet address", wallet, err)
+			continue
+		}
+		requests = append(requests, jsonrpc.NewRequest("getTokenAccountsByOwner",
+			owner,
+			rpc.M{"programId": solana.TokenProgramID},
+			rpc.M{"commitment": c.polling(), "encoding": solana.EncodingJSONParsed},
+		))
+		index = append(index, i)
+	}
+	if len(requests) == 0 {
+		return results
+	}
+
+	responses, err := c.RPCClient.RPCCallBatch(ctx, requests)
+	if err != nil {
+		err = rpcFailed("get token accounts in a batch", err)
+		for _, i := range index {
+			results[i].Err = err
+		}
+		return results
+	}
+
+	byID := responses.AsMap()
+	for id, i := range index {
+		response, ok := byID[id]
+		switch {
+		case !ok:
+			results[i].Err = fmt.Errorf("failed to get token accounts: no response for %s in the batch", wallets[i])
+		case response.Error != nil:
+			results[i].Err = rpcFailed("get token accounts", response.Error)
+		default:
+			var res rpc.GetTokenAccountsResult
+			if err := response.GetObject(&res); err != nil {
+				results[i].Err = fmt.Errorf("failed to decode token accounts of %s: %w", wallets[i], err)
+				continue
+			}
+			results[i].Accounts = c.parseTokenAccounts(ctx, &res)
+		}
+	}
+	return results
+}
+
+// AccountSource is implemented by data sources that look up token accounts
+// by address, many per request
+type AccountSource interface {
+	GetTokenAccountsByAddress(ctx context.Context, addresses []string) (map[string]TokenAccountInfo, error)
+}
+
+var _ AccountSource = (*Client)(nil)
+
+// GetTokenAccountsByAddress looks up token accounts through
+// getMultipleAccounts, up to 100 per request. Accounts that were closed, or
+// no longer hold a token account, are missing from the result.
+func (c *Client) GetTokenAccountsByAddress(ctx context.Context, addresses []string) (map[string]TokenAccountInfo, error) {
+	pubkeys := make([]solana.PublicKey, 0, len(addresses))
+	for _, address := range addresses {
+		pubkey, err := solana.PublicKeyFromBase58(address)
+		if err != nil {
+			return nil, invalidAddress("token account", address, err)
+		}
+		pubkeys = append(pubkeys, pubkey)
+	}
+
+	accounts := make(map[string]TokenAccountInfo, len(pubkeys))
+	for start := 0; start < len(pubkeys); start += maxMultipleAccounts {
+		end := start + maxMultipleAccounts
+		if end > len(pubkeys) {
+			end = len(pubkeys)
+		}
+
+		res, err := c.RPCClient.GetMultipleAccountsWithOpts(ctx, pubkeys[start:end], &rpc.GetMultipleAccountsOpts{
+			Encoding:   solana.EncodingJSONParsed,
+			Commitment: c.polling(),
+		})
+		if err != nil {
+			return nil, rpcFailed("get token accounts", err)
+		}
+
+		for i, account := range res.Value {
+			if account == nil {
+				continue
+			}
+			// Closed addresses can be reused by other programs
+			if program := account.Owner.String(); program != TokenProgramID && program != Token2022ProgramID {
+				continue
+			}
+			pubkey := pubkeys[start+i]
+			info, err := c.parseTokenAccount(ctx, pubkey, account, res.Context.Slot)
+			if err != nil {
+				c.Log().Warn("Failed to parse token account data", "account", pubkey, "error", err)
+				c.ParseErrors.record(pubkey.String(), err)
+				continue
+			}
+			accounts[info.Address] = *info
+		}
+	}
+
+	return accounts, nil
+}
@@ -0,0 +1,165 @@
+package solana
+
+import (
+	"crypto/sha256"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Program IDs involved in compressed NFT operations
+const (
+	BubblegumProgramID = "BGUMAp9Gq7iTEuizy4pqaxsTyUCBK68MDfK752saRPUY"
+	NoopProgramID      = "noopb9bkMVfRPU8AsbpTUg8AQkHtKwMYZiFUjNRtMmV"
+)
+
+// Compressed NFT action kinds
+const (
+	CompressedNFTMint     = "mint"
+	CompressedNFTTransfer = "transfer"
+	CompressedNFTBurn     = "burn"
+)
+
+// CompressedNFTAction is a Bubblegum mint, transfer or burn of a compressed NFT
+type CompressedNFTAction struct {
+	Kind    string `json:"kind"`
+	AssetID string `json:"asset_id,omitempty"`
+	Tree    string `json:"tree"`
+	// From is the previous leaf owner of transfers and burns
+	From string `json:"from,omitempty"`
+	// To is the leaf owner of mints and the new leaf owner of transfers
+	To string `json:"to,omitempty"`
+}
+
+// bubblegumInstruction describes where an instruction keeps its accounts
+type bubblegumInstruction struct {
+	kind     string
+	from     int
+	to       int
+	tree     int
+	hasNonce bool
+}
+
+// bubblegumInstructions maps Anchor discriminators to instruction layouts
+var bubblegumInstructions = map[[8]byte]bubblegumInstruction{
+	anchorDiscriminator("mint_v1"):               {kind: CompressedNFTMint, from: -1, to: 1, tree: 3},
+	anchorDiscriminator("mint_to_collection_v1"): {kind: CompressedNFTMint, from: -1, to: 1, tree: 3},
+	anchorDiscriminator("transfer"):              {kind: CompressedNFTTransfer, from: 1, to: 3, tree: 4, hasNonce: true},
+	anchorDiscriminator("burn"):                  {kind: CompressedNFTBurn, from: 1, to: -1, tree: 3, hasNonce: true},
+}
+
+// anchorDiscriminator returns the 8 byte prefix Anchor uses to identify an
+// instruction
+func anchorDiscriminator(name string) [8]byte {
+	var discriminator [8]byte
+	sum := sha256.Sum256([]byte("global:" + name))
+	copy(discriminator[:], sum[:8])
+	return discriminator
+}
+
+// MentionsBubblegum reports whether transaction logs show a Bubblegum invocation
+func MentionsBubblegum(logs []string) bool {
+	for _, line := range logs {
+		if strings.Contains(line, BubblegumProgramID) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseCompressedNFTActions extracts compressed NFT mints, transfers and
+// burns from a transaction, including ones invoked by other programs
+func ParseCompressedNFTActions(tx *Transaction) []CompressedNFTAction {
+	var actions []CompressedNFTAction
+	for i, ix := range tx.Instructions {
+		if ix.ProgramID != BubblegumProgramID || len(ix.Data) < 8 {
+			continue
+		}
+
+		var discriminator [8]byte
+		copy(discriminator[:], ix.Data[:8])
+		layout, ok := bubblegumInstructions[discriminator]
+		if !ok {
+			continue
+		}
+
+		action := CompressedNFTAction{
+			Kind: layout.kind,
+			Tree: account(ix, layout.tree),
+			From: account(ix, layout.from),
+			To:   account(ix, layout.to),
+		}
+
+		// Mints and transfers log the new leaf through the noop program;
+		// transfers and burns also carry the leaf nonce the asset ID derives from
+		action.AssetID = leafAssetID(tx.Instructions[i+1:])
+		if action.AssetID == "" && layout.hasNonce {
+			action.AssetID = nonceAssetID(action.Tree, ix.Data)
+		}
+
+		actions = append(actions, action)
+	}
+	return actions
+}
+
+// account returns the account at position in an instruction, or an empty
+// string if the position is unused or out of range
+func account(ix Instruction, position int) string {
+	if position < 0 || position >= len(ix.Accounts) {
+		return ""
+	}
+	return ix.Accounts[position]
+}
+
+// leafAssetID reads the asset ID from the leaf schema event Bubblegum logs
+// through the noop program right after the instruction
+func leafAssetID(following []Instruction) string {
+	// AccountCompressionEvent::ApplicationData (1), ApplicationDataEvent::V1
+	// (0), u32 length, event type (LeafSchemaEvent = 1), version and
+	// LeafSchema::V1 tag precede the ID
+	const (
+		eventTypeOffset = 6
+		idOffset        = 9
+	)
+
+	for _, ix := range following {
+		if !ix.Inner {
+			break
+		}
+		if ix.ProgramID == BubblegumProgramID {
+			break
+		}
+		if ix.ProgramID != NoopProgramID || len(ix.Data) < idOffset+32 {
+			continue
+		}
+		if ix.Data[0] != 1 || ix.Data[eventTypeOffset] != 1 {
+			continue
+		}
+		return solana.PublicKeyFromBytes(ix.Data[idOffset : idOffset+32]).String()
+	}
+	return ""
+}
+
+// nonceAssetID derives the asset ID of a leaf from its tree and the nonce
+// carried in transfer and burn instructions
+func nonceAssetID(tree string, data []byte) string {
+	// Discriminator, root, data hash and creator hash precede the nonce
+	const nonceOffset = 8 + 32*3
+
+	treeKey, err := solana.PublicKeyFromBase58(tree)
+	if err != nil || len(data) < nonceOffset+8 {
+		return ""
+	}
+
+	// The nonce is a little endian u64, which is also its seed encoding
+	nonce := data[nonceOffset : nonceOffset+8]
+
+	assetID, _, err := solana.FindProgramAddress(
+		[][]byte{[]byte("asset"), treeKey[:], nonce},
+		solana.MustPublicKeyFromBase58(BubblegumProgramID),
+	)
+	if err != nil {
+		return ""
+	}
+	return assetID.String()
+}
@@ -0,0 +1,93 @@
+package solana
+
+import (
+	"strconv"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// CLITokenAccounts is the output of `spl-token accounts --output json`
+type CLITokenAccounts struct {
+	Accounts []CLITokenAccount `json:"accounts"`
+}
+
+// CLITokenAccount is a token account as the spl-token CLI prints it
+type CLITokenAccount struct {
+	Address      string         `json:"address"`
+	ProgramID    string         `json:"programId"`
+	IsAssociated bool           `json:"isAssociated"`
+	Mint         string         `json:"mint"`
+	Owner        string         `json:"owner"`
+	TokenAmount  CLITokenAmount `json:"tokenAmount"`
+	Delegate     string         `json:"delegate,omitempty"`
+	State        string         `json:"state"`
+	IsNative     bool           `json:"isNative"`
+}
+
+// CLITokenAmount is a token amount as the spl-token CLI prints it
+type CLITokenAmount struct {
+	Amount         string  `json:"amount"`
+	Decimals       uint8   `json:"decimals"`
+	UIAmount       float64 `json:"uiAmount"`
+	UIAmountString string  `json:"uiAmountString"`
+}
+
+// ToCLI converts token accounts to the JSON output of the spl-token CLI, so
+// the tracker's view can be compared with it
+func ToCLI(accounts []TokenAccountInfo) CLITokenAccounts {
+	output := CLITokenAccounts{Accounts: make([]CLITokenAccount, 0, len(accounts))}
+	for _, account := range accounts {
+		programID := account.ProgramID
+		if programID == "" {
+			programID = TokenProgramID
+		}
+		state := "initialized"
+		if account.Frozen {
+			state = "frozen"
+		}
+		output.Accounts = append(output.Accounts, CLITokenAccount{
+			Address:      account.Address,
+			ProgramID:    programID,
+			IsAssociated: isAssociated(account, programID),
+			Mint:         account.Mint,
+			Owner:        account.Owner,
+			TokenAmount:  cliAmount(account.Balance, account.Decimals),
+			Delegate:     account.Delegate,
+			State:        state,
+			IsNative:     account.Mint == solana.WrappedSol.String(),
+		})
+	}
+	return output
+}
+
+// isAssociated reports whether a token account is the associated token
+// account of its owner for its mint
+func isAssociated(account TokenAccountInfo, programID string) bool {
+	owner, err := solana.PublicKeyFromBase58(account.Owner)
+	if err != nil {
+		return false
+	}
+	mint, err := solana.PublicKeyFromBase58(account.Mint)
+	if err != nil {
+		return false
+	}
+	program, err := solana.PublicKeyFromBase58(programID)
+	if err != nil {
+		return false
+	}
+	address, _, err := solana.FindProgramAddress(
+		[][]byte{owner[:], program[:], mint[:]},
+		solana.SPLAssociatedTokenAccountProgramID,
+	)
+	return err == nil && address.String() == account.Address
+}
+
+// cliAmount formats a raw amount like the spl-token CLI
+func cliAmount(amount uint64, decimals uint8) CLITokenAmount {
+	return CLITokenAmount{
+		Amount:         strconv.FormatUint(amount, 10),
+		Decimals:       decimals,
+		UIAmount:       UIAmount(amount, decimals),
+		UIAmountString: UIAmountString(amount, decimals),
+	}
+}
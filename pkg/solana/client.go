@@ -3,13 +3,15 @@ package solana
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
 	"github.com/gagliardetto/solana-go/rpc/ws"
-	"github.com/sirupsen/logrus"
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
 )
 
 // Client represents a Solana RPC and WebSocket client
@@ -18,27 +20,112 @@ type Client struct {
 	WSClient    *ws.Client
 	RPCEndpoint string
 	WSEndpoint  string
+	Commitments Commitments
+	// Transport configures the connections to the endpoints, including
+	// those of dedicated clients
+	Transport Transport
+	// ParseErrors keeps the token account data that failed to parse
+	ParseErrors *ParseErrorLog
+	// Mints caches the mint accounts looked up, see GetCachedMintInfo
+	Mints *MintCache
+	// Logger logs the failures the client skips over, the default logger
+	// when nil
+	Logger logging.Logger
+}
+
+// Log returns the logger of the client
+func (c *Client) Log() logging.Logger {
+	if c.Logger == nil {
+		return logging.Default()
+	}
+	return c.Logger
+}
+
+// Commitments are the commitment levels of the kinds of requests a client
+// makes, confirmed when empty
+type Commitments struct {
+	// Subscriptions covers token account and mint subscriptions
+	Subscriptions rpc.CommitmentType
+	// Polling covers account, balance and stake lookups
+	Polling rpc.CommitmentType
+	// Transactions covers transaction and signature lookups and the log
+	// subscriptions that lead to them. Processed isn't supported.
+	Transactions rpc.CommitmentType
+}
+
+// subscriptions returns the commitment of account subscriptions
+func (c *Client) subscriptions() rpc.CommitmentType {
+	return orConfirmed(c.Commitments.Subscriptions)
+}
+
+// polling returns the commitment of account lookups
+func (c *Client) polling() rpc.CommitmentType {
+	return orConfirmed(c.Commitments.Polling)
+}
+
+// transactions returns the commitment of transaction lookups
+func (c *Client) transactions() rpc.CommitmentType {
+	return orConfirmed(c.Commitments.Transactions)
+}
+
+// orConfirmed defaults an empty commitment to confirmed
+func orConfirmed(commitment rpc.CommitmentType) rpc.CommitmentType {
+	if commitment == "" {
+		return rpc.CommitmentConfirmed
+	}
+	return commitment
 }
 
 // TokenAccountInfo contains token account data
 type TokenAccountInfo struct {
-	Address       string
-	Owner         string
-	Mint          string
-	Balance       uint64
-	Decimals      uint8
-	ProgramID     string
-	LastUpdatedAt time.Time
+	Address   string `json:"address"`
+	Owner     string `json:"owner"`
+	Mint      string `json:"mint"`
+	Balance   uint64 `json:"balance"`
+	Decimals  uint8  `json:"decimals"`
+	ProgramID string `json:"program_id"`
+	Delegate  string `json:"delegate,omitempty"`
+	// DelegatedAmount is how much of the balance the delegate may still
+	// transfer, in base units
+	DelegatedAmount uint64 `json:"delegated_amount,omitempty"`
+	// CloseAuthority may close the account in place of the owner, when set
+	CloseAuthority string `json:"close_authority,omitempty"`
+	Frozen         bool   `json:"frozen,omitempty"`
+	// Slot is the slot the account data was observed at, used to order
+	// updates arriving from different sources
+	Slot uint64 `json:"slot,omitempty"`
+	// Signature is the transaction that caused the update, when it could be
+	// resolved
+	Signature     string    `json:"signature,omitempty"`
+	LastUpdatedAt time.Time `json:"last_updated_at"`
+	// Source is how the update arrived, one of the Source constants, when
+	// known. It isn't serialized.
+	Source string `json:"-"`
+}
+
+// Sources of token account updates
+const (
+	SourceWebSocket = "websocket"
+	SourceGeyser    = "geyser"
+	SourcePoll      = "poll"
+	SourceIngest    = "ingest"
+)
+
+// NewClient creates a new Solana client. ctx bounds the WebSocket
+// connection attempt.
+func NewClient(ctx context.Context, rpcEndpoint, wsEndpoint string) (*Client, error) {
+	return NewClientWithTransport(ctx, rpcEndpoint, wsEndpoint, Transport{})
 }
 
-// NewClient creates a new Solana client
-func NewClient(rpcEndpoint, wsEndpoint string) (*Client, error) {
-	rpcClient := rpc.New(rpcEndpoint)
+// NewClientWithTransport creates a new Solana client connecting to its
+// endpoints through transport
+func NewClientWithTransport(ctx context.Context, rpcEndpoint, wsEndpoint string, transport Transport) (*Client, error) {
+	rpcClient := transport.rpcClient(rpcEndpoint)
 
 	// Initialize the WebSocket client
-	wsClient, err := ws.Connect(context.Background(), wsEndpoint)
+	wsClient, err := transport.connect(ctx, wsEndpoint)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to WebSocket: %w", err)
+		return nil, err
 	}
 
 	return &Client{
@@ -46,6 +133,33 @@ func NewClient(rpcEndpoint, wsEndpoint string) (*Client, error) {
 		WSClient:    wsClient,
 		RPCEndpoint: rpcEndpoint,
 		WSEndpoint:  wsEndpoint,
+		Transport:   transport,
+		ParseErrors: &ParseErrorLog{},
+		Mints:       &MintCache{},
+	}, nil
+}
+
+// Dedicated returns a client with its own WebSocket connection, sharing the
+// RPC client, whose token account subscriptions use the given commitment.
+// Subscriptions on it don't queue behind those of other wallets.
+func (c *Client) Dedicated(ctx context.Context, commitment rpc.CommitmentType) (*Client, error) {
+	wsClient, err := c.Transport.connect(ctx, c.WSEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	commitments := c.Commitments
+	commitments.Subscriptions = commitment
+	return &Client{
+		RPCClient:   c.RPCClient,
+		WSClient:    wsClient,
+		RPCEndpoint: c.RPCEndpoint,
+		WSEndpoint:  c.WSEndpoint,
+		Commitments: commitments,
+		Transport:   c.Transport,
+		ParseErrors: c.ParseErrors,
+		Mints:       c.Mints,
+		Logger:      c.Logger,
 	}, nil
 }
 
@@ -56,58 +170,122 @@ func (c *Client) Close() {
 	}
 }
 
+// ValidateAddress checks that an address is a valid base58 public key
+func ValidateAddress(address string) error {
+	if _, err := solana.PublicKeyFromBase58(address); err != nil {
+		return invalidAddress("address", address, err)
+	}
+	return nil
+}
+
+// NativeMint is the wrapped SOL mint, which prices native SOL
+var NativeMint = solana.SolMint.String()
+
+// SystemProgram owns native SOL accounts such as wallets
+var SystemProgram = solana.SystemProgramID.String()
+
+// GetSOLBalance returns the native SOL balance of a wallet in lamports
+func (c *Client) GetSOLBalance(ctx context.Context, walletAddress string) (uint64, error) {
+	pubkey, err := solana.PublicKeyFromBase58(walletAddress)
+	if err != nil {
+		return 0, invalidAddress("wallet address", walletAddress, err)
+	}
+
+	res, err := c.RPCClient.GetBalance(ctx, pubkey, c.polling())
+	if err != nil {
+		return 0, rpcFailed("get balance", err)
+	}
+
+	return res.Value, nil
+}
+
 // GetTokenAccounts retrieves all SPL token accounts for a given wallet address
 func (c *Client) GetTokenAccounts(ctx context.Context, walletAddress string) ([]TokenAccountInfo, error) {
+	return c.getTokenAccounts(ctx, walletAddress, &rpc.GetTokenAccountsConfig{
+		ProgramId: solana.TokenProgramID.ToPointer(),
+	})
+}
+
+// GetTokenAccountsByMint retrieves the token accounts of a mint owned by a
+// wallet
+func (c *Client) GetTokenAccountsByMint(ctx context.Context, walletAddress, mintAddress string) ([]TokenAccountInfo, error) {
+	mint, err := solana.PublicKeyFromBase58(mintAddress)
+	if err != nil {
+		return nil, invalidAddress("mint address", mintAddress, err)
+	}
+	return c.getTokenAccounts(ctx, walletAddress, &rpc.GetTokenAccountsConfig{Mint: mint.ToPointer()})
+}
+
+// getTokenAccounts retrieves the token accounts of a wallet selected by
+// program or mint
+func (c *Client) getTokenAccounts(ctx context.Context, walletAddress string, config *rpc.GetTokenAccountsConfig) ([]TokenAccountInfo, error) {
 	// Parse the public key from string
 	pubkey, err := solana.PublicKeyFromBase58(walletAddress)
 	if err != nil {
-		return nil, fmt.Errorf("invalid wallet address: %w", err)
+		return nil, invalidAddress("wallet address", walletAddress, err)
 	}
 
 	// Request token accounts
 	res, err := c.RPCClient.GetTokenAccountsByOwner(
 		ctx,
 		pubkey,
-		&rpc.GetTokenAccountsByOwnerOpts{
-			ProgramId: solana.TokenProgramID.ToPointer(),
-		},
+		config,
 		&rpc.GetTokenAccountsOpts{
-			Encoding: rpc.AccountEncodingJSONParsed,
+			Commitment: c.polling(),
+			Encoding:   solana.EncodingJSONParsed,
 		},
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get token accounts: %w", err)
+		return nil, rpcFailed("get token accounts", err)
 	}
 
+	return c.parseTokenAccounts(ctx, res), nil
+}
+
+// parseTokenAccounts parses the token accounts of a lookup, recording and
+// skipping those that fail to parse
+func (c *Client) parseTokenAccounts(ctx context.Context, res *rpc.GetTokenAccountsResult) []TokenAccountInfo {
 	var accounts []TokenAccountInfo
 	for _, item := range res.Value {
 		// Parse account info
-		info, ok := item.Account.Data.GetJSONParsed().(*rpc.ParsedTokenAccount)
-		if !ok {
-			logrus.Warnf("Failed to parse token account data for %s", item.Pubkey)
+		tokenInfo, err := c.parseTokenAccount(ctx, item.Pubkey, &item.Account, res.Context.Slot)
+		if err != nil {
+			c.Log().Warn("Failed to parse token account data", "account", item.Pubkey, "error", err)
+			c.ParseErrors.record(item.Pubkey.String(), err)
 			continue
 		}
 
-		// Create token account info
-		tokenInfo := TokenAccountInfo{
-			Address:       item.Pubkey.String(),
-			Owner:         walletAddress,
-			Mint:          info.Data.Parsed.Info.Mint,
-			ProgramID:     info.Data.Program,
-			LastUpdatedAt: time.Now(),
-		}
+		accounts = append(accounts, *tokenInfo)
+	}
 
-		// Parse balance and decimals
-		tokenInfo.Balance = info.Data.Parsed.Info.TokenAmount.Amount.Uint64()
-		tokenInfo.Decimals = uint8(info.Data.Parsed.Info.TokenAmount.Decimals)
+	return accounts
+}
 
-		accounts = append(accounts, tokenInfo)
+// GetTokenAccount retrieves a token account by address. It returns nil when
+// the account doesn't exist.
+func (c *Client) GetTokenAccount(ctx context.Context, address string) (*TokenAccountInfo, error) {
+	pubkey, err := solana.PublicKeyFromBase58(address)
+	if err != nil {
+		return nil, invalidAddress("token account", address, err)
 	}
 
-	return accounts, nil
+	res, err := c.RPCClient.GetAccountInfoWithOpts(ctx, pubkey, &rpc.GetAccountInfoOpts{
+		Commitment: c.polling(),
+		Encoding:   solana.EncodingJSONParsed,
+	})
+	if errors.Is(err, rpc.ErrNotFound) || (err == nil && res.Value == nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, rpcFailed("get token account", err)
+	}
+	return c.parseTokenAccount(ctx, pubkey, res.Value, res.Context.Slot)
 }
 
-// SubscribeToTokenAccountUpdates subscribes to token account updates for a given wallet
+// SubscribeToTokenAccountUpdates subscribes to updates of the token accounts
+// owned by a wallet and calls callback for each. The subscription filters on
+// the owner, so every wallet only receives its own accounts. It blocks until
+// ctx is cancelled or the subscription fails.
 func (c *Client) SubscribeToTokenAccountUpdates(
 	ctx context.Context,
 	walletAddress string,
@@ -116,94 +294,115 @@ func (c *Client) SubscribeToTokenAccountUpdates(
 	// Parse the wallet address
 	pubkey, err := solana.PublicKeyFromBase58(walletAddress)
 	if err != nil {
-		return fmt.Errorf("invalid wallet address: %w", err)
+		return invalidAddress("wallet address", walletAddress, err)
 	}
 
-	// Subscribe to account updates using the WebSocket client
-	_, err = c.WSClient.ProgramSubscribe(
-		ctx,
+	// Token accounts store the owner after the mint, at offset 32
+	sub, err := c.WSClient.ProgramSubscribeWithOpts(
 		solana.TokenProgramID,
-		rpc.CommitmentConfirmed,
-		func(res ws.ProgramNotification) {
-			// The subscription callback will receive updates for all token program operations
-			// We need to filter for our wallet address
-
-			// Check if the update is for our wallet
-			accountInfo, err := parseTokenAccountFromSubscription(res, walletAddress)
-			if err != nil {
-				logrus.Warnf("Failed to parse token account update: %v", err)
-				return
-			}
-
-			// If we successfully parsed an account belonging to our wallet, call the callback
-			if accountInfo != nil {
-				callback(*accountInfo)
-			}
+		c.subscriptions(),
+		solana.EncodingJSONParsed,
+		[]rpc.RPCFilter{
+			{DataSize: tokenAccountSize},
+			{Memcmp: &rpc.RPCFilterMemcmp{Offset: 32, Bytes: pubkey[:]}},
 		},
 	)
-
 	if err != nil {
-		return fmt.Errorf("failed to subscribe to program updates: %w", err)
+		return rpcFailed("subscribe to program updates", err)
 	}
 
-	return nil
+	go func() {
+		<-ctx.Done()
+		sub.Unsubscribe()
+	}()
+
+	for {
+		res, err := sub.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return subscriptionLost("token account", err)
+		}
+
+		accountInfo, err := c.parseTokenAccount(ctx, res.Value.Pubkey, res.Value.Account, res.Context.Slot)
+		if err != nil {
+			c.Log().Warn("Failed to parse token account update", "error", err)
+			c.ParseErrors.record(res.Value.Pubkey.String(), err)
+			continue
+		}
+		accountInfo.Source = SourceWebSocket
+		callback(*accountInfo)
+	}
 }
 
-// parseTokenAccountFromSubscription parses token account info from WebSocket notification
-func parseTokenAccountFromSubscription(
-	notification ws.ProgramNotification,
-	walletAddress string,
-) (*TokenAccountInfo, error) {
-	// Skip notifications that are not related to token accounts
-	if notification.Result.Value.Account.Owner != solana.TokenProgramID.String() {
-		return nil, nil
+// parseTokenAccount parses a jsonParsed token account observed at a slot.
+// Decimals missing from the data are taken from the mint cache.
+func (c *Client) parseTokenAccount(ctx context.Context, address solana.PublicKey, account *rpc.Account, slot uint64) (*TokenAccountInfo, error) {
+	if account == nil || account.Data == nil {
+		return nil, fmt.Errorf("token account %s has no data", address)
 	}
 
-	// Attempt to parse the account data
 	var tokenAccount struct {
-		Owner string `json:"owner"`
-		Data  struct {
-			Parsed struct {
-				Type string `json:"type"`
-				Info struct {
-					Mint        string `json:"mint"`
-					Owner       string `json:"owner"`
-					TokenAmount struct {
-						Amount   string `json:"amount"`
-						Decimals uint8  `json:"decimals"`
-					} `json:"tokenAmount"`
-				} `json:"info"`
-			} `json:"parsed"`
-		} `json:"data"`
-	}
-
-	// Convert the account data to JSON and parse it
-	accountData, err := json.Marshal(notification.Result.Value.Account)
-	if err != nil {
-		return nil, err
+		Program string `json:"program"`
+		Parsed  struct {
+			Type string `json:"type"`
+			Info struct {
+				Mint           string `json:"mint"`
+				Owner          string `json:"owner"`
+				Delegate       string `json:"delegate"`
+				CloseAuthority string `json:"closeAuthority"`
+				State          string `json:"state"`
+				TokenAmount    struct {
+					Amount   string `json:"amount"`
+					Decimals *uint8 `json:"decimals"`
+				} `json:"tokenAmount"`
+				DelegatedAmount *struct {
+					Amount string `json:"amount"`
+				} `json:"delegatedAmount"`
+			} `json:"info"`
+		} `json:"parsed"`
+	}
+	if err := json.Unmarshal(account.Data.GetRawJSON(), &tokenAccount); err != nil {
+		return nil, fmt.Errorf("failed to decode token account %s: %w", address, err)
 	}
 
-	if err := json.Unmarshal(accountData, &tokenAccount); err != nil {
-		return nil, err
+	info := tokenAccount.Parsed.Info
+	amount, err := strconv.ParseUint(info.TokenAmount.Amount, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token amount: %s", info.TokenAmount.Amount)
 	}
 
-	// Check if this account belongs to our wallet
-	if tokenAccount.Data.Parsed.Info.Owner != walletAddress {
-		return nil, nil
+	var delegated uint64
+	if info.DelegatedAmount != nil {
+		if delegated, err = strconv.ParseUint(info.DelegatedAmount.Amount, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid delegated amount: %s", info.DelegatedAmount.Amount)
+		}
 	}
 
-	// Create token account info
-	amount, ok := new(solana.U64).SetString(tokenAccount.Data.Parsed.Info.TokenAmount.Amount)
-	if !ok {
-		return nil, fmt.Errorf("invalid token amount: %s", tokenAccount.Data.Parsed.Info.TokenAmount.Amount)
+	var decimals uint8
+	if info.TokenAmount.Decimals != nil {
+		decimals = *info.TokenAmount.Decimals
+	} else {
+		mint, err := c.GetCachedMintInfo(ctx, info.Mint)
+		if err != nil {
+			return nil, fmt.Errorf("token account %s has no decimals: %w", address, err)
+		}
+		decimals = mint.Decimals
 	}
 
 	return &TokenAccountInfo{
-		Address:       notification.Result.Value.Pubkey.String(),
-		Owner:         walletAddress,
-		Mint:          tokenAccount.Data.Parsed.Info.Mint,
-		Balance:       amount.Uint64(),
-		Decimals:      tokenAccount.Data.Parsed.Info.TokenAmount.Decimals,
-		LastUpdatedAt: time.Now(),
+		Address:         address.String(),
+		Owner:           info.Owner,
+		Mint:            info.Mint,
+		Balance:         amount,
+		Decimals:        decimals,
+		ProgramID:       account.Owner.String(),
+		Delegate:        info.Delegate,
+		DelegatedAmount: delegated,
+		CloseAuthority:  info.CloseAuthority,
+		Frozen:          info.State == "frozen",
+		Slot:            slot,
+		LastUpdatedAt:   time.Now(),
 	}, nil
 }
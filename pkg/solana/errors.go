@@ -0,0 +1,102 @@
+package solana
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+)
+
+// Error classes returned by the client. Test for them with errors.Is; the
+// underlying RPC error stays available through errors.As.
+var (
+	// ErrInvalidAddress is returned for addresses or signatures that are not
+	// valid base58
+	ErrInvalidAddress = errors.New("invalid address")
+	// ErrRateLimited is returned when the provider throttles requests
+	ErrRateLimited = errors.New("rate limited")
+	// ErrSubscriptionLost is returned when a WebSocket subscription ends
+	// before its context is cancelled
+	ErrSubscriptionLost = errors.New("subscription lost")
+	// ErrProviderUnsupported is returned when the provider doesn't implement
+	// a method, such as logsSubscribe on some RPC plans
+	ErrProviderUnsupported = errors.New("method not supported by provider")
+	// ErrDomainNotFound is returned for .sol domains that aren't registered
+	ErrDomainNotFound = errors.New("domain not found")
+)
+
+// methodNotFound is the JSON-RPC code for unknown methods
+const methodNotFound = -32601
+
+// Error is a client error of a known class
+type Error struct {
+	// Kind is one of the Err* classes
+	Kind error
+	// Err is the error with its context
+	Err error
+}
+
+// Error returns the message of the wrapped error
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the class of the error
+func (e *Error) Is(target error) bool {
+	return e.Kind == target
+}
+
+// invalidAddress reports an address that failed to parse
+func invalidAddress(what, address string, err error) error {
+	return &Error{Kind: ErrInvalidAddress, Err: fmt.Errorf("invalid %s %q: %w", what, address, err)}
+}
+
+// subscriptionLost reports a subscription that stopped delivering updates
+func subscriptionLost(what string, err error) error {
+	return &Error{Kind: ErrSubscriptionLost, Err: fmt.Errorf("%s subscription failed: %w", what, err)}
+}
+
+// rpcFailed wraps an RPC error, classifying throttling and unsupported
+// methods so callers can back off or fail over
+func rpcFailed(action string, err error) error {
+	wrapped := fmt.Errorf("failed to %s: %w", action, err)
+	if kind := classify(err); kind != nil {
+		return &Error{Kind: kind, Err: wrapped}
+	}
+	return wrapped
+}
+
+// classify returns the class of an RPC error, or nil if it has none
+func classify(err error) error {
+	var httpErr *jsonrpc.HTTPError
+	if errors.As(err, &httpErr) && httpErr.Code == http.StatusTooManyRequests {
+		return ErrRateLimited
+	}
+
+	var rpcErr *jsonrpc.RPCError
+	if errors.As(err, &rpcErr) {
+		switch rpcErr.Code {
+		case http.StatusTooManyRequests:
+			return ErrRateLimited
+		case methodNotFound:
+			return ErrProviderUnsupported
+		}
+	}
+
+	// WebSocket errors only carry the provider's message
+	message := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(message, "too many requests") || strings.Contains(message, "rate limit"):
+		return ErrRateLimited
+	case strings.Contains(message, "method not found") || strings.Contains(message, "not supported"):
+		return ErrProviderUnsupported
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package solana
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+)
+
+// MintInfo contains SPL token mint data
+type MintInfo struct {
+	Address         string `json:"address"`
+	Decimals        uint8  `json:"decimals"`
+	Supply          uint64 `json:"supply"`
+	MintAuthority   string `json:"mint_authority,omitempty"`
+	FreezeAuthority string `json:"freeze_authority,omitempty"`
+}
+
+// TokenMetadata contains the Metaplex metadata of a token mint
+type TokenMetadata struct {
+	Mint   string `json:"mint"`
+	Name   string `json:"name"`
+	Symbol string `json:"symbol"`
+	URI    string `json:"uri,omitempty"`
+}
+
+// metaplexMetadata mirrors the leading fields of a Metaplex metadata account
+type metaplexMetadata struct {
+	Key             uint8
+	UpdateAuthority solana.PublicKey
+	Mint            solana.PublicKey
+	Name            string
+	Symbol          string
+	URI             string
+}
+
+// GetMintInfo retrieves the mint account of a token
+func (c *Client) GetMintInfo(ctx context.Context, mintAddress string) (*MintInfo, error) {
+	pubkey, err := solana.PublicKeyFromBase58(mintAddress)
+	if err != nil {
+		return nil, invalidAddress("mint address", mintAddress, err)
+	}
+
+	var mint token.Mint
+	if err := c.RPCClient.GetAccountDataInto(ctx, pubkey, &mint); err != nil {
+		return nil, rpcFailed("get mint account", err)
+	}
+
+	info := &MintInfo{
+		Address:  mintAddress,
+		Decimals: mint.Decimals,
+		Supply:   mint.Supply,
+	}
+	if mint.MintAuthority != nil {
+		info.MintAuthority = mint.MintAuthority.String()
+	}
+	if mint.FreezeAuthority != nil {
+		info.FreezeAuthority = mint.FreezeAuthority.String()
+	}
+
+	return info, nil
+}
+
+// GetTokenMetadata retrieves the Metaplex metadata of a token mint
+func (c *Client) GetTokenMetadata(ctx context.Context, mintAddress string) (*TokenMetadata, error) {
+	pubkey, err := solana.PublicKeyFromBase58(mintAddress)
+	if err != nil {
+		return nil, invalidAddress("mint address", mintAddress, err)
+	}
+
+	metadataAddress, _, err := solana.FindTokenMetadataAddress(pubkey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive metadata address: %w", err)
+	}
+
+	var metadata metaplexMetadata
+	if err := c.RPCClient.GetAccountDataBorshInto(ctx, metadataAddress, &metadata); err != nil {
+		return nil, rpcFailed("get token metadata", err)
+	}
+
+	// Metaplex pads strings with null bytes
+	return &TokenMetadata{
+		Mint:   mintAddress,
+		Name:   strings.TrimRight(metadata.Name, "\x00"),
+		Symbol: strings.TrimRight(metadata.Symbol, "\x00"),
+		URI:    strings.TrimRight(metadata.URI, "\x00"),
+	}, nil
+}
@@ -0,0 +1,72 @@
+package solana
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// mintRefreshInterval is how long a cached mint is served before it is
+// fetched again. Decimals never change, but supply and authorities do.
+const mintRefreshInterval = 10 * time.Minute
+
+// cachedMint is a mint account and when it was fetched
+type cachedMint struct {
+	info      MintInfo
+	fetchedAt time.Time
+}
+
+// MintCache keeps the mint accounts a client and the clients derived from it
+// looked up, so decimals are known for account updates that lack them
+// without a request per update
+type MintCache struct {
+	mints map[string]cachedMint
+	mutex sync.RWMutex
+}
+
+// get returns a cached mint and whether it is due for a refresh
+func (m *MintCache) get(mint string) (MintInfo, bool, bool) {
+	if m == nil {
+		return MintInfo{}, false, false
+	}
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	cached, ok := m.mints[mint]
+	return cached.info, ok, ok && time.Since(cached.fetchedAt) >= mintRefreshInterval
+}
+
+// put caches a fetched mint
+func (m *MintCache) put(info MintInfo) {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.mints == nil {
+		m.mints = make(map[string]cachedMint)
+	}
+	m.mints[info.Address] = cachedMint{info: info, fetchedAt: time.Now()}
+}
+
+// GetCachedMintInfo returns the mint account of a token from the mint cache,
+// fetching it on first use and again once it is older than ten minutes. A
+// failed refresh serves the cached mint.
+func (c *Client) GetCachedMintInfo(ctx context.Context, mintAddress string) (*MintInfo, error) {
+	cached, ok, stale := c.Mints.get(mintAddress)
+	if ok && !stale {
+		return &cached, nil
+	}
+
+	info, err := c.GetMintInfo(ctx, mintAddress)
+	if err != nil {
+		if ok {
+			c.Log().Debug("Failed to refresh mint, using cached", "mint", mintAddress, "error", err)
+			return &cached, nil
+		}
+		return nil, err
+	}
+	c.Mints.put(*info)
+	return info, nil
+}
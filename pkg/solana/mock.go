@@ -0,0 +1,168 @@
+package solana
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+var _ DataSource = (*MockClient)(nil)
+
+// MockClient is an in-memory DataSource for tests. Token accounts and
+// transactions are set up front, and updates pushed to it are delivered to
+// the subscriptions of their owner before Push returns.
+type MockClient struct {
+	mu           sync.Mutex
+	subscribed   *sync.Cond
+	accounts     map[string]map[string]TokenAccountInfo
+	transactions map[string][]SignatureInfo
+	subscribers  map[string]map[int]func(TokenAccountInfo)
+	logs         map[string]map[int]func(LogNotification)
+	nextID       int
+}
+
+// NewMockClient creates an empty mock client
+func NewMockClient() *MockClient {
+	c := &MockClient{
+		accounts:     make(map[string]map[string]TokenAccountInfo),
+		transactions: make(map[string][]SignatureInfo),
+		subscribers:  make(map[string]map[int]func(TokenAccountInfo)),
+		logs:         make(map[string]map[int]func(LogNotification)),
+	}
+	c.subscribed = sync.NewCond(&c.mu)
+	return c
+}
+
+// SetTokenAccounts sets the token accounts a wallet holds, without notifying
+// its subscriptions
+func (c *MockClient) SetTokenAccounts(wallet string, accounts ...TokenAccountInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	held := make(map[string]TokenAccountInfo, len(accounts))
+	for _, account := range accounts {
+		held[account.Address] = account
+	}
+	c.accounts[wallet] = held
+}
+
+// Push stores an update of a token account of its owner and delivers it to
+// the subscriptions of the owner
+func (c *MockClient) Push(account TokenAccountInfo) {
+	c.mu.Lock()
+	if c.accounts[account.Owner] == nil {
+		c.accounts[account.Owner] = make(map[string]TokenAccountInfo)
+	}
+	c.accounts[account.Owner][account.Address] = account
+	callbacks := make([]func(TokenAccountInfo), 0, len(c.subscribers[account.Owner]))
+	for _, callback := range c.subscribers[account.Owner] {
+		callbacks = append(callbacks, callback)
+	}
+	c.mu.Unlock()
+
+	for _, callback := range callbacks {
+		callback(account)
+	}
+}
+
+// AddTransaction records a transaction as the newest of an address and
+// delivers it to the log subscriptions of the address
+func (c *MockClient) AddTransaction(address string, signature SignatureInfo) {
+	c.mu.Lock()
+	c.transactions[address] = append([]SignatureInfo{signature}, c.transactions[address]...)
+	callbacks := make([]func(LogNotification), 0, len(c.logs[address]))
+	for _, callback := range c.logs[address] {
+		callbacks = append(callbacks, callback)
+	}
+	c.mu.Unlock()
+
+	for _, callback := range callbacks {
+		callback(LogNotification{
+			Signature: signature.Signature,
+			Slot:      signature.Slot,
+			Failed:    signature.Failed,
+		})
+	}
+}
+
+// WaitForSubscription blocks until a wallet has a token account or log
+// subscription
+func (c *MockClient) WaitForSubscription(wallet string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.subscribers[wallet]) == 0 && len(c.logs[wallet]) == 0 {
+		c.subscribed.Wait()
+	}
+}
+
+// GetTokenAccounts returns the token accounts of a wallet ordered by address
+func (c *MockClient) GetTokenAccounts(ctx context.Context, wallet string) ([]TokenAccountInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	accounts := make([]TokenAccountInfo, 0, len(c.accounts[wallet]))
+	for _, account := range c.accounts[wallet] {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].Address < accounts[j].Address
+	})
+	return accounts, nil
+}
+
+// GetTransactions returns the latest transactions recorded for an address
+func (c *MockClient) GetTransactions(ctx context.Context, address string, limit int) ([]SignatureInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	signatures := c.transactions[address]
+	if limit > 0 && len(signatures) > limit {
+		signatures = signatures[:limit]
+	}
+	return append([]SignatureInfo(nil), signatures...), nil
+}
+
+// Subscribe delivers the updates pushed for a wallet until ctx is cancelled
+func (c *MockClient) Subscribe(ctx context.Context, wallet string, callback func(TokenAccountInfo)) error {
+	c.mu.Lock()
+	if c.subscribers[wallet] == nil {
+		c.subscribers[wallet] = make(map[int]func(TokenAccountInfo))
+	}
+	id := c.register()
+	c.subscribers[wallet][id] = callback
+	c.mu.Unlock()
+
+	<-ctx.Done()
+
+	c.mu.Lock()
+	delete(c.subscribers[wallet], id)
+	c.mu.Unlock()
+	return nil
+}
+
+// SubscribeToWalletLogs delivers the transactions added for a wallet until
+// ctx is cancelled
+func (c *MockClient) SubscribeToWalletLogs(ctx context.Context, wallet string, callback func(LogNotification)) error {
+	c.mu.Lock()
+	if c.logs[wallet] == nil {
+		c.logs[wallet] = make(map[int]func(LogNotification))
+	}
+	id := c.register()
+	c.logs[wallet][id] = callback
+	c.mu.Unlock()
+
+	<-ctx.Done()
+
+	c.mu.Lock()
+	delete(c.logs[wallet], id)
+	c.mu.Unlock()
+	return nil
+}
+
+// register allocates the ID of a subscription and wakes up waiters. Callers
+// must hold the lock.
+func (c *MockClient) register() int {
+	c.nextID++
+	c.subscribed.Broadcast()
+	return c.nextID
+}
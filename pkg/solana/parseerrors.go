@@ -0,0 +1,54 @@
+package solana
+
+import (
+	"sync"
+	"time"
+)
+
+// maxParseErrors is how many parse errors a log keeps
+const maxParseErrors = 100
+
+// ParseError is token account data that couldn't be parsed
+type ParseError struct {
+	Account string    `json:"account"`
+	Error   string    `json:"error"`
+	At      time.Time `json:"at"`
+}
+
+// ParseErrorLog keeps the latest parse errors of a client and the clients
+// derived from it, for debugging accounts that stop updating
+type ParseErrorLog struct {
+	errors []ParseError
+	mutex  sync.Mutex
+}
+
+// record adds a parse error, dropping the oldest beyond the limit
+func (l *ParseErrorLog) record(account string, err error) {
+	if l == nil {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.errors = append(l.errors, ParseError{Account: account, Error: err.Error(), At: time.Now()})
+	if len(l.errors) > maxParseErrors {
+		l.errors = l.errors[len(l.errors)-maxParseErrors:]
+	}
+}
+
+// Recent returns the kept parse errors of an account, oldest first
+func (l *ParseErrorLog) Recent(account string) []ParseError {
+	if l == nil {
+		return nil
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var errors []ParseError
+	for _, parseError := range l.errors {
+		if parseError.Account == account {
+			errors = append(errors, parseError)
+		}
+	}
+	return errors
+}
@@ -0,0 +1,137 @@
+package solana
+
+import (
+	"encoding/binary"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Instructions that move account rent
+const (
+	systemCreateAccount         = 0
+	systemCreateAccountWithSeed = 3
+	tokenCloseAccount           = 9
+)
+
+// wrappedSOLMint is the mint of wrapped SOL, whose token accounts hold the
+// wrapped amount in lamports on top of their rent
+var wrappedSOLMint = solana.WrappedSol.String()
+
+// RentChange is rent a wallet paid to create an account, or reclaimed by
+// closing one
+type RentChange struct {
+	Account string `json:"account"`
+	// Wallet funded the account, or received its lamports when it was closed
+	Wallet    string `json:"wallet"`
+	Lamports  uint64 `json:"lamports"`
+	Reclaimed bool   `json:"reclaimed,omitempty"`
+}
+
+// MentionsRent reports whether transaction logs show an invocation of the
+// system program or a token account being closed, which may move rent
+func MentionsRent(logs []string) bool {
+	for _, line := range logs {
+		if strings.HasPrefix(line, "Program "+SystemProgramID+" invoke") || strings.HasSuffix(line, "Instruction: CloseAccount") {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseRentChanges finds the accounts a transaction created with the system
+// program and the token accounts it closed. Accounts created and closed by
+// the same transaction, such as temporary wrapped SOL accounts, hold no rent
+// afterwards and are left out.
+func ParseRentChanges(tx *Transaction) []RentChange {
+	if tx.Failed {
+		return nil
+	}
+
+	var changes []RentChange
+	created := make(map[string]bool)
+	closed := make(map[string]bool)
+	for _, ix := range tx.Instructions {
+		switch ix.ProgramID {
+		case SystemProgramID:
+			change, ok := parseCreateAccount(ix)
+			if ok {
+				changes = append(changes, change)
+				created[change.Account] = true
+			}
+		case TokenProgramID, Token2022ProgramID:
+			if len(ix.Data) == 0 || ix.Data[0] != tokenCloseAccount || len(ix.Accounts) < 2 {
+				continue
+			}
+			change := RentChange{
+				Account:   ix.Accounts[0],
+				Wallet:    ix.Accounts[1],
+				Lamports:  tx.closedRent(ix.Accounts[0]),
+				Reclaimed: true,
+			}
+			changes = append(changes, change)
+			closed[change.Account] = true
+		}
+	}
+
+	result := changes[:0]
+	for _, change := range changes {
+		if !(created[change.Account] && closed[change.Account]) && change.Lamports > 0 {
+			result = append(result, change)
+		}
+	}
+	return result
+}
+
+// parseCreateAccount decodes the funder, new account and lamports of a system
+// CreateAccount or CreateAccountWithSeed instruction
+func parseCreateAccount(ix Instruction) (RentChange, bool) {
+	if len(ix.Data) < 4 || len(ix.Accounts) < 2 {
+		return RentChange{}, false
+	}
+
+	var offset int
+	switch binary.LittleEndian.Uint32(ix.Data) {
+	case systemCreateAccount:
+		offset = 4
+	case systemCreateAccountWithSeed:
+		// The base key and the length prefixed seed come first
+		if len(ix.Data) < 44 {
+			return RentChange{}, false
+		}
+		seedLength := binary.LittleEndian.Uint64(ix.Data[36:])
+		if seedLength > uint64(len(ix.Data)) {
+			return RentChange{}, false
+		}
+		offset = 44 + int(seedLength)
+	default:
+		return RentChange{}, false
+	}
+	if len(ix.Data) < offset+8 {
+		return RentChange{}, false
+	}
+
+	return RentChange{
+		Account:  ix.Accounts[1],
+		Wallet:   ix.Accounts[0],
+		Lamports: binary.LittleEndian.Uint64(ix.Data[offset:]),
+	}, true
+}
+
+// closedRent returns the rent a closed token account held before the
+// transaction, leaving out the SOL of wrapped SOL accounts
+func (t *Transaction) closedRent(address string) uint64 {
+	var lamports uint64
+	for i, key := range t.AccountKeys {
+		if key == address && i < len(t.PreBalances) {
+			lamports = t.PreBalances[i]
+			break
+		}
+	}
+	for _, change := range t.TokenBalances {
+		if change.Account == address && change.Mint == wrappedSOLMint && change.Pre <= lamports {
+			lamports -= change.Pre
+		}
+	}
+	return lamports
+}
@@ -0,0 +1,84 @@
+package solana
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Solana Name Service accounts
+var (
+	nameServiceProgramID = solana.MustPublicKeyFromBase58("namesLPneVptA9Z5rqUDD9tMTWEJwofgaYwp8cawRkX")
+	// solTLD is the parent of every .sol domain
+	solTLD = solana.MustPublicKeyFromBase58("58PwtjSDuFHuUkYjH9BYnnQKHfwo9reZhC2zMJv9JPkx")
+)
+
+// nameHashPrefix is prepended to names before hashing them into seeds
+const nameHashPrefix = "SPL Name Service"
+
+// nameHeaderSize is the size of the parent, owner and class keys that start
+// every name account
+const nameHeaderSize = 96
+
+// ResolveDomain returns the wallet owning a .sol domain, such as
+// "bonfida.sol" or "dex.bonfida.sol", as registered in the Solana Name
+// Service. Domains wrapped as NFTs resolve to the wrapping escrow.
+func (c *Client) ResolveDomain(ctx context.Context, domain string) (string, error) {
+	key, err := domainKey(domain)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := c.RPCClient.GetAccountInfoWithOpts(ctx, key, &rpc.GetAccountInfoOpts{
+		Commitment: c.polling(),
+	})
+	if errors.Is(err, rpc.ErrNotFound) || (err == nil && (res.Value == nil || res.Value.Owner != nameServiceProgramID)) {
+		return "", &Error{Kind: ErrDomainNotFound, Err: fmt.Errorf("domain %s is not registered", domain)}
+	}
+	if err != nil {
+		return "", rpcFailed("get domain account", err)
+	}
+
+	data := res.Value.Data.GetBinary()
+	if len(data) < nameHeaderSize {
+		return "", fmt.Errorf("domain account of %s is too short", domain)
+	}
+	return solana.PublicKeyFromBytes(data[32:64]).String(), nil
+}
+
+// domainKey derives the name account of a domain. Each label is a child of
+// the one to its right; subdomain labels are hashed with a leading zero
+// byte.
+func domainKey(domain string) (solana.PublicKey, error) {
+	labels := strings.Split(strings.TrimSuffix(strings.ToLower(domain), ".sol"), ".")
+	if len(labels) > 2 {
+		return solana.PublicKey{}, fmt.Errorf("domain %s is nested too deep", domain)
+	}
+
+	parent := solTLD
+	for i := len(labels) - 1; i >= 0; i-- {
+		name := labels[i]
+		if i < len(labels)-1 {
+			name = "\x00" + name
+		}
+		key, err := nameKey(name, parent)
+		if err != nil {
+			return solana.PublicKey{}, fmt.Errorf("failed to derive the account of %s: %w", domain, err)
+		}
+		parent = key
+	}
+	return parent, nil
+}
+
+// nameKey derives the name account of a name under a parent
+func nameKey(name string, parent solana.PublicKey) (solana.PublicKey, error) {
+	hashed := sha256.Sum256([]byte(nameHashPrefix + name))
+	var class solana.PublicKey
+	key, _, err := solana.FindProgramAddress([][]byte{hashed[:], class[:], parent[:]}, nameServiceProgramID)
+	return key, err
+}
@@ -0,0 +1,116 @@
+package solana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// DataSource provides the token accounts of wallets, their updates and the
+// transactions of addresses. The Client reads them over RPC and WebSocket;
+// other sources, such as Geyser streams, can replace it or be combined with
+// it through Combine.
+type DataSource interface {
+	// GetTokenAccounts returns the token accounts owned by a wallet
+	GetTokenAccounts(ctx context.Context, wallet string) ([]TokenAccountInfo, error)
+	// Subscribe calls callback with the updates of the token accounts owned
+	// by a wallet until ctx is cancelled, which returns nil, or the
+	// subscription fails. Sources may also report the lamports of the
+	// wallet, as an update with the wallet as its address and the native
+	// mint.
+	Subscribe(ctx context.Context, wallet string, callback func(TokenAccountInfo)) error
+	// GetTransactions returns the latest transactions mentioning an address,
+	// newest first
+	GetTransactions(ctx context.Context, address string, limit int) ([]SignatureInfo, error)
+}
+
+var _ DataSource = (*Client)(nil)
+
+// Subscribe follows the token accounts of a wallet through a program
+// subscription, see SubscribeToTokenAccountUpdates
+func (c *Client) Subscribe(ctx context.Context, wallet string, callback func(TokenAccountInfo)) error {
+	return c.SubscribeToTokenAccountUpdates(ctx, wallet, callback)
+}
+
+// GetTransactions returns the latest signatures of an address, see
+// GetRecentSignatures
+func (c *Client) GetTransactions(ctx context.Context, address string, limit int) ([]SignatureInfo, error) {
+	return c.GetRecentSignatures(ctx, address, limit)
+}
+
+// Unsupported reports an operation a data source doesn't provide. Combined
+// sources skip sources failing with it.
+func Unsupported(source, operation string) error {
+	return &Error{Kind: ErrProviderUnsupported, Err: fmt.Errorf("%s doesn't provide %s", source, operation)}
+}
+
+// combined is a DataSource merging several sources
+type combined []DataSource
+
+// Combine merges data sources. Lookups go to the first source that provides
+// them; subscriptions run on every source that provides them at once, so a
+// Geyser stream can back up WebSocket subscriptions. The callback of such
+// subscriptions may be called concurrently.
+func Combine(sources ...DataSource) DataSource {
+	return combined(sources)
+}
+
+// GetTokenAccounts asks the first source providing token account lookups
+func (c combined) GetTokenAccounts(ctx context.Context, wallet string) ([]TokenAccountInfo, error) {
+	for _, source := range c {
+		accounts, err := source.GetTokenAccounts(ctx, wallet)
+		if !errors.Is(err, ErrProviderUnsupported) {
+			return accounts, err
+		}
+	}
+	return nil, Unsupported("combined source", "token account lookups")
+}
+
+// GetTransactions asks the first source providing transaction lookups
+func (c combined) GetTransactions(ctx context.Context, address string, limit int) ([]SignatureInfo, error) {
+	for _, source := range c {
+		signatures, err := source.GetTransactions(ctx, address, limit)
+		if !errors.Is(err, ErrProviderUnsupported) {
+			return signatures, err
+		}
+	}
+	return nil, Unsupported("combined source", "transaction lookups")
+}
+
+// Subscribe subscribes on every source until ctx is cancelled or one of the
+// subscriptions fails, which stops the others
+func (c combined) Subscribe(ctx context.Context, wallet string, callback func(TokenAccountInfo)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, len(c))
+	for _, source := range c {
+		source := source
+		go func() {
+			errs <- source.Subscribe(ctx, wallet, callback)
+		}()
+	}
+
+	var unsupported int
+	for range c {
+		err := <-errs
+		if errors.Is(err, ErrProviderUnsupported) {
+			unsupported++
+			continue
+		}
+		if ctx.Err() != nil {
+			continue
+		}
+		// A subscription ending before ctx is cancelled failed, even
+		// without an error
+		if err == nil {
+			err = subscriptionLost("combined", errors.New("subscription ended"))
+		}
+		cancel()
+		return err
+	}
+	if unsupported == len(c) {
+		return Unsupported("combined source", "subscriptions")
+	}
+	return nil
+}
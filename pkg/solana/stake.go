@@ -0,0 +1,195 @@
+package solana
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Stake account layout offsets
+const (
+	stakeStakerOffset     = 12
+	stakeWithdrawerOffset = 44
+	stakeDelegationOffset = 124
+	stakeAccountMinSize   = 196
+)
+
+// Stake account states as stored on chain
+const (
+	stakeStateInitialized = 1
+	stakeStateDelegated   = 2
+)
+
+// StakeProgramID is the address of the native stake program
+var StakeProgramID = solana.StakeProgramID.String()
+
+// Stake activation statuses
+const (
+	StakeInactive     = "inactive"
+	StakeActivating   = "activating"
+	StakeActive       = "active"
+	StakeDeactivating = "deactivating"
+)
+
+// StakeAccountInfo contains stake account data
+type StakeAccountInfo struct {
+	Address           string    `json:"address"`
+	Staker            string    `json:"staker"`
+	Withdrawer        string    `json:"withdrawer"`
+	Lamports          uint64    `json:"lamports"`
+	Voter             string    `json:"voter,omitempty"`
+	DelegatedStake    uint64    `json:"delegated_stake"`
+	ActivationEpoch   uint64    `json:"activation_epoch"`
+	DeactivationEpoch uint64    `json:"deactivation_epoch"`
+	Status            string    `json:"status"`
+	LastUpdatedAt     time.Time `json:"last_updated_at"`
+}
+
+// StakeReward is an inflation reward credited to a stake account
+type StakeReward struct {
+	Address     string `json:"address"`
+	Epoch       uint64 `json:"epoch"`
+	Amount      uint64 `json:"amount"`
+	PostBalance uint64 `json:"post_balance"`
+}
+
+// GetEpoch returns the current epoch
+func (c *Client) GetEpoch(ctx context.Context) (uint64, error) {
+	info, err := c.RPCClient.GetEpochInfo(ctx, c.polling())
+	if err != nil {
+		return 0, rpcFailed("get epoch info", err)
+	}
+	return info.Epoch, nil
+}
+
+// GetStakeAccounts retrieves stake accounts where the wallet is the staker or
+// withdrawer authority
+func (c *Client) GetStakeAccounts(ctx context.Context, walletAddress string, epoch uint64) ([]StakeAccountInfo, error) {
+	pubkey, err := solana.PublicKeyFromBase58(walletAddress)
+	if err != nil {
+		return nil, invalidAddress("wallet address", walletAddress, err)
+	}
+
+	seen := make(map[string]bool)
+	var accounts []StakeAccountInfo
+	for _, offset := range []uint64{stakeStakerOffset, stakeWithdrawerOffset} {
+		res, err := c.RPCClient.GetProgramAccountsWithOpts(ctx, solana.StakeProgramID, &rpc.GetProgramAccountsOpts{
+			Commitment: c.polling(),
+			Encoding:   solana.EncodingBase64,
+			Filters: []rpc.RPCFilter{
+				{Memcmp: &rpc.RPCFilterMemcmp{Offset: offset, Bytes: pubkey[:]}},
+			},
+		})
+		if err != nil {
+			return nil, rpcFailed("get stake accounts", err)
+		}
+
+		for _, item := range res {
+			address := item.Pubkey.String()
+			if seen[address] {
+				continue
+			}
+			seen[address] = true
+
+			info, err := decodeStakeAccount(address, item.Account.Lamports, item.Account.Data.GetBinary(), epoch)
+			if err != nil {
+				return nil, err
+			}
+			accounts = append(accounts, *info)
+		}
+	}
+
+	return accounts, nil
+}
+
+// GetStakeRewards retrieves the inflation rewards credited to stake accounts in an epoch
+func (c *Client) GetStakeRewards(ctx context.Context, addresses []string, epoch uint64) ([]StakeReward, error) {
+	pubkeys := make([]solana.PublicKey, 0, len(addresses))
+	for _, address := range addresses {
+		pubkey, err := solana.PublicKeyFromBase58(address)
+		if err != nil {
+			return nil, invalidAddress("stake address", address, err)
+		}
+		pubkeys = append(pubkeys, pubkey)
+	}
+
+	res, err := c.RPCClient.GetInflationReward(ctx, pubkeys, &rpc.GetInflationRewardOpts{
+		Commitment: rpc.CommitmentFinalized,
+		Epoch:      &epoch,
+	})
+	if err != nil {
+		return nil, rpcFailed("get inflation rewards", err)
+	}
+
+	var rewards []StakeReward
+	for i, reward := range res {
+		if reward == nil || reward.Amount == 0 {
+			continue
+		}
+		rewards = append(rewards, StakeReward{
+			Address:     addresses[i],
+			Epoch:       reward.Epoch,
+			Amount:      reward.Amount,
+			PostBalance: reward.PostBalance,
+		})
+	}
+
+	return rewards, nil
+}
+
+// decodeStakeAccount decodes raw stake account data
+func decodeStakeAccount(address string, lamports uint64, data []byte, epoch uint64) (*StakeAccountInfo, error) {
+	if len(data) < stakeDelegationOffset {
+		return nil, fmt.Errorf("stake account %s is too short: %d bytes", address, len(data))
+	}
+
+	info := &StakeAccountInfo{
+		Address:           address,
+		Staker:            solana.PublicKeyFromBytes(data[stakeStakerOffset : stakeStakerOffset+32]).String(),
+		Withdrawer:        solana.PublicKeyFromBytes(data[stakeWithdrawerOffset : stakeWithdrawerOffset+32]).String(),
+		Lamports:          lamports,
+		DeactivationEpoch: math.MaxUint64,
+		Status:            StakeInactive,
+		LastUpdatedAt:     time.Now(),
+	}
+
+	state := binary.LittleEndian.Uint32(data[0:4])
+	if state != stakeStateDelegated {
+		if state != stakeStateInitialized {
+			return nil, fmt.Errorf("stake account %s is not initialized", address)
+		}
+		return info, nil
+	}
+	if len(data) < stakeAccountMinSize {
+		return nil, fmt.Errorf("stake account %s is too short: %d bytes", address, len(data))
+	}
+
+	delegation := data[stakeDelegationOffset:]
+	info.Voter = solana.PublicKeyFromBytes(delegation[0:32]).String()
+	info.DelegatedStake = binary.LittleEndian.Uint64(delegation[32:40])
+	info.ActivationEpoch = binary.LittleEndian.Uint64(delegation[40:48])
+	info.DeactivationEpoch = binary.LittleEndian.Uint64(delegation[48:56])
+	info.Status = stakeStatus(info.ActivationEpoch, info.DeactivationEpoch, epoch)
+
+	return info, nil
+}
+
+// stakeStatus derives the activation status of a delegation at an epoch,
+// ignoring partial warmup and cooldown
+func stakeStatus(activationEpoch, deactivationEpoch, epoch uint64) string {
+	switch {
+	case deactivationEpoch != math.MaxUint64 && epoch > deactivationEpoch:
+		return StakeInactive
+	case deactivationEpoch != math.MaxUint64:
+		return StakeDeactivating
+	case activationEpoch >= epoch && activationEpoch != math.MaxUint64:
+		return StakeActivating
+	default:
+		return StakeActive
+	}
+}
@@ -0,0 +1,161 @@
+package solana
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// tokenAccountSize is the size of an SPL token account
+const tokenAccountSize = 165
+
+// decodeTokenAccount decodes raw SPL token account data
+func decodeTokenAccount(address solana.PublicKey, data []byte, decimals uint8) (*TokenAccountInfo, error) {
+	var account token.Account
+	if err := bin.NewBinDecoder(data).Decode(&account); err != nil {
+		return nil, fmt.Errorf("failed to decode token account %s: %w", address, err)
+	}
+
+	info := &TokenAccountInfo{
+		Address:       address.String(),
+		Owner:         account.Owner.String(),
+		Mint:          account.Mint.String(),
+		Balance:       account.Amount,
+		Decimals:      decimals,
+		ProgramID:     solana.TokenProgramID.String(),
+		Frozen:        account.State == token.Frozen,
+		LastUpdatedAt: time.Now(),
+	}
+	if account.Delegate != nil {
+		info.Delegate = account.Delegate.String()
+		info.DelegatedAmount = account.DelegatedAmount
+	}
+	if account.CloseAuthority != nil {
+		info.CloseAuthority = account.CloseAuthority.String()
+	}
+
+	return info, nil
+}
+
+// DecodeTokenAccount decodes the raw data of an SPL token account received
+// from another source, such as a Geyser stream, taking its decimals from the
+// mint cache
+func (c *Client) DecodeTokenAccount(ctx context.Context, address string, data []byte) (*TokenAccountInfo, error) {
+	pubkey, err := solana.PublicKeyFromBase58(address)
+	if err != nil {
+		return nil, invalidAddress("token account address", address, err)
+	}
+	if len(data) != tokenAccountSize {
+		return nil, fmt.Errorf("token account %s has %d bytes of data", address, len(data))
+	}
+
+	// The mint is the first field of a token account
+	mint := solana.PublicKeyFromBytes(data[:32]).String()
+	info, err := c.GetCachedMintInfo(ctx, mint)
+	if err != nil {
+		return nil, fmt.Errorf("token account %s has no decimals: %w", address, err)
+	}
+	return decodeTokenAccount(pubkey, data, info.Decimals)
+}
+
+// GetLargestTokenAccounts retrieves the largest token accounts of a mint
+func (c *Client) GetLargestTokenAccounts(ctx context.Context, mintAddress string) ([]TokenAccountInfo, error) {
+	mint, err := solana.PublicKeyFromBase58(mintAddress)
+	if err != nil {
+		return nil, invalidAddress("mint address", mintAddress, err)
+	}
+
+	largest, err := c.RPCClient.GetTokenLargestAccounts(ctx, mint, c.polling())
+	if err != nil {
+		return nil, rpcFailed("get largest token accounts", err)
+	}
+	if len(largest.Value) == 0 {
+		return nil, nil
+	}
+
+	addresses := make([]solana.PublicKey, 0, len(largest.Value))
+	for _, item := range largest.Value {
+		addresses = append(addresses, item.Address)
+	}
+	decimals := largest.Value[0].Decimals
+
+	res, err := c.RPCClient.GetMultipleAccountsWithOpts(ctx, addresses, &rpc.GetMultipleAccountsOpts{
+		Encoding:   solana.EncodingBase64,
+		Commitment: c.polling(),
+	})
+	if err != nil {
+		return nil, rpcFailed("get token accounts", err)
+	}
+
+	var accounts []TokenAccountInfo
+	for i, account := range res.Value {
+		if account == nil {
+			continue
+		}
+		info, err := decodeTokenAccount(addresses[i], account.Data.GetBinary(), decimals)
+		if err != nil {
+			return nil, err
+		}
+		info.Slot = res.Context.Slot
+		accounts = append(accounts, *info)
+	}
+
+	return accounts, nil
+}
+
+// SubscribeToMintAccounts subscribes to every token account of a mint and
+// calls callback for each update. It blocks until ctx is cancelled or the
+// subscription fails.
+func (c *Client) SubscribeToMintAccounts(
+	ctx context.Context,
+	mintAddress string,
+	decimals uint8,
+	callback func(TokenAccountInfo),
+) error {
+	mint, err := solana.PublicKeyFromBase58(mintAddress)
+	if err != nil {
+		return invalidAddress("mint address", mintAddress, err)
+	}
+
+	// Token accounts start with the mint, so a memcmp at offset 0 selects
+	// every holder of this mint
+	sub, err := c.WSClient.ProgramSubscribeWithOpts(
+		solana.TokenProgramID,
+		c.subscriptions(),
+		solana.EncodingBase64,
+		[]rpc.RPCFilter{
+			{DataSize: tokenAccountSize},
+			{Memcmp: &rpc.RPCFilterMemcmp{Offset: 0, Bytes: mint[:]}},
+		},
+	)
+	if err != nil {
+		return rpcFailed("subscribe to mint accounts", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		sub.Unsubscribe()
+	}()
+
+	for {
+		res, err := sub.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return subscriptionLost("mint", err)
+		}
+
+		info, err := decodeTokenAccount(res.Value.Pubkey, res.Value.Account.Data.GetBinary(), decimals)
+		if err != nil {
+			continue
+		}
+		info.Slot = res.Context.Slot
+		callback(*info)
+	}
+}
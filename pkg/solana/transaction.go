@@ -0,0 +1,294 @@
+package solana
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Transaction is a confirmed transaction with its account keys resolved
+type Transaction struct {
+	Signature string
+	Slot      uint64
+	BlockTime time.Time
+	Failed    bool
+	// AccountKeys holds the static keys followed by the writable and
+	// readonly keys loaded from address lookup tables
+	AccountKeys  []string
+	Instructions []Instruction
+	Logs         []string
+	// PreBalances and PostBalances hold the lamports of each account key
+	// before and after the transaction
+	PreBalances  []uint64
+	PostBalances []uint64
+	// TokenBalances holds the token balances the transaction touched
+	TokenBalances []TokenBalanceChange
+}
+
+// TokenBalanceChange is the balance of a token account before and after a
+// transaction
+type TokenBalanceChange struct {
+	Account  string
+	Owner    string
+	Mint     string
+	Decimals uint8
+	Pre      uint64
+	Post     uint64
+}
+
+// SignatureInfo is a transaction signature that involved an address
+type SignatureInfo struct {
+	Signature string
+	Slot      uint64
+	BlockTime time.Time
+	Failed    bool
+	Memo      string
+}
+
+// Instruction is a top-level or inner instruction of a transaction
+type Instruction struct {
+	ProgramID string
+	Accounts  []string
+	Data      []byte
+	// Inner is set for instructions invoked through cross-program invocation
+	Inner bool
+}
+
+// LogNotification is a transaction reported by a logs subscription
+type LogNotification struct {
+	Signature string
+	Slot      uint64
+	Failed    bool
+	Logs      []string
+}
+
+// GetTransaction fetches a confirmed transaction and flattens its top-level
+// and inner instructions in execution order
+func (c *Client) GetTransaction(ctx context.Context, signature string) (*Transaction, error) {
+	sig, err := solana.SignatureFromBase58(signature)
+	if err != nil {
+		return nil, invalidAddress("signature", signature, err)
+	}
+
+	maxVersion := uint64(0)
+	res, err := c.RPCClient.GetTransaction(ctx, sig, &rpc.GetTransactionOpts{
+		Encoding:                       solana.EncodingBase64,
+		Commitment:                     c.transactions(),
+		MaxSupportedTransactionVersion: &maxVersion,
+	})
+	if err != nil {
+		return nil, rpcFailed("get transaction", err)
+	}
+	if res.Transaction == nil || res.Meta == nil {
+		return nil, fmt.Errorf("transaction %s has no data", signature)
+	}
+
+	tx, err := res.Transaction.GetTransaction()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode transaction: %w", err)
+	}
+
+	result := &Transaction{
+		Signature: signature,
+		Slot:      res.Slot,
+		Failed:    res.Meta.Err != nil,
+		Logs:      res.Meta.LogMessages,
+		// Balances are ordered like the static and loaded account keys
+		PreBalances:  res.Meta.PreBalances,
+		PostBalances: res.Meta.PostBalances,
+	}
+	if res.BlockTime != nil {
+		result.BlockTime = res.BlockTime.Time()
+	}
+
+	keys := make([]solana.PublicKey, 0, len(tx.Message.AccountKeys))
+	keys = append(keys, tx.Message.AccountKeys...)
+	keys = append(keys, res.Meta.LoadedAddresses.Writable...)
+	keys = append(keys, res.Meta.LoadedAddresses.ReadOnly...)
+	for _, key := range keys {
+		result.AccountKeys = append(result.AccountKeys, key.String())
+	}
+
+	result.TokenBalances = tokenBalanceChanges(result.AccountKeys, res.Meta.PreTokenBalances, res.Meta.PostTokenBalances)
+
+	inner := make(map[uint16][]solana.CompiledInstruction)
+	for _, set := range res.Meta.InnerInstructions {
+		inner[set.Index] = append(inner[set.Index], set.Instructions...)
+	}
+
+	for i, compiled := range tx.Message.Instructions {
+		result.Instructions = append(result.Instructions, result.resolve(compiled, false))
+		for _, compiled := range inner[uint16(i)] {
+			result.Instructions = append(result.Instructions, result.resolve(compiled, true))
+		}
+	}
+
+	return result, nil
+}
+
+// tokenBalanceChanges pairs the pre and post token balances of a transaction
+// by account. Accounts created or closed by the transaction have a zero pre
+// or post balance.
+func tokenBalanceChanges(keys []string, pre, post []rpc.TokenBalance) []TokenBalanceChange {
+	changes := make(map[uint16]*TokenBalanceChange)
+	var order []uint16
+
+	record := func(balance rpc.TokenBalance, isPost bool) {
+		change, ok := changes[balance.AccountIndex]
+		if !ok {
+			change = &TokenBalanceChange{Mint: balance.Mint.String()}
+			if int(balance.AccountIndex) < len(keys) {
+				change.Account = keys[balance.AccountIndex]
+			}
+			if balance.Owner != nil {
+				change.Owner = balance.Owner.String()
+			}
+			changes[balance.AccountIndex] = change
+			order = append(order, balance.AccountIndex)
+		}
+		if balance.UiTokenAmount == nil {
+			return
+		}
+		change.Decimals = balance.UiTokenAmount.Decimals
+		amount, err := strconv.ParseUint(balance.UiTokenAmount.Amount, 10, 64)
+		if err != nil {
+			return
+		}
+		if isPost {
+			change.Post = amount
+		} else {
+			change.Pre = amount
+		}
+	}
+
+	for _, balance := range pre {
+		record(balance, false)
+	}
+	for _, balance := range post {
+		record(balance, true)
+	}
+
+	result := make([]TokenBalanceChange, 0, len(order))
+	for _, index := range order {
+		result = append(result, *changes[index])
+	}
+	return result
+}
+
+// GetRecentSignatures returns up to limit of the latest transaction
+// signatures that involved an address, newest first
+func (c *Client) GetRecentSignatures(ctx context.Context, address string, limit int) ([]SignatureInfo, error) {
+	pubkey, err := solana.PublicKeyFromBase58(address)
+	if err != nil {
+		return nil, invalidAddress("address", address, err)
+	}
+
+	res, err := c.RPCClient.GetSignaturesForAddressWithOpts(ctx, pubkey, &rpc.GetSignaturesForAddressOpts{
+		Limit:      &limit,
+		Commitment: c.transactions(),
+	})
+	if err != nil {
+		return nil, rpcFailed("get signatures", err)
+	}
+
+	signatures := make([]SignatureInfo, 0, len(res))
+	for _, item := range res {
+		info := SignatureInfo{
+			Signature: item.Signature.String(),
+			Slot:      item.Slot,
+			Failed:    item.Err != nil,
+		}
+		if item.BlockTime != nil {
+			info.BlockTime = item.BlockTime.Time()
+		}
+		if item.Memo != nil {
+			info.Memo = memoText(*item.Memo)
+		}
+		signatures = append(signatures, info)
+	}
+
+	return signatures, nil
+}
+
+// memoText strips the length the RPC puts before each memo of a
+// transaction, e.g. "[5] hello; [3] bye" becomes "hello; bye"
+func memoText(memo string) string {
+	parts := strings.Split(memo, "; ")
+	for i, part := range parts {
+		if strings.HasPrefix(part, "[") {
+			if end := strings.Index(part, "] "); end > 0 {
+				if _, err := strconv.Atoi(part[1:end]); err == nil {
+					parts[i] = part[end+2:]
+				}
+			}
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// resolve maps the account indexes of a compiled instruction to addresses
+func (t *Transaction) resolve(compiled solana.CompiledInstruction, inner bool) Instruction {
+	ix := Instruction{
+		ProgramID: t.key(compiled.ProgramIDIndex),
+		Data:      compiled.Data,
+		Inner:     inner,
+	}
+	for _, index := range compiled.Accounts {
+		ix.Accounts = append(ix.Accounts, t.key(index))
+	}
+	return ix
+}
+
+// key returns the account key at index, or an empty string if out of range
+func (t *Transaction) key(index uint16) string {
+	if int(index) >= len(t.AccountKeys) {
+		return ""
+	}
+	return t.AccountKeys[index]
+}
+
+// SubscribeToWalletLogs subscribes to the logs of every transaction that
+// mentions a wallet and calls callback for each. It blocks until ctx is
+// cancelled or the subscription fails.
+func (c *Client) SubscribeToWalletLogs(
+	ctx context.Context,
+	walletAddress string,
+	callback func(LogNotification),
+) error {
+	wallet, err := solana.PublicKeyFromBase58(walletAddress)
+	if err != nil {
+		return invalidAddress("wallet address", walletAddress, err)
+	}
+
+	sub, err := c.WSClient.LogsSubscribeMentions(wallet, c.transactions())
+	if err != nil {
+		return rpcFailed("subscribe to wallet logs", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		sub.Unsubscribe()
+	}()
+
+	for {
+		res, err := sub.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return subscriptionLost("logs", err)
+		}
+
+		callback(LogNotification{
+			Signature: res.Value.Signature.String(),
+			Slot:      res.Context.Slot,
+			Failed:    res.Value.Err != nil,
+			Logs:      res.Value.Logs,
+		})
+	}
+}
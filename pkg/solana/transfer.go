@@ -0,0 +1,168 @@
+package solana
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// Transfer is the transaction that last changed a token account balance
+type Transfer struct {
+	Signature string    `json:"signature"`
+	Slot      uint64    `json:"slot"`
+	BlockTime time.Time `json:"block_time"`
+	Memo      string    `json:"memo,omitempty"`
+	// Direction is DirectionIn when the tracked account received tokens and
+	// DirectionOut when it sent them
+	Direction string `json:"direction"`
+	// Kind is TransferKindMint when the tokens were minted to the tracked
+	// account, as airdrops and claims are, TransferKindBurn when the account
+	// burned them and TransferKindTransfer otherwise
+	Kind string `json:"kind,omitempty"`
+	// MintAuthority signed the mint instruction of minted tokens
+	MintAuthority string `json:"mint_authority,omitempty"`
+	// Burned is the amount the tracked account burned, in base units, when
+	// Kind is TransferKindBurn
+	Burned uint64 `json:"burned,omitempty"`
+	// Counterparties are the owners whose balance of the same mint moved the
+	// opposite way in the transaction
+	Counterparties []Counterparty `json:"counterparties,omitempty"`
+}
+
+// Transfer directions
+const (
+	DirectionIn  = "in"
+	DirectionOut = "out"
+)
+
+// Transfer kinds
+const (
+	TransferKindTransfer = "transfer"
+	TransferKindMint     = "mint"
+	TransferKindBurn     = "burn"
+)
+
+// Token instructions that mint tokens to an account or burn them
+const (
+	tokenMintTo        = 7
+	tokenBurn          = 8
+	tokenMintToChecked = 14
+	tokenBurnChecked   = 15
+)
+
+// Counterparty is the other side of a transfer
+type Counterparty struct {
+	Owner   string `json:"owner"`
+	Account string `json:"account"`
+	// Amount is the amount the counterparty sent or received, in base units
+	Amount uint64 `json:"amount"`
+	// Name and Kind identify counterparties that are known entities, such
+	// as exchanges
+	Name string `json:"name,omitempty"`
+	Kind string `json:"kind,omitempty"`
+}
+
+// ResolveTransfer finds the latest transaction of a token account and the
+// counterparties of its balance change
+func (c *Client) ResolveTransfer(ctx context.Context, account TokenAccountInfo) (*Transfer, error) {
+	signatures, err := c.GetRecentSignatures(ctx, account.Address, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(signatures) == 0 {
+		return nil, fmt.Errorf("no transactions for %s", account.Address)
+	}
+
+	tx, err := c.GetTransaction(ctx, signatures[0].Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := &Transfer{
+		Signature: tx.Signature,
+		Slot:      tx.Slot,
+		BlockTime: tx.BlockTime,
+		Memo:      signatures[0].Memo,
+	}
+
+	// Direction of the tracked account's change
+	var received bool
+	for _, change := range tx.TokenBalances {
+		if change.Account == account.Address {
+			received = change.Post > change.Pre
+		}
+	}
+	transfer.Direction = DirectionOut
+	transfer.Kind = TransferKindTransfer
+	if received {
+		transfer.Direction = DirectionIn
+		if authority, ok := tx.mintAuthority(account.Address); ok {
+			transfer.Kind = TransferKindMint
+			transfer.MintAuthority = authority
+		}
+	} else if burned := tx.burned(account.Address); burned > 0 {
+		transfer.Kind = TransferKindBurn
+		transfer.Burned = burned
+	}
+
+	for _, change := range tx.TokenBalances {
+		if change.Mint != account.Mint || change.Owner == account.Owner || change.Post == change.Pre {
+			continue
+		}
+
+		// A receipt pairs with decreases elsewhere, a send with increases
+		if received == (change.Post < change.Pre) {
+			amount := change.Post - change.Pre
+			if change.Post < change.Pre {
+				amount = change.Pre - change.Post
+			}
+			transfer.Counterparties = append(transfer.Counterparties, Counterparty{
+				Owner:   change.Owner,
+				Account: change.Account,
+				Amount:  amount,
+			})
+		}
+	}
+
+	return transfer, nil
+}
+
+// mintAuthority returns the authority of a token instruction of the
+// transaction minting to an account
+func (tx *Transaction) mintAuthority(account string) (string, bool) {
+	for _, ix := range tx.Instructions {
+		if ix.ProgramID != TokenProgramID && ix.ProgramID != Token2022ProgramID {
+			continue
+		}
+		if len(ix.Data) == 0 || len(ix.Accounts) < 3 {
+			continue
+		}
+		// MintTo and MintToChecked take the mint, the destination and the
+		// authority
+		if (ix.Data[0] == tokenMintTo || ix.Data[0] == tokenMintToChecked) && ix.Accounts[1] == account {
+			return ix.Accounts[2], true
+		}
+	}
+	return "", false
+}
+
+// burned returns the amount the token instructions of the transaction burned
+// from an account
+func (tx *Transaction) burned(account string) uint64 {
+	var burned uint64
+	for _, ix := range tx.Instructions {
+		if ix.ProgramID != TokenProgramID && ix.ProgramID != Token2022ProgramID {
+			continue
+		}
+		// Burn and BurnChecked take the account, the mint and the authority
+		// and encode the amount after the instruction
+		if len(ix.Data) < 9 || len(ix.Accounts) < 1 || ix.Accounts[0] != account {
+			continue
+		}
+		if ix.Data[0] == tokenBurn || ix.Data[0] == tokenBurnChecked {
+			burned += binary.LittleEndian.Uint64(ix.Data[1:9])
+		}
+	}
+	return burned
+}
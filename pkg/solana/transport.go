@@ -0,0 +1,165 @@
+package solana
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/gagliardetto/solana-go/rpc/ws"
+	"github.com/yourusername/solana-wallet-tracker/pkg/telemetry"
+)
+
+// defaultRPCTimeout bounds RPC requests when the transport doesn't, as
+// solana-go does
+const defaultRPCTimeout = 5 * time.Minute
+
+// Transport configures the connections of a client to its endpoints. The
+// zero value connects the way solana-go does by default.
+//
+// The WebSocket client dials on its own, so Proxy and TLS only apply to RPC
+// requests: WebSocket connections go through the proxy of the HTTPS_PROXY
+// environment variable and trust the system certificates, which
+// SSL_CERT_FILE can extend.
+type Transport struct {
+	// Proxy is the URL of the proxy of RPC requests. Without it, the
+	// HTTPS_PROXY and HTTP_PROXY environment variables apply.
+	Proxy *url.URL
+	// Headers are sent with every RPC request and WebSocket handshake, such
+	// as the API key header of an RPC provider
+	Headers map[string]string
+	// TLS configures the TLS connections of RPC requests
+	TLS *tls.Config
+	// Timeout bounds each RPC request (default 5m)
+	Timeout time.Duration
+	// HandshakeTimeout bounds the opening of WebSocket connections
+	HandshakeTimeout time.Duration
+}
+
+// custom reports whether the transport changes the defaults of the RPC client
+func (t Transport) custom() bool {
+	return t.Proxy != nil || len(t.Headers) > 0 || t.TLS != nil || t.Timeout > 0
+}
+
+// rpcClient returns an RPC client of the endpoint using the transport.
+// With telemetry enabled, every request is traced, see tracedTransport.
+func (t Transport) rpcClient(endpoint string) *rpc.Client {
+	if !t.custom() && !telemetry.Enabled() {
+		return rpc.New(endpoint)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if t.Proxy != nil {
+		transport.Proxy = http.ProxyURL(t.Proxy)
+	}
+	if t.TLS != nil {
+		transport.TLSClientConfig = t.TLS
+	}
+	timeout := t.Timeout
+	if timeout <= 0 {
+		timeout = defaultRPCTimeout
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if telemetry.Enabled() {
+		roundTripper = tracedTransport{next: transport}
+	}
+
+	return rpc.NewWithCustomRPCClient(jsonrpc.NewClientWithOpts(endpoint, &jsonrpc.RPCClientOpts{
+		HTTPClient:    &http.Client{Transport: roundTripper, Timeout: timeout},
+		CustomHeaders: t.Headers,
+	}))
+}
+
+// connect opens a WebSocket connection to the endpoint using the transport
+func (t Transport) connect(ctx context.Context, endpoint string) (*ws.Client, error) {
+	options := &ws.Options{HandshakeTimeout: t.HandshakeTimeout}
+	if len(t.Headers) > 0 {
+		options.HttpHeader = make(http.Header, len(t.Headers))
+		for name, value := range t.Headers {
+			options.HttpHeader.Set(name, value)
+		}
+	}
+
+	client, err := ws.ConnectWithOptions(ctx, endpoint, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to WebSocket: %w", err)
+	}
+	return client, nil
+}
+
+// rpcDuration is the duration of RPC requests by method
+var rpcDuration = telemetry.NewHistogram("rpc.client.duration", "Duration of Solana RPC requests", "ms", telemetry.DurationBounds)
+
+// tracedTransport records a span and the duration of every RPC request,
+// named after its JSON-RPC method, and passes the trace on in the
+// traceparent header
+type tracedTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip sends a traced request
+func (t tracedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method := rpcMethod(req)
+	ctx, span := telemetry.Start(req.Context(), "rpc "+method, telemetry.KindClient,
+		telemetry.String("rpc.system", "jsonrpc"),
+		telemetry.String("rpc.method", method),
+	)
+	req = req.WithContext(ctx)
+	if span != nil {
+		req.Header.Set("traceparent", telemetry.Traceparent(span.Context()))
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	outcome := "ok"
+	switch {
+	case err != nil:
+		outcome = "error"
+		span.SetError(err)
+	case resp.StatusCode != http.StatusOK:
+		outcome = "error"
+		span.SetError(fmt.Errorf("status %d", resp.StatusCode))
+	}
+	if resp != nil {
+		span.SetAttributes(telemetry.Int("http.response.status_code", int64(resp.StatusCode)))
+	}
+	span.End()
+	rpcDuration.Record(float64(time.Since(start))/float64(time.Millisecond),
+		telemetry.String("rpc.method", method),
+		telemetry.String("outcome", outcome),
+	)
+	return resp, err
+}
+
+// rpcMethod returns the JSON-RPC method of a request, "batch" for batches
+func rpcMethod(req *http.Request) string {
+	if req.GetBody == nil {
+		return "unknown"
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "unknown"
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "unknown"
+	}
+	var request struct {
+		Method string `json:"method"`
+	}
+	if len(data) > 0 && data[0] == '[' {
+		return "batch"
+	}
+	if err := json.Unmarshal(data, &request); err != nil || request.Method == "" {
+		return "unknown"
+	}
+	return request.Method
+}
@@ -0,0 +1,170 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// DeadLetter is a notification that couldn't be delivered after every retry
+type DeadLetter struct {
+	ID string `json:"id"`
+	// Notifier is the type of the notifier and NotifierIndex its position in
+	// the configured notifiers, those of Tenant if it is set
+	Notifier      string `json:"notifier"`
+	NotifierIndex int    `json:"notifier_index"`
+	Tenant        string `json:"tenant,omitempty"`
+	// Events holds the JSON of the undelivered monitor events
+	Events   json.RawMessage `json:"events"`
+	Error    string          `json:"error"`
+	Attempts int             `json:"attempts"`
+	FailedAt time.Time       `json:"failed_at"`
+}
+
+// DeadLetterStore is implemented by stores that keep undelivered
+// notifications for replay
+type DeadLetterStore interface {
+	// AddDeadLetter stores a notification, assigning an ID if it has none
+	AddDeadLetter(ctx context.Context, letter DeadLetter) error
+	// DeadLetters returns the stored notifications, oldest first
+	DeadLetters(ctx context.Context) ([]DeadLetter, error)
+	// RemoveDeadLetter deletes a notification, e.g. after it was replayed
+	RemoveDeadLetter(ctx context.Context, id string) error
+}
+
+// newDeadLetterID returns a random ID
+func newDeadLetterID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// AddDeadLetter appends a notification to the dead letter side file
+func (s *FileStore) AddDeadLetter(ctx context.Context, letter DeadLetter) error {
+	if letter.ID == "" {
+		letter.ID = newDeadLetterID()
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	letters, err := s.loadDeadLetters()
+	if err != nil {
+		return err
+	}
+	return s.saveDeadLetters(append(letters, letter))
+}
+
+// DeadLetters returns the notifications in the dead letter side file
+func (s *FileStore) DeadLetters(ctx context.Context) ([]DeadLetter, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.loadDeadLetters()
+}
+
+// RemoveDeadLetter deletes a notification from the dead letter side file
+func (s *FileStore) RemoveDeadLetter(ctx context.Context, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	letters, err := s.loadDeadLetters()
+	if err != nil {
+		return err
+	}
+	kept := letters[:0]
+	for _, letter := range letters {
+		if letter.ID != id {
+			kept = append(kept, letter)
+		}
+	}
+	return s.saveDeadLetters(kept)
+}
+
+// deadLettersPath returns the path of the dead letter side file
+func (s *FileStore) deadLettersPath() string {
+	return s.path + ".deadletters.json"
+}
+
+// loadDeadLetters reads the dead letter side file. It is read on every call
+// so the replay command and a running tracker see each other's changes.
+func (s *FileStore) loadDeadLetters() ([]DeadLetter, error) {
+	data, err := ioutil.ReadFile(s.deadLettersPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var letters []DeadLetter
+	if err := json.Unmarshal(data, &letters); err != nil {
+		return nil, err
+	}
+	return letters, nil
+}
+
+// saveDeadLetters replaces the dead letter side file. Callers must hold the
+// write lock.
+func (s *FileStore) saveDeadLetters(letters []DeadLetter) error {
+	data, err := json.MarshalIndent(letters, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := s.deadLettersPath() + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, s.deadLettersPath())
+}
+
+// AddDeadLetter inserts an undelivered notification
+func (s *SQLStore) AddDeadLetter(ctx context.Context, letter DeadLetter) error {
+	if letter.ID == "" {
+		letter.ID = newDeadLetterID()
+	}
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		s.query("INSERT INTO dead_letters (id, failed_at, data) VALUES (?, ?, ?)"),
+		letter.ID, letter.FailedAt.UnixNano(), string(data),
+	)
+	return err
+}
+
+// DeadLetters returns the undelivered notifications, oldest first
+func (s *SQLStore) DeadLetters(ctx context.Context) ([]DeadLetter, error) {
+	rows, err := s.db.QueryContext(ctx, s.query("SELECT data FROM dead_letters ORDER BY failed_at, id"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var letters []DeadLetter
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+
+		var letter DeadLetter
+		if err := json.Unmarshal([]byte(data), &letter); err != nil {
+			return nil, err
+		}
+		letters = append(letters, letter)
+	}
+	return letters, rows.Err()
+}
+
+// RemoveDeadLetter deletes an undelivered notification
+func (s *SQLStore) RemoveDeadLetter(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, s.query("DELETE FROM dead_letters WHERE id = ?"), id)
+	return err
+}
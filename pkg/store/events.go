@@ -0,0 +1,347 @@
+package store
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StoredEvent is an event of the persistent event log
+type StoredEvent struct {
+	// ID increases with every stored event, so consumers ask for the events
+	// after the last one they handled
+	ID     int64     `json:"id"`
+	Wallet string    `json:"wallet"`
+	At     time.Time `json:"at"`
+	// Event holds the JSON of the monitor event
+	Event json.RawMessage `json:"event,omitempty"`
+}
+
+// EventStore is implemented by stores that keep a log of events for
+// consumers to catch up on
+type EventStore interface {
+	// AppendEvent stores an event, assigning it an ID above those of every
+	// stored event, and returns the ID
+	AppendEvent(ctx context.Context, event StoredEvent) (int64, error)
+	// EventsAfter returns up to limit events of an ID above after, oldest
+	// first, only those of the given wallets unless wallets is nil
+	EventsAfter(ctx context.Context, after int64, limit int, wallets []string) ([]StoredEvent, error)
+	// EventCursorAt returns a cursor for EventsAfter that skips the events
+	// stored before at, and only those
+	EventCursorAt(ctx context.Context, at time.Time) (int64, error)
+}
+
+// eventOffset is where an event starts in the event side file
+type eventOffset struct {
+	id     int64
+	at     time.Time
+	offset int64
+}
+
+// AppendEvent appends an event to the event side file
+func (s *FileStore) AppendEvent(ctx context.Context, event StoredEvent) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.lastEventID == 0 {
+		file, err := s.indexEvents()
+		if err != nil {
+			return 0, err
+		}
+		if file != nil {
+			file.Close()
+		}
+		if len(s.eventIndex) > 0 {
+			s.lastEventID = s.eventIndex[len(s.eventIndex)-1].id
+		}
+	}
+	event.ID = s.lastEventID + 1
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+	file, err := os.OpenFile(s.eventsPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return 0, err
+	}
+	if err := file.Close(); err != nil {
+		return 0, err
+	}
+	s.lastEventID = event.ID
+	return event.ID, nil
+}
+
+// EventsAfter returns events of the event side file after the given ID. It
+// seeks to the first of them by the offset index, so a page costs only the
+// events it reads.
+func (s *FileStore) EventsAfter(ctx context.Context, after int64, limit int, wallets []string) ([]StoredEvent, error) {
+	// Indexing the events appended since the last call changes the index
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	file, err := s.indexEvents()
+	if err != nil || file == nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var wanted map[string]bool
+	if wallets != nil {
+		wanted = make(map[string]bool, len(wallets))
+		for _, wallet := range wallets {
+			wanted[wallet] = true
+		}
+	}
+
+	first := sort.Search(len(s.eventIndex), func(i int) bool { return s.eventIndex[i].id > after })
+	if first == len(s.eventIndex) {
+		return nil, nil
+	}
+	start := s.eventIndex[first].offset
+	reader := bufio.NewReader(io.NewSectionReader(file, start, s.eventsIndexed-start))
+
+	var result []StoredEvent
+	for len(result) < limit {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var event StoredEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			return nil, err
+		}
+		if event.ID > after && event.Event != nil && (wanted == nil || wanted[event.Wallet]) {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+// EventCursorAt returns the ID before the first event stored at or after
+// at, by the offset index
+func (s *FileStore) EventCursorAt(ctx context.Context, at time.Time) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	file, err := s.indexEvents()
+	if err != nil || file == nil {
+		return 0, err
+	}
+	file.Close()
+
+	// Events are stored about in time order, but appenders take their time
+	// before the lock, so every entry is checked
+	var cursor int64
+	if len(s.eventIndex) > 0 {
+		cursor = s.eventIndex[len(s.eventIndex)-1].id
+	}
+	for _, entry := range s.eventIndex {
+		if !entry.at.Before(at) && entry.id-1 < cursor {
+			cursor = entry.id - 1
+		}
+	}
+	return cursor, nil
+}
+
+// eventsPath returns the path of the event side file
+func (s *FileStore) eventsPath() string {
+	return s.path + ".events.jsonl"
+}
+
+// indexEvents adds the events appended to the event side file since it was
+// last indexed to the offset index, and returns the file opened for reading
+// or nil when there is none. The file is checked on every call so consumers
+// see the events of a running tracker; the index starts over when the file
+// was replaced or truncated. Callers must hold the write lock.
+func (s *FileStore) indexEvents() (*os.File, error) {
+	file, err := os.Open(s.eventsPath())
+	if os.IsNotExist(err) {
+		s.resetEventIndex()
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if s.eventsFile == nil || !os.SameFile(info, s.eventsFile) || info.Size() < s.eventsIndexed {
+		s.resetEventIndex()
+	}
+	s.eventsFile = info
+
+	reader := bufio.NewReader(io.NewSectionReader(file, s.eventsIndexed, info.Size()-s.eventsIndexed))
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// A line without its newline is still being written
+			break
+		}
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		var event struct {
+			ID int64     `json:"id"`
+			At time.Time `json:"at"`
+		}
+		if err := json.Unmarshal(line, &event); err != nil {
+			file.Close()
+			return nil, err
+		}
+		s.eventIndex = append(s.eventIndex, eventOffset{id: event.ID, at: event.At, offset: s.eventsIndexed})
+		s.eventsIndexed += int64(len(line))
+	}
+	return file, nil
+}
+
+// resetEventIndex forgets the offset index
+func (s *FileStore) resetEventIndex() {
+	s.eventIndex = nil
+	s.eventsIndexed = 0
+	s.eventsFile = nil
+}
+
+// loadEvents reads the whole event side file
+func (s *FileStore) loadEvents() ([]StoredEvent, error) {
+	file, err := os.Open(s.eventsPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var events []StoredEvent
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var event StoredEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, scanner.Err()
+}
+
+// purgeEvents removes the events of a wallet from the event side file. The
+// last event is kept without its wallet and event, so its ID isn't handed
+// out again. Callers must hold the write lock.
+func (s *FileStore) purgeEvents(wallet string) error {
+	events, err := s.loadEvents()
+	if err != nil || len(events) == 0 {
+		return err
+	}
+	last := events[len(events)-1].ID
+
+	tmpPath := s.eventsPath() + ".tmp"
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(tmp)
+	for _, event := range events {
+		if event.Wallet == wallet {
+			if event.ID != last {
+				continue
+			}
+			event = StoredEvent{ID: event.ID, At: event.At}
+		}
+		data, err := json.Marshal(event)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		writer.Write(append(data, '\n'))
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	s.resetEventIndex()
+	return os.Rename(tmpPath, s.eventsPath())
+}
+
+// AppendEvent inserts an event, which the database numbers. Appends are
+// serialized: IDs are assigned on insert, but concurrent inserts may commit
+// out of order, and a reader seeing a later ID before an earlier one commits
+// would move its cursor past the earlier one for good.
+func (s *SQLStore) AppendEvent(ctx context.Context, event StoredEvent) (int64, error) {
+	s.appendMutex.Lock()
+	defer s.appendMutex.Unlock()
+
+	var id int64
+	err := s.db.QueryRowContext(ctx,
+		s.query("INSERT INTO events (wallet, at, data) VALUES (?, ?, ?) RETURNING id"),
+		event.Wallet, event.At.UnixNano(), string(event.Event),
+	).Scan(&id)
+	return id, err
+}
+
+// EventsAfter returns up to limit events after the given ID, oldest first
+func (s *SQLStore) EventsAfter(ctx context.Context, after int64, limit int, wallets []string) ([]StoredEvent, error) {
+	q := "SELECT id, wallet, at, data FROM events WHERE id > ?"
+	args := []interface{}{after}
+	if wallets != nil {
+		if len(wallets) == 0 {
+			return nil, nil
+		}
+		q += " AND wallet IN (?" + strings.Repeat(", ?", len(wallets)-1) + ")"
+		for _, wallet := range wallets {
+			args = append(args, wallet)
+		}
+	}
+	q += " ORDER BY id LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, s.query(q), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []StoredEvent
+	for rows.Next() {
+		var event StoredEvent
+		var at int64
+		var data string
+		if err := rows.Scan(&event.ID, &event.Wallet, &at, &data); err != nil {
+			return nil, err
+		}
+		event.At = time.Unix(0, at).UTC()
+		event.Event = json.RawMessage(data)
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// EventCursorAt returns the ID before the first event stored at or after
+// at, or the last ID when there is none
+func (s *SQLStore) EventCursorAt(ctx context.Context, at time.Time) (int64, error) {
+	var cursor int64
+	err := s.db.QueryRowContext(ctx,
+		s.query("SELECT COALESCE(MIN(id) - 1, (SELECT COALESCE(MAX(id), 0) FROM events)) FROM events WHERE at >= ?"),
+		at.UnixNano(),
+	).Scan(&cursor)
+	return cursor, err
+}
@@ -0,0 +1,270 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+)
+
+// eventStores opens every event store kind in a temporary directory
+func eventStores(t *testing.T) map[string]EventStore {
+	t.Helper()
+	dir := t.TempDir()
+
+	file, err := NewFileStore(filepath.Join(dir, "history.jsonl"), nil)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	t.Cleanup(func() { file.Close() })
+
+	stores := map[string]EventStore{"file": file}
+	if open, ok := backends["sqlite"]; ok {
+		sqlite, err := open(config.StoreConfig{Path: filepath.Join(dir, "history.db")})
+		if err != nil {
+			t.Fatalf("open sqlite: %v", err)
+		}
+		t.Cleanup(func() { sqlite.Close() })
+		stores["sqlite"] = sqlite.(EventStore)
+	}
+	return stores
+}
+
+func TestEventsAfterConcurrentAppends(t *testing.T) {
+	const writers, perWriter, pageLimit = 8, 50, 7
+
+	for name, events := range eventStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			done := make(chan struct{})
+
+			// A consumer pages by cursor while the events are appended and
+			// must end up with every one of them, in order
+			var seen []int64
+			readerDone := make(chan error, 1)
+			go func() {
+				var after int64
+				for {
+					finished := false
+					select {
+					case <-done:
+						finished = true
+					default:
+					}
+					page, err := events.EventsAfter(ctx, after, pageLimit, nil)
+					if err != nil {
+						readerDone <- err
+						return
+					}
+					for _, event := range page {
+						seen = append(seen, event.ID)
+						after = event.ID
+					}
+					if finished && len(page) == 0 {
+						readerDone <- nil
+						return
+					}
+				}
+			}()
+
+			var wg sync.WaitGroup
+			for w := 0; w < writers; w++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < perWriter; i++ {
+						_, err := events.AppendEvent(ctx, StoredEvent{
+							Wallet: "wallet",
+							At:     time.Now().UTC(),
+							Event:  json.RawMessage(`{"type":"balance_changed"}`),
+						})
+						if err != nil {
+							t.Errorf("AppendEvent: %v", err)
+							return
+						}
+					}
+				}()
+			}
+			wg.Wait()
+			close(done)
+
+			if err := <-readerDone; err != nil {
+				t.Fatalf("EventsAfter: %v", err)
+			}
+			if len(seen) != writers*perWriter {
+				t.Fatalf("consumer saw %d events, want %d", len(seen), writers*perWriter)
+			}
+			for i, id := range seen {
+				if id != int64(i+1) {
+					t.Fatalf("event %d has ID %d, want %d", i, id, i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestEventsAfterWallets(t *testing.T) {
+	for name, events := range eventStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			for _, wallet := range []string{"a", "b", "a", "c", "a"} {
+				if _, err := events.AppendEvent(ctx, StoredEvent{Wallet: wallet, At: time.Now().UTC(), Event: json.RawMessage(`{}`)}); err != nil {
+					t.Fatalf("AppendEvent: %v", err)
+				}
+			}
+
+			tests := []struct {
+				name    string
+				after   int64
+				limit   int
+				wallets []string
+				want    []int64
+			}{
+				{"every wallet", 0, 10, nil, []int64{1, 2, 3, 4, 5}},
+				{"after cursor", 3, 10, nil, []int64{4, 5}},
+				{"limited", 0, 2, nil, []int64{1, 2}},
+				{"one wallet", 0, 10, []string{"a"}, []int64{1, 3, 5}},
+				{"one wallet after cursor", 1, 1, []string{"a"}, []int64{3}},
+				{"no wallets", 0, 10, []string{}, nil},
+			}
+			for _, tt := range tests {
+				page, err := events.EventsAfter(ctx, tt.after, tt.limit, tt.wallets)
+				if err != nil {
+					t.Fatalf("%s: EventsAfter: %v", tt.name, err)
+				}
+				var got []int64
+				for _, event := range page {
+					got = append(got, event.ID)
+				}
+				if !equalIDs(got, tt.want) {
+					t.Errorf("%s: got IDs %v, want %v", tt.name, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// equalIDs compares event IDs
+func equalIDs(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestFileStoreEventIndex(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	s, err := NewFileStore(path, nil)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	defer s.Close()
+
+	appendEvents := func(wallets ...string) {
+		for _, wallet := range wallets {
+			if _, err := s.AppendEvent(ctx, StoredEvent{Wallet: wallet, At: time.Now().UTC(), Event: json.RawMessage(`{}`)}); err != nil {
+				t.Fatalf("AppendEvent: %v", err)
+			}
+		}
+	}
+	ids := func(after int64) []int64 {
+		page, err := s.EventsAfter(ctx, after, 100, nil)
+		if err != nil {
+			t.Fatalf("EventsAfter: %v", err)
+		}
+		var got []int64
+		for _, event := range page {
+			got = append(got, event.ID)
+		}
+		return got
+	}
+
+	appendEvents("a", "b", "a")
+	if got := ids(1); !equalIDs(got, []int64{2, 3}) {
+		t.Fatalf("got IDs %v, want [2 3]", got)
+	}
+	// Events appended after indexing are picked up
+	appendEvents("b")
+	if got := ids(2); !equalIDs(got, []int64{3, 4}) {
+		t.Fatalf("got IDs %v after append, want [3 4]", got)
+	}
+
+	// Purging rewrites the side file, which starts the index over; the last
+	// ID stays taken
+	if err := s.PurgeWallet(ctx, "b"); err != nil {
+		t.Fatalf("PurgeWallet: %v", err)
+	}
+	if got := ids(0); !equalIDs(got, []int64{1, 3}) {
+		t.Fatalf("got IDs %v after purge, want [1 3]", got)
+	}
+	appendEvents("a")
+	if got := ids(3); !equalIDs(got, []int64{5}) {
+		t.Fatalf("got IDs %v after purge and append, want [5]", got)
+	}
+
+	// Another store on the same files, such as a CLI command next to a
+	// running tracker, sees the same events
+	other, err := NewFileStore(path, nil)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	defer other.Close()
+	page, err := other.EventsAfter(ctx, 0, 100, []string{"a"})
+	if err != nil {
+		t.Fatalf("EventsAfter: %v", err)
+	}
+	if len(page) != 3 || page[2].ID != 5 {
+		t.Fatalf("other store got %+v, want the 3 events of a up to ID 5", page)
+	}
+}
+
+func TestEventCursorAt(t *testing.T) {
+	base := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	for name, events := range eventStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			cursor, err := events.EventCursorAt(ctx, base)
+			if err != nil || cursor != 0 {
+				t.Fatalf("empty log: got cursor %d (%v), want 0", cursor, err)
+			}
+
+			// The third event was timed before the second took the lock
+			for _, hours := range []int{0, 2, 1, 3} {
+				if _, err := events.AppendEvent(ctx, StoredEvent{Wallet: "a", At: base.Add(time.Duration(hours) * time.Hour), Event: json.RawMessage(`{}`)}); err != nil {
+					t.Fatalf("AppendEvent: %v", err)
+				}
+			}
+
+			tests := []struct {
+				hours int
+				want  int64
+			}{
+				{-1, 0},
+				{0, 0},
+				{1, 1},
+				{2, 1},
+				{3, 3},
+				{4, 4},
+			}
+			for _, tt := range tests {
+				cursor, err := events.EventCursorAt(ctx, base.Add(time.Duration(tt.hours)*time.Hour))
+				if err != nil {
+					t.Fatalf("EventCursorAt: %v", err)
+				}
+				if cursor != tt.want {
+					t.Errorf("at %dh: got cursor %d, want %d", tt.hours, cursor, tt.want)
+				}
+			}
+		})
+	}
+}
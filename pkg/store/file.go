@@ -0,0 +1,260 @@
+package store
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// FileStore is an append-only JSON lines store. The whole history is kept in
+// memory, so it suits small deployments. Archived wallets are tracked in a
+// <path>.wallets.json side file, transfer statistics in <path>.stats.json and
+// the event log in <path>.events.jsonl.
+type FileStore struct {
+	path     string
+	file     *os.File
+	records  []solana.TokenAccountInfo
+	archived map[string]time.Time
+	// lastEventID is the ID of the last event logged, zero until the event
+	// log was read
+	lastEventID int64
+	// eventIndex locates the events of the event side file, whose first
+	// eventsIndexed bytes of eventsFile it covers
+	eventIndex    []eventOffset
+	eventsIndexed int64
+	eventsFile    os.FileInfo
+	mutex         sync.RWMutex
+}
+
+// NewFileStore opens or creates a file store at path. Malformed records are
+// skipped and logged through log, or the default logger when nil.
+func NewFileStore(path string, log logging.Logger) (*FileStore, error) {
+	if log == nil {
+		log = logging.Default()
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FileStore{
+		path:     path,
+		file:     file,
+		archived: make(map[string]time.Time),
+	}
+
+	// Load archived wallets
+	if data, err := ioutil.ReadFile(s.walletsPath()); err == nil {
+		if err := json.Unmarshal(data, &s.archived); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+
+	// Load existing records
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record solana.TokenAccountInfo
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			log.Warn("Skipping malformed history record", "path", path, "error", err)
+			continue
+		}
+		s.records = append(s.records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// SaveBalance appends a balance observation to the file
+func (s *FileStore) SaveBalance(ctx context.Context, accountInfo solana.TokenAccountInfo) error {
+	data, err := json.Marshal(accountInfo)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	s.records = append(s.records, accountInfo)
+
+	return nil
+}
+
+// History returns balance observations for a wallet and mint since the given time
+func (s *FileStore) History(ctx context.Context, owner, mint string, since time.Time) ([]solana.TokenAccountInfo, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var history []solana.TokenAccountInfo
+	for _, record := range s.records {
+		if record.Owner == owner && record.Mint == mint && !record.LastUpdatedAt.Before(since) {
+			history = append(history, record)
+		}
+	}
+
+	return history, nil
+}
+
+// Records returns every stored observation in insertion order
+func (s *FileStore) Records(ctx context.Context) ([]solana.TokenAccountInfo, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	records := make([]solana.TokenAccountInfo, len(s.records))
+	copy(records, s.records)
+
+	return records, nil
+}
+
+// HasHeld reports whether a wallet ever had a non-zero balance of a mint
+func (s *FileStore) HasHeld(ctx context.Context, owner, mint string) (bool, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, record := range s.records {
+		if record.Owner == owner && record.Mint == mint && record.Balance > 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Wallets returns every wallet with stored history and its archive status
+func (s *FileStore) Wallets(ctx context.Context) ([]WalletStatus, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	owners := make(map[string]bool)
+	for _, record := range s.records {
+		owners[record.Owner] = true
+	}
+	for owner := range s.archived {
+		owners[owner] = true
+	}
+
+	wallets := make([]WalletStatus, 0, len(owners))
+	for owner := range owners {
+		status := WalletStatus{Owner: owner}
+		if at, ok := s.archived[owner]; ok {
+			archivedAt := at
+			status.ArchivedAt = &archivedAt
+		}
+		wallets = append(wallets, status)
+	}
+	sort.Slice(wallets, func(i, j int) bool { return wallets[i].Owner < wallets[j].Owner })
+
+	return wallets, nil
+}
+
+// ArchiveWallet marks a wallet's history as archived
+func (s *FileStore) ArchiveWallet(ctx context.Context, owner string, at time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.archived[owner] = at
+	return s.saveArchived()
+}
+
+// RestoreWallet clears the archived mark of a wallet
+func (s *FileStore) RestoreWallet(ctx context.Context, owner string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.archived, owner)
+	return s.saveArchived()
+}
+
+// PurgeWallet rewrites the history file without the wallet's records
+func (s *FileStore) PurgeWallet(ctx context.Context, owner string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	kept := make([]solana.TokenAccountInfo, 0, len(s.records))
+	for _, record := range s.records {
+		if record.Owner != owner {
+			kept = append(kept, record)
+		}
+	}
+
+	// Write the remaining history next to the original and swap it in
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(tmp)
+	for _, record := range kept {
+		data, err := json.Marshal(record)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		writer.Write(append(data, '\n'))
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	s.file.Close()
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	s.file = file
+	s.records = kept
+
+	if err := s.purgeRentEntries(owner); err != nil {
+		return err
+	}
+	if err := s.purgeTransferStats(owner); err != nil {
+		return err
+	}
+	if err := s.purgeEvents(owner); err != nil {
+		return err
+	}
+	delete(s.archived, owner)
+	return s.saveArchived()
+}
+
+// walletsPath returns the path of the archived wallets side file
+func (s *FileStore) walletsPath() string {
+	return s.path + ".wallets.json"
+}
+
+// saveArchived persists the archived wallets. Callers must hold the write lock.
+func (s *FileStore) saveArchived() error {
+	data, err := json.MarshalIndent(s.archived, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.walletsPath(), data, 0644)
+}
+
+// Close closes the underlying file
+func (s *FileStore) Close() error {
+	return s.file.Close()
+}
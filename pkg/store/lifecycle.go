@@ -0,0 +1,38 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// SyncWallets archives stored wallets that are no longer configured and
+// restores archived wallets that were configured again
+func SyncWallets(ctx context.Context, st Store, configured []string) (archived, restored []string, err error) {
+	wanted := make(map[string]bool, len(configured))
+	for _, wallet := range configured {
+		wanted[wallet] = true
+	}
+
+	wallets, err := st.Wallets(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now().UTC()
+	for _, wallet := range wallets {
+		switch {
+		case wanted[wallet.Owner] && wallet.Archived():
+			if err := st.RestoreWallet(ctx, wallet.Owner); err != nil {
+				return archived, restored, err
+			}
+			restored = append(restored, wallet.Owner)
+		case !wanted[wallet.Owner] && !wallet.Archived():
+			if err := st.ArchiveWallet(ctx, wallet.Owner, now); err != nil {
+				return archived, restored, err
+			}
+			archived = append(archived, wallet.Owner)
+		}
+	}
+
+	return archived, restored, nil
+}
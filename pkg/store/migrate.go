@@ -0,0 +1,150 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// MigrationReport summarizes a completed migration
+type MigrationReport struct {
+	Records  int    `json:"records"`
+	Checksum string `json:"checksum"`
+}
+
+// ParseURI converts a store URI into a store configuration. Supported forms
+// are file:<path>, sqlite:<path> and postgres://... connection URLs.
+func ParseURI(uri string) (config.StoreConfig, error) {
+	switch {
+	case strings.HasPrefix(uri, "postgres://"), strings.HasPrefix(uri, "postgresql://"):
+		return config.StoreConfig{Type: "postgres", DSN: uri}, nil
+	case strings.HasPrefix(uri, "sqlite:"):
+		return config.StoreConfig{Type: "sqlite", Path: strings.TrimPrefix(uri, "sqlite:")}, nil
+	case strings.HasPrefix(uri, "file:"):
+		return config.StoreConfig{Type: "file", Path: strings.TrimPrefix(uri, "file:")}, nil
+	default:
+		return config.StoreConfig{}, fmt.Errorf("unsupported store URI: %q", uri)
+	}
+}
+
+// Migrate copies all history from one store into another and verifies that
+// the target holds the same records afterwards. The target must be empty.
+func Migrate(ctx context.Context, from, to Store) (*MigrationReport, error) {
+	existing, err := to.Records(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read target store: %w", err)
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("target store is not empty: %d records", len(existing))
+	}
+
+	records, err := from.Records(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source store: %w", err)
+	}
+
+	for i, record := range records {
+		if err := to.SaveBalance(ctx, record); err != nil {
+			return nil, fmt.Errorf("failed to copy record %d: %w", i+1, err)
+		}
+	}
+
+	wallets, err := from.Wallets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source wallets: %w", err)
+	}
+	for _, wallet := range wallets {
+		if !wallet.Archived() {
+			continue
+		}
+		if err := to.ArchiveWallet(ctx, wallet.Owner, *wallet.ArchivedAt); err != nil {
+			return nil, fmt.Errorf("failed to copy archived wallet %s: %w", wallet.Owner, err)
+		}
+	}
+
+	sourceSum, err := checksum(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	targetSum, err := checksum(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+	if sourceSum != targetSum {
+		return nil, fmt.Errorf("verification failed: source checksum %s, target checksum %s", sourceSum, targetSum)
+	}
+
+	return &MigrationReport{Records: len(records), Checksum: sourceSum}, nil
+}
+
+// RecordTarget is a migration target that keeps the balance history only,
+// such as ClickHouse for analytics
+type RecordTarget interface {
+	// CountRecords returns the number of records the target holds
+	CountRecords(ctx context.Context) (int, error)
+	// InsertRecords adds records to the target in order
+	InsertRecords(ctx context.Context, records []solana.TokenAccountInfo) error
+}
+
+// MigrateRecords copies all history from a store into a record target and
+// verifies that the target holds as many records afterwards. The target
+// must be empty. Archived wallets are copied as history, without their
+// archive status.
+func MigrateRecords(ctx context.Context, from Store, to RecordTarget) (*MigrationReport, error) {
+	existing, err := to.CountRecords(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read target: %w", err)
+	}
+	if existing > 0 {
+		return nil, fmt.Errorf("target is not empty: %d records", existing)
+	}
+
+	records, err := from.Records(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source store: %w", err)
+	}
+	if err := to.InsertRecords(ctx, records); err != nil {
+		return nil, fmt.Errorf("failed to copy records: %w", err)
+	}
+
+	copied, err := to.CountRecords(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read target: %w", err)
+	}
+	if copied != len(records) {
+		return nil, fmt.Errorf("verification failed: source has %d records, target %d", len(records), copied)
+	}
+
+	sum, err := checksum(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	return &MigrationReport{Records: len(records), Checksum: sum}, nil
+}
+
+// checksum hashes every record of a store in order
+func checksum(ctx context.Context, st Store) (string, error) {
+	records, err := st.Records(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	for _, record := range records {
+		// Normalize timestamps so backends with different time encodings compare equal
+		record.LastUpdatedAt = record.LastUpdatedAt.UTC()
+		data, err := json.Marshal(record)
+		if err != nil {
+			return "", err
+		}
+		hash.Write(data)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// recordTarget is a RecordTarget in memory that can lose rows
+type recordTarget struct {
+	records []solana.TokenAccountInfo
+	lose    int
+}
+
+func (r *recordTarget) CountRecords(ctx context.Context) (int, error) {
+	return len(r.records), nil
+}
+
+func (r *recordTarget) InsertRecords(ctx context.Context, records []solana.TokenAccountInfo) error {
+	r.records = append(r.records, records[r.lose:]...)
+	return nil
+}
+
+func TestMigrateRecords(t *testing.T) {
+	ctx := context.Background()
+	source, err := NewFileStore(filepath.Join(t.TempDir(), "history.jsonl"), nil)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	defer source.Close()
+	at := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		record := solana.TokenAccountInfo{Owner: "w1", Mint: "mint", Address: "a1", Balance: uint64(i + 1), LastUpdatedAt: at.Add(time.Duration(i) * time.Hour)}
+		if err := source.SaveBalance(ctx, record); err != nil {
+			t.Fatalf("SaveBalance: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		target  *recordTarget
+		wantErr string
+	}{
+		{"empty target", &recordTarget{}, ""},
+		{"target not empty", &recordTarget{records: make([]solana.TokenAccountInfo, 1)}, "target is not empty"},
+		{"rows lost", &recordTarget{lose: 1}, "verification failed"},
+	}
+	for _, tt := range tests {
+		report, err := MigrateRecords(ctx, source, tt.target)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if report.Records != 3 || len(tt.target.records) != 3 || tt.target.records[2].Balance != 3 {
+			t.Errorf("%s: copied %d records of report %+v, want 3 in order", tt.name, len(tt.target.records), report)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+//go:build !nopostgres
+
+package store
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+
+	// Register the PostgreSQL driver
+	_ "github.com/lib/pq"
+)
+
+func init() {
+	backends["postgres"] = func(cfg config.StoreConfig) (Store, error) {
+		if cfg.DSN == "" {
+			return nil, fmt.Errorf("postgres store requires dsn")
+		}
+		return NewPostgresStore(cfg.DSN)
+	}
+}
+
+// postgresDialect is the PostgreSQL flavour of the SQL store
+var postgresDialect = dialect{
+	driver:      "postgres",
+	placeholder: func(n int) string { return "$" + strconv.Itoa(n) },
+	schema: []string{
+		`CREATE TABLE IF NOT EXISTS balances (
+			id BIGSERIAL PRIMARY KEY,
+			owner TEXT NOT NULL,
+			mint TEXT NOT NULL,
+			balance TEXT NOT NULL,
+			updated_at BIGINT NOT NULL,
+			data TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS balances_owner_mint ON balances (owner, mint, updated_at)`,
+		`CREATE TABLE IF NOT EXISTS wallets (
+			owner TEXT PRIMARY KEY,
+			archived_at BIGINT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS dead_letters (
+			id TEXT PRIMARY KEY,
+			failed_at BIGINT NOT NULL,
+			data TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS rent (
+			signature TEXT NOT NULL,
+			account TEXT NOT NULL,
+			reclaimed BOOLEAN NOT NULL,
+			wallet TEXT NOT NULL,
+			at BIGINT NOT NULL,
+			data TEXT NOT NULL,
+			PRIMARY KEY (signature, account, reclaimed)
+		)`,
+		`CREATE INDEX IF NOT EXISTS rent_at ON rent (at)`,
+		`CREATE TABLE IF NOT EXISTS transfer_stats (
+			owner TEXT NOT NULL,
+			mint TEXT NOT NULL,
+			data TEXT NOT NULL,
+			PRIMARY KEY (owner, mint)
+		)`,
+		`CREATE TABLE IF NOT EXISTS markers (
+			name TEXT PRIMARY KEY,
+			at BIGINT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS events (
+			id BIGSERIAL PRIMARY KEY,
+			wallet TEXT NOT NULL,
+			at BIGINT NOT NULL,
+			data TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS events_wallet ON events (wallet, id)`,
+		`CREATE INDEX IF NOT EXISTS events_at ON events (at)`,
+	},
+}
+
+// NewPostgresStore connects to a PostgreSQL store using a connection URL
+func NewPostgresStore(dsn string) (*SQLStore, error) {
+	return newSQLStore(postgresDialect, dsn)
+}
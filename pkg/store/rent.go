@@ -0,0 +1,186 @@
+package store
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// RentEntry is rent a wallet paid to create an account, or reclaimed by
+// closing one
+type RentEntry struct {
+	Wallet    string    `json:"wallet"`
+	Account   string    `json:"account"`
+	Signature string    `json:"signature"`
+	Lamports  uint64    `json:"lamports"`
+	Reclaimed bool      `json:"reclaimed,omitempty"`
+	At        time.Time `json:"at"`
+}
+
+// RentStore is implemented by stores that keep the rent paid and reclaimed
+// by wallets
+type RentStore interface {
+	// AddRentEntry stores an entry. An entry of the same transaction,
+	// account and direction is only stored once.
+	AddRentEntry(ctx context.Context, entry RentEntry) error
+	// RentEntries returns the entries since the given time, oldest first
+	RentEntries(ctx context.Context, since time.Time) ([]RentEntry, error)
+}
+
+// same reports whether two entries record the same rent movement
+func (e RentEntry) same(other RentEntry) bool {
+	return e.Signature == other.Signature && e.Account == other.Account && e.Reclaimed == other.Reclaimed
+}
+
+// AddRentEntry appends an entry to the rent side file
+func (s *FileStore) AddRentEntry(ctx context.Context, entry RentEntry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entries, err := s.loadRentEntries()
+	if err != nil {
+		return err
+	}
+	for _, existing := range entries {
+		if existing.same(entry) {
+			return nil
+		}
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(s.rentPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// RentEntries returns the entries of the rent side file since the given time
+func (s *FileStore) RentEntries(ctx context.Context, since time.Time) ([]RentEntry, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	entries, err := s.loadRentEntries()
+	if err != nil {
+		return nil, err
+	}
+	var result []RentEntry
+	for _, entry := range entries {
+		if !entry.At.Before(since) {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+// rentPath returns the path of the rent side file
+func (s *FileStore) rentPath() string {
+	return s.path + ".rent.jsonl"
+}
+
+// loadRentEntries reads the rent side file. It is read on every call so the
+// rent report sees the entries of a running tracker.
+func (s *FileStore) loadRentEntries() ([]RentEntry, error) {
+	file, err := os.Open(s.rentPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []RentEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry RentEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// purgeRentEntries removes the entries of a wallet from the rent side file.
+// Callers must hold the write lock.
+func (s *FileStore) purgeRentEntries(wallet string) error {
+	entries, err := s.loadRentEntries()
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+
+	tmpPath := s.rentPath() + ".tmp"
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(tmp)
+	for _, entry := range entries {
+		if entry.Wallet == wallet {
+			continue
+		}
+		data, err := json.Marshal(entry)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		writer.Write(append(data, '\n'))
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, s.rentPath())
+}
+
+// AddRentEntry inserts an entry unless it is already stored
+func (s *SQLStore) AddRentEntry(ctx context.Context, entry RentEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		s.query(`INSERT INTO rent (signature, account, reclaimed, wallet, at, data) VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (signature, account, reclaimed) DO NOTHING`),
+		entry.Signature, entry.Account, entry.Reclaimed, entry.Wallet, entry.At.UnixNano(), string(data),
+	)
+	return err
+}
+
+// RentEntries returns the entries since the given time, oldest first
+func (s *SQLStore) RentEntries(ctx context.Context, since time.Time) ([]RentEntry, error) {
+	rows, err := s.db.QueryContext(ctx, s.query("SELECT data FROM rent WHERE at >= ? ORDER BY at"), since.UnixNano())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []RentEntry
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+
+		var entry RentEntry
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Kinds of searchable documents
+const (
+	DocumentLabel = "label"
+	DocumentNote  = "note"
+	DocumentMemo  = "memo"
+)
+
+// Document is a piece of text indexed for full-text search
+type Document struct {
+	Kind string `json:"kind"`
+	// Key identifies the document within its kind: the wallet address for
+	// labels and notes, the transaction signature for memos
+	Key    string    `json:"key"`
+	Wallet string    `json:"wallet,omitempty"`
+	Text   string    `json:"text"`
+	At     time.Time `json:"at"`
+}
+
+// SearchResult is a matching document with the matched text highlighted
+type SearchResult struct {
+	Document
+	Snippet string `json:"snippet"`
+}
+
+// Searcher is implemented by stores with full-text search
+type Searcher interface {
+	// IndexDocument adds a document, replacing one of the same kind and key.
+	// A document without text removes it.
+	IndexDocument(ctx context.Context, doc Document) error
+	// Search returns the documents matching all words of the query, best
+	// match first
+	Search(ctx context.Context, query string, limit int) ([]SearchResult, error)
+}
+
+// matchQuery turns free text into a full-text query matching every word.
+// Words are quoted so punctuation such as "#" in "invoice #123" isn't read
+// as query syntax.
+func matchQuery(text string) string {
+	words := strings.Fields(text)
+	for i, word := range words {
+		words[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"`
+	}
+	return strings.Join(words, " ")
+}
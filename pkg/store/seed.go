@@ -0,0 +1,113 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// seededMarker names the marker of the first initial state load
+const seededMarker = "seeded"
+
+// SeedMarker is implemented by stores that remember whether the tracker
+// loaded the initial state of its wallets before, so the first start can
+// seed it silently and later ones report the changes since the last stored
+// balances
+type SeedMarker interface {
+	// SeededAt returns when the initial state was first loaded, zero if
+	// it never was
+	SeededAt(ctx context.Context) (time.Time, error)
+	// MarkSeeded records the first load of the initial state. Later calls
+	// keep the first time.
+	MarkSeeded(ctx context.Context, at time.Time) error
+}
+
+// SeededAt reads the seeded marker side file
+func (s *FileStore) SeededAt(ctx context.Context) (time.Time, error) {
+	data, err := ioutil.ReadFile(s.seededPath())
+	if os.IsNotExist(err) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	var at time.Time
+	if err := json.Unmarshal(data, &at); err != nil {
+		return time.Time{}, err
+	}
+	return at, nil
+}
+
+// MarkSeeded writes the seeded marker side file unless it exists
+func (s *FileStore) MarkSeeded(ctx context.Context, at time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err := os.Stat(s.seededPath()); err == nil {
+		return nil
+	}
+	data, err := json.Marshal(at.UTC())
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.seededPath(), data, 0644)
+}
+
+// seededPath returns the path of the seeded marker side file
+func (s *FileStore) seededPath() string {
+	return s.path + ".seeded.json"
+}
+
+// SeededAt reads the seeded marker
+func (s *SQLStore) SeededAt(ctx context.Context) (time.Time, error) {
+	var at int64
+	err := s.db.QueryRowContext(ctx, s.query("SELECT at FROM markers WHERE name = ?"), seededMarker).Scan(&at)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, at).UTC(), nil
+}
+
+// MarkSeeded inserts the seeded marker unless it exists
+func (s *SQLStore) MarkSeeded(ctx context.Context, at time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		s.query("INSERT INTO markers (name, at) VALUES (?, ?) ON CONFLICT (name) DO NOTHING"),
+		seededMarker, at.UnixNano(),
+	)
+	return err
+}
+
+// LatestBalances returns the most recent record of every token account,
+// ordered by owner and mint
+func LatestBalances(records []solana.TokenAccountInfo) []solana.TokenAccountInfo {
+	latest := make(map[string]solana.TokenAccountInfo)
+	for _, record := range records {
+		key := record.Owner + ":" + record.Mint
+		if current, ok := latest[key]; !ok || !record.LastUpdatedAt.Before(current.LastUpdatedAt) {
+			latest[key] = record
+		}
+	}
+
+	snapshot := make([]solana.TokenAccountInfo, 0, len(latest))
+	for _, record := range latest {
+		snapshot = append(snapshot, record)
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		if snapshot[i].Owner != snapshot[j].Owner {
+			return snapshot[i].Owner < snapshot[j].Owner
+		}
+		return snapshot[i].Mint < snapshot[j].Mint
+	})
+
+	return snapshot
+}
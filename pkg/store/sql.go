@@ -0,0 +1,209 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// dialect captures the SQL differences between database backends
+type dialect struct {
+	driver string
+	// placeholder returns the bind parameter for the nth argument (1-based)
+	placeholder func(n int) string
+	schema      []string
+}
+
+// SQLStore persists balance history in a SQL database. Each record is kept
+// as JSON alongside indexed lookup columns, so new TokenAccountInfo fields
+// need no schema migration.
+type SQLStore struct {
+	db      *sql.DB
+	dialect dialect
+	// appendMutex serializes event appends, so events commit in the order
+	// of their IDs
+	appendMutex sync.Mutex
+}
+
+// newSQLStore opens a database and ensures the schema exists
+func newSQLStore(d dialect, dsn string) (*SQLStore, error) {
+	db, err := sql.Open(d.driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, statement := range d.schema {
+		if _, err := db.Exec(statement); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create schema: %w", err)
+		}
+	}
+
+	return &SQLStore{db: db, dialect: d}, nil
+}
+
+// query rewrites ? placeholders into the dialect's bind parameters
+func (s *SQLStore) query(q string) string {
+	out := make([]byte, 0, len(q))
+	n := 0
+	for i := 0; i < len(q); i++ {
+		if q[i] == '?' {
+			n++
+			out = append(out, s.dialect.placeholder(n)...)
+			continue
+		}
+		out = append(out, q[i])
+	}
+	return string(out)
+}
+
+// SaveBalance inserts a balance observation
+func (s *SQLStore) SaveBalance(ctx context.Context, accountInfo solana.TokenAccountInfo) error {
+	data, err := json.Marshal(accountInfo)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		s.query("INSERT INTO balances (owner, mint, balance, updated_at, data) VALUES (?, ?, ?, ?, ?)"),
+		accountInfo.Owner,
+		accountInfo.Mint,
+		strconv.FormatUint(accountInfo.Balance, 10),
+		accountInfo.LastUpdatedAt.UnixNano(),
+		string(data),
+	)
+	return err
+}
+
+// History returns balance observations for a wallet and mint since the given time
+func (s *SQLStore) History(ctx context.Context, owner, mint string, since time.Time) ([]solana.TokenAccountInfo, error) {
+	return s.scan(ctx,
+		"SELECT data FROM balances WHERE owner = ? AND mint = ? AND updated_at >= ? ORDER BY id",
+		owner, mint, since.UnixNano(),
+	)
+}
+
+// Records returns every stored observation in insertion order
+func (s *SQLStore) Records(ctx context.Context) ([]solana.TokenAccountInfo, error) {
+	return s.scan(ctx, "SELECT data FROM balances ORDER BY id")
+}
+
+// HasHeld reports whether a wallet ever had a non-zero balance of a mint
+func (s *SQLStore) HasHeld(ctx context.Context, owner, mint string) (bool, error) {
+	var found int
+	err := s.db.QueryRowContext(ctx,
+		s.query("SELECT 1 FROM balances WHERE owner = ? AND mint = ? AND balance <> '0' LIMIT 1"),
+		owner, mint,
+	).Scan(&found)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Wallets returns every wallet with stored history and its archive status
+func (s *SQLStore) Wallets(ctx context.Context) ([]WalletStatus, error) {
+	rows, err := s.db.QueryContext(ctx, s.query(`
+		SELECT owners.owner, wallets.archived_at
+		FROM (SELECT DISTINCT owner FROM balances UNION SELECT owner FROM wallets) owners
+		LEFT JOIN wallets ON wallets.owner = owners.owner
+		ORDER BY owners.owner`))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var wallets []WalletStatus
+	for rows.Next() {
+		var status WalletStatus
+		var archivedAt sql.NullInt64
+		if err := rows.Scan(&status.Owner, &archivedAt); err != nil {
+			return nil, err
+		}
+		if archivedAt.Valid {
+			at := time.Unix(0, archivedAt.Int64).UTC()
+			status.ArchivedAt = &at
+		}
+		wallets = append(wallets, status)
+	}
+
+	return wallets, rows.Err()
+}
+
+// ArchiveWallet marks a wallet's history as archived
+func (s *SQLStore) ArchiveWallet(ctx context.Context, owner string, at time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		s.query("INSERT INTO wallets (owner, archived_at) VALUES (?, ?) ON CONFLICT (owner) DO UPDATE SET archived_at = excluded.archived_at"),
+		owner, at.UnixNano(),
+	)
+	return err
+}
+
+// RestoreWallet clears the archived mark of a wallet
+func (s *SQLStore) RestoreWallet(ctx context.Context, owner string) error {
+	_, err := s.db.ExecContext(ctx, s.query("DELETE FROM wallets WHERE owner = ?"), owner)
+	return err
+}
+
+// PurgeWallet permanently deletes all history of a wallet
+func (s *SQLStore) PurgeWallet(ctx context.Context, owner string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, s.query("DELETE FROM balances WHERE owner = ?"), owner); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, s.query("DELETE FROM wallets WHERE owner = ?"), owner); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, s.query("DELETE FROM rent WHERE wallet = ?"), owner); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, s.query("DELETE FROM transfer_stats WHERE owner = ?"), owner); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, s.query("DELETE FROM events WHERE wallet = ?"), owner); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Close closes the database
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
+
+// scan runs a query returning JSON records and decodes them
+func (s *SQLStore) scan(ctx context.Context, q string, args ...interface{}) ([]solana.TokenAccountInfo, error) {
+	rows, err := s.db.QueryContext(ctx, s.query(q), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []solana.TokenAccountInfo
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+
+		var record solana.TokenAccountInfo
+		if err := json.Unmarshal([]byte(data), &record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
@@ -0,0 +1,160 @@
+//go:build !nosqlite
+
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+
+	// Register the pure Go SQLite driver
+	_ "modernc.org/sqlite"
+)
+
+func init() {
+	backends["sqlite"] = func(cfg config.StoreConfig) (Store, error) {
+		path := cfg.Path
+		if path == "" {
+			path = "history.db"
+		}
+		return NewSQLiteStore(path)
+	}
+}
+
+// sqliteDialect is the SQLite flavour of the SQL store
+var sqliteDialect = dialect{
+	driver:      "sqlite",
+	placeholder: func(n int) string { return "?" },
+	schema: []string{
+		`CREATE TABLE IF NOT EXISTS balances (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			owner TEXT NOT NULL,
+			mint TEXT NOT NULL,
+			balance TEXT NOT NULL,
+			updated_at INTEGER NOT NULL,
+			data TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS balances_owner_mint ON balances (owner, mint, updated_at)`,
+		`CREATE TABLE IF NOT EXISTS wallets (
+			owner TEXT PRIMARY KEY,
+			archived_at BIGINT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS dead_letters (
+			id TEXT PRIMARY KEY,
+			failed_at BIGINT NOT NULL,
+			data TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS rent (
+			signature TEXT NOT NULL,
+			account TEXT NOT NULL,
+			reclaimed BOOLEAN NOT NULL,
+			wallet TEXT NOT NULL,
+			at BIGINT NOT NULL,
+			data TEXT NOT NULL,
+			PRIMARY KEY (signature, account, reclaimed)
+		)`,
+		`CREATE INDEX IF NOT EXISTS rent_at ON rent (at)`,
+		`CREATE TABLE IF NOT EXISTS transfer_stats (
+			owner TEXT NOT NULL,
+			mint TEXT NOT NULL,
+			data TEXT NOT NULL,
+			PRIMARY KEY (owner, mint)
+		)`,
+		`CREATE TABLE IF NOT EXISTS markers (
+			name TEXT PRIMARY KEY,
+			at BIGINT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			wallet TEXT NOT NULL,
+			at BIGINT NOT NULL,
+			data TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS events_wallet ON events (wallet, id)`,
+		`CREATE INDEX IF NOT EXISTS events_at ON events (at)`,
+		`CREATE VIRTUAL TABLE IF NOT EXISTS search_index USING fts5(
+			kind UNINDEXED,
+			key UNINDEXED,
+			wallet UNINDEXED,
+			at UNINDEXED,
+			text
+		)`,
+	},
+}
+
+// SQLiteStore is a SQL store in SQLite, which adds full-text search
+type SQLiteStore struct {
+	*SQLStore
+}
+
+// NewSQLiteStore opens or creates a SQLite store at path
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	s, err := newSQLStore(sqliteDialect, path)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows a single writer at a time
+	s.db.SetMaxOpenConns(1)
+
+	return &SQLiteStore{s}, nil
+}
+
+// IndexDocument adds a document to the search index, replacing one of the
+// same kind and key. A document without text removes it.
+func (s *SQLiteStore) IndexDocument(ctx context.Context, doc Document) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM search_index WHERE kind = ? AND key = ?", doc.Kind, doc.Key); err != nil {
+		return err
+	}
+	if doc.Text == "" {
+		return tx.Commit()
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO search_index (kind, key, wallet, at, text) VALUES (?, ?, ?, ?, ?)",
+		doc.Kind, doc.Key, doc.Wallet, doc.At.UnixNano(), doc.Text,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Search returns the documents matching all words of the query, best match
+// first
+func (s *SQLiteStore) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	match := matchQuery(query)
+	if match == "" {
+		return nil, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT kind, key, wallet, at, text, snippet(search_index, 4, '[', ']', '…', 12)
+		FROM search_index
+		WHERE search_index MATCH ?
+		ORDER BY rank
+		LIMIT ?`,
+		match, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var result SearchResult
+		var at int64
+		if err := rows.Scan(&result.Kind, &result.Key, &result.Wallet, &at, &result.Text, &result.Snippet); err != nil {
+			return nil, err
+		}
+		result.At = time.Unix(0, at).UTC()
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// TransferStats are rolling statistics of the transfer sizes of a wallet and
+// mint, in UI units
+type TransferStats struct {
+	Owner     string    `json:"owner"`
+	Mint      string    `json:"mint"`
+	Count     int64     `json:"count"`
+	Mean      float64   `json:"mean"`
+	Variance  float64   `json:"variance"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// StatsStore is implemented by stores that keep transfer statistics
+type StatsStore interface {
+	// TransferStats returns the statistics of a wallet and mint, which are
+	// empty when none were saved
+	TransferStats(ctx context.Context, owner, mint string) (TransferStats, error)
+	// SaveTransferStats replaces the statistics of a wallet and mint
+	SaveTransferStats(ctx context.Context, stats TransferStats) error
+}
+
+// TransferStats returns statistics from the stats side file
+func (s *FileStore) TransferStats(ctx context.Context, owner, mint string) (TransferStats, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	all, err := s.loadTransferStats()
+	if err != nil {
+		return TransferStats{}, err
+	}
+	if stats, ok := all[owner+":"+mint]; ok {
+		return stats, nil
+	}
+	return TransferStats{Owner: owner, Mint: mint}, nil
+}
+
+// SaveTransferStats rewrites the stats side file with the statistics
+func (s *FileStore) SaveTransferStats(ctx context.Context, stats TransferStats) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	all, err := s.loadTransferStats()
+	if err != nil {
+		return err
+	}
+	all[stats.Owner+":"+stats.Mint] = stats
+	return s.saveTransferStats(all)
+}
+
+// statsPath returns the path of the stats side file
+func (s *FileStore) statsPath() string {
+	return s.path + ".stats.json"
+}
+
+// loadTransferStats reads the stats side file, keyed by owner and mint
+func (s *FileStore) loadTransferStats() (map[string]TransferStats, error) {
+	all := make(map[string]TransferStats)
+	data, err := ioutil.ReadFile(s.statsPath())
+	if os.IsNotExist(err) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// saveTransferStats writes the stats side file. Callers must hold the write
+// lock.
+func (s *FileStore) saveTransferStats(all map[string]TransferStats) error {
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.statsPath(), data, 0644)
+}
+
+// purgeTransferStats removes the statistics of a wallet from the stats side
+// file. Callers must hold the write lock.
+func (s *FileStore) purgeTransferStats(owner string) error {
+	all, err := s.loadTransferStats()
+	if err != nil || len(all) == 0 {
+		return err
+	}
+	for key, stats := range all {
+		if stats.Owner == owner {
+			delete(all, key)
+		}
+	}
+	return s.saveTransferStats(all)
+}
+
+// TransferStats returns the statistics of a wallet and mint
+func (s *SQLStore) TransferStats(ctx context.Context, owner, mint string) (TransferStats, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx,
+		s.query("SELECT data FROM transfer_stats WHERE owner = ? AND mint = ?"), owner, mint,
+	).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return TransferStats{Owner: owner, Mint: mint}, nil
+	}
+	if err != nil {
+		return TransferStats{}, err
+	}
+
+	var stats TransferStats
+	if err := json.Unmarshal([]byte(data), &stats); err != nil {
+		return TransferStats{}, err
+	}
+	return stats, nil
+}
+
+// SaveTransferStats inserts or replaces the statistics of a wallet and mint
+func (s *SQLStore) SaveTransferStats(ctx context.Context, stats TransferStats) error {
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		s.query(`INSERT INTO transfer_stats (owner, mint, data) VALUES (?, ?, ?)
+			ON CONFLICT (owner, mint) DO UPDATE SET data = excluded.data`),
+		stats.Owner, stats.Mint, string(data),
+	)
+	return err
+}
@@ -0,0 +1,82 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// Store persists token balance history
+type Store interface {
+	// SaveBalance records a token account balance observation
+	SaveBalance(ctx context.Context, accountInfo solana.TokenAccountInfo) error
+	// History returns observations for a wallet and mint since the given time, oldest first
+	History(ctx context.Context, owner, mint string, since time.Time) ([]solana.TokenAccountInfo, error)
+	// Records returns every stored observation, oldest first
+	Records(ctx context.Context) ([]solana.TokenAccountInfo, error)
+	// HasHeld reports whether a wallet ever had a non-zero balance of a mint
+	HasHeld(ctx context.Context, owner, mint string) (bool, error)
+
+	// Wallets returns every wallet with stored history and its archive status
+	Wallets(ctx context.Context) ([]WalletStatus, error)
+	// ArchiveWallet marks a wallet's history as archived. Archived history
+	// stays queryable but is excluded from live views.
+	ArchiveWallet(ctx context.Context, owner string, at time.Time) error
+	// RestoreWallet clears the archived mark of a wallet
+	RestoreWallet(ctx context.Context, owner string) error
+	// PurgeWallet permanently deletes all history of a wallet
+	PurgeWallet(ctx context.Context, owner string) error
+
+	// Close releases resources held by the store
+	Close() error
+}
+
+// WalletStatus describes a wallet known to the store
+type WalletStatus struct {
+	Owner      string     `json:"owner"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+}
+
+// Archived reports whether the wallet is archived
+func (w WalletStatus) Archived() bool {
+	return w.ArchivedAt != nil
+}
+
+// opener creates a store of one type from its configuration
+type opener func(cfg config.StoreConfig) (Store, error)
+
+// backends are the optional store types compiled into the binary. Each
+// registers itself from a file excluded by its build tag, so minimal builds
+// leave the database drivers out.
+var backends = map[string]opener{}
+
+// backendTags are the build tags that exclude optional store types
+var backendTags = map[string]string{
+	"sqlite":   "nosqlite",
+	"postgres": "nopostgres",
+}
+
+// New creates a store from its configuration, logging through log or the
+// default logger when nil
+func New(cfg config.StoreConfig, log logging.Logger) (Store, error) {
+	switch cfg.Type {
+	case "", "file":
+		path := cfg.Path
+		if path == "" {
+			path = "history.jsonl"
+		}
+		return NewFileStore(path, log)
+	}
+
+	if open, ok := backends[cfg.Type]; ok {
+		return open(cfg)
+	}
+	if tag, ok := backendTags[cfg.Type]; ok {
+		return nil, fmt.Errorf("%s store is not available in this build (built with the %s tag)", cfg.Type, tag)
+	}
+	return nil, fmt.Errorf("unknown store type: %q", cfg.Type)
+}
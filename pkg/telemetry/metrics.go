@@ -0,0 +1,182 @@
+package telemetry
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DurationBounds are the bucket bounds of duration histograms, in
+// milliseconds
+var DurationBounds = []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000, 300000}
+
+// instrument is a metric whose data points are exported
+type instrument interface {
+	collect(now time.Time) metric
+}
+
+// instruments are every counter and histogram created
+var instruments struct {
+	mu   sync.Mutex
+	list []instrument
+}
+
+// register adds an instrument to the exported ones
+func register(i instrument) {
+	instruments.mu.Lock()
+	defer instruments.mu.Unlock()
+	instruments.list = append(instruments.list, i)
+}
+
+// collect returns the metrics of every instrument with data points
+func collect() []metric {
+	instruments.mu.Lock()
+	list := append([]instrument(nil), instruments.list...)
+	instruments.mu.Unlock()
+
+	now := time.Now()
+	var metrics []metric
+	for _, i := range list {
+		if m := i.collect(now); len(m.points) > 0 {
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics
+}
+
+// Counter is a cumulative count, e.g. of notifications received
+type Counter struct {
+	name        string
+	description string
+	unit        string
+	start       time.Time
+
+	mu     sync.Mutex
+	points map[string]*point
+}
+
+// NewCounter creates a counter and exports it with the default provider
+func NewCounter(name, description, unit string) *Counter {
+	c := &Counter{name: name, description: description, unit: unit, start: time.Now(), points: make(map[string]*point)}
+	register(c)
+	return c
+}
+
+// Add adds n to the count of the attributes
+func (c *Counter) Add(n int64, attrs ...Attribute) {
+	if !Enabled() {
+		return
+	}
+	key := attributeKey(attrs)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.points[key]
+	if !ok {
+		p = &point{attrs: attrs}
+		c.points[key] = p
+	}
+	p.count += uint64(n)
+}
+
+// collect returns the counts of the counter
+func (c *Counter) collect(now time.Time) metric {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := metric{name: c.name, description: c.description, unit: c.unit, start: c.start, time: now}
+	for _, p := range c.points {
+		m.points = append(m.points, *p)
+	}
+	return m
+}
+
+// Histogram is a cumulative distribution of values, e.g. durations
+type Histogram struct {
+	name        string
+	description string
+	unit        string
+	bounds      []float64
+	start       time.Time
+
+	mu     sync.Mutex
+	points map[string]*point
+}
+
+// NewHistogram creates a histogram with explicit bucket bounds and exports
+// it with the default provider
+func NewHistogram(name, description, unit string, bounds []float64) *Histogram {
+	h := &Histogram{name: name, description: description, unit: unit, bounds: bounds, start: time.Now(), points: make(map[string]*point)}
+	register(h)
+	return h
+}
+
+// Record adds a value to the distribution of the attributes
+func (h *Histogram) Record(value float64, attrs ...Attribute) {
+	if !Enabled() {
+		return
+	}
+	key := attributeKey(attrs)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	p, ok := h.points[key]
+	if !ok {
+		p = &point{attrs: attrs, buckets: make([]uint64, len(h.bounds)+1), min: value, max: value}
+		h.points[key] = p
+	}
+	p.count++
+	p.sum += value
+	if value < p.min {
+		p.min = value
+	}
+	if value > p.max {
+		p.max = value
+	}
+	p.buckets[sort.SearchFloat64s(h.bounds, value)]++
+}
+
+// collect returns the distributions of the histogram
+func (h *Histogram) collect(now time.Time) metric {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	m := metric{name: h.name, description: h.description, unit: h.unit, start: h.start, time: now, bounds: h.bounds}
+	for _, p := range h.points {
+		p := *p
+		p.buckets = append([]uint64(nil), p.buckets...)
+		m.points = append(m.points, p)
+	}
+	return m
+}
+
+// metric is the state of an instrument at a point in time. Histograms have
+// bounds; counters don't.
+type metric struct {
+	name        string
+	description string
+	unit        string
+	start       time.Time
+	time        time.Time
+	bounds      []float64
+	points      []point
+}
+
+// point is the value of a metric for a set of attributes
+type point struct {
+	attrs []Attribute
+	count uint64
+	// The distribution of histograms
+	sum     float64
+	min     float64
+	max     float64
+	buckets []uint64
+}
+
+// attributeKey identifies a set of attributes regardless of their order
+func attributeKey(attrs []Attribute) string {
+	parts := make([]string, len(attrs))
+	for i, attr := range attrs {
+		parts[i] = fmt.Sprintf("%s=%v", attr.Key, attr.Value)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "\x00")
+}
@@ -0,0 +1,214 @@
+package telemetry
+
+import (
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// scopeName names the instrumentation scope of every span and metric
+const scopeName = "github.com/yourusername/solana-wallet-tracker"
+
+// OTLP JSON encoding, see
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding. 64-bit
+// integers are strings.
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpStatus struct {
+	// Code is 1 for ok and 2 for error
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              SpanKind       `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpDataPoint struct {
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	TimeUnixNano      string         `json:"timeUnixNano"`
+	// AsInt is the value of sums
+	AsInt string `json:"asInt,omitempty"`
+	// The distribution of histograms
+	Count          string    `json:"count,omitempty"`
+	Sum            *float64  `json:"sum,omitempty"`
+	Min            *float64  `json:"min,omitempty"`
+	Max            *float64  `json:"max,omitempty"`
+	BucketCounts   []string  `json:"bucketCounts,omitempty"`
+	ExplicitBounds []float64 `json:"explicitBounds,omitempty"`
+}
+
+type otlpSum struct {
+	DataPoints []otlpDataPoint `json:"dataPoints"`
+	// AggregationTemporality is 2, cumulative
+	AggregationTemporality int  `json:"aggregationTemporality"`
+	IsMonotonic            bool `json:"isMonotonic"`
+}
+
+type otlpHistogram struct {
+	DataPoints             []otlpDataPoint `json:"dataPoints"`
+	AggregationTemporality int             `json:"aggregationTemporality"`
+}
+
+type otlpMetric struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Unit        string         `json:"unit,omitempty"`
+	Sum         *otlpSum       `json:"sum,omitempty"`
+	Histogram   *otlpHistogram `json:"histogram,omitempty"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   otlpScope    `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource     otlpResource       `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpMetrics struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+// cumulative is the aggregation temporality of every metric
+const cumulative = 2
+
+// traces encodes spans as an export request
+func (p *Provider) traces(spans []spanData) otlpTraces {
+	encoded := make([]otlpSpan, 0, len(spans))
+	for _, data := range spans {
+		span := otlpSpan{
+			TraceID:           hex.EncodeToString(data.span.context.TraceID[:]),
+			SpanID:            hex.EncodeToString(data.span.context.SpanID[:]),
+			Name:              data.span.name,
+			Kind:              data.span.kind,
+			StartTimeUnixNano: nanos(data.span.start),
+			EndTimeUnixNano:   nanos(data.end),
+			Attributes:        keyValues(data.span.attrs),
+			Status:            otlpStatus{Code: 1},
+		}
+		if data.span.parent != (SpanID{}) {
+			span.ParentSpanID = hex.EncodeToString(data.span.parent[:])
+		}
+		if data.span.err != "" {
+			span.Status = otlpStatus{Code: 2, Message: data.span.err}
+		}
+		encoded = append(encoded, span)
+	}
+
+	return otlpTraces{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: keyValues(p.resource)},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: scopeName}, Spans: encoded}},
+	}}}
+}
+
+// metrics encodes metrics as an export request
+func (p *Provider) metrics(metrics []metric) otlpMetrics {
+	encoded := make([]otlpMetric, 0, len(metrics))
+	for _, m := range metrics {
+		out := otlpMetric{Name: m.name, Description: m.description, Unit: m.unit}
+		points := make([]otlpDataPoint, 0, len(m.points))
+		for _, pt := range m.points {
+			dataPoint := otlpDataPoint{
+				Attributes:        keyValues(pt.attrs),
+				StartTimeUnixNano: nanos(m.start),
+				TimeUnixNano:      nanos(m.time),
+			}
+			if m.bounds == nil {
+				dataPoint.AsInt = strconv.FormatUint(pt.count, 10)
+			} else {
+				sum, low, high := pt.sum, pt.min, pt.max
+				dataPoint.Count = strconv.FormatUint(pt.count, 10)
+				dataPoint.Sum, dataPoint.Min, dataPoint.Max = &sum, &low, &high
+				dataPoint.ExplicitBounds = m.bounds
+				for _, count := range pt.buckets {
+					dataPoint.BucketCounts = append(dataPoint.BucketCounts, strconv.FormatUint(count, 10))
+				}
+			}
+			points = append(points, dataPoint)
+		}
+		if m.bounds == nil {
+			out.Sum = &otlpSum{DataPoints: points, AggregationTemporality: cumulative, IsMonotonic: true}
+		} else {
+			out.Histogram = &otlpHistogram{DataPoints: points, AggregationTemporality: cumulative}
+		}
+		encoded = append(encoded, out)
+	}
+
+	return otlpMetrics{ResourceMetrics: []otlpResourceMetrics{{
+		Resource:     otlpResource{Attributes: keyValues(p.resource)},
+		ScopeMetrics: []otlpScopeMetrics{{Scope: otlpScope{Name: scopeName}, Metrics: encoded}},
+	}}}
+}
+
+// keyValues encodes attributes
+func keyValues(attrs []Attribute) []otlpKeyValue {
+	encoded := make([]otlpKeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		var value otlpAnyValue
+		switch v := attr.Value.(type) {
+		case string:
+			value.StringValue = &v
+		case int64:
+			s := strconv.FormatInt(v, 10)
+			value.IntValue = &s
+		case float64:
+			value.DoubleValue = &v
+		case bool:
+			value.BoolValue = &v
+		default:
+			continue
+		}
+		encoded = append(encoded, otlpKeyValue{Key: attr.Key, Value: value})
+	}
+	return encoded
+}
+
+// nanos encodes a time as nanoseconds since the epoch
+func nanos(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
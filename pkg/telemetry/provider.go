@@ -0,0 +1,214 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+)
+
+// Defaults of the provider
+const (
+	DefaultServiceName    = "solana-wallet-tracker"
+	DefaultMetricInterval = time.Minute
+)
+
+// spanInterval is how often queued spans are exported
+const spanInterval = 5 * time.Second
+
+// maxQueuedSpans is how many spans wait for export before new ones are
+// dropped
+const maxQueuedSpans = 10000
+
+// exportTimeout bounds an export request
+const exportTimeout = 10 * time.Second
+
+// Options configure a provider
+type Options struct {
+	// Endpoint is the base URL of the OTLP HTTP receiver, e.g.
+	// http://localhost:4318; spans go to /v1/traces and metrics to
+	// /v1/metrics
+	Endpoint string
+	// Headers are sent with every export, e.g. the API key of a vendor
+	Headers     map[string]string
+	ServiceName string
+	// Attributes describe the process, e.g. deployment.environment
+	Attributes []Attribute
+	// SampleRatio is the share of traces exported, decided at their root
+	SampleRatio float64
+	// MetricInterval is how often metrics are exported
+	MetricInterval time.Duration
+}
+
+// Provider exports spans and metrics to an OTLP receiver
+type Provider struct {
+	options    Options
+	resource   []Attribute
+	httpClient *http.Client
+	log        logging.Logger
+
+	mu      sync.Mutex
+	spans   []spanData
+	dropped int
+}
+
+// spanData is an ended span
+type spanData struct {
+	span Span
+	end  time.Time
+}
+
+// defaultProvider is the provider of Start and the instruments, nil while
+// telemetry is disabled
+var defaultProvider atomic.Value
+
+// SetDefault makes a provider record every span and metric. Call it before
+// the instrumented clients and monitors are created.
+func SetDefault(provider *Provider) {
+	defaultProvider.Store(provider)
+}
+
+// Default returns the default provider, nil while telemetry is disabled
+func Default() *Provider {
+	provider, _ := defaultProvider.Load().(*Provider)
+	return provider
+}
+
+// Enabled reports whether a default provider records telemetry
+func Enabled() bool {
+	return Default() != nil
+}
+
+// NewProvider creates a provider. Zero options use the defaults; a zero
+// SampleRatio samples every trace.
+func NewProvider(options Options, log logging.Logger) *Provider {
+	if options.ServiceName == "" {
+		options.ServiceName = DefaultServiceName
+	}
+	if options.SampleRatio <= 0 {
+		options.SampleRatio = 1
+	}
+	if options.MetricInterval <= 0 {
+		options.MetricInterval = DefaultMetricInterval
+	}
+	options.Endpoint = strings.TrimSuffix(options.Endpoint, "/")
+	if log == nil {
+		log = logging.Default()
+	}
+	return &Provider{
+		options:    options,
+		resource:   append([]Attribute{String("service.name", options.ServiceName)}, options.Attributes...),
+		httpClient: &http.Client{Timeout: exportTimeout},
+		log:        log,
+	}
+}
+
+// sample decides whether a new trace is exported
+func (p *Provider) sample() bool {
+	return sampleRandom(p.options.SampleRatio)
+}
+
+// queue queues an ended span for export
+func (p *Provider) queue(span spanData) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.spans) >= maxQueuedSpans {
+		p.dropped++
+		return
+	}
+	p.spans = append(p.spans, span)
+}
+
+// Run exports the queued spans every few seconds and the metrics each
+// metric interval until ctx is done
+func (p *Provider) Run(ctx context.Context) {
+	spans := time.NewTicker(spanInterval)
+	defer spans.Stop()
+	metrics := time.NewTicker(p.options.MetricInterval)
+	defer metrics.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-spans.C:
+			p.exportSpans(ctx)
+		case <-metrics.C:
+			p.exportMetrics(ctx)
+		}
+	}
+}
+
+// Shutdown exports the spans and metrics left
+func (p *Provider) Shutdown(ctx context.Context) {
+	p.exportSpans(ctx)
+	p.exportMetrics(ctx)
+}
+
+// exportSpans exports the queued spans, logging failures. Spans of a failed
+// export are dropped, as they'd be stale by the next one.
+func (p *Provider) exportSpans(ctx context.Context) {
+	p.mu.Lock()
+	spans := p.spans
+	dropped := p.dropped
+	p.spans = nil
+	p.dropped = 0
+	p.mu.Unlock()
+
+	if dropped > 0 {
+		p.log.Warn("Telemetry span queue is full, dropped spans", "spans", dropped)
+	}
+	if len(spans) == 0 {
+		return
+	}
+	if err := p.post(ctx, "/v1/traces", p.traces(spans)); err != nil {
+		p.log.Warn("Failed to export spans", "spans", len(spans), "error", err)
+	}
+}
+
+// exportMetrics exports the current values of every instrument
+func (p *Provider) exportMetrics(ctx context.Context) {
+	metrics := collect()
+	if len(metrics) == 0 {
+		return
+	}
+	if err := p.post(ctx, "/v1/metrics", p.metrics(metrics)); err != nil {
+		p.log.Warn("Failed to export metrics", "error", err)
+	}
+}
+
+// post sends an OTLP request in its JSON encoding
+func (p *Provider) post(ctx context.Context, path string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, exportTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.options.Endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range p.options.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("collector returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
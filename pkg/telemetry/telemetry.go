@@ -0,0 +1,184 @@
+// Package telemetry traces updates from the chain through the monitor to
+// the notifiers and keeps metrics of them, exported to an OpenTelemetry
+// collector with OTLP over HTTP, without the OpenTelemetry SDK.
+//
+// Spans and metrics are recorded through the default provider, see
+// SetDefault. Without one they cost a nil check.
+package telemetry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"math"
+	"math/big"
+	"time"
+)
+
+// SpanKind is the role of a span in a trace, numbered as in OTLP
+type SpanKind int
+
+// Span kinds
+const (
+	KindInternal SpanKind = 1
+	KindClient   SpanKind = 3
+	KindProducer SpanKind = 4
+	KindConsumer SpanKind = 5
+)
+
+// TraceID identifies a trace
+type TraceID [16]byte
+
+// SpanID identifies a span within a trace
+type SpanID [8]byte
+
+// SpanContext identifies a span, so work done elsewhere, e.g. by an event
+// handler, can continue its trace
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	// Sampled spans are exported, others only carry the trace on
+	Sampled bool
+}
+
+// IsValid reports whether the span context identifies a span
+func (s SpanContext) IsValid() bool {
+	return s.TraceID != TraceID{} && s.SpanID != SpanID{}
+}
+
+// Attribute describes a span or a metric data point. Values are strings,
+// int64s, float64s or bools.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns a string attribute
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int returns an integer attribute
+func Int(key string, value int64) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Bool returns a boolean attribute
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span is a timed operation of a trace. Its methods do nothing on a nil
+// span, which Start returns while telemetry is disabled.
+type Span struct {
+	provider *Provider
+	context  SpanContext
+	parent   SpanID
+	name     string
+	kind     SpanKind
+	start    time.Time
+	attrs    []Attribute
+	err      string
+}
+
+// spanKey keys the span context of a context.Context
+type spanKey struct{}
+
+// ContextWithSpanContext returns a context whose spans continue the trace
+// of span
+func ContextWithSpanContext(ctx context.Context, span SpanContext) context.Context {
+	if !span.IsValid() {
+		return ctx
+	}
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanContextFromContext returns the span context of ctx, which is zero when
+// ctx carries none
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	span, _ := ctx.Value(spanKey{}).(SpanContext)
+	return span
+}
+
+// Start starts a span with the default provider, as a child of the span of
+// ctx or else as the root of a new trace, and returns a context carrying
+// it. It returns ctx and a nil span while telemetry is disabled.
+func Start(ctx context.Context, name string, kind SpanKind, attrs ...Attribute) (context.Context, *Span) {
+	provider := Default()
+	if provider == nil {
+		return ctx, nil
+	}
+
+	span := &Span{
+		provider: provider,
+		name:     name,
+		kind:     kind,
+		start:    time.Now(),
+		attrs:    attrs,
+	}
+	if parent := SpanContextFromContext(ctx); parent.IsValid() {
+		span.context = SpanContext{TraceID: parent.TraceID, Sampled: parent.Sampled}
+		span.parent = parent.SpanID
+	} else {
+		rand.Read(span.context.TraceID[:])
+		span.context.Sampled = provider.sample()
+	}
+	rand.Read(span.context.SpanID[:])
+	return ContextWithSpanContext(ctx, span.context), span
+}
+
+// Context returns the span context of the span
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.context
+}
+
+// SetAttributes adds attributes to the span
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// SetError marks the span failed with err, when not nil
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.err = err.Error()
+}
+
+// End ends the span, queueing sampled spans for export
+func (s *Span) End() {
+	if s == nil || !s.context.Sampled {
+		return
+	}
+	s.provider.queue(spanData{span: *s, end: time.Now()})
+}
+
+// Traceparent returns the W3C traceparent header value of a span context
+func Traceparent(span SpanContext) string {
+	flags := "00"
+	if span.Sampled {
+		flags = "01"
+	}
+	return "00-" + hex.EncodeToString(span.TraceID[:]) + "-" + hex.EncodeToString(span.SpanID[:]) + "-" + flags
+}
+
+// sampleRandom returns whether a random draw falls within ratio
+func sampleRandom(ratio float64) bool {
+	if ratio >= 1 {
+		return true
+	}
+	if ratio <= 0 {
+		return false
+	}
+	n, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
+	if err != nil {
+		return false
+	}
+	return float64(n.Int64())/math.MaxInt64 < ratio
+}
@@ -0,0 +1,120 @@
+//go:build !noinflux
+
+package timeseries
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+)
+
+func init() {
+	backends["influxdb"] = func(cfg config.TimeSeriesConfig) (Writer, error) {
+		return NewInfluxWriter(cfg.URL, cfg.Token, cfg.Org, cfg.Bucket, cfg.Measurement), nil
+	}
+}
+
+// DefaultMeasurement names the InfluxDB points unless configured
+const DefaultMeasurement = "balance"
+
+// influxTimeout bounds a write to InfluxDB
+const influxTimeout = 10 * time.Second
+
+// Escapers of the line protocol
+var (
+	measurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `, "\n", `\n`)
+	tagEscaper         = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `, "\n", `\n`)
+)
+
+// InfluxWriter writes points with the line protocol to the v2 write API,
+// which InfluxDB 2 and 3 serve, and InfluxDB 1.8 and later for the bucket
+// DATABASE/RETENTION_POLICY with the token USER:PASSWORD
+type InfluxWriter struct {
+	writeURL    string
+	token       string
+	measurement string
+	httpClient  *http.Client
+}
+
+// NewInfluxWriter creates a writer to a bucket of the InfluxDB server at
+// baseURL. An empty measurement uses DefaultMeasurement.
+func NewInfluxWriter(baseURL, token, org, bucket, measurement string) *InfluxWriter {
+	if measurement == "" {
+		measurement = DefaultMeasurement
+	}
+	query := url.Values{"bucket": {bucket}, "precision": {"ms"}}
+	if org != "" {
+		query.Set("org", org)
+	}
+	return &InfluxWriter{
+		writeURL:    strings.TrimSuffix(baseURL, "/") + "/api/v2/write?" + query.Encode(),
+		token:       token,
+		measurement: measurement,
+		httpClient:  &http.Client{Timeout: influxTimeout},
+	}
+}
+
+// Write writes points in one request. The wallet, mint, token account,
+// network, label and symbol are tags; the UI amount and USD value fields.
+func (w *InfluxWriter) Write(ctx context.Context, points []Point) error {
+	var body bytes.Buffer
+	for _, point := range points {
+		w.appendLine(&body, point)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.writeURL, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if w.token != "" {
+		req.Header.Set("Authorization", "Token "+w.token)
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to write to InfluxDB: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("influxdb returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// appendLine appends the line of a point. Empty tags are left out, as the
+// line protocol has no empty tag values.
+func (w *InfluxWriter) appendLine(buf *bytes.Buffer, point Point) {
+	buf.WriteString(measurementEscaper.Replace(w.measurement))
+	tags := [][2]string{
+		{"account", point.Account},
+		{"label", point.Label},
+		{"mint", point.Mint},
+		{"network", point.Network},
+		{"symbol", point.Symbol},
+		{"wallet", point.Wallet},
+	}
+	for _, tag := range tags {
+		if tag[1] != "" {
+			buf.WriteString("," + tag[0] + "=" + tagEscaper.Replace(tag[1]))
+		}
+	}
+	buf.WriteString(" ui_amount=" + strconv.FormatFloat(point.UIAmount, 'g', -1, 64))
+	if point.USDValue != nil {
+		buf.WriteString(",usd_value=" + strconv.FormatFloat(*point.USDValue, 'g', -1, 64))
+	}
+	buf.WriteString(" " + strconv.FormatInt(point.Time.UnixMilli(), 10) + "\n")
+}
+
+// Close does nothing, as the writer holds no connection
+func (w *InfluxWriter) Close() error {
+	return nil
+}
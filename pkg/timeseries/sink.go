@@ -0,0 +1,192 @@
+package timeseries
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/price"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// DefaultInterval is how often every balance is written unless configured
+const DefaultInterval = 5 * time.Minute
+
+// queueSize is how many points of changes wait to be written before new
+// ones are dropped
+const queueSize = 10000
+
+// maxBatch is the most queued points written at once
+const maxBatch = 1000
+
+// writeTimeout bounds a write of the sink
+const writeTimeout = 30 * time.Second
+
+// Source is the state of one monitor
+type Source struct {
+	Network string
+	// State returns the tracked token accounts by address
+	State func() map[string]solana.TokenAccountInfo
+	// Label returns the label of a wallet
+	Label func(wallet string) string
+}
+
+// Sink writes a point whenever a balance changes and points of every
+// balance of its sources each interval. Its Handle method is a
+// monitor.EventHandler.
+type Sink struct {
+	writer   Writer
+	prices   *price.Cache
+	interval time.Duration
+	queue    chan Point
+	log      logging.Logger
+
+	mu      sync.Mutex
+	sources []Source
+	symbols map[string]string
+}
+
+// NewSink creates a sink writing to writer and logging through log, or the
+// default logger when nil. USD values are left out when prices is nil. A
+// zero interval uses DefaultInterval.
+func NewSink(writer Writer, prices *price.Cache, interval time.Duration, log logging.Logger) *Sink {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	if log == nil {
+		log = logging.Default()
+	}
+	return &Sink{
+		writer:   writer,
+		prices:   prices,
+		interval: interval,
+		queue:    make(chan Point, queueSize),
+		log:      log,
+		symbols:  map[string]string{solana.NativeMint: "SOL"},
+	}
+}
+
+// AddSource adds the balances of a monitor to the periodic points
+func (s *Sink) AddSource(source Source) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sources = append(s.sources, source)
+}
+
+// Handle queues the point of the balance of an event and learns the symbol
+// of its mint. Events without a token account, such as those of signatures,
+// are skipped.
+func (s *Sink) Handle(event monitor.Event) {
+	if event.Account.Address == "" || event.Account.Mint == "" {
+		return
+	}
+	s.mu.Lock()
+	if event.Metadata != nil && event.Metadata.Symbol != "" {
+		s.symbols[event.Account.Mint] = event.Metadata.Symbol
+	}
+	s.mu.Unlock()
+
+	at := event.Account.LastUpdatedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+	point := s.point(event.Account, at)
+	point.Network = event.Network
+	point.Label = event.Label
+	select {
+	case s.queue <- point:
+	default:
+		s.log.Warn("Time series queue is full, dropping balance point", "account", event.Account.Address)
+	}
+}
+
+// Run writes the queued points as they arrive and every balance each
+// interval until ctx is done
+func (s *Sink) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	s.write(s.statePoints())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case point := <-s.queue:
+			s.write(s.drain(point))
+		case <-ticker.C:
+			s.write(s.statePoints())
+		}
+	}
+}
+
+// drain returns a point along with the points queued after it, up to
+// maxBatch
+func (s *Sink) drain(first Point) []Point {
+	points := []Point{first}
+	for len(points) < maxBatch {
+		select {
+		case point := <-s.queue:
+			points = append(points, point)
+		default:
+			return points
+		}
+	}
+	return points
+}
+
+// statePoints returns the points of every balance of the sources
+func (s *Sink) statePoints() []Point {
+	s.mu.Lock()
+	sources := append([]Source(nil), s.sources...)
+	s.mu.Unlock()
+
+	now := time.Now()
+	var points []Point
+	for _, source := range sources {
+		for _, account := range source.State() {
+			point := s.point(account, now)
+			point.Network = source.Network
+			if source.Label != nil {
+				point.Label = source.Label(account.Owner)
+			}
+			points = append(points, point)
+		}
+	}
+	return points
+}
+
+// point returns the point of a token account without its network and label
+func (s *Sink) point(account solana.TokenAccountInfo, at time.Time) Point {
+	s.mu.Lock()
+	symbol := s.symbols[account.Mint]
+	s.mu.Unlock()
+	return Point{
+		Time:     at,
+		Wallet:   account.Owner,
+		Mint:     account.Mint,
+		Account:  account.Address,
+		Symbol:   symbol,
+		UIAmount: account.UIAmount(),
+	}
+}
+
+// write adds the USD values to points and writes them, logging failures
+func (s *Sink) write(points []Point) {
+	if len(points) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+	if s.prices != nil {
+		for i := range points {
+			if price, ok := s.prices.Price(ctx, points[i].Mint); ok {
+				value := points[i].UIAmount * price
+				points[i].USDValue = &value
+			}
+		}
+	}
+	if err := s.writer.Write(ctx, points); err != nil {
+		s.log.Warn("Failed to write balance points", "points", len(points), "error", err)
+	}
+}
@@ -0,0 +1,130 @@
+//go:build !nopostgres
+
+package timeseries
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+
+	// Register the PostgreSQL driver
+	_ "github.com/lib/pq"
+)
+
+// DefaultTable holds the TimescaleDB points unless configured
+const DefaultTable = "balance_points"
+
+// timescaleBatch is the most rows inserted by one statement, keeping the
+// parameters below the limit of PostgreSQL
+const timescaleBatch = 1000
+
+func init() {
+	backends["timescaledb"] = func(cfg config.TimeSeriesConfig) (Writer, error) {
+		if cfg.DSN == "" {
+			return nil, fmt.Errorf("timescaledb time series require dsn")
+		}
+		return NewTimescaleWriter(cfg.DSN, cfg.Table)
+	}
+}
+
+// TimescaleWriter inserts points into a TimescaleDB hypertable
+type TimescaleWriter struct {
+	db    *sql.DB
+	table string
+}
+
+// NewTimescaleWriter connects to TimescaleDB and creates the hypertable of
+// the points when missing. An empty table uses DefaultTable. The
+// timescaledb extension must be installed in the database.
+func NewTimescaleWriter(dsn, table string) (*TimescaleWriter, error) {
+	if table == "" {
+		table = DefaultTable
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	var installed bool
+	if err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'timescaledb')`).Scan(&installed); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to look up the timescaledb extension: %w", err)
+	}
+	if !installed {
+		db.Close()
+		return nil, fmt.Errorf("the timescaledb extension is not installed, run CREATE EXTENSION timescaledb")
+	}
+
+	// Indexes live in the schema of their table, so their name is unqualified
+	index := table[strings.LastIndex(table, ".")+1:] + "_wallet_mint"
+	schema := []string{
+		`CREATE TABLE IF NOT EXISTS ` + table + ` (
+			time TIMESTAMPTZ NOT NULL,
+			network TEXT NOT NULL,
+			wallet TEXT NOT NULL,
+			mint TEXT NOT NULL,
+			account TEXT NOT NULL,
+			label TEXT NOT NULL,
+			symbol TEXT NOT NULL,
+			ui_amount DOUBLE PRECISION NOT NULL,
+			usd_value DOUBLE PRECISION
+		)`,
+		`SELECT create_hypertable('` + table + `', 'time', if_not_exists => TRUE)`,
+		`CREATE INDEX IF NOT EXISTS ` + index + ` ON ` + table + ` (wallet, mint, time DESC)`,
+	}
+	for _, statement := range schema {
+		if _, err := db.Exec(statement); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create table %s: %w", table, err)
+		}
+	}
+	return &TimescaleWriter{db: db, table: table}, nil
+}
+
+// Write inserts points in one transaction
+func (w *TimescaleWriter) Write(ctx context.Context, points []Point) error {
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for start := 0; start < len(points); start += timescaleBatch {
+		end := start + timescaleBatch
+		if end > len(points) {
+			end = len(points)
+		}
+		var query strings.Builder
+		query.WriteString(`INSERT INTO ` + w.table + ` (time, network, wallet, mint, account, label, symbol, ui_amount, usd_value) VALUES `)
+		args := make([]interface{}, 0, (end-start)*9)
+		for i, point := range points[start:end] {
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			n := len(args)
+			fmt.Fprintf(&query, "($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9)
+			var usd sql.NullFloat64
+			if point.USDValue != nil {
+				usd = sql.NullFloat64{Float64: *point.USDValue, Valid: true}
+			}
+			args = append(args, point.Time.UTC(), point.Network, point.Wallet, point.Mint, point.Account,
+				point.Label, point.Symbol, point.UIAmount, usd)
+		}
+		if _, err := tx.ExecContext(ctx, query.String(), args...); err != nil {
+			return fmt.Errorf("failed to insert points: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Close closes the database connection
+func (w *TimescaleWriter) Close() error {
+	return w.db.Close()
+}
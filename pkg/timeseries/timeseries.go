@@ -0,0 +1,59 @@
+// Package timeseries writes token balances as points to time-series
+// databases, so dashboards such as Grafana can chart them natively.
+package timeseries
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/config"
+)
+
+// Point is the balance of a token account at a point in time
+type Point struct {
+	Time     time.Time
+	Network  string
+	Wallet   string
+	Mint     string
+	Account  string
+	Label    string
+	Symbol   string
+	UIAmount float64
+	// USDValue is the value of the balance, when the price of the mint is
+	// known
+	USDValue *float64
+}
+
+// Writer writes points to a database
+type Writer interface {
+	// Write writes points, all or none
+	Write(ctx context.Context, points []Point) error
+	// Close releases resources held by the writer
+	Close() error
+}
+
+// opener creates a writer of one type from its configuration
+type opener func(cfg config.TimeSeriesConfig) (Writer, error)
+
+// backends are the optional writer types compiled into the binary. Each
+// registers itself from a file excluded by its build tag, like the store
+// backends.
+var backends = map[string]opener{}
+
+// backendTags are the build tags that exclude optional writer types
+var backendTags = map[string]string{
+	"influxdb":    "noinflux",
+	"timescaledb": "nopostgres",
+}
+
+// New creates a writer from its configuration
+func New(cfg config.TimeSeriesConfig) (Writer, error) {
+	if open, ok := backends[cfg.Type]; ok {
+		return open(cfg)
+	}
+	if tag, ok := backendTags[cfg.Type]; ok {
+		return nil, fmt.Errorf("%s time series are not available in this build (built with the %s tag)", cfg.Type, tag)
+	}
+	return nil, fmt.Errorf("unknown time series type: %q", cfg.Type)
+}
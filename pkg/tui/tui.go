@@ -0,0 +1,367 @@
+// Package tui is the terminal dashboard of `tracker tui`: a live table of the
+// token balances of the tracked wallets and a scrolling feed of their events,
+// drawn in place of the logs.
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/notify"
+	"github.com/yourusername/solana-wallet-tracker/pkg/solana"
+)
+
+// maxFeed is how many events the feed keeps
+const maxFeed = 500
+
+// refreshInterval is how often the table is read again from the monitors
+const refreshInterval = time.Second
+
+// StateSource returns the token accounts of a monitor, such as
+// Monitor.GetCurrentState
+type StateSource func() map[string]solana.TokenAccountInfo
+
+// Dashboard collects what the terminal shows. Its event handler and log
+// writer may be used before Run, and from any goroutine.
+type Dashboard struct {
+	locale string
+
+	mu      sync.Mutex
+	sources []StateSource
+	labels  map[string]string
+	// symbols are the token symbols seen in event metadata, by mint
+	symbols map[string]string
+	// changed is the time of the last event of each token account, by
+	// owner and mint
+	changed map[string]time.Time
+	feed    []string
+	lastLog string
+	// running is set while Run draws the dashboard; logs go to stderr
+	// before and after
+	running bool
+}
+
+// New creates a dashboard rendering events in locale
+func New(locale string) *Dashboard {
+	return &Dashboard{
+		locale:  locale,
+		symbols: map[string]string{solana.NativeMint: "SOL"},
+		changed: make(map[string]time.Time),
+	}
+}
+
+// SetLabels names wallets by their labels, by address
+func (d *Dashboard) SetLabels(labels map[string]string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.labels = labels
+}
+
+// AddSource adds the token accounts of a monitor to the table
+func (d *Dashboard) AddSource(source StateSource) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sources = append(d.sources, source)
+}
+
+// Handle adds an event to the feed and dates the change of its account. It
+// is a monitor.EventHandler.
+func (d *Dashboard) Handle(event monitor.Event) {
+	now := time.Now()
+	line := fmt.Sprintf("%s  %-20s %s", now.Format("15:04:05"), truncate(d.walletName(event.Account.Owner), 20),
+		notify.FormatSummaryLine(d.locale, event))
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if event.Account.Mint != "" {
+		d.changed[event.Account.Owner+":"+event.Account.Mint] = now
+	}
+	if event.Metadata != nil && event.Metadata.Symbol != "" {
+		d.symbols[event.Account.Mint] = event.Metadata.Symbol
+	}
+	d.feed = append(d.feed, line)
+	if len(d.feed) > maxFeed {
+		d.feed = d.feed[len(d.feed)-maxFeed:]
+	}
+}
+
+// Write keeps the last line logged, shown below the feed, which lets the
+// dashboard be the output of the logger. Until Run draws the dashboard, such
+// as while the tracker starts, and once it returns, logs are written to
+// stderr instead.
+func (d *Dashboard) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.running {
+		return os.Stderr.Write(p)
+	}
+	lines := strings.Split(strings.TrimSpace(string(p)), "\n")
+	if line := lines[len(lines)-1]; line != "" {
+		d.lastLog = line
+	}
+	return len(p), nil
+}
+
+var _ io.Writer = (*Dashboard)(nil)
+
+// Run draws the dashboard until the user quits it or ctx is done
+func (d *Dashboard) Run(ctx context.Context) error {
+	d.setRunning(true)
+	defer d.setRunning(false)
+
+	program := tea.NewProgram(&model{dashboard: d}, tea.WithAltScreen(), tea.WithContext(ctx))
+	if _, err := program.Run(); err != nil && !errors.Is(err, tea.ErrProgramKilled) {
+		return err
+	}
+	return nil
+}
+
+// setRunning switches the logs between the dashboard and stderr
+func (d *Dashboard) setRunning(running bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.running = running
+}
+
+// row is a token account of the table
+type row struct {
+	wallet  string
+	token   string
+	balance string
+	changed time.Time
+}
+
+// rows reads the token accounts of the monitors, ordered by wallet and
+// token
+func (d *Dashboard) rows() []row {
+	d.mu.Lock()
+	sources := append([]StateSource(nil), d.sources...)
+	d.mu.Unlock()
+
+	var accounts []solana.TokenAccountInfo
+	for _, source := range sources {
+		for _, account := range source() {
+			accounts = append(accounts, account)
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	rows := make([]row, 0, len(accounts))
+	for _, account := range accounts {
+		token, ok := d.symbols[account.Mint]
+		if !ok {
+			token = shorten(account.Mint)
+		}
+		rows = append(rows, row{
+			wallet:  d.walletNameLocked(account.Owner),
+			token:   token,
+			balance: account.UIAmountString(),
+			changed: d.changed[account.Owner+":"+account.Mint],
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].wallet != rows[j].wallet {
+			return rows[i].wallet < rows[j].wallet
+		}
+		return rows[i].token < rows[j].token
+	})
+	return rows
+}
+
+// walletName returns the label of a wallet, or its shortened address
+func (d *Dashboard) walletName(wallet string) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.walletNameLocked(wallet)
+}
+
+// walletNameLocked is walletName with the lock held
+func (d *Dashboard) walletNameLocked(wallet string) string {
+	if label := d.labels[wallet]; label != "" {
+		return label
+	}
+	return shorten(wallet)
+}
+
+// snapshot returns the feed and the last log line
+func (d *Dashboard) snapshot() ([]string, string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.feed...), d.lastLog
+}
+
+// shorten abbreviates an address to its first and last characters
+func shorten(address string) string {
+	if len(address) <= 12 {
+		return address
+	}
+	return address[:4] + "…" + address[len(address)-4:]
+}
+
+// since renders how long ago a change was
+func since(changed, now time.Time) string {
+	if changed.IsZero() {
+		return "-"
+	}
+	elapsed := now.Sub(changed)
+	switch {
+	case elapsed < time.Minute:
+		return fmt.Sprintf("%ds ago", int(elapsed.Seconds()))
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%dm ago", int(elapsed.Minutes()))
+	case elapsed < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(elapsed.Hours()))
+	default:
+		return changed.Format("2006-01-02 15:04")
+	}
+}
+
+// refreshMsg asks the model to read the monitors again
+type refreshMsg time.Time
+
+// refresh schedules the next refresh
+func refresh() tea.Cmd {
+	return tea.Tick(refreshInterval, func(t time.Time) tea.Msg { return refreshMsg(t) })
+}
+
+// model is the bubbletea model of the dashboard
+type model struct {
+	dashboard *Dashboard
+	rows      []row
+	now       time.Time
+	width     int
+	height    int
+	// tableOffset is the first row shown, feedOffset how many of the
+	// latest events are scrolled past
+	tableOffset int
+	feedOffset  int
+}
+
+// Init loads the table
+func (m *model) Init() tea.Cmd {
+	return func() tea.Msg { return refreshMsg(time.Now()) }
+}
+
+// Update handles keys, resizes and refreshes
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case refreshMsg:
+		m.now = time.Time(msg)
+		m.rows = m.dashboard.rows()
+		return m, refresh()
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		case "up", "k":
+			m.tableOffset--
+		case "down", "j":
+			m.tableOffset++
+		case "pgup":
+			m.feedOffset += m.feedHeight()
+		case "pgdown":
+			m.feedOffset -= m.feedHeight()
+		case "end":
+			m.feedOffset = 0
+		}
+	}
+	return m, nil
+}
+
+// tableHeight is how many rows of the table fit, half of the screen
+func (m *model) tableHeight() int {
+	// Title, table header, feed header, log and help lines
+	return atLeast((m.height-5)/2, 1)
+}
+
+// feedHeight is how many events of the feed fit below the table
+func (m *model) feedHeight() int {
+	return atLeast(m.height-5-m.tableHeight(), 1)
+}
+
+// View draws the title, the table, the feed and the last log line
+func (m *model) View() string {
+	if m.height == 0 {
+		return ""
+	}
+	var b strings.Builder
+
+	wallets := make(map[string]bool)
+	for _, r := range m.rows {
+		wallets[r.wallet] = true
+	}
+	title := fmt.Sprintf("Solana wallet tracker · %d wallets · %d token accounts", len(wallets), len(m.rows))
+	b.WriteString(termenv.String(title).Bold().String() + "\n")
+
+	// Table
+	height := m.tableHeight()
+	m.tableOffset = clamp(m.tableOffset, 0, atLeast(len(m.rows)-height, 0))
+	b.WriteString(termenv.String(fmt.Sprintf("%-20s %-12s %24s  %s", "WALLET", "TOKEN", "BALANCE", "LAST CHANGE")).Reverse().String() + "\n")
+	for i := 0; i < height; i++ {
+		if index := m.tableOffset + i; index < len(m.rows) {
+			r := m.rows[index]
+			fmt.Fprintf(&b, "%-20s %-12s %24s  %s", truncate(r.wallet, 20), truncate(r.token, 12), r.balance, since(r.changed, m.now))
+		}
+		b.WriteString("\n")
+	}
+
+	// Feed, newest last
+	feed, lastLog := m.dashboard.snapshot()
+	height = m.feedHeight()
+	m.feedOffset = clamp(m.feedOffset, 0, atLeast(len(feed)-height, 0))
+	end := len(feed) - m.feedOffset
+	start := atLeast(end-height, 0)
+	header := fmt.Sprintf("EVENTS (%d)", len(feed))
+	if m.feedOffset > 0 {
+		header += fmt.Sprintf(" · %d newer below", m.feedOffset)
+	}
+	b.WriteString(termenv.String(header).Reverse().String() + "\n")
+	for i := start; i < start+height; i++ {
+		if i < end {
+			b.WriteString(feed[i])
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(termenv.String(lastLog).Faint().String() + "\n")
+	b.WriteString(termenv.String("q quit · ↑/↓ scroll balances · PgUp/PgDn scroll events · End latest").Faint().String())
+	return b.String()
+}
+
+// truncate cuts a value to width characters
+func truncate(value string, width int) string {
+	runes := []rune(value)
+	if len(runes) <= width {
+		return value
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// clamp bounds a value, preferring low when high is below it
+func clamp(value, low, high int) int {
+	if value > high {
+		value = high
+	}
+	return atLeast(value, low)
+}
+
+// atLeast returns value, or low when value is below it
+func atLeast(value, low int) int {
+	if value < low {
+		return low
+	}
+	return value
+}
@@ -0,0 +1,227 @@
+package watchdog
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yourusername/solana-wallet-tracker/pkg/logging"
+	"github.com/yourusername/solana-wallet-tracker/pkg/monitor"
+	"github.com/yourusername/solana-wallet-tracker/pkg/notify"
+)
+
+// Defaults for zero options
+const (
+	DefaultRPCFailures   = 3
+	DefaultCheckInterval = time.Minute
+)
+
+// pingTimeout bounds a single RPC health check
+const pingTimeout = 10 * time.Second
+
+// Options control when the watchdog reports
+type Options struct {
+	// Heartbeat is the interval between heartbeats; zero sends none
+	Heartbeat time.Duration
+	// Silence is how long without events degrades the tracker; zero
+	// disables the check
+	Silence time.Duration
+	// RPCFailures is the number of failed health checks in a row that
+	// degrade the tracker
+	RPCFailures   int
+	CheckInterval time.Duration
+}
+
+// Watchdog watches the tracker itself and reports its health to a channel
+// of its own: heartbeats while it runs, and an alert when it stops seeing
+// events or its RPC endpoint keeps failing, so outages don't go unnoticed.
+type Watchdog struct {
+	notifier notify.Notifier
+	ping     func(ctx context.Context) error
+	wallets  func() int
+	options  Options
+	started  time.Time
+	log      logging.Logger
+
+	mutex     sync.Mutex
+	events    int
+	lastEvent time.Time
+
+	// Owned by Run
+	failures int
+	rpcError string
+	problems []string
+}
+
+// New creates a watchdog reporting to notifier and logging through log, or
+// the default logger when nil. ping checks the RPC endpoint and wallets
+// returns the number of monitored wallets.
+func New(notifier notify.Notifier, ping func(ctx context.Context) error, wallets func() int, options Options, log logging.Logger) *Watchdog {
+	if log == nil {
+		log = logging.Default()
+	}
+	if options.RPCFailures <= 0 {
+		options.RPCFailures = DefaultRPCFailures
+	}
+	if options.CheckInterval <= 0 {
+		options.CheckInterval = DefaultCheckInterval
+	}
+	return &Watchdog{
+		notifier: notifier,
+		ping:     ping,
+		wallets:  wallets,
+		options:  options,
+		started:  time.Now(),
+		log:      log,
+	}
+}
+
+// Observe records an event. It matches the monitor.EventHandler signature
+// so it can be registered directly. Wallet stale events report silence
+// rather than activity and are left out.
+func (w *Watchdog) Observe(event monitor.Event) {
+	if event.Type == monitor.EventWalletStale {
+		return
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.events++
+	w.lastEvent = time.Now()
+}
+
+// Run checks the tracker and sends reports until ctx is cancelled
+func (w *Watchdog) Run(ctx context.Context) {
+	checks := time.NewTicker(w.options.CheckInterval)
+	defer checks.Stop()
+
+	var heartbeats <-chan time.Time
+	if w.options.Heartbeat > 0 {
+		ticker := time.NewTicker(w.options.Heartbeat)
+		defer ticker.Stop()
+		heartbeats = ticker.C
+	}
+
+	for {
+		select {
+		case <-checks.C:
+			w.check(ctx)
+		case <-heartbeats:
+			w.report(w.health(true))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// check pings the RPC endpoint and reports when the set of problems changes
+func (w *Watchdog) check(ctx context.Context) {
+	pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+	err := w.ping(pingCtx)
+	cancel()
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		w.failures++
+		w.rpcError = err.Error()
+		w.log.Warn("Watchdog RPC health check failed", "failures", w.failures, "error", err)
+	} else {
+		w.failures = 0
+		w.rpcError = ""
+	}
+
+	var problems []string
+	if w.options.Silence > 0 && w.quietFor() >= w.options.Silence {
+		problems = append(problems, notify.ProblemNoEvents)
+	}
+	if w.failures >= w.options.RPCFailures {
+		problems = append(problems, notify.ProblemRPCFailures)
+	}
+
+	wasDegraded := len(w.problems) > 0
+	changed := !sameProblems(w.problems, problems)
+	w.problems = problems
+	if !changed {
+		return
+	}
+
+	health := w.health(false)
+	if !wasDegraded || len(problems) > 0 {
+		w.log.Warn("Tracker degraded", "problems", problems)
+	} else {
+		health.Status = notify.HealthRecovered
+		w.log.Info("Tracker recovered")
+	}
+	w.report(health)
+}
+
+// health builds a report of the current state and starts counting events
+// anew
+func (w *Watchdog) health(heartbeat bool) notify.Health {
+	w.mutex.Lock()
+	events := w.events
+	lastEvent := w.lastEvent
+	w.events = 0
+	w.mutex.Unlock()
+
+	now := time.Now()
+	health := notify.Health{
+		Status:    notify.HealthOK,
+		Heartbeat: heartbeat,
+		Problems:  w.problems,
+		Uptime:    now.Sub(w.started).Round(time.Second).String(),
+		Wallets:   w.wallets(),
+		Events:    events,
+		At:        now,
+	}
+	if len(w.problems) > 0 {
+		health.Status = notify.HealthDegraded
+	}
+	if !lastEvent.IsZero() {
+		health.LastEventAt = &lastEvent
+	}
+	if health.Has(notify.ProblemNoEvents) {
+		health.QuietFor = w.quietFor().Round(time.Second).String()
+	}
+	if health.Has(notify.ProblemRPCFailures) {
+		health.RPCFailures = w.failures
+		health.RPCError = w.rpcError
+	}
+	return health
+}
+
+// quietFor returns how long no event was seen, counting from the start when
+// there was none yet
+func (w *Watchdog) quietFor() time.Duration {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.lastEvent.IsZero() {
+		return time.Since(w.started)
+	}
+	return time.Since(w.lastEvent)
+}
+
+// report delivers a health report. Failures are logged only, as the channel
+// they would be reported to is the one failing.
+func (w *Watchdog) report(health notify.Health) {
+	if err := notify.SendHealth(w.notifier, health); err != nil {
+		w.log.Error("Failed to deliver health report",
+			"notifier", w.notifier.Name(),
+			"status", health.Status,
+			"error", err,
+		)
+	}
+}
+
+// sameProblems reports whether two problem lists are equal
+func sameProblems(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}